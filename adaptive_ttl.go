@@ -0,0 +1,201 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveTTL defaults.
+const (
+	adaptiveTTLDefaultMinTTL         = time.Second
+	adaptiveTTLDefaultMaxTTL         = 24 * time.Hour
+	adaptiveTTLDefaultMaxTrackedKeys = 10000
+)
+
+// AdaptiveTTLConfig holds the settings of an AdaptiveTTL cache decorator.
+type AdaptiveTTLConfig struct {
+	// MinTTL is the lower bound of adjusted expiration periods. By default (0), it's 1s.
+	MinTTL time.Duration
+	// MaxTTL is the upper bound of adjusted expiration periods. By default (0), it's 24h.
+	MaxTTL time.Duration
+	// MaxTrackedKeys is the max. no. of tracked keys, bounding the memory used for tracking.
+	// When reached, an arbitrary tracked key is dropped. By default (0), 10000 keys are tracked.
+	MaxTrackedKeys int
+	// TrackKey returns the tracking unit of a key. It can be used to track prefixes instead of keys
+	// (example: "user:123" => "user:"). By default, keys are tracked individually.
+	TrackKey func(key string) string
+}
+
+// AdaptiveTTL is an experimental Cache decorator which adjusts the expiration periods of keys on re-save,
+// based on their observed reuse (loads, and intervals between them):
+// - a key reused (loaded) within its lifetime gets double the previous expiration period;
+// - a key not reused since its previous save (a one-hit wonder) gets half the previous expiration period.
+// Adjusted expiration periods are kept within [MinTTL, MaxTTL] bounds.
+// The expiration period given at the first save of a key (bounded, too) is the starting point.
+// Keys saved with NoExpire, and deletions, are not adjusted.
+//
+// It is meant to reduce eviction pressure on a small Memory layer, by extending lifetimes of frequently reused keys
+// and shortening them for keys which are written and never read.
+// Tracking information is kept in memory, for current instance only.
+type AdaptiveTTL struct {
+	cache   Cache
+	config  AdaptiveTTLConfig
+	tracked map[string]*keyReuse
+	mu      sync.Mutex
+}
+
+// keyReuse holds the observed reuse of a key.
+type keyReuse struct {
+	ttl          time.Duration // last expiration period the key was saved with.
+	lastLoadedAt time.Time     // last load moment.
+	loads        int64         // no. of loads since last save.
+	avgInterval  time.Duration // moving average of intervals between loads.
+}
+
+// NewAdaptiveTTL instantiates a new AdaptiveTTL which decorates given cache, with given settings.
+func NewAdaptiveTTL(cache Cache, config AdaptiveTTLConfig) *AdaptiveTTL {
+	if config.MaxTrackedKeys <= 0 {
+		config.MaxTrackedKeys = adaptiveTTLDefaultMaxTrackedKeys
+	}
+	if config.MinTTL <= 0 {
+		config.MinTTL = adaptiveTTLDefaultMinTTL
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = adaptiveTTLDefaultMaxTTL
+	}
+	if config.MaxTTL < config.MinTTL {
+		config.MaxTTL = config.MinTTL
+	}
+
+	return &AdaptiveTTL{
+		cache:   cache,
+		config:  config,
+		tracked: make(map[string]*keyReuse),
+	}
+}
+
+// Save stores the given key-value into decorated cache, with an expiration period
+// adjusted according to key's observed reuse.
+func (cache *AdaptiveTTL) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		cache.mu.Lock()
+		delete(cache.tracked, cache.trackKey(key))
+		cache.mu.Unlock()
+	} else if expire > 0 {
+		expire = cache.adjustTTL(key, expire)
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache, and records the access.
+func (cache *AdaptiveTTL) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err == nil {
+		cache.recordLoad(key)
+	}
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *AdaptiveTTL) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *AdaptiveTTL) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *AdaptiveTTL) Unwrap() Cache {
+	return cache.cache
+}
+
+// adjustTTL returns the expiration period for given key, and resets its reuse tracking.
+func (cache *AdaptiveTTL) adjustTTL(key string, expire time.Duration) time.Duration {
+	now := time.Now()
+	trackKey := cache.trackKey(key)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	reuse, found := cache.tracked[trackKey]
+	if !found {
+		if len(cache.tracked) >= cache.config.MaxTrackedKeys {
+			for k := range cache.tracked { // drop an arbitrary key
+				delete(cache.tracked, k)
+
+				break
+			}
+		}
+		reuse = &keyReuse{ttl: cache.boundTTL(expire)}
+		cache.tracked[trackKey] = reuse
+	} else {
+		switch {
+		case reuse.loads == 0:
+			reuse.ttl = cache.boundTTL(reuse.ttl / 2)
+		case reuse.avgInterval < reuse.ttl:
+			reuse.ttl = cache.boundTTL(reuse.ttl * 2)
+		}
+	}
+	reuse.lastLoadedAt = now
+	reuse.loads = 0
+
+	return reuse.ttl
+}
+
+// recordLoad records a load of given key.
+func (cache *AdaptiveTTL) recordLoad(key string) {
+	now := time.Now()
+	trackKey := cache.trackKey(key)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	reuse, found := cache.tracked[trackKey]
+	if !found {
+		return
+	}
+	interval := now.Sub(reuse.lastLoadedAt)
+	if reuse.avgInterval == 0 {
+		reuse.avgInterval = interval
+	} else { // exponentially weighted moving average
+		reuse.avgInterval = (3*reuse.avgInterval + interval) / 4
+	}
+	reuse.lastLoadedAt = now
+	reuse.loads++
+}
+
+// boundTTL returns given expiration period within configured bounds.
+func (cache *AdaptiveTTL) boundTTL(ttl time.Duration) time.Duration {
+	if ttl < cache.config.MinTTL {
+		return cache.config.MinTTL
+	}
+	if ttl > cache.config.MaxTTL {
+		return cache.config.MaxTTL
+	}
+
+	return ttl
+}
+
+// trackKey returns the tracking unit of given key.
+func (cache *AdaptiveTTL) trackKey(key string) string {
+	if cache.config.TrackKey != nil {
+		return cache.config.TrackKey(key)
+	}
+
+	return key
+}
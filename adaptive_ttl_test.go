@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.AdaptiveTTL)(nil) // test AdaptiveTTL is a Cache
+}
+
+func TestAdaptiveTTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ttl is adjusted by reuse", testAdaptiveTTLTTLIsAdjustedByReuse)
+	t.Run("prefixes are tracked", testAdaptiveTTLPrefixesAreTracked)
+	t.Run("no expire and deletions are not adjusted", testAdaptiveTTLNoExpireAndDeletionsAreNotAdjusted)
+}
+
+func testAdaptiveTTLTTLIsAdjustedByReuse(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewAdaptiveTTL(cache, xcache.AdaptiveTTLConfig{
+			MinTTL: time.Minute,
+			MaxTTL: 8 * time.Minute,
+		})
+		ctx       = context.Background()
+		key       = "test-adaptive-ttl-key"
+		savedTTLs []time.Duration
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		savedTTLs = append(savedTTLs, exp)
+
+		return nil
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return []byte("test value"), nil
+	})
+	saveAndLoad := func(loads int) {
+		_ = subject.Save(ctx, key, []byte("test value"), 2*time.Minute)
+		for i := 0; i < loads; i++ {
+			_, _ = subject.Load(ctx, key)
+		}
+	}
+
+	// act
+	saveAndLoad(2) // initial ttl
+	saveAndLoad(1) // reused => doubled
+	saveAndLoad(3) // reused => doubled
+	saveAndLoad(0) // reused => doubled, but bounded
+	saveAndLoad(0) // not reused => halved
+	saveAndLoad(0) // not reused => halved
+	saveAndLoad(0) // not reused => halved, but bounded
+	saveAndLoad(0) // not reused => halved, but bounded
+
+	// assert
+	assertEqual(
+		t,
+		[]time.Duration{
+			2 * time.Minute,
+			4 * time.Minute,
+			8 * time.Minute,
+			8 * time.Minute,
+			4 * time.Minute,
+			2 * time.Minute,
+			time.Minute,
+			time.Minute,
+		},
+		savedTTLs,
+	)
+}
+
+func testAdaptiveTTLPrefixesAreTracked(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewAdaptiveTTL(cache, xcache.AdaptiveTTLConfig{
+			TrackKey: func(key string) string {
+				return key[:strings.IndexByte(key, ':')+1]
+			},
+		})
+		ctx       = context.Background()
+		savedTTLs = make(map[string]time.Duration)
+	)
+	cache.SetSaveCallback(func(_ context.Context, key string, _ []byte, exp time.Duration) error {
+		savedTTLs[key] = exp
+
+		return nil
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return []byte("test value"), nil
+	})
+	_ = subject.Save(ctx, "test-adaptive-ttl-user:1", []byte("1"), time.Minute)
+	_, _ = subject.Load(ctx, "test-adaptive-ttl-user:1")
+	_ = subject.Save(ctx, "test-adaptive-ttl-order:1", []byte("1"), time.Minute)
+
+	// act
+	err := subject.Save(ctx, "test-adaptive-ttl-user:2", []byte("2"), time.Minute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, time.Minute, savedTTLs["test-adaptive-ttl-user:1"])
+	assertEqual(t, time.Minute, savedTTLs["test-adaptive-ttl-order:1"])
+	assertEqual(t, 2*time.Minute, savedTTLs["test-adaptive-ttl-user:2"]) // doubled as prefix was reused
+}
+
+func testAdaptiveTTLNoExpireAndDeletionsAreNotAdjusted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = new(xcache.Mock)
+		subject   = xcache.NewAdaptiveTTL(cache, xcache.AdaptiveTTLConfig{MinTTL: time.Minute})
+		ctx       = context.Background()
+		key       = "test-adaptive-ttl-key"
+		savedTTLs []time.Duration
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		savedTTLs = append(savedTTLs, exp)
+
+		return nil
+	})
+
+	// act
+	_ = subject.Save(ctx, key, []byte("test value"), xcache.NoExpire)
+	_ = subject.Save(ctx, key, []byte("test value"), time.Second)
+	_ = subject.Save(ctx, key, nil, -1)
+	_ = subject.Save(ctx, key, []byte("test value"), 2*time.Minute) // tracking was reset by deletion
+
+	// assert
+	assertEqual(t, []time.Duration{xcache.NoExpire, time.Minute, -1, 2 * time.Minute}, savedTTLs)
+}
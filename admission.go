@@ -0,0 +1,175 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// Admission defaults.
+const (
+	admissionDefaultMinRequests = 2
+	admissionDefaultWindow      = time.Minute
+	admissionDefaultWidth       = 4096
+	admissionSketchDepth        = 4
+)
+
+// AdmissionConfig holds the settings of an Admission cache decorator.
+type AdmissionConfig struct {
+	// MinRequests is the no. of times a key must be requested (loaded) within a window,
+	// in order to be admitted into the decorated cache. By default (0), it's 2.
+	MinRequests int
+	// Window is the period requests are counted for. Counters are reset at the end of each window.
+	// By default (0), it's 1m.
+	Window time.Duration
+	// Width is the no. of counters of each of the 4 rows of the counting filter.
+	// A larger width means less over-estimation of requests, at the cost of more memory (1 byte / counter).
+	// By default (0), it's 4096.
+	Width int
+}
+
+// Admission is a Cache decorator which admits a key into the decorated cache (usually the local Memory layer
+// of a Multi cache) only after it has been requested (loaded) MinRequests times within a window,
+// so write-once-read-never keys do not evict genuinely hot entries from a small cache.
+// Requests are tracked with a tiny counting filter (count-min sketch), so counts can be over-estimated
+// (a key can be admitted earlier), but never under-estimated.
+// Deletions (saves with a negative expiration period) always reach the decorated cache.
+//
+// Example:
+//
+//	cache := xcache.NewMulti(xcache.NewAdmission(memCache, xcache.AdmissionConfig{}), redisCache)
+type Admission struct {
+	cache       Cache
+	config      AdmissionConfig
+	seed        maphash.Seed
+	counters    [admissionSketchDepth][]uint8
+	windowStart time.Time
+	mu          sync.Mutex
+}
+
+// NewAdmission instantiates a new Admission which decorates given cache, with given settings.
+func NewAdmission(cache Cache, config AdmissionConfig) *Admission {
+	if config.MinRequests <= 0 {
+		config.MinRequests = admissionDefaultMinRequests
+	}
+	if config.Window <= 0 {
+		config.Window = admissionDefaultWindow
+	}
+	if config.Width <= 0 {
+		config.Width = admissionDefaultWidth
+	}
+
+	adm := &Admission{
+		cache:       cache,
+		config:      config,
+		seed:        maphash.MakeSeed(),
+		windowStart: time.Now(),
+	}
+	for i := range adm.counters {
+		adm.counters[i] = make([]uint8, config.Width)
+	}
+
+	return adm
+}
+
+// Save stores the given key-value with expiration period into decorated cache,
+// if the key was requested enough times within current window (deletions are performed anyway).
+func (cache *Admission) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire >= 0 && !cache.admit(key) {
+		return nil
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache, and counts the request.
+func (cache *Admission) Load(ctx context.Context, key string) ([]byte, error) {
+	cache.count(key)
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *Admission) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *Admission) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Admission) Unwrap() Cache {
+	return cache.cache
+}
+
+// count increments the counters of given key.
+func (cache *Admission) count(key string) {
+	h1, h2 := cache.hash(key)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.resetExpiredWindow()
+	for i := range cache.counters {
+		idx := cache.index(h1, h2, i)
+		if cache.counters[i][idx] < 255 {
+			cache.counters[i][idx]++
+		}
+	}
+}
+
+// admit checks if given key was requested enough times within current window.
+func (cache *Admission) admit(key string) bool {
+	h1, h2 := cache.hash(key)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.resetExpiredWindow()
+	estimate := uint8(255)
+	for i := range cache.counters {
+		if counter := cache.counters[i][cache.index(h1, h2, i)]; counter < estimate {
+			estimate = counter
+		}
+	}
+
+	return int(estimate) >= cache.config.MinRequests
+}
+
+// resetExpiredWindow resets the counters if current window has passed.
+// It should be called under lock.
+func (cache *Admission) resetExpiredWindow() {
+	now := time.Now()
+	if now.Sub(cache.windowStart) < cache.config.Window {
+		return
+	}
+	for i := range cache.counters {
+		clear(cache.counters[i])
+	}
+	cache.windowStart = now
+}
+
+// hash returns two 32 bit hashes of given key, from which counters' indexes are derived.
+func (cache *Admission) hash(key string) (uint32, uint32) {
+	h := maphash.String(cache.seed, key)
+
+	return uint32(h), uint32(h >> 32)
+}
+
+// index returns the index of the counter in given row, for given hashes.
+func (cache *Admission) index(h1, h2 uint32, row int) int {
+	return int((h1 + uint32(row)*h2) % uint32(cache.config.Width))
+}
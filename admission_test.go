@@ -0,0 +1,131 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Admission)(nil) // test Admission is a Cache
+}
+
+func TestAdmission(t *testing.T) {
+	t.Parallel()
+
+	t.Run("key is admitted after enough requests", testAdmissionKeyIsAdmittedAfterEnoughRequests)
+	t.Run("counters are reset after window", testAdmissionCountersAreResetAfterWindow)
+	t.Run("deletions are not filtered", testAdmissionDeletionsAreNotFiltered)
+	t.Run("multi cache", testAdmissionMultiCache)
+}
+
+func testAdmissionKeyIsAdmittedAfterEnoughRequests(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewAdmission(cache, xcache.AdmissionConfig{MinRequests: 3})
+		ctx     = context.Background()
+		key     = "test-admission-key"
+		value   = []byte("test value")
+	)
+
+	for i := 1; i <= 3; i++ {
+		// act
+		_, errLoad := subject.Load(ctx, key)
+		errSave := subject.Save(ctx, key, value, time.Minute)
+
+		// assert
+		assertNil(t, errSave)
+		assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+		_, err := cache.Load(ctx, key)
+		if i < 3 {
+			assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+		} else {
+			assertNil(t, err)
+		}
+	}
+	resultValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, resultValue)
+	_, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	_, err = subject.Stats(ctx)
+	assertNil(t, err)
+}
+
+func testAdmissionCountersAreResetAfterWindow(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewAdmission(cache, xcache.AdmissionConfig{
+			MinRequests: 2,
+			Window:      100 * time.Millisecond,
+		})
+		ctx = context.Background()
+		key = "test-admission-key"
+	)
+	_, _ = subject.Load(ctx, key)
+	time.Sleep(150 * time.Millisecond)
+	_, _ = subject.Load(ctx, key)
+
+	// act
+	err := subject.Save(ctx, key, []byte("test value"), time.Minute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 0, cache.SaveCallsCount())
+}
+
+func testAdmissionDeletionsAreNotFiltered(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewAdmission(cache, xcache.AdmissionConfig{})
+	)
+
+	// act
+	err := subject.Save(context.Background(), "test-admission-key", nil, -1)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}
+
+func testAdmissionMultiCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		memCache    = xcache.NewMemory(1)
+		remoteCache = xcache.NewMemory(1)
+		subject     = xcache.NewMulti(xcache.NewAdmission(memCache, xcache.AdmissionConfig{}), remoteCache)
+		ctx         = context.Background()
+		key         = "test-admission-multi-key"
+	)
+	_ = subject.Save(ctx, key, []byte("test value"), time.Minute) // write once
+
+	// act & assert
+	_, err := subject.Load(ctx, key) // 1st request
+	assertNil(t, err)
+	_, err = memCache.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	_, err = subject.Load(ctx, key) // 2nd request
+	assertNil(t, err)
+	_, err = memCache.Load(ctx, key)
+	assertNil(t, err)
+}
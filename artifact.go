@@ -0,0 +1,97 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+)
+
+// ArtifactsConfig holds the settings of an Artifacts cache helper.
+type ArtifactsConfig struct {
+	// KeyPrefix is prepended to artifacts' keys.
+	KeyPrefix string
+	// TTL is the expiration period of cached artifacts.
+	// A value of 0 (NoExpire) means no expiration.
+	TTL time.Duration
+}
+
+// DeriveFunc derives an artifact (like a thumbnail of an image) from given source content.
+type DeriveFunc func(ctx context.Context, source []byte) ([]byte, error)
+
+// Artifacts caches binary artifacts derived from some source content (like images thumbnails,
+// converted documents, etc.) under content-addressed keys: the key of an artifact is built upon
+// the transformation name and the SHA-256 hash of the source content, no matter where the content comes from.
+// Thus, the same source processed by multiple instances (or uploaded multiple times) is stored only once.
+//
+// Keys have the format: <KeyPrefix><transformation>:<hex source hash>.
+// The transformation should identify the parameters of the derivation, too (example: "thumb-200x200").
+type Artifacts struct {
+	cache  Cache
+	config ArtifactsConfig
+}
+
+// NewArtifacts instantiates a new Artifacts which caches derived artifacts into given cache,
+// according to given settings.
+func NewArtifacts(cache Cache, config ArtifactsConfig) *Artifacts {
+	return &Artifacts{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Get returns the cached artifact derived with given transformation from the content read from source, if any,
+// otherwise it derives the artifact with given function, and caches it.
+// The source is read (and hashed) in a streaming fashion, and buffered for the derive function.
+// Cache errors are not returned, the artifact is derived instead.
+// It returns the source's error, or the derive function's one.
+func (a *Artifacts) Get(
+	ctx context.Context,
+	transformation string,
+	source io.Reader,
+	deriveFn DeriveFunc,
+) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		h   = sha256.New()
+	)
+	if _, err := io.Copy(io.MultiWriter(h, &buf), source); err != nil {
+		return nil, err
+	}
+	key := a.key(transformation, h.Sum(nil))
+
+	if artifact, err := a.cache.Load(ctx, key); err == nil {
+		return artifact, nil
+	}
+
+	artifact, err := deriveFn(ctx, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	_ = a.cache.Save(ctx, key, artifact, a.config.TTL)
+
+	return artifact, nil
+}
+
+// Key returns the key of the artifact derived with given transformation from the content read from source.
+// It returns the source's error, if any.
+func (a *Artifacts) Key(transformation string, source io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, source); err != nil {
+		return "", err
+	}
+
+	return a.key(transformation, h.Sum(nil)), nil
+}
+
+// key returns the key of the artifact derived with given transformation from a source with given hash.
+func (a *Artifacts) key(transformation string, sourceHash []byte) string {
+	return a.config.KeyPrefix + transformation + ":" + hex.EncodeToString(sourceHash)
+}
@@ -0,0 +1,124 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestArtifacts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("artifacts are content addressed", testArtifactsAreContentAddressed)
+	t.Run("derive error is returned", testArtifactsDeriveErrIsReturned)
+	t.Run("source error is returned", testArtifactsSourceErrIsReturned)
+}
+
+func testArtifactsAreContentAddressed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewArtifacts(cache, xcache.ArtifactsConfig{
+			KeyPrefix: "test-artifact-",
+			TTL:       time.Minute,
+		})
+		ctx       = context.Background()
+		source    = []byte("some image content")
+		deriveCnt int
+		deriveFn  = func(_ context.Context, src []byte) ([]byte, error) {
+			deriveCnt++
+
+			return bytes.ToUpper(src), nil
+		}
+		expected = []byte("SOME IMAGE CONTENT")
+	)
+
+	// act
+	result1, err1 := subject.Get(ctx, "thumb-200x200", bytes.NewReader(source), deriveFn)
+	result2, err2 := subject.Get(ctx, "thumb-200x200", strings.NewReader(string(source)), deriveFn) // same content
+	_, err3 := subject.Get(ctx, "thumb-100x100", bytes.NewReader(source), deriveFn)                 // other transformation
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertNil(t, err3)
+	assertEqual(t, expected, result1)
+	assertEqual(t, expected, result2)
+	assertEqual(t, 2, deriveCnt)
+	key, err := subject.Key("thumb-200x200", bytes.NewReader(source))
+	requireNil(t, err)
+	assertTrue(t, strings.HasPrefix(key, "test-artifact-thumb-200x200:"))
+	cached, err := cache.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, expected, cached)
+}
+
+func testArtifactsDeriveErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewArtifacts(cache, xcache.ArtifactsConfig{})
+		expectedErr = errors.New("intentionally triggered derive error")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, xcache.ErrNotFound
+	})
+
+	// act
+	result, err := subject.Get(
+		context.Background(),
+		"thumb",
+		strings.NewReader("content"),
+		func(context.Context, []byte) ([]byte, error) {
+			return nil, expectedErr
+		},
+	)
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, result)
+	assertEqual(t, 0, cache.SaveCallsCount())
+}
+
+func testArtifactsSourceErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewArtifacts(cache, xcache.ArtifactsConfig{})
+		expectedErr = errors.New("intentionally triggered source error")
+	)
+
+	// act
+	_, errGet := subject.Get(
+		context.Background(),
+		"thumb",
+		iotest.ErrReader(expectedErr),
+		func(context.Context, []byte) ([]byte, error) {
+			return nil, nil
+		},
+	)
+	_, errKey := subject.Key("thumb", io.MultiReader(strings.NewReader("a"), iotest.ErrReader(expectedErr)))
+
+	// assert
+	assertTrue(t, errors.Is(errGet, expectedErr))
+	assertTrue(t, errors.Is(errKey, expectedErr))
+	assertEqual(t, 0, cache.LoadCallsCount())
+}
@@ -0,0 +1,263 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// AzureBlobExpiresAtMetadata is the blob metadata key the expiration moment is stored under,
+// in unix milliseconds. A blob without it does not expire.
+// Note: Azure metadata names must be valid C# identifiers, hence the underscores.
+const AzureBlobExpiresAtMetadata = "xcache_expires_at"
+
+// AzureBlobItem is a blob, as stored into an Azure Storage container.
+type AzureBlobItem struct {
+	// Body is the blob's content (the value). It's not set by AzureBlobClient's GetProperties.
+	Body []byte
+	// Metadata is the blob's metadata.
+	Metadata map[string]string
+	// ETag is the blob's ETag, set by AzureBlobClient's Download / GetProperties.
+	ETag string
+}
+
+// AzureBlobCondition is the (access) condition of an upload / delete of a blob.
+// The zero value means no condition.
+type AzureBlobCondition struct {
+	// IfNotExists conditions the operation on the blob not existing (If-None-Match: *).
+	IfNotExists bool
+	// IfMatch, if not empty, conditions the operation on the blob's ETag (If-Match).
+	IfMatch string
+}
+
+// AzureBlobClient is the subset of Azure Blob Storage operations an AzureBlob cache is built upon.
+// When a condition is not met, ErrConditionFailed is returned.
+//
+// An azure-sdk-for-go (azblob) based implementation is along these lines:
+//
+//	type azureBlobClient struct{ az *azblob.Client }
+//
+//	func (c azureBlobClient) Download(ctx context.Context, container, blob string) (xcache.AzureBlobItem, error) {
+//		resp, err := c.az.DownloadStream(ctx, container, blob, nil)
+//		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+//			return xcache.AzureBlobItem{}, xcache.ErrNotFound
+//		} else if err != nil {
+//			return xcache.AzureBlobItem{}, err
+//		}
+//		defer resp.Body.Close()
+//		item := xcache.AzureBlobItem{Metadata: make(map[string]string), ETag: string(*resp.ETag)}
+//		for name, value := range resp.Metadata {
+//			item.Metadata[strings.ToLower(name)] = *value
+//		}
+//		item.Body, err = io.ReadAll(resp.Body)
+//
+//		return item, err
+//	}
+//
+//	func (c azureBlobClient) Upload(
+//		ctx context.Context,
+//		container, blob string,
+//		item xcache.AzureBlobItem,
+//		cond xcache.AzureBlobCondition,
+//	) error {
+//		opts := &azblob.UploadBufferOptions{Metadata: make(map[string]*string)}
+//		for name, value := range item.Metadata {
+//			opts.Metadata[name] = to.Ptr(value)
+//		}
+//		if cond.IfNotExists {
+//			opts.AccessConditions = &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{
+//				IfNoneMatch: to.Ptr(azcore.ETagAny),
+//			}}
+//		} else if cond.IfMatch != "" {
+//			opts.AccessConditions = &blob.AccessConditions{ModifiedAccessConditions: &blob.ModifiedAccessConditions{
+//				IfMatch: to.Ptr(azcore.ETag(cond.IfMatch)),
+//			}}
+//		}
+//		_, err := c.az.UploadBuffer(ctx, container, blob, item.Body, opts)
+//		if bloberror.HasCode(err, bloberror.ConditionNotMet, bloberror.BlobAlreadyExists) {
+//			return xcache.ErrConditionFailed
+//		}
+//
+//		return err
+//	}
+//
+// GetProperties and Delete are similar (blob client's GetProperties / Delete, BlobNotFound being
+// mapped to ErrNotFound, respectively ignored).
+type AzureBlobClient interface {
+	// Download returns the blob with given name, or ErrNotFound, if it does not exist.
+	Download(ctx context.Context, container, blob string) (AzureBlobItem, error)
+	// GetProperties returns the blob with given name, without its body, or ErrNotFound, if it does not exist.
+	GetProperties(ctx context.Context, container, blob string) (AzureBlobItem, error)
+	// Upload stores (overwrites) the blob with given name, if given condition is met.
+	Upload(ctx context.Context, container, blob string, item AzureBlobItem, cond AzureBlobCondition) error
+	// Delete deletes the blob with given name (not existing is not an error), if given condition is met.
+	Delete(ctx context.Context, container, blob string, cond AzureBlobCondition) error
+}
+
+// AzureBlobConfig holds the settings of an AzureBlob cache.
+type AzureBlobConfig struct {
+	// Container is the container the blobs are stored into.
+	Container string
+	// Prefix is prepended to each key, isolating the cache's blobs from other container data.
+	// By default (""), it's "xcache/".
+	Prefix string
+}
+
+// AzureBlob is a Cache backed by an Azure Blob Storage container, the Azure counterpart of S3 / GCS:
+// for very large, infrequently changing values, meant to be the last layer of a Multi cache.
+// A key's expiration moment is stored in the blob's metadata (see AzureBlobExpiresAtMetadata); expired blobs
+// are never returned, and they are deleted lazily, when they are loaded (conditioned on their ETag,
+// so that a blob re-uploaded meanwhile is not deleted).
+// Expired blobs which are never loaded again are not deleted, configure a lifecycle management rule
+// on the container's prefix (with an age greater than your longest TTL) to reclaim them.
+//
+// Example:
+//
+//	client, err := azblob.NewClient(serviceURL, credential, nil)
+//	if err != nil {
+//		return err
+//	}
+//	blobCache := xcache.NewAzureBlob(azureBlobClient{client}, xcache.AzureBlobConfig{Container: "app-cache"})
+//	cache := xcache.NewMulti(memCache, redisCache, blobCache)
+type AzureBlob struct {
+	client    AzureBlobClient
+	container string
+	prefix    string
+	hits      int64
+	misses    int64
+}
+
+// NewAzureBlob instantiates a new AzureBlob cache upon given client, according to given settings.
+func NewAzureBlob(client AzureBlobClient, config AzureBlobConfig) *AzureBlob {
+	if config.Prefix == "" {
+		config.Prefix = "xcache/"
+	}
+
+	return &AzureBlob{
+		client:    client,
+		container: config.Container,
+		prefix:    config.Prefix,
+	}
+}
+
+// Save stores (overwrites) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *AzureBlob) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	return cache.client.Upload(ctx, cache.container, cache.prefix+key, azureBlobItem(value, expire), AzureBlobCondition{})
+}
+
+// SaveIfAbsent stores the given key-value with expiration period, only if the key does not exist
+// (or it's expired), through conditional uploads.
+// It returns true if the key was saved, or an error if something bad happened.
+func (cache *AzureBlob) SaveIfAbsent(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) (bool, error) {
+	item := azureBlobItem(value, expire)
+	err := cache.client.Upload(ctx, cache.container, cache.prefix+key, item, AzureBlobCondition{IfNotExists: true})
+	if !errors.Is(err, ErrConditionFailed) {
+		return err == nil, err
+	}
+
+	// the blob exists, overwrite it if it's expired (and it was not re-uploaded meanwhile).
+	props, err := cache.client.GetProperties(ctx, cache.container, cache.prefix+key)
+	if errors.Is(err, ErrNotFound) { // deleted meanwhile.
+		props.ETag = ""
+	} else if err != nil {
+		return false, err
+	} else if !isBlobExpired(props.Metadata, AzureBlobExpiresAtMetadata) {
+		return false, nil
+	}
+	cond := AzureBlobCondition{IfMatch: props.ETag, IfNotExists: props.ETag == ""}
+	err = cache.client.Upload(ctx, cache.container, cache.prefix+key, item, cond)
+	if errors.Is(err, ErrConditionFailed) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+// An expired blob is deleted.
+func (cache *AzureBlob) Load(ctx context.Context, key string) ([]byte, error) {
+	item, err := cache.client.Download(ctx, cache.container, cache.prefix+key)
+	if err == nil && isBlobExpired(item.Metadata, AzureBlobExpiresAtMetadata) {
+		// lazy deletion, best effort.
+		_ = cache.client.Delete(ctx, cache.container, cache.prefix+key, AzureBlobCondition{IfMatch: item.ETag})
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return item.Body, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the read fails.
+// If the key is not found (or it's expired), a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *AzureBlob) TTL(ctx context.Context, key string) (time.Duration, error) {
+	props, err := cache.client.GetProperties(ctx, cache.container, cache.prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	return blobTTL(props.Metadata, AzureBlobExpiresAtMetadata), nil
+}
+
+// Stats returns the hits and misses since the cache was instantiated.
+// The no. of keys and memory are not reported, as listing a container is not a cheap operation
+// (use the storage account's metrics instead).
+func (cache *AzureBlob) Stats(_ context.Context) (Stats, error) {
+	return Stats{
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *AzureBlob) Delete(ctx context.Context, key string) error {
+	return cache.client.Delete(ctx, cache.container, cache.prefix+key, AzureBlobCondition{})
+}
+
+// azureBlobItem returns the blob storing given value with expiration period.
+func azureBlobItem(value []byte, expire time.Duration) AzureBlobItem {
+	item := AzureBlobItem{Body: value, Metadata: map[string]string{}}
+	if expire > 0 {
+		expiresAt := time.Now().Add(expire).UnixMilli()
+		item.Metadata[AzureBlobExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+	}
+	if item.Body == nil {
+		item.Body = []byte{}
+	}
+
+	return item
+}
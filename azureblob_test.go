@@ -0,0 +1,281 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.AzureBlob)(nil)         // test AzureBlob is a Cache
+	var _ xcache.Deleter = (*xcache.AzureBlob)(nil)       // test AzureBlob is a Deleter
+	var _ xcache.AzureBlobClient = (*azureContainer)(nil) // test azureContainer is an AzureBlobClient
+}
+
+func TestAzureBlob(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testAzureBlobSaveLoadTTLDelete)
+	t.Run("expired blobs are deleted lazily", testAzureBlobExpiredBlobsAreDeletedLazily)
+	t.Run("save if absent", testAzureBlobSaveIfAbsent)
+	t.Run("client errors are returned", testAzureBlobClientErrorsAreReturned)
+}
+
+func testAzureBlobSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		container = newAzureContainer()
+		subject   = xcache.NewAzureBlob(container, xcache.AzureBlobConfig{Container: "test-container", Prefix: "blobs/"})
+		ctx       = context.Background()
+		key       = "test-azureblob-key"
+		value     = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	item := container.Blob("test-container/blobs/" + key)
+	assertEqual(t, value, item.Body)
+	assertTrue(t, item.Metadata[xcache.AzureBlobExpiresAtMetadata] != "")
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	requireNil(t, subject.Delete(ctx, key))
+	assertEqual(t, 0, container.Len())
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+}
+
+func testAzureBlobExpiredBlobsAreDeletedLazily(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		container = newAzureContainer()
+		subject   = xcache.NewAzureBlob(container, xcache.AzureBlobConfig{Container: "test-container"})
+		ctx       = context.Background()
+		key       = "test-azureblob-expired-key"
+		path      = "test-container/xcache/" + key
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), time.Minute))
+	container.Expire(path)
+
+	// act
+	ttl, errTTL := subject.TTL(ctx, key)
+	lenBeforeLoad := container.Len()
+	_, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+	assertEqual(t, 1, lenBeforeLoad)
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertEqual(t, 0, container.Len())
+}
+
+func testAzureBlobSaveIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		container = newAzureContainer()
+		subject   = xcache.NewAzureBlob(container, xcache.AzureBlobConfig{Container: "test-container"})
+		ctx       = context.Background()
+		key       = "test-azureblob-absent-key"
+		path      = "test-container/xcache/" + key
+	)
+
+	// act & assert
+	saved, err := subject.SaveIfAbsent(ctx, key, []byte("value 1"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 2"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	assertEqual(t, []byte("value 1"), container.Blob(path).Body)
+
+	container.Expire(path)
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 3"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+	assertEqual(t, []byte("value 3"), container.Blob(path).Body)
+
+	container.Expire(path)
+	container.beforeUpload = func() { // another uploader wins the race.
+		container.beforeUpload = nil
+		requireNil(t, subject.Save(ctx, key, []byte("value 4"), time.Minute))
+	}
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 5"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	assertEqual(t, []byte("value 4"), container.Blob(path).Body)
+}
+
+func testAzureBlobClientErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		container = newAzureContainer()
+		subject   = xcache.NewAzureBlob(container, xcache.AzureBlobConfig{Container: "test-container"})
+		ctx       = context.Background()
+		key       = "test-azureblob-error-key"
+		errAzure  = errors.New("server busy")
+	)
+	container.err = errAzure
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errSaveIfAbsent := subject.SaveIfAbsent(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	errDelete := subject.Delete(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errSave, errAzure))
+	assertTrue(t, errors.Is(errSaveIfAbsent, errAzure))
+	assertTrue(t, errors.Is(errLoad, errAzure))
+	assertTrue(t, errors.Is(errTTL, errAzure))
+	assertTrue(t, errors.Is(errDelete, errAzure))
+}
+
+// azureContainer is an in memory xcache.AzureBlobClient, blobs being stored by "container/blob",
+// with a new ETag at each upload.
+// Note: it's not safe for concurrent use, when beforeUpload is set.
+type azureContainer struct {
+	blobs        map[string]xcache.AzureBlobItem
+	version      int
+	beforeUpload func() // called before a conditional upload, if set.
+	err          error
+	mu           sync.Mutex
+}
+
+func newAzureContainer() *azureContainer {
+	return &azureContainer{blobs: make(map[string]xcache.AzureBlobItem)}
+}
+
+func (c *azureContainer) Download(_ context.Context, container, blob string) (xcache.AzureBlobItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return xcache.AzureBlobItem{}, c.err
+	}
+	item, found := c.blobs[container+"/"+blob]
+	if !found {
+		return xcache.AzureBlobItem{}, xcache.ErrNotFound
+	}
+
+	return item, nil
+}
+
+func (c *azureContainer) GetProperties(ctx context.Context, container, blob string) (xcache.AzureBlobItem, error) {
+	item, err := c.Download(ctx, container, blob)
+	item.Body = nil
+
+	return item, err
+}
+
+func (c *azureContainer) Upload(
+	_ context.Context,
+	container, blob string,
+	item xcache.AzureBlobItem,
+	cond xcache.AzureBlobCondition,
+) error {
+	if c.beforeUpload != nil && cond != (xcache.AzureBlobCondition{}) {
+		c.beforeUpload()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	if !c.isMet(container+"/"+blob, cond) {
+		return xcache.ErrConditionFailed
+	}
+	c.version++
+	item.ETag = "0x" + strconv.Itoa(c.version)
+	c.blobs[container+"/"+blob] = item
+
+	return nil
+}
+
+func (c *azureContainer) Delete(_ context.Context, container, blob string, cond xcache.AzureBlobCondition) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	if !c.isMet(container+"/"+blob, cond) {
+		return xcache.ErrConditionFailed
+	}
+	delete(c.blobs, container+"/"+blob)
+
+	return nil
+}
+
+// isMet returns whether given condition is met by the blob with given path.
+func (c *azureContainer) isMet(path string, cond xcache.AzureBlobCondition) bool {
+	item, found := c.blobs[path]
+	if cond.IfNotExists {
+		return !found
+	}
+
+	return cond.IfMatch == "" || (found && item.ETag == cond.IfMatch)
+}
+
+func (c *azureContainer) Blob(path string) xcache.AzureBlobItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.blobs[path]
+}
+
+// Expire makes the blob with given path expired.
+func (c *azureContainer) Expire(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(-time.Second).UnixMilli()
+	c.blobs[path].Metadata[xcache.AzureBlobExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+}
+
+func (c *azureContainer) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.blobs)
+}
@@ -0,0 +1,46 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Command benchfmt reads the benchmark suite's `go test -bench` output from stdin,
+// and writes its results as a markdown table or as JSON to stdout.
+//
+// Usage:
+//
+//	go test -run=^$ -bench=. -benchmem ./benchmarks | go run ./benchmarks/cmd/benchfmt -format=json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/actforgood/xcache/benchmarks"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format: markdown / json")
+	flag.Parse()
+
+	if err := run(*format); err != nil {
+		fmt.Fprintln(os.Stderr, "benchfmt:", err)
+		os.Exit(1)
+	}
+}
+
+func run(format string) error {
+	results, err := benchmarks.ParseResults(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "markdown":
+		return benchmarks.WriteMarkdown(os.Stdout, results)
+	case "json":
+		return benchmarks.WriteJSON(os.Stdout, results)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
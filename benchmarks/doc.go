@@ -0,0 +1,20 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package benchmarks holds a benchmark suite which runs a standard workloads matrix
+// (load hit, load miss, save, mixed 90% load / 10% save) against Memory, Redis, Multi and common decorator stacks,
+// and a results formatter, so that configurations can be compared and performance regressions caught.
+//
+// The suite is BenchmarkSuite, its results are named "BenchmarkSuite/<stack>/<workload>".
+// Redis stacks run by default against an embedded Redis server (miniredis);
+// a real Redis server can be used by setting XCACHE_BENCH_REDIS_ADDR environment variable (like "127.0.0.1:6379").
+//
+// Example of running the suite and formatting its results as a markdown table
+// (the first stack is the baseline other stacks are compared with):
+//
+//	go test -run=^$ -bench=. -benchmem ./benchmarks | go run ./benchmarks/cmd/benchfmt -format=markdown
+//
+// See ParseResults, WriteMarkdown and WriteJSON for processing the results programmatically.
+package benchmarks
@@ -0,0 +1,174 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package benchmarks
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// suiteBenchmarkPrefix is the name prefix of the suite's benchmarks results.
+const suiteBenchmarkPrefix = "BenchmarkSuite/"
+
+// Result is the result of a suite's benchmark: a workload run against a cache stack.
+type Result struct {
+	// Stack is the name of the cache stack (backend and its decorators, like "Compressed+Memory").
+	Stack string `json:"stack"`
+	// Workload is the name of the workload (like "load-hit").
+	Workload string `json:"workload"`
+	// Iterations is the no. of iterations the benchmark ran.
+	Iterations int64 `json:"iterations"`
+	// NsPerOp is the no. of nanoseconds an operation took.
+	NsPerOp float64 `json:"nsPerOp"`
+	// BytesPerOp is the no. of bytes allocated per operation (reported with -benchmem).
+	BytesPerOp int64 `json:"bytesPerOp"`
+	// AllocsPerOp is the no. of allocations per operation (reported with -benchmem).
+	AllocsPerOp int64 `json:"allocsPerOp"`
+	// Ratio is the ratio between NsPerOp and the NsPerOp of the baseline stack (the first one) for the same workload.
+	// 0 if there is no baseline result.
+	Ratio float64 `json:"ratio,omitempty"`
+}
+
+// ParseResults reads `go test -bench` output, and returns the suite's benchmarks results
+// (other lines and benchmarks are disregarded), in their order, with their Ratio computed.
+// If a benchmark ran multiple times (-count), its last result is kept.
+func ParseResults(r io.Reader) ([]Result, error) {
+	var (
+		results []Result
+		indexes = make(map[string]int) // stack + workload => result index.
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		result, ok := parseResultLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		id := result.Stack + "\x00" + result.Workload
+		if idx, found := indexes[id]; found {
+			results[idx] = result
+
+			continue
+		}
+		indexes[id] = len(results)
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	computeRatios(results)
+
+	return results, nil
+}
+
+// parseResultLine parses a benchmark result line, like:
+//
+//	BenchmarkSuite/Memory/load-hit-8    1000000    1050 ns/op    40 B/op    2 allocs/op
+func parseResultLine(line string) (Result, bool) {
+	if !strings.HasPrefix(line, suiteBenchmarkPrefix) {
+		return Result{}, false
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[3] != "ns/op" {
+		return Result{}, false
+	}
+	name := strings.TrimPrefix(fields[0], suiteBenchmarkPrefix)
+	if idx := strings.LastIndexByte(name, '-'); idx > 0 { // GOMAXPROCS suffix.
+		if _, err := strconv.Atoi(name[idx+1:]); err == nil {
+			name = name[:idx]
+		}
+	}
+	stack, workload, found := strings.Cut(name, "/")
+	if !found {
+		return Result{}, false
+	}
+
+	var (
+		result = Result{Stack: stack, Workload: workload}
+		err    error
+	)
+	if result.Iterations, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return Result{}, false
+	}
+	if result.NsPerOp, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return Result{}, false
+	}
+	for idx := 4; idx+1 < len(fields); idx += 2 {
+		value, err := strconv.ParseInt(fields[idx], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[idx+1] {
+		case "B/op":
+			result.BytesPerOp = value
+		case "allocs/op":
+			result.AllocsPerOp = value
+		}
+	}
+
+	return result, true
+}
+
+// computeRatios sets the ratios between results' NsPerOp and the baseline's ones.
+func computeRatios(results []Result) {
+	if len(results) == 0 {
+		return
+	}
+	baseline := results[0].Stack
+	baselineNs := make(map[string]float64)
+	for _, result := range results {
+		if result.Stack == baseline {
+			baselineNs[result.Workload] = result.NsPerOp
+		}
+	}
+	for idx := range results {
+		if ns := baselineNs[results[idx].Workload]; ns > 0 {
+			results[idx].Ratio = results[idx].NsPerOp / ns
+		}
+	}
+}
+
+// WriteMarkdown writes given results as a markdown table, grouped by workload.
+func WriteMarkdown(w io.Writer, results []Result) error {
+	var workloads []string
+	byWorkload := make(map[string][]Result)
+	for _, result := range results {
+		if _, found := byWorkload[result.Workload]; !found {
+			workloads = append(workloads, result.Workload)
+		}
+		byWorkload[result.Workload] = append(byWorkload[result.Workload], result)
+	}
+
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("| Workload | Stack | ns/op | B/op | allocs/op | vs baseline |\n")
+	_, _ = bw.WriteString("|---|---|---:|---:|---:|---:|\n")
+	for _, workload := range workloads {
+		for _, result := range byWorkload[workload] {
+			ratio := "-"
+			if result.Ratio > 0 {
+				ratio = fmt.Sprintf("x%.2f", result.Ratio)
+			}
+			_, _ = fmt.Fprintf(bw, "| %s | %s | %.1f | %d | %d | %s |\n",
+				result.Workload, result.Stack, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp, ratio)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// WriteJSON writes given results as a JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(results)
+}
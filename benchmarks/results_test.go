@@ -0,0 +1,125 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package benchmarks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xcache/benchmarks"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: github.com/actforgood/xcache/benchmarks
+BenchmarkSuite/Memory/load-hit-8         	 5000000	       200.0 ns/op	    1024 B/op	       1 allocs/op
+BenchmarkSuite/Memory/save-8             	 3000000	       400 ns/op	       0 B/op	       0 allocs/op
+BenchmarkSuite/Redis7/load-hit-8         	  100000	     10000 ns/op	    1300 B/op	      10 allocs/op
+BenchmarkSuite/Redis7/save-8             	  100000	     12000 ns/op
+BenchmarkSuite/Multi(Memory,Redis7)/load-hit	 4000000	       300 ns/op	    1024 B/op	       2 allocs/op
+BenchmarkOther-8                         	 1000000	      1000 ns/op
+--- FAIL: BenchmarkSuite/Memory/load-miss
+PASS
+ok  	github.com/actforgood/xcache/benchmarks	12.345s
+`
+
+func TestParseResults(t *testing.T) {
+	t.Parallel()
+
+	// act
+	results, err := benchmarks.ParseResults(strings.NewReader(benchOutput))
+
+	// assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []benchmarks.Result{
+		{
+			Stack:       "Memory",
+			Workload:    "load-hit",
+			Iterations:  5000000,
+			NsPerOp:     200,
+			BytesPerOp:  1024,
+			AllocsPerOp: 1,
+			Ratio:       1,
+		},
+		{Stack: "Memory", Workload: "save", Iterations: 3000000, NsPerOp: 400, Ratio: 1},
+		{
+			Stack:       "Redis7",
+			Workload:    "load-hit",
+			Iterations:  100000,
+			NsPerOp:     10000,
+			BytesPerOp:  1300,
+			AllocsPerOp: 10,
+			Ratio:       50,
+		},
+		{Stack: "Redis7", Workload: "save", Iterations: 100000, NsPerOp: 12000, Ratio: 30},
+		{
+			Stack:       "Multi(Memory,Redis7)",
+			Workload:    "load-hit",
+			Iterations:  4000000,
+			NsPerOp:     300,
+			BytesPerOp:  1024,
+			AllocsPerOp: 2,
+			Ratio:       1.5,
+		},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Errorf("expected %+v, but got %+v", expected, results)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	results, _ := benchmarks.ParseResults(strings.NewReader(benchOutput))
+	var buf bytes.Buffer
+
+	// act
+	err := benchmarks.WriteMarkdown(&buf, results)
+
+	// assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "| Workload | Stack | ns/op | B/op | allocs/op | vs baseline |\n" +
+		"|---|---|---:|---:|---:|---:|\n" +
+		"| load-hit | Memory | 200.0 | 1024 | 1 | x1.00 |\n" +
+		"| load-hit | Redis7 | 10000.0 | 1300 | 10 | x50.00 |\n" +
+		"| load-hit | Multi(Memory,Redis7) | 300.0 | 1024 | 2 | x1.50 |\n" +
+		"| save | Memory | 400.0 | 0 | 0 | x1.00 |\n" +
+		"| save | Redis7 | 12000.0 | 0 | 0 | x30.00 |\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	results, _ := benchmarks.ParseResults(strings.NewReader(benchOutput))
+	var buf bytes.Buffer
+
+	// act
+	err := benchmarks.WriteJSON(&buf, results)
+
+	// assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []benchmarks.Result
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(results, decoded) {
+		t.Errorf("expected %+v, but got %+v", results, decoded)
+	}
+}
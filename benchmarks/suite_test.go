@@ -0,0 +1,221 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package benchmarks_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+const (
+	// suiteKeys is the no. of keys the workloads operate on.
+	suiteKeys = 1024
+	// suiteMemSize is the memory size of the Memory caches.
+	suiteMemSize = 32 * 1024 * 1024
+	// suiteExpire is the expiration period of the saved keys.
+	suiteExpire = 10 * time.Minute
+	// suiteRedisAddrEnv is the environment variable holding the address of a real Redis server to be used.
+	suiteRedisAddrEnv = "XCACHE_BENCH_REDIS_ADDR"
+)
+
+// suiteStack is a cache stack the workloads run against.
+type suiteStack struct {
+	name string
+	new  func(b *testing.B) xcache.Cache
+}
+
+// suiteWorkload is a workload run against a cache stack.
+type suiteWorkload struct {
+	name string
+	run  func(b *testing.B, cache xcache.Cache)
+}
+
+// suiteStacks are the cache stacks the workloads run against. The first one is the baseline.
+var suiteStacks = [...]suiteStack{
+	{
+		name: "Memory",
+		new: func(*testing.B) xcache.Cache {
+			return xcache.NewMemory(suiteMemSize)
+		},
+	},
+	{
+		name: "Metered+Memory",
+		new: func(*testing.B) xcache.Cache {
+			return xcache.NewMetered(xcache.NewMemory(suiteMemSize))
+		},
+	},
+	{
+		name: "Compressed+Memory",
+		new: func(*testing.B) xcache.Cache {
+			return xcache.NewCompressed(xcache.NewMemory(suiteMemSize), xcache.NewGzipCompressor(gzip.BestSpeed))
+		},
+	},
+	{
+		name: "TimeToIdle+Memory",
+		new: func(*testing.B) xcache.Cache {
+			return xcache.NewTimeToIdle(xcache.NewMemory(suiteMemSize), time.Minute)
+		},
+	},
+	{
+		name: "Redis7",
+		new: func(b *testing.B) xcache.Cache {
+			return newSuiteRedis7(b)
+		},
+	},
+	{
+		name: "Multi(Memory,Redis7)",
+		new: func(b *testing.B) xcache.Cache {
+			return xcache.NewMulti(xcache.NewMemory(suiteMemSize), newSuiteRedis7(b))
+		},
+	},
+	{
+		name: "Metered+Multi(Memory,Redis7)",
+		new: func(b *testing.B) xcache.Cache {
+			return xcache.NewMetered(xcache.NewMulti(xcache.NewMemory(suiteMemSize), newSuiteRedis7(b)))
+		},
+	},
+}
+
+// suiteWorkloads are the workloads run against each cache stack.
+var suiteWorkloads = [...]suiteWorkload{
+	{name: "load-hit", run: runLoadHitWorkload},
+	{name: "load-miss", run: runLoadMissWorkload},
+	{name: "save", run: runSaveWorkload},
+	{name: "mixed", run: runMixedWorkload},
+}
+
+// BenchmarkSuite runs the workloads matrix against the cache stacks.
+// Its output can be formatted with benchfmt command.
+func BenchmarkSuite(b *testing.B) {
+	for _, stack := range suiteStacks {
+		stack := stack // capture range variable
+		b.Run(stack.name, func(b *testing.B) {
+			for _, workload := range suiteWorkloads {
+				workload := workload // capture range variable
+				b.Run(workload.name, func(b *testing.B) {
+					cache := stack.new(b)
+					workload.run(b, cache)
+				})
+			}
+		})
+	}
+}
+
+// newSuiteRedis7 returns a Redis7 connected to the server from XCACHE_BENCH_REDIS_ADDR environment variable,
+// if set, or to an embedded Redis server, otherwise.
+func newSuiteRedis7(b *testing.B) *xcache.Redis7 {
+	b.Helper()
+
+	if addr := os.Getenv(suiteRedisAddrEnv); addr != "" {
+		cache := xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{addr}})
+		b.Cleanup(func() { _ = cache.Close() })
+
+		return cache
+	}
+
+	return xcachetest.NewMiniRedis(b).NewRedis7(b)
+}
+
+func runLoadHitWorkload(b *testing.B, cache xcache.Cache) {
+	ctx, keys := context.Background(), suiteKeyNames("hit")
+	populate(b, cache, keys)
+
+	runParallel(b, func(i uint64) {
+		if _, err := cache.Load(ctx, keys[i%suiteKeys]); err != nil {
+			b.Error(err)
+		}
+	})
+}
+
+func runLoadMissWorkload(b *testing.B, cache xcache.Cache) {
+	ctx, keys := context.Background(), suiteKeyNames("miss")
+
+	runParallel(b, func(i uint64) {
+		_, _ = cache.Load(ctx, keys[i%suiteKeys])
+	})
+}
+
+func runSaveWorkload(b *testing.B, cache xcache.Cache) {
+	ctx, keys, value := context.Background(), suiteKeyNames("save"), suiteValue()
+
+	runParallel(b, func(i uint64) {
+		if err := cache.Save(ctx, keys[i%suiteKeys], value, suiteExpire); err != nil {
+			b.Error(err)
+		}
+	})
+}
+
+// runMixedWorkload runs 90% loads (of existing keys) and 10% saves.
+func runMixedWorkload(b *testing.B, cache xcache.Cache) {
+	ctx, keys, value := context.Background(), suiteKeyNames("mixed"), suiteValue()
+	populate(b, cache, keys)
+
+	runParallel(b, func(i uint64) {
+		key := keys[i%suiteKeys]
+		if i%10 == 0 {
+			if err := cache.Save(ctx, key, value, suiteExpire); err != nil {
+				b.Error(err)
+			}
+
+			return
+		}
+		if _, err := cache.Load(ctx, key); err != nil {
+			b.Error(err)
+		}
+	})
+}
+
+// runParallel runs given operation in parallel, b.N times in total, passing it the operation's counter.
+func runParallel(b *testing.B, op func(i uint64)) {
+	var counter uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			op(atomic.AddUint64(&counter, 1))
+		}
+	})
+}
+
+// populate saves given keys into cache.
+func populate(b *testing.B, cache xcache.Cache, keys []string) {
+	b.Helper()
+
+	ctx, value := context.Background(), suiteValue()
+	for _, key := range keys {
+		if err := cache.Save(ctx, key, value, suiteExpire); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// suiteKeyNames returns the keys a workload operates on.
+func suiteKeyNames(prefix string) []string {
+	keys := make([]string, suiteKeys)
+	for i := range keys {
+		keys[i] = "xcache-bench-" + prefix + "-" + strconv.Itoa(i)
+	}
+
+	return keys
+}
+
+// suiteValue returns a ~1KB, compressible (JSON like), value.
+func suiteValue() []byte {
+	record := []byte(`{"id":123456,"name":"product name","price":99.99,"stock":42},`)
+
+	return bytes.Repeat(record, 1024/len(record)+1)
+}
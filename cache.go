@@ -38,3 +38,205 @@ type Cache interface {
 	// It returns an error if something goes wrong.
 	Stats(context.Context) (Stats, error)
 }
+
+// Unwrapper is implemented by Cache decorators, which expose the cache they decorate.
+type Unwrapper interface {
+	// Unwrap returns the decorated cache.
+	Unwrap() Cache
+}
+
+// As finds the first cache in the decorators chain of given cache (starting with the cache itself)
+// which is of type T, see Unwrapper. It returns false if no such cache is found.
+// It's useful for reaching an underlying cache regardless of how many decorators are stacked on top of it.
+//
+// Example:
+//
+//	if redisCache, ok := xcache.As[*xcache.Redis7](cache); ok {
+//		redisCache.AddHook(tracingHook)
+//	}
+func As[T any](cache Cache) (T, bool) {
+	for cache != nil {
+		if target, ok := cache.(T); ok {
+			return target, true
+		}
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			break
+		}
+		cache = unwrapper.Unwrap()
+	}
+	var zero T
+
+	return zero, false
+}
+
+// Deleter is implemented by caches which can delete a key explicitly
+// (an alternative to saving it with a negative expiration period).
+type Deleter interface {
+	// Delete deletes the given key. Deleting a key which does not exist is not an error.
+	// It returns an error if the key could not be deleted.
+	Delete(ctx context.Context, key string) error
+}
+
+// deleteKey deletes the given key from given cache, with Delete, if the cache is a Deleter,
+// otherwise by saving it with a negative expiration period.
+func deleteKey(ctx context.Context, cache Cache, key string) error {
+	if deleter, ok := cache.(Deleter); ok {
+		return deleter.Delete(ctx, key)
+	}
+
+	return cache.Save(ctx, key, nil, -1)
+}
+
+// BulkDeleter is implemented by caches which can delete multiple keys at once, saving round trips.
+type BulkDeleter interface {
+	// DeleteMany deletes the given keys. Deleting keys which do not exist is not an error.
+	// It returns an error if the keys could not be deleted.
+	DeleteMany(ctx context.Context, keys ...string) error
+}
+
+// deleteKeys deletes the given keys from given cache, with DeleteMany, if the cache is a BulkDeleter,
+// otherwise one by one, see deleteKey.
+func deleteKeys(ctx context.Context, cache Cache, keys ...string) error {
+	if bulkDeleter, ok := cache.(BulkDeleter); ok {
+		return bulkDeleter.DeleteMany(ctx, keys...)
+	}
+	for _, key := range keys {
+		if err := deleteKey(ctx, cache, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExistenceChecker is implemented by caches which can check if a key exists, without transferring its value.
+type ExistenceChecker interface {
+	// Has returns true if the given key exists, or an error if something bad happened.
+	Has(ctx context.Context, key string) (bool, error)
+}
+
+// hasKey checks if the given key exists in given cache, with Has, if the cache is an ExistenceChecker,
+// otherwise with TTL.
+func hasKey(ctx context.Context, cache Cache, key string) (bool, error) {
+	if checker, ok := cache.(ExistenceChecker); ok {
+		return checker.Has(ctx, key)
+	}
+	ttl, err := cache.TTL(ctx, key)
+
+	return err == nil && ttl >= 0, err
+}
+
+// Item is a value to be saved, with its expiration period.
+type Item struct {
+	// Value is the value to be saved.
+	Value []byte
+	// Expire is the expiration period. 0 (NoExpire) means no expiration, a negative one triggers deletion of key.
+	Expire time.Duration
+}
+
+// Batcher is implemented by caches which can save / load multiple keys at once, saving round trips.
+type Batcher interface {
+	// SaveMany stores the given items (key => item).
+	// It returns an error if any of the items could not be saved.
+	SaveMany(ctx context.Context, items map[string]Item) error
+
+	// LoadMany returns the values of given keys (key => value).
+	// Keys not found are missing from the returned map.
+	// It returns an error if something bad happened.
+	LoadMany(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// saveMany stores the given items into given cache, with SaveMany, if the cache is a Batcher,
+// otherwise one by one.
+func saveMany(ctx context.Context, cache Cache, items map[string]Item) error {
+	if batcher, ok := cache.(Batcher); ok {
+		return batcher.SaveMany(ctx, items)
+	}
+	for key, item := range items {
+		if err := cache.Save(ctx, key, item.Value, item.Expire); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadMany returns the values of given keys from given cache, with LoadMany, if the cache is a Batcher,
+// otherwise one by one.
+func loadMany(ctx context.Context, cache Cache, keys []string) (map[string][]byte, error) {
+	if batcher, ok := cache.(Batcher); ok {
+		return batcher.LoadMany(ctx, keys)
+	}
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		value, err := cache.Load(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return values, err
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// PrefixDeleter is implemented by caches which can delete all keys sharing a prefix.
+type PrefixDeleter interface {
+	// DeletePrefix deletes all keys starting with given prefix.
+	// It returns the number of deleted keys, or an error if something bad happened.
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// Toucher is implemented by caches which can change a key's expiration period, without rewriting its value.
+type Toucher interface {
+	// Touch sets the given expiration period to an existing key.
+	// An expiration period equal to 0 (NoExpire) means no expiration.
+	// A negative expiration period triggers deletion of key.
+	// It returns false if the key does not exist, or an error if something bad happened.
+	Touch(ctx context.Context, key string, expire time.Duration) (bool, error)
+}
+
+// TTLKeeper is implemented by caches which can overwrite a key's value, keeping its remaining time to live.
+type TTLKeeper interface {
+	// SaveKeepTTL stores the given value for a key, keeping key's remaining time to live.
+	// A key which does not exist is saved as with NoExpire.
+	// It returns an error if the key could not be saved.
+	SaveKeepTTL(ctx context.Context, key string, value []byte) error
+}
+
+// SaveKeepTTL stores the given value for a key into given cache, keeping key's remaining time to live,
+// so that updating a value does not reset its expiration.
+// A key which does not exist is saved as with NoExpire.
+// If the cache is a TTLKeeper, the operation is atomic, otherwise key's TTL is looked up and the key
+// is saved with it (note, the key can be changed by someone else between the two calls).
+func SaveKeepTTL(ctx context.Context, cache Cache, key string, value []byte) error {
+	if keeper, ok := cache.(TTLKeeper); ok {
+		return keeper.SaveKeepTTL(ctx, key, value)
+	}
+	ttl, err := cache.TTL(ctx, key)
+	if err != nil {
+		return err
+	}
+	if ttl < 0 { // key does not exist.
+		ttl = NoExpire
+	}
+
+	return cache.Save(ctx, key, value, ttl)
+}
+
+// Scanner is implemented by caches which can iterate over their keys.
+type Scanner interface {
+	// Scan returns a batch of keys starting with given prefix, and the cursor to be passed to
+	// the next Scan call in order to continue the iteration.
+	// An empty cursor starts a new iteration, and an empty returned cursor signals its end.
+	// The cursor is an opaque token which can be persisted, so that a long-running iteration
+	// can be resumed later (for example after an interruption), without restarting from zero.
+	// The count parameter is a hint for the number of keys to be examined per call.
+	// If the cursor is not valid, ErrInvalidCursor is returned.
+	// Note: the cost of resuming an iteration depends on the implementation, Memory's one is linear
+	// in the position of the cursor, making a full iteration quadratic (see Memory.Scan).
+	Scan(ctx context.Context, cursor, prefix string, count int) (keys []string, nextCursor string, err error)
+}
@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Unwrapper = (*xcache.AdaptiveTTL)(nil)          // test AdaptiveTTL is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Admission)(nil)            // test Admission is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.CircuitBreaker)(nil)       // test CircuitBreaker is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Compressed)(nil)           // test Compressed is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.DeadlineAware)(nil)        // test DeadlineAware is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Encrypted)(nil)            // test Encrypted is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.HashedKeys)(nil)           // test HashedKeys is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Hooked)(nil)               // test Hooked is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Jittered)(nil)             // test Jittered is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.LoadShed)(nil)             // test LoadShed is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Logged)(nil)               // test Logged is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Metered)(nil)              // test Metered is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Namespaced)(nil)           // test Namespaced is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.NegativeCache)(nil)        // test NegativeCache is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.RateLimited)(nil)          // test RateLimited is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Recorder)(nil)             // test Recorder is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Redis7Tracked)(nil)        // test Redis7Tracked is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.RefreshAhead)(nil)         // test RefreshAhead is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Retry)(nil)                // test Retry is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.StaleWhileRevalidate)(nil) // test StaleWhileRevalidate is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.TenantCache)(nil)          // test TenantCache is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.TimeLimited)(nil)          // test TimeLimited is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.TimeToIdle)(nil)           // test TimeToIdle is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Traced)(nil)               // test Traced is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.Tunable)(nil)              // test Tunable is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.ValidatedKeys)(nil)        // test ValidatedKeys is an Unwrapper
+	var _ xcache.Unwrapper = (*xcache.XFetch)(nil)               // test XFetch is an Unwrapper
+}
+
+func TestAs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("underlying cache is found", testAsUnderlyingCacheIsFound)
+	t.Run("decorator is found", testAsDecoratorIsFound)
+	t.Run("not found", testAsNotFound)
+}
+
+func testAsUnderlyingCacheIsFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		memory = xcache.NewMemory(1)
+		cache  = xcache.NewMetered(
+			xcache.NewLoadShed(
+				xcache.NewRecorder(
+					xcache.NewTimeToIdle(memory, time.Minute),
+					io.Discard,
+				),
+				10, 10,
+			),
+		)
+	)
+
+	// act
+	result, found := xcache.As[*xcache.Memory](cache)
+
+	// assert
+	assertTrue(t, found)
+	assertTrue(t, result == memory)
+}
+
+func testAsDecoratorIsFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		loadShed = xcache.NewLoadShed(new(xcache.Mock), 10, 10)
+		cache    = xcache.NewMetered(loadShed)
+	)
+
+	// act
+	result, found := xcache.As[interface{ ShedCount() int64 }](cache)
+
+	// assert
+	assertTrue(t, found)
+	assertTrue(t, result == loadShed)
+}
+
+func testAsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	cache := xcache.NewMetered(xcache.NewMulti(xcache.NewMemory(1))) // Multi is not unwrapped.
+
+	// act
+	result, found := xcache.As[*xcache.Memory](cache)
+
+	// assert
+	assertTrue(t, !found)
+	assertNil(t, result)
+}
+
+func TestSaveKeepTTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not a TTLKeeper, existing key", testSaveKeepTTLFallbackExistingKey)
+	t.Run("not a TTLKeeper, not existing key", testSaveKeepTTLFallbackNotExistingKey)
+}
+
+func testSaveKeepTTLFallbackExistingKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		memory  = xcache.NewMemory(freecacheMinMem)
+		subject = struct{ xcache.Cache }{memory} // does not implement TTLKeeper
+		key     = "test-save-keep-ttl-fallback-key"
+		value   = []byte("test value")
+		ctx     = context.Background()
+	)
+	requireNil(t, memory.Save(ctx, key, []byte("old value"), time.Hour))
+
+	// act
+	resultErr := xcache.SaveKeepTTL(ctx, subject, key, value)
+
+	// assert
+	assertNil(t, resultErr)
+	resultValue, err := memory.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, resultValue)
+	resultTTL, err := memory.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, resultTTL > 59*time.Minute && resultTTL <= time.Hour)
+}
+
+func testSaveKeepTTLFallbackNotExistingKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		memory  = xcache.NewMemory(freecacheMinMem)
+		subject = struct{ xcache.Cache }{memory} // does not implement TTLKeeper
+		key     = "test-save-keep-ttl-fallback-not-exist-key"
+		value   = []byte("test value")
+		ctx     = context.Background()
+	)
+
+	// act
+	resultErr := xcache.SaveKeepTTL(ctx, subject, key, value)
+
+	// assert
+	assertNil(t, resultErr)
+	resultValue, err := memory.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, resultValue)
+	resultTTL, err := memory.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, resultTTL)
+}
@@ -0,0 +1,118 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"io"
+	"strings"
+)
+
+// Capability is an optional feature of a cache, provided by implementing an extension interface.
+type Capability uint16
+
+// Capabilities.
+const (
+	// CapabilityDeleter is provided by a Deleter.
+	CapabilityDeleter Capability = 1 << iota
+	// CapabilityBulkDeleter is provided by a BulkDeleter.
+	CapabilityBulkDeleter
+	// CapabilityBatcher is provided by a Batcher.
+	CapabilityBatcher
+	// CapabilityExistenceChecker is provided by an ExistenceChecker.
+	CapabilityExistenceChecker
+	// CapabilityPrefixDeleter is provided by a PrefixDeleter.
+	CapabilityPrefixDeleter
+	// CapabilityToucher is provided by a Toucher.
+	CapabilityToucher
+	// CapabilityScanner is provided by a Scanner.
+	CapabilityScanner
+	// CapabilityCloser is provided by an [io.Closer].
+	CapabilityCloser
+	// CapabilityTTLKeeper is provided by a TTLKeeper.
+	CapabilityTTLKeeper
+)
+
+// capabilityNames holds the names of the capabilities, in their bits order.
+var capabilityNames = [...]string{
+	"Deleter",
+	"BulkDeleter",
+	"Batcher",
+	"ExistenceChecker",
+	"PrefixDeleter",
+	"Toucher",
+	"Scanner",
+	"Closer",
+	"TTLKeeper",
+}
+
+// CapabilitySet is a set of capabilities.
+type CapabilitySet uint16
+
+// Has returns true if the set contains given capability.
+func (set CapabilitySet) Has(capability Capability) bool {
+	return set&CapabilitySet(capability) != 0
+}
+
+// String implements fmt.Stringer.
+// Returns the names of the capabilities in the set, separated by comma.
+//
+// Example: "Deleter,ExistenceChecker,Toucher".
+func (set CapabilitySet) String() string {
+	var sb strings.Builder
+	for bit, name := range capabilityNames {
+		if set&(1<<bit) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+	}
+
+	return sb.String()
+}
+
+// Capabilities returns the set of capabilities given cache provides (the extension interfaces it implements),
+// so generic infrastructure code can adapt to it.
+// Note: a decorator provides only the capabilities it implements itself, regardless of the decorated cache's ones.
+//
+// Example:
+//
+//	if xcache.Capabilities(cache).Has(xcache.CapabilityPrefixDeleter) {
+//		// register an admin endpoint for deleting keys by prefix.
+//	}
+func Capabilities(cache Cache) CapabilitySet {
+	var set CapabilitySet
+	if _, ok := cache.(Deleter); ok {
+		set |= CapabilitySet(CapabilityDeleter)
+	}
+	if _, ok := cache.(BulkDeleter); ok {
+		set |= CapabilitySet(CapabilityBulkDeleter)
+	}
+	if _, ok := cache.(Batcher); ok {
+		set |= CapabilitySet(CapabilityBatcher)
+	}
+	if _, ok := cache.(ExistenceChecker); ok {
+		set |= CapabilitySet(CapabilityExistenceChecker)
+	}
+	if _, ok := cache.(PrefixDeleter); ok {
+		set |= CapabilitySet(CapabilityPrefixDeleter)
+	}
+	if _, ok := cache.(Toucher); ok {
+		set |= CapabilitySet(CapabilityToucher)
+	}
+	if _, ok := cache.(Scanner); ok {
+		set |= CapabilitySet(CapabilityScanner)
+	}
+	if _, ok := cache.(io.Closer); ok {
+		set |= CapabilitySet(CapabilityCloser)
+	}
+	if _, ok := cache.(TTLKeeper); ok {
+		set |= CapabilitySet(CapabilityTTLKeeper)
+	}
+
+	return set
+}
@@ -0,0 +1,54 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	redisCache := xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{"127.0.0.1:6379"}})
+	defer func() { _ = redisCache.Close() }()
+	tests := [...]struct {
+		name           string
+		cache          xcache.Cache
+		expectedString string
+	}{
+		{
+			name:           "Memory",
+			cache:          xcache.NewMemory(1),
+			expectedString: "Deleter,BulkDeleter,Batcher,ExistenceChecker,PrefixDeleter,Toucher,Scanner,TTLKeeper",
+		},
+		{
+			name:           "Redis7",
+			cache:          redisCache,
+			expectedString: "Deleter,BulkDeleter,Batcher,ExistenceChecker,PrefixDeleter,Toucher,Scanner,Closer,TTLKeeper",
+		},
+		{
+			name:           "decorator",
+			cache:          xcache.NewTimeToIdle(xcache.NewMemory(1), time.Minute),
+			expectedString: "",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// act
+			result := xcache.Capabilities(test.cache)
+
+			// assert
+			assertEqual(t, test.expectedString, result.String())
+			assertEqual(t, test.expectedString != "", result.Has(xcache.CapabilityDeleter))
+			assertEqual(t, test.expectedString != "", result.Has(xcache.CapabilityScanner))
+		})
+	}
+}
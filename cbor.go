@@ -0,0 +1,69 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ErrInvalidCBOR is returned when data cannot be decoded as CBOR, or into the requested type.
+var ErrInvalidCBOR = errors.New("invalid cbor data")
+
+// cborMaxDepth is the max nesting level of arrays / maps / tags accepted by the decoder.
+const cborMaxDepth = 256
+
+var (
+	// cborEncMode is the encoding mode used by CBORCodec.
+	cborEncMode = mustCBOREncMode(cbor.EncOptions{
+		Sort:          cbor.SortLengthFirst,
+		ShortestFloat: cbor.ShortestFloat16,
+		NaNConvert:    cbor.NaNConvert7e00,
+		InfConvert:    cbor.InfConvertFloat16,
+		Time:          cbor.TimeRFC3339Nano,
+		TimeTag:       cbor.EncTagRequired,
+	})
+	// cborDecMode is the decoding mode used by CBORCodec.
+	cborDecMode = mustCBORDecMode(cbor.DecOptions{
+		MaxNestedLevels:      cborMaxDepth,
+		UnrecognizedTagToAny: cbor.UnrecognizedTagContentToAny,
+		TimeTag:              cbor.DecTagOptional,
+	})
+)
+
+// cborEncode returns the CBOR encoding of given value.
+func cborEncode(value any) ([]byte, error) {
+	return cborEncMode.Marshal(value)
+}
+
+// cborDecode decodes given CBOR data into the value pointed by given pointer.
+func cborDecode(data []byte, ptr any) error {
+	if err := cborDecMode.Unmarshal(data, ptr); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCBOR, err)
+	}
+
+	return nil
+}
+
+func mustCBOREncMode(opts cbor.EncOptions) cbor.EncMode {
+	mode, err := opts.EncMode()
+	if err != nil {
+		panic("xcache: invalid cbor encoding options: " + err.Error())
+	}
+
+	return mode
+}
+
+func mustCBORDecMode(opts cbor.DecOptions) cbor.DecMode {
+	mode, err := opts.DecMode()
+	if err != nil {
+		panic("xcache: invalid cbor decoding options: " + err.Error())
+	}
+
+	return mode
+}
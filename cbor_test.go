@@ -0,0 +1,446 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Codec[testUser] = xcache.CBORCodec[testUser]{} // test CBORCodec is a Codec
+}
+
+func TestCBORCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rfc 8949 examples", testCBORCodecRFCExamples)
+	t.Run("indefinite length and tagged items", testCBORCodecIndefiniteLengthAndTaggedItems)
+	t.Run("structs", testCBORCodecStructs)
+	t.Run("deterministic maps", testCBORCodecDeterministicMaps)
+	t.Run("invalid data", testCBORCodecInvalidData)
+	t.Run("unsupported type", testCBORCodecUnsupportedType)
+}
+
+// testCBORCodecRFCExamples checks the examples of RFC 8949, Appendix A, are encoded / decoded accordingly.
+func testCBORCodecRFCExamples(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		value   any // the value to encode.
+		decoded any // the decoded value.
+		hex     string
+	}{
+		{value: 0, decoded: uint64(0), hex: "00"},
+		{value: 1, decoded: uint64(1), hex: "01"},
+		{value: 10, decoded: uint64(10), hex: "0a"},
+		{value: 23, decoded: uint64(23), hex: "17"},
+		{value: 24, decoded: uint64(24), hex: "1818"},
+		{value: 100, decoded: uint64(100), hex: "1864"},
+		{value: 1000, decoded: uint64(1000), hex: "1903e8"},
+		{value: 1000000, decoded: uint64(1000000), hex: "1a000f4240"},
+		{value: 1000000000000, decoded: uint64(1000000000000), hex: "1b000000e8d4a51000"},
+		{value: uint64(math.MaxUint64), decoded: uint64(math.MaxUint64), hex: "1bffffffffffffffff"},
+		{value: -1, decoded: int64(-1), hex: "20"},
+		{value: -10, decoded: int64(-10), hex: "29"},
+		{value: -100, decoded: int64(-100), hex: "3863"},
+		{value: -1000, decoded: int64(-1000), hex: "3903e7"},
+		{value: int64(math.MinInt64), decoded: int64(math.MinInt64), hex: "3b7fffffffffffffff"},
+		{value: 0.0, decoded: 0.0, hex: "f90000"},
+		{value: math.Copysign(0, -1), decoded: math.Copysign(0, -1), hex: "f98000"},
+		{value: 1.0, decoded: 1.0, hex: "f93c00"},
+		{value: 1.1, decoded: 1.1, hex: "fb3ff199999999999a"},
+		{value: 1.5, decoded: 1.5, hex: "f93e00"},
+		{value: 65504.0, decoded: 65504.0, hex: "f97bff"},
+		{value: 100000.0, decoded: 100000.0, hex: "fa47c35000"},
+		{value: 3.4028234663852886e+38, decoded: 3.4028234663852886e+38, hex: "fa7f7fffff"},
+		{value: 1.0e+300, decoded: 1.0e+300, hex: "fb7e37e43c8800759c"},
+		{value: 5.960464477539063e-8, decoded: 5.960464477539063e-8, hex: "f90001"},
+		{value: 0.00006103515625, decoded: 0.00006103515625, hex: "f90400"},
+		{value: -4.0, decoded: -4.0, hex: "f9c400"},
+		{value: -4.1, decoded: -4.1, hex: "fbc010666666666666"},
+		{value: float32(0.1), decoded: float64(float32(0.1)), hex: "fa3dcccccd"},
+		{value: math.Inf(1), decoded: math.Inf(1), hex: "f97c00"},
+		{value: math.Inf(-1), decoded: math.Inf(-1), hex: "f9fc00"},
+		{value: false, decoded: false, hex: "f4"},
+		{value: true, decoded: true, hex: "f5"},
+		{value: nil, decoded: nil, hex: "f6"},
+		{value: []byte{}, decoded: []byte{}, hex: "40"},
+		{value: []byte{1, 2, 3, 4}, decoded: []byte{1, 2, 3, 4}, hex: "4401020304"},
+		{value: "", decoded: "", hex: "60"},
+		{value: "a", decoded: "a", hex: "6161"},
+		{value: "IETF", decoded: "IETF", hex: "6449455446"},
+		{value: "\"\\", decoded: "\"\\", hex: "62225c"},
+		{value: "ü", decoded: "ü", hex: "62c3bc"},
+		{value: "水", decoded: "水", hex: "63e6b0b4"},
+		{value: []int{}, decoded: []any{}, hex: "80"},
+		{value: []int{1, 2, 3}, decoded: []any{uint64(1), uint64(2), uint64(3)}, hex: "83010203"},
+		{
+			value:   []any{1, []int{2, 3}, [2]int{4, 5}},
+			decoded: []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}},
+			hex:     "8301820203820405",
+		},
+		{value: map[int]int{}, decoded: map[any]any{}, hex: "a0"},
+		{
+			value:   map[int]int{3: 4, 1: 2},
+			decoded: map[any]any{uint64(1): uint64(2), uint64(3): uint64(4)},
+			hex:     "a201020304",
+		},
+		{
+			value:   map[string]any{"b": []int{2, 3}, "a": 1},
+			decoded: map[any]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}},
+			hex:     "a26161016162820203",
+		},
+		{
+			value:   time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+			decoded: time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+			hex:     "c074323031332d30332d32315432303a30343a30305a",
+		},
+	}
+	subject := xcache.CBORCodec[any]{}
+
+	for _, test := range tests {
+		// act
+		data, errEncode := subject.Encode(test.value)
+		result, errDecode := subject.Decode(data)
+
+		// assert
+		assertNil(t, errEncode)
+		assertEqual(t, test.hex, hex.EncodeToString(data))
+		assertNil(t, errDecode)
+		if f, ok := test.decoded.(float64); ok && f == 0 {
+			assertEqual(t, math.Signbit(f), math.Signbit(result.(float64)))
+		}
+		assertEqual(t, test.decoded, result)
+	}
+
+	// act & assert NaN
+	data, err := subject.Encode(math.NaN())
+	assertNil(t, err)
+	assertEqual(t, "f97e00", hex.EncodeToString(data))
+	result, err := xcache.CBORCodec[float64]{}.Decode(data)
+	assertNil(t, err)
+	assertTrue(t, math.IsNaN(result))
+}
+
+func testCBORCodecIndefiniteLengthAndTaggedItems(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name     string
+		hex      string
+		expected any
+	}{
+		{
+			name:     "indefinite length byte string",
+			hex:      "5f42010243030405ff",
+			expected: []byte{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "indefinite length text string",
+			hex:      "7f657374726561646d696e67ff",
+			expected: "streaming",
+		},
+		{
+			name:     "indefinite length empty array",
+			hex:      "9fff",
+			expected: []any{},
+		},
+		{
+			name:     "indefinite length nested arrays",
+			hex:      "9f018202039f0405ffff",
+			expected: []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}},
+		},
+		{
+			name:     "indefinite length map",
+			hex:      "bf61610161629f0203ffff",
+			expected: map[any]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}},
+		},
+		{
+			name:     "undefined",
+			hex:      "f7",
+			expected: nil,
+		},
+		{
+			name:     "half precision float",
+			hex:      "f93c00",
+			expected: 1.0,
+		},
+		{
+			name:     "unknown tag",
+			hex:      "d82076687474703a2f2f7777772e6578616d706c652e636f6d",
+			expected: "http://www.example.com",
+		},
+	}
+	subject := xcache.CBORCodec[any]{}
+
+	for _, test := range tests {
+		// act
+		data, _ := hex.DecodeString(test.hex)
+		result, err := subject.Decode(data)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, test.expected, result)
+	}
+
+	// act & assert epoch based date / time
+	timeSubject := xcache.CBORCodec[time.Time]{}
+	for _, test := range [...]struct {
+		hex      string
+		expected time.Time
+	}{
+		{hex: "c11a514b67b0", expected: time.Unix(1363896240, 0)},
+		{hex: "c1fb41d452d9ec200000", expected: time.Unix(1363896240, 500000000)},
+		{hex: "1a514b67b0", expected: time.Unix(1363896240, 0)},
+	} {
+		data, _ := hex.DecodeString(test.hex)
+		result, err := timeSubject.Decode(data)
+		assertNil(t, err)
+		assertTrue(t, test.expected.Equal(result))
+	}
+}
+
+type testCBORAddress struct {
+	City string `cbor:"city"`
+	Zip  string `cbor:"zip,omitempty"`
+}
+
+type testCBORPerson struct {
+	ID        int64             `cbor:"id"`
+	Name      string            `json:"name"`
+	Email     string            `cbor:"email,omitempty"`
+	Avatar    []byte            `cbor:"avatar"`
+	Tags      []string          `cbor:"tags"`
+	Scores    map[string]uint16 `cbor:"scores"`
+	Address   *testCBORAddress  `cbor:"address"`
+	CreatedAt time.Time         `cbor:"created_at"`
+	Active    bool
+	Ratio     float32 `cbor:"ratio"`
+	Secret    string  `cbor:"-"`
+	internal  int
+}
+
+type testCBORPersonSummary struct {
+	ID     int64
+	NAME   string
+	Active bool
+}
+
+func testCBORCodecStructs(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.CBORCodec[testCBORPerson]{}
+		person  = testCBORPerson{
+			ID:        -123,
+			Name:      "John Doe",
+			Avatar:    []byte{0xca, 0xfe},
+			Tags:      []string{"admin", "staff"},
+			Scores:    map[string]uint16{"math": 10, "physics": 9},
+			Address:   &testCBORAddress{City: "Bucharest"},
+			CreatedAt: time.Date(2024, 5, 17, 10, 20, 30, 123456789, time.FixedZone("EEST", 3*60*60)),
+			Active:    true,
+			Ratio:     0.25,
+			Secret:    "test secret",
+			internal:  1,
+		}
+	)
+
+	// act
+	data, errEncode := subject.Encode(person)
+	result, errDecode := subject.Decode(data)
+
+	// assert
+	assertNil(t, errEncode)
+	assertNil(t, errDecode)
+	assertTrue(t, person.CreatedAt.Equal(result.CreatedAt))
+	result.CreatedAt = person.CreatedAt
+	person.Secret, person.internal = "", 0
+	assertEqual(t, person, result)
+
+	// act & assert decoding into a type with less fields (unknown ones are skipped, names are matched case insensitive)
+	summary, err := xcache.CBORCodec[testCBORPersonSummary]{}.Decode(data)
+	assertNil(t, err)
+	assertEqual(t, testCBORPersonSummary{ID: -123, NAME: "John Doe", Active: true}, summary)
+
+	// act & assert pointers and nil values
+	data, err = xcache.CBORCodec[*testCBORPerson]{}.Encode(nil)
+	assertNil(t, err)
+	assertEqual(t, []byte{0xf6}, data)
+	resultPtr, err := xcache.CBORCodec[*testCBORPerson]{}.Decode(data)
+	assertNil(t, err)
+	assertTrue(t, resultPtr == nil)
+	data, err = subject.Encode(testCBORPerson{})
+	assertNil(t, err)
+	result, err = subject.Decode(data)
+	assertNil(t, err)
+	assertEqual(t, testCBORPerson{CreatedAt: result.CreatedAt}, result)
+	assertTrue(t, result.CreatedAt.IsZero())
+}
+
+func testCBORCodecDeterministicMaps(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject  = xcache.CBORCodec[map[string]int]{}
+		value    = map[string]int{"zz": 1, "b": 2, "a": 3, "aa": 4, "c": 5}
+		expected []byte
+	)
+
+	for i := 0; i < 10; i++ {
+		// act
+		data, err := subject.Encode(value)
+
+		// assert
+		assertNil(t, err)
+		if expected == nil {
+			expected = data
+		}
+		assertTrue(t, bytes.Equal(expected, data))
+	}
+	// shorter keys first, then bytewise.
+	assertEqual(t, "a561610361620261630562616104627a7a01", hex.EncodeToString(expected))
+}
+
+func testCBORCodecInvalidData(t *testing.T) {
+	t.Parallel()
+
+	deeplyNested := append(bytes.Repeat([]byte{0x81}, 300), 0x00)
+	tests := [...]struct {
+		name    string
+		data    []byte
+		decode  func([]byte) error
+		wantErr error
+	}{
+		{
+			name:    "empty data",
+			data:    nil,
+			decode:  decodeCBORInto[any],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "truncated integer",
+			data:    []byte{0x19, 0x03},
+			decode:  decodeCBORInto[any],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "truncated string",
+			data:    []byte{0x64, 'I', 'E'},
+			decode:  decodeCBORInto[string],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "too large array length",
+			data:    []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			decode:  decodeCBORInto[[]int],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "too large map length",
+			data:    []byte{0xba, 0xff, 0xff, 0xff, 0xff},
+			decode:  decodeCBORInto[map[string]int],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "trailing data",
+			data:    []byte{0x01, 0x02},
+			decode:  decodeCBORInto[int],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "unexpected break",
+			data:    []byte{0xff},
+			decode:  decodeCBORInto[any],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "reserved additional information",
+			data:    []byte{0x1c},
+			decode:  decodeCBORInto[int],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "type mismatch",
+			data:    []byte{0x61, 'a'},
+			decode:  decodeCBORInto[int],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "integer overflow",
+			data:    []byte{0x19, 0x01, 0x2c}, // 300
+			decode:  decodeCBORInto[int8],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "negative integer into unsigned",
+			data:    []byte{0x20},
+			decode:  decodeCBORInto[uint],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "max depth exceeded",
+			data:    deeplyNested,
+			decode:  decodeCBORInto[any],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "invalid date / time",
+			data:    []byte{0xc0, 0x61, 'a'},
+			decode:  decodeCBORInto[time.Time],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+		{
+			name:    "unhashable map key",
+			data:    []byte{0xa1, 0x80, 0x01},
+			decode:  decodeCBORInto[any],
+			wantErr: xcache.ErrInvalidCBOR,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			err := test.decode(test.data)
+
+			// assert
+			assertTrue(t, errors.Is(err, test.wantErr))
+		})
+	}
+}
+
+func testCBORCodecUnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.CBORCodec[map[string]any]{}
+
+	// act
+	data, err := subject.Encode(map[string]any{"ch": make(chan int)})
+
+	// assert
+	assertNotNil(t, err)
+	assertNil(t, data)
+}
+
+// decodeCBORInto decodes given data into a T value.
+func decodeCBORInto[T any](data []byte) error {
+	_, err := xcache.CBORCodec[T]{}.Decode(data)
+
+	return err
+}
@@ -0,0 +1,376 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker cache when an operation is rejected,
+// as the decorated cache is considered unhealthy.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// errCircuitOpenLoad is returned by a CircuitBreaker cache when a load is rejected.
+// It's also an ErrNotFound, so callers (Multi, LoadOrCompute, etc.) treat the key as missing.
+var errCircuitOpenLoad = fmt.Errorf("%w: %w", ErrCircuitOpen, ErrNotFound)
+
+// Circuit breaker defaults.
+const (
+	circuitDefaultFailureRatio   = 0.5
+	circuitDefaultMinRequests    = 20
+	circuitDefaultWindow         = 10 * time.Second
+	circuitDefaultOpenTimeout    = 5 * time.Second
+	circuitDefaultHalfOpenProbes = 1
+)
+
+// Circuit breaker operations, see CircuitBreaker.State.
+const (
+	CircuitOpSave = "save"
+	CircuitOpLoad = "load"
+	CircuitOpTTL  = "ttl"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int32
+
+// Circuit breaker states.
+const (
+	// CircuitClosed is the state in which operations are executed, and their failures are tracked.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state in which operations are rejected, with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen is the state in which a limited no. of probe operations are executed,
+	// in order to find out if the decorated cache recovered; other operations are rejected.
+	CircuitHalfOpen
+)
+
+// String returns the state's name.
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig holds the settings of a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureRatio is the ratio, within (0, 1], of failed operations within a Window, which opens the breaker.
+	// By default (0), it's 0.5.
+	FailureRatio float64
+	// MinRequests is the min. no. of operations within a Window for the FailureRatio to be taken into account.
+	// By default (0), it's 20.
+	MinRequests int
+	// Window is the period operations' failures are counted over. By default (0), it's 10s.
+	Window time.Duration
+	// OpenTimeout is the period the breaker stays open, before probing the decorated cache (half-open).
+	// By default (0), it's 5s.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the no. of probe operations executed while the breaker is half-open,
+	// all of them have to succeed for the breaker to be closed. By default (0), it's 1.
+	HalfOpenProbes int
+	// IsFailure tells whether an operation's error counts as a failure.
+	// By default (nil), timeouts, unavailability and backend errors count (see ClassifyError),
+	// while not found keys and canceled operations do not.
+	IsFailure func(err error) bool
+	// OnStateChange, if set, is called when an operation's breaker changes its state (for logging, for example).
+	// It's called synchronously, while the breaker is locked, so it must not call the CircuitBreaker.
+	OnStateChange func(operation string, from, to CircuitState)
+}
+
+// CircuitBreaker is a Cache decorator which tracks the failure rate of each operation (Save, Load, TTL)
+// on the decorated (usually remote) cache, and opens the operation's breaker when the cache is unhealthy,
+// failing fast, instead of waiting for each operation to time out:
+//   - while closed, operations are executed; if, within a Window, at least MinRequests operations were executed,
+//     and at least FailureRatio of them failed, the breaker opens;
+//   - while open, operations are rejected: Save and TTL return ErrCircuitOpen, Load returns an error
+//     which is both ErrCircuitOpen and ErrNotFound (so the key is treated as missing);
+//   - after OpenTimeout, the breaker is half-open: HalfOpenProbes operations are executed,
+//     if all of them succeed the breaker closes, if any of them fails the breaker opens again.
+//
+// Stats is not guarded.
+// The settings can be changed at runtime, see SetConfig, and NewCircuitBreakerWithConfig for having the
+// thresholds driven by a xconf.Config.
+//
+// To serve only from the Memory layer when Redis degrades, decorate the Redis layer of a Multi cache:
+//
+//	cache := xcache.NewMulti(memCache, xcache.NewCircuitBreaker(redisCache, xcache.CircuitBreakerConfig{}))
+type CircuitBreaker struct {
+	cache    Cache
+	config   CircuitBreakerConfig
+	mu       sync.RWMutex // guards config.
+	save     circuit
+	load     circuit
+	ttl      circuit
+	rejected int64
+	opened   int64
+}
+
+// NewCircuitBreaker instantiates a new CircuitBreaker which decorates given cache, according to given settings.
+func NewCircuitBreaker(cache Cache, config CircuitBreakerConfig) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		cache:  cache,
+		config: withCircuitDefaults(config),
+	}
+	cb.save.init(cb, CircuitOpSave)
+	cb.load.init(cb, CircuitOpLoad)
+	cb.ttl.init(cb, CircuitOpTTL)
+
+	return cb
+}
+
+// Config returns current settings.
+func (cache *CircuitBreaker) Config() CircuitBreakerConfig {
+	cache.mu.RLock()
+	config := cache.config
+	cache.mu.RUnlock()
+
+	return config
+}
+
+// SetConfig changes the settings. Zero values are replaced with defaults, as in NewCircuitBreaker.
+// Breakers' states are kept, the new settings apply to subsequent operations.
+func (cache *CircuitBreaker) SetConfig(config CircuitBreakerConfig) {
+	config = withCircuitDefaults(config)
+	cache.mu.Lock()
+	cache.config = config
+	cache.mu.Unlock()
+}
+
+// withCircuitDefaults returns given settings, having zero / invalid values replaced with defaults.
+func withCircuitDefaults(config CircuitBreakerConfig) CircuitBreakerConfig {
+	if config.FailureRatio <= 0 || config.FailureRatio > 1 {
+		config.FailureRatio = circuitDefaultFailureRatio
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = circuitDefaultMinRequests
+	}
+	if config.Window <= 0 {
+		config.Window = circuitDefaultWindow
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = circuitDefaultOpenTimeout
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = circuitDefaultHalfOpenProbes
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+
+	return config
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// It returns ErrCircuitOpen if the operation was rejected.
+func (cache *CircuitBreaker) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if !cache.save.allow() {
+		return ErrCircuitOpen
+	}
+	err := cache.cache.Save(ctx, key, value, expire)
+	cache.save.done(err)
+
+	return err
+}
+
+// Load returns a key's value from decorated cache.
+// It returns an error which is both ErrCircuitOpen and ErrNotFound if the operation was rejected.
+func (cache *CircuitBreaker) Load(ctx context.Context, key string) ([]byte, error) {
+	if !cache.load.allow() {
+		return nil, errCircuitOpenLoad
+	}
+	value, err := cache.cache.Load(ctx, key)
+	cache.load.done(err)
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// It returns ErrCircuitOpen if the operation was rejected.
+func (cache *CircuitBreaker) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if !cache.ttl.allow() {
+		return -1, ErrCircuitOpen
+	}
+	ttl, err := cache.cache.TTL(ctx, key)
+	cache.ttl.done(err)
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics (regardless of the breakers' states).
+func (cache *CircuitBreaker) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *CircuitBreaker) Unwrap() Cache {
+	return cache.cache
+}
+
+// State returns the state of given operation's breaker (see CircuitOpSave, CircuitOpLoad, CircuitOpTTL).
+// CircuitClosed is returned for an unknown operation.
+func (cache *CircuitBreaker) State(operation string) CircuitState {
+	switch operation {
+	case CircuitOpSave:
+		return cache.save.currentState()
+	case CircuitOpLoad:
+		return cache.load.currentState()
+	case CircuitOpTTL:
+		return cache.ttl.currentState()
+	default:
+		return CircuitClosed
+	}
+}
+
+// ContributeStats reports the no. of rejected operations, as "circuitbreaker.rejected" metric,
+// the no. of times a breaker opened, as "circuitbreaker.opened" metric,
+// and the no. of breakers currently not closed (open, or half-open), as "circuitbreaker.open" metric.
+func (cache *CircuitBreaker) ContributeStats(add func(name string, value float64)) {
+	var open float64
+	for _, c := range [...]*circuit{&cache.save, &cache.load, &cache.ttl} {
+		if c.currentState() != CircuitClosed {
+			open++
+		}
+	}
+	add("circuitbreaker.rejected", float64(atomic.LoadInt64(&cache.rejected)))
+	add("circuitbreaker.opened", float64(atomic.LoadInt64(&cache.opened)))
+	add("circuitbreaker.open", open)
+}
+
+// isCircuitFailure is the default CircuitBreakerConfig.IsFailure.
+func isCircuitFailure(err error) bool {
+	class := ClassifyError(err)
+
+	return class != ErrorClassNone && class != ErrorClassCanceled
+}
+
+// circuit is the breaker of an operation.
+type circuit struct {
+	cb          *CircuitBreaker
+	operation   string
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time // the moment the current window started (closed state).
+	requests    int       // no. of operations within the current window (closed state).
+	failures    int       // no. of failed operations within the current window (closed state).
+	openedAt    time.Time // the moment the breaker opened (open state).
+	probes      int       // no. of probe operations in progress (half-open state).
+	successes   int       // no. of succeeded probe operations (half-open state).
+}
+
+// init initializes the breaker of given operation.
+func (c *circuit) init(cb *CircuitBreaker, operation string) {
+	c.cb = cb
+	c.operation = operation
+	c.windowStart = time.Now()
+}
+
+// allow returns whether an operation can be executed.
+// If true is returned, done must be called with the operation's error.
+func (c *circuit) allow() bool {
+	config := c.cb.Config()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= config.OpenTimeout {
+		c.setState(CircuitHalfOpen)
+	}
+	switch c.state {
+	case CircuitClosed:
+		return true
+	case CircuitHalfOpen:
+		if c.probes+c.successes < config.HalfOpenProbes {
+			c.probes++
+
+			return true
+		}
+	}
+	atomic.AddInt64(&c.cb.rejected, 1)
+
+	return false
+}
+
+// done records the result of an allowed operation.
+func (c *circuit) done(err error) {
+	config := c.cb.Config()
+	failed := config.IsFailure(err)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case CircuitClosed:
+		now := time.Now()
+		if now.Sub(c.windowStart) >= config.Window {
+			c.windowStart, c.requests, c.failures = now, 0, 0
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= config.MinRequests &&
+			float64(c.failures) >= config.FailureRatio*float64(c.requests) {
+			c.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		c.probes--
+		if failed {
+			c.setState(CircuitOpen)
+
+			return
+		}
+		if c.successes++; c.successes >= config.HalfOpenProbes {
+			c.setState(CircuitClosed)
+		}
+	case CircuitOpen: // a probe's result arrived after another probe reopened the breaker.
+	}
+}
+
+// currentState returns the breaker's state.
+func (c *circuit) currentState() CircuitState {
+	config := c.cb.Config()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// setState transitions the breaker to given state. Mutex must be locked.
+func (c *circuit) setState(state CircuitState) {
+	from := c.state
+	c.state = state
+	switch state {
+	case CircuitClosed:
+		c.windowStart, c.requests, c.failures = time.Now(), 0, 0
+	case CircuitOpen:
+		c.openedAt = time.Now()
+		atomic.AddInt64(&c.cb.opened, 1)
+	case CircuitHalfOpen:
+		c.probes, c.successes = 0, 0
+	}
+	if onStateChange := c.cb.Config().OnStateChange; onStateChange != nil {
+		onStateChange(c.operation, from, state)
+	}
+}
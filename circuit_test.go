@@ -0,0 +1,205 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.CircuitBreaker)(nil)            // test CircuitBreaker is a Cache
+	var _ xcache.StatsContributor = (*xcache.CircuitBreaker)(nil) // test CircuitBreaker is a StatsContributor
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("breaker opens on failures", testCircuitBreakerOpensOnFailures)
+	t.Run("breaker closes after successful probe", testCircuitBreakerClosesAfterSuccessfulProbe)
+	t.Run("breaker reopens after failed probe", testCircuitBreakerReopensAfterFailedProbe)
+	t.Run("not found and canceled are not failures", testCircuitBreakerNotFoundAndCanceledAreNotFailures)
+	t.Run("operations have distinct breakers", testCircuitBreakerOperationsHaveDistinctBreakers)
+}
+
+func testCircuitBreakerOpensOnFailures(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		transitions []string
+		subject     = xcache.NewCircuitBreaker(cache, xcache.CircuitBreakerConfig{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+			OnStateChange: func(op string, from, to xcache.CircuitState) {
+				transitions = append(transitions, op+":"+from.String()+"->"+to.String())
+			},
+		})
+		ctx        = context.Background()
+		key        = "test-circuit-key"
+		errTimeout = context.DeadlineExceeded
+		loads      int32
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		if atomic.AddInt32(&loads, 1)%2 == 0 {
+			return nil, errTimeout
+		}
+
+		return []byte("test value"), nil
+	})
+
+	// act
+	for i := 0; i < 4; i++ {
+		_, _ = subject.Load(ctx, key)
+	}
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrCircuitOpen))
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, result)
+	assertEqual(t, 4, cache.LoadCallsCount())
+	assertEqual(t, xcache.CircuitOpen, subject.State(xcache.CircuitOpLoad))
+	assertEqual(t, []string{"load:closed->open"}, transitions)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(
+		t,
+		map[string]float64{"circuitbreaker.rejected": 1, "circuitbreaker.opened": 1, "circuitbreaker.open": 1},
+		metrics,
+	)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testCircuitBreakerClosesAfterSuccessfulProbe(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCircuitBreaker(cache, xcache.CircuitBreakerConfig{
+			MinRequests:    1,
+			OpenTimeout:    50 * time.Millisecond,
+			HalfOpenProbes: 2,
+		})
+		ctx    = context.Background()
+		key    = "test-circuit-probe-key"
+		value  = []byte("test value")
+		errOOM = errors.New("OOM command not allowed")
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return errOOM
+	})
+	assertTrue(t, errors.Is(subject.Save(ctx, key, value, time.Minute), errOOM))
+	assertTrue(t, errors.Is(subject.Save(ctx, key, value, time.Minute), xcache.ErrCircuitOpen))
+	cache.SetSaveCallback(nil)
+
+	// act
+	time.Sleep(60 * time.Millisecond)
+	state := subject.State(xcache.CircuitOpSave)
+	err1 := subject.Save(ctx, key, value, time.Minute)
+	err2 := subject.Save(ctx, key, value, time.Minute)
+
+	// assert
+	assertEqual(t, xcache.CircuitHalfOpen, state)
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, xcache.CircuitClosed, subject.State(xcache.CircuitOpSave))
+	assertEqual(t, 3, cache.SaveCallsCount())
+}
+
+func testCircuitBreakerReopensAfterFailedProbe(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCircuitBreaker(cache, xcache.CircuitBreakerConfig{
+			MinRequests: 1,
+			OpenTimeout: 50 * time.Millisecond,
+		})
+		ctx = context.Background()
+		key = "test-circuit-reopen-key"
+	)
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return -1, xcache.ErrOverloaded
+	})
+	_, _ = subject.TTL(ctx, key)
+	time.Sleep(60 * time.Millisecond)
+
+	// act
+	_, errProbe := subject.TTL(ctx, key)
+	ttl, err := subject.TTL(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errProbe, xcache.ErrOverloaded))
+	assertTrue(t, errors.Is(err, xcache.ErrCircuitOpen))
+	assertEqual(t, time.Duration(-1), ttl)
+	assertEqual(t, xcache.CircuitOpen, subject.State(xcache.CircuitOpTTL))
+	assertEqual(t, 2, cache.TTLCallsCount())
+}
+
+func testCircuitBreakerNotFoundAndCanceledAreNotFailures(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCircuitBreaker(cache, xcache.CircuitBreakerConfig{MinRequests: 1})
+		ctx     = context.Background()
+		key     = "test-circuit-not-found-key"
+		loads   int32
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		if atomic.AddInt32(&loads, 1)%2 == 0 {
+			return nil, context.Canceled
+		}
+
+		return nil, xcache.ErrNotFound
+	})
+
+	// act
+	for i := 0; i < 10; i++ {
+		_, _ = subject.Load(ctx, key)
+	}
+
+	// assert
+	assertEqual(t, xcache.CircuitClosed, subject.State(xcache.CircuitOpLoad))
+	assertEqual(t, 10, cache.LoadCallsCount())
+}
+
+func testCircuitBreakerOperationsHaveDistinctBreakers(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCircuitBreaker(cache, xcache.CircuitBreakerConfig{MinRequests: 1})
+		ctx     = context.Background()
+		key     = "test-circuit-distinct-key"
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return xcache.ErrOverloaded
+	})
+
+	// act
+	_ = subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, !errors.Is(err, xcache.ErrCircuitOpen)) // mock's key not found.
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertEqual(t, xcache.CircuitOpen, subject.State(xcache.CircuitOpSave))
+	assertEqual(t, xcache.CircuitClosed, subject.State(xcache.CircuitOpLoad))
+	assertEqual(t, xcache.CircuitClosed, subject.State(xcache.CircuitOpTTL))
+	assertEqual(t, xcache.CircuitClosed, subject.State("unknown"))
+}
@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"github.com/actforgood/xconf"
+)
+
+const (
+	// CircuitBreakerCfgKeyFailureRatio is the key under which xconf.Config expects the failure ratio.
+	CircuitBreakerCfgKeyFailureRatio = "xcache.circuitbreaker.failureratio"
+	// CircuitBreakerCfgKeyMinRequests is the key under which xconf.Config expects the min. no. of requests.
+	CircuitBreakerCfgKeyMinRequests = "xcache.circuitbreaker.minrequests"
+	// CircuitBreakerCfgKeyWindow is the key under which xconf.Config expects the failures counting window.
+	CircuitBreakerCfgKeyWindow = "xcache.circuitbreaker.window"
+	// CircuitBreakerCfgKeyOpenTimeout is the key under which xconf.Config expects the open timeout.
+	CircuitBreakerCfgKeyOpenTimeout = "xcache.circuitbreaker.opentimeout"
+	// CircuitBreakerCfgKeyHalfOpenProbes is the key under which xconf.Config expects the no. of half-open probes.
+	CircuitBreakerCfgKeyHalfOpenProbes = "xcache.circuitbreaker.halfopenprobes"
+)
+
+// NewCircuitBreakerWithConfig initializes a CircuitBreaker which decorates given cache,
+// with the thresholds (see CircuitBreakerConfig) taken from a xconf.Config.
+//
+// Keys under which settings are expected are defined in CircuitBreakerCfgKey* constants.
+// Values with common representations are coerced to expected types (for example, the window
+// can be given as a duration string like "10s"). Missing and invalid values are replaced with defaults,
+// use NewCircuitBreakerWithValidConfig to fail on invalid ones instead.
+// Callbacks (IsFailure, OnStateChange) are taken from given base settings.
+//
+// An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
+// In case any of the thresholds is changed, it is applied right away, without the need of
+// restarting your application. An invalid configuration reload is disregarded.
+func NewCircuitBreakerWithConfig(cache Cache, config xconf.Config, base CircuitBreakerConfig) *CircuitBreaker {
+	cbConfig, _ := getCircuitBreakerConfig(config, base)
+	cb := NewCircuitBreaker(cache, cbConfig)
+
+	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
+		defConfig.RegisterObserver(cb.onConfigChange)
+	}
+
+	return cb
+}
+
+// NewCircuitBreakerWithValidConfig is like NewCircuitBreakerWithConfig, but it fails on invalid values:
+// an error aggregating a ConfigError for each of them is returned, and cb is nil.
+func NewCircuitBreakerWithValidConfig(
+	cache Cache,
+	config xconf.Config,
+	base CircuitBreakerConfig,
+) (*CircuitBreaker, error) {
+	if err := ValidateCircuitBreakerXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewCircuitBreakerWithConfig(cache, config, base), nil
+}
+
+// getCircuitBreakerConfig returns given base settings, having the thresholds taken from a xconf.Config.
+// Invalid values are replaced with defaults, and an error aggregating ConfigError(s) is returned.
+func getCircuitBreakerConfig(config xconf.Config, base CircuitBreakerConfig) (CircuitBreakerConfig, error) {
+	r := newXConfReader(config)
+	cbConfig := base
+	cbConfig.FailureRatio = r.Float64(CircuitBreakerCfgKeyFailureRatio, circuitDefaultFailureRatio)
+	cbConfig.MinRequests = r.Int(CircuitBreakerCfgKeyMinRequests, circuitDefaultMinRequests)
+	cbConfig.Window = r.Duration(CircuitBreakerCfgKeyWindow, circuitDefaultWindow)
+	cbConfig.OpenTimeout = r.Duration(CircuitBreakerCfgKeyOpenTimeout, circuitDefaultOpenTimeout)
+	cbConfig.HalfOpenProbes = r.Int(CircuitBreakerCfgKeyHalfOpenProbes, circuitDefaultHalfOpenProbes)
+	if cbConfig.FailureRatio <= 0 || cbConfig.FailureRatio > 1 {
+		r.addErr(CircuitBreakerCfgKeyFailureRatio, errConfigValueRange)
+		cbConfig.FailureRatio = circuitDefaultFailureRatio
+	}
+	if cbConfig.MinRequests <= 0 {
+		r.addErr(CircuitBreakerCfgKeyMinRequests, errConfigValueRange)
+		cbConfig.MinRequests = circuitDefaultMinRequests
+	}
+	if cbConfig.Window <= 0 {
+		r.addErr(CircuitBreakerCfgKeyWindow, errConfigValueRange)
+		cbConfig.Window = circuitDefaultWindow
+	}
+	if cbConfig.OpenTimeout <= 0 {
+		r.addErr(CircuitBreakerCfgKeyOpenTimeout, errConfigValueRange)
+		cbConfig.OpenTimeout = circuitDefaultOpenTimeout
+	}
+	if cbConfig.HalfOpenProbes <= 0 {
+		r.addErr(CircuitBreakerCfgKeyHalfOpenProbes, errConfigValueRange)
+		cbConfig.HalfOpenProbes = circuitDefaultHalfOpenProbes
+	}
+
+	return cbConfig, r.Err()
+}
+
+// ValidateCircuitBreakerXConfig checks the CircuitBreaker configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for each value which cannot be interpreted,
+// or nil if configuration is valid.
+func ValidateCircuitBreakerXConfig(config xconf.Config) error {
+	_, err := getCircuitBreakerConfig(config, CircuitBreakerConfig{})
+
+	return err
+}
+
+// onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
+// In case one of CircuitBreakerCfgKey* configs is changed, the new thresholds are applied, if they are valid.
+// This callback is automatically registered on instantiation of a CircuitBreaker object
+// with NewCircuitBreakerWithConfig.
+func (cache *CircuitBreaker) onConfigChange(config xconf.Config, changedKeys ...string) {
+	configHasChanged := false
+	for _, changedKey := range changedKeys {
+		if changedKey == CircuitBreakerCfgKeyFailureRatio ||
+			changedKey == CircuitBreakerCfgKeyMinRequests ||
+			changedKey == CircuitBreakerCfgKeyWindow ||
+			changedKey == CircuitBreakerCfgKeyOpenTimeout ||
+			changedKey == CircuitBreakerCfgKeyHalfOpenProbes ||
+			changedKey == CfgKeyDurationUnit {
+			configHasChanged = true
+
+			break
+		}
+	}
+	if !configHasChanged {
+		return
+	}
+
+	cbConfig, err := getCircuitBreakerConfig(config, cache.Config())
+	if err != nil { // keep current settings.
+		return
+	}
+	cache.SetConfig(cbConfig)
+}
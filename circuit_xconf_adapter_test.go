@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestCircuitBreaker_withXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig  uint32
+		initialConfig = map[string]any{
+			xcache.CircuitBreakerCfgKeyFailureRatio: 0.3,
+			xcache.CircuitBreakerCfgKeyMinRequests:  50,
+		}
+		configReloaded = map[string]any{
+			xcache.CircuitBreakerCfgKeyFailureRatio:   "0.6",
+			xcache.CircuitBreakerCfgKeyMinRequests:    "10",
+			xcache.CircuitBreakerCfgKeyWindow:         "30s",
+			xcache.CircuitBreakerCfgKeyOpenTimeout:    "2s",
+			xcache.CircuitBreakerCfgKeyHalfOpenProbes: 3,
+		}
+		configReloadedInvalid = map[string]any{
+			xcache.CircuitBreakerCfgKeyFailureRatio: 2,
+			xcache.CircuitBreakerCfgKeyMinRequests:  5,
+		}
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			switch atomic.LoadUint32(&reloadConfig) {
+			case 1:
+				return configReloaded, nil
+			case 2:
+				return configReloadedInvalid, nil
+			}
+
+			return initialConfig, nil
+		})
+		stateChanges uint32
+		subject      = xcache.NewCircuitBreakerWithConfig(
+			xcache.Nop{},
+			config,
+			xcache.CircuitBreakerConfig{
+				OnStateChange: func(string, xcache.CircuitState, xcache.CircuitState) {
+					atomic.AddUint32(&stateChanges, 1)
+				},
+			},
+		)
+	)
+
+	// act & assert initial config
+	result := subject.Config()
+	assertEqual(t, 0.3, result.FailureRatio)
+	assertEqual(t, 50, result.MinRequests)
+	assertEqual(t, 10*time.Second, result.Window)
+	assertEqual(t, 5*time.Second, result.OpenTimeout)
+	assertEqual(t, 1, result.HalfOpenProbes)
+	assertNotNil(t, result.IsFailure)
+	assertNotNil(t, result.OnStateChange)
+
+	// act & assert reloaded config
+	atomic.StoreUint32(&reloadConfig, 1)
+	waitReload()
+	result = subject.Config()
+	assertEqual(t, 0.6, result.FailureRatio)
+	assertEqual(t, 10, result.MinRequests)
+	assertEqual(t, 30*time.Second, result.Window)
+	assertEqual(t, 2*time.Second, result.OpenTimeout)
+	assertEqual(t, 3, result.HalfOpenProbes)
+	assertNotNil(t, result.OnStateChange)
+
+	// act & assert invalid reloaded config is disregarded
+	atomic.StoreUint32(&reloadConfig, 2)
+	waitReload()
+	result = subject.Config()
+	assertEqual(t, 0.6, result.FailureRatio)
+	assertEqual(t, 10, result.MinRequests)
+	assertEqual(t, uint32(0), atomic.LoadUint32(&stateChanges))
+}
+
+func TestValidateCircuitBreakerXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing values",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name: "valid values",
+			config: xconf.NewMockConfig(
+				xcache.CircuitBreakerCfgKeyFailureRatio, 0.5,
+				xcache.CircuitBreakerCfgKeyMinRequests, 20,
+				xcache.CircuitBreakerCfgKeyWindow, "10s",
+				xcache.CircuitBreakerCfgKeyOpenTimeout, "5s",
+				xcache.CircuitBreakerCfgKeyHalfOpenProbes, 1,
+			),
+		},
+		{
+			name: "invalid values",
+			config: xconf.NewMockConfig(
+				xcache.CircuitBreakerCfgKeyFailureRatio, 1.5,
+				xcache.CircuitBreakerCfgKeyMinRequests, "many",
+				xcache.CircuitBreakerCfgKeyWindow, "-10s",
+				xcache.CircuitBreakerCfgKeyOpenTimeout, "soon",
+				xcache.CircuitBreakerCfgKeyHalfOpenProbes, 0,
+			),
+			expectedErrKeys: []string{ // values which cannot be interpreted come first.
+				xcache.CircuitBreakerCfgKeyMinRequests,
+				xcache.CircuitBreakerCfgKeyOpenTimeout,
+				xcache.CircuitBreakerCfgKeyFailureRatio,
+				xcache.CircuitBreakerCfgKeyWindow,
+				xcache.CircuitBreakerCfgKeyHalfOpenProbes,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateCircuitBreakerXConfig(test.config)
+			cb, resultCtorErr := xcache.NewCircuitBreakerWithValidConfig(
+				xcache.Nop{},
+				test.config,
+				xcache.CircuitBreakerConfig{},
+			)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, cb != nil)
+		})
+	}
+}
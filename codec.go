@@ -0,0 +1,130 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNilProtoMessage is returned by ProtoCodec when encoding a nil message.
+var ErrNilProtoMessage = errors.New("nil proto message")
+
+// Codec converts values of type T to / from the bytes stored into a cache.
+type Codec[T any] interface {
+	// Encode returns the bytes representation of given value.
+	Encode(value T) ([]byte, error)
+	// Decode returns the value represented by given bytes.
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec is a Codec which relies upon [encoding/json] package.
+type JSONCodec[T any] struct{}
+
+// Encode returns the JSON encoding of given value.
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode returns the value represented by given JSON encoded data.
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+
+	return value, err
+}
+
+// GobCodec is a Codec which relies upon [encoding/gob] package.
+// Unlike JSON, it supports Go specific types, like maps with non string keys,
+// and interface fields (their concrete types must be registered with [gob.Register]).
+// Note: each value is encoded with its type definition, so the encoding of small values is larger than the JSON one.
+type GobCodec[T any] struct{}
+
+// Encode returns the gob encoding of given value.
+func (GobCodec[T]) Encode(value T) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := gob.NewEncoder(buf).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+// Decode returns the value represented by given gob encoded data.
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+
+	return value, err
+}
+
+// CBORCodec is a Codec which encodes values as CBOR (RFC 8949), for interoperability with CBOR based services,
+// and relies upon [github.com/fxamacker/cbor/v2] package.
+// Struct fields are map entries named after their "cbor" tag, or "json" tag, or their name ("omitempty" is supported),
+// []byte is encoded as a byte string, and time.Time as a RFC 3339 date / time string (tag 0).
+// Maps are encoded with their keys sorted (length first), so the encoding of a value is deterministic,
+// and floats are encoded in their shortest form which preserves their value.
+// Decoding into an interface produces uint64 / int64, float64, bool, string, []byte, []any,
+// map[any]any, time.Time, or nil values. Decoding errors wrap ErrInvalidCBOR.
+type CBORCodec[T any] struct{}
+
+// Encode returns the CBOR encoding of given value.
+func (CBORCodec[T]) Encode(value T) ([]byte, error) {
+	return cborEncode(value)
+}
+
+// Decode returns the value represented by given CBOR encoded data.
+func (CBORCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	if err := cborDecode(data, &value); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return value, nil
+}
+
+// ProtoCodec is a Codec for protobuf messages, which relies upon [google.golang.org/protobuf/proto] package.
+// T is the pointer type of a generated message, like *pb.User.
+// Unknown fields (written by a newer version of the message) are preserved on decoding, and encoded back,
+// unless DiscardUnknown is set.
+//
+// Example:
+//
+//	users := xcache.NewTypedCache[*pb.User](cache, xcache.ProtoCodec[*pb.User]{})
+type ProtoCodec[T proto.Message] struct {
+	// DiscardUnknown specifies whether unknown fields are dropped on decoding.
+	DiscardUnknown bool
+}
+
+// Encode returns the protobuf wire encoding of given message.
+// It returns ErrNilProtoMessage if the message is nil.
+func (ProtoCodec[T]) Encode(value T) ([]byte, error) {
+	if !value.ProtoReflect().IsValid() {
+		return nil, ErrNilProtoMessage
+	}
+
+	return proto.Marshal(value)
+}
+
+// Decode returns the message represented by given protobuf wire encoded data.
+func (codec ProtoCodec[T]) Decode(data []byte) (T, error) {
+	var zero T
+	value, _ := zero.ProtoReflect().New().Interface().(T)
+	opts := proto.UnmarshalOptions{DiscardUnknown: codec.DiscardUnknown}
+	if err := opts.Unmarshal(data, value); err != nil {
+		return zero, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Codec[testUser] = xcache.GobCodec[testUser]{}                                 // test GobCodec is a Codec
+	var _ xcache.Codec[*wrapperspb.StringValue] = xcache.ProtoCodec[*wrapperspb.StringValue]{} // test ProtoCodec is a Codec
+}
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name    string
+		subject xcache.Codec[testUser]
+	}{
+		{
+			name:    "json",
+			subject: xcache.JSONCodec[testUser]{},
+		},
+		{
+			name:    "gob",
+			subject: xcache.GobCodec[testUser]{},
+		},
+		{
+			name:    "cbor",
+			subject: xcache.CBORCodec[testUser]{},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			user := testUser{ID: 123, Name: "John Doe"}
+
+			// act
+			data, errEncode := test.subject.Encode(user)
+			result, errDecode := test.subject.Decode(data)
+
+			// assert
+			assertNil(t, errEncode)
+			assertNil(t, errDecode)
+			assertEqual(t, user, result)
+
+			// act & assert invalid data
+			result, errDecode = test.subject.Decode([]byte("invalid data"))
+			assertNotNil(t, errDecode)
+			assertEqual(t, testUser{}, result)
+		})
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode and decode", testProtoCodecEncodeAndDecode)
+	t.Run("nil message", testProtoCodecNilMessage)
+	t.Run("unknown fields", testProtoCodecUnknownFields)
+}
+
+func testProtoCodecEncodeAndDecode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.ProtoCodec[*wrapperspb.StringValue]{}
+		message = wrapperspb.String("test value")
+	)
+
+	// act
+	data, errEncode := subject.Encode(message)
+	result, errDecode := subject.Decode(data)
+
+	// assert
+	assertNil(t, errEncode)
+	assertNil(t, errDecode)
+	assertTrue(t, proto.Equal(message, result))
+
+	// act & assert invalid data
+	result, errDecode = subject.Decode([]byte("invalid data"))
+	assertNotNil(t, errDecode)
+	assertTrue(t, result == nil)
+}
+
+func testProtoCodecNilMessage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.ProtoCodec[*wrapperspb.StringValue]{}
+
+	// act
+	data, err := subject.Encode(nil)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNilProtoMessage))
+	assertNil(t, data)
+
+	// act & assert empty data decodes into an empty message
+	result, err := subject.Decode(nil)
+	assertNil(t, err)
+	assertNotNil(t, result)
+	assertEqual(t, "", result.GetValue())
+}
+
+func testProtoCodecUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange, a message written by a "newer" schema (Empty has no fields, StringValue has one)
+	data, err := proto.Marshal(wrapperspb.String("test value"))
+	requireNil(t, err)
+
+	// act & assert unknown fields are preserved
+	subject := xcache.ProtoCodec[*emptypb.Empty]{}
+	result, err := subject.Decode(data)
+	assertNil(t, err)
+	assertEqual(t, len(data), len(result.ProtoReflect().GetUnknown()))
+	resultData, err := subject.Encode(result)
+	assertNil(t, err)
+	assertEqual(t, data, resultData)
+
+	// act & assert unknown fields are discarded
+	subject = xcache.ProtoCodec[*emptypb.Empty]{DiscardUnknown: true}
+	result, err = subject.Decode(data)
+	assertNil(t, err)
+	assertEqual(t, 0, len(result.ProtoReflect().GetUnknown()))
+	resultData, err = subject.Encode(result)
+	assertNil(t, err)
+	assertEqual(t, 0, len(resultData))
+}
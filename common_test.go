@@ -77,6 +77,18 @@ func testCacheWithExpireKey(subject xcache.Cache) func(t *testing.T) {
 		resultTTL, resultErr := subject.TTL(ctx, key)
 		assertNil(t, resultErr)
 		assertTrue(t, resultTTL < 0)
+
+		// act & assert explicit delete
+		if deleteSubject, ok := subject.(xcache.Deleter); ok {
+			resultErr = subject.Save(ctx, key, value, exp)
+			requireNil(t, resultErr)
+			resultErr = deleteSubject.Delete(ctx, key)
+			assertNil(t, resultErr)
+			_, resultErr = subject.Load(ctx, key)
+			assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+			resultErr = deleteSubject.Delete(ctx, key) // not existing key
+			assertNil(t, resultErr)
+		}
 	}
 }
 
@@ -163,6 +175,383 @@ func testCacheTTLWithNotYetExpiredKey(subject xcache.Cache) func(t *testing.T) {
 	}
 }
 
+func testCacheDeletePrefix(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			prefix            = "test-delete-prefix-[a]*-"
+			otherKey          = "test-delete-prefix-a-other-key" // would match prefix if it's not escaped properly.
+			value             = []byte("test value")
+			ctx               = context.Background()
+			exp               = time.Minute
+			keysNo            = 25
+			prefixSubject, ok = subject.(xcache.PrefixDeleter)
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		for i := 0; i < keysNo; i++ {
+			key := prefix + strconv.FormatInt(int64(i), 10)
+			resultErr := subject.Save(ctx, key, value, exp)
+			requireNil(t, resultErr)
+		}
+		resultErr := subject.Save(ctx, otherKey, value, exp)
+		requireNil(t, resultErr)
+
+		// act
+		resultDeleted, resultErr := prefixSubject.DeletePrefix(ctx, prefix)
+
+		// assert
+		assertNil(t, resultErr)
+		assertEqual(t, keysNo, resultDeleted)
+		for i := 0; i < keysNo; i++ {
+			key := prefix + strconv.FormatInt(int64(i), 10)
+			_, resultErr = subject.Load(ctx, key)
+			assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+		}
+		resultValue, resultErr := subject.Load(ctx, otherKey)
+		assertNil(t, resultErr)
+		assertEqual(t, value, resultValue)
+	}
+}
+
+func testCacheScan(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			prefix             = "test-scan-[a]*-"
+			otherKey           = "test-scan-a-other-key" // would match prefix if it's not escaped properly.
+			value              = []byte("test value")
+			ctx                = context.Background()
+			exp                = time.Minute
+			keysNo             = 30
+			expectedKeys       = make(map[string]bool, keysNo)
+			resultKeys         = make(map[string]bool, keysNo)
+			scanSubject, ok    = subject.(xcache.Scanner)
+			cursor             string
+			checkpointedCursor string
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		for i := 0; i < keysNo; i++ {
+			key := prefix + strconv.FormatInt(int64(i), 10)
+			resultErr := subject.Save(ctx, key, value, exp)
+			requireNil(t, resultErr)
+			expectedKeys[key] = true
+		}
+		resultErr := subject.Save(ctx, otherKey, value, exp)
+		requireNil(t, resultErr)
+
+		// act & assert first batch, cursor is checkpointed
+		keys, cursor, resultErr := scanSubject.Scan(ctx, cursor, prefix, 5)
+		requireNil(t, resultErr)
+		for _, key := range keys {
+			resultKeys[key] = true
+		}
+		checkpointedCursor = cursor
+
+		// act & assert resume from checkpointed cursor until the end
+		for cursor = checkpointedCursor; cursor != ""; {
+			keys, cursor, resultErr = scanSubject.Scan(ctx, cursor, prefix, 5)
+			requireNil(t, resultErr)
+			for _, key := range keys {
+				resultKeys[key] = true
+			}
+		}
+		assertEqual(t, expectedKeys, resultKeys)
+
+		// act & assert invalid cursor
+		_, _, resultErr = scanSubject.Scan(ctx, "not a valid cursor", prefix, 5)
+		assertTrue(t, errors.Is(resultErr, xcache.ErrInvalidCursor))
+	}
+}
+
+func testCacheHas(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key            = "test-has-key"
+			notExistKey    = "test-has-not-exist-key"
+			value          = []byte("test value")
+			ctx            = context.Background()
+			hasSubject, ok = subject.(xcache.ExistenceChecker)
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		resultErr := subject.Save(ctx, key, value, time.Minute)
+		requireNil(t, resultErr)
+
+		// act & assert existing key
+		resultFound, resultErr := hasSubject.Has(ctx, key)
+		assertNil(t, resultErr)
+		assertTrue(t, resultFound)
+
+		// act & assert not existing key
+		resultFound, resultErr = hasSubject.Has(ctx, notExistKey)
+		assertNil(t, resultErr)
+		assertTrue(t, !resultFound)
+	}
+}
+
+func testCacheSaveManyLoadMany(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key1             = "test-save-many-key-1"
+			key2             = "test-save-many-key-2"
+			deletedKey       = "test-save-many-deleted-key"
+			notExistKey      = "test-save-many-not-exist-key"
+			value1           = []byte("test value 1")
+			value2           = []byte("test value 2")
+			ctx              = context.Background()
+			batchSubject, ok = subject.(xcache.Batcher)
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		requireNil(t, subject.Save(ctx, deletedKey, value1, time.Minute))
+
+		// act & assert save many
+		resultErr := batchSubject.SaveMany(ctx, map[string]xcache.Item{
+			key1:       {Value: value1, Expire: time.Minute},
+			key2:       {Value: value2, Expire: xcache.NoExpire},
+			deletedKey: {Expire: -1},
+		})
+		requireNil(t, resultErr)
+
+		// act & assert load many
+		resultValues, resultErr := batchSubject.LoadMany(ctx, []string{key1, key2, deletedKey, notExistKey})
+		assertNil(t, resultErr)
+		assertEqual(t, map[string][]byte{key1: value1, key2: value2}, resultValues)
+
+		// act & assert ttl
+		resultTTL, resultErr := subject.TTL(ctx, key1)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTTL > 0 && resultTTL <= time.Minute)
+		resultTTL, resultErr = subject.TTL(ctx, key2)
+		assertNil(t, resultErr)
+		assertEqual(t, xcache.NoExpire, resultTTL)
+	}
+}
+
+func testCacheDeleteMany(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			keys              = []string{"test-delete-many-key-1", "test-delete-many-key-2", "test-delete-many-key-3"}
+			notExistKey       = "test-delete-many-not-exist-key"
+			value             = []byte("test value")
+			ctx               = context.Background()
+			deleteSubject, ok = subject.(xcache.BulkDeleter)
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		for _, key := range keys {
+			requireNil(t, subject.Save(ctx, key, value, time.Minute))
+		}
+
+		// act
+		resultErr := deleteSubject.DeleteMany(ctx, append(keys, notExistKey)...)
+
+		// assert
+		assertNil(t, resultErr)
+		for _, key := range keys {
+			_, resultErr = subject.Load(ctx, key)
+			assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+		}
+	}
+}
+
+func testCacheTouch(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key              = "test-touch-key"
+			notExistKey      = "test-touch-not-exist-key"
+			value            = []byte("test value")
+			ctx              = context.Background()
+			touchSubject, ok = subject.(xcache.Toucher)
+			resultTouched    bool
+			resultErr        error
+			resultTTL        time.Duration
+			resultValue      []byte
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		resultErr = subject.Save(ctx, key, value, time.Minute)
+		requireNil(t, resultErr)
+
+		// act & assert no expire
+		resultTouched, resultErr = touchSubject.Touch(ctx, key, xcache.NoExpire)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTouched)
+		resultTTL, resultErr = subject.TTL(ctx, key)
+		assertNil(t, resultErr)
+		assertEqual(t, xcache.NoExpire, resultTTL)
+
+		// act & assert expire
+		resultTouched, resultErr = touchSubject.Touch(ctx, key, time.Hour)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTouched)
+		resultTTL, resultErr = subject.TTL(ctx, key)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTTL > 0)
+		resultValue, resultErr = subject.Load(ctx, key)
+		assertNil(t, resultErr)
+		assertEqual(t, value, resultValue)
+
+		// act & assert not existing key
+		resultTouched, resultErr = touchSubject.Touch(ctx, notExistKey, time.Hour)
+		assertNil(t, resultErr)
+		assertTrue(t, !resultTouched)
+
+		// act & assert deletion
+		resultTouched, resultErr = touchSubject.Touch(ctx, key, -1)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTouched)
+		_, resultErr = subject.Load(ctx, key)
+		assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+	}
+}
+
+func testCacheSaveKeepTTL(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key            = "test-save-keep-ttl-key"
+			notExistKey    = "test-save-keep-ttl-not-exist-key"
+			value          = []byte("test value")
+			newValue       = []byte("test new value")
+			ctx            = context.Background()
+			keeper, ok     = subject.(xcache.TTLKeeper)
+			resultErr      error
+			resultTTL      time.Duration
+			resultValue    []byte
+			expectedMinTTL = 50 * time.Minute
+		)
+		if !assertTrue(t, ok) {
+			return
+		}
+		resultErr = subject.Save(ctx, key, value, time.Hour)
+		requireNil(t, resultErr)
+
+		// act & assert existing key
+		resultErr = keeper.SaveKeepTTL(ctx, key, newValue)
+		assertNil(t, resultErr)
+		resultValue, resultErr = subject.Load(ctx, key)
+		assertNil(t, resultErr)
+		assertEqual(t, newValue, resultValue)
+		resultTTL, resultErr = subject.TTL(ctx, key)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTTL > expectedMinTTL && resultTTL <= time.Hour)
+
+		// act & assert not existing key
+		resultErr = keeper.SaveKeepTTL(ctx, notExistKey, value)
+		assertNil(t, resultErr)
+		resultValue, resultErr = subject.Load(ctx, notExistKey)
+		assertNil(t, resultErr)
+		assertEqual(t, value, resultValue)
+		resultTTL, resultErr = subject.TTL(ctx, notExistKey)
+		assertNil(t, resultErr)
+		assertEqual(t, xcache.NoExpire, resultTTL)
+
+		_ = subject.Save(ctx, key, nil, -1)
+		_ = subject.Save(ctx, notExistKey, nil, -1)
+	}
+}
+
+func testCacheWithDoneContext(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key            = "test-done-context-key"
+			value          = []byte("test value")
+			ctx, cancelCtx = context.WithCancel(context.Background())
+		)
+		cancelCtx()
+
+		// act & assert save
+		resultErr := subject.Save(ctx, key, value, time.Minute)
+		assertTrue(t, errors.Is(resultErr, context.Canceled))
+
+		// act & assert load
+		_, resultErr = subject.Load(ctx, key)
+		assertTrue(t, errors.Is(resultErr, context.Canceled))
+
+		// act & assert ttl
+		_, resultErr = subject.TTL(ctx, key)
+		assertTrue(t, errors.Is(resultErr, context.Canceled))
+
+		// act & assert stats
+		_, resultErr = subject.Stats(ctx)
+		assertTrue(t, errors.Is(resultErr, context.Canceled))
+
+		// act & assert delete prefix
+		if prefixSubject, ok := subject.(xcache.PrefixDeleter); ok {
+			_, resultErr = prefixSubject.DeletePrefix(ctx, key)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert scan
+		if scanSubject, ok := subject.(xcache.Scanner); ok {
+			_, _, resultErr = scanSubject.Scan(ctx, "", key, 10)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert delete
+		if deleteSubject, ok := subject.(xcache.Deleter); ok {
+			resultErr = deleteSubject.Delete(ctx, key)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert delete many
+		if deleteSubject, ok := subject.(xcache.BulkDeleter); ok {
+			resultErr = deleteSubject.DeleteMany(ctx, key)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert has
+		if hasSubject, ok := subject.(xcache.ExistenceChecker); ok {
+			_, resultErr = hasSubject.Has(ctx, key)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert save many & load many
+		if batchSubject, ok := subject.(xcache.Batcher); ok {
+			resultErr = batchSubject.SaveMany(ctx, map[string]xcache.Item{key: {Value: value, Expire: time.Minute}})
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+			_, resultErr = batchSubject.LoadMany(ctx, []string{key})
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+
+		// act & assert touch
+		if touchSubject, ok := subject.(xcache.Toucher); ok {
+			_, resultErr = touchSubject.Touch(ctx, key, time.Minute)
+			assertTrue(t, errors.Is(resultErr, context.Canceled))
+		}
+	}
+}
+
 func testCacheStats(
 	subject xcache.Cache,
 	expectedMem, expectedMaxMem int64, memCheckOp string,
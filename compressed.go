@@ -0,0 +1,384 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrUnsupportedCompression is returned by a Compressed cache when loading a value
+// compressed with an algorithm it has no Compressor for.
+var ErrUnsupportedCompression = errors.New("unsupported compression algorithm")
+
+// Compression algorithms identifiers, stored alongside compressed values.
+// Compressor implementations of these algorithms should use them, so values can be shared.
+const (
+	CompressionGzip   uint8 = 1
+	CompressionSnappy uint8 = 2
+	CompressionZstd   uint8 = 3
+)
+
+// Compressor compresses / decompresses the values of a Compressed cache.
+// GzipCompressor, SnappyCompressor and ZstdCompressor are provided.
+type Compressor interface {
+	// Algorithm returns the (non-zero) identifier of the compression algorithm.
+	Algorithm() uint8
+	// Compress appends to dst the compressed src, and returns the extended buffer.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress appends to dst the decompressed src, and returns the extended buffer.
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// GzipCompressor is a Compressor which relies upon [compress/gzip] package.
+// Gzip writers / readers are reused.
+type GzipCompressor struct {
+	level   int
+	writers sync.Pool
+	readers sync.Pool
+}
+
+// NewGzipCompressor instantiates a new GzipCompressor, with given compression level.
+// A level of 0 means gzip.DefaultCompression.
+func NewGzipCompressor(level int) *GzipCompressor {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	return &GzipCompressor{level: level}
+}
+
+// Algorithm returns CompressionGzip.
+func (*GzipCompressor) Algorithm() uint8 {
+	return CompressionGzip
+}
+
+// Compress appends to dst the gzip compressed src, and returns the extended buffer.
+func (c *GzipCompressor) Compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	zw, _ := c.writers.Get().(*gzip.Writer)
+	if zw == nil {
+		var err error
+		if zw, err = gzip.NewWriterLevel(buf, c.level); err != nil {
+			return dst, err
+		}
+	} else {
+		zw.Reset(buf)
+	}
+	defer c.writers.Put(zw)
+
+	if _, err := zw.Write(src); err != nil {
+		return dst, err
+	}
+	if err := zw.Close(); err != nil {
+		return dst, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress appends to dst the decompressed gzip src, and returns the extended buffer.
+func (c *GzipCompressor) Decompress(dst, src []byte) ([]byte, error) {
+	var (
+		zr, _ = c.readers.Get().(*gzip.Reader)
+		err   error
+	)
+	if zr == nil {
+		if zr, err = gzip.NewReader(bytes.NewReader(src)); err != nil {
+			return dst, err
+		}
+	} else if err = zr.Reset(bytes.NewReader(src)); err != nil {
+		return dst, err
+	}
+	defer c.readers.Put(zr)
+
+	buf := bytes.NewBuffer(dst)
+	if _, err = buf.ReadFrom(zr); err != nil {
+		return dst, err
+	}
+	if err = zr.Close(); err != nil {
+		return dst, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SnappyCompressor is a Compressor which produces the Snappy block format,
+// relying upon [github.com/klauspost/compress/s2] package.
+// It's faster, but compresses less, than gzip / zstd.
+type SnappyCompressor struct{}
+
+// NewSnappyCompressor instantiates a new SnappyCompressor.
+func NewSnappyCompressor() SnappyCompressor {
+	return SnappyCompressor{}
+}
+
+// Algorithm returns CompressionSnappy.
+func (SnappyCompressor) Algorithm() uint8 {
+	return CompressionSnappy
+}
+
+// Compress appends to dst the snappy compressed src, and returns the extended buffer.
+func (SnappyCompressor) Compress(dst, src []byte) ([]byte, error) {
+	maxLen := s2.MaxEncodedLen(len(src))
+	if maxLen < 0 {
+		return dst, s2.ErrTooLarge
+	}
+	dst = growBytes(dst, maxLen)
+	compressed := s2.EncodeSnappy(dst[len(dst):], src)
+
+	return dst[:len(dst)+len(compressed)], nil
+}
+
+// Decompress appends to dst the decompressed snappy src, and returns the extended buffer.
+func (SnappyCompressor) Decompress(dst, src []byte) ([]byte, error) {
+	decodedLen, err := s2.DecodedLen(src)
+	if err != nil {
+		return dst, err
+	}
+	dst = growBytes(dst, decodedLen)
+	decompressed, err := s2.Decode(dst[len(dst):], src)
+	if err != nil {
+		return dst, err
+	}
+
+	return dst[:len(dst)+len(decompressed)], nil
+}
+
+// ZstdCompressor is a Compressor which relies upon [github.com/klauspost/compress/zstd] package.
+// It compresses better than gzip, at a similar, or better, speed.
+// It's safe for concurrent use.
+type ZstdCompressor struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// NewZstdCompressor instantiates a new ZstdCompressor, with given compression level,
+// a zstd level (1 - 22), mapped to the closest level supported by the encoder (see zstd.EncoderLevelFromZstd).
+// A level of 0 means zstd.SpeedDefault.
+func NewZstdCompressor(level int) *ZstdCompressor {
+	encLevel := zstd.SpeedDefault
+	if level != 0 {
+		encLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	// options are valid, and there is no stream to read from / write to, so no error can occur.
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(encLevel))
+	dec, _ := zstd.NewReader(nil)
+
+	return &ZstdCompressor{
+		enc: enc,
+		dec: dec,
+	}
+}
+
+// Algorithm returns CompressionZstd.
+func (*ZstdCompressor) Algorithm() uint8 {
+	return CompressionZstd
+}
+
+// Compress appends to dst the zstd compressed src, and returns the extended buffer.
+func (c *ZstdCompressor) Compress(dst, src []byte) ([]byte, error) {
+	return c.enc.EncodeAll(src, dst), nil
+}
+
+// Decompress appends to dst the decompressed zstd src, and returns the extended buffer.
+func (c *ZstdCompressor) Decompress(dst, src []byte) ([]byte, error) {
+	return c.dec.DecodeAll(src, dst)
+}
+
+// growBytes returns given buffer, having capacity for at least n more bytes.
+func growBytes(buf []byte, n int) []byte {
+	if cap(buf)-len(buf) >= n {
+		return buf
+	}
+	grown := make([]byte, len(buf), len(buf)+n)
+	copy(grown, buf)
+
+	return grown
+}
+
+// Compressed is a Cache decorator which compresses values on Save, and decompresses them on Load,
+// trading some CPU for memory (and network transfer) in decorated cache. It fits large, compressible, values,
+// like HTML fragments or JSON documents.
+//
+// Each value is stored with a small header (an Envelope) recording whether its payload is compressed,
+// and with which algorithm, so that:
+//   - values smaller than a threshold (see WithThreshold), and values which do not shrink by compression,
+//     are stored raw (with a 3 bytes header);
+//   - values compressed with other algorithms can still be loaded (see WithDecompressors),
+//     while switching from an algorithm to another;
+//   - values not having a header (saved directly into decorated cache, before enabling compression, for example)
+//     are returned as they are.
+//
+// Example:
+//
+//	cache := xcache.NewCompressed(redisCache, xcache.NewGzipCompressor(gzip.BestSpeed)).WithThreshold(1024)
+type Compressed struct {
+	cache         Cache
+	compressor    Compressor
+	threshold     int64 // accessed atomically.
+	decompressors map[uint8]Compressor
+	rawBytes      int64 // the no. of bytes of saved values.
+	storedBytes   int64 // the no. of bytes of saved values, as stored (compressed, or not, with header).
+}
+
+// NewCompressed instantiates a new Compressed which decorates given cache,
+// compressing values with given compressor.
+func NewCompressed(cache Cache, compressor Compressor) *Compressed {
+	return &Compressed{
+		cache:         cache,
+		compressor:    compressor,
+		decompressors: map[uint8]Compressor{compressor.Algorithm(): compressor},
+	}
+}
+
+// WithThreshold returns a copy of the Compressed cache which stores raw values smaller than given no. of bytes,
+// as compressing them is not worth the CPU (their size is not reduced, or is reduced with a few bytes only).
+// By default, all values are compressed.
+func (cache *Compressed) WithThreshold(threshold int) *Compressed {
+	c := cache.clone()
+	c.threshold = int64(threshold)
+
+	return c
+}
+
+// Threshold returns current threshold, the no. of bytes under which values are stored raw.
+func (cache *Compressed) Threshold() int {
+	return int(atomic.LoadInt64(&cache.threshold))
+}
+
+// SetThreshold changes the threshold, the no. of bytes under which values are stored raw, at runtime
+// (see also NewCompressedWithConfig for having it driven by a xconf.Config).
+// Unlike WithThreshold, the cache itself is changed.
+func (cache *Compressed) SetThreshold(threshold int) {
+	atomic.StoreInt64(&cache.threshold, int64(threshold))
+}
+
+// WithDecompressors returns a copy of the Compressed cache which also loads values compressed with
+// given compressors' algorithms (values are still saved compressed with the configured compressor only).
+// It's useful when switching from an algorithm to another, in order to load the values saved with the old one.
+func (cache *Compressed) WithDecompressors(compressors ...Compressor) *Compressed {
+	c := cache.clone()
+	c.decompressors = make(map[uint8]Compressor, len(cache.decompressors)+len(compressors))
+	for _, compressor := range compressors {
+		c.decompressors[compressor.Algorithm()] = compressor
+	}
+	for algorithm, compressor := range cache.decompressors {
+		c.decompressors[algorithm] = compressor
+	}
+
+	return c
+}
+
+// Save stores the given key-value, compressed (unless it's smaller than the threshold,
+// or its size is not reduced by compression), into decorated cache.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the value cannot be compressed.
+func (cache *Compressed) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.cache.Save(ctx, key, value, expire)
+	}
+
+	var (
+		dst       = make([]byte, 0, (Envelope{}).Size(len(value))) // compressed values are smaller.
+		enveloped []byte
+	)
+	if len(value) >= cache.Threshold() {
+		env := Envelope{Compression: cache.compressor.Algorithm()}
+		header := env.Append(dst, nil)
+		compressed, err := cache.compressor.Compress(header, value)
+		if err != nil {
+			return err
+		}
+		if len(compressed)-len(header) < len(value) {
+			enveloped = compressed
+		}
+	}
+	if enveloped == nil { // store it raw.
+		var env Envelope
+		enveloped = env.Append(dst[:0], value)
+	}
+	atomic.AddInt64(&cache.rawBytes, int64(len(value)))
+	atomic.AddInt64(&cache.storedBytes, int64(len(enveloped)))
+
+	return cache.cache.Save(ctx, key, enveloped, expire)
+}
+
+// Load returns a key's value from decorated cache, decompressed.
+// It returns ErrUnsupportedCompression if the value is compressed with an algorithm
+// other than the configured one, or the ones of the compressors provided through WithDecompressors.
+func (cache *Compressed) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil || !IsEnvelope(value) {
+		return value, err
+	}
+
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return value, nil // not a value of ours, return it as it is.
+	}
+	if env.Compression == 0 {
+		return payload, nil
+	}
+	decompressor, found := cache.decompressors[env.Compression]
+	if !found {
+		return nil, ErrUnsupportedCompression
+	}
+
+	return decompressor.Decompress(nil, payload)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *Compressed) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *Compressed) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// ContributeStats reports the no. of bytes of saved values, as "compressed.raw_bytes" metric,
+// the no. of bytes they were stored with, as "compressed.stored_bytes" metric,
+// and the compression ratio (raw / stored bytes), as "compressed.ratio" metric.
+func (cache *Compressed) ContributeStats(add func(name string, value float64)) {
+	rawBytes := atomic.LoadInt64(&cache.rawBytes)
+	storedBytes := atomic.LoadInt64(&cache.storedBytes)
+	add("compressed.raw_bytes", float64(rawBytes))
+	add("compressed.stored_bytes", float64(storedBytes))
+	if storedBytes > 0 {
+		add("compressed.ratio", float64(rawBytes)/float64(storedBytes))
+	}
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Compressed) Unwrap() Cache {
+	return cache.cache
+}
+
+// clone returns a copy of the Compressed cache, having its own statistics.
+func (cache *Compressed) clone() *Compressed {
+	return &Compressed{
+		cache:         cache.cache,
+		compressor:    cache.compressor,
+		threshold:     atomic.LoadInt64(&cache.threshold),
+		decompressors: cache.decompressors,
+	}
+}
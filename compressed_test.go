@@ -0,0 +1,353 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Compressed)(nil)          // test Compressed is a Cache
+	var _ xcache.Compressor = (*xcache.GzipCompressor)(nil) // test GzipCompressor is a Compressor
+	var _ xcache.Compressor = xcache.SnappyCompressor{}     // test SnappyCompressor is a Compressor
+	var _ xcache.Compressor = (*xcache.ZstdCompressor)(nil) // test ZstdCompressor is a Compressor
+}
+
+func TestCompressed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value is compressed and decompressed", testCompressedValueIsCompressedAndDecompressed)
+	t.Run("small value is stored raw", testCompressedSmallValueIsStoredRaw)
+	t.Run("incompressible value is stored raw", testCompressedIncompressibleValueIsStoredRaw)
+	t.Run("value compressed with another algorithm is loaded", testCompressedValueCompressedWithAnotherAlgorithmIsLoaded)
+	t.Run("value without envelope is returned", testCompressedValueWithoutEnvelopeIsReturned)
+	t.Run("unsupported compression", testCompressedUnsupportedCompression)
+	t.Run("deletion is passed through", testCompressedDeletionIsPassedThrough)
+	t.Run("ttl and stats are passed through", testCompressedTTLAndStatsArePassedThrough)
+}
+
+func testCompressedValueIsCompressedAndDecompressed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(8 * 1024 * 1024)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(gzip.BestSpeed))
+		ctx     = context.Background()
+		key     = "test-compressed-key"
+		value   = bytes.Repeat([]byte("<div class=\"product\">test product</div>"), 1000)
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	stored, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	assertTrue(t, len(stored) < len(value)/10)
+	var env xcache.Envelope
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, xcache.CompressionGzip, env.Compression)
+
+	// act & assert empty value
+	errSave = subject.Save(ctx, key, []byte{}, time.Minute)
+	result, errLoad = subject.Load(ctx, key)
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, 0, len(result))
+}
+
+func testCompressedSmallValueIsStoredRaw(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = xcache.NewMemory(8 * 1024 * 1024)
+		compressed = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		subject    = compressed.WithThreshold(1024)
+		ctx        = context.Background()
+		key        = "test-compressed-small-key"
+		value      = bytes.Repeat([]byte("a"), 1023)
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	stored, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	assertEqual(t, len(value)+3, len(stored))
+	var env xcache.Envelope
+	payload, err := env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, uint8(0), env.Compression)
+	assertEqual(t, value, payload)
+
+	// act & assert value having threshold size is compressed
+	value = append(value, 'a')
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	stored, err = cache.Load(ctx, key)
+	requireNil(t, err)
+	assertTrue(t, len(stored) < len(value))
+
+	// act & assert original Compressed cache is not affected
+	value = []byte("a")
+	requireNil(t, compressed.Save(ctx, key, bytes.Repeat(value, 100), time.Minute))
+	stored, err = cache.Load(ctx, key)
+	requireNil(t, err)
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, xcache.CompressionGzip, env.Compression)
+}
+
+func testCompressedIncompressibleValueIsStoredRaw(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(8 * 1024 * 1024)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		ctx     = context.Background()
+		key     = "test-compressed-incompressible-key"
+		value   = make([]byte, 4096)
+	)
+	_, _ = rand.Read(value)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	stored, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	var env xcache.Envelope
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, uint8(0), env.Compression)
+}
+
+func testCompressedValueCompressedWithAnotherAlgorithmIsLoaded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(8 * 1024 * 1024)
+		oldAlgo = xcache.NewZstdCompressor(0)
+		old     = xcache.NewCompressed(cache, oldAlgo)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0)).WithDecompressors(oldAlgo)
+		ctx     = context.Background()
+		oldKey  = "test-compressed-old-algorithm-key"
+		newKey  = "test-compressed-new-algorithm-key"
+		value   = bytes.Repeat([]byte("test value "), 100)
+	)
+	requireNil(t, old.Save(ctx, oldKey, value, time.Minute))
+
+	// act
+	errSave := subject.Save(ctx, newKey, value, time.Minute)
+	resultOld, errLoadOld := subject.Load(ctx, oldKey)
+	resultNew, errLoadNew := subject.Load(ctx, newKey)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoadOld)
+	assertEqual(t, value, resultOld)
+	assertNil(t, errLoadNew)
+	assertEqual(t, value, resultNew)
+	stored, err := cache.Load(ctx, newKey)
+	requireNil(t, err)
+	var env xcache.Envelope
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, xcache.CompressionGzip, env.Compression)
+}
+
+func testCompressedValueWithoutEnvelopeIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(8 * 1024 * 1024)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		ctx     = context.Background()
+		key     = "test-compressed-legacy-key"
+		value   = []byte("test legacy value")
+	)
+	requireNil(t, cache.Save(ctx, key, value, time.Minute))
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+}
+
+func testCompressedUnsupportedCompression(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(8 * 1024 * 1024)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		ctx     = context.Background()
+		key     = "test-compressed-zstd-key"
+		env     = xcache.Envelope{Compression: xcache.CompressionZstd}
+	)
+	requireNil(t, cache.Save(ctx, key, env.Append(nil, []byte("test zstd compressed value")), time.Minute))
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrUnsupportedCompression))
+	assertNil(t, result)
+}
+
+func testCompressedDeletionIsPassedThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		ctx     = context.Background()
+		key     = "test-compressed-delete-key"
+	)
+	cache.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, key, k)
+		assertNil(t, v)
+		assertEqual(t, time.Duration(-1), exp)
+
+		return nil
+	})
+
+	// act
+	err := subject.Save(ctx, key, nil, -1)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}
+
+func testCompressedTTLAndStatsArePassedThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		ctx     = context.Background()
+	)
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return time.Minute, nil
+	})
+	cache.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		return xcache.Stats{Keys: 5}, nil
+	})
+
+	// act
+	resultTTL, errTTL := subject.TTL(ctx, "test-compressed-ttl-key")
+	resultStats, errStats := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, errTTL)
+	assertEqual(t, time.Minute, resultTTL)
+	assertNil(t, errStats)
+	assertEqual(t, int64(5), resultStats.Keys)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func TestCompressors(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name              string
+		subject           xcache.Compressor
+		expectedAlgorithm uint8
+	}{
+		{
+			name:              "gzip",
+			subject:           xcache.NewGzipCompressor(gzip.BestCompression),
+			expectedAlgorithm: xcache.CompressionGzip,
+		},
+		{
+			name:              "snappy",
+			subject:           xcache.NewSnappyCompressor(),
+			expectedAlgorithm: xcache.CompressionSnappy,
+		},
+		{
+			name:              "zstd",
+			subject:           xcache.NewZstdCompressor(3),
+			expectedAlgorithm: xcache.CompressionZstd,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				value  = bytes.Repeat([]byte("test value "), 100)
+				prefix = []byte("prefix")
+			)
+
+			// act
+			compressed, errCompress := test.subject.Compress(append([]byte(nil), prefix...), value)
+			decompressed, errDecompress := test.subject.Decompress(
+				append([]byte(nil), prefix...),
+				compressed[len(prefix):],
+			)
+
+			// assert
+			assertNil(t, errCompress)
+			assertNil(t, errDecompress)
+			assertEqual(t, prefix, compressed[:len(prefix)])
+			assertTrue(t, len(compressed)-len(prefix) < len(value)/10)
+			assertEqual(t, append(append([]byte(nil), prefix...), value...), decompressed)
+			assertEqual(t, test.expectedAlgorithm, test.subject.Algorithm())
+
+			// act & assert dst with enough capacity
+			compressed, errCompress = test.subject.Compress(make([]byte, 0, 2*len(value)), value)
+			requireNil(t, errCompress)
+			decompressed, errDecompress = test.subject.Decompress(make([]byte, 0, 2*len(value)), compressed)
+			assertNil(t, errDecompress)
+			assertEqual(t, value, decompressed)
+
+			// act & assert empty value
+			compressed, errCompress = test.subject.Compress(nil, nil)
+			requireNil(t, errCompress)
+			decompressed, errDecompress = test.subject.Decompress(nil, compressed)
+			assertNil(t, errDecompress)
+			assertEqual(t, 0, len(decompressed))
+
+			// act & assert invalid data
+			_, errDecompress = test.subject.Decompress(nil, []byte("not compressed data"))
+			assertNotNil(t, errDecompress)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"github.com/actforgood/xconf"
+)
+
+// CompressedCfgKeyThreshold is the key under which xconf.Config expects the no. of bytes
+// under which values are stored raw, see Compressed.WithThreshold.
+const CompressedCfgKeyThreshold = "xcache.compressed.threshold"
+
+// NewCompressedWithConfig initializes a Compressed which decorates given cache, compressing values
+// with given compressor, having the threshold taken from a xconf.Config (under CompressedCfgKeyThreshold).
+// The threshold can be given as a byte size string like "1KB". An invalid (or missing) threshold
+// is replaced with 0 (all values are compressed), use NewCompressedWithValidConfig to fail on it instead.
+//
+// An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
+// In case the threshold is changed, it is applied right away, without the need of
+// restarting your application. An invalid configuration reload is disregarded.
+//
+// Note: the observer is registered for the returned object; copies made afterwards with
+// WithThreshold / WithDecompressors are not updated.
+func NewCompressedWithConfig(cache Cache, compressor Compressor, config xconf.Config) *Compressed {
+	threshold, _ := getCompressedThreshold(config)
+	compressed := NewCompressed(cache, compressor)
+	compressed.SetThreshold(threshold)
+
+	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
+		defConfig.RegisterObserver(compressed.onConfigChange)
+	}
+
+	return compressed
+}
+
+// NewCompressedWithValidConfig is like NewCompressedWithConfig, but it fails on an invalid threshold:
+// an error aggregating a ConfigError is returned, and compressed is nil.
+func NewCompressedWithValidConfig(cache Cache, compressor Compressor, config xconf.Config) (*Compressed, error) {
+	if err := ValidateCompressedXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewCompressedWithConfig(cache, compressor, config), nil
+}
+
+// getCompressedThreshold returns the threshold taken from a xconf.Config.
+// An invalid value is replaced with 0, and an error aggregating a ConfigError is returned.
+func getCompressedThreshold(config xconf.Config) (int, error) {
+	r := newXConfReader(config)
+	threshold := r.ByteSize(CompressedCfgKeyThreshold, 0)
+	if threshold < 0 {
+		r.addErr(CompressedCfgKeyThreshold, errConfigValueRange)
+		threshold = 0
+	}
+
+	return threshold, r.Err()
+}
+
+// ValidateCompressedXConfig checks the Compressed configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for a threshold which cannot be interpreted,
+// or nil if configuration is valid.
+func ValidateCompressedXConfig(config xconf.Config) error {
+	_, err := getCompressedThreshold(config)
+
+	return err
+}
+
+// onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
+// In case CompressedCfgKeyThreshold config is changed, the new threshold is applied, if it is valid.
+// This callback is automatically registered on instantiation of a Compressed object with NewCompressedWithConfig.
+func (cache *Compressed) onConfigChange(config xconf.Config, changedKeys ...string) {
+	configHasChanged := false
+	for _, changedKey := range changedKeys {
+		if changedKey == CompressedCfgKeyThreshold {
+			configHasChanged = true
+
+			break
+		}
+	}
+	if !configHasChanged {
+		return
+	}
+
+	threshold, err := getCompressedThreshold(config)
+	if err != nil { // keep current threshold.
+		return
+	}
+	cache.SetThreshold(threshold)
+}
@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"compress/gzip"
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestCompressed_withXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig       uint32
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			switch atomic.LoadUint32(&reloadConfig) {
+			case 1:
+				return map[string]any{xcache.CompressedCfgKeyThreshold: "2KB"}, nil
+			case 2:
+				return map[string]any{xcache.CompressedCfgKeyThreshold: "huge"}, nil
+			}
+
+			return map[string]any{xcache.CompressedCfgKeyThreshold: 512}, nil
+		})
+		subject = xcache.NewCompressedWithConfig(
+			xcache.NewMemory(freecacheMinMem),
+			xcache.NewGzipCompressor(gzip.BestSpeed),
+			config,
+		)
+	)
+
+	// act & assert initial config
+	assertEqual(t, 512, subject.Threshold())
+
+	// act & assert reloaded config
+	atomic.StoreUint32(&reloadConfig, 1)
+	waitReload()
+	assertEqual(t, 2048, subject.Threshold())
+
+	// act & assert invalid reloaded config is disregarded
+	atomic.StoreUint32(&reloadConfig, 2)
+	waitReload()
+	assertEqual(t, 2048, subject.Threshold())
+}
+
+func TestValidateCompressedXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing value",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name:   "valid value",
+			config: xconf.NewMockConfig(xcache.CompressedCfgKeyThreshold, "1KB"),
+		},
+		{
+			name:            "invalid value",
+			config:          xconf.NewMockConfig(xcache.CompressedCfgKeyThreshold, "huge"),
+			expectedErrKeys: []string{xcache.CompressedCfgKeyThreshold},
+		},
+		{
+			name:            "out of range value",
+			config:          xconf.NewMockConfig(xcache.CompressedCfgKeyThreshold, -1),
+			expectedErrKeys: []string{xcache.CompressedCfgKeyThreshold},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateCompressedXConfig(test.config)
+			compressed, resultCtorErr := xcache.NewCompressedWithValidConfig(
+				xcache.Nop{},
+				xcache.NewGzipCompressor(gzip.BestSpeed),
+				test.config,
+			)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, compressed != nil)
+		})
+	}
+}
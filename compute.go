@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// computeGroup deduplicates the in-process computations of LoadOrCompute.
+var computeGroup singleflight.Group
+
+// ComputeFunc computes a key's value from the source of truth (a database, a service, etc.).
+type ComputeFunc func(ctx context.Context) ([]byte, error)
+
+// LoadOrCompute returns a key's value from given cache, or, if it's missing, computes it with given function
+// and saves it into cache, with given expiration period.
+// Concurrent in-process calls for the same cache and key share the computation (singleflight):
+// only one goroutine computes the value, while the others wait for it, preventing a cache stampede
+// on the source of truth when a hot key expires (see also NewJittered, for spreading keys' expirations).
+//
+// Cache errors are not returned (the value is computed, as if the key was not found),
+// nor is a failure to save the computed value. Errors returned by the compute function are returned
+// (to all the callers waiting for the computation), and nothing is cached.
+// The computation runs with the context of the caller who triggered it, without its cancellation
+// (see context.WithoutCancel), as it's shared: a caller whose context is done stops waiting,
+// returning its context's error, without failing the computation for the other callers.
+// If the cache is a NegativeCache, ErrKnownMissing is returned for a key known to be missing, without computing it,
+// and a compute function's error wrapping ErrNotFound results in the key being saved as missing.
+//
+// Example:
+//
+//	value, err := xcache.LoadOrCompute(ctx, cache, "product:"+id, time.Hour, func(ctx context.Context) ([]byte, error) {
+//		return productsRepo.GetJSON(ctx, id)
+//	})
+func LoadOrCompute(
+	ctx context.Context,
+	cache Cache,
+	key string,
+	expire time.Duration,
+	compute ComputeFunc,
+) ([]byte, error) {
+	if value, err := cache.Load(ctx, key); err == nil || errors.Is(err, ErrKnownMissing) {
+		return value, err
+	}
+
+	resultCh := computeGroup.DoChan(cacheIdentity(cache)+"\x00"+key, func() (any, error) {
+		sharedCtx := context.WithoutCancel(ctx)
+		// the key may have been computed meanwhile, by a computation which just finished.
+		if value, err := cache.Load(sharedCtx, key); err == nil || errors.Is(err, ErrKnownMissing) {
+			return value, err
+		}
+		value, err := compute(sharedCtx)
+		if err != nil {
+			if negative, ok := cache.(*NegativeCache); ok && errors.Is(err, ErrNotFound) {
+				_ = negative.SaveMissing(sharedCtx, key)
+			}
+
+			return nil, err
+		}
+		_ = cache.Save(sharedCtx, key, value, expire)
+
+		return value, nil
+	})
+
+	return awaitComputation(ctx, resultCh)
+}
+
+// awaitComputation waits for a computation's result, or for the context to be done.
+// It's the only place a caller's context is checked, the computation itself being shared.
+func awaitComputation(ctx context.Context, resultCh <-chan singleflight.Result) ([]byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		value, _ := result.Val.([]byte)
+		if result.Shared {
+			value = append([]byte(nil), value...) // callers can modify their values.
+		}
+
+		return value, nil
+	}
+}
+
+// cacheIdentity returns an identifier of given cache, so that computations of the same key
+// for different caches (like namespaced views of a cache) are not shared.
+func cacheIdentity(cache Cache) string {
+	if multi, ok := cache.(Multi); ok {
+		ids := make([]string, 0, len(multi.caches))
+		for _, layer := range multi.caches {
+			ids = append(ids, cacheIdentity(layer))
+		}
+
+		return "multi(" + strings.Join(ids, ",") + ")"
+	}
+	if value := reflect.ValueOf(cache); value.Kind() == reflect.Pointer {
+		return fmt.Sprintf("%T@%x", cache, value.Pointer())
+	}
+
+	return fmt.Sprintf("%T%+v", cache, cache)
+}
@@ -0,0 +1,251 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestLoadOrCompute(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent calls share the computation", testLoadOrComputeConcurrentCallsShareTheComputation)
+	t.Run("cached value is returned", testLoadOrComputeCachedValueIsReturned)
+	t.Run("compute error is returned", testLoadOrComputeComputeErrIsReturned)
+	t.Run("different caches do not share the computation", testLoadOrComputeDifferentCachesDoNotShare)
+	t.Run("waiter's done context", testLoadOrComputeWaitersDoneContext)
+	t.Run("leader's canceled context - waiter still succeeds", testLoadOrComputeLeadersCanceledContext)
+}
+
+func testLoadOrComputeConcurrentCallsShareTheComputation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = xcache.NewMemory(freecacheMinMem)
+		ctx        = context.Background()
+		key        = "test-compute-shared-key"
+		value      = []byte("test value")
+		goroutines = 50
+		computes   int32
+		release    = make(chan struct{})
+		results    = make([][]byte, goroutines)
+		errs       = make([]error, goroutines)
+		wg         sync.WaitGroup
+	)
+	compute := func(context.Context) ([]byte, error) {
+		atomic.AddInt32(&computes, 1)
+		<-release
+
+		return value, nil
+	}
+
+	// act
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			results[idx], errs[idx] = xcache.LoadOrCompute(ctx, cache, key, time.Minute, compute)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond) // let goroutines pile up.
+	close(release)
+	wg.Wait()
+
+	// assert
+	assertEqual(t, int32(1), atomic.LoadInt32(&computes))
+	for i := 0; i < goroutines; i++ {
+		assertNil(t, errs[i])
+		assertEqual(t, value, results[i])
+	}
+	cached, err := cache.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, cached)
+	ttl, err := cache.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 0 && ttl <= time.Minute)
+}
+
+func testLoadOrComputeCachedValueIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache = xcache.NewMemory(freecacheMinMem)
+		ctx   = context.Background()
+		key   = "test-compute-cached-key"
+		value = []byte("test cached value")
+	)
+	requireNil(t, cache.Save(ctx, key, value, time.Minute))
+
+	// act
+	result, err := xcache.LoadOrCompute(ctx, cache, key, time.Minute, func(context.Context) ([]byte, error) {
+		t.Error("compute should not be called")
+
+		return nil, nil
+	})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+}
+
+func testLoadOrComputeComputeErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = xcache.NewMemory(freecacheMinMem)
+		ctx         = context.Background()
+		key         = "test-compute-err-key"
+		expectedErr = errors.New("intentionally triggered compute error")
+	)
+
+	// act
+	result, err := xcache.LoadOrCompute(ctx, cache, key, time.Minute, func(context.Context) ([]byte, error) {
+		return nil, expectedErr
+	})
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, result)
+	_, err = cache.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testLoadOrComputeDifferentCachesDoNotShare(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = xcache.NewMemory(freecacheMinMem)
+		users    = xcache.WithNamespace(cache, "users:")
+		products = xcache.WithNamespace(cache, "products:")
+		ctx      = context.Background()
+		key      = "1"
+		release  = make(chan struct{})
+		wg       sync.WaitGroup
+		results  = make([][]byte, 2)
+	)
+
+	// act
+	for i, subject := range []xcache.Cache{users, products} {
+		wg.Add(1)
+		go func(idx int, subject xcache.Cache) {
+			defer wg.Done()
+			results[idx], _ = xcache.LoadOrCompute(ctx, subject, key, time.Minute, func(context.Context) ([]byte, error) {
+				<-release
+
+				return []byte{byte('a' + idx)}, nil
+			})
+		}(i, subject)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// assert
+	assertEqual(t, []byte("a"), results[0])
+	assertEqual(t, []byte("b"), results[1])
+}
+
+func testLoadOrComputeWaitersDoneContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		key     = "test-compute-waiter-key"
+		release = make(chan struct{})
+		started = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		_, _ = xcache.LoadOrCompute(context.Background(), cache, key, time.Minute, func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+
+			return []byte("test value"), nil
+		})
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// act
+	result, err := xcache.LoadOrCompute(ctx, cache, key, time.Minute, func(context.Context) ([]byte, error) {
+		t.Error("compute should not be called")
+
+		return nil, nil
+	})
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertNil(t, result)
+	close(release)
+	<-done
+}
+
+func testLoadOrComputeLeadersCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache                 = new(xcache.Mock) // every key is missing.
+		key                   = "test-compute-leader-key"
+		value                 = []byte("test value")
+		release               = make(chan struct{})
+		started               = make(chan struct{})
+		leaderCtx, cancelLead = context.WithCancel(context.Background())
+		leaderErr             = make(chan error, 1)
+	)
+	defer cancelLead()
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, xcache.ErrNotFound
+	})
+	go func() {
+		_, err := xcache.LoadOrCompute(leaderCtx, cache, key, time.Minute, func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-release
+
+			return value, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-started
+	waiterResult := make(chan []byte, 1)
+	go func() {
+		result, err := xcache.LoadOrCompute(context.Background(), cache, key, time.Minute,
+			func(context.Context) ([]byte, error) {
+				return nil, errors.New("compute should not be called")
+			})
+		assertNil(t, err)
+		waiterResult <- result
+	}()
+	for cache.LoadCallsCount() < 3 { // leader's loads (before, and within the computation), waiter's load.
+		runtime.Gosched()
+	}
+
+	// act
+	cancelLead()
+	errLeader := <-leaderErr
+	close(release)
+	result := <-waiterResult
+
+	// assert
+	assertTrue(t, errors.Is(errLeader, context.Canceled))
+	assertEqual(t, value, result)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}
@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// DeadlineAware is a Cache decorator which skips reading from the decorated (usually remote) cache
+// when the remaining time until the context's deadline is below a threshold,
+// so nearly expired requests do not waste their last milliseconds on a round trip that can't help them:
+// loads are treated as misses (ErrNotFound is returned), TTL returns a negative value (as for a not found key).
+// Saves (and deletions) always reach the decorated cache, bounded by the context, so a write is never
+// silently lost, nor stale data served. Contexts without a deadline are not affected.
+//
+// To serve only from the Memory layer under a tight budget, decorate the Redis layer of a Multi cache:
+//
+//	cache := xcache.NewMulti(memCache, xcache.NewDeadlineAware(redisCache, 5*time.Millisecond))
+type DeadlineAware struct {
+	cache     Cache
+	minBudget time.Duration
+	skipped   int64
+}
+
+// NewDeadlineAware instantiates a new DeadlineAware which decorates given cache,
+// skipping it when the remaining time until context's deadline is below given budget.
+func NewDeadlineAware(cache Cache, minBudget time.Duration) *DeadlineAware {
+	return &DeadlineAware{
+		cache:     cache,
+		minBudget: minBudget,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache,
+// regardless of the context's remaining budget.
+func (cache *DeadlineAware) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// If the context's remaining budget is too low, ErrNotFound is returned.
+func (cache *DeadlineAware) Load(ctx context.Context, key string) ([]byte, error) {
+	if cache.skip(ctx) {
+		return nil, ErrNotFound
+	}
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// If the context's remaining budget is too low, a negative TTL is returned.
+func (cache *DeadlineAware) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if cache.skip(ctx) {
+		return -1, nil
+	}
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics (regardless of context's deadline).
+func (cache *DeadlineAware) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *DeadlineAware) Unwrap() Cache {
+	return cache.cache
+}
+
+// SkippedCount returns the number of read operations (Load, TTL) for which the decorated cache was skipped.
+func (cache *DeadlineAware) SkippedCount() int64 {
+	return atomic.LoadInt64(&cache.skipped)
+}
+
+// ContributeStats reports the no. of skipped operations, as "deadline.skipped" metric.
+func (cache *DeadlineAware) ContributeStats(add func(name string, value float64)) {
+	add("deadline.skipped", float64(cache.SkippedCount()))
+}
+
+// skip checks if the decorated cache should be skipped, as the remaining time until
+// context's deadline is below the minimum budget.
+func (cache *DeadlineAware) skip(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) >= cache.minBudget {
+		return false
+	}
+	atomic.AddInt64(&cache.skipped, 1)
+
+	return true
+}
@@ -0,0 +1,91 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.DeadlineAware)(nil) // test DeadlineAware is a Cache
+}
+
+func TestDeadlineAware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enough budget - cache is used", testDeadlineAwareEnoughBudgetCacheIsUsed)
+	t.Run("low budget - reads are skipped", testDeadlineAwareLowBudgetReadsAreSkipped)
+}
+
+func testDeadlineAwareEnoughBudgetCacheIsUsed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache          = xcache.NewMemory(1)
+		subject        = xcache.NewDeadlineAware(cache, 50*time.Millisecond)
+		key            = "test-deadline-aware-key"
+		value          = []byte("test value")
+		ctx, cancelCtx = context.WithTimeout(context.Background(), time.Minute)
+	)
+	defer cancelCtx()
+
+	for _, ctx := range [...]context.Context{ctx, context.Background()} {
+		// act
+		errSave := subject.Save(ctx, key, value, time.Minute)
+		resultValue, errLoad := subject.Load(ctx, key)
+		resultTTL, errTTL := subject.TTL(ctx, key)
+		_, errStats := subject.Stats(ctx)
+
+		// assert
+		assertNil(t, errSave)
+		assertNil(t, errLoad)
+		assertEqual(t, value, resultValue)
+		assertNil(t, errTTL)
+		assertTrue(t, resultTTL > 0)
+		assertNil(t, errStats)
+	}
+	assertEqual(t, int64(0), subject.SkippedCount())
+}
+
+func testDeadlineAwareLowBudgetReadsAreSkipped(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache          = new(xcache.Mock)
+		subject        = xcache.NewDeadlineAware(cache, 50*time.Millisecond)
+		key            = "test-deadline-aware-key"
+		ctx, cancelCtx = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	)
+	defer cancelCtx()
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	resultValue, errLoad := subject.Load(ctx, key)
+	resultTTL, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+	errDelete := subject.Save(ctx, key, nil, -1)
+
+	// assert
+	assertNil(t, errSave)
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertNil(t, resultValue)
+	assertNil(t, errTTL)
+	assertTrue(t, resultTTL < 0)
+	assertNil(t, errStats)
+	assertNil(t, errDelete)
+	assertEqual(t, 2, cache.SaveCallsCount()) // the save and the deletion
+	assertEqual(t, 0, cache.LoadCallsCount())
+	assertEqual(t, 0, cache.TTLCallsCount())
+	assertEqual(t, 1, cache.StatsCallsCount())
+	assertEqual(t, int64(2), subject.SkippedCount())
+}
@@ -0,0 +1,374 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Prefixes of the keys a Disk cache stores into its KVStore.
+const (
+	diskDataPrefix  = 'd' // "d" + key => expire at (8 bytes, unix nanoseconds, 0 for no expiration) + value.
+	diskIndexPrefix = 'x' // "x" + expire at (8 bytes, big endian) + key => empty (TTL index).
+)
+
+// diskHeaderLen is the length of a stored value's header (its expiration moment).
+const diskHeaderLen = 8
+
+// KVWrite is a write (set / delete) of a KVStore batch.
+type KVWrite struct {
+	Key    []byte
+	Value  []byte
+	Delete bool
+}
+
+// KVStore is an ordered, persistent, key-value store (an LSM tree like Pebble / Badger, or a B-tree like bbolt),
+// a Disk cache is built upon.
+// A Pebble based implementation is provided by xcachepebble subpackage.
+type KVStore interface {
+	// Get returns the value of given key, or ErrNotFound, if the key does not exist.
+	// Returned value must not be modified by the store afterwards.
+	Get(key []byte) ([]byte, error)
+	// Write applies given writes atomically.
+	Write(writes []KVWrite) error
+	// Iterate calls given function for the keys within [lower, upper), in ascending order,
+	// until it returns false. Given key and value are valid only during the call.
+	Iterate(lower, upper []byte, fn func(key, value []byte) bool) error
+}
+
+// DiskConfig holds the settings of a Disk cache.
+type DiskConfig struct {
+	// SweepInterval is the interval at which expired keys are removed from the store.
+	// By default (0), it's 1 minute. A negative value disables the background sweeping
+	// (expired keys are not returned anyway, and can be removed with Sweep).
+	SweepInterval time.Duration
+	// SweepBatch is the max. no. of expired keys removed in a store write. By default (0), it's 1000.
+	SweepBatch int
+}
+
+// Disk is a Cache backed by a local, persistent, KVStore (like Pebble on NVMe), targeting working sets too large
+// for RAM (tens of GB), which would be too costly to keep in Redis.
+// Expiration is implemented through a TTL index (ordered by expiration moment) stored along with the values,
+// which is swept periodically, in background, removing the expired keys. Expired keys not swept yet are not returned.
+// It implements io.Closer and should be closed at your application shutdown (the store is not closed).
+//
+// Example:
+//
+//	db, err := pebble.Open("/mnt/nvme/cache", &pebble.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	cache, err := xcache.NewDisk(xcachepebble.NewStore(db, xcachepebble.StoreConfig{}), xcache.DiskConfig{})
+//	if err != nil {
+//		return err
+//	}
+//	defer cache.Close()
+type Disk struct {
+	store   KVStore
+	config  DiskConfig
+	locks   *KeyMutex
+	keys    int64
+	hits    int64
+	misses  int64
+	expired int64
+	closed  chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// NewDisk instantiates a new Disk cache upon given store, according to given settings.
+// The keys already stored are counted (iterating the store), and the background sweeping is started.
+// It returns an error if the store cannot be iterated.
+func NewDisk(store KVStore, config DiskConfig) (*Disk, error) {
+	if config.SweepInterval == 0 {
+		config.SweepInterval = time.Minute
+	}
+	if config.SweepBatch <= 0 {
+		config.SweepBatch = 1000
+	}
+	cache := &Disk{
+		store:  store,
+		config: config,
+		locks:  NewKeyMutex(256),
+		closed: make(chan struct{}),
+	}
+
+	err := store.Iterate([]byte{diskDataPrefix}, []byte{diskDataPrefix + 1}, func([]byte, []byte) bool {
+		cache.keys++
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SweepInterval > 0 {
+		cache.wg.Add(1)
+		go cache.sweepAsync()
+	}
+
+	return cache, nil
+}
+
+// Save stores the given key-value with expiration period into cache.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved, or the context's error, if it is done.
+func (cache *Disk) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dataKey := diskDataKey(key)
+	cache.locks.Lock(key)
+	defer cache.locks.Unlock(key)
+	existed, err := cache.exists(dataKey)
+	if err != nil {
+		return err
+	}
+
+	if expire < 0 { // the TTL index entry, if any, is removed by the sweeping.
+		if !existed {
+			return nil
+		}
+		if err := cache.store.Write([]KVWrite{{Key: dataKey, Delete: true}}); err != nil {
+			return err
+		}
+		atomic.AddInt64(&cache.keys, -1)
+
+		return nil
+	}
+
+	var expireAt int64
+	if expire > 0 {
+		expireAt = time.Now().Add(expire).UnixNano()
+	}
+	data := make([]byte, diskHeaderLen+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expireAt))
+	copy(data[diskHeaderLen:], value)
+	writes := []KVWrite{{Key: dataKey, Value: data}}
+	if expireAt > 0 { // a previous TTL index entry of the key is removed by the sweeping.
+		writes = append(writes, KVWrite{Key: diskIndexKey(expireAt, key), Value: []byte{}})
+	}
+	if err := cache.store.Write(writes); err != nil {
+		return err
+	}
+	if !existed {
+		atomic.AddInt64(&cache.keys, 1)
+	}
+
+	return nil
+}
+
+// Load returns a key's value from cache, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+// If the context is done, its error is returned.
+func (cache *Disk) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := cache.load(diskDataKey(key))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			atomic.AddInt64(&cache.misses, 1)
+		}
+
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return data[diskHeaderLen:], nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the store fails, or the context is done.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *Disk) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	data, err := cache.load(diskDataKey(key))
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	expireAt := int64(binary.BigEndian.Uint64(data))
+	if expireAt == 0 {
+		return NoExpire, nil
+	}
+
+	return time.Duration(expireAt - time.Now().UnixNano()), nil
+}
+
+// Stats returns statistics about disk cache: the no. of keys (including the expired ones not swept yet),
+// hits, misses and swept expired keys, since the cache was instantiated.
+// Memory is not reported. Returned error is nil, unless the context is done.
+func (cache *Disk) Stats(ctx context.Context) (Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Keys:    atomic.LoadInt64(&cache.keys),
+		Hits:    atomic.LoadInt64(&cache.hits),
+		Misses:  atomic.LoadInt64(&cache.misses),
+		Expired: atomic.LoadInt64(&cache.expired),
+	}, nil
+}
+
+// Sweep removes the keys expired until now, in batches (see DiskConfig.SweepBatch), and their TTL index entries.
+// It returns the no. of removed keys, or an error, if the store fails, or the context is done.
+func (cache *Disk) Sweep(ctx context.Context) (int, error) {
+	var (
+		upper = diskIndexKey(time.Now().UnixNano()+1, "")
+		swept int
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return swept, err
+		}
+
+		var entries [][]byte
+		err := cache.store.Iterate([]byte{diskIndexPrefix}, upper, func(key, _ []byte) bool {
+			entries = append(entries, append([]byte(nil), key...))
+
+			return len(entries) < cache.config.SweepBatch
+		})
+		if err != nil || len(entries) == 0 {
+			return swept, err
+		}
+
+		n, err := cache.sweepEntries(entries)
+		swept += n
+		if err != nil || len(entries) < cache.config.SweepBatch {
+			return swept, err
+		}
+	}
+}
+
+// Close stops the background sweeping.
+// It implements io.Closer interface, and the returned error can be disregarded (is nil all the time).
+func (cache *Disk) Close() error {
+	cache.once.Do(func() {
+		close(cache.closed)
+		cache.wg.Wait()
+	})
+
+	return nil
+}
+
+// sweepEntries removes the keys of given TTL index entries, if they did not get a new expiration meanwhile,
+// and the entries themselves. It returns the no. of removed keys.
+func (cache *Disk) sweepEntries(entries [][]byte) (int, error) {
+	var (
+		writes = make([]KVWrite, 0, 2*len(entries))
+		keys   = make([]string, 0, len(entries))
+	)
+	for _, entry := range entries {
+		keys = append(keys, string(entry[1+diskHeaderLen:]))
+	}
+	cache.locks.LockMany(keys...)
+	defer cache.locks.UnlockMany(keys...)
+
+	swept := 0
+	for idx, entry := range entries {
+		writes = append(writes, KVWrite{Key: entry, Delete: true})
+		dataKey := diskDataKey(keys[idx])
+		data, err := cache.store.Get(dataKey)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		if len(data) >= diskHeaderLen && string(data[:diskHeaderLen]) == string(entry[1:1+diskHeaderLen]) {
+			writes = append(writes, KVWrite{Key: dataKey, Delete: true})
+			swept++
+		}
+	}
+	if err := cache.store.Write(writes); err != nil {
+		return 0, err
+	}
+	atomic.AddInt64(&cache.keys, -int64(swept))
+	atomic.AddInt64(&cache.expired, int64(swept))
+
+	return swept, nil
+}
+
+// sweepAsync sweeps the expired keys, interval based, until the cache is closed.
+func (cache *Disk) sweepAsync() {
+	defer cache.wg.Done()
+
+	ticker := time.NewTicker(cache.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cache.closed:
+			return
+		case <-ticker.C:
+			_, _ = cache.Sweep(context.Background())
+		}
+	}
+}
+
+// load returns the stored data (header + value) of given data key,
+// or ErrNotFound, if the key does not exist, or it's expired.
+func (cache *Disk) load(dataKey []byte) ([]byte, error) {
+	data, err := cache.store.Get(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < diskHeaderLen {
+		return nil, ErrNotFound
+	}
+	expireAt := int64(binary.BigEndian.Uint64(data))
+	if expireAt > 0 && expireAt <= time.Now().UnixNano() {
+		return nil, ErrNotFound
+	}
+
+	return data, nil
+}
+
+// exists checks whether given data key is stored (expired, or not).
+func (cache *Disk) exists(dataKey []byte) (bool, error) {
+	_, err := cache.store.Get(dataKey)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// diskDataKey returns the store key holding given key's value.
+func diskDataKey(key string) []byte {
+	dataKey := make([]byte, 1+len(key))
+	dataKey[0] = diskDataPrefix
+	copy(dataKey[1:], key)
+
+	return dataKey
+}
+
+// diskIndexKey returns the store key of a TTL index entry of given key, expiring at given moment.
+func diskIndexKey(expireAt int64, key string) []byte {
+	indexKey := make([]byte, 1+diskHeaderLen+len(key))
+	indexKey[0] = diskIndexPrefix
+	binary.BigEndian.PutUint64(indexKey[1:], uint64(expireAt))
+	copy(indexKey[1+diskHeaderLen:], key)
+
+	return indexKey
+}
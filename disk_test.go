@@ -0,0 +1,225 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Disk)(nil)   // test Disk is a Cache
+	var _ io.Closer = (*xcache.Disk)(nil)      // test Disk is a Closer
+	var _ xcache.KVStore = (*sortedStore)(nil) // test sortedStore is a KVStore
+}
+
+func TestDisk(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testDiskSaveLoadTTLDelete)
+	t.Run("expired keys are not returned", testDiskExpiredKeysAreNotReturned)
+	t.Run("expired keys are swept", testDiskExpiredKeysAreSwept)
+	t.Run("stored keys are counted", testDiskStoredKeysAreCounted)
+}
+
+func testDiskSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xcache.NewDisk(newSortedStore(), xcache.DiskConfig{SweepInterval: -1})
+	requireNil(t, err)
+	defer subject.Close()
+	var (
+		ctx   = context.Background()
+		key   = "test-disk-key"
+		value = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+}
+
+func testDiskExpiredKeysAreNotReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xcache.NewDisk(newSortedStore(), xcache.DiskConfig{SweepInterval: -1})
+	requireNil(t, err)
+	defer subject.Close()
+	var (
+		ctx = context.Background()
+		key = "test-disk-expired-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), 10*time.Millisecond))
+
+	// act
+	time.Sleep(15 * time.Millisecond)
+	_, err = subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+}
+
+func testDiskExpiredKeysAreSwept(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	store := newSortedStore()
+	subject, err := xcache.NewDisk(store, xcache.DiskConfig{SweepInterval: -1, SweepBatch: 2})
+	requireNil(t, err)
+	defer subject.Close()
+	var (
+		ctx   = context.Background()
+		value = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-1", value, 10*time.Millisecond))
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-2", value, 10*time.Millisecond))
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-3", value, 10*time.Millisecond))
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-4", value, 10*time.Millisecond))
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-4", value, time.Minute)) // re-saved, not expired.
+	requireNil(t, subject.Save(ctx, "test-disk-sweep-key-5", value, xcache.NoExpire))
+	time.Sleep(15 * time.Millisecond)
+
+	// act
+	swept, err := subject.Sweep(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, swept)
+	assertEqual(t, 3, store.Len()) // key 4 with its index entry, and key 5.
+	_, err = subject.Load(ctx, "test-disk-sweep-key-4")
+	assertNil(t, err)
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(2), stats.Keys)
+	assertEqual(t, int64(3), stats.Expired)
+}
+
+func testDiskStoredKeysAreCounted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		store = newSortedStore()
+		ctx   = context.Background()
+	)
+	cache, err := xcache.NewDisk(store, xcache.DiskConfig{SweepInterval: time.Millisecond})
+	requireNil(t, err)
+	requireNil(t, cache.Save(ctx, "test-disk-count-key-1", []byte("test value"), time.Minute))
+	requireNil(t, cache.Save(ctx, "test-disk-count-key-2", []byte("test value"), time.Minute))
+	requireNil(t, cache.Save(ctx, "test-disk-count-key-2", []byte("test value"), time.Minute))
+	assertNil(t, cache.Close())
+
+	// act
+	subject, err := xcache.NewDisk(store, xcache.DiskConfig{})
+	requireNil(t, err)
+	defer subject.Close()
+
+	// assert
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(2), stats.Keys)
+}
+
+// sortedStore is an in memory xcache.KVStore.
+type sortedStore struct {
+	data map[string][]byte
+	mu   sync.Mutex
+}
+
+func newSortedStore() *sortedStore {
+	return &sortedStore{data: make(map[string][]byte)}
+}
+
+func (s *sortedStore) Get(key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, found := s.data[string(key)]
+	if !found {
+		return nil, xcache.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (s *sortedStore) Write(writes []xcache.KVWrite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range writes {
+		if w.Delete {
+			delete(s.data, string(w.Key))
+		} else {
+			s.data[string(w.Key)] = append([]byte(nil), w.Value...)
+		}
+	}
+
+	return nil
+}
+
+func (s *sortedStore) Iterate(lower, upper []byte, fn func(key, value []byte) bool) error {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		if key >= string(lower) && key < string(upper) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for idx, key := range keys {
+		values[idx] = s.data[key]
+	}
+	s.mu.Unlock()
+
+	for idx, key := range keys {
+		if !fn([]byte(key), values[idx]) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *sortedStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.data)
+}
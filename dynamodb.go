@@ -0,0 +1,245 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrConditionFailed is the error a DynamoDBClient (or a GCSClient / AzureBlobClient) returns
+// when a conditional write's condition is not met.
+var ErrConditionFailed = errors.New("condition failed")
+
+// DynamoDBItem is a key-value, as stored into a DynamoDB table.
+type DynamoDBItem struct {
+	// Key is the partition key.
+	Key string
+	// Value is the (binary) value.
+	Value []byte
+	// ExpiresAt is the expiration moment, in unix seconds, stored in the table's TTL attribute.
+	// 0 means no expiration (the attribute is not set).
+	ExpiresAt int64
+}
+
+// DynamoDBPut is a (conditional) write of an item.
+type DynamoDBPut struct {
+	// Table is the table the item is written into.
+	Table string
+	// Item is the written item.
+	Item DynamoDBItem
+	// IfAbsentAt, if not 0, conditions the write on the item not existing, or being expired
+	// at given moment (unix seconds), like "attribute_not_exists(pk) OR expires_at <= :now".
+	IfAbsentAt int64
+}
+
+// DynamoDBClient is the subset of DynamoDB operations a DynamoDB cache is built upon.
+// Each operation returns the capacity units it consumed.
+//
+// Subpackage xcachedynamo provides an aws-sdk-go-v2 based implementation, storing items as
+// {pk: S, v: B, expires_at: N}, with TTL enabled on expires_at attribute.
+type DynamoDBClient interface {
+	// GetItem returns the item with given key from given table, or ErrNotFound, if it does not exist.
+	GetItem(ctx context.Context, table, key string) (DynamoDBItem, float64, error)
+	// PutItem writes (creates, or replaces) an item.
+	// If the write's condition is not met, ErrConditionFailed is returned.
+	PutItem(ctx context.Context, put DynamoDBPut) (float64, error)
+	// DeleteItem deletes the item with given key from given table.
+	DeleteItem(ctx context.Context, table, key string) (float64, error)
+	// ItemCount returns the (approximate) no. of items of given table.
+	ItemCount(ctx context.Context, table string) (int64, error)
+}
+
+// DynamoDBConfig holds the settings of a DynamoDB cache.
+type DynamoDBConfig struct {
+	// Table is the table keys are stored into. By default (""), it's "xcache".
+	Table string
+}
+
+// DynamoDB is a Cache backed by a DynamoDB table, for serverless services which have no Redis.
+// Expiration relies on the table's native TTL attribute; as DynamoDB deletes expired items lazily
+// (within days), expired items not deleted yet are not returned.
+// Note: DynamoDB TTL has a second granularity, an expiration period is rounded up to a whole second.
+//
+// Example:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	client := xcachedynamo.NewClient(dynamodb.NewFromConfig(cfg))
+//	cache := xcache.NewDynamoDB(client, xcache.DynamoDBConfig{Table: "my-cache"})
+type DynamoDB struct {
+	client DynamoDBClient
+	config DynamoDBConfig
+	hits   int64
+	misses int64
+	rcu    float64 // consumed read capacity units.
+	wcu    float64 // consumed write capacity units.
+	mu     sync.Mutex
+}
+
+// NewDynamoDB instantiates a new DynamoDB cache upon given client, according to given settings.
+func NewDynamoDB(client DynamoDBClient, config DynamoDBConfig) *DynamoDB {
+	if config.Table == "" {
+		config.Table = "xcache"
+	}
+
+	return &DynamoDB{
+		client: client,
+		config: config,
+	}
+}
+
+// Save stores (creates, or replaces) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *DynamoDB) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	consumed, err := cache.client.PutItem(ctx, cache.put(key, value, expire))
+	cache.consumeWrite(consumed)
+
+	return err
+}
+
+// SaveIfAbsent stores the given key-value with expiration period, only if the key does not exist
+// (or it's expired), through a conditional write.
+// It returns true if the key was saved, or an error if something bad happened.
+func (cache *DynamoDB) SaveIfAbsent(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) (bool, error) {
+	put := cache.put(key, value, expire)
+	put.IfAbsentAt = time.Now().Unix()
+	consumed, err := cache.client.PutItem(ctx, put)
+	cache.consumeWrite(consumed)
+	if errors.Is(err, ErrConditionFailed) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+func (cache *DynamoDB) Load(ctx context.Context, key string) ([]byte, error) {
+	item, err := cache.get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return item.Value, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the read fails.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *DynamoDB) TTL(ctx context.Context, key string) (time.Duration, error) {
+	item, err := cache.get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if item.ExpiresAt == 0 {
+		return NoExpire, nil
+	}
+
+	return time.Until(time.Unix(item.ExpiresAt, 0)), nil
+}
+
+// Stats returns statistics about DynamoDB cache: the approximate no. of items (as DynamoDB reports it,
+// refreshed every few hours, including the expired ones not deleted yet), the hits and misses
+// since the cache was instantiated. Memory is not reported.
+// It returns an error if the items could not be counted.
+func (cache *DynamoDB) Stats(ctx context.Context) (Stats, error) {
+	keys, err := cache.client.ItemCount(ctx, cache.config.Table)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Keys:   keys,
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *DynamoDB) Delete(ctx context.Context, key string) error {
+	consumed, err := cache.client.DeleteItem(ctx, cache.config.Table, key)
+	cache.consumeWrite(consumed)
+
+	return err
+}
+
+// ContributeStats reports the read and write capacity units consumed since the cache was instantiated,
+// as "dynamodb.consumed_rcu" and "dynamodb.consumed_wcu" metrics.
+func (cache *DynamoDB) ContributeStats(add func(name string, value float64)) {
+	cache.mu.Lock()
+	rcu, wcu := cache.rcu, cache.wcu
+	cache.mu.Unlock()
+
+	add("dynamodb.consumed_rcu", rcu)
+	add("dynamodb.consumed_wcu", wcu)
+}
+
+// get returns the (not expired) item with given key, or ErrNotFound.
+func (cache *DynamoDB) get(ctx context.Context, key string) (DynamoDBItem, error) {
+	item, consumed, err := cache.client.GetItem(ctx, cache.config.Table, key)
+	cache.mu.Lock()
+	cache.rcu += consumed
+	cache.mu.Unlock()
+	if err != nil {
+		return DynamoDBItem{}, err
+	}
+	if item.ExpiresAt != 0 && item.ExpiresAt <= time.Now().Unix() {
+		return DynamoDBItem{}, ErrNotFound
+	}
+
+	return item, nil
+}
+
+// put returns the write of given key-value with expiration period.
+func (cache *DynamoDB) put(key string, value []byte, expire time.Duration) DynamoDBPut {
+	item := DynamoDBItem{Key: key, Value: value}
+	if expire > 0 { // round up to a whole second.
+		item.ExpiresAt = (time.Now().Add(expire).UnixNano() + int64(time.Second) - 1) / int64(time.Second)
+	}
+	if item.Value == nil {
+		item.Value = []byte{}
+	}
+
+	return DynamoDBPut{Table: cache.config.Table, Item: item}
+}
+
+// consumeWrite adds given write capacity units to the consumed ones.
+func (cache *DynamoDB) consumeWrite(consumed float64) {
+	cache.mu.Lock()
+	cache.wcu += consumed
+	cache.mu.Unlock()
+}
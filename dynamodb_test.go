@@ -0,0 +1,265 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.DynamoDB)(nil)            // test DynamoDB is a Cache
+	var _ xcache.Deleter = (*xcache.DynamoDB)(nil)          // test DynamoDB is a Deleter
+	var _ xcache.StatsContributor = (*xcache.DynamoDB)(nil) // test DynamoDB is a StatsContributor
+	var _ xcache.DynamoDBClient = (*dynamoTable)(nil)       // test dynamoTable is a DynamoDBClient
+}
+
+func TestDynamoDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testDynamoDBSaveLoadTTLDelete)
+	t.Run("expired items are not returned", testDynamoDBExpiredItemsAreNotReturned)
+	t.Run("save if absent", testDynamoDBSaveIfAbsent)
+	t.Run("client errors are returned", testDynamoDBClientErrorsAreReturned)
+}
+
+func testDynamoDBSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		table   = newDynamoTable()
+		subject = xcache.NewDynamoDB(table, xcache.DynamoDBConfig{Table: "test-cache"})
+		ctx     = context.Background()
+		key     = "test-dynamodb-key"
+		value   = []byte("test value")
+	)
+
+	// act & assert
+	savedAt := time.Now()
+	requireNil(t, subject.Save(ctx, key, value, 1500*time.Millisecond))
+	expiresAt := time.Unix(table.Item(key).ExpiresAt, 0) // rounded up to a whole second.
+	assertTrue(t, !expiresAt.Before(savedAt.Add(1500*time.Millisecond)))
+	assertTrue(t, expiresAt.Before(time.Now().Add(2500*time.Millisecond)))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > time.Second && ttl <= 2500*time.Millisecond)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"dynamodb.consumed_rcu": 2.5, "dynamodb.consumed_wcu": 3}, metrics)
+	assertEqual(t, []string{"test-cache"}, table.Tables())
+}
+
+func testDynamoDBExpiredItemsAreNotReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		table   = newDynamoTable()
+		subject = xcache.NewDynamoDB(table, xcache.DynamoDBConfig{})
+		ctx     = context.Background()
+		key     = "test-dynamodb-expired-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), time.Minute))
+	table.Expire(key) // expired, but not deleted by DynamoDB yet.
+
+	// act
+	_, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, []string{"xcache"}, table.Tables())
+}
+
+func testDynamoDBSaveIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		table   = newDynamoTable()
+		subject = xcache.NewDynamoDB(table, xcache.DynamoDBConfig{})
+		ctx     = context.Background()
+		key     = "test-dynamodb-absent-key"
+	)
+
+	// act & assert
+	saved, err := subject.SaveIfAbsent(ctx, key, []byte("value 1"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 2"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	assertEqual(t, []byte("value 1"), table.Item(key).Value)
+
+	table.Expire(key)
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 3"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+	assertEqual(t, []byte("value 3"), table.Item(key).Value)
+}
+
+func testDynamoDBClientErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		table   = newDynamoTable()
+		subject = xcache.NewDynamoDB(table, xcache.DynamoDBConfig{})
+		ctx     = context.Background()
+		key     = "test-dynamodb-error-key"
+		errDB   = errors.New("throttled")
+	)
+	table.err = errDB
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errSaveIfAbsent := subject.SaveIfAbsent(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+
+	// assert
+	assertTrue(t, errors.Is(errSave, errDB))
+	assertTrue(t, errors.Is(errSaveIfAbsent, errDB))
+	assertTrue(t, errors.Is(errLoad, errDB))
+	assertTrue(t, errors.Is(errTTL, errDB))
+	assertTrue(t, errors.Is(errStats, errDB))
+}
+
+// dynamoTable is an in memory xcache.DynamoDBClient, consuming 0.5 RCU per read and 1 WCU per write.
+type dynamoTable struct {
+	items  map[string]xcache.DynamoDBItem
+	tables map[string]struct{}
+	err    error
+	mu     sync.Mutex
+}
+
+func newDynamoTable() *dynamoTable {
+	return &dynamoTable{
+		items:  make(map[string]xcache.DynamoDBItem),
+		tables: make(map[string]struct{}),
+	}
+}
+
+func (d *dynamoTable) GetItem(_ context.Context, table, key string) (xcache.DynamoDBItem, float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return xcache.DynamoDBItem{}, 0, d.err
+	}
+	d.tables[table] = struct{}{}
+	item, found := d.items[key]
+	if !found {
+		return xcache.DynamoDBItem{}, 0.5, xcache.ErrNotFound
+	}
+
+	return item, 0.5, nil
+}
+
+func (d *dynamoTable) PutItem(_ context.Context, put xcache.DynamoDBPut) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.tables[put.Table] = struct{}{}
+	if put.IfAbsentAt != 0 {
+		item, found := d.items[put.Item.Key]
+		if found && (item.ExpiresAt == 0 || item.ExpiresAt > put.IfAbsentAt) {
+			return 1, xcache.ErrConditionFailed
+		}
+	}
+	d.items[put.Item.Key] = put.Item
+
+	return 1, nil
+}
+
+func (d *dynamoTable) DeleteItem(_ context.Context, table, key string) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.tables[table] = struct{}{}
+	delete(d.items, key)
+
+	return 1, nil
+}
+
+func (d *dynamoTable) ItemCount(_ context.Context, table string) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		return 0, d.err
+	}
+	d.tables[table] = struct{}{}
+
+	return int64(len(d.items)), nil
+}
+
+func (d *dynamoTable) Item(key string) xcache.DynamoDBItem {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.items[key]
+}
+
+// Expire makes the item with given key expired.
+func (d *dynamoTable) Expire(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	item := d.items[key]
+	item.ExpiresAt = time.Now().Unix() - 1
+	d.items[key] = item
+}
+
+func (d *dynamoTable) Tables() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tables := make([]string, 0, len(d.tables))
+	for table := range d.tables {
+		tables = append(tables, table)
+	}
+
+	return tables
+}
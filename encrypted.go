@@ -0,0 +1,171 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidEncryptionKeys is returned by NewEncrypted if the given encryption keys are not valid.
+	ErrInvalidEncryptionKeys = errors.New("invalid encryption keys")
+	// ErrDecryption is returned by an Encrypted cache when loading a value which cannot be decrypted
+	// (it was altered, or it was saved under another key).
+	ErrDecryption = errors.New("value cannot be decrypted")
+)
+
+// EncryptionKey is a key an Encrypted cache encrypts / decrypts values with.
+type EncryptionKey struct {
+	// ID identifies the key. It is stored alongside each value encrypted with the key,
+	// so it must be unique, and must not be reused for another key.
+	ID string
+	// Key is the AES key, 16, 24 or 32 bytes long (for AES-128, AES-192, AES-256).
+	Key []byte
+}
+
+// Encrypted is a Cache decorator which encrypts values on Save, and decrypts them on Load,
+// with AES-GCM, so that sensitive data (like personal information) is not stored in plain text
+// in decorated (usually remote, shared) cache.
+// Values are bound to their keys: a value copied under another key cannot be decrypted.
+//
+// An Encrypted cache is configured with one or more keys: values are encrypted with the first (newest) key,
+// and decrypted with any of them (the key's ID is stored alongside the value, in an Envelope),
+// so keys can be rotated without flushing the cache:
+//  1. deploy with the new key in front of the current one;
+//  2. after the longest expiration period of the keys passes, deploy without the previous key.
+//
+// Values encrypted with a key which is no longer configured, and values saved unencrypted
+// (directly into decorated cache, before enabling encryption, for example) are treated as not found,
+// so they are recomputed by callers, and saved again, encrypted with the newest key.
+//
+// Example:
+//
+//	cache, err := xcache.NewEncrypted(redisCache,
+//		xcache.EncryptionKey{ID: "2024-10", Key: newKey},
+//		xcache.EncryptionKey{ID: "2024-04", Key: previousKey},
+//	)
+type Encrypted struct {
+	cache Cache
+	ids   []string // keys IDs, newest first.
+	aeads map[string]cipher.AEAD
+}
+
+// NewEncrypted instantiates a new Encrypted which decorates given cache,
+// encrypting values with the first of given keys, and decrypting them with any of given keys.
+// It returns ErrInvalidEncryptionKeys if no key is given, a key's ID is empty, longer than 255 bytes,
+// or duplicated, or a key's length is not valid.
+func NewEncrypted(cache Cache, keys ...EncryptionKey) (*Encrypted, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: at least a key is required", ErrInvalidEncryptionKeys)
+	}
+
+	encrypted := &Encrypted{
+		cache: cache,
+		ids:   make([]string, 0, len(keys)),
+		aeads: make(map[string]cipher.AEAD, len(keys)),
+	}
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("%w: key ID %q must have 1 up to 255 bytes", ErrInvalidEncryptionKeys, key.ID)
+		}
+		if _, found := encrypted.aeads[key.ID]; found {
+			return nil, fmt.Errorf("%w: duplicated key ID %q", ErrInvalidEncryptionKeys, key.ID)
+		}
+		block, err := aes.NewCipher(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key ID %q: %w", ErrInvalidEncryptionKeys, key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key ID %q: %w", ErrInvalidEncryptionKeys, key.ID, err)
+		}
+		encrypted.ids = append(encrypted.ids, key.ID)
+		encrypted.aeads[key.ID] = aead
+	}
+
+	return encrypted, nil
+}
+
+// Save stores the given key-value, encrypted with the newest key, into decorated cache.
+// A negative expiration period triggers deletion of key.
+// It returns an error if a random nonce cannot be generated.
+func (cache *Encrypted) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.cache.Save(ctx, key, value, expire)
+	}
+
+	var (
+		id   = cache.ids[0]
+		aead = cache.aeads[id]
+		env  = Envelope{Metadata: []byte(id)}
+	)
+	enveloped := env.Append(make([]byte, 0, env.Size(aead.NonceSize()+len(value)+aead.Overhead())), nil)
+	nonceStart := len(enveloped)
+	enveloped = enveloped[:nonceStart+aead.NonceSize()]
+	nonce := enveloped[nonceStart:]
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	enveloped = aead.Seal(enveloped, nonce, value, []byte(key))
+
+	return cache.cache.Save(ctx, key, enveloped, expire)
+}
+
+// Load returns a key's value from decorated cache, decrypted.
+// Values not encrypted, or encrypted with a key which is not configured, are treated as not found (ErrNotFound).
+// It returns ErrDecryption if the value cannot be decrypted.
+func (cache *Encrypted) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	aead, found := cache.aeads[string(env.Metadata)]
+	if !found {
+		return nil, ErrNotFound
+	}
+	if len(payload) < aead.NonceSize() {
+		return nil, ErrDecryption
+	}
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	return plaintext, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *Encrypted) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *Encrypted) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Encrypted) Unwrap() Cache {
+	return cache.cache
+}
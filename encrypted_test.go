@@ -0,0 +1,228 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Encrypted)(nil) // test Encrypted is a Cache
+}
+
+func TestEncrypted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value is encrypted and decrypted", testEncryptedValueIsEncryptedAndDecrypted)
+	t.Run("keys are rotated", testEncryptedKeysAreRotated)
+	t.Run("value moved under another key cannot be decrypted", testEncryptedValueMovedUnderAnotherKeyCannotBeDecrypted)
+	t.Run("value without envelope is not found", testEncryptedValueWithoutEnvelopeIsNotFound)
+	t.Run("deletion is passed through", testEncryptedDeletionIsPassedThrough)
+	t.Run("invalid keys", testEncryptedInvalidKeys)
+}
+
+func testEncryptedValueIsEncryptedAndDecrypted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewEncrypted(cache, xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)})
+		ctx        = context.Background()
+		key        = "test-encrypted-key"
+		value      = []byte("test sensitive value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	stored, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	assertTrue(t, !bytes.Contains(stored, value))
+	var env xcache.Envelope
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, []byte("k1"), env.Metadata)
+
+	// act & assert same value is encrypted differently each time
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	storedAgain, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	assertTrue(t, !bytes.Equal(stored, storedAgain))
+}
+
+func testEncryptedKeysAreRotated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = xcache.NewMemory(freecacheMinMem)
+		oldKey      = xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 16)}
+		newKey      = xcache.EncryptionKey{ID: "k2", Key: bytes.Repeat([]byte{2}, 32)}
+		old, _      = xcache.NewEncrypted(cache, oldKey)
+		rotating, _ = xcache.NewEncrypted(cache, newKey, oldKey)
+		rotated, _  = xcache.NewEncrypted(cache, newKey)
+		ctx         = context.Background()
+		oldValueKey = "test-encrypted-old-key"
+		newValueKey = "test-encrypted-new-key"
+		value       = []byte("test sensitive value")
+	)
+	requireNil(t, old.Save(ctx, oldValueKey, value, time.Minute))
+
+	// act
+	errSave := rotating.Save(ctx, newValueKey, value, time.Minute)
+	resultOld, errLoadOld := rotating.Load(ctx, oldValueKey)
+	resultNew, errLoadNew := rotating.Load(ctx, newValueKey)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoadOld)
+	assertEqual(t, value, resultOld)
+	assertNil(t, errLoadNew)
+	assertEqual(t, value, resultNew)
+	stored, err := cache.Load(ctx, newValueKey)
+	requireNil(t, err)
+	var env xcache.Envelope
+	_, err = env.Unmarshal(stored)
+	assertNil(t, err)
+	assertEqual(t, []byte("k2"), env.Metadata)
+
+	// act & assert value encrypted with a retired key is not found
+	_, err = rotated.Load(ctx, oldValueKey)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	resultNew, err = rotated.Load(ctx, newValueKey)
+	assertNil(t, err)
+	assertEqual(t, value, resultNew)
+}
+
+func testEncryptedValueMovedUnderAnotherKeyCannotBeDecrypted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewEncrypted(cache, xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)})
+		ctx        = context.Background()
+		key        = "test-encrypted-key"
+		otherKey   = "test-encrypted-other-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test sensitive value"), time.Minute))
+	stored, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	requireNil(t, cache.Save(ctx, otherKey, stored, time.Minute))
+
+	// act
+	result, err := subject.Load(ctx, otherKey)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrDecryption))
+	assertNil(t, result)
+
+	// act & assert altered value
+	stored[len(stored)-1] ^= 0xFF
+	requireNil(t, cache.Save(ctx, key, stored, time.Minute))
+	result, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrDecryption))
+	assertNil(t, result)
+}
+
+func testEncryptedValueWithoutEnvelopeIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewEncrypted(cache, xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)})
+		ctx        = context.Background()
+		key        = "test-encrypted-plain-key"
+	)
+	requireNil(t, cache.Save(ctx, key, []byte("test plain value"), time.Minute))
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, result)
+}
+
+func testEncryptedDeletionIsPassedThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = new(xcache.Mock)
+		subject, _ = xcache.NewEncrypted(cache, xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)})
+		ctx        = context.Background()
+		key        = "test-encrypted-delete-key"
+	)
+	cache.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, key, k)
+		assertNil(t, v)
+		assertEqual(t, time.Duration(-1), exp)
+
+		return nil
+	})
+
+	// act
+	err := subject.Save(ctx, key, nil, -1)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, cache.SaveCallsCount())
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testEncryptedInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	validKey := bytes.Repeat([]byte{1}, 32)
+	tests := [...]struct {
+		name string
+		keys []xcache.EncryptionKey
+	}{
+		{
+			name: "no key",
+		},
+		{
+			name: "empty ID",
+			keys: []xcache.EncryptionKey{{Key: validKey}},
+		},
+		{
+			name: "duplicated ID",
+			keys: []xcache.EncryptionKey{{ID: "k1", Key: validKey}, {ID: "k1", Key: validKey}},
+		},
+		{
+			name: "invalid key length",
+			keys: []xcache.EncryptionKey{{ID: "k1", Key: validKey[:10]}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			subject, err := xcache.NewEncrypted(xcache.Nop{}, test.keys...)
+
+			// assert
+			assertTrue(t, errors.Is(err, xcache.ErrInvalidEncryptionKeys))
+			assertTrue(t, subject == nil)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"os"
+)
+
+// Environment variables names (without prefix) from which Redis configuration is read.
+// They follow the naming of RedisCfgKey* xconf keys.
+const (
+	// RedisEnvAddrs is the env var holding Redis server(s), comma separated.
+	RedisEnvAddrs = "ADDRS"
+	// RedisEnvDB is the env var holding Redis DB.
+	RedisEnvDB = "DB"
+	// RedisEnvAuthUsername is the env var holding auth username.
+	RedisEnvAuthUsername = "AUTH_USERNAME"
+	// RedisEnvAuthPassword is the env var holding auth password.
+	RedisEnvAuthPassword = "AUTH_PASSWORD"
+	// RedisEnvDialTimeout is the env var holding dial timeout, as a duration string (like "5s").
+	RedisEnvDialTimeout = "TIMEOUT_DIAL"
+	// RedisEnvReadTimeout is the env var holding read timeout, as a duration string (like "3s").
+	RedisEnvReadTimeout = "TIMEOUT_READ"
+	// RedisEnvWriteTimeout is the env var holding write timeout, as a duration string (like "5s").
+	RedisEnvWriteTimeout = "TIMEOUT_WRITE"
+	// RedisEnvTLS is the env var holding the flag to connect over TLS.
+	RedisEnvTLS = "TLS"
+	// RedisEnvDisableUnlink is the env var holding the flag to use DEL instead of UNLINK.
+	RedisEnvDisableUnlink = "DISABLEUNLINK"
+	// RedisEnvStatsCacheTTL is the env var holding the period for which stats are cached,
+	// as a duration string (like "500ms").
+	RedisEnvStatsCacheTTL = "STATS_CACHETTL"
+	// RedisEnvForbidNoExpire is the env var holding the flag to forbid saving keys without expiration.
+	RedisEnvForbidNoExpire = "NOEXPIRE_FORBID"
+	// RedisEnvNoExpireTTL is the env var holding the expiration period keys saved without expiration
+	// are stored with instead, as a duration string (like "24h").
+	RedisEnvNoExpireTTL = "NOEXPIRE_TTL"
+	// RedisEnvKeyPrefix is the env var holding the prefix prepended to each key.
+	RedisEnvKeyPrefix = "KEYPREFIX"
+	// RedisEnvCountPrefixKeys is the env var holding the flag to count only the keys having the key prefix, in stats.
+	RedisEnvCountPrefixKeys = "KEYPREFIX_COUNT"
+	// RedisEnvClusterReadonly is the env var holding readonly flag.
+	RedisEnvClusterReadonly = "CLUSTER_READONLY"
+	// RedisEnvClusterCountKeys is the env var holding the flag to count the keys of a cluster, in stats.
+	RedisEnvClusterCountKeys = "CLUSTER_COUNTKEYS"
+	// RedisEnvFailoverMasterName is the env var holding master name.
+	RedisEnvFailoverMasterName = "FAILOVER_MASTERNAME"
+	// RedisEnvFailoverAuthUsername is the env var holding sentinel auth username.
+	RedisEnvFailoverAuthUsername = "FAILOVER_AUTH_USERNAME"
+	// RedisEnvFailoverAuthPassword is the env var holding sentinel auth password.
+	RedisEnvFailoverAuthPassword = "FAILOVER_AUTH_PASSWORD"
+	// RedisEnvDurationUnit is the env var holding the unit of durations given as numbers (like "ms").
+	// By default, numbers are considered milliseconds.
+	RedisEnvDurationUnit = "DURATION_UNIT"
+)
+
+// MemoryEnvMemorySize is the env var name (without prefix) holding memory size in bytes.
+// It follows the naming of MemoryCfgKeyMemorySize xconf key.
+const MemoryEnvMemorySize = "MEMSIZEBYTES"
+
+// redisEnvNames maps RedisCfgKey* xconf keys to RedisEnv* env vars names.
+var redisEnvNames = map[string]string{
+	RedisCfgKeyAddrs:                RedisEnvAddrs,
+	RedisCfgKeyDB:                   RedisEnvDB,
+	RedisCfgKeyAuthUsername:         RedisEnvAuthUsername,
+	RedisCfgKeyAuthPassword:         RedisEnvAuthPassword,
+	RedisCfgKeyDialTimeout:          RedisEnvDialTimeout,
+	RedisCfgKeyReadTimeout:          RedisEnvReadTimeout,
+	RedisCfgKeyWriteTimeout:         RedisEnvWriteTimeout,
+	RedisCfgKeyTLS:                  RedisEnvTLS,
+	RedisCfgKeyDisableUnlink:        RedisEnvDisableUnlink,
+	RedisCfgKeyStatsCacheTTL:        RedisEnvStatsCacheTTL,
+	RedisCfgKeyForbidNoExpire:       RedisEnvForbidNoExpire,
+	RedisCfgKeyNoExpireTTL:          RedisEnvNoExpireTTL,
+	RedisCfgKeyKeyPrefix:            RedisEnvKeyPrefix,
+	RedisCfgKeyCountPrefixKeys:      RedisEnvCountPrefixKeys,
+	RedisCfgKeyClusterReadonly:      RedisEnvClusterReadonly,
+	RedisCfgKeyClusterCountKeys:     RedisEnvClusterCountKeys,
+	RedisCfgKeyFailoverMasterName:   RedisEnvFailoverMasterName,
+	RedisCfgKeyFailoverAuthUsername: RedisEnvFailoverAuthUsername,
+	RedisCfgKeyFailoverAuthPassword: RedisEnvFailoverAuthPassword,
+	CfgKeyDurationUnit:              RedisEnvDurationUnit,
+}
+
+// memoryEnvNames maps MemoryCfgKey* xconf keys to MemoryEnv* env vars names.
+var memoryEnvNames = map[string]string{
+	MemoryCfgKeyMemorySize: MemoryEnvMemorySize,
+}
+
+// RedisConfigFromEnv returns a RedisConfig populated with values taken from environment variables,
+// named as RedisEnv* constants, prefixed with given prefix.
+// Example: for "MY_APP_REDIS_" prefix, Redis server(s) are read from "MY_APP_REDIS_ADDRS".
+//
+// Missing variables result in the same defaults used by the xconf adapter (see NewRedis7WithConfig).
+// Invalid values are replaced with defaults, too, and an error aggregating a ConfigError
+// (having the env var name as Key) for each of them is returned.
+func RedisConfigFromEnv(prefix string) (RedisConfig, error) {
+	r := newEnvReader(prefix, redisEnvNames)
+	redisConfig := readRedisConfig(r)
+
+	return redisConfig, r.Err()
+}
+
+// NewRedis6FromEnv instantiates a new Redis6 Cache with configuration taken from environment variables.
+// See RedisConfigFromEnv for more details.
+// If the configuration is invalid, an error is returned, and cache is nil.
+func NewRedis6FromEnv(prefix string) (*Redis6, error) {
+	redisConfig, err := RedisConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedis6(redisConfig), nil
+}
+
+// NewRedis7FromEnv instantiates a new Redis7 Cache with configuration taken from environment variables.
+// See RedisConfigFromEnv for more details.
+// If the configuration is invalid, an error is returned, and cache is nil.
+func NewRedis7FromEnv(prefix string) (*Redis7, error) {
+	redisConfig, err := RedisConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedis7(redisConfig), nil
+}
+
+// NewMemoryFromEnv instantiates a new Memory Cache with memory size taken from
+// the environment variable named MemoryEnvMemorySize, prefixed with given prefix.
+// Example: for "MY_APP_CACHE_" prefix, memory size is read from "MY_APP_CACHE_MEMSIZEBYTES".
+//
+// If the variable is missing, the same default used by the xconf adapter (10M) is used.
+// If the configuration is invalid, an error is returned, and cache is nil.
+func NewMemoryFromEnv(prefix string) (*Memory, error) {
+	r := newEnvReader(prefix, memoryEnvNames)
+	memSize := readMemorySize(r)
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewMemory(memSize), nil
+}
+
+// envConfig is a xconf.Config holding environment variables values under xconf keys.
+type envConfig map[string]any
+
+// Get returns the value for given xconf key.
+func (cfg envConfig) Get(key string, def ...any) any {
+	if value, found := cfg[key]; found {
+		return value
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	return nil
+}
+
+// newEnvReader returns a xconfReader over the environment variables, named as given names
+// (which are indexed by xconf keys) prefixed with given prefix.
+// Errors are reported under env vars names.
+func newEnvReader(prefix string, envNames map[string]string) *xconfReader {
+	config := make(envConfig, len(envNames))
+	for key, envName := range envNames {
+		if value, found := os.LookupEnv(prefix + envName); found {
+			config[key] = value
+		}
+	}
+
+	return &xconfReader{
+		config: config,
+		keyName: func(key string) string {
+			return prefix + envNames[key]
+		},
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestRedisConfigFromEnv(t *testing.T) {
+	t.Run("values are read", testRedisConfigFromEnvValuesAreRead)
+	t.Run("missing values are defaulted", testRedisConfigFromEnvMissingValuesAreDefaulted)
+	t.Run("durations as milliseconds are read", testRedisConfigFromEnvDurationsAsMillisecondsAreRead)
+	t.Run("invalid values are reported", testRedisConfigFromEnvInvalidValuesAreReported)
+}
+
+func testRedisConfigFromEnvValuesAreRead(t *testing.T) {
+	// arrange
+	prefix := "TEST_XCACHE_REDIS_"
+	t.Setenv(prefix+xcache.RedisEnvAddrs, "127.0.0.1:7000,127.0.0.1:7001")
+	t.Setenv(prefix+xcache.RedisEnvDB, "2")
+	t.Setenv(prefix+xcache.RedisEnvAuthUsername, "user")
+	t.Setenv(prefix+xcache.RedisEnvAuthPassword, "pass")
+	t.Setenv(prefix+xcache.RedisEnvDialTimeout, "1s")
+	t.Setenv(prefix+xcache.RedisEnvReadTimeout, "2s")
+	t.Setenv(prefix+xcache.RedisEnvWriteTimeout, "3s")
+	t.Setenv(prefix+xcache.RedisEnvTLS, "true")
+	t.Setenv(prefix+xcache.RedisEnvDisableUnlink, "1")
+	t.Setenv(prefix+xcache.RedisEnvStatsCacheTTL, "500ms")
+	t.Setenv(prefix+xcache.RedisEnvClusterReadonly, "true")
+	t.Setenv(prefix+xcache.RedisEnvFailoverMasterName, "master")
+	t.Setenv(prefix+xcache.RedisEnvFailoverAuthUsername, "sentinel-user")
+	t.Setenv(prefix+xcache.RedisEnvFailoverAuthPassword, "sentinel-pass")
+
+	// act
+	result, err := xcache.RedisConfigFromEnv(prefix)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, []string{"127.0.0.1:7000", "127.0.0.1:7001"}, result.Addrs)
+	assertEqual(t, 2, result.DB)
+	assertEqual(t, xcache.RedisAuth{Username: "user", Password: "pass"}, result.Auth)
+	assertEqual(t, time.Second, result.DialTimeout)
+	assertEqual(t, 2*time.Second, result.ReadTimeout)
+	assertEqual(t, 3*time.Second, result.WriteTimeout)
+	assertNotNil(t, result.TLSConfig)
+	assertTrue(t, result.DisableUnlink)
+	assertEqual(t, 500*time.Millisecond, result.StatsCacheTTL)
+	assertTrue(t, result.ReadOnly)
+	assertEqual(t, "master", result.MasterName)
+	assertEqual(t, xcache.RedisAuth{Username: "sentinel-user", Password: "sentinel-pass"}, result.SentinelAuth)
+}
+
+func testRedisConfigFromEnvMissingValuesAreDefaulted(t *testing.T) {
+	// act
+	result, err := xcache.RedisConfigFromEnv("TEST_XCACHE_MISSING_REDIS_")
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, []string{"127.0.0.1:6379"}, result.Addrs)
+	assertEqual(t, 0, result.DB)
+	assertEqual(t, 5*time.Second, result.DialTimeout)
+	assertEqual(t, 3*time.Second, result.ReadTimeout)
+	assertEqual(t, 5*time.Second, result.WriteTimeout)
+	assertTrue(t, result.TLSConfig == nil)
+	assertTrue(t, !result.DisableUnlink)
+	assertEqual(t, time.Duration(0), result.StatsCacheTTL)
+}
+
+func testRedisConfigFromEnvDurationsAsMillisecondsAreRead(t *testing.T) {
+	// arrange
+	prefix := "TEST_XCACHE_MS_REDIS_"
+	t.Setenv(prefix+xcache.RedisEnvDurationUnit, "ms")
+	t.Setenv(prefix+xcache.RedisEnvDialTimeout, "1000")
+	t.Setenv(prefix+xcache.RedisEnvReadTimeout, "2s")
+	t.Setenv(prefix+xcache.RedisEnvStatsCacheTTL, "500")
+
+	// act
+	result, err := xcache.RedisConfigFromEnv(prefix)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, time.Second, result.DialTimeout)
+	assertEqual(t, 2*time.Second, result.ReadTimeout)
+	assertEqual(t, 5*time.Second, result.WriteTimeout)
+	assertEqual(t, 500*time.Millisecond, result.StatsCacheTTL)
+}
+
+func testRedisConfigFromEnvInvalidValuesAreReported(t *testing.T) {
+	// arrange
+	prefix := "TEST_XCACHE_INVALID_REDIS_"
+	t.Setenv(prefix+xcache.RedisEnvDB, "one")
+	t.Setenv(prefix+xcache.RedisEnvTLS, "sure")
+
+	// act
+	result, err := xcache.RedisConfigFromEnv(prefix)
+	cache6, err6 := xcache.NewRedis6FromEnv(prefix)
+	cache7, err7 := xcache.NewRedis7FromEnv(prefix)
+
+	// assert
+	assertConfigErrorKeys(t, []string{prefix + xcache.RedisEnvDB, prefix + xcache.RedisEnvTLS}, err)
+	assertEqual(t, 0, result.DB)
+	assertTrue(t, result.TLSConfig == nil)
+	assertNotNil(t, err6)
+	assertTrue(t, cache6 == nil)
+	assertNotNil(t, err7)
+	assertTrue(t, cache7 == nil)
+}
+
+func TestNewRedisFromEnv(t *testing.T) {
+	// arrange
+	prefix := "TEST_XCACHE_NEW_REDIS_"
+	t.Setenv(prefix+xcache.RedisEnvAddrs, "127.0.0.1:6379")
+
+	// act
+	cache6, err6 := xcache.NewRedis6FromEnv(prefix)
+	cache7, err7 := xcache.NewRedis7FromEnv(prefix)
+
+	// assert
+	requireNil(t, err6)
+	requireNil(t, err7)
+	assertNil(t, cache6.Close())
+	assertNil(t, cache7.Close())
+}
+
+func TestNewMemoryFromEnv(t *testing.T) {
+	t.Run("value is read", func(t *testing.T) {
+		// arrange
+		prefix := "TEST_XCACHE_MEMORY_"
+		t.Setenv(prefix+xcache.MemoryEnvMemorySize, "1048576")
+
+		// act
+		subject, err := xcache.NewMemoryFromEnv(prefix)
+
+		// assert
+		requireNil(t, err)
+		stats, _ := subject.Stats(context.Background())
+		assertEqual(t, int64(1048576), stats.MaxMemory)
+	})
+
+	t.Run("missing value is defaulted", func(t *testing.T) {
+		// act
+		subject, err := xcache.NewMemoryFromEnv("TEST_XCACHE_MISSING_MEMORY_")
+
+		// assert
+		requireNil(t, err)
+		stats, _ := subject.Stats(context.Background())
+		assertEqual(t, int64(10*1024*1024), stats.MaxMemory)
+	})
+
+	t.Run("invalid value is reported", func(t *testing.T) {
+		// arrange
+		prefix := "TEST_XCACHE_INVALID_MEMORY_"
+		t.Setenv(prefix+xcache.MemoryEnvMemorySize, "10M")
+
+		// act
+		subject, err := xcache.NewMemoryFromEnv(prefix)
+
+		// assert
+		assertConfigErrorKeys(t, []string{prefix + xcache.MemoryEnvMemorySize}, err)
+		assertTrue(t, subject == nil)
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidEnvelope is returned when a value cannot be unmarshaled as an Envelope.
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// envelope header markers.
+const (
+	envelopeMagic         = 0xE7
+	envelopeFormatVersion = 1
+	envelopeFixedSize     = 3 // magic, format version, flags.
+)
+
+// envelope flags, signaling which optional fields are present.
+const (
+	envelopeFlagCompression = 1 << iota
+	envelopeFlagChunks
+	envelopeFlagVersion
+	envelopeFlagMetadata
+	envelopeFlagExpireAt
+	envelopeFlagsAll = envelopeFlagCompression | envelopeFlagChunks | envelopeFlagVersion |
+		envelopeFlagMetadata | envelopeFlagExpireAt
+)
+
+// Envelope holds the information stored alongside a value by features which need it
+// (compression headers, chunking, versioning, expiration caps, metadata).
+//
+// A value with an envelope has the format:
+//
+//	<magic byte> <format version byte> <flags byte> [optional fields] <payload>
+//
+// Only the non-zero fields are encoded (as uvarints), so an envelope with no field set
+// adds 3 bytes to the payload.
+//
+// Envelopes are designed to be used without allocations: an Envelope can be a stack variable,
+// it is appended to a buffer (of Size capacity), and unmarshaled in place, its Metadata and the returned payload
+// referencing the unmarshaled value.
+type Envelope struct {
+	// Compression identifies the algorithm the payload is compressed with. 0 means no compression.
+	Compression uint8
+	// Chunks represents the no. of chunks a value was split into. 0 means the value is not chunked.
+	Chunks uint32
+	// Version represents the version of the value (monotonic, per key). 0 means no version.
+	Version uint64
+	// ExpireAt represents the moment (unix time, in milliseconds) the value expires at,
+	// regardless of its expiration period in cache (which can be extended, on access, for example).
+	// 0 means no such moment.
+	ExpireAt int64
+	// Metadata holds some arbitrary information about the value.
+	Metadata []byte
+}
+
+// Size returns the no. of bytes the envelope and given payload size occupy.
+func (env Envelope) Size(payloadSize int) int {
+	size := envelopeFixedSize + payloadSize
+	if env.Compression != 0 {
+		size++
+	}
+	if env.Chunks != 0 {
+		size += uvarintSize(uint64(env.Chunks))
+	}
+	if env.Version != 0 {
+		size += uvarintSize(env.Version)
+	}
+	if env.ExpireAt > 0 {
+		size += uvarintSize(uint64(env.ExpireAt))
+	}
+	if len(env.Metadata) != 0 {
+		size += uvarintSize(uint64(len(env.Metadata))) + len(env.Metadata)
+	}
+
+	return size
+}
+
+// Append appends the envelope and given payload to dst, and returns the extended buffer.
+// If dst has enough capacity (see Size), no allocation is made.
+func (env Envelope) Append(dst, payload []byte) []byte {
+	var flags byte
+	if env.Compression != 0 {
+		flags |= envelopeFlagCompression
+	}
+	if env.Chunks != 0 {
+		flags |= envelopeFlagChunks
+	}
+	if env.Version != 0 {
+		flags |= envelopeFlagVersion
+	}
+	if env.ExpireAt > 0 {
+		flags |= envelopeFlagExpireAt
+	}
+	if len(env.Metadata) != 0 {
+		flags |= envelopeFlagMetadata
+	}
+
+	dst = append(dst, envelopeMagic, envelopeFormatVersion, flags)
+	if env.Compression != 0 {
+		dst = append(dst, env.Compression)
+	}
+	if env.Chunks != 0 {
+		dst = binary.AppendUvarint(dst, uint64(env.Chunks))
+	}
+	if env.Version != 0 {
+		dst = binary.AppendUvarint(dst, env.Version)
+	}
+	if env.ExpireAt > 0 {
+		dst = binary.AppendUvarint(dst, uint64(env.ExpireAt))
+	}
+	if len(env.Metadata) != 0 {
+		dst = binary.AppendUvarint(dst, uint64(len(env.Metadata)))
+		dst = append(dst, env.Metadata...)
+	}
+
+	return append(dst, payload...)
+}
+
+// Unmarshal reads the envelope from given value, and returns the payload.
+// Metadata and the returned payload reference the given value, they are not copied.
+// It returns ErrInvalidEnvelope if the value does not have a (valid) envelope.
+func (env *Envelope) Unmarshal(value []byte) ([]byte, error) {
+	if !IsEnvelope(value) {
+		return nil, ErrInvalidEnvelope
+	}
+	flags := value[2]
+	if flags&^envelopeFlagsAll != 0 {
+		return nil, ErrInvalidEnvelope
+	}
+	*env = Envelope{}
+	value = value[envelopeFixedSize:]
+
+	if flags&envelopeFlagCompression != 0 {
+		if len(value) == 0 {
+			return nil, ErrInvalidEnvelope
+		}
+		env.Compression = value[0]
+		value = value[1:]
+	}
+	if flags&envelopeFlagChunks != 0 {
+		chunks, n := binary.Uvarint(value)
+		if n <= 0 || chunks > uint64(^uint32(0)) {
+			return nil, ErrInvalidEnvelope
+		}
+		env.Chunks = uint32(chunks)
+		value = value[n:]
+	}
+	if flags&envelopeFlagVersion != 0 {
+		version, n := binary.Uvarint(value)
+		if n <= 0 {
+			return nil, ErrInvalidEnvelope
+		}
+		env.Version = version
+		value = value[n:]
+	}
+	if flags&envelopeFlagExpireAt != 0 {
+		expireAt, n := binary.Uvarint(value)
+		if n <= 0 || expireAt > math.MaxInt64 {
+			return nil, ErrInvalidEnvelope
+		}
+		env.ExpireAt = int64(expireAt)
+		value = value[n:]
+	}
+	if flags&envelopeFlagMetadata != 0 {
+		metadataSize, n := binary.Uvarint(value)
+		if n <= 0 || metadataSize > uint64(len(value)-n) {
+			return nil, ErrInvalidEnvelope
+		}
+		value = value[n:]
+		env.Metadata = value[:metadataSize:metadataSize]
+		value = value[metadataSize:]
+	}
+
+	return value, nil
+}
+
+// IsEnvelope checks if given value starts with an envelope header
+// (so values stored before a feature started to use envelopes can be recognized).
+func IsEnvelope(value []byte) bool {
+	return len(value) >= envelopeFixedSize &&
+		value[0] == envelopeMagic &&
+		value[1] == envelopeFormatVersion
+}
+
+// uvarintSize returns the no. of bytes given number occupies, uvarint encoded.
+func uvarintSize(x uint64) int {
+	size := 1
+	for x >= 0x80 {
+		x >>= 7
+		size++
+	}
+
+	return size
+}
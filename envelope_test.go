@@ -0,0 +1,236 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	t.Run("marshal - unmarshal", testEnvelopeMarshalUnmarshal)
+	t.Run("invalid envelope", testEnvelopeInvalidEnvelope)
+	t.Run("enveloped values are not reused", testEnvelopeEnvelopedValuesAreNotReused)
+}
+
+func testEnvelopeMarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte("test payload")
+	tests := [...]struct {
+		name string
+		env  xcache.Envelope
+	}{
+		{
+			name: "no field",
+			env:  xcache.Envelope{},
+		},
+		{
+			name: "compression",
+			env:  xcache.Envelope{Compression: 1},
+		},
+		{
+			name: "all fields",
+			env: xcache.Envelope{
+				Compression: 2,
+				Chunks:      300,
+				Version:     1<<63 + 1,
+				ExpireAt:    1700000000000,
+				Metadata:    []byte("test metadata"),
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			value := test.env.Append(nil, payload)
+			var resultEnv xcache.Envelope
+			resultPayload, err := resultEnv.Unmarshal(value)
+
+			// assert
+			requireNil(t, err)
+			assertEqual(t, test.env.Size(len(payload)), len(value))
+			assertTrue(t, xcache.IsEnvelope(value))
+			assertEqual(t, test.env, resultEnv)
+			assertEqual(t, payload, resultPayload)
+		})
+	}
+}
+
+func testEnvelopeInvalidEnvelope(t *testing.T) {
+	t.Parallel()
+
+	valid := xcache.Envelope{Version: 1, Metadata: []byte("test metadata")}.Append(nil, []byte("test payload"))
+	tests := [...]struct {
+		name  string
+		value []byte
+	}{
+		{
+			name:  "empty value",
+			value: nil,
+		},
+		{
+			name:  "value without envelope",
+			value: []byte("test payload"),
+		},
+		{
+			name:  "unknown flags",
+			value: []byte{valid[0], valid[1], 0xFF, 'x'},
+		},
+		{
+			name:  "truncated fields",
+			value: valid[:6],
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			var env xcache.Envelope
+			payload, err := env.Unmarshal(test.value)
+
+			// assert
+			assertTrue(t, errors.Is(err, xcache.ErrInvalidEnvelope))
+			assertNil(t, payload)
+		})
+	}
+}
+
+func testEnvelopeEnvelopedValuesAreNotReused(t *testing.T) {
+	t.Parallel()
+
+	decorators := map[string]func(xcache.Cache) xcache.Cache{
+		"compressed": func(cache xcache.Cache) xcache.Cache {
+			return xcache.NewCompressed(cache, xcache.NewGzipCompressor(0))
+		},
+		"encrypted": func(cache xcache.Cache) xcache.Cache {
+			encrypted, _ := xcache.NewEncrypted(cache, xcache.EncryptionKey{ID: "k1", Key: bytes.Repeat([]byte{1}, 32)})
+
+			return encrypted
+		},
+		"tti": func(cache xcache.Cache) xcache.Cache {
+			return xcache.NewTimeToIdle(cache, time.Minute)
+		},
+		"stale": func(cache xcache.Cache) xcache.Cache {
+			return xcache.NewStaleWhileRevalidate(cache, xcache.StaleWhileRevalidateConfig{StalePeriod: time.Hour})
+		},
+		"xfetch": func(cache xcache.Cache) xcache.Cache {
+			return xcache.NewXFetch(cache, 1)
+		},
+		"refresh ahead": func(cache xcache.Cache) xcache.Cache {
+			return xcache.NewRefreshAhead(cache, time.Minute, nil, nil)
+		},
+	}
+
+	for name, decorate := range decorators {
+		decorate := decorate // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var (
+				mu       sync.Mutex
+				retained [][]byte // values, as the decorated cache is allowed to keep them.
+				copies   [][]byte // values, as they were saved.
+				cache    = new(xcache.Mock)
+				subject  = decorate(cache)
+				ctx      = context.Background()
+			)
+			cache.SetSaveCallback(func(_ context.Context, _ string, value []byte, _ time.Duration) error {
+				mu.Lock()
+				retained = append(retained, value)
+				copies = append(copies, append([]byte(nil), value...))
+				mu.Unlock()
+
+				return nil
+			})
+
+			// act
+			for i := 0; i < 10; i++ {
+				value := bytes.Repeat([]byte{byte('a' + i)}, 64)
+				requireNil(t, subject.Save(ctx, "test-envelope-key", value, time.Minute))
+			}
+
+			// assert
+			mu.Lock()
+			defer mu.Unlock()
+			assertEqual(t, 10, len(retained))
+			for i := range retained {
+				assertEqual(t, copies[i], retained[i])
+			}
+		})
+	}
+}
+
+func TestEnvelope_noAllocations(t *testing.T) {
+	// Note: test is not parallel as AllocsPerRun does not support it.
+
+	// arrange
+	var (
+		env = xcache.Envelope{
+			Compression: 1,
+			Version:     123,
+			Metadata:    []byte("test metadata"),
+		}
+		payload = []byte("test payload")
+		buf     = make([]byte, 0, env.Size(len(payload)))
+	)
+
+	// act
+	allocs := testing.AllocsPerRun(100, func() {
+		value := env.Append(buf[:0], payload)
+		var resultEnv xcache.Envelope
+		if _, err := resultEnv.Unmarshal(value); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// assert
+	assertEqual(t, 0.0, allocs)
+}
+
+func BenchmarkEnvelope_Append(b *testing.B) {
+	env := xcache.Envelope{Compression: 1, Version: 123, Metadata: []byte("test metadata")}
+	payload := []byte("test payload")
+	buf := make([]byte, 0, env.Size(len(payload)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		buf = env.Append(buf[:0], payload)
+	}
+}
+
+func BenchmarkEnvelope_Unmarshal(b *testing.B) {
+	value := xcache.Envelope{Compression: 1, Version: 123, Metadata: []byte("test metadata")}.
+		Append(nil, []byte("test payload"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		var env xcache.Envelope
+		if _, err := env.Unmarshal(value); err != nil {
+			b.Error(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+
+	redis6 "github.com/go-redis/redis/v8"
+	redis7 "github.com/redis/go-redis/v9"
+)
+
+// ErrorClass is the class of a cache operation's error, see ClassifyError.
+type ErrorClass string
+
+// Error classes.
+const (
+	// ErrorClassNone is the class of a successful operation (including a not found key).
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassTimeout is the class of an operation which timed out
+	// (context's deadline exceeded, network timeout).
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassCanceled is the class of an operation which was canceled (by its context).
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassUnavailable is the class of an operation which could not reach the cache
+	// (connection refused / reset / closed, overloaded cache, see ErrOverloaded,
+	// open circuit breaker, see ErrCircuitOpen, exceeded rate limit, see ErrRateLimited).
+	ErrorClassUnavailable ErrorClass = "unavailable"
+	// ErrorClassBackend is the class of an operation which failed for any other reason
+	// (error reported by the cache server, invalid data, etc.).
+	ErrorClassBackend ErrorClass = "backend"
+)
+
+// ClassifyError returns the class of given cache operation's error,
+// so that errors can be reported / handled by their nature, regardless of the cache implementation.
+// ErrNotFound is not considered an error, ErrorClassNone is returned for it.
+func ClassifyError(err error) ErrorClass {
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return ErrorClassNone
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, ErrOverloaded) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, redis6.ErrClosed) ||
+		errors.Is(err, redis7.ErrClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return ErrorClassUnavailable
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassUnavailable
+	}
+
+	return ErrorClassBackend
+}
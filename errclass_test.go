@@ -0,0 +1,98 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name          string
+		err           error
+		expectedClass xcache.ErrorClass
+	}{
+		{
+			name:          "nil",
+			err:           nil,
+			expectedClass: xcache.ErrorClassNone,
+		},
+		{
+			name:          "not found",
+			err:           xcache.ErrNotFound,
+			expectedClass: xcache.ErrorClassNone,
+		},
+		{
+			name:          "deadline exceeded",
+			err:           fmt.Errorf("load: %w", context.DeadlineExceeded),
+			expectedClass: xcache.ErrorClassTimeout,
+		},
+		{
+			name:          "network timeout",
+			err:           &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			expectedClass: xcache.ErrorClassTimeout,
+		},
+		{
+			name:          "canceled",
+			err:           context.Canceled,
+			expectedClass: xcache.ErrorClassCanceled,
+		},
+		{
+			name:          "connection refused",
+			err:           &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			expectedClass: xcache.ErrorClassUnavailable,
+		},
+		{
+			name:          "eof",
+			err:           io.EOF,
+			expectedClass: xcache.ErrorClassUnavailable,
+		},
+		{
+			name:          "overloaded",
+			err:           xcache.ErrOverloaded,
+			expectedClass: xcache.ErrorClassUnavailable,
+		},
+		{
+			name:          "circuit open",
+			err:           xcache.ErrCircuitOpen,
+			expectedClass: xcache.ErrorClassUnavailable,
+		},
+		{
+			name:          "rate limited",
+			err:           xcache.ErrRateLimited,
+			expectedClass: xcache.ErrorClassUnavailable,
+		},
+		{
+			name:          "backend",
+			err:           errors.New("OOM command not allowed when used memory > 'maxmemory'"),
+			expectedClass: xcache.ErrorClassBackend,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result := xcache.ClassifyError(test.err)
+
+			// assert
+			assertEqual(t, test.expectedClass, result)
+		})
+	}
+}
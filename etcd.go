@@ -0,0 +1,185 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"crypto/tls"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdConfig holds the settings of an Etcd cache.
+type EtcdConfig struct {
+	// Endpoints is the list of etcd members' URLs.
+	// Example:
+	//	Endpoints: []string{"http://etcd-0.etcd:2379", "http://etcd-1.etcd:2379", "http://etcd-2.etcd:2379"}
+	Endpoints []string
+	// Username is the user name for authentication, if enabled.
+	Username string
+	// Password is the password for authentication, if enabled.
+	Password string
+	// DialTimeout is the timeout for failing to establish a connection. By default (0), it's 5 seconds.
+	DialTimeout time.Duration
+	// TLSConfig is the TLS configuration to use. When set, TLS is negotiated.
+	TLSConfig *tls.Config
+	// Prefix is prepended to each key, isolating the cache's keys from other etcd data.
+	// By default (""), it's "xcache/".
+	Prefix string
+}
+
+// Etcd is an etcd (v3) based implementation for Cache, intended for small, config like, values,
+// in environments where etcd is already present (Kubernetes native), and Redis isn't.
+// A key's expiration is implemented with a lease, granted at each save.
+// Note: lease TTLs have a second granularity, an expiration period is rounded up to a whole second
+// (and etcd may raise it to its minimum lease TTL, of a couple of seconds).
+// It implements io.Closer, and thus it should be closed at your application shutdown.
+type Etcd struct {
+	client *clientv3.Client
+	prefix string
+	hits   int64
+	misses int64
+}
+
+// NewEtcd instantiates a new Etcd Cache instance, according to given settings.
+// It returns an error if the etcd client cannot be created (for example, no endpoint is given).
+func NewEtcd(config EtcdConfig) (*Etcd, error) {
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.Prefix == "" {
+		config.Prefix = "xcache/"
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   config.Endpoints,
+		Username:    config.Username,
+		Password:    config.Password,
+		DialTimeout: config.DialTimeout,
+		TLS:         config.TLSConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Etcd{
+		client: client,
+		prefix: config.Prefix,
+	}, nil
+}
+
+// Save stores the given key-value with expiration period into cache.
+// An expiration period equal to 0 (NoExpire) means no expiration (the key is not attached to a lease).
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *Etcd) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	var opts []clientv3.OpOption
+	if expire > 0 {
+		ttl := int64((expire + time.Second - 1) / time.Second) // round up to a whole second.
+		lease, err := cache.client.Grant(ctx, ttl)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, clientv3.WithLease(lease.ID))
+	}
+	_, err := cache.client.Put(ctx, cache.prefix+key, string(value), opts...)
+
+	return err
+}
+
+// Load returns a key's value from cache, or an error if something bad happened.
+// If the key is not found, ErrNotFound is returned.
+func (cache *Etcd) Load(ctx context.Context, key string) ([]byte, error) {
+	kv, err := cache.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, ErrNotFound
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return kv.Value, nil
+}
+
+// TTL returns a key's remaining time to live (its lease's), or an error if something bad happened.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *Etcd) TTL(ctx context.Context, key string) (time.Duration, error) {
+	kv, err := cache.get(ctx, key)
+	if err != nil {
+		return -1, err
+	}
+	if kv == nil {
+		return -1, nil
+	}
+	if kv.Lease == 0 {
+		return NoExpire, nil
+	}
+
+	lease, err := cache.client.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+	if err != nil {
+		return -1, err
+	}
+	if lease.TTL <= 0 { // lease expired (-1) meanwhile.
+		return -1, nil
+	}
+
+	return time.Duration(lease.TTL) * time.Second, nil
+}
+
+// Stats returns statistics about etcd cache: the no. of keys (under configured prefix),
+// and the hits and misses, since the cache was instantiated.
+// Memory is not reported (etcd's database is shared with other data).
+// It returns an error if the keys could not be counted.
+func (cache *Etcd) Stats(ctx context.Context) (Stats, error) {
+	resp, err := cache.client.Get(ctx, cache.prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Keys:   resp.Count,
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key from cache.
+// It returns an error if the key could not be deleted.
+func (cache *Etcd) Delete(ctx context.Context, key string) error {
+	_, err := cache.client.Delete(ctx, cache.prefix+key)
+
+	return err
+}
+
+// Close closes the underlying etcd client.
+func (cache *Etcd) Close() error {
+	return cache.client.Close()
+}
+
+// get returns the etcd key-value of given key, or nil, if it does not exist.
+func (cache *Etcd) get(ctx context.Context, key string) (*mvccpb.KeyValue, error) {
+	resp, err := cache.client.Get(ctx, cache.prefix+key)
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+
+	return resp.Kvs[0], nil
+}
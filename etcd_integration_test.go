@@ -0,0 +1,93 @@
+//go:build integration
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+var etcdConfigIntegration = xcache.EtcdConfig{
+	Endpoints: []string{"127.0.0.1:2379"},
+	Prefix:    "xcache-integration/",
+}
+
+func init() {
+	etcdEndpoints := os.Getenv("XCACHE_ETCD_ENDPOINTS")
+	if etcdEndpoints != "" {
+		etcdConfigIntegration.Endpoints = strings.Split(etcdEndpoints, ",")
+	}
+}
+
+func TestEtcd_integration(t *testing.T) {
+	t.Parallel()
+
+	// setup
+	subject, err := xcache.NewEtcd(etcdConfigIntegration)
+	requireNil(t, err)
+
+	t.Run("wait", func(t *testing.T) { // wait for parallel tests to complete
+		t.Run("key that does not expire", testCacheWithNoExpireKey(subject))
+		t.Run("key expires", testEtcdKeyExpires(subject))
+		t.Run("key does not exist", testCacheWithNotExistKey(subject))
+		t.Run("delete key", testCacheDeleteKey(subject))
+		t.Run("ttl for not yet expired key", testCacheTTLWithNotYetExpiredKey(subject))
+	})
+
+	// stats
+	stats, err := subject.Stats(context.Background())
+	assertNil(t, err)
+	assertTrue(t, stats.Keys >= 2) // no expire key, not yet expired key.
+	assertTrue(t, stats.Hits > 0)
+	assertTrue(t, stats.Misses > 0)
+
+	// tear down
+	err = subject.Close()
+	assertNil(t, err)
+}
+
+// testEtcdKeyExpires is like testCacheWithExpireKey, allowing etcd's min. lease TTL to elapse.
+func testEtcdKeyExpires(subject xcache.Cache) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			key   = "test-expire-key"
+			value = []byte("test value")
+			ctx   = context.Background()
+			exp   = 500 * time.Millisecond
+		)
+
+		// act & assert save
+		resultErr := subject.Save(ctx, key, value, exp)
+		requireNil(t, resultErr)
+
+		// act & assert load
+		resultValue, resultErr := subject.Load(ctx, key)
+		assertNil(t, resultErr)
+		assertEqual(t, value, resultValue)
+
+		// act & assert load after expire time passed
+		time.Sleep(4 * time.Second)
+		resultValue, resultErr = subject.Load(ctx, key)
+		assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+		assertNil(t, resultValue)
+
+		// act & assert ttl
+		resultTTL, resultErr := subject.TTL(ctx, key)
+		assertNil(t, resultErr)
+		assertTrue(t, resultTTL < 0)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Etcd)(nil)   // test Etcd is a Cache
+	var _ xcache.Deleter = (*xcache.Etcd)(nil) // test Etcd is a Deleter
+	var _ io.Closer = (*xcache.Etcd)(nil)      // test Etcd is a Closer
+}
+
+func TestNewEtcd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no endpoints", testNewEtcdWithNoEndpoints)
+	t.Run("done context", testNewEtcdWithDoneContext)
+}
+
+func testNewEtcdWithNoEndpoints(t *testing.T) {
+	t.Parallel()
+
+	// act
+	subject, err := xcache.NewEtcd(xcache.EtcdConfig{})
+
+	// assert
+	assertNotNil(t, err)
+	assertTrue(t, subject == nil)
+}
+
+func testNewEtcdWithDoneContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := xcache.NewEtcd(xcache.EtcdConfig{
+		Endpoints:   []string{"127.0.0.1:1"},
+		DialTimeout: time.Second,
+	})
+	requireNil(t, err)
+	defer subject.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	errSave := subject.Save(ctx, "test-etcd-key", []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, "test-etcd-key")
+
+	// assert
+	assertTrue(t, errors.Is(errSave, context.Canceled))
+	assertTrue(t, errors.Is(errLoad, context.Canceled))
+}
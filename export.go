@@ -0,0 +1,145 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportConfig holds the settings of ExportKeys.
+type ExportConfig struct {
+	// Prefix filters the exported keys. By default, all the keys are exported.
+	Prefix string
+	// Cursor resumes an interrupted export, see ExportKeys. By default, the export starts from the beginning.
+	Cursor string
+	// BatchSize is the no. of keys scanned / loaded at once. By default, it's 100.
+	BatchSize int
+	// HashValues enables exporting the (hex encoded) SHA-256 hashes of the values,
+	// so values can be compared / deduplicated offline, without exporting them.
+	HashValues bool
+	// RateLimit is the max. no. of keys exported per second, so that the export does not impact
+	// a production cache. By default (0), there is no limit.
+	RateLimit int
+}
+
+// ExportKeys streams the keys of given cache, with their values' sizes (in bytes), and TTLs (in milliseconds,
+// 0 meaning no expiration), and, optionally, their values' hashes, as CSV records, to given writer,
+// for offline analysis (in a data warehouse, for example). The first record is the header:
+//
+//	key,size,ttl_ms[,sha256]
+//
+// Keys are iterated with Scan (the cache must be a Scanner, otherwise [errors.ErrUnsupported] is returned),
+// and their values are loaded in batches (with LoadMany, if the cache is a Batcher).
+// Keys which expire during the export are skipped.
+// Note: the output can be converted into other formats, like Parquet, with the tools of the data warehouse.
+//
+// It returns the no. of exported keys, and, if the export is interrupted (by an error, or by the context
+// being done), the cursor it can be resumed from (see ExportConfig.Cursor), along with the error.
+// A resumed export does not write the header again.
+func ExportKeys(ctx context.Context, cache Cache, w io.Writer, config ExportConfig) (int, string, error) {
+	scanner, ok := cache.(Scanner)
+	if !ok {
+		return 0, "", fmt.Errorf("%w: cache is not a Scanner", errors.ErrUnsupported)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = scanDefaultCount
+	}
+
+	var (
+		csvWriter = csv.NewWriter(w)
+		record    = make([]string, 0, 4)
+		exported  int
+		cursor    = config.Cursor
+		start     = time.Now()
+	)
+	if cursor == "" {
+		record = append(record, "key", "size", "ttl_ms")
+		if config.HashValues {
+			record = append(record, "sha256")
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return 0, cursor, err
+		}
+	}
+
+	for {
+		keys, nextCursor, err := scanner.Scan(ctx, cursor, config.Prefix, config.BatchSize)
+		if err != nil {
+			return exported, cursor, err
+		}
+		if len(keys) > 0 {
+			values, err := loadMany(ctx, cache, keys)
+			if err != nil {
+				return exported, cursor, err
+			}
+			for _, key := range keys {
+				value, found := values[key]
+				if !found {
+					continue // expired meanwhile.
+				}
+				ttl, err := cache.TTL(ctx, key)
+				if err != nil {
+					return exported, cursor, err
+				}
+				if ttl < 0 {
+					continue // expired meanwhile.
+				}
+				record = append(record[:0], key, strconv.Itoa(len(value)), strconv.FormatInt(ttl.Milliseconds(), 10))
+				if config.HashValues {
+					hash := sha256.Sum256(value)
+					record = append(record, hex.EncodeToString(hash[:]))
+				}
+				if err := csvWriter.Write(record); err != nil {
+					return exported, cursor, err
+				}
+				exported++
+			}
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return exported, cursor, err
+			}
+		}
+		cursor = nextCursor
+		if cursor == "" {
+			break
+		}
+		if err := exportWait(ctx, start, exported, config.RateLimit); err != nil {
+			return exported, cursor, err
+		}
+	}
+	csvWriter.Flush()
+
+	return exported, "", csvWriter.Error()
+}
+
+// exportWait waits, if needed, so that the no. of exported keys since start does not exceed the rate limit.
+// It returns the context's error, if it's done meanwhile.
+func exportWait(ctx context.Context, start time.Time, exported, rateLimit int) error {
+	if rateLimit <= 0 {
+		return ctx.Err()
+	}
+	wait := time.Duration(exported)*time.Second/time.Duration(rateLimit) - time.Since(start)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
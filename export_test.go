@@ -0,0 +1,157 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestExportKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys are exported", testExportKeysKeysAreExported)
+	t.Run("interrupted export is resumed", testExportKeysInterruptedExportIsResumed)
+	t.Run("rate limit", testExportKeysRateLimit)
+	t.Run("cache is not a scanner", testExportKeysCacheIsNotAScanner)
+}
+
+func testExportKeysKeysAreExported(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache = xcache.NewMemory(freecacheMinMem)
+		ctx   = context.Background()
+		out   bytes.Buffer
+	)
+	requireNil(t, cache.Save(ctx, "test-export-key-1", []byte("value 1"), time.Minute))
+	requireNil(t, cache.Save(ctx, "test-export-key-2", []byte("value 22"), xcache.NoExpire))
+	requireNil(t, cache.Save(ctx, "test-other-key", []byte("other value"), time.Minute))
+	hash := sha256.Sum256([]byte("value 1"))
+
+	// act
+	exported, cursor, err := xcache.ExportKeys(ctx, cache, &out, xcache.ExportConfig{
+		Prefix:     "test-export-",
+		BatchSize:  1,
+		HashValues: true,
+	})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, exported)
+	assertEqual(t, "", cursor)
+	records, err := csv.NewReader(&out).ReadAll()
+	requireNil(t, err)
+	if !assertEqual(t, 3, len(records)) {
+		return
+	}
+	assertEqual(t, []string{"key", "size", "ttl_ms", "sha256"}, records[0])
+	sort.Slice(records[1:], func(i, j int) bool { return records[1+i][0] < records[1+j][0] })
+	assertEqual(t, "test-export-key-1", records[1][0])
+	assertEqual(t, "7", records[1][1])
+	assertTrue(t, records[1][2] == "60000" || records[1][2] == "59000")
+	assertEqual(t, hex.EncodeToString(hash[:]), records[1][3])
+	assertEqual(t, "test-export-key-2", records[2][0])
+	assertEqual(t, "8", records[2][1])
+	assertEqual(t, "0", records[2][2])
+}
+
+func testExportKeysInterruptedExportIsResumed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = xcache.NewMemory(freecacheMinMem)
+		ctx         = context.Background()
+		out         bytes.Buffer
+		keys        = []string{"test-export-key-1", "test-export-key-2", "test-export-key-3"}
+		config      = xcache.ExportConfig{BatchSize: 1, RateLimit: 1}
+		ctxTimeout  context.Context
+		cancelCtx   context.CancelFunc
+		exportedKey = make([]string, 0, len(keys))
+	)
+	for _, key := range keys {
+		requireNil(t, cache.Save(ctx, key, []byte("value"), time.Minute))
+	}
+	ctxTimeout, cancelCtx = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelCtx()
+
+	// act
+	exported, cursor, err := xcache.ExportKeys(ctxTimeout, cache, &out, config)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertEqual(t, 1, exported)
+	assertTrue(t, cursor != "")
+
+	// act & assert resume
+	config.Cursor, config.RateLimit = cursor, 0
+	exported, cursor, err = xcache.ExportKeys(ctx, cache, &out, config)
+	assertNil(t, err)
+	assertEqual(t, 2, exported)
+	assertEqual(t, "", cursor)
+	records, err := csv.NewReader(&out).ReadAll()
+	requireNil(t, err)
+	if !assertEqual(t, 4, len(records)) {
+		return
+	}
+	for _, record := range records[1:] {
+		exportedKey = append(exportedKey, record[0])
+	}
+	sort.Strings(exportedKey)
+	assertEqual(t, keys, exportedKey)
+}
+
+func testExportKeysRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache = xcache.NewMemory(freecacheMinMem)
+		ctx   = context.Background()
+		out   bytes.Buffer
+	)
+	for _, key := range []string{"test-export-key-1", "test-export-key-2", "test-export-key-3"} {
+		requireNil(t, cache.Save(ctx, key, []byte("value"), time.Minute))
+	}
+	start := time.Now()
+
+	// act
+	exported, _, err := xcache.ExportKeys(ctx, cache, &out, xcache.ExportConfig{BatchSize: 1, RateLimit: 20})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, exported)
+	assertTrue(t, time.Since(start) >= 100*time.Millisecond) // 3rd key is exported after 2/20 seconds.
+}
+
+func testExportKeysCacheIsNotAScanner(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache = xcache.NewTimeToIdle(xcache.NewMemory(freecacheMinMem), time.Minute)
+		out   bytes.Buffer
+	)
+
+	// act
+	exported, _, err := xcache.ExportKeys(context.Background(), cache, &out, xcache.ExportConfig{})
+
+	// assert
+	assertTrue(t, errors.Is(err, errors.ErrUnsupported))
+	assertEqual(t, 0, exported)
+	assertEqual(t, 0, out.Len())
+}
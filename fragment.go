@@ -0,0 +1,170 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FragmentsConfig holds the settings of a Fragments cache helper.
+type FragmentsConfig struct {
+	// KeyPrefix is prepended to fragments' keys.
+	KeyPrefix string
+	// TTL is the expiration period of cached fragments.
+	// A value of 0 (NoExpire) means no expiration.
+	TTL time.Duration
+	// CompressionLevel is the gzip compression level fragments are stored with.
+	// By default (0), gzip.DefaultCompression is used.
+	CompressionLevel int
+}
+
+// RenderFunc renders a fragment (a template executed with some data, for example) into given writer.
+type RenderFunc func(w io.Writer) error
+
+// Fragments caches rendered fragments (like HTML ones), keyed by the template name,
+// the hash of the data it is rendered with, and the values of some dimensions the rendering varies by
+// (like the HTTP Vary header does - language, device type, etc.).
+// Fragments are stored gzip compressed.
+//
+// Keys have the format: <KeyPrefix><template name>:<hash>, so all the fragments of a template
+// can be deleted with a PrefixDeleter, if the cache is one.
+type Fragments struct {
+	cache       Cache
+	config      FragmentsConfig
+	gzipWriters sync.Pool // reusable gzip writers, of configured compression level.
+	gzipReaders sync.Pool // reusable gzip readers.
+}
+
+// NewFragments instantiates a new Fragments which caches rendered fragments into given cache,
+// according to given settings.
+func NewFragments(cache Cache, config FragmentsConfig) *Fragments {
+	if config.CompressionLevel == 0 {
+		config.CompressionLevel = gzip.DefaultCompression
+	}
+
+	return &Fragments{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Render writes into w the cached fragment of given template name, data and dimensions, if any,
+// otherwise, it renders the fragment with given function, writes it into w, and caches it.
+// The data is JSON encoded in order to be hashed, if it cannot be, the fragment is rendered without being cached.
+// Cache errors are not returned, the fragment is rendered instead.
+// It returns the render function's error, or w's one.
+func (f *Fragments) Render(
+	ctx context.Context,
+	w io.Writer,
+	name string,
+	data any,
+	vary map[string]string,
+	render RenderFunc,
+) error {
+	key, err := f.Key(name, data, vary)
+	if err != nil {
+		return render(w)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if cached, err := f.cache.Load(ctx, key); err == nil {
+		if err := f.gunzip(buf, cached); err == nil {
+			_, err = w.Write(buf.Bytes())
+
+			return err
+		}
+		buf.Reset()
+	}
+
+	if err := render(buf); err != nil {
+		return err
+	}
+	var compressed bytes.Buffer // not pooled, as the cache may retain it.
+	if err := f.gzip(&compressed, buf.Bytes()); err == nil {
+		_ = f.cache.Save(ctx, key, compressed.Bytes(), f.config.TTL)
+	}
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+// Key returns the cache key of the fragment of given template name, data and dimensions.
+// It returns an error if the data cannot be JSON encoded.
+func (f *Fragments) Key(name string, data any, vary map[string]string) (string, error) {
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	_, _ = h.Write(encodedData)
+	dimensions := make([]string, 0, len(vary))
+	for dimension := range vary {
+		dimensions = append(dimensions, dimension)
+	}
+	sort.Strings(dimensions)
+	for _, dimension := range dimensions {
+		_, _ = h.Write([]byte{0})
+		_, _ = io.WriteString(h, dimension)
+		_, _ = h.Write([]byte{'='})
+		_, _ = io.WriteString(h, vary[dimension])
+	}
+
+	return f.config.KeyPrefix + name + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// gzip writes into dst the gzip compressed fragment.
+func (f *Fragments) gzip(dst *bytes.Buffer, fragment []byte) error {
+	zw, _ := f.gzipWriters.Get().(*gzip.Writer)
+	if zw == nil {
+		var err error
+		if zw, err = gzip.NewWriterLevel(dst, f.config.CompressionLevel); err != nil {
+			return err
+		}
+	} else {
+		zw.Reset(dst)
+	}
+	defer f.gzipWriters.Put(zw)
+
+	if _, err := zw.Write(fragment); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// gunzip writes into dst the decompressed fragment.
+func (f *Fragments) gunzip(dst *bytes.Buffer, compressed []byte) error {
+	var (
+		zr, _ = f.gzipReaders.Get().(*gzip.Reader)
+		err   error
+	)
+	if zr == nil {
+		if zr, err = gzip.NewReader(bytes.NewReader(compressed)); err != nil {
+			return err
+		}
+	} else if err = zr.Reset(bytes.NewReader(compressed)); err != nil {
+		return err
+	}
+	defer f.gzipReaders.Put(zr)
+
+	if _, err = dst.ReadFrom(zr); err != nil {
+		return err
+	}
+
+	return zr.Close()
+}
@@ -0,0 +1,186 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestFragments(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fragment is rendered once and cached compressed", testFragmentsFragmentIsRenderedOnceAndCachedCompressed)
+	t.Run("keys vary by data and dimensions", testFragmentsKeysVaryByDataAndDimensions)
+	t.Run("render error is returned", testFragmentsRenderErrIsReturned)
+	t.Run("not encodable data is not cached", testFragmentsNotEncodableDataIsNotCached)
+}
+
+func testFragmentsFragmentIsRenderedOnceAndCachedCompressed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewFragments(cache, xcache.FragmentsConfig{
+			KeyPrefix: "test-fragment-",
+			TTL:       time.Minute,
+		})
+		ctx       = context.Background()
+		tmpl      = template.Must(template.New("greeting").Parse("<p>Hello {{.Name}}</p>"))
+		data      = map[string]string{"Name": "John"}
+		vary      = map[string]string{"lang": "en"}
+		renderCnt int
+		render    = func(w io.Writer) error {
+			renderCnt++
+
+			return tmpl.Execute(w, data)
+		}
+		expected = "<p>Hello John</p>"
+	)
+
+	// act
+	var out1, out2 bytes.Buffer
+	err1 := subject.Render(ctx, &out1, "greeting", data, vary, render)
+	err2 := subject.Render(ctx, &out2, "greeting", data, vary, render)
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, expected, out1.String())
+	assertEqual(t, expected, out2.String())
+	assertEqual(t, 1, renderCnt)
+	key, err := subject.Key("greeting", data, vary)
+	requireNil(t, err)
+	assertTrue(t, strings.HasPrefix(key, "test-fragment-greeting:"))
+	compressed, err := cache.Load(ctx, key)
+	requireNil(t, err)
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	requireNil(t, err)
+	decompressed, err := io.ReadAll(zr)
+	assertNil(t, err)
+	assertEqual(t, expected, string(decompressed))
+}
+
+func testFragmentsKeysVaryByDataAndDimensions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewFragments(new(xcache.Nop), xcache.FragmentsConfig{})
+	data := map[string]any{"id": 1}
+
+	// act
+	key1, _ := subject.Key("tmpl", data, map[string]string{"lang": "en", "device": "mobile"})
+	key2, _ := subject.Key("tmpl", data, map[string]string{"device": "mobile", "lang": "en"})
+	key3, _ := subject.Key("tmpl", data, map[string]string{"lang": "fr", "device": "mobile"})
+	key4, _ := subject.Key("tmpl", map[string]any{"id": 2}, map[string]string{"lang": "en", "device": "mobile"})
+	key5, _ := subject.Key("other", data, map[string]string{"lang": "en", "device": "mobile"})
+
+	// assert
+	assertEqual(t, key1, key2)
+	assertTrue(t, key1 != key3)
+	assertTrue(t, key1 != key4)
+	assertTrue(t, key1 != key5)
+}
+
+func testFragmentsRenderErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewFragments(cache, xcache.FragmentsConfig{})
+		expectedErr = errors.New("intentionally triggered render error")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, xcache.ErrNotFound
+	})
+
+	// act
+	err := subject.Render(context.Background(), io.Discard, "tmpl", nil, nil, func(io.Writer) error {
+		return expectedErr
+	})
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, 0, cache.SaveCallsCount())
+}
+
+func testFragmentsNotEncodableDataIsNotCached(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewFragments(cache, xcache.FragmentsConfig{})
+		out     bytes.Buffer
+	)
+
+	// act
+	err := subject.Render(context.Background(), &out, "tmpl", func() {}, nil, func(w io.Writer) error {
+		_, err := io.WriteString(w, "rendered")
+
+		return err
+	})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, "rendered", out.String())
+	assertEqual(t, 0, cache.LoadCallsCount())
+	assertEqual(t, 0, cache.SaveCallsCount())
+}
+
+func BenchmarkFragments_Render(b *testing.B) {
+	subject := xcache.NewFragments(xcache.NewMemory(1024*1024), xcache.FragmentsConfig{TTL: time.Minute})
+	ctx := context.Background()
+	fragment := strings.Repeat("<p>Hello fragment</p>", 100)
+	render := func(w io.Writer) error {
+		_, err := io.WriteString(w, fragment)
+
+		return err
+	}
+	if err := subject.Render(ctx, io.Discard, "tmpl", 1, nil, render); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if err := subject.Render(ctx, io.Discard, "tmpl", 1, nil, render); err != nil {
+			b.Error(err)
+		}
+	}
+}
+
+func BenchmarkFragments_Render_notCached(b *testing.B) {
+	subject := xcache.NewFragments(xcache.Nop{}, xcache.FragmentsConfig{})
+	ctx := context.Background()
+	fragment := strings.Repeat("<p>Hello fragment</p>", 100)
+	render := func(w io.Writer) error {
+		_, err := io.WriteString(w, fragment)
+
+		return err
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if err := subject.Render(ctx, io.Discard, "tmpl", 1, nil, render); err != nil {
+			b.Error(err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// GCSExpiresAtMetadata is the (custom) object metadata key the expiration moment is stored under,
+// in unix milliseconds. An object without it does not expire.
+const GCSExpiresAtMetadata = "xcache-expires-at"
+
+// GCSObject is an object, as stored into a Google Cloud Storage bucket.
+type GCSObject struct {
+	// Body is the object's content (the value). It's not set by GCSClient's Attrs.
+	Body []byte
+	// Metadata is the object's custom metadata.
+	Metadata map[string]string
+	// Generation is the object's generation (version), set by GCSClient's Read / Attrs.
+	Generation int64
+}
+
+// GCSCondition is the precondition of a write / delete of an object.
+// The zero value means no precondition.
+type GCSCondition struct {
+	// DoesNotExist conditions the operation on the object not existing (ifGenerationMatch=0).
+	DoesNotExist bool
+	// GenerationMatch, if not 0, conditions the operation on the object's generation (ifGenerationMatch).
+	GenerationMatch int64
+}
+
+// GCSClient is the subset of Google Cloud Storage operations a GCS cache is built upon.
+// When a condition is not met, ErrConditionFailed is returned.
+//
+// A cloud.google.com/go/storage based implementation is along these lines:
+//
+//	type gcsClient struct{ gcs *storage.Client }
+//
+//	func (c gcsClient) Read(ctx context.Context, bucket, key string) (xcache.GCSObject, error) {
+//		obj, err := c.Attrs(ctx, bucket, key)
+//		if err != nil {
+//			return obj, err
+//		}
+//		r, err := c.gcs.Bucket(bucket).Object(key).Generation(obj.Generation).NewReader(ctx)
+//		if errors.Is(err, storage.ErrObjectNotExist) {
+//			return xcache.GCSObject{}, xcache.ErrNotFound
+//		} else if err != nil {
+//			return xcache.GCSObject{}, err
+//		}
+//		defer r.Close()
+//		obj.Body, err = io.ReadAll(r)
+//
+//		return obj, err
+//	}
+//
+//	func (c gcsClient) Write(
+//		ctx context.Context,
+//		bucket, key string,
+//		obj xcache.GCSObject,
+//		cond xcache.GCSCondition,
+//	) error {
+//		handle := c.gcs.Bucket(bucket).Object(key)
+//		if cond.DoesNotExist {
+//			handle = handle.If(storage.Conditions{DoesNotExist: true})
+//		} else if cond.GenerationMatch != 0 {
+//			handle = handle.If(storage.Conditions{GenerationMatch: cond.GenerationMatch})
+//		}
+//		w := handle.NewWriter(ctx)
+//		w.Metadata = obj.Metadata
+//		if _, err := w.Write(obj.Body); err != nil {
+//			_ = w.Close()
+//
+//			return err
+//		}
+//		err := w.Close()
+//		var apiErr *googleapi.Error
+//		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+//			return xcache.ErrConditionFailed
+//		}
+//
+//		return err
+//	}
+//
+// Attrs and Delete are similar (ObjectHandle's Attrs / Delete, storage.ErrObjectNotExist being
+// mapped to ErrNotFound, respectively ignored).
+type GCSClient interface {
+	// Read returns the object with given key, or ErrNotFound, if it does not exist.
+	Read(ctx context.Context, bucket, key string) (GCSObject, error)
+	// Attrs returns the object with given key, without its body, or ErrNotFound, if it does not exist.
+	Attrs(ctx context.Context, bucket, key string) (GCSObject, error)
+	// Write stores (overwrites) the object with given key, if given condition is met.
+	Write(ctx context.Context, bucket, key string, obj GCSObject, cond GCSCondition) error
+	// Delete deletes the object with given key (not existing is not an error), if given condition is met.
+	Delete(ctx context.Context, bucket, key string, cond GCSCondition) error
+}
+
+// GCSConfig holds the settings of a GCS cache.
+type GCSConfig struct {
+	// Bucket is the bucket the objects are stored into.
+	Bucket string
+	// Prefix is prepended to each key, isolating the cache's objects from other bucket data.
+	// By default (""), it's "xcache/".
+	Prefix string
+}
+
+// GCS is a Cache backed by a Google Cloud Storage bucket, the GCP counterpart of S3: for very large,
+// infrequently changing values, meant to be the last layer of a Multi cache.
+// A key's expiration moment is stored in the object's metadata (see GCSExpiresAtMetadata); expired objects
+// are never returned, and they are deleted lazily, when they are loaded (conditioned on their generation,
+// so that an object re-written meanwhile is not deleted).
+// Expired objects which are never loaded again are not deleted, configure a lifecycle rule
+// on the bucket's prefix (with an age greater than your longest TTL) to reclaim them.
+//
+// Example:
+//
+//	client, err := storage.NewClient(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	blobCache := xcache.NewGCS(gcsClient{client}, xcache.GCSConfig{Bucket: "app-cache"})
+//	cache := xcache.NewMulti(memCache, redisCache, blobCache)
+type GCS struct {
+	client GCSClient
+	bucket string
+	prefix string
+	hits   int64
+	misses int64
+}
+
+// NewGCS instantiates a new GCS cache upon given client, according to given settings.
+func NewGCS(client GCSClient, config GCSConfig) *GCS {
+	if config.Prefix == "" {
+		config.Prefix = "xcache/"
+	}
+
+	return &GCS{
+		client: client,
+		bucket: config.Bucket,
+		prefix: config.Prefix,
+	}
+}
+
+// Save stores (overwrites) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *GCS) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	return cache.client.Write(ctx, cache.bucket, cache.prefix+key, gcsObject(value, expire), GCSCondition{})
+}
+
+// SaveIfAbsent stores the given key-value with expiration period, only if the key does not exist
+// (or it's expired), through conditional writes.
+// It returns true if the key was saved, or an error if something bad happened.
+func (cache *GCS) SaveIfAbsent(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) (bool, error) {
+	obj := gcsObject(value, expire)
+	err := cache.client.Write(ctx, cache.bucket, cache.prefix+key, obj, GCSCondition{DoesNotExist: true})
+	if !errors.Is(err, ErrConditionFailed) {
+		return err == nil, err
+	}
+
+	// the object exists, overwrite it if it's expired (and it was not re-written meanwhile).
+	attrs, err := cache.client.Attrs(ctx, cache.bucket, cache.prefix+key)
+	if errors.Is(err, ErrNotFound) { // deleted meanwhile.
+		attrs.Generation = 0
+	} else if err != nil {
+		return false, err
+	} else if !isBlobExpired(attrs.Metadata, GCSExpiresAtMetadata) {
+		return false, nil
+	}
+	cond := GCSCondition{GenerationMatch: attrs.Generation, DoesNotExist: attrs.Generation == 0}
+	err = cache.client.Write(ctx, cache.bucket, cache.prefix+key, obj, cond)
+	if errors.Is(err, ErrConditionFailed) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+// An expired object is deleted.
+func (cache *GCS) Load(ctx context.Context, key string) ([]byte, error) {
+	obj, err := cache.client.Read(ctx, cache.bucket, cache.prefix+key)
+	if err == nil && isBlobExpired(obj.Metadata, GCSExpiresAtMetadata) {
+		// lazy deletion, best effort.
+		_ = cache.client.Delete(ctx, cache.bucket, cache.prefix+key, GCSCondition{GenerationMatch: obj.Generation})
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return obj.Body, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the read fails.
+// If the key is not found (or it's expired), a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *GCS) TTL(ctx context.Context, key string) (time.Duration, error) {
+	attrs, err := cache.client.Attrs(ctx, cache.bucket, cache.prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	return blobTTL(attrs.Metadata, GCSExpiresAtMetadata), nil
+}
+
+// Stats returns the hits and misses since the cache was instantiated.
+// The no. of keys and memory are not reported, as listing a bucket is not a cheap operation
+// (use the bucket's monitoring metrics instead).
+func (cache *GCS) Stats(_ context.Context) (Stats, error) {
+	return Stats{
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *GCS) Delete(ctx context.Context, key string) error {
+	return cache.client.Delete(ctx, cache.bucket, cache.prefix+key, GCSCondition{})
+}
+
+// gcsObject returns the object storing given value with expiration period.
+func gcsObject(value []byte, expire time.Duration) GCSObject {
+	obj := GCSObject{Body: value, Metadata: map[string]string{}}
+	if expire > 0 {
+		expiresAt := time.Now().Add(expire).UnixMilli()
+		obj.Metadata[GCSExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+	}
+	if obj.Body == nil {
+		obj.Body = []byte{}
+	}
+
+	return obj
+}
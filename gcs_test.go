@@ -0,0 +1,281 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.GCS)(nil)    // test GCS is a Cache
+	var _ xcache.Deleter = (*xcache.GCS)(nil)  // test GCS is a Deleter
+	var _ xcache.GCSClient = (*gcsBucket)(nil) // test gcsBucket is a GCSClient
+}
+
+func TestGCS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testGCSSaveLoadTTLDelete)
+	t.Run("expired objects are deleted lazily", testGCSExpiredObjectsAreDeletedLazily)
+	t.Run("save if absent", testGCSSaveIfAbsent)
+	t.Run("client errors are returned", testGCSClientErrorsAreReturned)
+}
+
+func testGCSSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newGCSBucket()
+		subject = xcache.NewGCS(bucket, xcache.GCSConfig{Bucket: "test-bucket", Prefix: "blobs/"})
+		ctx     = context.Background()
+		key     = "test-gcs-key"
+		value   = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	obj := bucket.Object("test-bucket/blobs/" + key)
+	assertEqual(t, value, obj.Body)
+	assertTrue(t, obj.Metadata[xcache.GCSExpiresAtMetadata] != "")
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	requireNil(t, subject.Delete(ctx, key))
+	assertEqual(t, 0, bucket.Len())
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+}
+
+func testGCSExpiredObjectsAreDeletedLazily(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newGCSBucket()
+		subject = xcache.NewGCS(bucket, xcache.GCSConfig{Bucket: "test-bucket"})
+		ctx     = context.Background()
+		key     = "test-gcs-expired-key"
+		path    = "test-bucket/xcache/" + key
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), time.Minute))
+	bucket.Expire(path)
+
+	// act
+	ttl, errTTL := subject.TTL(ctx, key)
+	lenBeforeLoad := bucket.Len()
+	_, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+	assertEqual(t, 1, lenBeforeLoad)
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertEqual(t, 0, bucket.Len())
+}
+
+func testGCSSaveIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newGCSBucket()
+		subject = xcache.NewGCS(bucket, xcache.GCSConfig{Bucket: "test-bucket"})
+		ctx     = context.Background()
+		key     = "test-gcs-absent-key"
+		path    = "test-bucket/xcache/" + key
+	)
+
+	// act & assert
+	saved, err := subject.SaveIfAbsent(ctx, key, []byte("value 1"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 2"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	assertEqual(t, []byte("value 1"), bucket.Object(path).Body)
+
+	bucket.Expire(path)
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 3"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, saved)
+	assertEqual(t, []byte("value 3"), bucket.Object(path).Body)
+
+	bucket.Expire(path)
+	bucket.beforeWrite = func() { // another writer wins the race.
+		bucket.beforeWrite = nil
+		requireNil(t, subject.Save(ctx, key, []byte("value 4"), time.Minute))
+	}
+	saved, err = subject.SaveIfAbsent(ctx, key, []byte("value 5"), time.Minute)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	assertEqual(t, []byte("value 4"), bucket.Object(path).Body)
+}
+
+func testGCSClientErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newGCSBucket()
+		subject = xcache.NewGCS(bucket, xcache.GCSConfig{Bucket: "test-bucket"})
+		ctx     = context.Background()
+		key     = "test-gcs-error-key"
+		errGCS  = errors.New("rate limit exceeded")
+	)
+	bucket.err = errGCS
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errSaveIfAbsent := subject.SaveIfAbsent(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	errDelete := subject.Delete(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errSave, errGCS))
+	assertTrue(t, errors.Is(errSaveIfAbsent, errGCS))
+	assertTrue(t, errors.Is(errLoad, errGCS))
+	assertTrue(t, errors.Is(errTTL, errGCS))
+	assertTrue(t, errors.Is(errDelete, errGCS))
+}
+
+// gcsBucket is an in memory xcache.GCSClient, objects being stored by "bucket/key",
+// with a generation incremented at each write.
+// Note: it's not safe for concurrent use, when beforeWrite is set.
+type gcsBucket struct {
+	objects     map[string]xcache.GCSObject
+	generation  int64
+	beforeWrite func() // called (once) before a conditional write, if set.
+	err         error
+	mu          sync.Mutex
+}
+
+func newGCSBucket() *gcsBucket {
+	return &gcsBucket{objects: make(map[string]xcache.GCSObject)}
+}
+
+func (b *gcsBucket) Read(_ context.Context, bucket, key string) (xcache.GCSObject, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return xcache.GCSObject{}, b.err
+	}
+	obj, found := b.objects[bucket+"/"+key]
+	if !found {
+		return xcache.GCSObject{}, xcache.ErrNotFound
+	}
+
+	return obj, nil
+}
+
+func (b *gcsBucket) Attrs(ctx context.Context, bucket, key string) (xcache.GCSObject, error) {
+	obj, err := b.Read(ctx, bucket, key)
+	obj.Body = nil
+
+	return obj, err
+}
+
+func (b *gcsBucket) Write(
+	_ context.Context,
+	bucket, key string,
+	obj xcache.GCSObject,
+	cond xcache.GCSCondition,
+) error {
+	if b.beforeWrite != nil && cond != (xcache.GCSCondition{}) {
+		b.beforeWrite()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	if !b.isMet(bucket+"/"+key, cond) {
+		return xcache.ErrConditionFailed
+	}
+	b.generation++
+	obj.Generation = b.generation
+	b.objects[bucket+"/"+key] = obj
+
+	return nil
+}
+
+func (b *gcsBucket) Delete(_ context.Context, bucket, key string, cond xcache.GCSCondition) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	if !b.isMet(bucket+"/"+key, cond) {
+		return xcache.ErrConditionFailed
+	}
+	delete(b.objects, bucket+"/"+key)
+
+	return nil
+}
+
+// isMet returns whether given condition is met by the object with given path.
+func (b *gcsBucket) isMet(path string, cond xcache.GCSCondition) bool {
+	obj, found := b.objects[path]
+	if cond.DoesNotExist {
+		return !found
+	}
+
+	return cond.GenerationMatch == 0 || (found && obj.Generation == cond.GenerationMatch)
+}
+
+func (b *gcsBucket) Object(path string) xcache.GCSObject {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.objects[path]
+}
+
+// Expire makes the object with given path expired.
+func (b *gcsBucket) Expire(path string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expiresAt := time.Now().Add(-time.Second).UnixMilli()
+	b.objects[path].Metadata[xcache.GCSExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+}
+
+func (b *gcsBucket) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.objects)
+}
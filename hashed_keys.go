@@ -0,0 +1,165 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+const (
+	// hashedKeySuffixLen is the length of the suffix of a hashed key: a separator and the base64 encoded SHA-256 hash.
+	hashedKeySuffixLen = 1 + 43
+	// hashedKeysMinMaxLen is the min. value of HashedKeys' max. key length.
+	hashedKeysMinMaxLen = 64
+	// HashedKeysDefaultMaxLen is the default max. length of a key, not hashed, of a HashedKeys cache.
+	HashedKeysDefaultMaxLen = 250
+)
+
+// HashedKeys is a Cache decorator which transparently hashes the keys longer than a max. length,
+// so that callers can safely use long strings (like URLs or queries) as keys:
+// Memory cache rejects keys longer than 65535 bytes, and long Redis keys waste memory.
+// Shorter keys are passed through.
+//
+// A long key is replaced with its first bytes, followed by "#" and the base64 (URL encoding, no padding)
+// encoded SHA-256 hash of the whole key, in total having the max. length, so the keys' prefixes are kept
+// (keys can still be deleted / scanned by prefix, for example).
+//
+// Example:
+//
+//	cache := xcache.NewHashedKeys(redisCache, 0)
+//	err := cache.Save(ctx, requestURL, response, time.Minute)
+type HashedKeys struct {
+	cache  Cache
+	maxLen int
+}
+
+// NewHashedKeys instantiates a new HashedKeys which decorates given cache,
+// hashing keys longer than given max. length (in bytes).
+// A non-positive max. length means HashedKeysDefaultMaxLen. A max. length lower than 64 is raised to 64.
+func NewHashedKeys(cache Cache, maxLen int) *HashedKeys {
+	if maxLen <= 0 {
+		maxLen = HashedKeysDefaultMaxLen
+	} else if maxLen < hashedKeysMinMaxLen {
+		maxLen = hashedKeysMinMaxLen
+	}
+
+	return &HashedKeys{
+		cache:  cache,
+		maxLen: maxLen,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// A negative expiration period triggers deletion of key.
+func (cache *HashedKeys) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.cache.Save(ctx, cache.Key(key), value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+func (cache *HashedKeys) Load(ctx context.Context, key string) ([]byte, error) {
+	return cache.cache.Load(ctx, cache.Key(key))
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *HashedKeys) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, cache.Key(key))
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *HashedKeys) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Delete deletes the given key from decorated cache.
+func (cache *HashedKeys) Delete(ctx context.Context, key string) error {
+	return deleteKey(ctx, cache.cache, cache.Key(key))
+}
+
+// DeleteMany deletes the given keys from decorated cache.
+func (cache *HashedKeys) DeleteMany(ctx context.Context, keys ...string) error {
+	hashedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		hashedKeys[i] = cache.Key(key)
+	}
+
+	return deleteKeys(ctx, cache.cache, hashedKeys...)
+}
+
+// Has checks if the given key exists in decorated cache.
+func (cache *HashedKeys) Has(ctx context.Context, key string) (bool, error) {
+	return hasKey(ctx, cache.cache, cache.Key(key))
+}
+
+// SaveMany stores the given items into decorated cache.
+func (cache *HashedKeys) SaveMany(ctx context.Context, items map[string]Item) error {
+	hashedItems := make(map[string]Item, len(items))
+	for key, item := range items {
+		hashedItems[cache.Key(key)] = item
+	}
+
+	return saveMany(ctx, cache.cache, hashedItems)
+}
+
+// LoadMany returns the values of given keys from decorated cache (keys not found are missing from the returned map).
+func (cache *HashedKeys) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	var (
+		hashedKeys = make([]string, len(keys))
+		origKeys   map[string]string // hashed key => original key, for the hashed ones.
+	)
+	for i, key := range keys {
+		hashedKeys[i] = cache.Key(key)
+		if hashedKeys[i] != key {
+			if origKeys == nil {
+				origKeys = make(map[string]string)
+			}
+			origKeys[hashedKeys[i]] = key
+		}
+	}
+	hashedValues, err := loadMany(ctx, cache.cache, hashedKeys)
+	if origKeys == nil {
+		return hashedValues, err
+	}
+	values := make(map[string][]byte, len(hashedValues))
+	for hashedKey, value := range hashedValues {
+		if key, hashed := origKeys[hashedKey]; hashed {
+			values[key] = value
+		} else {
+			values[hashedKey] = value
+		}
+	}
+
+	return values, err
+}
+
+// Unwrap returns the decorated cache.
+func (cache *HashedKeys) Unwrap() Cache {
+	return cache.cache
+}
+
+// Key returns the key given key is stored under in decorated cache: the key itself,
+// if it's not longer than the max. length, otherwise its hashed form.
+func (cache *HashedKeys) Key(key string) string {
+	if len(key) <= cache.maxLen {
+		return key
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	prefixLen := cache.maxLen - hashedKeySuffixLen
+	hashedKey := make([]byte, cache.maxLen)
+	copy(hashedKey, key[:prefixLen])
+	hashedKey[prefixLen] = '#'
+	base64.RawURLEncoding.Encode(hashedKey[prefixLen+1:], hash[:])
+
+	return bytesToString(hashedKey)
+}
@@ -0,0 +1,128 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.HashedKeys)(nil)            // test HashedKeys is a Cache
+	var _ xcache.Deleter = (*xcache.HashedKeys)(nil)          // test HashedKeys is a Deleter
+	var _ xcache.BulkDeleter = (*xcache.HashedKeys)(nil)      // test HashedKeys is a BulkDeleter
+	var _ xcache.ExistenceChecker = (*xcache.HashedKeys)(nil) // test HashedKeys is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.HashedKeys)(nil)          // test HashedKeys is a Batcher
+}
+
+func TestHashedKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("long key is hashed", testHashedKeysLongKeyIsHashed)
+	t.Run("short key is passed through", testHashedKeysShortKeyIsPassedThrough)
+	t.Run("batch operations", testHashedKeysBatchOperations)
+}
+
+func testHashedKeysLongKeyIsHashed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = xcache.NewMemory(freecacheMinMem)
+		subject  = xcache.NewHashedKeys(cache, 100)
+		ctx      = context.Background()
+		key      = "https://example.com/search?q=" + strings.Repeat("x", 70000)
+		otherKey = key[:len(key)-1] + "y" // same prefix, different hash.
+		value    = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+	_, errLoadOther := subject.Load(ctx, otherKey)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertNil(t, errTTL)
+	assertTrue(t, ttl > 0)
+	assertTrue(t, errors.Is(errLoadOther, xcache.ErrNotFound))
+	hashedKey := subject.Key(key)
+	assertEqual(t, 100, len(hashedKey))
+	assertTrue(t, strings.HasPrefix(hashedKey, key[:56]+"#"))
+	result, err := cache.Load(ctx, hashedKey)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertTrue(t, subject.Unwrap() == xcache.Cache(cache))
+
+	// act & assert deletion
+	requireNil(t, subject.Delete(ctx, key))
+	has, err := subject.Has(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, !has)
+}
+
+func testHashedKeysShortKeyIsPassedThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewHashedKeys(cache, 0)
+		ctx     = context.Background()
+		key     = strings.Repeat("k", xcache.HashedKeysDefaultMaxLen)
+		value   = []byte("test value")
+	)
+
+	// act
+	err := subject.Save(ctx, key, value, time.Minute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, key, subject.Key(key))
+	result, err := cache.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertEqual(t, 64, len(xcache.NewHashedKeys(cache, 10).Key(key))) // max. length is raised to 64.
+}
+
+func testHashedKeysBatchOperations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewHashedKeys(cache, 64)
+		ctx     = context.Background()
+		longKey = strings.Repeat("k", 100)
+		items   = map[string]xcache.Item{
+			"short": {Value: []byte("test value 1"), Expire: time.Minute},
+			longKey: {Value: []byte("test value 2"), Expire: time.Minute},
+		}
+	)
+
+	// act
+	errSave := subject.SaveMany(ctx, items)
+	result, errLoad := subject.LoadMany(ctx, []string{"short", longKey, "missing"})
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, map[string][]byte{"short": []byte("test value 1"), longKey: []byte("test value 2")}, result)
+
+	// act & assert bulk deletion
+	requireNil(t, subject.DeleteMany(ctx, "short", longKey))
+	result, err := subject.LoadMany(ctx, []string{"short", longKey})
+	assertNil(t, err)
+	assertEqual(t, 0, len(result))
+}
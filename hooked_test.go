@@ -0,0 +1,114 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Hooked)(nil) // test Hooked is a Cache
+}
+
+func TestHooked(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hooks are called", testHookedHooksAreCalled)
+	t.Run("error hook is called", testHookedErrorHookIsCalled)
+}
+
+func testHookedHooksAreCalled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		events []xcache.HookEvent
+		record = func(_ context.Context, event xcache.HookEvent) {
+			event.Duration = 0 // not deterministic.
+			events = append(events, event)
+		}
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewHooked(cache, xcache.Hooks{
+			OnHit:    record,
+			OnMiss:   record,
+			OnSave:   record,
+			OnDelete: record,
+			OnError:  record,
+		})
+		ctx   = context.Background()
+		key   = "test-hooked-key"
+		value = []byte("test value")
+	)
+
+	// act
+	_ = subject.Save(ctx, key, value, time.Minute)
+	_, _ = subject.Load(ctx, key)
+	_ = subject.Save(ctx, key, nil, -1)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.TTL(ctx, key)
+
+	// assert
+	assertEqual(
+		t,
+		[]xcache.HookEvent{
+			{Operation: xcache.HookOpSave, Key: key, Size: len(value)},
+			{Operation: xcache.HookOpLoad, Key: key, Size: len(value)},
+			{Operation: xcache.HookOpDelete, Key: key},
+			{Operation: xcache.HookOpLoad, Key: key},
+		},
+		events,
+	)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testHookedErrorHookIsCalled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		errOp    = errors.New("connection reset by peer")
+		hits     int
+		failures []string
+		cache    = new(xcache.Mock)
+		subject  = xcache.NewHooked(cache, xcache.Hooks{
+			OnHit: func(context.Context, xcache.HookEvent) { hits++ },
+			OnError: func(_ context.Context, event xcache.HookEvent) {
+				assertTrue(t, errors.Is(event.Err, errOp))
+				failures = append(failures, event.Operation)
+			},
+		})
+		ctx = context.Background()
+		key = "test-hooked-err-key"
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return errOp
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errOp
+	})
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return -1, errOp
+	})
+
+	// act
+	_ = subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_ = subject.Save(ctx, key, nil, -1)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.TTL(ctx, key)
+
+	// assert
+	assertEqual(
+		t,
+		[]string{xcache.HookOpSave, xcache.HookOpDelete, xcache.HookOpLoad, xcache.HookOpTTL},
+		failures,
+	)
+	assertEqual(t, 0, hits)
+}
@@ -0,0 +1,250 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// InvalidationKind identifies what an Invalidation targets.
+type InvalidationKind uint8
+
+// Invalidation kinds.
+const (
+	// InvalidationKey deletes the key equal to Invalidation's target.
+	InvalidationKey InvalidationKind = iota
+	// InvalidationTag invalidates the tag named as Invalidation's target, see Tags.
+	InvalidationTag
+	// InvalidationPrefix deletes the keys starting with Invalidation's target, see PrefixDeleter.
+	InvalidationPrefix
+)
+
+// Invalidation is a cache invalidation command (produced, for example, from database change events).
+type Invalidation struct {
+	// Kind identifies what Target is (a key, a tag, a prefix).
+	Kind InvalidationKind
+	// Target is the key / tag / prefix to invalidate.
+	Target string
+}
+
+// ErrInvalidInvalidation is returned when an Invalidation cannot be unmarshaled from text.
+var ErrInvalidInvalidation = errors.New("invalid invalidation")
+
+// invalidationKindNames holds the text representations of invalidation kinds.
+var invalidationKindNames = [...]string{
+	InvalidationKey:    "key",
+	InvalidationTag:    "tag",
+	InvalidationPrefix: "prefix",
+}
+
+// MarshalText encodes the invalidation as "<kind>:<target>" (example: "tag:users"),
+// so it can be sent through text based transports (Postgres notifications, Kafka messages, etc.).
+// It implements [encoding.TextMarshaler].
+func (invalidation Invalidation) MarshalText() ([]byte, error) {
+	if int(invalidation.Kind) >= len(invalidationKindNames) {
+		return nil, ErrInvalidInvalidation
+	}
+	kind := invalidationKindNames[invalidation.Kind]
+	text := make([]byte, 0, len(kind)+1+len(invalidation.Target))
+	text = append(text, kind...)
+	text = append(text, ':')
+
+	return append(text, invalidation.Target...), nil
+}
+
+// UnmarshalText decodes an invalidation encoded with MarshalText.
+// It returns ErrInvalidInvalidation if the text is not a valid invalidation.
+// It implements [encoding.TextUnmarshaler].
+func (invalidation *Invalidation) UnmarshalText(text []byte) error {
+	kind, target, found := strings.Cut(string(text), ":")
+	if !found {
+		return ErrInvalidInvalidation
+	}
+	for k, name := range invalidationKindNames {
+		if name == kind {
+			invalidation.Kind = InvalidationKind(k)
+			invalidation.Target = target
+
+			return nil
+		}
+	}
+
+	return ErrInvalidInvalidation
+}
+
+// Invalidator defaults.
+const (
+	invalidatorDefaultBatchSize     = 100
+	invalidatorDefaultFlushInterval = 100 * time.Millisecond
+	invalidatorDefaultMaxRetries    = 3
+	invalidatorDefaultRetryDelay    = 50 * time.Millisecond
+)
+
+// InvalidatorConfig holds the settings of an Invalidator.
+type InvalidatorConfig struct {
+	// Tags is used to apply tag invalidations.
+	// If not set, tag invalidations fail with [errors.ErrUnsupported].
+	Tags *Tags
+	// BatchSize is the max. no. of invalidations applied together. By default (0), it's 100.
+	BatchSize int
+	// FlushInterval is the max. period an invalidation waits for its batch to fill up. By default (0), it's 100ms.
+	FlushInterval time.Duration
+	// MaxRetries is the max. no. of times a failed invalidation is retried. By default (0), it's 3.
+	// A negative value disables retries.
+	MaxRetries int
+	// RetryDelay is the period waited before the first retry, doubled for each subsequent one.
+	// By default (0), it's 50ms.
+	RetryDelay time.Duration
+	// OnError is called with an invalidation which could not be applied (after retries), and the last error.
+	// It can be used to log the failure / send the invalidation to a dead letter queue.
+	OnError func(invalidation Invalidation, err error)
+}
+
+// Invalidator applies streams of invalidation commands to a cache, in batches, retrying the failed ones.
+// It's meant to be the sink of database-driven cache invalidation pipelines (CDC, Kafka consumers, etc.).
+//
+// Example:
+//
+//	invalidator := xcache.NewInvalidator(cache, xcache.InvalidatorConfig{Tags: tags})
+//	go func() {
+//		_ = invalidator.Apply(ctx, invalidations) // invalidations are sent by a Kafka consumer.
+//	}()
+type Invalidator struct {
+	cache  Cache
+	config InvalidatorConfig
+}
+
+// NewInvalidator instantiates a new Invalidator which applies invalidations to given cache, with given settings.
+func NewInvalidator(cache Cache, config InvalidatorConfig) *Invalidator {
+	if config.BatchSize <= 0 {
+		config.BatchSize = invalidatorDefaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = invalidatorDefaultFlushInterval
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = invalidatorDefaultMaxRetries
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = invalidatorDefaultRetryDelay
+	}
+
+	return &Invalidator{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// ApplyInvalidations applies the invalidations received on given channel to given cache,
+// with default Invalidator settings (key and prefix invalidations are supported), see Invalidator.Apply.
+func ApplyInvalidations(ctx context.Context, cache Cache, invalidations <-chan Invalidation) error {
+	return NewInvalidator(cache, InvalidatorConfig{}).Apply(ctx, invalidations)
+}
+
+// Apply consumes the invalidations received on given channel, and applies them in batches:
+// a batch is applied when it's full, or when the flush interval passed since its first invalidation was received.
+// Duplicate invalidations within a batch are applied once.
+// Failed invalidations are retried, and, if they still fail, reported to OnError callback;
+// processing continues with the next invalidations.
+//
+// It blocks until the channel is closed (and the pending invalidations are applied), returning nil,
+// or until the context is done, returning its error.
+func (inv *Invalidator) Apply(ctx context.Context, invalidations <-chan Invalidation) error {
+	var (
+		batch   = make([]Invalidation, 0, inv.config.BatchSize)
+		flushCh <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case invalidation, ok := <-invalidations:
+			if !ok {
+				inv.applyBatch(ctx, batch)
+
+				return ctx.Err()
+			}
+			batch = append(batch, invalidation)
+			if len(batch) == 1 {
+				flushCh = time.After(inv.config.FlushInterval)
+			}
+			if len(batch) >= inv.config.BatchSize {
+				inv.applyBatch(ctx, batch)
+				batch = batch[:0]
+				flushCh = nil
+			}
+		case <-flushCh:
+			inv.applyBatch(ctx, batch)
+			batch = batch[:0]
+			flushCh = nil
+		}
+	}
+}
+
+// applyBatch applies given invalidations, once per distinct invalidation.
+func (inv *Invalidator) applyBatch(ctx context.Context, batch []Invalidation) {
+	applied := make(map[Invalidation]struct{}, len(batch))
+	for _, invalidation := range batch {
+		if _, found := applied[invalidation]; found {
+			continue
+		}
+		applied[invalidation] = struct{}{}
+
+		if err := inv.applyWithRetry(ctx, invalidation); err != nil && inv.config.OnError != nil {
+			inv.config.OnError(invalidation, err)
+		}
+	}
+}
+
+// applyWithRetry applies given invalidation, retrying it (with exponential backoff) if it fails.
+func (inv *Invalidator) applyWithRetry(ctx context.Context, invalidation Invalidation) error {
+	delay := inv.config.RetryDelay
+	err := inv.apply(ctx, invalidation)
+	for retry := 0; err != nil && retry < inv.config.MaxRetries; retry++ {
+		if errors.Is(err, errors.ErrUnsupported) {
+			break
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		err = inv.apply(ctx, invalidation)
+	}
+
+	return err
+}
+
+// apply applies given invalidation.
+func (inv *Invalidator) apply(ctx context.Context, invalidation Invalidation) error {
+	switch invalidation.Kind {
+	case InvalidationKey:
+		return deleteKey(ctx, inv.cache, invalidation.Target)
+	case InvalidationTag:
+		if inv.config.Tags == nil {
+			return errors.ErrUnsupported
+		}
+
+		return inv.config.Tags.Invalidate(ctx, invalidation.Target)
+	case InvalidationPrefix:
+		prefixDeleter, ok := inv.cache.(PrefixDeleter)
+		if !ok {
+			return errors.ErrUnsupported
+		}
+		_, err := prefixDeleter.DeletePrefix(ctx, invalidation.Target)
+
+		return err
+	default:
+		return errors.ErrUnsupported
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestInvalidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys, tags, prefixes are invalidated", testInvalidatorKeysTagsPrefixesAreInvalidated)
+	t.Run("duplicates within a batch are applied once", testInvalidatorDuplicatesWithinBatchAreAppliedOnce)
+	t.Run("batch is flushed after interval", testInvalidatorBatchIsFlushedAfterInterval)
+	t.Run("failed invalidation is retried", testInvalidatorFailedInvalidationIsRetried)
+	t.Run("failed invalidation is reported", testInvalidatorFailedInvalidationIsReported)
+	t.Run("done context", testInvalidatorWithDoneContext)
+}
+
+func testInvalidatorKeysTagsPrefixesAreInvalidated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache         = xcache.NewMemory(1)
+		tags          = xcache.NewTags(cache, "test-invalidator-tag:")
+		subject       = xcache.NewInvalidator(cache, xcache.InvalidatorConfig{Tags: tags})
+		ctx           = context.Background()
+		value         = []byte("test value")
+		invalidations = make(chan xcache.Invalidation, 3)
+	)
+	requireNil(t, cache.Save(ctx, "test-invalidator-key", value, time.Minute))
+	requireNil(t, cache.Save(ctx, "test-invalidator-prefix-1", value, time.Minute))
+	requireNil(t, cache.Save(ctx, "test-invalidator-prefix-2", value, time.Minute))
+	versions, err := tags.Versions(ctx, "users")
+	requireNil(t, err)
+	invalidations <- xcache.Invalidation{Kind: xcache.InvalidationKey, Target: "test-invalidator-key"}
+	invalidations <- xcache.Invalidation{Kind: xcache.InvalidationTag, Target: "users"}
+	invalidations <- xcache.Invalidation{Kind: xcache.InvalidationPrefix, Target: "test-invalidator-prefix-"}
+	close(invalidations)
+
+	// act
+	err = subject.Apply(ctx, invalidations)
+
+	// assert
+	assertNil(t, err)
+	for _, key := range []string{"test-invalidator-key", "test-invalidator-prefix-1", "test-invalidator-prefix-2"} {
+		_, err = cache.Load(ctx, key)
+		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	}
+	newVersions, err := tags.Versions(ctx, "users")
+	assertNil(t, err)
+	assertTrue(t, versions != newVersions)
+}
+
+func testInvalidatorDuplicatesWithinBatchAreAppliedOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache         = new(xcache.Mock)
+		subject       = xcache.NewInvalidator(cache, xcache.InvalidatorConfig{BatchSize: 3, FlushInterval: time.Hour})
+		invalidations = make(chan xcache.Invalidation, 6)
+	)
+	for i := 0; i < 6; i++ { // 2 batches, with one distinct key each.
+		invalidations <- xcache.Invalidation{Target: "test-invalidator-duplicate-key"}
+	}
+	close(invalidations)
+
+	// act
+	err := subject.Apply(context.Background(), invalidations)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, cache.DeleteCallsCount())
+}
+
+func testInvalidatorBatchIsFlushedAfterInterval(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewInvalidator(cache, xcache.InvalidatorConfig{
+			BatchSize:     10,
+			FlushInterval: 10 * time.Millisecond,
+		})
+		invalidations = make(chan xcache.Invalidation)
+		applied       = make(chan string, 1)
+		done          = make(chan error, 1)
+	)
+	cache.SetDeleteCallback(func(_ context.Context, key string) error {
+		applied <- key
+
+		return nil
+	})
+	go func() {
+		done <- subject.Apply(context.Background(), invalidations)
+	}()
+
+	// act
+	invalidations <- xcache.Invalidation{Target: "test-invalidator-flush-key"}
+
+	// assert
+	select {
+	case key := <-applied:
+		assertEqual(t, "test-invalidator-flush-key", key)
+	case <-time.After(time.Second):
+		t.Error("batch was not flushed")
+	}
+	close(invalidations)
+	assertNil(t, <-done)
+}
+
+func testInvalidatorFailedInvalidationIsRetried(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewInvalidator(cache, xcache.InvalidatorConfig{
+			RetryDelay: time.Millisecond,
+			OnError: func(xcache.Invalidation, error) {
+				t.Error("invalidation should have been applied")
+			},
+		})
+		invalidations = make(chan xcache.Invalidation, 1)
+	)
+	cache.SetDeleteCallback(func(context.Context, string) error {
+		if cache.DeleteCallsCount() < 3 {
+			return errors.New("intentionally triggered Delete error")
+		}
+
+		return nil
+	})
+	invalidations <- xcache.Invalidation{Target: "test-invalidator-retry-key"}
+	close(invalidations)
+
+	// act
+	err := subject.Apply(context.Background(), invalidations)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, cache.DeleteCallsCount())
+}
+
+func testInvalidatorFailedInvalidationIsReported(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = struct{ xcache.Cache }{new(xcache.Mock)} // does not implement PrefixDeleter
+		mock        = new(xcache.Mock)
+		expectedErr = errors.New("intentionally triggered Delete error")
+		failed      = make(map[xcache.Invalidation]error)
+		mu          sync.Mutex
+		onError     = func(invalidation xcache.Invalidation, err error) {
+			mu.Lock()
+			failed[invalidation] = err
+			mu.Unlock()
+		}
+		invalidations     = make(chan xcache.Invalidation, 2)
+		mockInvalidations = make(chan xcache.Invalidation, 1)
+	)
+	mock.SetDeleteCallback(func(context.Context, string) error {
+		return expectedErr
+	})
+	invalidations <- xcache.Invalidation{Kind: xcache.InvalidationPrefix, Target: "test-invalidator-prefix-"}
+	invalidations <- xcache.Invalidation{Kind: xcache.InvalidationTag, Target: "users"} // no Tags configured.
+	close(invalidations)
+	mockInvalidations <- xcache.Invalidation{Target: "test-invalidator-failed-key"}
+	close(mockInvalidations)
+
+	// act
+	err := xcache.NewInvalidator(cache, xcache.InvalidatorConfig{OnError: onError}).
+		Apply(context.Background(), invalidations)
+	mockErr := xcache.NewInvalidator(mock, xcache.InvalidatorConfig{
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+		OnError:    onError,
+	}).Apply(context.Background(), mockInvalidations)
+
+	// assert
+	assertNil(t, err)
+	assertNil(t, mockErr)
+	assertEqual(t, 3, len(failed))
+	assertTrue(t, errors.Is(
+		failed[xcache.Invalidation{Kind: xcache.InvalidationPrefix, Target: "test-invalidator-prefix-"}],
+		errors.ErrUnsupported,
+	))
+	assertTrue(t, errors.Is(
+		failed[xcache.Invalidation{Kind: xcache.InvalidationTag, Target: "users"}],
+		errors.ErrUnsupported,
+	))
+	assertTrue(t, errors.Is(failed[xcache.Invalidation{Target: "test-invalidator-failed-key"}], expectedErr))
+	assertEqual(t, 2, mock.DeleteCallsCount()) // 1 attempt + 1 retry.
+}
+
+func testInvalidatorWithDoneContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache          = new(xcache.Mock)
+		ctx, cancelCtx = context.WithCancel(context.Background())
+		invalidations  = make(chan xcache.Invalidation)
+	)
+	cancelCtx()
+
+	// act
+	err := xcache.ApplyInvalidations(ctx, cache, invalidations)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.Canceled))
+	assertEqual(t, 0, cache.DeleteCallsCount())
+}
+
+func TestInvalidation_MarshalText_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name         string
+		invalidation xcache.Invalidation
+		text         string
+	}{
+		{
+			name:         "key",
+			invalidation: xcache.Invalidation{Kind: xcache.InvalidationKey, Target: "user:123"},
+			text:         "key:user:123",
+		},
+		{
+			name:         "tag",
+			invalidation: xcache.Invalidation{Kind: xcache.InvalidationTag, Target: "users"},
+			text:         "tag:users",
+		},
+		{
+			name:         "prefix",
+			invalidation: xcache.Invalidation{Kind: xcache.InvalidationPrefix, Target: "user:"},
+			text:         "prefix:user:",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultText, errMarshal := test.invalidation.MarshalText()
+			var resultInvalidation xcache.Invalidation
+			errUnmarshal := resultInvalidation.UnmarshalText([]byte(test.text))
+
+			// assert
+			assertNil(t, errMarshal)
+			assertEqual(t, test.text, string(resultText))
+			assertNil(t, errUnmarshal)
+			assertEqual(t, test.invalidation, resultInvalidation)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var invalidation xcache.Invalidation
+		assertTrue(t, errors.Is(invalidation.UnmarshalText([]byte("user:123")), xcache.ErrInvalidInvalidation))
+		assertTrue(t, errors.Is(invalidation.UnmarshalText([]byte("no separator")), xcache.ErrInvalidInvalidation))
+		_, err := xcache.Invalidation{Kind: 99}.MarshalText()
+		assertTrue(t, errors.Is(err, xcache.ErrInvalidInvalidation))
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Jittered is a Cache decorator which adds a random jitter, of up to ± a configured percent,
+// to the expiration periods of saved keys, so that keys saved at the same moment
+// (when warming up the cache at deploy time, for example) do not expire at the same moment,
+// hammering the origin (database) all at once.
+// Keys saved with NoExpire, and deletions, are not jittered.
+// The percent can be changed at runtime, see SetPercent, and NewJitteredWithConfig for having it
+// driven by a xconf.Config.
+//
+// Example:
+//
+//	cache := xcache.NewJittered(redisCache, 10)
+//	// key expires in [54m, 66m].
+//	err := cache.Save(ctx, key, value, time.Hour)
+type Jittered struct {
+	cache   Cache
+	percent float64
+	mu      sync.RWMutex
+}
+
+// NewJittered instantiates a new Jittered which decorates given cache,
+// jittering expiration periods with up to ± given percent (a value within [0, 100)).
+// A percent outside the range is bounded to it.
+func NewJittered(cache Cache, percent float64) *Jittered {
+	return &Jittered{
+		cache:   cache,
+		percent: boundJitterPercent(percent),
+	}
+}
+
+// Save stores the given key-value into decorated cache, with the expiration period jittered.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+func (cache *Jittered) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.cache.Save(ctx, key, value, cache.jitter(expire))
+}
+
+// Load returns a key's value from decorated cache.
+func (cache *Jittered) Load(ctx context.Context, key string) ([]byte, error) {
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *Jittered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *Jittered) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Jittered) Unwrap() Cache {
+	return cache.cache
+}
+
+// Percent returns current max. jitter percent.
+func (cache *Jittered) Percent() float64 {
+	cache.mu.RLock()
+	percent := cache.percent
+	cache.mu.RUnlock()
+
+	return percent
+}
+
+// SetPercent changes the max. jitter percent. A percent outside [0, 100) is bounded to it.
+func (cache *Jittered) SetPercent(percent float64) {
+	percent = boundJitterPercent(percent)
+	cache.mu.Lock()
+	cache.percent = percent
+	cache.mu.Unlock()
+}
+
+// jitter returns given expiration period, randomly increased / decreased with up to the configured percent.
+// The result is at least 1ms, as a key should not expire instantly (or be deleted) due to jitter.
+func (cache *Jittered) jitter(expire time.Duration) time.Duration {
+	percent := cache.Percent()
+	if expire <= 0 || percent == 0 {
+		return expire
+	}
+
+	delta := float64(expire) * percent / 100 * (2*rand.Float64() - 1)
+	jittered := expire + time.Duration(delta)
+	if jittered < time.Millisecond {
+		return time.Millisecond
+	}
+
+	return jittered
+}
+
+// boundJitterPercent bounds given jitter percent to [0, 99].
+func boundJitterPercent(percent float64) float64 {
+	if percent < 0 {
+		return 0
+	} else if percent > 99 {
+		return 99
+	}
+
+	return percent
+}
@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Jittered)(nil) // test Jittered is a Cache
+}
+
+func TestJittered(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expiration is jittered", testJitteredExpirationIsJittered)
+	t.Run("no expiration and deletion are not jittered", testJitteredNoExpirationAndDeletionAreNotJittered)
+	t.Run("zero percent", testJitteredZeroPercent)
+}
+
+func testJitteredExpirationIsJittered(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = new(xcache.Mock)
+		subject  = xcache.NewJittered(cache, 10)
+		ctx      = context.Background()
+		expire   = time.Hour
+		expires  = make(map[time.Duration]struct{})
+		minValue = expire
+		maxValue = expire
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		expires[exp] = struct{}{}
+		minValue = min(minValue, exp)
+		maxValue = max(maxValue, exp)
+
+		return nil
+	})
+
+	// act
+	for i := 0; i < 1000; i++ {
+		requireNil(t, subject.Save(ctx, "test-jittered-key", []byte("test value"), expire))
+	}
+
+	// assert
+	assertEqual(t, 1000, cache.SaveCallsCount())
+	assertTrue(t, len(expires) > 1)
+	assertTrue(t, minValue >= 54*time.Minute)
+	assertTrue(t, maxValue <= 66*time.Minute)
+	assertTrue(t, minValue < 57*time.Minute) // jitter spreads both ways.
+	assertTrue(t, maxValue > 63*time.Minute)
+}
+
+func testJitteredNoExpirationAndDeletionAreNotJittered(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewJittered(cache, 50)
+		ctx     = context.Background()
+		expires []time.Duration
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		expires = append(expires, exp)
+
+		return nil
+	})
+
+	// act
+	errNoExpire := subject.Save(ctx, "test-jittered-no-exp-key", []byte("test value"), xcache.NoExpire)
+	errDelete := subject.Save(ctx, "test-jittered-delete-key", nil, -1)
+
+	// assert
+	assertNil(t, errNoExpire)
+	assertNil(t, errDelete)
+	assertEqual(t, []time.Duration{xcache.NoExpire, -1}, expires)
+}
+
+func testJitteredZeroPercent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewJittered(cache, -5)
+		ctx     = context.Background()
+		key     = "test-jittered-zero-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertNil(t, errTTL)
+	assertTrue(t, ttl >= 59*time.Second && ttl <= time.Minute) // not jittered.
+	assertTrue(t, subject.Unwrap() == cache)
+}
@@ -0,0 +1,87 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"github.com/actforgood/xconf"
+)
+
+// JitteredCfgKeyPercent is the key under which xconf.Config expects the max. jitter percent, see NewJittered.
+const JitteredCfgKeyPercent = "xcache.jitter.percent"
+
+// NewJitteredWithConfig initializes a Jittered which decorates given cache,
+// with the max. jitter percent taken from a xconf.Config (under JitteredCfgKeyPercent).
+// An invalid (or missing) percent is replaced with 0 (no jitter),
+// use NewJitteredWithValidConfig to fail on it instead.
+//
+// An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
+// In case the percent is changed, it is applied right away, without the need of
+// restarting your application. An invalid configuration reload is disregarded.
+func NewJitteredWithConfig(cache Cache, config xconf.Config) *Jittered {
+	percent, _ := getJitteredPercent(config)
+	jittered := NewJittered(cache, percent)
+
+	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
+		defConfig.RegisterObserver(jittered.onConfigChange)
+	}
+
+	return jittered
+}
+
+// NewJitteredWithValidConfig is like NewJitteredWithConfig, but it fails on an invalid percent:
+// an error aggregating a ConfigError is returned, and jittered is nil.
+func NewJitteredWithValidConfig(cache Cache, config xconf.Config) (*Jittered, error) {
+	if err := ValidateJitteredXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewJitteredWithConfig(cache, config), nil
+}
+
+// getJitteredPercent returns the max. jitter percent taken from a xconf.Config.
+// An invalid value is replaced with 0, and an error aggregating a ConfigError is returned.
+func getJitteredPercent(config xconf.Config) (float64, error) {
+	r := newXConfReader(config)
+	percent := r.Float64(JitteredCfgKeyPercent, 0)
+	if percent < 0 || percent >= 100 {
+		r.addErr(JitteredCfgKeyPercent, errConfigValueRange)
+		percent = 0
+	}
+
+	return percent, r.Err()
+}
+
+// ValidateJitteredXConfig checks the Jittered configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for a percent which cannot be interpreted,
+// or is not within [0, 100), or nil if configuration is valid.
+func ValidateJitteredXConfig(config xconf.Config) error {
+	_, err := getJitteredPercent(config)
+
+	return err
+}
+
+// onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
+// In case JitteredCfgKeyPercent config is changed, the new percent is applied, if it is valid.
+// This callback is automatically registered on instantiation of a Jittered object with NewJitteredWithConfig.
+func (cache *Jittered) onConfigChange(config xconf.Config, changedKeys ...string) {
+	configHasChanged := false
+	for _, changedKey := range changedKeys {
+		if changedKey == JitteredCfgKeyPercent {
+			configHasChanged = true
+
+			break
+		}
+	}
+	if !configHasChanged {
+		return
+	}
+
+	percent, err := getJitteredPercent(config)
+	if err != nil { // keep current percent.
+		return
+	}
+	cache.SetPercent(percent)
+}
@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestJittered_withXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig       uint32
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			switch atomic.LoadUint32(&reloadConfig) {
+			case 1:
+				return map[string]any{xcache.JitteredCfgKeyPercent: "25"}, nil
+			case 2:
+				return map[string]any{xcache.JitteredCfgKeyPercent: 150}, nil
+			}
+
+			return map[string]any{xcache.JitteredCfgKeyPercent: 10}, nil
+		})
+		subject = xcache.NewJitteredWithConfig(xcache.Nop{}, config)
+	)
+
+	// act & assert initial config
+	assertEqual(t, 10.0, subject.Percent())
+
+	// act & assert reloaded config
+	atomic.StoreUint32(&reloadConfig, 1)
+	waitReload()
+	assertEqual(t, 25.0, subject.Percent())
+
+	// act & assert invalid reloaded config is disregarded
+	atomic.StoreUint32(&reloadConfig, 2)
+	waitReload()
+	assertEqual(t, 25.0, subject.Percent())
+}
+
+func TestValidateJitteredXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing value",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name:   "valid value",
+			config: xconf.NewMockConfig(xcache.JitteredCfgKeyPercent, 12.5),
+		},
+		{
+			name:            "invalid value",
+			config:          xconf.NewMockConfig(xcache.JitteredCfgKeyPercent, "a lot"),
+			expectedErrKeys: []string{xcache.JitteredCfgKeyPercent},
+		},
+		{
+			name:            "out of range value",
+			config:          xconf.NewMockConfig(xcache.JitteredCfgKeyPercent, 100),
+			expectedErrKeys: []string{xcache.JitteredCfgKeyPercent},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateJitteredXConfig(test.config)
+			jittered, resultCtorErr := xcache.NewJitteredWithValidConfig(xcache.Nop{}, test.config)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, jittered != nil)
+		})
+	}
+}
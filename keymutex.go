@@ -0,0 +1,120 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"hash/maphash"
+	"slices"
+	"sync"
+)
+
+// DefaultKeyMutexStripes is the default no. of stripes of a KeyMutex.
+const DefaultKeyMutexStripes = 256
+
+// KeyMutex is a mutual exclusion lock per key, useful for coordinating the local
+// (re)computation of values: only one goroutine computes a key at a time, while keys are computed concurrently.
+//
+// Keys are distributed to a fixed no. of locks (stripes), so its memory footprint is constant,
+// at the cost of different keys sharing a stripe waiting for each other.
+// For the same reason, a goroutine must not lock a key while holding the lock of another key
+// (it may deadlock), LockMany must be used instead.
+// The zero value is not usable, a KeyMutex must be instantiated with NewKeyMutex.
+//
+// Example:
+//
+//	km := xcache.NewKeyMutex(0)
+//	km.Lock(key)
+//	defer km.Unlock(key)
+//	if value, err := cache.Load(ctx, key); err == nil {
+//		return value, nil // computed meanwhile by another goroutine.
+//	}
+//	value := compute(key)
+//	_ = cache.Save(ctx, key, value, ttl)
+type KeyMutex struct {
+	seed    maphash.Seed
+	stripes []sync.Mutex
+}
+
+// NewKeyMutex instantiates a new KeyMutex with given no. of stripes.
+// If stripes is not positive, DefaultKeyMutexStripes is used.
+func NewKeyMutex(stripes int) *KeyMutex {
+	if stripes <= 0 {
+		stripes = DefaultKeyMutexStripes
+	}
+
+	return &KeyMutex{
+		seed:    maphash.MakeSeed(),
+		stripes: make([]sync.Mutex, stripes),
+	}
+}
+
+// Lock locks given key. If the key is already locked, it blocks until it is unlocked.
+func (km *KeyMutex) Lock(key string) {
+	km.stripes[km.stripe(key)].Lock()
+}
+
+// TryLock tries to lock given key, without blocking, and reports whether it succeeded.
+func (km *KeyMutex) TryLock(key string) bool {
+	return km.stripes[km.stripe(key)].TryLock()
+}
+
+// Unlock unlocks given key.
+// It is a run-time error if the key is not locked.
+func (km *KeyMutex) Unlock(key string) {
+	km.stripes[km.stripe(key)].Unlock()
+}
+
+// LockMany locks given keys, always in the same order, so that concurrent LockMany calls
+// with overlapping keys do not deadlock.
+// The keys must be unlocked with UnlockMany, with the same keys.
+func (km *KeyMutex) LockMany(keys ...string) {
+	km.lockStripes(km.stripesOf(keys))
+}
+
+// UnlockMany unlocks given keys, locked with LockMany.
+func (km *KeyMutex) UnlockMany(keys ...string) {
+	km.unlockStripes(km.stripesOf(keys))
+}
+
+// stripe returns the index of the stripe given key belongs to.
+func (km *KeyMutex) stripe(key string) int {
+	return int(maphash.String(km.seed, key) % uint64(len(km.stripes)))
+}
+
+// stripesOf returns the sorted, distinct, indexes of the stripes given keys belong to.
+func (km *KeyMutex) stripesOf(keys []string) []int {
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		indexes = append(indexes, km.stripe(key))
+	}
+	slices.Sort(indexes) // lock in the same order, to avoid deadlocks.
+
+	return slices.Compact(indexes)
+}
+
+// allStripes returns the indexes of all the stripes.
+func (km *KeyMutex) allStripes() []int {
+	indexes := make([]int, len(km.stripes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return indexes
+}
+
+// lockStripes locks given stripes.
+func (km *KeyMutex) lockStripes(indexes []int) {
+	for _, idx := range indexes {
+		km.stripes[idx].Lock()
+	}
+}
+
+// unlockStripes unlocks given stripes.
+func (km *KeyMutex) unlockStripes(indexes []int) {
+	for _, idx := range indexes {
+		km.stripes[idx].Unlock()
+	}
+}
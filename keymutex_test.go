@@ -0,0 +1,125 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestKeyMutex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lock excludes same key", testKeyMutexLockExcludesSameKey)
+	t.Run("try lock", testKeyMutexTryLock)
+	t.Run("lock many with overlapping keys", testKeyMutexLockManyWithOverlappingKeys)
+}
+
+func testKeyMutexLockExcludesSameKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject      = xcache.NewKeyMutex(0)
+		key          = "test-key-mutex-key"
+		goroutinesNo = 20
+		incrementsNo = 100
+		counter      int
+		wg           sync.WaitGroup
+	)
+
+	// act
+	wg.Add(goroutinesNo)
+	for i := 0; i < goroutinesNo; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < incrementsNo; j++ {
+				subject.Lock(key)
+				counter++ // the race detector complains if the lock does not work.
+				subject.Unlock(key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, goroutinesNo*incrementsNo, counter)
+}
+
+func testKeyMutexTryLock(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewKeyMutex(1)
+		key     = "test-key-mutex-key"
+	)
+
+	// act & assert
+	assertTrue(t, subject.TryLock(key))
+	assertTrue(t, !subject.TryLock(key))
+	assertTrue(t, !subject.TryLock("test-key-mutex-other-key")) // single stripe, shared by all keys.
+	subject.Unlock(key)
+	assertTrue(t, subject.TryLock(key))
+	subject.Unlock(key)
+}
+
+func testKeyMutexLockManyWithOverlappingKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject      = xcache.NewKeyMutex(8)
+		goroutinesNo = 10
+		keysNo       = 20
+		counters     = make([]int, keysNo) // counter per key, incremented while holding key's lock.
+		wg           sync.WaitGroup
+	)
+	keys := make([]string, 0, keysNo)
+	for i := 0; i < keysNo; i++ {
+		keys = append(keys, "test-key-mutex-key-"+strconv.Itoa(i))
+	}
+
+	// act
+	wg.Add(goroutinesNo)
+	for i := 0; i < goroutinesNo; i++ {
+		go func(i int) {
+			defer wg.Done()
+			// each goroutine locks a window of keys (keys in reverse order for odd goroutines),
+			// overlapping with other goroutines' windows.
+			lockedIdx := make([]int, 0, 5)
+			for j := 0; j < cap(lockedIdx); j++ {
+				lockedIdx = append(lockedIdx, (i+j)%keysNo)
+			}
+			if i%2 == 1 {
+				slices.Reverse(lockedIdx)
+			}
+			lockedKeys := make([]string, 0, len(lockedIdx))
+			for _, idx := range lockedIdx {
+				lockedKeys = append(lockedKeys, keys[idx])
+			}
+			for j := 0; j < 100; j++ {
+				subject.LockMany(lockedKeys...)
+				for _, idx := range lockedIdx {
+					counters[idx]++
+				}
+				subject.UnlockMany(lockedKeys...)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	total := 0
+	for _, counter := range counters {
+		total += counter
+	}
+	assertEqual(t, goroutinesNo*5*100, total)
+}
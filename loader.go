@@ -0,0 +1,147 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBatchResultsMismatch is returned by a Loader if its batch function does not return
+// a value for each requested key.
+var ErrBatchResultsMismatch = errors.New("batch function results do not match the requested keys")
+
+// LoaderBatchFunc fetches the values of given keys from the source of truth (a database, a service, etc.).
+// Returned values must have the same length and order as the keys.
+// Returned errors can be nil (no error occurred), or have the same length and order as the keys
+// (a nil element meaning no error occurred for the corresponding key).
+type LoaderBatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader is a request scoped, dataloader like, component which resolves values of type V,
+// identified by keys of type K, in batches:
+// 1. values already resolved during the Loader's lifetime are returned from memory (request scoped memoization);
+// 2. values are looked up in the cache (cross requests caching);
+// 3. the remaining keys are fetched with the batch function, in a single call, and their values are cached.
+//
+// It is meant to be instantiated per request (for example, in a GraphQL middleware), and its LoadMany
+// to be used as the batch function of a dataloader (like github.com/graph-gophers/dataloader),
+// so resolvers get per request deduplication plus cross requests caching from one component.
+// It is safe for concurrent use.
+type Loader[K comparable, V any] struct {
+	typed   *Typed[K, V]
+	batchFn LoaderBatchFunc[K, V]
+	memo    map[K]V
+	mu      sync.Mutex
+}
+
+// NewLoader instantiates a new Loader, which caches values into given cache, according to given settings,
+// and fetches values missing from cache with given batch function.
+func NewLoader[K comparable, V any](
+	cache Cache,
+	config TypedConfig[K, V],
+	batchFn LoaderBatchFunc[K, V],
+) *Loader[K, V] {
+	return &Loader[K, V]{
+		typed:   NewTyped(cache, config),
+		batchFn: batchFn,
+		memo:    make(map[K]V),
+	}
+}
+
+// Load returns the value of given key.
+func (loader *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	values, errs := loader.LoadMany(ctx, []K{key})
+
+	return values[0], errs[0]
+}
+
+// LoadMany returns the values of given keys, and the errors encountered for each of them.
+// Returned slices have the same length and order as the keys.
+// Cache errors are not returned, the corresponding keys are fetched with the batch function.
+// Errors returned by the batch function are not memoized, so a subsequent call retries the fetch.
+func (loader *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	var (
+		values  = make([]V, len(keys))
+		errs    = make([]error, len(keys))
+		missIdx = make(map[K][]int) // indexes of not yet resolved keys.
+		misses  []K
+	)
+
+	loader.mu.Lock()
+	for i, key := range keys {
+		if value, found := loader.memo[key]; found {
+			values[i] = value
+		} else {
+			if _, requested := missIdx[key]; !requested {
+				misses = append(misses, key)
+			}
+			missIdx[key] = append(missIdx[key], i)
+		}
+	}
+	loader.mu.Unlock()
+
+	// lookup the cache.
+	var fetches []K
+	for _, key := range misses {
+		value, err := loader.typed.Get(ctx, key)
+		if err != nil {
+			fetches = append(fetches, key)
+
+			continue
+		}
+		loader.resolve(key, value, missIdx[key], values)
+	}
+	if len(fetches) == 0 {
+		return values, errs
+	}
+
+	// fetch from the source of truth.
+	fetchedValues, fetchErrs := loader.batchFn(ctx, fetches)
+	if len(fetchedValues) != len(fetches) || (fetchErrs != nil && len(fetchErrs) != len(fetches)) {
+		for _, key := range fetches {
+			for _, idx := range missIdx[key] {
+				errs[idx] = ErrBatchResultsMismatch
+			}
+		}
+
+		return values, errs
+	}
+	for i, key := range fetches {
+		if fetchErrs != nil && fetchErrs[i] != nil {
+			for _, idx := range missIdx[key] {
+				errs[idx] = fetchErrs[i]
+			}
+
+			continue
+		}
+		_ = loader.typed.Set(ctx, key, fetchedValues[i])
+		loader.resolve(key, fetchedValues[i], missIdx[key], values)
+	}
+
+	return values, errs
+}
+
+// Clear removes the value of given key from memory and cache, so it will be fetched again.
+// It returns an error if the value could not be deleted from cache.
+func (loader *Loader[K, V]) Clear(ctx context.Context, key K) error {
+	loader.mu.Lock()
+	delete(loader.memo, key)
+	loader.mu.Unlock()
+
+	return loader.typed.Invalidate(ctx, key)
+}
+
+// resolve memoizes given key's value, and sets it at given indexes in values.
+func (loader *Loader[K, V]) resolve(key K, value V, indexes []int, values []V) {
+	loader.mu.Lock()
+	loader.memo[key] = value
+	loader.mu.Unlock()
+
+	for _, idx := range indexes {
+		values[idx] = value
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestLoader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values are memoized, cached and fetched", testLoaderValuesAreMemoizedCachedAndFetched)
+	t.Run("fetch errors are returned", testLoaderFetchErrsAreReturned)
+	t.Run("batch results mismatch", testLoaderBatchResultsMismatch)
+	t.Run("clear", testLoaderClear)
+}
+
+func testLoaderValuesAreMemoizedCachedAndFetched(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		config  = xcache.TypedConfig[int, string]{KeyPrefix: "test-loader-memo-", TTL: time.Minute}
+		ctx     = context.Background()
+		fetched [][]int
+		batchFn = func(_ context.Context, keys []int) ([]string, []error) {
+			fetched = append(fetched, keys)
+			values := make([]string, len(keys))
+			for i, key := range keys {
+				values[i] = "value-" + strconv.Itoa(key)
+			}
+
+			return values, nil
+		}
+	)
+	_ = xcache.NewTyped(cache, config).Set(ctx, 2, "cached-value-2")
+	subject1 := xcache.NewLoader(cache, config, batchFn)
+
+	// act
+	values1, errs1 := subject1.LoadMany(ctx, []int{1, 2, 3, 1})
+	value1, err1 := subject1.Load(ctx, 3)
+	subject2 := xcache.NewLoader(cache, config, batchFn) // simulate another request
+	values2, errs2 := subject2.LoadMany(ctx, []int{1, 3, 4})
+
+	// assert
+	assertEqual(t, []string{"value-1", "cached-value-2", "value-3", "value-1"}, values1)
+	assertEqual(t, []error{nil, nil, nil, nil}, errs1)
+	assertNil(t, err1)
+	assertEqual(t, "value-3", value1)
+	assertEqual(t, []string{"value-1", "value-3", "value-4"}, values2)
+	assertEqual(t, []error{nil, nil, nil}, errs2)
+	assertEqual(t, [][]int{{1, 3}, {4}}, fetched)
+}
+
+func testLoaderFetchErrsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		expectedErr = errors.New("intentionally triggered fetch error")
+		fetchesCnt  int
+		subject     = xcache.NewLoader(
+			xcache.NewMemory(1),
+			xcache.TypedConfig[int, string]{KeyPrefix: "test-loader-err-"},
+			func(_ context.Context, keys []int) ([]string, []error) {
+				fetchesCnt++
+				values := make([]string, len(keys))
+				errs := make([]error, len(keys))
+				for i, key := range keys {
+					if key%2 == 0 {
+						errs[i] = expectedErr
+					} else {
+						values[i] = "value-" + strconv.Itoa(key)
+					}
+				}
+
+				return values, errs
+			},
+		)
+		ctx = context.Background()
+	)
+
+	// act
+	values, errs := subject.LoadMany(ctx, []int{1, 2})
+	_, err := subject.Load(ctx, 2)
+
+	// assert
+	assertEqual(t, []string{"value-1", ""}, values)
+	assertNil(t, errs[0])
+	assertTrue(t, errors.Is(errs[1], expectedErr))
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertEqual(t, 2, fetchesCnt) // errors are not memoized
+}
+
+func testLoaderBatchResultsMismatch(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewLoader(
+		new(xcache.Nop),
+		xcache.TypedConfig[string, string]{},
+		func(context.Context, []string) ([]string, []error) {
+			return []string{"only one value"}, nil
+		},
+	)
+
+	// act
+	_, errs := subject.LoadMany(context.Background(), []string{"a", "b"})
+
+	// assert
+	assertTrue(t, errors.Is(errs[0], xcache.ErrBatchResultsMismatch))
+	assertTrue(t, errors.Is(errs[1], xcache.ErrBatchResultsMismatch))
+}
+
+func testLoaderClear(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fetchesCnt int
+		cache      = xcache.NewMemory(1)
+		subject    = xcache.NewLoader(
+			cache,
+			xcache.TypedConfig[string, string]{KeyPrefix: "test-loader-clear-"},
+			func(_ context.Context, keys []string) ([]string, []error) {
+				fetchesCnt++
+
+				return []string{"value-" + strconv.Itoa(fetchesCnt)}, nil
+			},
+		)
+		ctx = context.Background()
+	)
+	value, err := subject.Load(ctx, "a")
+	requireNil(t, err)
+	assertEqual(t, "value-1", value)
+
+	// act
+	err = subject.Clear(ctx, "a")
+
+	// assert
+	assertNil(t, err)
+	_, err = cache.Load(ctx, "test-loader-clear-a")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	value, err = subject.Load(ctx, "a")
+	assertNil(t, err)
+	assertEqual(t, "value-2", value)
+}
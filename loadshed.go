@@ -0,0 +1,147 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrOverloaded is returned by a LoadShed cache when an operation is rejected,
+// as the max. no. of concurrent and queued operations was reached.
+var ErrOverloaded = errors.New("cache overloaded")
+
+// LoadShed is a Cache decorator which bounds the no. of concurrent operations on the decorated (usually remote) cache,
+// and fails fast, with ErrOverloaded, beyond limits: an operation is executed if less than maxConcurrent operations
+// are in progress, otherwise it waits for its turn if less than maxQueue operations are already waiting,
+// otherwise it's rejected.
+// It protects Redis from connection storms when an upstream incident multiplies the traffic.
+// Deletions (saves with a negative expiration period) are not rejected (they wait for their turn),
+// so no stale data is served. Stats is not limited.
+//
+// To serve only from the Memory layer when Redis is overloaded, decorate the Redis layer of a Multi cache:
+//
+//	cache := xcache.NewMulti(memCache, xcache.NewLoadShed(redisCache, 64, 256))
+type LoadShed struct {
+	cache    Cache
+	slots    chan struct{}
+	maxQueue int64
+	queued   int64
+	shed     int64
+}
+
+// NewLoadShed instantiates a new LoadShed which decorates given cache, allowing at most maxConcurrent operations
+// in progress (at least 1), and at most maxQueue operations waiting for their turn.
+func NewLoadShed(cache Cache, maxConcurrent, maxQueue int) *LoadShed {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+
+	return &LoadShed{
+		cache:    cache,
+		slots:    make(chan struct{}, maxConcurrent),
+		maxQueue: int64(maxQueue),
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// It returns ErrOverloaded if the operation was rejected.
+func (cache *LoadShed) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if err := cache.acquire(ctx, expire < 0); err != nil {
+		return err
+	}
+	defer cache.release()
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// It returns ErrOverloaded if the operation was rejected.
+func (cache *LoadShed) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := cache.acquire(ctx, false); err != nil {
+		return nil, err
+	}
+	defer cache.release()
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// It returns ErrOverloaded if the operation was rejected.
+func (cache *LoadShed) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := cache.acquire(ctx, false); err != nil {
+		return -1, err
+	}
+	defer cache.release()
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics (regardless of the load).
+func (cache *LoadShed) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *LoadShed) Unwrap() Cache {
+	return cache.cache
+}
+
+// ShedCount returns the number of rejected operations.
+func (cache *LoadShed) ShedCount() int64 {
+	return atomic.LoadInt64(&cache.shed)
+}
+
+// ContributeStats reports the no. of rejected operations, as "loadshed.shed" metric,
+// the no. of operations in progress, as "loadshed.concurrent" metric,
+// and the no. of operations waiting for their turn, as "loadshed.queued" metric.
+func (cache *LoadShed) ContributeStats(add func(name string, value float64)) {
+	add("loadshed.shed", float64(cache.ShedCount()))
+	add("loadshed.concurrent", float64(len(cache.slots)))
+	add("loadshed.queued", float64(atomic.LoadInt64(&cache.queued)))
+}
+
+// acquire waits for an operation slot. If the operation cannot be queued (and it's not a mandatory one),
+// ErrOverloaded is returned. If the context is done while waiting, its error is returned.
+func (cache *LoadShed) acquire(ctx context.Context, mandatory bool) error {
+	select {
+	case cache.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if !mandatory {
+		if atomic.AddInt64(&cache.queued, 1) > cache.maxQueue {
+			atomic.AddInt64(&cache.queued, -1)
+			atomic.AddInt64(&cache.shed, 1)
+
+			return ErrOverloaded
+		}
+		defer atomic.AddInt64(&cache.queued, -1)
+	}
+
+	select {
+	case cache.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees an operation slot.
+func (cache *LoadShed) release() {
+	<-cache.slots
+}
@@ -0,0 +1,139 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.LoadShed)(nil) // test LoadShed is a Cache
+}
+
+func TestLoadShed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("within limits - cache is used", testLoadShedWithinLimitsCacheIsUsed)
+	t.Run("beyond limits - operations are rejected", testLoadShedBeyondLimitsOperationsAreRejected)
+	t.Run("queued operation waits for its turn", testLoadShedQueuedOperationWaitsForItsTurn)
+}
+
+func testLoadShedWithinLimitsCacheIsUsed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewLoadShed(xcache.NewMemory(1), 2, 0)
+		key     = "test-load-shed-key"
+		value   = []byte("test value")
+		ctx     = context.Background()
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	resultValue, errLoad := subject.Load(ctx, key)
+	resultTTL, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, resultValue)
+	assertNil(t, errTTL)
+	assertTrue(t, resultTTL > 0)
+	assertNil(t, errStats)
+	assertEqual(t, int64(0), subject.ShedCount())
+}
+
+func testLoadShedBeyondLimitsOperationsAreRejected(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewLoadShed(cache, 1, 0)
+		key     = "test-load-shed-overloaded-key"
+		ctx     = context.Background()
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		close(started)
+		<-release
+
+		return nil, xcache.ErrNotFound
+	})
+	go func() { // occupies the only slot.
+		_, _ = subject.Load(ctx, key)
+		close(done)
+	}()
+	<-started
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+
+	// assert
+	assertTrue(t, errors.Is(errSave, xcache.ErrOverloaded))
+	assertTrue(t, errors.Is(errLoad, xcache.ErrOverloaded))
+	assertTrue(t, errors.Is(errTTL, xcache.ErrOverloaded))
+	assertNil(t, errStats)
+	assertEqual(t, int64(3), subject.ShedCount())
+	assertEqual(t, 0, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertEqual(t, 0, cache.TTLCallsCount())
+	close(release)
+	<-done
+}
+
+func testLoadShedQueuedOperationWaitsForItsTurn(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewLoadShed(cache, 1, 1)
+		key     = "test-load-shed-queued-key"
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		close(started)
+		<-release
+
+		return nil, xcache.ErrNotFound
+	})
+	go func() { // occupies the only slot.
+		_, _ = subject.Load(context.Background(), key)
+		close(done)
+	}()
+	<-started
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelCtx()
+
+	// act
+	_, errTTL := subject.TTL(ctx, key)           // queued, until context's deadline.
+	errDelete := subject.Save(ctx, key, nil, -1) // deletions are not rejected.
+	close(release)
+	<-done
+	_, errTTLAfterRelease := subject.TTL(context.Background(), key)
+
+	// assert
+	assertTrue(t, errors.Is(errTTL, context.DeadlineExceeded))
+	assertTrue(t, errors.Is(errDelete, context.DeadlineExceeded))
+	assertNil(t, errTTLAfterRelease)
+	assertEqual(t, int64(0), subject.ShedCount())
+	assertEqual(t, 1, cache.TTLCallsCount())
+}
@@ -0,0 +1,193 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/actforgood/xlog"
+)
+
+// loggedMessage is the message of an operation's log.
+const loggedMessage = "cache operation"
+
+// Operations' outcomes, logged by a Logged cache.
+const (
+	logOutcomeOK    = "ok"
+	logOutcomeHit   = "hit"
+	logOutcomeMiss  = "miss"
+	logOutcomeError = "error"
+)
+
+// LogConfig holds the settings of a Logged cache.
+type LogConfig struct {
+	// Logger is the slog logger operations are logged through. If neither Logger, nor XLogger is set,
+	// slog.Default() is used.
+	Logger *slog.Logger
+	// XLogger is the xlog logger operations are logged through (instead of Logger).
+	// Levels are mapped to xlog's ones: below Info to Debug, below Warn to Info, below Error to Warn,
+	// and the others to Error.
+	XLogger xlog.Logger
+	// Level is the level successful operations (including misses) are logged at.
+	// By default (nil), it's slog.LevelDebug. A *slog.LevelVar can be used to change it at runtime.
+	Level slog.Leveler
+	// ErrorLevel is the level failed operations are logged at. By default (nil), it's slog.LevelError.
+	ErrorLevel slog.Leveler
+	// SampleRate is the fraction, within (0, 1], of successful operations which are logged
+	// (failed operations are always logged). By default (0), all of them are logged.
+	SampleRate float64
+}
+
+// Logged is a Cache decorator which logs every operation (Save, Load, TTL, Stats) with its key,
+// duration, outcome ("ok", "hit", "miss", "error") and error, through slog (or xlog), at configurable levels,
+// and with optional sampling, see LogConfig. It's meant for debugging cache behavior (in staging, for example)
+// without sprinkling logs through application's code.
+// Note: keys are logged as they are, do not log caches whose keys hold sensitive data.
+//
+// Example of output, with a slog JSON handler:
+//
+//	{"level":"DEBUG","msg":"cache operation","op":"load","key":"product:1","duration":183042,"outcome":"hit","size":512}
+type Logged struct {
+	cache  Cache
+	config LogConfig
+}
+
+// NewLogged instantiates a new Logged which decorates given cache, according to given settings.
+func NewLogged(cache Cache, config LogConfig) *Logged {
+	if config.Logger == nil && config.XLogger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Level == nil {
+		config.Level = slog.LevelDebug
+	}
+	if config.ErrorLevel == nil {
+		config.ErrorLevel = slog.LevelError
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+
+	return &Logged{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache, and logs the operation.
+func (cache *Logged) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	start := time.Now()
+	err := cache.cache.Save(ctx, key, value, expire)
+	cache.log(ctx, "save", key, start, err, slog.Int("size", len(value)), slog.Duration("expire", expire))
+
+	return err
+}
+
+// Load returns a key's value from decorated cache, and logs the operation.
+func (cache *Logged) Load(ctx context.Context, key string) ([]byte, error) {
+	start := time.Now()
+	value, err := cache.cache.Load(ctx, key)
+	cache.log(ctx, "load", key, start, err, slog.Int("size", len(value)))
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, and logs the operation.
+func (cache *Logged) TTL(ctx context.Context, key string) (time.Duration, error) {
+	start := time.Now()
+	ttl, err := cache.cache.TTL(ctx, key)
+	cache.log(ctx, "ttl", key, start, err, slog.Duration("ttl", ttl))
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics, and logs the operation.
+func (cache *Logged) Stats(ctx context.Context) (Stats, error) {
+	start := time.Now()
+	stats, err := cache.cache.Stats(ctx)
+	cache.log(ctx, "stats", "", start, err)
+
+	return stats, err
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Logged) Unwrap() Cache {
+	return cache.cache
+}
+
+// log logs an operation, if its level is enabled, and it was sampled.
+func (cache *Logged) log(
+	ctx context.Context,
+	op, key string,
+	start time.Time,
+	err error,
+	extra ...slog.Attr,
+) {
+	duration := time.Since(start)
+	level, outcome := cache.config.Level.Level(), logOutcomeOK
+	switch {
+	case errors.Is(err, ErrNotFound):
+		outcome = logOutcomeMiss
+	case err != nil:
+		level, outcome = cache.config.ErrorLevel.Level(), logOutcomeError
+	case op == "load":
+		outcome = logOutcomeHit
+	}
+	if outcome != logOutcomeError && cache.config.SampleRate < 1 && rand.Float64() >= cache.config.SampleRate {
+		return
+	}
+	if cache.config.Logger != nil && !cache.config.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 5+len(extra))
+	attrs = append(attrs, slog.String("op", op))
+	if key != "" {
+		attrs = append(attrs, slog.String("key", key))
+	}
+	attrs = append(attrs, slog.Duration("duration", duration), slog.String("outcome", outcome))
+	if outcome == logOutcomeError {
+		attrs = append(attrs, slog.String("err", err.Error()))
+	}
+	if err == nil {
+		attrs = append(attrs, extra...)
+	}
+
+	if cache.config.Logger != nil {
+		cache.config.Logger.LogAttrs(ctx, level, loggedMessage, attrs...)
+
+		return
+	}
+	cache.logXLog(level, attrs)
+}
+
+// logXLog logs given attributes through the xlog logger, at the xlog level corresponding to given level.
+func (cache *Logged) logXLog(level slog.Level, attrs []slog.Attr) {
+	keyValues := make([]any, 0, 2+2*len(attrs))
+	keyValues = append(keyValues, xlog.MessageKey, loggedMessage)
+	for _, attr := range attrs {
+		keyValues = append(keyValues, attr.Key, attr.Value.Any())
+	}
+
+	switch {
+	case level < slog.LevelInfo:
+		cache.config.XLogger.Debug(keyValues...)
+	case level < slog.LevelWarn:
+		cache.config.XLogger.Info(keyValues...)
+	case level < slog.LevelError:
+		cache.config.XLogger.Warn(keyValues...)
+	default:
+		cache.config.XLogger.Error(keyValues...)
+	}
+}
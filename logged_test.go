@@ -0,0 +1,141 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xlog"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Logged)(nil) // test Logged is a Cache
+}
+
+func TestLogged(t *testing.T) {
+	t.Parallel()
+
+	t.Run("operations are logged through slog", testLoggedOperationsAreLoggedThroughSlog)
+	t.Run("disabled level is not logged", testLoggedDisabledLevelIsNotLogged)
+	t.Run("operations are logged through xlog", testLoggedOperationsAreLoggedThroughXLog)
+}
+
+func testLoggedOperationsAreLoggedThroughSlog(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		logger  = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewLogged(cache, xcache.LogConfig{Logger: logger})
+		ctx     = context.Background()
+		key     = "test-logged-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	_ = subject.Save(ctx, key, value, time.Minute)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.Load(ctx, "test-logged-missing-key")
+	_, _ = subject.TTL(ctx, key)
+	_, _ = subject.Stats(ctx)
+
+	// assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assertEqual(t, 5, len(lines)) {
+		expected := []map[string]any{
+			{"level": "DEBUG", "op": "save", "key": key, "outcome": "ok", "size": float64(len(value))},
+			{"level": "DEBUG", "op": "load", "key": key, "outcome": "hit", "size": float64(len(value))},
+			{"level": "DEBUG", "op": "load", "key": "test-logged-missing-key", "outcome": "miss"},
+			{"level": "DEBUG", "op": "ttl", "key": key, "outcome": "ok"},
+			{"level": "DEBUG", "op": "stats", "outcome": "ok"},
+		}
+		for idx, line := range lines {
+			var record map[string]any
+			requireNil(t, json.Unmarshal([]byte(line), &record))
+			assertEqual(t, "cache operation", record["msg"])
+			assertNotNil(t, record["duration"])
+			for attr, expectedValue := range expected[idx] {
+				assertEqual(t, expectedValue, record[attr])
+			}
+		}
+	}
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testLoggedDisabledLevelIsNotLogged(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf     bytes.Buffer
+		logger  = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		cache   = new(xcache.Mock)
+		subject = xcache.NewLogged(cache, xcache.LogConfig{Logger: logger, SampleRate: 0.5})
+		ctx     = context.Background()
+		key     = "test-logged-disabled-key"
+		errLoad = errors.New("connection reset by peer")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errLoad
+	})
+
+	// act
+	_ = subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, _ = subject.Load(ctx, key)
+
+	// assert: only the failed load is logged (successful operations are logged at debug level).
+	var record map[string]any
+	requireNil(t, json.Unmarshal(buf.Bytes(), &record))
+	assertEqual(t, "ERROR", record["level"])
+	assertEqual(t, "load", record["op"])
+	assertEqual(t, "error", record["outcome"])
+	assertEqual(t, errLoad.Error(), record["err"])
+}
+
+func testLoggedOperationsAreLoggedThroughXLog(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		logger  = xlog.NewMockLogger()
+		cache   = new(xcache.Mock)
+		subject = xcache.NewLogged(cache, xcache.LogConfig{
+			XLogger:    logger,
+			Level:      slog.LevelInfo,
+			ErrorLevel: slog.LevelWarn,
+		})
+		ctx = context.Background()
+		key = "test-logged-xlog-key"
+	)
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return -1, context.DeadlineExceeded
+	})
+	logger.SetLogCallback(xlog.LevelInfo, func(keyValues ...any) {
+		assertEqual(t, []any{xlog.MessageKey, "cache operation", "op", "load", "key", key}, keyValues[:6])
+	})
+	logger.SetLogCallback(xlog.LevelWarning, func(keyValues ...any) {
+		assertEqual(t, []any{xlog.MessageKey, "cache operation", "op", "ttl", "key", key}, keyValues[:6])
+		assertEqual(t, []any{"outcome", "error", "err", context.DeadlineExceeded.Error()}, keyValues[8:])
+	})
+
+	// act
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.TTL(ctx, key)
+
+	// assert
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelInfo))
+	assertEqual(t, 1, logger.LogCallsCount(xlog.LevelWarning))
+}
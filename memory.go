@@ -6,6 +6,7 @@
 package xcache
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"sync"
@@ -16,14 +17,25 @@ import (
 
 const freecacheMinBufSize = 512 * 1024
 
+// memoryStatsSampleSize is the max. no. of entries examined in order to estimate the used memory.
+const memoryStatsSampleSize = 256
+
+// memoryEntryHeaderSize is the size of the header Freecache stores along with each entry.
+const memoryEntryHeaderSize = 24
+
+// memoryCtxCheckInterval is the no. of iterated entries after which the context is checked
+// for being done, in long-running loops.
+const memoryCtxCheckInterval = 1024
+
 // Memory is an in memory implementation for Cache.
 // It is not distributed, keys are stored in memory,
 // only for current instance.
 // It relies upon Freecache package.
 type Memory struct {
-	client  *freecache.Cache
-	memSize int64         // memory size in bytes
-	mu      *sync.RWMutex // concurrency semaphore used for xconf adapter.
+	client        *freecache.Cache
+	memSize       int64                                // memory size in bytes
+	mu            *sync.RWMutex                        // concurrency semaphore used for xconf adapter.
+	onReconfigure []func(oldMemSize, newMemSize int64) // callbacks executed after reinitialization.
 }
 
 // NewMemory initializes a new Memory instance.
@@ -46,25 +58,132 @@ func NewMemory(memSize int) *Memory {
 // Save stores the given key-value with expiration period into cache.
 // An expiration period equal to 0 (NoExpire) means no expiration.
 // A negative expiration period triggers deletion of key.
-// It returns an error if the key could not be saved.
+// It returns an error if the key could not be saved, or the context's error, if it is done.
 //
 // Additional relaying package notes:
 // If the key is larger than 65535 or value is larger than 1/1024 of the cache size,
 // the entry will not be written to the cache.
 // Items can be evicted when cache is full.
 func (cache *Memory) Save(
-	_ context.Context,
+	ctx context.Context,
 	key string,
 	value []byte,
 	expire time.Duration,
 ) error {
-	if expire < 0 { // delete the key
-		cache.rLock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	cache.rLock()
+	err := cache.client.Set([]byte(key), value, memoryExpireSeconds(expire))
+	cache.rUnlock()
+
+	return err
+}
+
+// SaveMany stores the given items into cache, see Save.
+// It returns an error if any of the items could not be saved, or the context's error, if it is done.
+func (cache *Memory) SaveMany(ctx context.Context, items map[string]Item) error {
+	for key, item := range items {
+		if err := cache.Save(ctx, key, item.Value, item.Expire); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadMany returns the values of given keys from cache (keys not found are missing from the returned map).
+// Returned error is nil, unless the context is done.
+func (cache *Memory) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(keys))
+	cache.rLock()
+	for _, key := range keys {
+		if value, err := cache.client.Get([]byte(key)); err == nil {
+			values[key] = value
+		}
+	}
+	cache.rUnlock()
+
+	return values, nil
+}
+
+// Delete deletes the given key from cache.
+// Returned error is nil, unless the context is done.
+func (cache *Memory) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cache.rLock()
+	_ = cache.client.Del([]byte(key))
+	cache.rUnlock()
+
+	return nil
+}
+
+// DeleteMany deletes the given keys from cache.
+// Returned error is nil, unless the context is done.
+func (cache *Memory) DeleteMany(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cache.rLock()
+	for _, key := range keys {
 		_ = cache.client.Del([]byte(key))
-		cache.rUnlock()
+	}
+	cache.rUnlock()
+
+	return nil
+}
 
-		return nil
+// Has returns true if the given key exists in cache. The value is not copied, and hits / misses are not counted.
+// Returned error is nil, unless the context is done.
+func (cache *Memory) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
 	}
+
+	cache.rLock()
+	err := cache.client.PeekFn([]byte(key), func([]byte) error { return nil })
+	cache.rUnlock()
+
+	return err == nil, nil
+}
+
+// Touch sets the given expiration period to an existing key.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns false if the key does not exist. Returned error is nil, unless the context is done.
+func (cache *Memory) Touch(ctx context.Context, key string, expire time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	if expire < 0 {
+		return cache.client.Del([]byte(key)), nil
+	}
+	err := cache.client.Touch([]byte(key), memoryExpireSeconds(expire))
+	if errors.Is(err, freecache.ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// memoryExpireSeconds converts given (non-negative) expiration period to seconds, as Freecache expects.
+func memoryExpireSeconds(expire time.Duration) int {
 	expireSeconds := int(expire.Seconds())
 	if expire > 0 && expireSeconds == 0 {
 		// convert expire < 1s to 1s as Freecache expects seconds, and 0 means no expiration.
@@ -72,16 +191,41 @@ func (cache *Memory) Save(
 		expireSeconds = 1
 	}
 
+	return expireSeconds
+}
+
+// SaveKeepTTL stores the given value for a key, keeping key's remaining time to live (rounded up to seconds).
+// A key which does not exist is saved as with NoExpire.
+// It returns an error if the key could not be saved, or the context's error, if it is done.
+//
+// Note: key's expiration moment is looked up before the value is set, a concurrent Save of the key,
+// in between, can have its expiration period overwritten.
+func (cache *Memory) SaveKeepTTL(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	cache.rLock()
-	err := cache.client.Set([]byte(key), value, expireSeconds)
-	cache.rUnlock()
+	defer cache.rUnlock()
 
-	return err
+	expireSeconds := 0
+	_, expireAt, err := cache.client.GetWithExpiration([]byte(key))
+	if err == nil && expireAt > 0 {
+		// Freecache works with seconds; an almost expired key is kept for 1 more second.
+		expireSeconds = max(int(int64(expireAt)-time.Now().Unix()), 1)
+	}
+
+	return cache.client.Set([]byte(key), value, expireSeconds)
 }
 
 // Load returns a key's value from cache, or an error if something bad happened.
 // If the key is not found, ErrNotFound is returned.
-func (cache *Memory) Load(_ context.Context, key string) ([]byte, error) {
+// If the context is done, its error is returned.
+func (cache *Memory) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	cache.rLock()
 	value, err := cache.client.Get([]byte(key))
 	cache.rUnlock()
@@ -93,10 +237,14 @@ func (cache *Memory) Load(_ context.Context, key string) ([]byte, error) {
 	return value, err
 }
 
-// TTL returns a key's remaining time to live. Error is always nil.
+// TTL returns a key's remaining time to live. Error is nil, unless the context is done.
 // If the key is not found, a negative TTL is returned.
 // If the key has no expiration, 0 (NoExpire) is returned.
-func (cache *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
+func (cache *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
 	cache.rLock()
 	ttl, err := cache.client.TTL([]byte(key))
 	cache.rUnlock()
@@ -105,15 +253,19 @@ func (cache *Memory) TTL(_ context.Context, key string) (time.Duration, error) {
 		return -1, nil
 	}
 
-	return time.Duration(ttl), err
+	return time.Duration(ttl) * time.Second, err
 }
 
 // Stats returns statistics about memory cache.
-// Returned error is always nil and can be safely disregarded.
-func (cache *Memory) Stats(_ context.Context) (Stats, error) {
+// Returned error is nil, unless the context is done.
+// The used memory is estimated upon a sample of entries, see [Stats] for more details.
+func (cache *Memory) Stats(ctx context.Context) (Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return Stats{}, err
+	}
+
 	cache.rLock()
 	stats := Stats{
-		Memory:    cache.memSize,
 		MaxMemory: cache.memSize,
 		Hits:      cache.client.HitCount(),
 		Misses:    cache.client.MissCount(),
@@ -121,11 +273,155 @@ func (cache *Memory) Stats(_ context.Context) (Stats, error) {
 		Expired:   cache.client.ExpiredCount(),
 		Evicted:   cache.client.EvacuateCount(),
 	}
+	stats.Memory = cache.usedMemory(stats.Keys)
 	cache.rUnlock()
 
 	return stats, nil
 }
 
+// usedMemory estimates the memory occupied by given no. of entries, as their count multiplied
+// by the average size of (at most memoryStatsSampleSize) iterated entries.
+func (cache *Memory) usedMemory(entriesCnt int64) int64 {
+	if entriesCnt <= 0 {
+		return 0
+	}
+
+	var (
+		iter        = cache.client.NewIterator()
+		sampled     int64
+		sampledSize int64
+	)
+	for sampled < memoryStatsSampleSize {
+		entry := iter.Next()
+		if entry == nil {
+			break
+		}
+		sampled++
+		sampledSize += memoryEntryHeaderSize + int64(len(entry.Key)) + int64(len(entry.Value))
+	}
+	if sampled == 0 {
+		return 0
+	}
+
+	used := entriesCnt * sampledSize / sampled
+	if used > cache.memSize {
+		used = cache.memSize
+	}
+
+	return used
+}
+
+// DeletePrefix deletes all keys starting with given prefix.
+// It returns the number of deleted keys. Returned error is nil, unless the context is done
+// (the context is checked periodically, while iterating).
+//
+// Note: all cache entries are iterated in order to find the matching keys.
+func (cache *Memory) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	// collect keys first, as deleting while iterating can make the iterator skip entries.
+	var (
+		keys        [][]byte
+		prefixBytes = []byte(prefix)
+		iter        = cache.client.NewIterator()
+		iterated    int
+	)
+	for entry := iter.Next(); entry != nil; entry = iter.Next() {
+		if iterated++; iterated%memoryCtxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if bytes.HasPrefix(entry.Key, prefixBytes) {
+			keys = append(keys, entry.Key)
+		}
+	}
+
+	deleted := 0
+	for i, key := range keys {
+		if (i+1)%memoryCtxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return deleted, err
+			}
+		}
+		if cache.client.Del(key) {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Scan returns a batch of keys starting with given prefix, and the cursor to continue the iteration with.
+// See [Scanner] for more details.
+//
+// Note: the cursor stores the position of the last examined entry, and, as freecache's iterator
+// cannot be positioned, resuming an iteration implies re-iterating (and skipping) all the already
+// examined entries. A call costs O(position + count), and so, a full iteration over N entries
+// costs O(N²/count): on large caches, use a count close to the no. of entries, or avoid
+// paginated scans. Entries added / removed in the meantime may shift positions, and so,
+// keys may be returned multiple times or skipped.
+// The context is checked periodically, while iterating, and its error is returned, if it is done.
+func (cache *Memory) Scan(ctx context.Context, cursor, prefix string, count int) ([]string, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	offset, err := decodeUintCursor(cursor, cursorKindMemory)
+	if err != nil {
+		return nil, "", err
+	}
+	if count <= 0 {
+		count = scanDefaultCount
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	var (
+		keys        []string
+		prefixBytes = []byte(prefix)
+		iter        = cache.client.NewIterator()
+		pos         uint64
+		limit       = offset + uint64(count)
+	)
+	for entry := iter.Next(); entry != nil; entry = iter.Next() {
+		pos++
+		if pos%memoryCtxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, "", err
+			}
+		}
+		if pos <= offset {
+			continue
+		}
+		if bytes.HasPrefix(entry.Key, prefixBytes) {
+			keys = append(keys, string(entry.Key))
+		}
+		if pos == limit {
+			return keys, encodeUintCursor(cursorKindMemory, pos), nil
+		}
+	}
+
+	return keys, "", nil
+}
+
+func (cache *Memory) lock() {
+	if cache.mu != nil {
+		cache.mu.Lock()
+	}
+}
+
+func (cache *Memory) unlock() {
+	if cache.mu != nil {
+		cache.mu.Unlock()
+	}
+}
+
 func (cache *Memory) rLock() {
 	if cache.mu != nil {
 		cache.mu.RLock()
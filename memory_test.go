@@ -20,7 +20,13 @@ const (
 )
 
 func init() {
-	var _ xcache.Cache = (*xcache.Memory)(nil) // test Memory is a Cache
+	var _ xcache.Cache = (*xcache.Memory)(nil)            // test Memory is a Cache
+	var _ xcache.Deleter = (*xcache.Memory)(nil)          // test Memory is a Deleter
+	var _ xcache.ExistenceChecker = (*xcache.Memory)(nil) // test Memory is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Memory)(nil)          // test Memory is a Batcher
+	var _ xcache.BulkDeleter = (*xcache.Memory)(nil)      // test Memory is a BulkDeleter
+	var _ xcache.Toucher = (*xcache.Memory)(nil)          // test Memory is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Memory)(nil)        // test Memory is a TTLKeeper
 }
 
 func TestMemory(t *testing.T) {
@@ -33,7 +39,65 @@ func TestMemory(t *testing.T) {
 	t.Run("key does not exist", testCacheWithNotExistKey(subject))
 	t.Run("delete key", testCacheDeleteKey(subject))
 	t.Run("ttl for not yet expired key", testCacheTTLWithNotYetExpiredKey(subject))
-	t.Run("stats", testCacheStats(subject, freecacheMinMem, freecacheMinMem, "==", true))
+	t.Run("delete prefix", testCacheDeletePrefix(subject))
+	t.Run("scan", testCacheScan(xcache.NewMemory(1))) // separate instance, as concurrent writes can shift positions.
+	t.Run("touch", testCacheTouch(subject))
+	t.Run("save keep ttl", testCacheSaveKeepTTL(subject))
+	t.Run("has", testCacheHas(subject))
+	t.Run("save many & load many", testCacheSaveManyLoadMany(subject))
+	t.Run("delete many", testCacheDeleteMany(subject))
+	t.Run("done context", testCacheWithDoneContext(subject))
+	t.Run("stats", testCacheStats(subject, 1, freecacheMinMem, ">=", true))
+}
+
+func TestMemory_Stats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("used memory is reported", testMemoryStatsUsedMemoryIsReported)
+	t.Run("empty cache has no used memory", testMemoryStatsEmptyCacheHasNoUsedMemory)
+}
+
+func testMemoryStatsUsedMemoryIsReported(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject    = xcache.NewMemory(freecacheMinMem)
+		ctx        = context.Background()
+		value      = []byte("test value")
+		keysNo     = 300
+		entryHdrSz = 24 // Freecache's entry header size
+	)
+	for i := 0; i < keysNo; i++ {
+		key := fmt.Sprintf("test-used-memory-key-%03d", i)
+		err := subject.Save(ctx, key, value, xcache.NoExpire)
+		requireNil(t, err)
+	}
+	expectedMem := int64(keysNo * (entryHdrSz + len("test-used-memory-key-000") + len(value)))
+
+	// act
+	result, err := subject.Stats(ctx)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, expectedMem, result.Memory)
+	assertEqual(t, int64(freecacheMinMem), result.MaxMemory)
+	assertEqual(t, int64(keysNo), result.Keys)
+}
+
+func testMemoryStatsEmptyCacheHasNoUsedMemory(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewMemory(freecacheMinMem)
+
+	// act
+	result, err := subject.Stats(context.Background())
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, int64(0), result.Memory)
+	assertEqual(t, int64(freecacheMinMem), result.MaxMemory)
 }
 
 func BenchmarkMemory_Save(b *testing.B) {
@@ -30,8 +30,13 @@ const (
 // and all items from old freecache instance are copied to the new one. Note: host machine/container needs to have
 // additional to current occupied memory, the new memory size available (until old memory is garbage collected,
 // old memory size is still occupied).
+// See OnReconfigure for being notified about it.
+//
+// Memory size can be given as any integer type, or as a numeric string, too.
+// An invalid value is replaced with the default one, use NewMemoryWithValidConfig to fail on it instead.
+// An invalid configuration reload is disregarded, the current memory size is kept.
 func NewMemoryWithConfig(config xconf.Config) *Memory {
-	mem := config.Get(MemoryCfgKeyMemorySize, memoryCfgDefValueMemorySize).(int)
+	mem, _ := getMemorySize(config)
 
 	cache := NewMemory(mem)
 	cache.mu = new(sync.RWMutex)
@@ -43,6 +48,49 @@ func NewMemoryWithConfig(config xconf.Config) *Memory {
 	return cache
 }
 
+// NewMemoryWithValidConfig is like NewMemoryWithConfig, but it fails on an invalid memory size:
+// a ConfigError is returned, and cache is nil.
+func NewMemoryWithValidConfig(config xconf.Config) (*Memory, error) {
+	if err := ValidateMemoryXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewMemoryWithConfig(config), nil
+}
+
+// getMemorySize returns the memory size taken from a xconf.Config.
+// An invalid value is replaced with the default one, and a ConfigError is returned.
+func getMemorySize(config xconf.Config) (int, error) {
+	r := newXConfReader(config)
+	memSize := readMemorySize(r)
+
+	return memSize, r.Err()
+}
+
+// readMemorySize returns the memory size read by given reader.
+func readMemorySize(r *xconfReader) int {
+	memSize := r.Int(MemoryCfgKeyMemorySize, memoryCfgDefValueMemorySize)
+	if memSize < 0 {
+		r.addErr(MemoryCfgKeyMemorySize, errConfigValueRange)
+		memSize = memoryCfgDefValueMemorySize
+	}
+
+	return memSize
+}
+
+// ValidateMemoryXConfig checks the Memory configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for each value which cannot be interpreted,
+// or nil if configuration is valid.
+//
+// NewMemoryWithConfig does not fail on invalid values, defaults are used instead,
+// so you may want to call this function at your application's startup, in order to fail fast
+// (or use NewMemoryWithValidConfig).
+func ValidateMemoryXConfig(config xconf.Config) error {
+	_, err := getMemorySize(config)
+
+	return err
+}
+
 // onConfigChange is a callback to be registered to xconf.DefaultConfig that knows to reload configuration.
 // In case "xcache.memory.memsizebytes" config is changed, the Memory is reinitialized with the new memory size,
 // and all items from old freecache instance are copied to the new one.
@@ -51,7 +99,10 @@ func (cache *Memory) onConfigChange(config xconf.Config, changedKeys ...string)
 	memSize := 0
 	for _, changedKey := range changedKeys {
 		if changedKey == MemoryCfgKeyMemorySize {
-			memSize = config.Get(MemoryCfgKeyMemorySize, memoryCfgDefValueMemorySize).(int)
+			var err error
+			if memSize, err = getMemorySize(config); err != nil { // keep current memory size.
+				return
+			}
 			memSize = getRealMemorySize(memSize)
 
 			break
@@ -62,28 +113,47 @@ func (cache *Memory) onConfigChange(config xconf.Config, changedKeys ...string)
 	}
 
 	cache.mu.Lock()
-	if memSize != int(cache.memSize) {
-		// note 1: stats will be reset on the new client.
-		// note 2: during this code execution memory occupied will be oldMemorySize + newMemorySize,
-		// so machine needs to have to this memory available.
-		// note 3: not tested performance if a large number of keys needs to be copied.
-
-		newClient := freecache.NewCache(memSize)
-		oldClient := cache.client
-
-		// copy old cache items in new cache
-		iter := oldClient.NewIterator()
-		for {
-			entry := iter.Next()
-			if entry == nil {
-				break
-			}
-			if ttl, err := oldClient.TTL(entry.Key); err == nil {
-				_ = newClient.Set(entry.Key, entry.Value, int(ttl))
-			}
+	if memSize == int(cache.memSize) {
+		cache.mu.Unlock()
+
+		return
+	}
+
+	// note 1: stats will be reset on the new client.
+	// note 2: during this code execution memory occupied will be oldMemorySize + newMemorySize,
+	// so machine needs to have to this memory available.
+	// note 3: not tested performance if a large number of keys needs to be copied.
+
+	newClient := freecache.NewCache(memSize)
+	oldClient := cache.client
+
+	// copy old cache items in new cache
+	iter := oldClient.NewIterator()
+	for {
+		entry := iter.Next()
+		if entry == nil {
+			break
+		}
+		if ttl, err := oldClient.TTL(entry.Key); err == nil {
+			_ = newClient.Set(entry.Key, entry.Value, int(ttl))
 		}
-		cache.client = newClient
-		cache.memSize = int64(memSize)
 	}
+	oldMemSize := cache.memSize
+	cache.client = newClient
+	cache.memSize = int64(memSize)
+	onReconfigure := cache.onReconfigure
 	cache.mu.Unlock()
+
+	for _, callback := range onReconfigure {
+		callback(oldMemSize, int64(memSize))
+	}
+}
+
+// OnReconfigure registers a callback to be executed after the Memory was reinitialized
+// with a new memory size, as a result of a config change (see NewMemoryWithConfig).
+// It can be used to log, or emit metrics about reconfiguration.
+func (cache *Memory) OnReconfigure(callback func(oldMemSize, newMemSize int64)) {
+	cache.lock()
+	cache.onReconfigure = append(cache.onReconfigure, callback)
+	cache.unlock()
 }
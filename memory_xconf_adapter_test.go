@@ -24,6 +24,7 @@ func TestMemory_withXConf(t *testing.T) {
 
 	t.Run("expected config is changed", testMemoryWithXConfConfigIsChanged)
 	t.Run("expected config is not changed", testMemoryWithXConfConfigIsNotChanged)
+	t.Run("invalid config is disregarded", testMemoryWithXConfInvalidConfigIsDisregarded)
 }
 
 func testMemoryWithXConfConfigIsChanged(t *testing.T) {
@@ -51,12 +52,18 @@ func testMemoryWithXConfConfigIsChanged(t *testing.T) {
 			configLoader,
 			xconf.DefaultConfigWithReloadInterval(time.Second),
 		)
-		subject   = xcache.NewMemoryWithConfig(config)
-		keyPrefix = "test-xconf-key-"
-		value     = []byte("test value")
-		ctx       = context.Background()
+		subject         = xcache.NewMemoryWithConfig(config)
+		keyPrefix       = "test-xconf-key-"
+		value           = []byte("test value")
+		ctx             = context.Background()
+		reconfiguredCnt uint32
 	)
 	defer config.Close()
+	subject.OnReconfigure(func(oldMemSize, newMemSize int64) {
+		atomic.AddUint32(&reconfiguredCnt, 1)
+		assertEqual(t, memSize1, oldMemSize)
+		assertEqual(t, memSize2, newMemSize)
+	})
 	// save some keys
 	for i := 0; i < 10; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
@@ -86,6 +93,7 @@ func testMemoryWithXConfConfigIsChanged(t *testing.T) {
 	assertEqual(t, int64(30), stats1.Keys)
 	assertEqual(t, memSize2, stats2.MaxMemory)
 	assertEqual(t, int64(20), stats2.Keys) // 10 expired
+	assertEqual(t, uint32(1), atomic.LoadUint32(&reconfiguredCnt))
 	for i := 0; i < 20; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
 		_, err := subject.Load(ctx, key)
@@ -124,12 +132,16 @@ func testMemoryWithXConfConfigIsNotChanged(t *testing.T) {
 			configLoader,
 			xconf.DefaultConfigWithReloadInterval(time.Second),
 		)
-		subject   = xcache.NewMemoryWithConfig(config)
-		keyPrefix = "test-xconf-key-"
-		value     = []byte("test value")
-		ctx       = context.Background()
+		subject         = xcache.NewMemoryWithConfig(config)
+		keyPrefix       = "test-xconf-key-"
+		value           = []byte("test value")
+		ctx             = context.Background()
+		reconfiguredCnt uint32
 	)
 	defer config.Close()
+	subject.OnReconfigure(func(int64, int64) {
+		atomic.AddUint32(&reconfiguredCnt, 1)
+	})
 	// save some keys
 	for i := 0; i < 10; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
@@ -159,6 +171,7 @@ func testMemoryWithXConfConfigIsNotChanged(t *testing.T) {
 	assertEqual(t, int64(30), stats1.Keys)
 	assertEqual(t, memSize, stats2.MaxMemory)
 	assertEqual(t, int64(30), stats2.Keys) // 10 expired, but freecache does not deletes them until load
+	assertEqual(t, uint32(0), atomic.LoadUint32(&reconfiguredCnt))
 	for i := 0; i < 20; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
 		_, err := subject.Load(ctx, key)
@@ -171,6 +184,127 @@ func testMemoryWithXConfConfigIsNotChanged(t *testing.T) {
 	}
 }
 
+func testMemoryWithXConfInvalidConfigIsDisregarded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig  uint32
+		memSize       int64 = 1024 * 1024 // 1 Mb
+		initialConfig       = map[string]any{
+			xcache.MemoryCfgKeyMemorySize: "1048576",
+		}
+		configReloaded = map[string]any{
+			xcache.MemoryCfgKeyMemorySize: "1 Mb",
+		}
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			if atomic.LoadUint32(&reloadConfig) == 1 {
+				return configReloaded, nil
+			}
+
+			return initialConfig, nil
+		})
+		subject = xcache.NewMemoryWithConfig(config)
+		ctx     = context.Background()
+	)
+
+	// act
+	stats1, _ := subject.Stats(ctx)
+	atomic.AddUint32(&reloadConfig, 1)
+	waitReload()
+	stats2, _ := subject.Stats(ctx)
+
+	// assert
+	assertEqual(t, memSize, stats1.MaxMemory)
+	assertEqual(t, memSize, stats2.MaxMemory)
+}
+
+// newReloadingConfig returns a xconf.DefaultConfig which reloads, at a short interval, the configuration
+// given loader returns, closed at the end of the test, and a function which waits for the configuration
+// to be reloaded (and its observers to be notified) after the function is called.
+func newReloadingConfig(
+	t *testing.T,
+	loader func() (map[string]any, error),
+) (*xconf.DefaultConfig, func()) {
+	t.Helper()
+
+	var loads uint32
+	config, err := xconf.NewDefaultConfig(
+		xconf.LoaderFunc(func() (map[string]any, error) {
+			atomic.AddUint32(&loads, 1)
+
+			return loader()
+		}),
+		xconf.DefaultConfigWithReloadInterval(5*time.Millisecond),
+	)
+	requireNil(t, err)
+	t.Cleanup(func() { _ = config.Close() })
+
+	waitReload := func() {
+		t.Helper()
+
+		// a load started after this call is followed by notifying observers, before the next load.
+		target := atomic.LoadUint32(&loads) + 2
+		for deadline := time.Now().Add(5 * time.Second); atomic.LoadUint32(&loads) < target; {
+			if time.Now().After(deadline) {
+				t.Fatal("configuration was not reloaded")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	return config, waitReload
+}
+
+func TestValidateMemoryXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing value",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name:   "expected type",
+			config: xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, 1024),
+		},
+		{
+			name:   "numeric string",
+			config: xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, "1024"),
+		},
+		{
+			name:            "invalid value",
+			config:          xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, "1 Kb"),
+			expectedErrKeys: []string{xcache.MemoryCfgKeyMemorySize},
+		},
+		{
+			name:            "negative value",
+			config:          xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, -1024),
+			expectedErrKeys: []string{xcache.MemoryCfgKeyMemorySize},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateMemoryXConfig(test.config)
+			cache, resultCtorErr := xcache.NewMemoryWithValidConfig(test.config)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, cache != nil)
+		})
+	}
+}
+
 func TestMemory_withXConf_concurrency(t *testing.T) {
 	t.Parallel()
 
@@ -249,6 +383,6 @@ func ExampleMemory_withXConf() {
 	}
 
 	// Output:
-	// mem=1M maxMem=1M memUsage=100.00% hits=0 misses=0 hitRate=100.00% keys=0 expired=0 evicted=0
-	// mem=5M maxMem=5M memUsage=100.00% hits=0 misses=0 hitRate=100.00% keys=0 expired=0 evicted=0
+	// mem=0B maxMem=1M memUsage=0.00% hits=0 misses=0 hitRate=100.00% keys=0 expired=0 evicted=0
+	// mem=0B maxMem=5M memUsage=0.00% hits=0 misses=0 hitRate=100.00% keys=0 expired=0 evicted=0
 }
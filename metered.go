@@ -0,0 +1,200 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Metered is a Cache decorator which counts the bytes transferred to / from the decorated cache.
+// Counters are reported through Stats (see Stats.BytesRead / Stats.BytesWritten), and can be used
+// to compute the network cost of a distributed cache, or to validate that compression
+// of values actually reduces the transfer volume (decorate the cache which stores compressed values).
+//
+// Only keys' and values' payload is counted, protocol overhead is not taken into account:
+// - Save counts the key and the value as written bytes;
+// - Load counts the key as written bytes, and the returned value as read bytes;
+// - TTL counts the key as written bytes.
+//
+// It also counts operations' errors, by their class (see ClassifyError), and, for operations having
+// a context with deadline, the time spent in them and the remaining time until deadline (the budget)
+// they started with, so that, during an incident, it can be proven whether the cache made the requests slow
+// (see ContributeStats). The time spent in cache operations by a request can be found out with CacheTime.
+type Metered struct {
+	cache          Cache
+	bytesRead      int64
+	bytesWritten   int64
+	errTimeout     int64
+	errCanceled    int64
+	errUnavailable int64
+	errBackend     int64
+	deadlineOps    int64 // no. of operations having a deadline.
+	deadlineSpent  int64 // time spent in operations having a deadline, in nanoseconds.
+	deadlineBudget int64 // remaining time until deadline operations started with, in nanoseconds.
+	deadlineOver   int64 // no. of operations which spent more than half of their budget.
+}
+
+// NewMetered instantiates a new Metered which decorates given cache.
+func NewMetered(cache Cache) *Metered {
+	return &Metered{cache: cache}
+}
+
+// Save stores the given key-value with expiration period into decorated cache,
+// and counts the sent bytes.
+func (cache *Metered) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	atomic.AddInt64(&cache.bytesWritten, int64(len(key)+len(value)))
+	start := time.Now()
+	err := cache.cache.Save(ctx, key, value, expire)
+	cache.observe(ctx, start, err)
+
+	return err
+}
+
+// Load returns a key's value from decorated cache, and counts the sent / received bytes.
+func (cache *Metered) Load(ctx context.Context, key string) ([]byte, error) {
+	atomic.AddInt64(&cache.bytesWritten, int64(len(key)))
+	start := time.Now()
+	value, err := cache.cache.Load(ctx, key)
+	cache.observe(ctx, start, err)
+	if len(value) > 0 {
+		atomic.AddInt64(&cache.bytesRead, int64(len(value)))
+	}
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, and counts the sent bytes.
+func (cache *Metered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	atomic.AddInt64(&cache.bytesWritten, int64(len(key)))
+	start := time.Now()
+	ttl, err := cache.cache.TTL(ctx, key)
+	cache.observe(ctx, start, err)
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics, having the bytes read / written populated.
+func (cache *Metered) Stats(ctx context.Context) (Stats, error) {
+	stats, err := cache.cache.Stats(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.BytesRead += atomic.LoadInt64(&cache.bytesRead)
+	stats.BytesWritten += atomic.LoadInt64(&cache.bytesWritten)
+
+	return stats, nil
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Metered) Unwrap() Cache {
+	return cache.cache
+}
+
+// ErrorsCount returns the no. of operations which failed with an error of given class.
+func (cache *Metered) ErrorsCount(class ErrorClass) int64 {
+	switch class {
+	case ErrorClassTimeout:
+		return atomic.LoadInt64(&cache.errTimeout)
+	case ErrorClassCanceled:
+		return atomic.LoadInt64(&cache.errCanceled)
+	case ErrorClassUnavailable:
+		return atomic.LoadInt64(&cache.errUnavailable)
+	case ErrorClassBackend:
+		return atomic.LoadInt64(&cache.errBackend)
+	default:
+		return 0
+	}
+}
+
+// ContributeStats reports the no. of failed operations, by error class, as "metered.errors.<class>" metrics,
+// and, for operations having a context with deadline:
+// - their no., as "metered.deadline.ops" metric;
+// - the time spent in them, in seconds, as "metered.deadline.spent" metric;
+// - the remaining time until deadline they started with, in seconds, as "metered.deadline.budget" metric
+// (spent / budget is the fraction of the requests' deadlines consumed by the cache);
+// - the no. of operations which spent more than half of their budget, as "metered.deadline.over_half" metric.
+func (cache *Metered) ContributeStats(add func(name string, value float64)) {
+	for _, class := range [...]ErrorClass{
+		ErrorClassTimeout, ErrorClassCanceled, ErrorClassUnavailable, ErrorClassBackend,
+	} {
+		add("metered.errors."+string(class), float64(cache.ErrorsCount(class)))
+	}
+	add("metered.deadline.ops", float64(atomic.LoadInt64(&cache.deadlineOps)))
+	add("metered.deadline.spent", time.Duration(atomic.LoadInt64(&cache.deadlineSpent)).Seconds())
+	add("metered.deadline.budget", time.Duration(atomic.LoadInt64(&cache.deadlineBudget)).Seconds())
+	add("metered.deadline.over_half", float64(atomic.LoadInt64(&cache.deadlineOver)))
+}
+
+// observe counts the error of an operation started at given moment, and attributes the time spent in it.
+func (cache *Metered) observe(ctx context.Context, start time.Time, err error) {
+	switch ClassifyError(err) {
+	case ErrorClassTimeout:
+		atomic.AddInt64(&cache.errTimeout, 1)
+	case ErrorClassCanceled:
+		atomic.AddInt64(&cache.errCanceled, 1)
+	case ErrorClassUnavailable:
+		atomic.AddInt64(&cache.errUnavailable, 1)
+	case ErrorClassBackend:
+		atomic.AddInt64(&cache.errBackend, 1)
+	}
+
+	spent := time.Since(start)
+	if tracker, ok := ctx.Value(cacheTimeCtxKey{}).(*cacheTime); ok {
+		atomic.AddInt64(&tracker.spent, int64(spent))
+		atomic.AddInt64(&tracker.ops, 1)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		budget := max(deadline.Sub(start), 0)
+		atomic.AddInt64(&cache.deadlineOps, 1)
+		atomic.AddInt64(&cache.deadlineSpent, int64(spent))
+		atomic.AddInt64(&cache.deadlineBudget, int64(budget))
+		if 2*spent > budget {
+			atomic.AddInt64(&cache.deadlineOver, 1)
+		}
+	}
+}
+
+// cacheTimeCtxKey is the context key the time spent in cache operations is tracked under.
+type cacheTimeCtxKey struct{}
+
+// cacheTime holds the time spent in cache operations, and their no.
+type cacheTime struct {
+	spent int64
+	ops   int64
+}
+
+// WithCacheTime returns a copy of given context which tracks the time spent in cache operations
+// performed with it (or with contexts derived from it) through Metered caches. See CacheTime.
+// It's meant to be called at the beginning of a request.
+func WithCacheTime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheTimeCtxKey{}, new(cacheTime))
+}
+
+// CacheTime returns the time spent in cache operations performed with given context, and their no.
+// The context must have been obtained with WithCacheTime, otherwise 0 values are returned.
+//
+// Example:
+//
+//	ctx = xcache.WithCacheTime(ctx)
+//	// ... handle request
+//	if spent, ops := xcache.CacheTime(ctx); spent > slowThreshold {
+//		logger.Warn(xlog.MessageKey, "slow cache", "spent", spent, "ops", ops)
+//	}
+func CacheTime(ctx context.Context) (time.Duration, int) {
+	tracker, ok := ctx.Value(cacheTimeCtxKey{}).(*cacheTime)
+	if !ok {
+		return 0, 0
+	}
+
+	return time.Duration(atomic.LoadInt64(&tracker.spent)), int(atomic.LoadInt64(&tracker.ops))
+}
@@ -0,0 +1,141 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Metered)(nil)            // test Metered is a Cache
+	var _ xcache.StatsContributor = (*xcache.Metered)(nil) // test Metered is a StatsContributor
+}
+
+func TestMetered(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bytes are counted", testMeteredBytesAreCounted)
+	t.Run("stats error is returned", testMeteredStatsErrIsReturned)
+	t.Run("errors and deadlines are attributed", testMeteredErrorsAndDeadlinesAreAttributed)
+}
+
+func testMeteredBytesAreCounted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewMetered(cache)
+		ctx     = context.Background()
+		key     = "test-metered-key"         // 16 bytes
+		missKey = "test-metered-missing-key" // 24 bytes
+		value   = []byte("test value")       // 10 bytes
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	resultValue, errLoad := subject.Load(ctx, key)
+	_, errLoadMiss := subject.Load(ctx, missKey)
+	_, errTTL := subject.TTL(ctx, key)
+	resultStats, errStats := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, resultValue)
+	assertTrue(t, errors.Is(errLoadMiss, xcache.ErrNotFound))
+	assertNil(t, errTTL)
+	assertNil(t, errStats)
+	assertEqual(t, int64(10), resultStats.BytesRead)
+	assertEqual(t, int64(16+10+16+24+16), resultStats.BytesWritten)
+	assertEqual(t, int64(1), resultStats.Keys)
+}
+
+func testMeteredStatsErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewMetered(cache)
+		expectedErr = errors.New("intentionally triggered Stats error")
+	)
+	cache.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		return xcache.Stats{}, expectedErr
+	})
+	_ = subject.Save(context.Background(), "test-metered-key", []byte("test value"), time.Minute)
+
+	// act
+	resultStats, resultErr := subject.Stats(context.Background())
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, xcache.Stats{}, resultStats)
+	assertEqual(t, 1, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.StatsCallsCount())
+}
+
+func testMeteredErrorsAndDeadlinesAreAttributed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache        = new(xcache.Mock)
+		subject      = xcache.NewMetered(cache)
+		ctx          = xcache.WithCacheTime(context.Background())
+		latency      = 30 * time.Millisecond
+		saveErrs     = []error{context.DeadlineExceeded, context.Canceled, xcache.ErrOverloaded, errors.New("ERR")}
+		saveCallsCnt int
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		err := saveErrs[saveCallsCnt]
+		saveCallsCnt++
+
+		return err
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		time.Sleep(latency)
+
+		return nil, xcache.ErrNotFound
+	})
+	ctxDeadline, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	// act
+	for range saveErrs {
+		_ = subject.Save(ctx, "test-metered-key", []byte("test value"), time.Minute)
+	}
+	_, errLoad := subject.Load(ctxDeadline, "test-metered-key")
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) {
+		metrics[name] = value
+	})
+
+	// assert
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertEqual(t, int64(1), subject.ErrorsCount(xcache.ErrorClassTimeout))
+	assertEqual(t, int64(1), subject.ErrorsCount(xcache.ErrorClassCanceled))
+	assertEqual(t, int64(1), subject.ErrorsCount(xcache.ErrorClassUnavailable))
+	assertEqual(t, int64(1), subject.ErrorsCount(xcache.ErrorClassBackend))
+	assertEqual(t, int64(0), subject.ErrorsCount(xcache.ErrorClassNone))
+	assertEqual(t, 1.0, metrics["metered.errors.timeout"])
+	assertEqual(t, 1.0, metrics["metered.errors.backend"])
+	assertEqual(t, 1.0, metrics["metered.deadline.ops"])
+	assertTrue(t, metrics["metered.deadline.spent"] >= latency.Seconds())
+	assertTrue(t, metrics["metered.deadline.budget"] > 0.04 && metrics["metered.deadline.budget"] <= 0.05)
+	assertEqual(t, 1.0, metrics["metered.deadline.over_half"])
+	spent, ops := xcache.CacheTime(ctx)
+	assertTrue(t, spent >= latency)
+	assertEqual(t, 5, ops)
+	spent, ops = xcache.CacheTime(context.Background())
+	assertEqual(t, time.Duration(0), spent)
+	assertEqual(t, 0, ops)
+}
@@ -13,14 +13,30 @@ import (
 
 // Mock is a mock to be used in UT.
 type Mock struct {
-	saveCallsCnt  uint32
-	saveCallback  func(context.Context, string, []byte, time.Duration) error
-	loadCallsCnt  uint32
-	loadCallback  func(context.Context, string) ([]byte, error)
-	ttlCallsCnt   uint32
-	ttlCallback   func(context.Context, string) (time.Duration, error)
-	statsCallsCnt uint32
-	statsCallback func(context.Context) (Stats, error)
+	saveCallsCnt         uint32
+	saveCallback         func(context.Context, string, []byte, time.Duration) error
+	loadCallsCnt         uint32
+	loadCallback         func(context.Context, string) ([]byte, error)
+	ttlCallsCnt          uint32
+	ttlCallback          func(context.Context, string) (time.Duration, error)
+	statsCallsCnt        uint32
+	statsCallback        func(context.Context) (Stats, error)
+	saveManyCallsCnt     uint32
+	saveManyCallback     func(context.Context, map[string]Item) error
+	loadManyCallsCnt     uint32
+	loadManyCallback     func(context.Context, []string) (map[string][]byte, error)
+	deleteCallsCnt       uint32
+	deleteCallback       func(context.Context, string) error
+	deleteManyCallsCnt   uint32
+	deleteManyCallback   func(context.Context, ...string) error
+	hasCallsCnt          uint32
+	hasCallback          func(context.Context, string) (bool, error)
+	deletePrefixCallsCnt uint32
+	deletePrefixCallback func(context.Context, string) (int, error)
+	scanCallsCnt         uint32
+	scanCallback         func(context.Context, string, string, int) ([]string, string, error)
+	touchCallsCnt        uint32
+	touchCallback        func(context.Context, string, time.Duration) (bool, error)
 }
 
 // Save mock logic...
@@ -68,6 +84,86 @@ func (mock *Mock) Stats(ctx context.Context) (Stats, error) {
 	return Stats{}, nil
 }
 
+// SaveMany mock logic...
+func (mock *Mock) SaveMany(ctx context.Context, items map[string]Item) error {
+	atomic.AddUint32(&mock.saveManyCallsCnt, 1)
+	if mock.saveManyCallback != nil {
+		return mock.saveManyCallback(ctx, items)
+	}
+
+	return nil
+}
+
+// LoadMany mock logic...
+func (mock *Mock) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	atomic.AddUint32(&mock.loadManyCallsCnt, 1)
+	if mock.loadManyCallback != nil {
+		return mock.loadManyCallback(ctx, keys)
+	}
+
+	return map[string][]byte{}, nil
+}
+
+// Delete mock logic...
+func (mock *Mock) Delete(ctx context.Context, key string) error {
+	atomic.AddUint32(&mock.deleteCallsCnt, 1)
+	if mock.deleteCallback != nil {
+		return mock.deleteCallback(ctx, key)
+	}
+
+	return nil
+}
+
+// Has mock logic...
+func (mock *Mock) Has(ctx context.Context, key string) (bool, error) {
+	atomic.AddUint32(&mock.hasCallsCnt, 1)
+	if mock.hasCallback != nil {
+		return mock.hasCallback(ctx, key)
+	}
+
+	return false, nil
+}
+
+// DeleteMany mock logic...
+func (mock *Mock) DeleteMany(ctx context.Context, keys ...string) error {
+	atomic.AddUint32(&mock.deleteManyCallsCnt, 1)
+	if mock.deleteManyCallback != nil {
+		return mock.deleteManyCallback(ctx, keys...)
+	}
+
+	return nil
+}
+
+// DeletePrefix mock logic...
+func (mock *Mock) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	atomic.AddUint32(&mock.deletePrefixCallsCnt, 1)
+	if mock.deletePrefixCallback != nil {
+		return mock.deletePrefixCallback(ctx, prefix)
+	}
+
+	return 0, nil
+}
+
+// Scan mock logic...
+func (mock *Mock) Scan(ctx context.Context, cursor, prefix string, count int) ([]string, string, error) {
+	atomic.AddUint32(&mock.scanCallsCnt, 1)
+	if mock.scanCallback != nil {
+		return mock.scanCallback(ctx, cursor, prefix, count)
+	}
+
+	return nil, "", nil
+}
+
+// Touch mock logic...
+func (mock *Mock) Touch(ctx context.Context, key string, expire time.Duration) (bool, error) {
+	atomic.AddUint32(&mock.touchCallsCnt, 1)
+	if mock.touchCallback != nil {
+		return mock.touchCallback(ctx, key, expire)
+	}
+
+	return false, nil
+}
+
 // SetSaveCallback sets the given callback to be executed inside Save() method.
 // You can inject yourself to make assertions upon passed parameter(s) this way
 // and/or control the returned value.
@@ -142,6 +238,142 @@ func (mock *Mock) SetStatsCallback(callback func(context.Context) (Stats, error)
 	mock.statsCallback = callback
 }
 
+// SetSaveManyCallback sets the given callback to be executed inside SaveMany() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetSaveManyCallback(func(ctx context.Context, items map[string]xcache.Item) error {
+//		if len(items) != 2 {
+//			t.Error("expected ...")
+//		}
+//
+//		return nil
+//	})
+func (mock *Mock) SetSaveManyCallback(callback func(context.Context, map[string]Item) error) {
+	mock.saveManyCallback = callback
+}
+
+// SetLoadManyCallback sets the given callback to be executed inside LoadMany() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetLoadManyCallback(func(ctx context.Context, keys []string) (map[string][]byte, error) {
+//		if len(keys) != 2 {
+//			t.Error("expected ...")
+//		}
+//
+//		return map[string][]byte{"expected-key": []byte("expected value")}, nil
+//	})
+func (mock *Mock) SetLoadManyCallback(callback func(context.Context, []string) (map[string][]byte, error)) {
+	mock.loadManyCallback = callback
+}
+
+// SetDeleteCallback sets the given callback to be executed inside Delete() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetDeleteCallback(func(ctx context.Context, key string) error {
+//		if key != "expected-key" {
+//			t.Error("expected ...")
+//		}
+//
+//		return nil
+//	})
+func (mock *Mock) SetDeleteCallback(callback func(context.Context, string) error) {
+	mock.deleteCallback = callback
+}
+
+// SetHasCallback sets the given callback to be executed inside Has() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetHasCallback(func(ctx context.Context, key string) (bool, error) {
+//		if key != "expected-key" {
+//			t.Error("expected ...")
+//		}
+//
+//		return true, nil
+//	})
+func (mock *Mock) SetHasCallback(callback func(context.Context, string) (bool, error)) {
+	mock.hasCallback = callback
+}
+
+// SetDeleteManyCallback sets the given callback to be executed inside DeleteMany() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetDeleteManyCallback(func(ctx context.Context, keys ...string) error {
+//		if len(keys) != 2 {
+//			t.Error("expected ...")
+//		}
+//
+//		return nil
+//	})
+func (mock *Mock) SetDeleteManyCallback(callback func(context.Context, ...string) error) {
+	mock.deleteManyCallback = callback
+}
+
+// SetDeletePrefixCallback sets the given callback to be executed inside DeletePrefix() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetDeletePrefixCallback(func(ctx context.Context, prefix string) (int, error) {
+//		if prefix != "expected-prefix" {
+//			t.Error("expected ...")
+//		}
+//
+//		return 3, nil
+//	})
+func (mock *Mock) SetDeletePrefixCallback(callback func(context.Context, string) (int, error)) {
+	mock.deletePrefixCallback = callback
+}
+
+// SetScanCallback sets the given callback to be executed inside Scan() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetScanCallback(func(ctx context.Context, cursor, prefix string, count int) ([]string, string, error) {
+//		if prefix != "expected-prefix" {
+//			t.Error("expected ...")
+//		}
+//
+//		return []string{"expected-prefix-key"}, "", nil
+//	})
+func (mock *Mock) SetScanCallback(callback func(context.Context, string, string, int) ([]string, string, error)) {
+	mock.scanCallback = callback
+}
+
+// SetTouchCallback sets the given callback to be executed inside Touch() method.
+// You can inject yourself to make assertions upon passed parameter(s) this way
+// and/or control the returned value.
+//
+// Usage example:
+//
+//	mock.SetTouchCallback(func(ctx context.Context, key string, expire time.Duration) (bool, error) {
+//		if expire != 10*time.Minute {
+//			t.Error("expected ...")
+//		}
+//
+//		return true, nil
+//	})
+func (mock *Mock) SetTouchCallback(callback func(context.Context, string, time.Duration) (bool, error)) {
+	mock.touchCallback = callback
+}
+
 // SaveCallsCount returns the no. of times Save() method was called.
 func (mock *Mock) SaveCallsCount() int {
 	return int(atomic.LoadUint32(&mock.saveCallsCnt))
@@ -161,3 +393,43 @@ func (mock *Mock) TTLCallsCount() int {
 func (mock *Mock) StatsCallsCount() int {
 	return int(atomic.LoadUint32(&mock.statsCallsCnt))
 }
+
+// SaveManyCallsCount returns the no. of times SaveMany() method was called.
+func (mock *Mock) SaveManyCallsCount() int {
+	return int(atomic.LoadUint32(&mock.saveManyCallsCnt))
+}
+
+// LoadManyCallsCount returns the no. of times LoadMany() method was called.
+func (mock *Mock) LoadManyCallsCount() int {
+	return int(atomic.LoadUint32(&mock.loadManyCallsCnt))
+}
+
+// DeleteCallsCount returns the no. of times Delete() method was called.
+func (mock *Mock) DeleteCallsCount() int {
+	return int(atomic.LoadUint32(&mock.deleteCallsCnt))
+}
+
+// HasCallsCount returns the no. of times Has() method was called.
+func (mock *Mock) HasCallsCount() int {
+	return int(atomic.LoadUint32(&mock.hasCallsCnt))
+}
+
+// DeleteManyCallsCount returns the no. of times DeleteMany() method was called.
+func (mock *Mock) DeleteManyCallsCount() int {
+	return int(atomic.LoadUint32(&mock.deleteManyCallsCnt))
+}
+
+// DeletePrefixCallsCount returns the no. of times DeletePrefix() method was called.
+func (mock *Mock) DeletePrefixCallsCount() int {
+	return int(atomic.LoadUint32(&mock.deletePrefixCallsCnt))
+}
+
+// ScanCallsCount returns the no. of times Scan() method was called.
+func (mock *Mock) ScanCallsCount() int {
+	return int(atomic.LoadUint32(&mock.scanCallsCnt))
+}
+
+// TouchCallsCount returns the no. of times Touch() method was called.
+func (mock *Mock) TouchCallsCount() int {
+	return int(atomic.LoadUint32(&mock.touchCallsCnt))
+}
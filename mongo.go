@@ -0,0 +1,174 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// MongoDocument is a key-value, as stored into a MongoDB collection:
+// {_id: key, value: binary, expires_at: date}.
+type MongoDocument struct {
+	// Key is the document's _id.
+	Key string
+	// Value is the (binary) value.
+	Value []byte
+	// ExpiresAt is the expiration moment, stored in the TTL indexed "expires_at" field.
+	// Zero value means no expiration (the field is not set).
+	ExpiresAt time.Time
+}
+
+// MongoCollStats holds the collStats statistics of a MongoDB collection a Mongo cache reports.
+type MongoCollStats struct {
+	// Count is the no. of documents.
+	Count int64
+	// Size is the uncompressed size of the documents, in bytes.
+	Size int64
+}
+
+// MongoCollection is the subset of MongoDB collection operations a Mongo cache is built upon.
+//
+// Subpackage xcachemongo provides a mongo-go-driver based implementation.
+type MongoCollection interface {
+	// FindOne returns the document with given key, or ErrNotFound, if it does not exist.
+	FindOne(ctx context.Context, key string) (MongoDocument, error)
+	// ReplaceOne replaces (or inserts, if it does not exist - upsert) the document.
+	ReplaceOne(ctx context.Context, doc MongoDocument) error
+	// DeleteOne deletes the document with given key.
+	DeleteOne(ctx context.Context, key string) error
+	// CreateTTLIndex creates, if it does not exist, the TTL index on "expires_at" field
+	// (with expireAfterSeconds 0).
+	CreateTTLIndex(ctx context.Context) error
+	// CollStats returns the collection's statistics (collStats command).
+	CollStats(ctx context.Context) (MongoCollStats, error)
+}
+
+// Mongo is a Cache backed by a MongoDB collection, for teams whose only shared datastore is MongoDB.
+// Expiration relies on a TTL index on the "expires_at" field (see CreateTTLIndex); as MongoDB deletes
+// expired documents periodically (every 60 seconds, by default), expired documents not deleted yet are not returned.
+//
+// Example:
+//
+//	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+//	if err != nil {
+//		return err
+//	}
+//	cache := xcache.NewMongo(xcachemongo.NewCollection(client.Database("app").Collection("cache")))
+//	if err := cache.CreateTTLIndex(ctx); err != nil {
+//		return err
+//	}
+type Mongo struct {
+	coll   MongoCollection
+	hits   int64
+	misses int64
+}
+
+// NewMongo instantiates a new Mongo cache upon given collection.
+func NewMongo(coll MongoCollection) *Mongo {
+	return &Mongo{coll: coll}
+}
+
+// CreateTTLIndex creates the TTL index on "expires_at" field, if it does not exist.
+func (cache *Mongo) CreateTTLIndex(ctx context.Context) error {
+	return cache.coll.CreateTTLIndex(ctx)
+}
+
+// Save stores (inserts, or replaces) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *Mongo) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.coll.DeleteOne(ctx, key)
+	}
+
+	doc := MongoDocument{Key: key, Value: value}
+	if expire > 0 {
+		doc.ExpiresAt = time.Now().Add(expire)
+	}
+	if doc.Value == nil {
+		doc.Value = []byte{}
+	}
+
+	return cache.coll.ReplaceOne(ctx, doc)
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+func (cache *Mongo) Load(ctx context.Context, key string) ([]byte, error) {
+	doc, err := cache.find(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return doc.Value, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the read fails.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *Mongo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	doc, err := cache.find(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if doc.ExpiresAt.IsZero() {
+		return NoExpire, nil
+	}
+
+	return time.Until(doc.ExpiresAt), nil
+}
+
+// Stats returns statistics about Mongo cache: the no. of documents and their size (as Memory),
+// from collStats (including the expired documents not deleted yet), and the hits and misses
+// since the cache was instantiated.
+// It returns an error if the statistics could not be retrieved.
+func (cache *Mongo) Stats(ctx context.Context) (Stats, error) {
+	collStats, err := cache.coll.CollStats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Keys:   collStats.Count,
+		Memory: collStats.Size,
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *Mongo) Delete(ctx context.Context, key string) error {
+	return cache.coll.DeleteOne(ctx, key)
+}
+
+// find returns the (not expired) document with given key, or ErrNotFound.
+func (cache *Mongo) find(ctx context.Context, key string) (MongoDocument, error) {
+	doc, err := cache.coll.FindOne(ctx, key)
+	if err != nil {
+		return MongoDocument{}, err
+	}
+	if !doc.ExpiresAt.IsZero() && !doc.ExpiresAt.After(time.Now()) {
+		return MongoDocument{}, ErrNotFound
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,217 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Mongo)(nil)              // test Mongo is a Cache
+	var _ xcache.Deleter = (*xcache.Mongo)(nil)            // test Mongo is a Deleter
+	var _ xcache.MongoCollection = (*mongoCollection)(nil) // test mongoCollection is a MongoCollection
+}
+
+func TestMongo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testMongoSaveLoadTTLDelete)
+	t.Run("expired documents are not returned", testMongoExpiredDocumentsAreNotReturned)
+	t.Run("collection errors are returned", testMongoCollectionErrorsAreReturned)
+}
+
+func testMongoSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		coll    = newMongoCollection()
+		subject = xcache.NewMongo(coll)
+		ctx     = context.Background()
+		key     = "test-mongo-key"
+		value   = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.CreateTTLIndex(ctx))
+	assertTrue(t, coll.ttlIndexed)
+
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	assertTrue(t, coll.Doc(key).ExpiresAt.IsZero())
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Keys: 1, Memory: int64(len(key) + len(value)), Hits: 1}, stats)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	requireNil(t, subject.Delete(ctx, key))
+	stats, err = subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+}
+
+func testMongoExpiredDocumentsAreNotReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		coll    = newMongoCollection()
+		subject = xcache.NewMongo(coll)
+		ctx     = context.Background()
+		key     = "test-mongo-expired-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), 10*time.Millisecond))
+
+	// act
+	time.Sleep(15 * time.Millisecond) // expired, but not deleted by TTL monitor yet.
+	_, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+}
+
+func testMongoCollectionErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		coll    = newMongoCollection()
+		subject = xcache.NewMongo(coll)
+		ctx     = context.Background()
+		key     = "test-mongo-error-key"
+		errDB   = errors.New("server selection timeout")
+	)
+	coll.err = errDB
+
+	// act
+	errIndex := subject.CreateTTLIndex(ctx)
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+	errDelete := subject.Delete(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errIndex, errDB))
+	assertTrue(t, errors.Is(errSave, errDB))
+	assertTrue(t, errors.Is(errLoad, errDB))
+	assertTrue(t, errors.Is(errTTL, errDB))
+	assertTrue(t, errors.Is(errStats, errDB))
+	assertTrue(t, errors.Is(errDelete, errDB))
+	stats, _ := xcache.NewMongo(newMongoCollection()).Stats(ctx)
+	assertEqual(t, xcache.Stats{}, stats)
+}
+
+// mongoCollection is an in memory xcache.MongoCollection.
+type mongoCollection struct {
+	docs       map[string]xcache.MongoDocument
+	ttlIndexed bool
+	err        error
+	mu         sync.Mutex
+}
+
+func newMongoCollection() *mongoCollection {
+	return &mongoCollection{docs: make(map[string]xcache.MongoDocument)}
+}
+
+func (c *mongoCollection) FindOne(_ context.Context, key string) (xcache.MongoDocument, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return xcache.MongoDocument{}, c.err
+	}
+	doc, found := c.docs[key]
+	if !found {
+		return xcache.MongoDocument{}, xcache.ErrNotFound
+	}
+
+	return doc, nil
+}
+
+func (c *mongoCollection) ReplaceOne(_ context.Context, doc xcache.MongoDocument) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.docs[doc.Key] = doc
+
+	return nil
+}
+
+func (c *mongoCollection) DeleteOne(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	delete(c.docs, key)
+
+	return nil
+}
+
+func (c *mongoCollection) CreateTTLIndex(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.ttlIndexed = true
+
+	return nil
+}
+
+func (c *mongoCollection) CollStats(context.Context) (xcache.MongoCollStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return xcache.MongoCollStats{}, c.err
+	}
+	stats := xcache.MongoCollStats{Count: int64(len(c.docs))}
+	for key, doc := range c.docs {
+		stats.Size += int64(len(key) + len(doc.Value))
+	}
+
+	return stats, nil
+}
+
+func (c *mongoCollection) Doc(key string) xcache.MongoDocument {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.docs[key]
+}
@@ -8,6 +8,7 @@ package xcache
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/actforgood/xerr"
@@ -18,7 +19,9 @@ import (
 // A key is loaded from the first cache it is found in
 // (in the order caches were provided in the constructor).
 type Multi struct {
-	caches []Cache
+	caches       []Cache
+	backfillPool *WorkerPool
+	guard        *backfillGuard
 }
 
 // NewMulti initializes a new Multi instance.
@@ -28,6 +31,24 @@ func NewMulti(caches ...Cache) Multi {
 	}
 }
 
+// WithBackfillPool returns a copy of the Multi cache which saves upfront keys found in deeper caches
+// asynchronously, through given pool (SingleHop mode): a Load returns after a single hop to the cache the key is
+// found in, and the backfill does not spawn a goroutine per Load. If the pool's queue is full, backfill is skipped.
+//
+// Backfills are ordered relative to the writes performed through the returned Multi cache, so an older value
+// never overwrites a newer one: a backfill is skipped if the key was written (saved / deleted) since it was loaded.
+// For this, writes hold a per key lock (keys are distributed to a fixed no. of locks)
+// while they are performed in all caches (DeletePrefix holds all the locks).
+// Values written by other instances, directly into deeper caches, can be protected by enveloping them
+// with a monotonic version (see NewVersionedValue): a backfill is skipped for upfront caches
+// which already hold the same, or a newer, version.
+func (cache Multi) WithBackfillPool(pool *WorkerPool) Multi {
+	cache.backfillPool = pool
+	cache.guard = newBackfillGuard()
+
+	return cache
+}
+
 // Save stores the given key-value with expiration period into all caches.
 // An expiration period equal to 0 (NoExpire) means no expiration.
 // A negative expiration period triggers deletion of key.
@@ -39,33 +60,42 @@ func (cache Multi) Save(
 	value []byte,
 	expire time.Duration,
 ) error {
-	var mErr *xerr.MultiError
+	var mErr multiErrors
+	locked := cache.guard.lockWrite(key)
 	for _, c := range cache.caches {
 		if err := c.Save(ctx, key, value, expire); err != nil {
-			mErr = mErr.Add(err)
+			mErr.add(err)
 		}
 	}
+	cache.guard.unlockWrite(locked)
 
-	return mErr.ErrOrNil()
+	return mErr.errOrNil()
 }
 
 // Load returns a key's value from the first cache it finds it.
-// If the key is found in a deeper cache, key is tried to be saved also in upfront cache(s).
+// If the key is found in a deeper cache, key is tried to be saved also in upfront cache(s)
+// (asynchronously, if a backfill pool was set, see WithBackfillPool).
 // Note: if a cache returns an error, but the next cache returns the value,
 // the value and nil error will be returned (method aims to be successful).
 // If the key is not found in any of the caches, ErrNotFound is returned.
 // If the key is not found in any of the caches, and any cache gave an error,
 // that error will be returned.
 func (cache Multi) Load(ctx context.Context, key string) ([]byte, error) {
-	var mErr *xerr.MultiError
+	var (
+		mErr multiErrors
+		gen  = cache.guard.generation(key)
+	)
 	for idx, c := range cache.caches {
 		val, err := c.Load(ctx, key)
 		if err == nil {
 			if idx > 0 { // save upfront the key
-				if ttl, errTTL := c.TTL(ctx, key); errTTL == nil {
-					for i := idx - 1; i >= 0; i-- {
-						_ = cache.caches[i].Save(ctx, key, val, ttl)
-					}
+				if cache.backfillPool != nil {
+					bgCtx := context.WithoutCancel(ctx)
+					cache.backfillPool.Submit(func() {
+						cache.backfill(bgCtx, idx, key, val, gen)
+					})
+				} else {
+					cache.backfill(ctx, idx, key, val, gen)
 				}
 			}
 
@@ -74,10 +104,10 @@ func (cache Multi) Load(ctx context.Context, key string) ([]byte, error) {
 		if errors.Is(err, ErrNotFound) {
 			continue
 		}
-		mErr = mErr.Add(err)
+		mErr.add(err)
 	}
 
-	err := mErr.ErrOrNil()
+	err := mErr.errOrNil()
 	if err == nil {
 		return nil, ErrNotFound
 	}
@@ -85,6 +115,123 @@ func (cache Multi) Load(ctx context.Context, key string) ([]byte, error) {
 	return nil, err
 }
 
+// SaveMany stores the given items into all caches.
+// Caches which do not implement Batcher get the items saved one by one.
+// It returns an error if the items could not be saved (in any of the
+// caches - note, that the items can end up being saved in other cache(s)).
+func (cache Multi) SaveMany(ctx context.Context, items map[string]Item) error {
+	var (
+		mErr   multiErrors
+		locked []int
+	)
+	if cache.guard != nil {
+		keys := make([]string, 0, len(items))
+		for key := range items {
+			keys = append(keys, key)
+		}
+		locked = cache.guard.lockWrite(keys...)
+	}
+	for _, c := range cache.caches {
+		if err := saveMany(ctx, c, items); err != nil {
+			mErr.add(err)
+		}
+	}
+	cache.guard.unlockWrite(locked)
+
+	return mErr.errOrNil()
+}
+
+// LoadMany returns the values of given keys, each one from the first cache it is found in
+// (a cache is asked only for the keys not found in upfront caches).
+// Caches which do not implement Batcher get the keys loaded one by one.
+// Keys found in a deeper cache are tried to be saved also in upfront cache(s), like in Load
+// (this implies a TTL call per key, a backfill pool is recommended, see WithBackfillPool).
+// Keys not found are missing from the returned map.
+// Note: if a cache returns an error, but all the keys are found in the other caches,
+// nil error will be returned (method aims to be successful).
+// If any key is not found, and any cache gave an error, the found values and that error will be returned.
+func (cache Multi) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	var (
+		mErr    multiErrors
+		values  = make(map[string][]byte, len(keys))
+		missing = keys
+		gens    map[string]uint64
+	)
+	if cache.guard != nil {
+		gens = make(map[string]uint64, len(keys))
+		for _, key := range keys {
+			gens[key] = cache.guard.generation(key)
+		}
+	}
+	for idx, c := range cache.caches {
+		if len(missing) == 0 {
+			break
+		}
+		found, err := loadMany(ctx, c, missing)
+		if err != nil {
+			mErr.add(err)
+		}
+		if len(found) == 0 {
+			continue
+		}
+		for key, val := range found {
+			values[key] = val
+		}
+		if idx > 0 { // save upfront the keys
+			if cache.backfillPool != nil {
+				bgCtx := context.WithoutCancel(ctx)
+				idx := idx // capture range variable
+				cache.backfillPool.Submit(func() {
+					for key, val := range found {
+						cache.backfill(bgCtx, idx, key, val, gens[key])
+					}
+				})
+			} else {
+				for key, val := range found {
+					cache.backfill(ctx, idx, key, val, gens[key])
+				}
+			}
+		}
+		stillMissing := make([]string, 0, len(missing)-len(found))
+		for _, key := range missing {
+			if _, ok := found[key]; !ok {
+				stillMissing = append(stillMissing, key)
+			}
+		}
+		missing = stillMissing
+	}
+
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	return values, mErr.errOrNil()
+}
+
+// backfill saves given key-value, found in the cache at given index, into upfront caches.
+// The backfill is skipped if the key was written since given generation (see backfillGuard),
+// and, for a versioned value, for upfront caches which already hold the same, or a newer, version.
+func (cache Multi) backfill(ctx context.Context, idx int, key string, val []byte, gen uint64) {
+	ttl, errTTL := cache.caches[idx].TTL(ctx, key)
+	if errTTL != nil {
+		return
+	}
+	if !cache.guard.lockBackfill(key, gen) {
+		return
+	}
+	defer cache.guard.unlockBackfill(key)
+
+	version := ValueVersion(val)
+	for i := idx - 1; i >= 0; i-- {
+		if version > 0 && cache.guard != nil {
+			if current, err := cache.caches[i].Load(ctx, key); err == nil && ValueVersion(current) >= version {
+				continue
+			}
+		}
+		_ = cache.caches[i].Save(ctx, key, val, ttl)
+	}
+}
+
 // TTL returns a key's remaining time to live from the first cache it finds it.
 // If the key is not found (in any of the caches), a negative TTL is returned.
 // If the key has no expiration, 0 (NoExpire) is returned.
@@ -93,41 +240,317 @@ func (cache Multi) Load(ctx context.Context, key string) ([]byte, error) {
 // If the key is not found in any of the caches, and any cache gave an error,
 // that error will be returned.
 func (cache Multi) TTL(ctx context.Context, key string) (time.Duration, error) {
-	var mErr *xerr.MultiError
+	var mErr multiErrors
 	for _, c := range cache.caches {
 		if ttl, err := c.TTL(ctx, key); err != nil {
-			mErr = mErr.Add(err)
+			mErr.add(err)
 		} else if ttl >= 0 {
 			return ttl, nil
 		}
 	}
 
-	return -1, mErr.ErrOrNil()
+	return -1, mErr.errOrNil()
+}
+
+// Has returns true if the given key exists in any of the caches.
+// Caches which do not implement ExistenceChecker are checked with TTL.
+// Note: if a cache returns an error, but the next cache has the key,
+// true and nil error will be returned (method aims to be successful).
+// If the key is not found in any of the caches, and any cache gave an error,
+// that error will be returned.
+func (cache Multi) Has(ctx context.Context, key string) (bool, error) {
+	var mErr multiErrors
+	for _, c := range cache.caches {
+		if found, err := hasKey(ctx, c, key); err != nil {
+			mErr.add(err)
+		} else if found {
+			return true, nil
+		}
+	}
+
+	return false, mErr.errOrNil()
 }
 
 // Stats returns statistics about memory cache, or an error if something bad happens within any of the caches.
 // Returned statistics are just summed up for all contained caches.
 func (cache Multi) Stats(ctx context.Context) (Stats, error) {
-	var mErr *xerr.MultiError
-	var mStats Stats
+	var (
+		mErr   multiErrors
+		mStats Stats
+	)
 	for _, c := range cache.caches {
 		if stats, err := c.Stats(ctx); err != nil {
-			mErr = mErr.Add(err)
+			mErr.add(err)
 		} else {
-			mStats.Memory += stats.Memory
-			mStats.MaxMemory += stats.MaxMemory
-			mStats.Hits += stats.Hits
-			mStats.Misses += stats.Misses
-			mStats.Keys += stats.Keys
-			mStats.Expired += stats.Expired
-			mStats.Evicted += stats.Evicted
+			mStats.add(stats)
 		}
 	}
 
-	err := mErr.ErrOrNil()
+	err := mErr.errOrNil()
 	if err != nil {
 		return Stats{}, err
 	}
 
 	return mStats, nil
 }
+
+// LayerStats holds the statistics of a cache (layer) of a Multi cache, and the moment they were collected at.
+type LayerStats struct {
+	Stats
+	// CollectedAt is the moment the statistics were collected at.
+	CollectedAt time.Time
+	// Err is the error the statistics could not be collected with, if any.
+	Err error
+}
+
+// MultiStats holds the statistics of all the caches (layers) of a Multi cache, collected within a single window.
+type MultiStats struct {
+	// Stats holds the aggregated statistics of the layers which were collected successfully.
+	Stats
+	// Layers holds the statistics of each layer, in the order caches were provided in the constructor.
+	Layers []LayerStats
+}
+
+// Skew returns the period between the first and the last successful collection of layers' statistics.
+func (ms MultiStats) Skew() time.Duration {
+	var first, last time.Time
+	for _, layer := range ms.Layers {
+		if layer.Err != nil {
+			continue
+		}
+		if first.IsZero() || layer.CollectedAt.Before(first) {
+			first = layer.CollectedAt
+		}
+		if layer.CollectedAt.After(last) {
+			last = layer.CollectedAt
+		}
+	}
+
+	return last.Sub(first)
+}
+
+// StatsSnapshot returns the statistics of all caches, collected in parallel, within given window, so
+// the aggregated statistics are coherent (unlike Stats, which collects them sequentially, at different moments).
+// Each layer's statistics are annotated with the moment they were collected at.
+// A layer whose statistics are not collected within the window gets a context.DeadlineExceeded error
+// (without waiting for it). A non-positive window means the statistics are collected with given context only.
+// It returns an error if the statistics of any layer could not be collected, the snapshot holding the collected ones.
+func (cache Multi) StatsSnapshot(ctx context.Context, window time.Duration) (MultiStats, error) {
+	if window > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, window)
+		defer cancel()
+	}
+
+	type result struct {
+		idx   int
+		layer LayerStats
+	}
+	results := make(chan result, len(cache.caches)) // buffered, late layers do not block.
+	for idx, c := range cache.caches {
+		go func(idx int, c Cache) {
+			stats, err := c.Stats(ctx)
+			results <- result{idx: idx, layer: LayerStats{Stats: stats, CollectedAt: time.Now(), Err: err}}
+		}(idx, c)
+	}
+
+	snapshot := MultiStats{Layers: make([]LayerStats, len(cache.caches))}
+collect:
+	for pending := len(cache.caches); pending > 0; pending-- {
+		select {
+		case res := <-results:
+			snapshot.Layers[res.idx] = res.layer
+		case <-ctx.Done():
+			for idx, layer := range snapshot.Layers {
+				if layer.CollectedAt.IsZero() {
+					snapshot.Layers[idx].Err = ctx.Err()
+				}
+			}
+
+			break collect
+		}
+	}
+
+	var mErr multiErrors
+	for _, layer := range snapshot.Layers {
+		if layer.Err != nil {
+			mErr.add(layer.Err)
+		} else {
+			snapshot.Stats.add(layer.Stats)
+		}
+	}
+
+	return snapshot, mErr.errOrNil()
+}
+
+// ContributeStats reports, if a backfill pool is configured (see WithBackfillPool), the no. of backfills
+// waiting for a worker, as "multi.backfill.queued" metric, and the no. of skipped backfills, as the pool's queue
+// was full, as "multi.backfill.dropped" metric (the pool's statistics, if it's shared by multiple caches).
+// The metrics of the layers are collected by CollectDetailedStats.
+func (cache Multi) ContributeStats(add func(name string, value float64)) {
+	if cache.backfillPool == nil {
+		return
+	}
+	poolStats := cache.backfillPool.Stats()
+	add("multi.backfill.queued", float64(poolStats.QueueDepth))
+	add("multi.backfill.dropped", float64(poolStats.Dropped))
+}
+
+// Delete deletes the given key from all caches.
+// Caches which do not implement Deleter get the key saved with a negative expiration period.
+// It returns an error if the key could not be deleted (from any of the
+// caches - note, that the key can end up being deleted from other cache(s)).
+func (cache Multi) Delete(ctx context.Context, key string) error {
+	var mErr multiErrors
+	locked := cache.guard.lockWrite(key)
+	for _, c := range cache.caches {
+		if err := deleteKey(ctx, c, key); err != nil {
+			mErr.add(err)
+		}
+	}
+	cache.guard.unlockWrite(locked)
+
+	return mErr.errOrNil()
+}
+
+// DeleteMany deletes the given keys from all caches.
+// Caches which do not implement BulkDeleter get the keys deleted one by one.
+// It returns an error if the keys could not be deleted (from any of the
+// caches - note, that the keys can end up being deleted from other cache(s)).
+func (cache Multi) DeleteMany(ctx context.Context, keys ...string) error {
+	var mErr multiErrors
+	locked := cache.guard.lockWrite(keys...)
+	for _, c := range cache.caches {
+		if err := deleteKeys(ctx, c, keys...); err != nil {
+			mErr.add(err)
+		}
+	}
+	cache.guard.unlockWrite(locked)
+
+	return mErr.errOrNil()
+}
+
+// DeletePrefix deletes all keys starting with given prefix from all caches.
+// It returns the total number of keys deleted from all caches, or an error
+// if deletion failed in any of the caches (note, that keys can end up being deleted
+// in other cache(s)).
+// A cache that does not implement PrefixDeleter results in an [errors.ErrUnsupported] error.
+func (cache Multi) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	var (
+		mErr    multiErrors
+		deleted int
+		locked  = cache.guard.lockWriteAll()
+	)
+	defer cache.guard.unlockWrite(locked)
+	for _, c := range cache.caches {
+		pd, ok := c.(PrefixDeleter)
+		if !ok {
+			mErr.add(errors.ErrUnsupported)
+
+			continue
+		}
+		n, err := pd.DeletePrefix(ctx, prefix)
+		deleted += n
+		if err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return deleted, mErr.errOrNil()
+}
+
+// Touch sets the given expiration period to a key, in all caches.
+// It returns true if the key exists in any of the caches, or an error
+// if touching failed in any of the caches (note, that the key can end up being touched
+// in other cache(s)).
+// A cache that does not implement Toucher results in an [errors.ErrUnsupported] error.
+func (cache Multi) Touch(ctx context.Context, key string, expire time.Duration) (bool, error) {
+	var (
+		mErr    multiErrors
+		touched bool
+		locked  = cache.guard.lockWrite(key)
+	)
+	defer cache.guard.unlockWrite(locked)
+	for _, c := range cache.caches {
+		toucher, ok := c.(Toucher)
+		if !ok {
+			mErr.add(errors.ErrUnsupported)
+
+			continue
+		}
+		found, err := toucher.Touch(ctx, key, expire)
+		touched = touched || found
+		if err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return touched, mErr.errOrNil()
+}
+
+// SaveKeepTTL stores the given value for a key into all caches, keeping key's remaining time to live
+// in each of them, see SaveKeepTTL function.
+// It returns an error if the key could not be saved (in any of the
+// caches - note, that the key can end up being saved in other cache(s)).
+func (cache Multi) SaveKeepTTL(ctx context.Context, key string, value []byte) error {
+	var mErr multiErrors
+	locked := cache.guard.lockWrite(key)
+	for _, c := range cache.caches {
+		if err := SaveKeepTTL(ctx, c, key, value); err != nil {
+			mErr.add(err)
+		}
+	}
+	cache.guard.unlockWrite(locked)
+
+	return mErr.errOrNil()
+}
+
+// multiMaxErrors is the maximum no. of errors a Multi operation keeps, further errors are only counted.
+const multiMaxErrors = 8
+
+// multiErrors aggregates the errors of a Multi operation.
+// At most multiMaxErrors errors are kept, further errors are only counted.
+// It does not allocate as long as no more than one error occurs (the error is returned as it is),
+// which is the common failure case.
+type multiErrors struct {
+	errs [multiMaxErrors]error // kept errors.
+	cnt  int                   // total no. of errors.
+}
+
+// add adds given error.
+func (mErr *multiErrors) add(err error) {
+	if mErr.cnt < multiMaxErrors {
+		mErr.errs[mErr.cnt] = err
+	}
+	mErr.cnt++
+}
+
+// errOrNil returns nil if no error was added, the error itself if only one error was added,
+// or a [xerr.MultiError] containing kept errors (and the no. of omitted errors, if any), otherwise.
+func (mErr *multiErrors) errOrNil() error {
+	switch mErr.cnt {
+	case 0:
+		return nil
+	case 1:
+		return mErr.errs[0]
+	}
+
+	kept := mErr.cnt
+	if kept > multiMaxErrors {
+		kept = multiMaxErrors
+	}
+	errs := xerr.NewMultiError().Add(mErr.errs[:kept]...)
+	if omitted := mErr.cnt - kept; omitted > 0 {
+		errs = errs.Add(omittedErrors(omitted))
+	}
+
+	return errs
+}
+
+// omittedErrors represents the no. of errors which were omitted from an aggregation.
+type omittedErrors int
+
+// Error returns the string representation of the error.
+func (cnt omittedErrors) Error() string {
+	return strconv.FormatInt(int64(cnt), 10) + " more error(s) omitted"
+}
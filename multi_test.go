@@ -9,14 +9,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/actforgood/xcache"
+	"github.com/actforgood/xerr"
 )
 
 func init() {
-	var _ xcache.Cache = (*xcache.Multi)(nil) // ensure Multi is a Cache
+	var _ xcache.Cache = (*xcache.Multi)(nil)            // ensure Multi is a Cache
+	var _ xcache.Deleter = (*xcache.Multi)(nil)          // ensure Multi is a Deleter
+	var _ xcache.ExistenceChecker = (*xcache.Multi)(nil) // ensure Multi is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Multi)(nil)          // ensure Multi is a Batcher
+	var _ xcache.BulkDeleter = (*xcache.Multi)(nil)      // ensure Multi is a BulkDeleter
+	var _ xcache.Toucher = (*xcache.Multi)(nil)          // ensure Multi is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Multi)(nil)        // ensure Multi is a TTLKeeper
 }
 
 func TestMulti_Save_Load(t *testing.T) {
@@ -25,6 +35,7 @@ func TestMulti_Save_Load(t *testing.T) {
 	t.Run("success - save", testMultiSaveSuccessful)
 	t.Run("error all - save", testMultiSaveAllCachesReturnErr)
 	t.Run("error one - save", testMultiSaveOneCacheReturnsErr)
+	t.Run("error many - save, errors are bounded", testMultiSaveManyCachesReturnErrBounded)
 
 	t.Run("success - load 1", testMultiLoadReturnsValueFoundInFirstCache)
 	t.Run("success - load 2", testMultiLoadReturnsValueFoundInSecondCache)
@@ -32,6 +43,7 @@ func TestMulti_Save_Load(t *testing.T) {
 		"success - load 2, err is ignored for cache 1",
 		testMultiLoadReturnsValueFoundInSecondCacheEvenIfFirstCacheLoadFailed,
 	)
+	t.Run("success - load 2, backfill through pool", testMultiLoadBackfillsThroughPool)
 	t.Run("error all - load", testMultiLoadAllCachesReturnErr)
 	t.Run("error not found - load", testMultiLoadReturnsNotFoundErr)
 }
@@ -132,6 +144,44 @@ func testMultiSaveAllCachesReturnErr(t *testing.T) {
 	assertEqual(t, 1, cache2.SaveCallsCount())
 }
 
+func testMultiSaveManyCachesReturnErrBounded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cachesNo     = 10
+		caches       = make([]xcache.Cache, cachesNo)
+		expectedErrs = make([]error, cachesNo)
+		subject      xcache.Multi
+		ctx          = context.Background()
+	)
+	for i := 0; i < cachesNo; i++ {
+		cache := new(xcache.Mock)
+		err := errors.New("intentionally triggered Save error " + strconv.Itoa(i+1))
+		cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+			return err
+		})
+		caches[i] = cache
+		expectedErrs[i] = err
+	}
+	subject = xcache.NewMulti(caches...)
+
+	// act
+	resultErr := subject.Save(ctx, "test-multi-save-key-fails-bounded", []byte("test value"), time.Minute)
+
+	// assert
+	var mErr *xerr.MultiError
+	if assertTrue(t, errors.As(resultErr, &mErr)) {
+		assertEqual(t, 9, len(mErr.Errors())) // first 8 errors + omitted errors count
+		for i := 0; i < 8; i++ {
+			assertTrue(t, errors.Is(resultErr, expectedErrs[i]))
+		}
+		assertTrue(t, !errors.Is(resultErr, expectedErrs[8]))
+		assertTrue(t, !errors.Is(resultErr, expectedErrs[9]))
+		assertTrue(t, strings.HasSuffix(resultErr.Error(), "2 more error(s) omitted"))
+	}
+}
+
 func testMultiLoadReturnsValueFoundInFirstCache(t *testing.T) {
 	t.Parallel()
 
@@ -309,6 +359,52 @@ func testMultiLoadReturnsNotFoundErr(t *testing.T) {
 	assertEqual(t, 1, cache2.LoadCallsCount())
 }
 
+func testMultiLoadBackfillsThroughPool(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewMulti(cache1, cache2).WithBackfillPool(pool)
+		key     = "test-multi-load-key-backfill-pool"
+		value   = []byte("test value")
+		saved   = make(chan struct{})
+	)
+	cache2.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return value, nil
+	})
+	cache2.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return time.Minute, nil
+	})
+	cache1.SetSaveCallback(func(ctx context.Context, k string, v []byte, exp time.Duration) error {
+		assertNil(t, ctx.Err()) // caller's context cancellation does not affect backfill.
+		assertEqual(t, key, k)
+		assertEqual(t, value, v)
+		assertEqual(t, time.Minute, exp)
+		close(saved)
+
+		return nil
+	})
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	// act
+	resultValue, resultErr := subject.Load(ctx, key)
+	cancelCtx()
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, value, resultValue)
+	select {
+	case <-saved:
+	case <-time.After(time.Second):
+		t.Error("key was not backfilled")
+	}
+	assertNil(t, pool.Close())
+	assertEqual(t, int64(1), pool.Stats().Completed)
+}
+
 func TestMulti_TTL(t *testing.T) {
 	t.Parallel()
 
@@ -517,29 +613,34 @@ func testMultiStatsSuccess(t *testing.T) {
 				Keys:      3,
 				Expired:   4,
 				Evicted:   5,
+				BytesRead: 6,
 			}, nil
 		}
 		statsCallback2 = func(ctxx context.Context) (xcache.Stats, error) {
 			assertEqual(t, ctx, ctxx)
 
 			return xcache.Stats{
-				Memory:    2 * 1024,
-				MaxMemory: 20 * 1024,
-				Hits:      10,
-				Misses:    11,
-				Keys:      12,
-				Expired:   13,
-				Evicted:   14,
+				Memory:       2 * 1024,
+				MaxMemory:    20 * 1024,
+				Hits:         10,
+				Misses:       11,
+				Keys:         12,
+				Expired:      13,
+				Evicted:      14,
+				BytesRead:    15,
+				BytesWritten: 16,
 			}, nil
 		}
 		expectedStats = xcache.Stats{
-			Memory:    3 * 1024,
-			MaxMemory: 30 * 1024,
-			Hits:      11,
-			Misses:    13,
-			Keys:      15,
-			Expired:   17,
-			Evicted:   19,
+			Memory:       3 * 1024,
+			MaxMemory:    30 * 1024,
+			Hits:         11,
+			Misses:       13,
+			Keys:         15,
+			Expired:      17,
+			Evicted:      19,
+			BytesRead:    21,
+			BytesWritten: 16,
 		}
 	)
 	cache1.SetStatsCallback(statsCallback1)
@@ -611,6 +712,442 @@ func testMultiStatsReturnsErr(t *testing.T) {
 	assertEqual(t, 1, cache4.StatsCallsCount())
 }
 
+func TestMulti_StatsSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("layers are collected in parallel", testMultiStatsSnapshotLayersAreCollectedInParallel)
+	t.Run("late layer is not waited for", testMultiStatsSnapshotLateLayerIsNotWaitedFor)
+}
+
+func testMultiStatsSnapshotLayersAreCollectedInParallel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2)
+		ctx     = context.Background()
+		barrier sync.WaitGroup // each layer waits for the other one, so sequential collection would time out.
+	)
+	barrier.Add(2)
+	cache1.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		barrier.Done()
+		barrier.Wait()
+
+		return xcache.Stats{Memory: 1024, Hits: 1, Keys: 3}, nil
+	})
+	cache2.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		barrier.Done()
+		barrier.Wait()
+
+		return xcache.Stats{Memory: 2048, Hits: 10, Keys: 12}, nil
+	})
+
+	// act
+	result, err := subject.StatsSnapshot(ctx, 5*time.Second)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Memory: 3072, Hits: 11, Keys: 15}, result.Stats)
+	if assertEqual(t, 2, len(result.Layers)) {
+		assertEqual(t, xcache.Stats{Memory: 1024, Hits: 1, Keys: 3}, result.Layers[0].Stats)
+		assertEqual(t, xcache.Stats{Memory: 2048, Hits: 10, Keys: 12}, result.Layers[1].Stats)
+		assertTrue(t, !result.Layers[0].CollectedAt.IsZero())
+		assertTrue(t, !result.Layers[1].CollectedAt.IsZero())
+		assertNil(t, result.Layers[0].Err)
+		assertNil(t, result.Layers[1].Err)
+	}
+	assertTrue(t, result.Skew() >= 0 && result.Skew() < 5*time.Second)
+}
+
+func testMultiStatsSnapshotLateLayerIsNotWaitedFor(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2)
+		ctx     = context.Background()
+		release = make(chan struct{})
+	)
+	defer close(release)
+	cache1.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		return xcache.Stats{Keys: 3}, nil
+	})
+	cache2.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		<-release // ignores the context.
+
+		return xcache.Stats{Keys: 12}, nil
+	})
+
+	// act
+	start := time.Now()
+	result, err := subject.StatsSnapshot(ctx, 50*time.Millisecond)
+
+	// assert
+	assertTrue(t, time.Since(start) < time.Second)
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertEqual(t, xcache.Stats{Keys: 3}, result.Stats)
+	if assertEqual(t, 2, len(result.Layers)) {
+		assertNil(t, result.Layers[0].Err)
+		assertTrue(t, errors.Is(result.Layers[1].Err, context.DeadlineExceeded))
+		assertTrue(t, result.Layers[1].CollectedAt.IsZero())
+	}
+	assertEqual(t, time.Duration(0), result.Skew())
+}
+
+func TestMulti_DeletePrefix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMultiDeletePrefixSuccessful)
+	t.Run("error", testMultiDeletePrefixReturnsErr)
+}
+
+func testMultiDeletePrefixSuccessful(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1   = new(xcache.Mock)
+		cache2   = new(xcache.Mock)
+		subject  = xcache.NewMulti(cache1, cache2)
+		prefix   = "test-multi-delete-prefix-"
+		ctx      = context.Background()
+		callback = func(deleted int) func(context.Context, string) (int, error) {
+			return func(ctxx context.Context, p string) (int, error) {
+				assertEqual(t, ctx, ctxx)
+				assertEqual(t, prefix, p)
+
+				return deleted, nil
+			}
+		}
+	)
+	cache1.SetDeletePrefixCallback(callback(2))
+	cache2.SetDeletePrefixCallback(callback(3))
+
+	// act
+	result, resultErr := subject.DeletePrefix(ctx, prefix)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 5, result)
+	assertEqual(t, 1, cache1.DeletePrefixCallsCount())
+	assertEqual(t, 1, cache2.DeletePrefixCallsCount())
+}
+
+func testMultiDeletePrefixReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = struct{ xcache.Cache }{new(xcache.Mock)} // does not implement PrefixDeleter
+		cache3      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2, cache3)
+		prefix      = "test-multi-delete-prefix-err-"
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered DeletePrefix error")
+	)
+	cache1.SetDeletePrefixCallback(func(context.Context, string) (int, error) {
+		return 1, expectedErr
+	})
+	cache3.SetDeletePrefixCallback(func(context.Context, string) (int, error) {
+		return 4, nil
+	})
+
+	// act
+	result, resultErr := subject.DeletePrefix(ctx, prefix)
+
+	// assert
+	if assertNotNil(t, resultErr) {
+		assertTrue(t, errors.Is(resultErr, expectedErr))
+		assertTrue(t, errors.Is(resultErr, errors.ErrUnsupported))
+	}
+	assertEqual(t, 5, result)
+	assertEqual(t, 1, cache1.DeletePrefixCallsCount())
+	assertEqual(t, 1, cache3.DeletePrefixCallsCount())
+}
+
+func TestMulti_Delete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMultiDeleteSuccessful)
+	t.Run("error", testMultiDeleteReturnsErr)
+}
+
+func testMultiDeleteSuccessful(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		mock2   = new(xcache.Mock)
+		cache2  = struct{ xcache.Cache }{mock2} // does not implement Deleter
+		subject = xcache.NewMulti(cache1, cache2)
+		key     = "test-multi-delete-key"
+		ctx     = context.Background()
+	)
+	cache1.SetDeleteCallback(func(_ context.Context, k string) error {
+		assertEqual(t, key, k)
+
+		return nil
+	})
+	mock2.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, key, k)
+		assertNil(t, v)
+		assertTrue(t, exp < 0)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject.Delete(ctx, key)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, cache1.DeleteCallsCount())
+	assertEqual(t, 0, cache1.SaveCallsCount())
+	assertEqual(t, 1, mock2.SaveCallsCount())
+}
+
+func testMultiDeleteReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2)
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered Delete error")
+	)
+	cache1.SetDeleteCallback(func(context.Context, string) error {
+		return expectedErr
+	})
+
+	// act
+	resultErr := subject.Delete(ctx, "test-multi-delete-err-key")
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, 1, cache1.DeleteCallsCount())
+	assertEqual(t, 1, cache2.DeleteCallsCount())
+}
+
+func TestMulti_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMultiDeleteManySuccess)
+	t.Run("error", testMultiDeleteManyReturnsErr)
+}
+
+func testMultiDeleteManySuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		mock2   = new(xcache.Mock)
+		cache2  = struct{ xcache.Cache }{mock2} // does not implement BulkDeleter, nor Deleter
+		subject = xcache.NewMulti(cache1, cache2)
+		keys    = []string{"test-multi-delete-many-key-1", "test-multi-delete-many-key-2"}
+		ctx     = context.Background()
+	)
+	cache1.SetDeleteManyCallback(func(_ context.Context, k ...string) error {
+		assertEqual(t, keys, k)
+
+		return nil
+	})
+	mock2.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		assertTrue(t, exp < 0)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject.DeleteMany(ctx, keys...)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, cache1.DeleteManyCallsCount())
+	assertEqual(t, 2, mock2.SaveCallsCount())
+}
+
+func testMultiDeleteManyReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2)
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered DeleteMany error")
+	)
+	cache1.SetDeleteManyCallback(func(context.Context, ...string) error {
+		return expectedErr
+	})
+
+	// act
+	resultErr := subject.DeleteMany(ctx, "test-multi-delete-many-err-key")
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, 1, cache1.DeleteManyCallsCount())
+	assertEqual(t, 1, cache2.DeleteManyCallsCount())
+}
+
+func TestMulti_Has(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - found in second cache", testMultiHasFoundInSecondCache)
+	t.Run("success - not found", testMultiHasNotFound)
+	t.Run("error", testMultiHasReturnsErr)
+}
+
+func testMultiHasFoundInSecondCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		mock2   = new(xcache.Mock)
+		cache2  = struct{ xcache.Cache }{mock2} // does not implement ExistenceChecker
+		cache3  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2, cache3)
+		key     = "test-multi-has-key"
+		ctx     = context.Background()
+	)
+	mock2.SetTTLCallback(func(_ context.Context, k string) (time.Duration, error) {
+		assertEqual(t, key, k)
+
+		return xcache.NoExpire, nil
+	})
+
+	// act
+	result, resultErr := subject.Has(ctx, key)
+
+	// assert
+	assertNil(t, resultErr)
+	assertTrue(t, result)
+	assertEqual(t, 1, cache1.HasCallsCount())
+	assertEqual(t, 1, mock2.TTLCallsCount())
+	assertEqual(t, 0, cache3.HasCallsCount())
+}
+
+func testMultiHasNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2)
+		ctx     = context.Background()
+	)
+
+	// act
+	result, resultErr := subject.Has(ctx, "test-multi-has-not-found-key")
+
+	// assert
+	assertNil(t, resultErr)
+	assertTrue(t, !result)
+	assertEqual(t, 1, cache1.HasCallsCount())
+	assertEqual(t, 1, cache2.HasCallsCount())
+}
+
+func testMultiHasReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2)
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered Has error")
+	)
+	cache1.SetHasCallback(func(context.Context, string) (bool, error) {
+		return false, expectedErr
+	})
+
+	// act
+	result, resultErr := subject.Has(ctx, "test-multi-has-err-key")
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertTrue(t, !result)
+	assertEqual(t, 1, cache2.HasCallsCount())
+}
+
+func TestMulti_Touch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMultiTouchSuccessful)
+	t.Run("error", testMultiTouchReturnsErr)
+}
+
+func testMultiTouchSuccessful(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2)
+		key     = "test-multi-touch-key"
+		ctx     = context.Background()
+		exp     = time.Minute
+	)
+	cache2.SetTouchCallback(func(_ context.Context, k string, e time.Duration) (bool, error) {
+		assertEqual(t, key, k)
+		assertEqual(t, exp, e)
+
+		return true, nil
+	})
+
+	// act
+	result, resultErr := subject.Touch(ctx, key, exp)
+
+	// assert
+	assertNil(t, resultErr)
+	assertTrue(t, result)
+	assertEqual(t, 1, cache1.TouchCallsCount())
+	assertEqual(t, 1, cache2.TouchCallsCount())
+}
+
+func testMultiTouchReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = struct{ xcache.Cache }{new(xcache.Mock)} // does not implement Toucher
+		cache3      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2, cache3)
+		key         = "test-multi-touch-err-key"
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered Touch error")
+	)
+	cache1.SetTouchCallback(func(context.Context, string, time.Duration) (bool, error) {
+		return false, expectedErr
+	})
+
+	// act
+	result, resultErr := subject.Touch(ctx, key, time.Minute)
+
+	// assert
+	if assertNotNil(t, resultErr) {
+		assertTrue(t, errors.Is(resultErr, expectedErr))
+		assertTrue(t, errors.Is(resultErr, errors.ErrUnsupported))
+	}
+	assertTrue(t, !result)
+	assertEqual(t, 1, cache1.TouchCallsCount())
+	assertEqual(t, 1, cache3.TouchCallsCount())
+}
+
 func BenchmarkMulti_Save(b *testing.B) {
 	cache := xcache.NewMulti(xcache.Nop{}, xcache.Nop{})
 	benchSaveSequential(cache)(b)
@@ -643,6 +1180,45 @@ func BenchmarkMulti_Load_parallel(b *testing.B) {
 	benchLoadParallel(cache)(b)
 }
 
+func BenchmarkMulti_Save_oneError(b *testing.B) {
+	cache1 := new(xcache.Mock)
+	errSave := errors.New("bench error")
+	cache1.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return errSave
+	})
+	cache := xcache.NewMulti(cache1, xcache.Nop{})
+	benchMultiSaveError(cache)(b)
+}
+
+func BenchmarkMulti_Save_allErrors(b *testing.B) {
+	caches := make([]xcache.Cache, 10)
+	errSave := errors.New("bench error")
+	for i := range caches {
+		cache := new(xcache.Mock)
+		cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+			return errSave
+		})
+		caches[i] = cache
+	}
+	cache := xcache.NewMulti(caches...)
+	benchMultiSaveError(cache)(b)
+}
+
+func benchMultiSaveError(cache xcache.Cache) func(b *testing.B) {
+	return func(b *testing.B) {
+		ctx, expire, key, value := getBenchInput()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			if err := cache.Save(ctx, key, value, expire); err == nil {
+				b.Error("expected error")
+			}
+		}
+	}
+}
+
 func BenchmarkMulti_TTL(b *testing.B) {
 	cache := xcache.NewMulti(xcache.Nop{}, xcache.Nop{})
 	benchTTLSequential(cache)(b)
@@ -682,3 +1258,272 @@ func ExampleMulti() {
 	// should output:
 	// Hello Multi Cache
 }
+
+func TestMulti_SaveMany(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", testMultiSaveManySuccess)
+	t.Run("error", testMultiSaveManyReturnsErr)
+}
+
+func testMultiSaveManySuccess(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		mock2   = new(xcache.Mock)
+		cache2  = struct{ xcache.Cache }{mock2} // does not implement Batcher
+		subject = xcache.NewMulti(cache1, cache2)
+		ctx     = context.Background()
+		items   = map[string]xcache.Item{
+			"test-multi-save-many-key-1": {Value: []byte("test value 1"), Expire: time.Minute},
+			"test-multi-save-many-key-2": {Value: []byte("test value 2"), Expire: xcache.NoExpire},
+		}
+	)
+	cache1.SetSaveManyCallback(func(_ context.Context, itms map[string]xcache.Item) error {
+		assertEqual(t, items, itms)
+
+		return nil
+	})
+	mock2.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, items[k].Value, v)
+		assertEqual(t, items[k].Expire, exp)
+
+		return nil
+	})
+
+	// act
+	resultErr := subject.SaveMany(ctx, items)
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, 1, cache1.SaveManyCallsCount())
+	assertEqual(t, 2, mock2.SaveCallsCount())
+}
+
+func testMultiSaveManyReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2)
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered SaveMany error")
+	)
+	cache1.SetSaveManyCallback(func(context.Context, map[string]xcache.Item) error {
+		return expectedErr
+	})
+
+	// act
+	resultErr := subject.SaveMany(ctx, map[string]xcache.Item{"test-multi-save-many-err-key": {}})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, 1, cache1.SaveManyCallsCount())
+	assertEqual(t, 1, cache2.SaveManyCallsCount())
+}
+
+func TestMulti_LoadMany(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - keys found in different caches", testMultiLoadManyFoundInDifferentCaches)
+	t.Run("success - error ignored as all keys are found", testMultiLoadManyErrIgnored)
+	t.Run("error", testMultiLoadManyReturnsErr)
+	t.Run("success - backfill through pool from many caches", testMultiLoadManyBackfillsThroughPoolFromManyCaches)
+}
+
+func testMultiLoadManyBackfillsThroughPoolFromManyCaches(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = xcache.NewMemory(freecacheMinMem)
+		cache2  = xcache.NewMemory(freecacheMinMem)
+		cache3  = xcache.NewMemory(freecacheMinMem)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 2})
+		subject = xcache.NewMulti(cache1, cache2, cache3).WithBackfillPool(pool)
+		keyA    = "test-multi-load-many-backfill-pool-key-a"
+		keyB    = "test-multi-load-many-backfill-pool-key-b"
+		keyC    = "test-multi-load-many-backfill-pool-key-c"
+		value   = []byte("test value")
+		ctx     = context.Background()
+	)
+	requireNil(t, cache2.Save(ctx, keyA, value, time.Minute))
+	requireNil(t, cache2.Save(ctx, keyB, value, time.Minute))
+	requireNil(t, cache3.Save(ctx, keyC, value, time.Minute))
+
+	// act
+	resultValues, resultErr := subject.LoadMany(ctx, []string{keyA, keyB, keyC})
+	assertNil(t, pool.Close()) // wait for backfills to finish.
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, map[string][]byte{keyA: value, keyB: value, keyC: value}, resultValues)
+	for _, key := range []string{keyA, keyB, keyC} {
+		backfilledValue, err := cache1.Load(ctx, key)
+		assertNil(t, err)
+		assertEqual(t, value, backfilledValue)
+	}
+	assertEqual(t, int64(2), pool.Stats().Completed)
+}
+
+func testMultiLoadManyFoundInDifferentCaches(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		mock2   = new(xcache.Mock)
+		cache2  = struct{ xcache.Cache }{mock2} // does not implement Batcher
+		cache3  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2, cache3)
+		key1    = "test-multi-load-many-key-1"
+		key2    = "test-multi-load-many-key-2"
+		key3    = "test-multi-load-many-key-3"
+		value1  = []byte("test value 1")
+		value2  = []byte("test value 2")
+		ctx     = context.Background()
+	)
+	cache1.SetLoadManyCallback(func(_ context.Context, keys []string) (map[string][]byte, error) {
+		assertEqual(t, []string{key1, key2, key3}, keys)
+
+		return map[string][]byte{key1: value1}, nil
+	})
+	mock2.SetLoadCallback(func(_ context.Context, k string) ([]byte, error) {
+		if k == key2 {
+			return value2, nil
+		}
+
+		return nil, xcache.ErrNotFound
+	})
+	mock2.SetTTLCallback(func(_ context.Context, k string) (time.Duration, error) {
+		assertEqual(t, key2, k)
+
+		return time.Minute, nil
+	})
+	cache1.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, key2, k)
+		assertEqual(t, value2, v)
+		assertEqual(t, time.Minute, exp)
+
+		return nil
+	})
+	cache3.SetLoadManyCallback(func(_ context.Context, keys []string) (map[string][]byte, error) {
+		assertEqual(t, []string{key3}, keys)
+
+		return map[string][]byte{}, nil
+	})
+
+	// act
+	resultValues, resultErr := subject.LoadMany(ctx, []string{key1, key2, key3})
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, map[string][]byte{key1: value1, key2: value2}, resultValues)
+	assertEqual(t, 1, cache1.LoadManyCallsCount())
+	assertEqual(t, 2, mock2.LoadCallsCount())
+	assertEqual(t, 1, cache1.SaveCallsCount())
+	assertEqual(t, 1, cache3.LoadManyCallsCount())
+}
+
+func testMultiLoadManyErrIgnored(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		subject = xcache.NewMulti(cache1, cache2)
+		key     = "test-multi-load-many-ignored-err-key"
+		value   = []byte("test value")
+		ctx     = context.Background()
+	)
+	cache1.SetLoadManyCallback(func(context.Context, []string) (map[string][]byte, error) {
+		return nil, errors.New("intentionally triggered LoadMany error")
+	})
+	cache2.SetLoadManyCallback(func(context.Context, []string) (map[string][]byte, error) {
+		return map[string][]byte{key: value}, nil
+	})
+
+	// act
+	resultValues, resultErr := subject.LoadMany(ctx, []string{key})
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, map[string][]byte{key: value}, resultValues)
+}
+
+func testMultiLoadManyReturnsErr(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = new(xcache.Mock)
+		cache2      = new(xcache.Mock)
+		subject     = xcache.NewMulti(cache1, cache2)
+		key1        = "test-multi-load-many-err-key-1"
+		key2        = "test-multi-load-many-err-key-2"
+		value       = []byte("test value")
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered LoadMany error")
+	)
+	cache1.SetLoadManyCallback(func(context.Context, []string) (map[string][]byte, error) {
+		return map[string][]byte{key1: value}, nil
+	})
+	cache2.SetLoadManyCallback(func(context.Context, []string) (map[string][]byte, error) {
+		return nil, expectedErr
+	})
+
+	// act
+	resultValues, resultErr := subject.LoadMany(ctx, []string{key1, key2})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, map[string][]byte{key1: value}, resultValues)
+}
+
+func TestMulti_SaveKeepTTL(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1      = xcache.NewMemory(freecacheMinMem) // a TTLKeeper
+		cache2      = new(xcache.Mock)                  // not a TTLKeeper
+		subject     = xcache.NewMulti(cache1, cache2)
+		key         = "test-multi-save-keep-ttl-key"
+		value       = []byte("test value")
+		ctx         = context.Background()
+		cache2TTL   = 30 * time.Minute
+		expectedErr = errors.New("intentionally triggered Save error")
+	)
+	requireNil(t, cache1.Save(ctx, key, []byte("old value"), time.Hour))
+	cache2.SetTTLCallback(func(_ context.Context, k string) (time.Duration, error) {
+		assertEqual(t, key, k)
+
+		return cache2TTL, nil
+	})
+	cache2.SetSaveCallback(func(_ context.Context, k string, v []byte, e time.Duration) error {
+		assertEqual(t, key, k)
+		assertEqual(t, value, v)
+		assertEqual(t, cache2TTL, e)
+
+		return expectedErr
+	})
+
+	// act
+	resultErr := subject.SaveKeepTTL(ctx, key, value)
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	resultValue, err := cache1.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, resultValue)
+	resultTTL, err := cache1.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, resultTTL > 59*time.Minute && resultTTL <= time.Hour)
+	assertEqual(t, 1, cache2.TTLCallsCount())
+	assertEqual(t, 1, cache2.SaveCallsCount())
+}
@@ -0,0 +1,112 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"time"
+)
+
+// Namespaced is a Cache decorator which prepends a namespace (prefix) to every key,
+// so that multiple services / modules can share a cache (like a Redis DB) without their keys colliding,
+// and without concatenating prefixes throughout business code.
+// Namespaces should not overlap (a namespace should not start with another namespace).
+//
+// Example:
+//
+//	users := xcache.WithNamespace(redisCache, "users:")
+//	err := users.Save(ctx, userID, user, time.Hour) // saved under "users:<userID>" key.
+type Namespaced struct {
+	cache  Cache
+	prefix string
+}
+
+// WithNamespace returns a Namespaced cache which decorates given cache,
+// prepending given prefix to every key.
+func WithNamespace(cache Cache, prefix string) *Namespaced {
+	return &Namespaced{
+		cache:  cache,
+		prefix: prefix,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache, under the namespace.
+// A negative expiration period triggers deletion of key.
+func (cache *Namespaced) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.cache.Save(ctx, cache.prefix+key, value, expire)
+}
+
+// Load returns a key's value from decorated cache, from under the namespace.
+func (cache *Namespaced) Load(ctx context.Context, key string) ([]byte, error) {
+	return cache.cache.Load(ctx, cache.prefix+key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache, from under the namespace.
+func (cache *Namespaced) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, cache.prefix+key)
+}
+
+// Stats returns decorated cache's statistics (which are not particular to the namespace).
+func (cache *Namespaced) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Delete deletes the given key from decorated cache, from under the namespace.
+func (cache *Namespaced) Delete(ctx context.Context, key string) error {
+	return deleteKey(ctx, cache.cache, cache.prefix+key)
+}
+
+// DeleteMany deletes the given keys from decorated cache, from under the namespace.
+func (cache *Namespaced) DeleteMany(ctx context.Context, keys ...string) error {
+	return deleteKeys(ctx, cache.cache, cache.keys(keys)...)
+}
+
+// Has checks if the given key exists in decorated cache, under the namespace.
+func (cache *Namespaced) Has(ctx context.Context, key string) (bool, error) {
+	return hasKey(ctx, cache.cache, cache.prefix+key)
+}
+
+// SaveMany stores the given items into decorated cache, under the namespace.
+func (cache *Namespaced) SaveMany(ctx context.Context, items map[string]Item) error {
+	namespacedItems := make(map[string]Item, len(items))
+	for key, item := range items {
+		namespacedItems[cache.prefix+key] = item
+	}
+
+	return saveMany(ctx, cache.cache, namespacedItems)
+}
+
+// LoadMany returns the values of given keys from decorated cache, from under the namespace
+// (keys not found are missing from the returned map).
+func (cache *Namespaced) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	namespacedValues, err := loadMany(ctx, cache.cache, cache.keys(keys))
+	values := make(map[string][]byte, len(namespacedValues))
+	for namespacedKey, value := range namespacedValues {
+		values[namespacedKey[len(cache.prefix):]] = value
+	}
+
+	return values, err
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Namespaced) Unwrap() Cache {
+	return cache.cache
+}
+
+// keys returns given keys, with the namespace prepended.
+func (cache *Namespaced) keys(keys []string) []string {
+	namespacedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		namespacedKeys[i] = cache.prefix + key
+	}
+
+	return namespacedKeys
+}
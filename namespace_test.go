@@ -0,0 +1,135 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Namespaced)(nil)            // test Namespaced is a Cache
+	var _ xcache.Deleter = (*xcache.Namespaced)(nil)          // test Namespaced is a Deleter
+	var _ xcache.BulkDeleter = (*xcache.Namespaced)(nil)      // test Namespaced is a BulkDeleter
+	var _ xcache.ExistenceChecker = (*xcache.Namespaced)(nil) // test Namespaced is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Namespaced)(nil)          // test Namespaced is a Batcher
+}
+
+func TestNamespaced(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys are namespaced", testNamespacedKeysAreNamespaced)
+	t.Run("namespaces do not collide", testNamespacedNamespacesDoNotCollide)
+	t.Run("batch operations", testNamespacedBatchOperations)
+}
+
+func testNamespacedKeysAreNamespaced(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.WithNamespace(cache, "users:")
+		ctx     = context.Background()
+		key     = "test-namespaced-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+	has, errHas := subject.Has(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertNil(t, errTTL)
+	assertTrue(t, ttl > 0)
+	assertNil(t, errHas)
+	assertTrue(t, has)
+	result, err := cache.Load(ctx, "users:"+key)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	_, err = cache.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertTrue(t, subject.Unwrap() == xcache.Cache(cache))
+
+	// act & assert deletion
+	requireNil(t, subject.Delete(ctx, key))
+	_, err = cache.Load(ctx, "users:"+key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testNamespacedNamespacesDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = xcache.NewMemory(freecacheMinMem)
+		users    = xcache.WithNamespace(cache, "users:")
+		products = xcache.WithNamespace(cache, "products:")
+		ctx      = context.Background()
+		key      = "123"
+	)
+	requireNil(t, users.Save(ctx, key, []byte("test user"), time.Minute))
+	requireNil(t, products.Save(ctx, key, []byte("test product"), time.Minute))
+
+	// act
+	user, errUser := users.Load(ctx, key)
+	product, errProduct := products.Load(ctx, key)
+	errDelete := users.Save(ctx, key, nil, -1)
+	_, errUserAfterDelete := users.Load(ctx, key)
+	productAfterDelete, errProductAfterDelete := products.Load(ctx, key)
+
+	// assert
+	assertNil(t, errUser)
+	assertEqual(t, []byte("test user"), user)
+	assertNil(t, errProduct)
+	assertEqual(t, []byte("test product"), product)
+	assertNil(t, errDelete)
+	assertTrue(t, errors.Is(errUserAfterDelete, xcache.ErrNotFound))
+	assertNil(t, errProductAfterDelete)
+	assertEqual(t, []byte("test product"), productAfterDelete)
+}
+
+func testNamespacedBatchOperations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.WithNamespace(cache, "users:")
+		ctx     = context.Background()
+		items   = map[string]xcache.Item{
+			"1": {Value: []byte("test user 1"), Expire: time.Minute},
+			"2": {Value: []byte("test user 2"), Expire: time.Minute},
+		}
+	)
+
+	// act
+	errSave := subject.SaveMany(ctx, items)
+	result, errLoad := subject.LoadMany(ctx, []string{"1", "2", "3"})
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, map[string][]byte{"1": []byte("test user 1"), "2": []byte("test user 2")}, result)
+	value, err := cache.Load(ctx, "users:2")
+	assertNil(t, err)
+	assertEqual(t, []byte("test user 2"), value)
+
+	// act & assert bulk deletion
+	requireNil(t, subject.DeleteMany(ctx, "1", "2"))
+	result, err = subject.LoadMany(ctx, []string{"1", "2"})
+	assertNil(t, err)
+	assertEqual(t, 0, len(result))
+}
@@ -0,0 +1,199 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// ErrKnownMissing is returned by a NegativeCache for a key which was recently reported as missing
+// from the source of truth, see NegativeCache.SaveMissing. It wraps ErrNotFound.
+var ErrKnownMissing = fmt.Errorf("%w (known to be missing from the source of truth)", ErrNotFound)
+
+// negativeCacheDefaultTTL is the default period a key reported missing is known as missing.
+const negativeCacheDefaultTTL = 30 * time.Second
+
+// negativeTombstoneMarker is the metadata of the envelope of a tombstone.
+const negativeTombstoneMarker = "xcache.tombstone"
+
+// NegativeCacheConfig holds the settings of a NegativeCache.
+type NegativeCacheConfig struct {
+	// TTL is the period a key reported missing is known as missing. By default (0), it's 30s.
+	TTL time.Duration
+	// BackoffFactor multiplies the period a key is known as missing, each time the key is reported missing
+	// again, shortly (within the same period) after it stopped being known as missing.
+	// So, keys which keep missing are looked up in the source of truth less and less often.
+	// A value <= 1 (default) disables the backoff.
+	BackoffFactor float64
+	// MaxTTL caps the backed off period a key is known as missing. By default (0), it's 10 x TTL.
+	MaxTTL time.Duration
+}
+
+// NegativeCache is a Cache decorator which caches misses: keys reported as missing from the source of truth
+// (a database, a service, etc.), with SaveMissing, are stored as tombstones, with a short expiration period,
+// and loading them returns ErrKnownMissing (which is also an ErrNotFound), so repeated lookups
+// of nonexistent keys do not hammer the source of truth.
+// Saving a key's value overwrites its tombstone.
+//
+// LoadOrCompute supports it: ErrKnownMissing is returned without computing the value,
+// and a compute function's error wrapping ErrNotFound results in a tombstone.
+//
+// Example:
+//
+//	cache := xcache.NewNegativeCache(redisCache, xcache.NegativeCacheConfig{TTL: time.Minute, BackoffFactor: 2})
+//	value, err := cache.Load(ctx, key)
+//	if errors.Is(err, xcache.ErrKnownMissing) {
+//		return nil, ErrProductNotFound // no need to query the database.
+//	}
+//	if err != nil {
+//		value, err = productsRepo.GetJSON(ctx, id)
+//		if errors.Is(err, sql.ErrNoRows) {
+//			_ = cache.SaveMissing(ctx, key)
+//		}
+//		// ...
+//	}
+type NegativeCache struct {
+	cache      Cache
+	config     NegativeCacheConfig
+	hits       int64
+	tombstones int64
+}
+
+// NewNegativeCache instantiates a new NegativeCache which decorates given cache, according to given settings.
+func NewNegativeCache(cache Cache, config NegativeCacheConfig) *NegativeCache {
+	if config.TTL <= 0 {
+		config.TTL = negativeCacheDefaultTTL
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = 10 * config.TTL
+	}
+
+	return &NegativeCache{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// SaveMissing reports given key as missing from the source of truth, storing a tombstone for it.
+// It returns an error if the tombstone could not be saved.
+func (cache *NegativeCache) SaveMissing(ctx context.Context, key string) error {
+	misses := uint64(1)
+	if value, err := cache.cache.Load(ctx, key); err == nil {
+		if prevMisses, _, ok := openTombstone(value); ok {
+			misses = prevMisses + 1
+		}
+	}
+	ttl := cache.tombstoneTTL(misses)
+	expire := ttl
+	if cache.config.BackoffFactor > 1 {
+		expire *= 2 // the tombstone is kept for one more period, so a repeated miss is backed off.
+	}
+
+	var (
+		env      = Envelope{ExpireAt: time.Now().Add(ttl).UnixMilli(), Metadata: []byte(negativeTombstoneMarker)}
+		countBuf [binary.MaxVarintLen64]byte
+		payload  = binary.AppendUvarint(countBuf[:0], misses)
+	)
+	tombstone := env.Append(make([]byte, 0, env.Size(len(payload))), payload)
+	err := cache.cache.Save(ctx, key, tombstone, expire)
+	if err == nil {
+		atomic.AddInt64(&cache.tombstones, 1)
+	}
+
+	return err
+}
+
+// Save stores the given key-value with expiration period into decorated cache (overwriting key's tombstone).
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+func (cache *NegativeCache) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// If the key is known to be missing from the source of truth, ErrKnownMissing is returned.
+func (cache *NegativeCache) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if _, expireAt, ok := openTombstone(value); ok {
+		if time.Now().UnixMilli() < expireAt {
+			atomic.AddInt64(&cache.hits, 1)
+
+			return nil, ErrKnownMissing
+		}
+
+		return nil, ErrNotFound // tombstone kept only for backing off a repeated miss.
+	}
+
+	return value, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// Note: tombstones are not distinguished from values, their remaining time to live is returned, too.
+func (cache *NegativeCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *NegativeCache) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *NegativeCache) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of loads of keys known to be missing, as "negative.hits" metric,
+// and the no. of saved tombstones, as "negative.tombstones" metric.
+func (cache *NegativeCache) ContributeStats(add func(name string, value float64)) {
+	add("negative.hits", float64(atomic.LoadInt64(&cache.hits)))
+	add("negative.tombstones", float64(atomic.LoadInt64(&cache.tombstones)))
+}
+
+// tombstoneTTL returns the period a key reported missing given no. of times in a row is known as missing.
+func (cache *NegativeCache) tombstoneTTL(misses uint64) time.Duration {
+	if cache.config.BackoffFactor <= 1 || misses <= 1 {
+		return cache.config.TTL
+	}
+	ttl := float64(cache.config.TTL) * math.Pow(cache.config.BackoffFactor, float64(misses-1))
+	if ttl >= float64(cache.config.MaxTTL) { // compared as float, it can overflow a Duration.
+		return cache.config.MaxTTL
+	}
+
+	return time.Duration(ttl)
+}
+
+// openTombstone returns the no. of misses in a row, and the moment (unix time, in milliseconds)
+// the key stops being known as missing, if given value is a tombstone.
+func openTombstone(value []byte) (misses uint64, expireAt int64, ok bool) {
+	if !IsEnvelope(value) {
+		return 0, 0, false
+	}
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil || string(env.Metadata) != negativeTombstoneMarker {
+		return 0, 0, false
+	}
+	misses, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return 0, 0, false
+	}
+
+	return misses, env.ExpireAt, true
+}
@@ -0,0 +1,154 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.NegativeCache)(nil)            // test NegativeCache is a Cache
+	var _ xcache.StatsContributor = (*xcache.NegativeCache)(nil) // test NegativeCache is a StatsContributor
+}
+
+func TestNegativeCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing key is known as missing", testNegativeCacheMissingKeyIsKnownAsMissing)
+	t.Run("tombstone ttl is backed off", testNegativeCacheTombstoneTTLIsBackedOff)
+	t.Run("expired tombstone is not found", testNegativeCacheExpiredTombstoneIsNotFound)
+	t.Run("load or compute", testNegativeCacheLoadOrCompute)
+}
+
+func testNegativeCacheMissingKeyIsKnownAsMissing(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewNegativeCache(cache, xcache.NegativeCacheConfig{})
+		ctx     = context.Background()
+		key     = "test-negative-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	err := subject.SaveMissing(ctx, key)
+
+	// assert
+	requireNil(t, err)
+	result, err := subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrKnownMissing))
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, result)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 25*time.Second && ttl <= 30*time.Second)
+
+	// act & assert value overwrites tombstone
+	requireNil(t, subject.Save(ctx, key, value, time.Hour))
+	result, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"negative.hits": 1, "negative.tombstones": 1}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testNegativeCacheTombstoneTTLIsBackedOff(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewNegativeCache(cache, xcache.NegativeCacheConfig{
+			TTL:           time.Minute,
+			BackoffFactor: 2,
+			MaxTTL:        3 * time.Minute,
+		})
+		ctx = context.Background()
+		key = "test-negative-backoff-key"
+		// tombstones are kept in cache twice their TTL, for backing off repeated misses.
+		expectedMaxTTLs = []time.Duration{2 * time.Minute, 4 * time.Minute, 6 * time.Minute, 6 * time.Minute}
+	)
+
+	for i, expectedMaxTTL := range expectedMaxTTLs {
+		// act
+		err := subject.SaveMissing(ctx, key)
+
+		// assert
+		requireNil(t, err)
+		ttl, err := subject.TTL(ctx, key)
+		assertNil(t, err)
+		if !assertTrue(t, ttl > expectedMaxTTL-5*time.Second && ttl <= expectedMaxTTL) {
+			t.Logf("miss #%d: ttl=%s", i+1, ttl)
+		}
+	}
+}
+
+func testNegativeCacheExpiredTombstoneIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewNegativeCache(cache, xcache.NegativeCacheConfig{BackoffFactor: 2})
+		ctx     = context.Background()
+		key     = "test-negative-expired-key"
+		env     = xcache.Envelope{
+			ExpireAt: time.Now().Add(-time.Second).UnixMilli(),
+			Metadata: []byte("xcache.tombstone"),
+		}
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return env.Append(nil, []byte{1}), nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertTrue(t, !errors.Is(err, xcache.ErrKnownMissing))
+	assertNil(t, result)
+}
+
+func testNegativeCacheLoadOrCompute(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject  = xcache.NewNegativeCache(xcache.NewMemory(freecacheMinMem), xcache.NegativeCacheConfig{})
+		ctx      = context.Background()
+		key      = "test-negative-compute-key"
+		computes int32
+		notFound = fmt.Errorf("product: %w", xcache.ErrNotFound)
+		compute  = func(context.Context) ([]byte, error) {
+			atomic.AddInt32(&computes, 1)
+
+			return nil, notFound
+		}
+	)
+
+	// act
+	result1, err1 := xcache.LoadOrCompute(ctx, subject, key, time.Hour, compute)
+	result2, err2 := xcache.LoadOrCompute(ctx, subject, key, time.Hour, compute)
+
+	// assert
+	assertTrue(t, errors.Is(err1, notFound))
+	assertNil(t, result1)
+	assertTrue(t, errors.Is(err2, xcache.ErrKnownMissing))
+	assertNil(t, result2)
+	assertEqual(t, int32(1), atomic.LoadInt32(&computes))
+}
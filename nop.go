@@ -33,3 +33,48 @@ func (Nop) TTL(context.Context, string) (time.Duration, error) {
 func (Nop) Stats(context.Context) (Stats, error) {
 	return Stats{}, nil
 }
+
+// SaveMany does nothing.
+func (Nop) SaveMany(context.Context, map[string]Item) error {
+	return nil
+}
+
+// LoadMany returns no values.
+func (Nop) LoadMany(context.Context, []string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+// Delete does nothing.
+func (Nop) Delete(context.Context, string) error {
+	return nil
+}
+
+// DeleteMany does nothing.
+func (Nop) DeleteMany(context.Context, ...string) error {
+	return nil
+}
+
+// DeletePrefix does nothing.
+func (Nop) DeletePrefix(context.Context, string) (int, error) {
+	return 0, nil
+}
+
+// Has reports the key as not found.
+func (Nop) Has(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+// Touch does nothing, reports the key as not found.
+func (Nop) Touch(context.Context, string, time.Duration) (bool, error) {
+	return false, nil
+}
+
+// SaveKeepTTL does nothing.
+func (Nop) SaveKeepTTL(context.Context, string, []byte) error {
+	return nil
+}
+
+// Scan does nothing, returns no keys.
+func (Nop) Scan(context.Context, string, string, int) ([]string, string, error) {
+	return nil, "", nil
+}
@@ -14,7 +14,13 @@ import (
 )
 
 func init() {
-	var _ xcache.Cache = (*xcache.Nop)(nil) // test Nop is a Cache
+	var _ xcache.Cache = (*xcache.Nop)(nil)            // test Nop is a Cache
+	var _ xcache.Deleter = (*xcache.Nop)(nil)          // test Nop is a Deleter
+	var _ xcache.ExistenceChecker = (*xcache.Nop)(nil) // test Nop is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Nop)(nil)          // test Nop is a Batcher
+	var _ xcache.BulkDeleter = (*xcache.Nop)(nil)      // test Nop is a BulkDeleter
+	var _ xcache.Toucher = (*xcache.Nop)(nil)          // test Nop is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Nop)(nil)        // test Nop is a TTLKeeper
 }
 
 func TestNop(t *testing.T) {
@@ -47,4 +53,15 @@ func TestNop(t *testing.T) {
 	resultStats, resultErr := subject.Stats(ctx)
 	assertEqual(t, xcache.Stats{}, resultStats)
 	assertNil(t, resultErr)
+
+	// act & assert delete prefix
+	resultDeleted, resultErr := subject.DeletePrefix(ctx, key)
+	assertNil(t, resultErr)
+	assertEqual(t, 0, resultDeleted)
+
+	// act & assert scan
+	resultKeys, resultCursor, resultErr := subject.Scan(ctx, "", key, 10)
+	assertNil(t, resultErr)
+	assertEqual(t, 0, len(resultKeys))
+	assertEqual(t, "", resultCursor)
 }
@@ -0,0 +1,128 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import "sync/atomic"
+
+// NewVersionedValue returns given payload, enveloped with given version (see Envelope).
+// Versions must be monotonic per key (a row version, or an updated at timestamp, for example), so
+// a Multi cache in SingleHop mode can detect that an asynchronous backfill carries an older value than
+// the one already present in an upfront cache (which can be written by other instances, too).
+func NewVersionedValue(payload []byte, version uint64) []byte {
+	env := Envelope{Version: version}
+
+	return env.Append(make([]byte, 0, env.Size(len(payload))), payload)
+}
+
+// ValueVersion returns the version given value was enveloped with (see NewVersionedValue),
+// or 0 if the value has no version.
+func ValueVersion(value []byte) uint64 {
+	if !IsEnvelope(value) {
+		return 0
+	}
+	var env Envelope
+	if _, err := env.Unmarshal(value); err != nil {
+		return 0
+	}
+
+	return env.Version
+}
+
+// backfillGuard orders the asynchronous backfills of a Multi cache relative to its writes,
+// so an older value can never overwrite a newer one:
+//   - writes (saves, deletions) of a key are performed holding the lock of key's stripe,
+//     and increment stripe's generation before releasing it;
+//   - a backfill captures the generation of key's stripe before the key is loaded, and saves it upfront
+//     holding the same lock, only if the generation did not change meanwhile (otherwise the backfilled
+//     value may be older than the written one, and the backfill is skipped).
+//
+// Keys are distributed to the stripes of a KeyMutex, so writes of different keys may wait for each other.
+// All guard's methods are safe to be called on a nil guard, in which case they do nothing.
+type backfillGuard struct {
+	km   *KeyMutex
+	gens []uint64 // generation per stripe, written while holding stripe's lock, read atomically.
+}
+
+// newBackfillGuard instantiates a new backfillGuard.
+func newBackfillGuard() *backfillGuard {
+	return &backfillGuard{
+		km:   NewKeyMutex(DefaultKeyMutexStripes),
+		gens: make([]uint64, DefaultKeyMutexStripes),
+	}
+}
+
+// generation returns the generation of the stripe given key belongs to.
+func (guard *backfillGuard) generation(key string) uint64 {
+	if guard == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&guard.gens[guard.km.stripe(key)])
+}
+
+// lockWrite locks the stripes of given keys, for a write operation.
+// It returns the locked stripes, which must be passed to unlockWrite.
+func (guard *backfillGuard) lockWrite(keys ...string) []int {
+	if guard == nil || len(keys) == 0 {
+		return nil
+	}
+
+	indexes := guard.km.stripesOf(keys)
+	guard.km.lockStripes(indexes)
+
+	return indexes
+}
+
+// lockWriteAll locks all the stripes, for a write operation which may affect any key.
+// It returns the locked stripes, which must be passed to unlockWrite.
+func (guard *backfillGuard) lockWriteAll() []int {
+	if guard == nil {
+		return nil
+	}
+
+	indexes := guard.km.allStripes()
+	guard.km.lockStripes(indexes)
+
+	return indexes
+}
+
+// unlockWrite increments the generation of given stripes, and unlocks them.
+func (guard *backfillGuard) unlockWrite(indexes []int) {
+	if guard == nil {
+		return
+	}
+
+	for _, idx := range indexes {
+		atomic.AddUint64(&guard.gens[idx], 1)
+	}
+	guard.km.unlockStripes(indexes)
+}
+
+// lockBackfill locks the stripe of given key, if its generation is still the given one.
+// It returns false if the stripe was not locked (the key was written meanwhile).
+func (guard *backfillGuard) lockBackfill(key string, gen uint64) bool {
+	if guard == nil {
+		return true
+	}
+
+	guard.km.Lock(key)
+	if atomic.LoadUint64(&guard.gens[guard.km.stripe(key)]) != gen {
+		guard.km.Unlock(key)
+
+		return false
+	}
+
+	return true
+}
+
+// unlockBackfill unlocks the stripe of given key.
+func (guard *backfillGuard) unlockBackfill(key string) {
+	if guard == nil {
+		return
+	}
+
+	guard.km.Unlock(key)
+}
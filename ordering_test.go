@@ -0,0 +1,197 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestNewVersionedValue(t *testing.T) {
+	t.Parallel()
+
+	property := func(payload []byte, version uint64) bool {
+		value := xcache.NewVersionedValue(payload, version)
+
+		var env xcache.Envelope
+		resultPayload, err := env.Unmarshal(value)
+
+		return err == nil &&
+			bytes.Equal(payload, resultPayload) &&
+			xcache.ValueVersion(value) == version
+	}
+
+	assertNil(t, quick.Check(property, nil))
+	assertEqual(t, uint64(0), xcache.ValueVersion([]byte("not versioned value")))
+}
+
+func TestMulti_backfillOrdering(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes through multi", testMultiBackfillOrderingWritesThroughMulti)
+	t.Run("versioned writes into deeper cache", testMultiBackfillOrderingVersionedWritesIntoDeeperCache)
+}
+
+// testMultiBackfillOrderingWritesThroughMulti checks the property that, while values are
+// written through a Multi cache in SingleHop mode, and concurrently loaded (triggering asynchronous backfills),
+// a value in the upfront cache is never replaced by an older one.
+func testMultiBackfillOrderingWritesThroughMulti(t *testing.T) {
+	t.Parallel()
+
+	property := func(writesNo, readersNo uint8) bool {
+		var (
+			front   = newOrderCheckingCache(orderingTestValueVersion)
+			deep    = slowTTLCache{xcache.NewMemory(1)}
+			pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 4})
+			subject = xcache.NewMulti(front, deep).WithBackfillPool(pool)
+			ctx     = context.Background()
+			key     = "test-multi-ordering-key"
+		)
+
+		runOrderingScenario(int(writesNo%20)+1, int(readersNo%8)+1, subject, func(version uint64, awaitLoads func()) {
+			_ = front.Save(ctx, key, nil, -1) // upfront cache expiration / eviction.
+			awaitLoads()                      // readers load the previous version from the deeper cache.
+			_ = subject.Save(ctx, key, []byte(strconv.FormatUint(version, 10)), time.Minute)
+		})
+		_ = pool.Close()
+
+		return front.violations() == 0
+	}
+
+	assertNil(t, quick.Check(property, &quick.Config{MaxCount: 10}))
+}
+
+// testMultiBackfillOrderingVersionedWritesIntoDeeperCache checks the property that, while versioned values are
+// written directly into the deeper cache of a Multi cache in SingleHop mode (like other instances do),
+// the upfront cache being invalidated, and the values are concurrently loaded (triggering asynchronous backfills),
+// a value in the upfront cache is never replaced by an older one.
+func testMultiBackfillOrderingVersionedWritesIntoDeeperCache(t *testing.T) {
+	t.Parallel()
+
+	property := func(writesNo, readersNo uint8) bool {
+		var (
+			front   = newOrderCheckingCache(xcache.ValueVersion)
+			deep    = slowTTLCache{xcache.NewMemory(1)}
+			pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 4})
+			subject = xcache.NewMulti(front, deep).WithBackfillPool(pool)
+			ctx     = context.Background()
+			key     = "test-multi-ordering-key"
+		)
+
+		runOrderingScenario(int(writesNo%20)+1, int(readersNo%8)+1, subject, func(version uint64, awaitLoads func()) {
+			_ = deep.Save(ctx, key, xcache.NewVersionedValue([]byte("test value"), version), time.Minute)
+			_ = front.Save(ctx, key, nil, -1) // invalidation received from a bus.
+			awaitLoads()
+		})
+		_ = pool.Close()
+
+		return front.violations() == 0
+	}
+
+	assertNil(t, quick.Check(property, &quick.Config{MaxCount: 10}))
+}
+
+// runOrderingScenario writes given no. of versions (starting with 1) with given write function,
+// while given no. of readers load the key from given cache.
+// The write function can wait, with awaitLoads, for the readers to perform some loads.
+func runOrderingScenario(
+	writesNo, readersNo int,
+	cache xcache.Cache,
+	write func(version uint64, awaitLoads func()),
+) {
+	var (
+		done  = make(chan struct{})
+		wg    sync.WaitGroup
+		loads int64
+	)
+	awaitLoads := func() {
+		for target := atomic.LoadInt64(&loads) + int64(readersNo); atomic.LoadInt64(&loads) < target; {
+			runtime.Gosched()
+		}
+	}
+	wg.Add(readersNo)
+	for i := 0; i < readersNo; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					_, _ = cache.Load(context.Background(), "test-multi-ordering-key")
+					atomic.AddInt64(&loads, 1)
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	for version := 1; version <= writesNo; version++ {
+		write(uint64(version), awaitLoads)
+	}
+	close(done)
+	wg.Wait()
+}
+
+// slowTTLCache is a Memory cache with slow TTL operations (like a remote cache has),
+// widening (randomly) the window between a backfilled key's load and its save upfront.
+// The window is widened by yielding the processor, rather than sleeping, to keep the test fast.
+type slowTTLCache struct {
+	*xcache.Memory
+}
+
+func (cache slowTTLCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	for yields := rand.Intn(20); yields > 0; yields-- {
+		runtime.Gosched()
+	}
+
+	return cache.Memory.TTL(ctx, key)
+}
+
+// orderingTestValueVersion returns the version of a value which is the version itself, as string.
+func orderingTestValueVersion(value []byte) uint64 {
+	version, _ := strconv.ParseUint(string(value), 10, 64)
+
+	return version
+}
+
+// orderCheckingCache is a Memory cache which counts the saves replacing a value with an older one.
+type orderCheckingCache struct {
+	*xcache.Memory
+	versionOf     func([]byte) uint64
+	violationsCnt int64
+}
+
+func newOrderCheckingCache(versionOf func([]byte) uint64) *orderCheckingCache {
+	return &orderCheckingCache{
+		Memory:    xcache.NewMemory(1),
+		versionOf: versionOf,
+	}
+}
+
+func (cache *orderCheckingCache) Save(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	if expire >= 0 {
+		if current, err := cache.Memory.Load(ctx, key); err == nil &&
+			cache.versionOf(current) > cache.versionOf(value) {
+			atomic.AddInt64(&cache.violationsCnt, 1)
+		}
+	}
+
+	return cache.Memory.Save(ctx, key, value, expire)
+}
+
+func (cache *orderCheckingCache) violations() int64 {
+	return atomic.LoadInt64(&cache.violationsCnt)
+}
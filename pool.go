@@ -0,0 +1,39 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPoolMaxCap is the max. capacity of a buffer to be put back into the pool.
+// Larger buffers are left to the garbage collector, so that the pool does not retain too much memory.
+const bufferPoolMaxCap = 1 << 20
+
+// bufferPool holds scratch buffers, in order to avoid an allocation per operation.
+// Their bytes must not be passed to a Cache's Save, as a Cache may retain the value.
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf, _ := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
+}
+
+// putBuffer puts back given buffer into the pool.
+// The buffer (and its bytes) must not be used after this call.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= bufferPoolMaxCap {
+		bufferPool.Put(buf)
+	}
+}
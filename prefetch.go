@@ -0,0 +1,105 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync"
+)
+
+// Prefetcher warms the upfront cache(s) of a Multi cache with keys found in deeper caches, in background.
+// It's meant for pages which know the keys the user is likely to access next
+// (a list page, for example, knows the detail keys of the listed items).
+//
+// Prefetching is executed by a WorkerPool (the no. of concurrent prefetches is bounded by the pool's workers),
+// and a key already being prefetched is not prefetched again, until its prefetch completes.
+//
+// Example:
+//
+//	prefetcher := xcache.NewPrefetcher(multiCache, pool)
+//	products := listProducts(ctx)
+//	prefetcher.Prefetch(ctx, productKeys(products))
+type Prefetcher struct {
+	cache    Multi
+	pool     *WorkerPool
+	inFlight map[string]struct{}
+	mu       sync.Mutex
+}
+
+// NewPrefetcher instantiates a new Prefetcher which warms given Multi cache, through given pool.
+func NewPrefetcher(cache Multi, pool *WorkerPool) *Prefetcher {
+	return &Prefetcher{
+		cache:    cache,
+		pool:     pool,
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// Prefetch schedules the prefetch of given keys, without blocking:
+// a key not found in the first cache, but found in a deeper one, is saved upfront, with its remaining TTL.
+// Keys already being prefetched, and keys not accepted by the pool (its queue is full), are skipped.
+// It returns the no. of scheduled keys.
+// Note: the prefetch is not canceled if the context gets done.
+func (p *Prefetcher) Prefetch(ctx context.Context, keys []string) int {
+	bgCtx := context.WithoutCancel(ctx)
+	scheduled := 0
+	for _, key := range keys {
+		if !p.acquire(key) {
+			continue
+		}
+		key := key // capture range variable
+		if p.pool.Submit(func() {
+			defer p.release(key)
+			p.prefetch(bgCtx, key)
+		}) {
+			scheduled++
+		} else {
+			p.release(key)
+		}
+	}
+
+	return scheduled
+}
+
+// prefetch loads the given key from the first cache it is found in, and saves it upfront.
+func (p *Prefetcher) prefetch(ctx context.Context, key string) {
+	gen := p.cache.guard.generation(key)
+	for idx, c := range p.cache.caches {
+		if idx == 0 {
+			if found, err := hasKey(ctx, c, key); err == nil && found {
+				return // already warm.
+			}
+
+			continue
+		}
+		if val, err := c.Load(ctx, key); err == nil {
+			p.cache.backfill(ctx, idx, key, val, gen)
+
+			return
+		}
+	}
+}
+
+// acquire marks the given key as being prefetched.
+// It returns false if the key is already being prefetched.
+func (p *Prefetcher) acquire(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, found := p.inFlight[key]; found {
+		return false
+	}
+	p.inFlight[key] = struct{}{}
+
+	return true
+}
+
+// release marks the given key as not being prefetched anymore.
+func (p *Prefetcher) release(key string) {
+	p.mu.Lock()
+	delete(p.inFlight, key)
+	p.mu.Unlock()
+}
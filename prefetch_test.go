@@ -0,0 +1,117 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestPrefetcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys are saved upfront", testPrefetcherKeysAreSavedUpfront)
+	t.Run("keys in flight are prefetched once", testPrefetcherKeysInFlightArePrefetchedOnce)
+	t.Run("keys are skipped if pool is closed", testPrefetcherKeysAreSkippedIfPoolIsClosed)
+}
+
+func testPrefetcherKeysAreSavedUpfront(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1     = xcache.NewMemory(1)
+		cache2     = xcache.NewMemory(1)
+		cache3     = new(xcache.Mock)
+		pool       = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 2})
+		subject    = xcache.NewPrefetcher(xcache.NewMulti(cache1, cache2, cache3), pool)
+		ctx        = context.Background()
+		warmKey    = "test-prefetch-warm-key"
+		coldKey    = "test-prefetch-cold-key"
+		missingKey = "test-prefetch-missing-key"
+		value      = []byte("test value")
+	)
+	requireNil(t, cache1.Save(ctx, warmKey, value, time.Minute))
+	requireNil(t, cache2.Save(ctx, coldKey, value, time.Minute))
+	cache3.SetLoadCallback(func(_ context.Context, key string) ([]byte, error) {
+		assertEqual(t, missingKey, key)
+
+		return nil, xcache.ErrNotFound
+	})
+
+	// act
+	result := subject.Prefetch(ctx, []string{warmKey, coldKey, missingKey})
+	requireNil(t, pool.Close()) // wait for prefetches to complete.
+
+	// assert
+	assertEqual(t, 3, result)
+	resultValue, resultErr := cache1.Load(ctx, coldKey)
+	assertNil(t, resultErr)
+	assertEqual(t, value, resultValue)
+	resultTTL, resultErr := cache1.TTL(ctx, coldKey)
+	assertNil(t, resultErr)
+	assertTrue(t, resultTTL > 0 && resultTTL <= time.Minute)
+	assertEqual(t, 1, cache3.LoadCallsCount()) // only the missing key reached the last cache.
+}
+
+func testPrefetcherKeysInFlightArePrefetchedOnce(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = xcache.NewMemory(1)
+		cache2  = new(xcache.Mock)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 2})
+		subject = xcache.NewPrefetcher(xcache.NewMulti(cache1, cache2), pool)
+		ctx     = context.Background()
+		key     = "test-prefetch-in-flight-key"
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	cache2.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		close(started)
+		<-release
+
+		return []byte("test value"), nil
+	})
+
+	// act
+	result1 := subject.Prefetch(ctx, []string{key, key})
+	<-started
+	result2 := subject.Prefetch(ctx, []string{key})
+	close(release)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertEqual(t, 1, result1)
+	assertEqual(t, 0, result2)
+	assertEqual(t, 1, cache2.LoadCallsCount())
+}
+
+func testPrefetcherKeysAreSkippedIfPoolIsClosed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache1  = new(xcache.Mock)
+		cache2  = new(xcache.Mock)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewPrefetcher(xcache.NewMulti(cache1, cache2), pool)
+	)
+	requireNil(t, pool.Close())
+
+	// act
+	result := subject.Prefetch(context.Background(), []string{"test-prefetch-closed-pool-key"})
+
+	// assert
+	assertEqual(t, 0, result)
+	assertEqual(t, 0, cache1.HasCallsCount())
+	assertEqual(t, 0, cache2.LoadCallsCount())
+	assertEqual(t, int64(1), pool.Stats().Dropped)
+}
@@ -0,0 +1,226 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultLatencyBuckets are the default upper bounds, in seconds, of the buckets
+// of the operations' latency histograms of a PrometheusCollector.
+var DefaultLatencyBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// PrometheusCollector is a prometheus.Collector (see [github.com/prometheus/client_golang/prometheus])
+// which exposes, on scrape, the stats of one or more (labeled) caches: hits, misses, keys, memory,
+// expired / evicted keys, bytes read / written, the metrics contributed by caches' pipelines
+// (see CollectDetailedStats), and per operation latency histograms.
+//
+// Exposed metrics (with "xcache" namespace), labeled with the cache's name ("cache"):
+//   - xcache_up - whether cache's stats were retrieved (1), or not (0) at the moment of the scrape;
+//   - xcache_hits_total, xcache_misses_total, xcache_expired_total, xcache_evicted_total - counters;
+//   - xcache_keys, xcache_memory_bytes, xcache_max_memory_bytes - gauges;
+//   - xcache_read_bytes_total, xcache_written_bytes_total - counters (see Metered);
+//   - xcache_pipeline_metric - pipeline's metrics, labeled also with their name ("metric");
+//   - xcache_operation_duration_seconds - histogram, labeled also with the operation ("op": save / load / ttl).
+//
+// Example:
+//
+//	collector := xcache.NewPrometheusCollector("", nil)
+//	localCache = collector.Register("local", localCache)
+//	redisCache = collector.Register("redis", redisCache)
+//	prometheus.MustRegister(collector)
+//	http.Handle("/metrics", promhttp.Handler())
+type PrometheusCollector struct {
+	upDesc       *prometheus.Desc
+	statsDescs   []prometheusStatsDesc
+	pipelineDesc *prometheus.Desc
+	latencies    *prometheus.HistogramVec
+	mu           sync.RWMutex
+	caches       []prometheusCache
+}
+
+// prometheusStatsDesc describes a metric exposed from caches' Stats.
+type prometheusStatsDesc struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(Stats) int64
+}
+
+// prometheusCache is a cache registered to a PrometheusCollector.
+type prometheusCache struct {
+	name  string
+	cache Cache
+}
+
+// NewPrometheusCollector instantiates a new PrometheusCollector.
+// An empty namespace is replaced with "xcache", and nil buckets with DefaultLatencyBuckets.
+func NewPrometheusCollector(namespace string, buckets []float64) *PrometheusCollector {
+	if namespace == "" {
+		namespace = "xcache"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	cacheLabels := []string{"cache"}
+	newDesc := func(name, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+	}
+	newStatsDesc := func(name, help string, valueType prometheus.ValueType, value func(Stats) int64) prometheusStatsDesc {
+		return prometheusStatsDesc{desc: newDesc(name, help, cacheLabels), valueType: valueType, value: value}
+	}
+
+	return &PrometheusCollector{
+		upDesc: newDesc("up", "Whether cache's stats were retrieved.", cacheLabels),
+		statsDescs: []prometheusStatsDesc{
+			newStatsDesc("hits_total", "Number of successful accesses of keys.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.Hits }),
+			newStatsDesc("misses_total", "Number of times keys were not found.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.Misses }),
+			newStatsDesc("keys", "Current number of keys.",
+				prometheus.GaugeValue, func(s Stats) int64 { return s.Keys }),
+			newStatsDesc("memory_bytes", "In use memory.",
+				prometheus.GaugeValue, func(s Stats) int64 { return s.Memory }),
+			newStatsDesc("max_memory_bytes", "Maximum memory.",
+				prometheus.GaugeValue, func(s Stats) int64 { return s.MaxMemory }),
+			newStatsDesc("expired_total", "Number of expired keys.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.Expired }),
+			newStatsDesc("evicted_total", "Number of evicted keys.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.Evicted }),
+			newStatsDesc("read_bytes_total", "Number of bytes read from cache.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.BytesRead }),
+			newStatsDesc("written_bytes_total", "Number of bytes sent to cache.",
+				prometheus.CounterValue, func(s Stats) int64 { return s.BytesWritten }),
+		},
+		pipelineDesc: newDesc("pipeline_metric", "Metrics contributed by cache's pipeline.", []string{"cache", "metric"}),
+		latencies: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of cache operations.",
+			Buckets:   buckets,
+		}, []string{"cache", "op"}),
+	}
+}
+
+// Register registers given cache to be exposed, labeled with given name (which should be unique),
+// returning it decorated so that its operations' latencies are observed.
+// Use the returned cache instead of the given one.
+func (collector *PrometheusCollector) Register(name string, cache Cache) Cache {
+	collector.mu.Lock()
+	collector.caches = append(collector.caches, prometheusCache{name: name, cache: cache})
+	collector.mu.Unlock()
+
+	return &latencyObserved{
+		cache: cache,
+		latencies: [3]prometheus.Observer{
+			collector.latencies.WithLabelValues(name, "save"),
+			collector.latencies.WithLabelValues(name, "load"),
+			collector.latencies.WithLabelValues(name, "ttl"),
+		},
+	}
+}
+
+// Describe implements prometheus.Collector. It sends the descriptors of the exposed metrics.
+func (collector *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.upDesc
+	for _, statsDesc := range collector.statsDescs {
+		ch <- statsDesc.desc
+	}
+	ch <- collector.pipelineDesc
+	collector.latencies.Describe(ch)
+}
+
+// Collect implements prometheus.Collector. It sends the metrics of the registered caches,
+// retrieving their stats at the moment of the scrape.
+func (collector *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.RLock()
+	caches := append([]prometheusCache(nil), collector.caches...)
+	collector.mu.RUnlock()
+
+	ctx := context.Background()
+	for _, registered := range caches {
+		stats, err := CollectDetailedStats(ctx, registered.cache)
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(collector.upDesc, prometheus.GaugeValue, 0, registered.name)
+
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(collector.upDesc, prometheus.GaugeValue, 1, registered.name)
+		for _, statsDesc := range collector.statsDescs {
+			ch <- prometheus.MustNewConstMetric(
+				statsDesc.desc,
+				statsDesc.valueType,
+				float64(statsDesc.value(stats.Stats)),
+				registered.name,
+			)
+		}
+		for metric, value := range stats.Metrics {
+			ch <- prometheus.MustNewConstMetric(
+				collector.pipelineDesc,
+				prometheus.UntypedValue,
+				value,
+				registered.name, metric,
+			)
+		}
+	}
+	collector.latencies.Collect(ch)
+}
+
+// latencyObserved is a Cache decorator which observes operations' latencies,
+// returned by PrometheusCollector.Register.
+type latencyObserved struct {
+	cache     Cache
+	latencies [3]prometheus.Observer // save, load, ttl.
+}
+
+// Save stores the given key-value with expiration period into decorated cache, observing its latency.
+func (cache *latencyObserved) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	start := time.Now()
+	err := cache.cache.Save(ctx, key, value, expire)
+	cache.latencies[0].Observe(time.Since(start).Seconds())
+
+	return err
+}
+
+// Load returns a key's value from decorated cache, observing its latency.
+func (cache *latencyObserved) Load(ctx context.Context, key string) ([]byte, error) {
+	start := time.Now()
+	value, err := cache.cache.Load(ctx, key)
+	cache.latencies[1].Observe(time.Since(start).Seconds())
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, observing its latency.
+func (cache *latencyObserved) TTL(ctx context.Context, key string) (time.Duration, error) {
+	start := time.Now()
+	ttl, err := cache.cache.TTL(ctx, key)
+	cache.latencies[2].Observe(time.Since(start).Seconds())
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *latencyObserved) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *latencyObserved) Unwrap() Cache {
+	return cache.cache
+}
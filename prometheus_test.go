@@ -0,0 +1,151 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ prometheus.Collector = (*xcache.PrometheusCollector)(nil) // test PrometheusCollector is a prometheus.Collector
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("metrics are exposed", testPrometheusCollectorMetricsAreExposed)
+	t.Run("stats error is exposed", testPrometheusCollectorStatsErrorIsExposed)
+}
+
+func testPrometheusCollectorMetricsAreExposed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject  = xcache.NewPrometheusCollector("", []float64{10, 0.5})
+		cache    = subject.Register("local", xcache.NewMetered(xcache.NewMemory(freecacheMinMem)))
+		registry = prometheus.NewPedanticRegistry()
+		ctx      = context.Background()
+		key      = "test-prometheus-key"
+		value    = []byte("test value")
+	)
+	requireNil(t, registry.Register(subject))
+	requireNil(t, cache.Save(ctx, key, value, time.Minute))
+	_, _ = cache.Load(ctx, key)
+	_, _ = cache.Load(ctx, "test-prometheus-missing-key")
+
+	// act
+	families, err := registry.Gather()
+
+	// assert
+	requireNil(t, err)
+	err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP xcache_up Whether cache's stats were retrieved.
+# TYPE xcache_up gauge
+xcache_up{cache="local"} 1
+# HELP xcache_hits_total Number of successful accesses of keys.
+# TYPE xcache_hits_total counter
+xcache_hits_total{cache="local"} 1
+# HELP xcache_misses_total Number of times keys were not found.
+# TYPE xcache_misses_total counter
+xcache_misses_total{cache="local"} 1
+# HELP xcache_keys Current number of keys.
+# TYPE xcache_keys gauge
+xcache_keys{cache="local"} 1
+# HELP xcache_read_bytes_total Number of bytes read from cache.
+# TYPE xcache_read_bytes_total counter
+xcache_read_bytes_total{cache="local"} 10
+`), "xcache_up", "xcache_hits_total", "xcache_misses_total", "xcache_keys", "xcache_read_bytes_total")
+	assertNil(t, err)
+	pipelineMetrics := findMetricFamily(families, "xcache_pipeline_metric")
+	if assertNotNil(t, pipelineMetrics) {
+		assertEqual(t, dto.MetricType_UNTYPED, pipelineMetrics.GetType())
+		assertTrue(t, findMetric(pipelineMetrics, "metric", "metered.errors.timeout") != nil)
+	}
+	latencies := findMetricFamily(families, "xcache_operation_duration_seconds")
+	if assertNotNil(t, latencies) {
+		assertEqual(t, dto.MetricType_HISTOGRAM, latencies.GetType())
+		save := findMetric(latencies, "op", "save").GetHistogram()
+		assertEqual(t, uint64(1), save.GetSampleCount())
+		if assertEqual(t, 2, len(save.GetBucket())) {
+			assertEqual(t, 0.5, save.GetBucket()[0].GetUpperBound())
+			assertEqual(t, uint64(1), save.GetBucket()[0].GetCumulativeCount())
+			assertEqual(t, 10.0, save.GetBucket()[1].GetUpperBound())
+		}
+		assertEqual(t, uint64(2), findMetric(latencies, "op", "load").GetHistogram().GetSampleCount())
+		assertEqual(t, uint64(0), findMetric(latencies, "op", "ttl").GetHistogram().GetSampleCount())
+	}
+	unwrapper, ok := cache.(xcache.Unwrapper)
+	if assertTrue(t, ok) {
+		_, isMetered := unwrapper.Unwrap().(*xcache.Metered)
+		assertTrue(t, isMetered)
+	}
+}
+
+func testPrometheusCollectorStatsErrorIsExposed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject  = xcache.NewPrometheusCollector("app_cache", nil)
+		mock     = new(xcache.Mock)
+		registry = prometheus.NewPedanticRegistry()
+	)
+	mock.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		return xcache.Stats{}, errors.New("connection refused")
+	})
+	_ = subject.Register(`redis "eu"`, mock)
+	requireNil(t, registry.Register(subject))
+
+	// act
+	families, err := registry.Gather()
+
+	// assert
+	requireNil(t, err)
+	up := findMetricFamily(families, "app_cache_up")
+	if assertNotNil(t, up) {
+		assertEqual(t, 0.0, findMetric(up, "cache", `redis "eu"`).GetGauge().GetValue())
+	}
+	assertTrue(t, findMetricFamily(families, "app_cache_hits_total") == nil)
+	latencies := findMetricFamily(families, "app_cache_operation_duration_seconds")
+	if assertNotNil(t, latencies) {
+		assertTrue(t, findMetric(latencies, "op", "load") != nil)
+	}
+}
+
+// findMetricFamily returns the metric family with given name, or nil if it's not found.
+func findMetricFamily(families []*dto.MetricFamily, name string) *dto.MetricFamily {
+	for _, family := range families {
+		if family.GetName() == name {
+			return family
+		}
+	}
+
+	return nil
+}
+
+// findMetric returns the first metric of given family having given label value, or nil if it's not found.
+func findMetric(family *dto.MetricFamily, labelName, labelValue string) *dto.Metric {
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == labelName && label.GetValue() == labelValue {
+				return metric
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// QueryFunc executes a query (against a database, for example) and returns its result.
+type QueryFunc[T any] func(ctx context.Context) (T, error)
+
+// QueryCache caches results of type T of queries (like database ones, performed with sqlc / sqlx / database/sql),
+// keyed by the hash of the statement, its arguments, and the versions of the tags the query depends on
+// (for example, the tables it reads from).
+// Results are read through: on a miss, the query is executed and its result is cached.
+// Invalidating a tag (see Tags.Invalidate), invalidates all the cached results of the queries depending on it.
+//
+// Example:
+//
+//	tags := xcache.NewTags(cache, "tag:")
+//	config := xcache.TypedConfig[string, []User]{KeyPrefix: "query:", TTL: time.Hour}
+//	users := xcache.NewQueryCache(cache, tags, config)
+//	stmt := "SELECT id, name FROM users WHERE status = $1"
+//	queryFn := func(ctx context.Context) ([]User, error) {
+//		return queryUsers(ctx, db, stmt, status)
+//	}
+//	result, err := users.Query(ctx, stmt, []any{status}, []string{"users"}, queryFn)
+//	// after users table is written:
+//	err = tags.Invalidate(ctx, "users")
+type QueryCache[T any] struct {
+	typed *Typed[string, T]
+	tags  *Tags
+}
+
+// NewQueryCache instantiates a new QueryCache which caches results into given cache, according to given settings
+// (the id passed to a config's KeyFunc is the query hash), and uses given Tags for invalidation.
+func NewQueryCache[T any](cache Cache, tags *Tags, config TypedConfig[string, T]) *QueryCache[T] {
+	return &QueryCache[T]{
+		typed: NewTyped(cache, config),
+		tags:  tags,
+	}
+}
+
+// Query returns the cached result of given statement and arguments, if any,
+// otherwise, it executes the query with given function, and caches its result.
+// The result depends on given tags: invalidating any of them results in a new query execution.
+// Cache errors are not returned, the query function is executed instead, and its error, if any, is returned.
+func (qc *QueryCache[T]) Query(
+	ctx context.Context,
+	statement string,
+	args []any,
+	tags []string,
+	queryFn QueryFunc[T],
+) (T, error) {
+	versions, err := qc.tags.Versions(ctx, tags...)
+	if err != nil { // skip the cache.
+		return queryFn(ctx)
+	}
+	key := queryHash(statement, args, versions)
+
+	result, err := qc.typed.Get(ctx, key)
+	if err == nil {
+		return result, nil
+	}
+
+	result, err = queryFn(ctx)
+	if err != nil {
+		return result, err
+	}
+	_ = qc.typed.Set(ctx, key, result)
+
+	return result, nil
+}
+
+// Invalidate deletes the cached result of given statement and arguments,
+// for current versions of given tags.
+func (qc *QueryCache[T]) Invalidate(ctx context.Context, statement string, args []any, tags []string) error {
+	versions, err := qc.tags.Versions(ctx, tags...)
+	if err != nil {
+		return err
+	}
+
+	return qc.typed.Invalidate(ctx, queryHash(statement, args, versions))
+}
+
+// queryHash returns the hex encoded SHA-256 hash of given statement, arguments and tags versions.
+// Arguments' types are taken into account, so 1 and "1" result in different hashes.
+func queryHash(statement string, args []any, versions string) string {
+	h := sha256.New()
+	writeQueryHashPart(h, statement)
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(h, "%T:%v", arg, arg)
+		_, _ = h.Write([]byte{0})
+	}
+	writeQueryHashPart(h, versions)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeQueryHashPart writes given part into the hash, followed by a separator.
+func writeQueryHashPart(h hash.Hash, part string) {
+	_, _ = h.Write([]byte(part))
+	_, _ = h.Write([]byte{0})
+}
@@ -0,0 +1,133 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestQueryCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("results are read through and invalidated by tag", testQueryCacheResultsAreReadThroughAndInvalidatedByTag)
+	t.Run("query error is returned", testQueryCacheQueryErrIsReturned)
+	t.Run("cache errors are skipped", testQueryCacheCacheErrsAreSkipped)
+}
+
+func testQueryCacheResultsAreReadThroughAndInvalidatedByTag(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		tags    = xcache.NewTags(cache, "test-query-tag-")
+		subject = xcache.NewQueryCache(cache, tags, xcache.TypedConfig[string, []testUser]{
+			KeyPrefix: "test-query-",
+			TTL:       time.Minute,
+		})
+		ctx      = context.Background()
+		stmt     = "SELECT id, name FROM users WHERE id > ?"
+		queryCnt int
+		queryFn  = func(context.Context) ([]testUser, error) {
+			queryCnt++
+
+			return []testUser{{ID: 2, Name: "John Doe"}}, nil
+		}
+		expected = []testUser{{ID: 2, Name: "John Doe"}}
+	)
+
+	// act & assert
+	result, err := subject.Query(ctx, stmt, []any{1}, []string{"users"}, queryFn)
+	assertNil(t, err)
+	assertEqual(t, expected, result)
+	assertEqual(t, 1, queryCnt)
+
+	result, err = subject.Query(ctx, stmt, []any{1}, []string{"users"}, queryFn)
+	assertNil(t, err)
+	assertEqual(t, expected, result)
+	assertEqual(t, 1, queryCnt) // result came from cache
+
+	_, _ = subject.Query(ctx, stmt, []any{"1"}, []string{"users"}, queryFn)
+	assertEqual(t, 2, queryCnt) // different args
+
+	time.Sleep(time.Microsecond) // make sure the new version differs
+	err = tags.Invalidate(ctx, "users")
+	requireNil(t, err)
+	_, _ = subject.Query(ctx, stmt, []any{1}, []string{"users"}, queryFn)
+	assertEqual(t, 3, queryCnt) // tag was invalidated
+
+	err = subject.Invalidate(ctx, stmt, []any{1}, []string{"users"})
+	requireNil(t, err)
+	_, _ = subject.Query(ctx, stmt, []any{1}, []string{"users"}, queryFn)
+	assertEqual(t, 4, queryCnt) // query result was invalidated
+}
+
+func testQueryCacheQueryErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewQueryCache(
+			cache,
+			xcache.NewTags(cache, "test-query-tag-"),
+			xcache.TypedConfig[string, int]{KeyPrefix: "test-query-err-"},
+		)
+		expectedErr = errors.New("intentionally triggered query error")
+		queryCnt    int
+		queryFn     = func(context.Context) (int, error) {
+			queryCnt++
+
+			return 0, expectedErr
+		}
+	)
+
+	// act
+	_, err1 := subject.Query(context.Background(), "SELECT COUNT(*) FROM orders", nil, []string{"orders"}, queryFn)
+	_, err2 := subject.Query(context.Background(), "SELECT COUNT(*) FROM orders", nil, []string{"orders"}, queryFn)
+
+	// assert
+	assertTrue(t, errors.Is(err1, expectedErr))
+	assertTrue(t, errors.Is(err2, expectedErr))
+	assertEqual(t, 2, queryCnt) // errors are not cached
+}
+
+func testQueryCacheCacheErrsAreSkipped(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewQueryCache(
+			cache,
+			xcache.NewTags(cache, "test-query-tag-"),
+			xcache.TypedConfig[string, int]{KeyPrefix: "test-query-cache-err-"},
+		)
+		cacheErr = errors.New("intentionally triggered cache error")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, cacheErr
+	})
+
+	// act
+	result, err := subject.Query(
+		context.Background(),
+		"SELECT COUNT(*) FROM orders",
+		nil,
+		[]string{"orders"},
+		func(context.Context) (int, error) { return 10, nil },
+	)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 10, result)
+	assertEqual(t, 0, cache.SaveCallsCount())
+}
@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRateLimited is returned by a RateLimited cache when an operation is rejected,
+// as the operations per second budget was exceeded.
+var ErrRateLimited = errors.New("cache rate limit exceeded")
+
+// RateLimitConfig holds the settings of a RateLimited cache.
+type RateLimitConfig struct {
+	// Rate is the budget of operations per second. A non-positive value means no limit.
+	Rate float64
+	// Burst is the max. no. of operations which can be executed at once, above the rate,
+	// after a period of inactivity (the token bucket's size). By default (0), it's the Rate (at least 1).
+	Burst int
+	// Wait tells whether an operation exceeding the budget waits for its turn (true),
+	// or is rejected right away, with ErrRateLimited (false).
+	Wait bool
+	// MaxWait is the max. period an operation waits for its turn; an operation which would wait longer
+	// is rejected, with ErrRateLimited. By default (0), an operation waits until its context gets done.
+	// It applies only if Wait is true.
+	MaxWait time.Duration
+}
+
+// RateLimited is a Cache decorator which enforces an operations per second budget (a token bucket)
+// toward the decorated (usually remote, shared) cache, protecting it from a single misbehaving service flooding it.
+// Operations exceeding the budget wait for their turn, or are rejected, with ErrRateLimited, see RateLimitConfig.
+// Deletions (saves with a negative expiration period) are not rejected (they wait for their turn),
+// so no stale data is served. Stats is not limited.
+//
+// Example:
+//
+//	cache := xcache.NewRateLimited(redisCache, xcache.RateLimitConfig{Rate: 5000, Burst: 500})
+type RateLimited struct {
+	cache    Cache
+	config   RateLimitConfig
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	limited  int64
+	rejected int64
+}
+
+// NewRateLimited instantiates a new RateLimited which decorates given cache, according to given settings.
+func NewRateLimited(cache Cache, config RateLimitConfig) *RateLimited {
+	if config.Burst <= 0 {
+		config.Burst = max(1, int(math.Ceil(config.Rate)))
+	}
+
+	return &RateLimited{
+		cache:  cache,
+		config: config,
+		tokens: float64(config.Burst),
+		last:   time.Now(),
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// It returns ErrRateLimited if the operation was rejected.
+func (cache *RateLimited) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if err := cache.acquire(ctx, expire < 0); err != nil {
+		return err
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// It returns ErrRateLimited if the operation was rejected.
+func (cache *RateLimited) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := cache.acquire(ctx, false); err != nil {
+		return nil, err
+	}
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// It returns ErrRateLimited if the operation was rejected.
+func (cache *RateLimited) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := cache.acquire(ctx, false); err != nil {
+		return -1, err
+	}
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics (regardless of the budget).
+func (cache *RateLimited) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *RateLimited) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of operations which exceeded the budget (waited, or were rejected),
+// as "ratelimit.limited" metric, and the no. of rejected operations, as "ratelimit.rejected" metric.
+func (cache *RateLimited) ContributeStats(add func(name string, value float64)) {
+	add("ratelimit.limited", float64(atomic.LoadInt64(&cache.limited)))
+	add("ratelimit.rejected", float64(atomic.LoadInt64(&cache.rejected)))
+}
+
+// acquire takes a token from the bucket, waiting for it, if configured so (or the operation is a mandatory one).
+// If the operation is rejected, ErrRateLimited is returned.
+// If the context is done while waiting, its error is returned.
+func (cache *RateLimited) acquire(ctx context.Context, mandatory bool) error {
+	if cache.config.Rate <= 0 {
+		return nil
+	}
+
+	cache.mu.Lock()
+	now := time.Now()
+	cache.tokens = math.Min(
+		float64(cache.config.Burst),
+		cache.tokens+now.Sub(cache.last).Seconds()*cache.config.Rate,
+	)
+	cache.last = now
+	if cache.tokens >= 1 {
+		cache.tokens--
+		cache.mu.Unlock()
+
+		return nil
+	}
+	wait := time.Duration((1 - cache.tokens) / cache.config.Rate * float64(time.Second))
+	if !mandatory && (!cache.config.Wait || (cache.config.MaxWait > 0 && wait > cache.config.MaxWait)) {
+		cache.mu.Unlock()
+		atomic.AddInt64(&cache.limited, 1)
+		atomic.AddInt64(&cache.rejected, 1)
+
+		return ErrRateLimited
+	}
+	cache.tokens-- // reserve the token, following operations wait after this one.
+	cache.mu.Unlock()
+	atomic.AddInt64(&cache.limited, 1)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cache.mu.Lock()
+		cache.tokens++ // give back the reserved token.
+		cache.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
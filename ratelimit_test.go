@@ -0,0 +1,159 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.RateLimited)(nil)            // test RateLimited is a Cache
+	var _ xcache.StatsContributor = (*xcache.RateLimited)(nil) // test RateLimited is a StatsContributor
+}
+
+func TestRateLimited(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fail fast when budget is exceeded", testRateLimitedFailFastWhenBudgetIsExceeded)
+	t.Run("wait when budget is exceeded", testRateLimitedWaitWhenBudgetIsExceeded)
+	t.Run("max wait is honored", testRateLimitedMaxWaitIsHonored)
+	t.Run("context cancellation is honored", testRateLimitedContextCancellationIsHonored)
+	t.Run("no rate means no limit", testRateLimitedNoRateMeansNoLimit)
+}
+
+func testRateLimitedFailFastWhenBudgetIsExceeded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRateLimited(cache, xcache.RateLimitConfig{Rate: 20, Burst: 2})
+		ctx     = context.Background()
+		key     = "test-ratelimit-key"
+	)
+
+	// act
+	_, err1 := subject.TTL(ctx, key)
+	_, err2 := subject.Load(ctx, key)
+	_, err3 := subject.Load(ctx, key)
+	errDelete := subject.Save(ctx, key, nil, -1) // deletions wait for their turn.
+
+	// assert
+	assertTrue(t, !errors.Is(err1, xcache.ErrRateLimited))
+	assertTrue(t, !errors.Is(err2, xcache.ErrRateLimited))
+	assertTrue(t, errors.Is(err3, xcache.ErrRateLimited))
+	assertNil(t, errDelete)
+	assertEqual(t, 1, cache.TTLCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertEqual(t, 1, cache.SaveCallsCount())
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"ratelimit.limited": 2, "ratelimit.rejected": 1}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testRateLimitedWaitWhenBudgetIsExceeded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRateLimited(cache, xcache.RateLimitConfig{Rate: 50, Burst: 1, Wait: true})
+		ctx     = context.Background()
+		value   = []byte("test value")
+	)
+
+	// act
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		requireNil(t, subject.Save(ctx, "test-ratelimit-wait-key", value, time.Minute))
+	}
+	elapsed := time.Since(start)
+
+	// assert
+	assertEqual(t, 4, cache.SaveCallsCount())
+	assertTrue(t, elapsed >= 55*time.Millisecond) // 3 x 20ms waits.
+}
+
+func testRateLimitedMaxWaitIsHonored(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRateLimited(cache, xcache.RateLimitConfig{
+			Rate:    10,
+			Burst:   1,
+			Wait:    true,
+			MaxWait: 150 * time.Millisecond,
+		})
+		ctx = context.Background()
+		key = "test-ratelimit-max-wait-key"
+	)
+
+	// act
+	_, err1 := subject.Load(ctx, key)
+	err2Ch := make(chan error, 1)
+	go func() {
+		_, err := subject.Load(ctx, key) // waits ~100ms.
+		err2Ch <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_, err3 := subject.Load(ctx, key) // would wait ~190ms, after the 2nd load.
+	err2 := <-err2Ch
+
+	// assert
+	assertTrue(t, !errors.Is(err1, xcache.ErrRateLimited))
+	assertTrue(t, !errors.Is(err2, xcache.ErrRateLimited))
+	assertTrue(t, errors.Is(err3, xcache.ErrRateLimited))
+	assertEqual(t, 2, cache.LoadCallsCount())
+}
+
+func testRateLimitedContextCancellationIsHonored(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewRateLimited(cache, xcache.RateLimitConfig{Rate: 0.1, Wait: true})
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		key         = "test-ratelimit-ctx-key"
+	)
+	defer cancel()
+
+	// act
+	_, err1 := subject.TTL(ctx, key)
+	_, err2 := subject.TTL(ctx, key)
+
+	// assert
+	assertTrue(t, !errors.Is(err1, context.DeadlineExceeded))
+	assertTrue(t, errors.Is(err2, context.DeadlineExceeded))
+	assertEqual(t, 1, cache.TTLCallsCount())
+}
+
+func testRateLimitedNoRateMeansNoLimit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRateLimited(cache, xcache.RateLimitConfig{})
+		ctx     = context.Background()
+	)
+
+	// act
+	for i := 0; i < 100; i++ {
+		_, _ = subject.Load(ctx, "test-ratelimit-no-rate-key")
+	}
+
+	// assert
+	assertEqual(t, 100, cache.LoadCallsCount())
+}
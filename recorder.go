@@ -0,0 +1,307 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Recorded operations names.
+const (
+	recOpSave  = "save"
+	recOpLoad  = "load"
+	recOpTTL   = "ttl"
+	recOpStats = "stats"
+)
+
+// ErrInvalidRecording is returned by Replay if the recording is malformed.
+var ErrInvalidRecording = errors.New("invalid recording")
+
+// Recorder is a Cache decorator which records the stream of operations
+// performed upon the decorated cache into a writer.
+// The recording can be later replayed against any Cache with Replay,
+// enabling realistic capacity tests of backend changes.
+//
+// Each operation is written on a separate line, as tab separated values:
+//
+//	<unix nano timestamp>	<op>	<key hash>	<value size>	<ttl>
+//
+// Keys are not recorded as they are, only their FNV-1a hash, and values are not
+// recorded at all, only their size, so no sensitive information ends up in the recording.
+// Writes are not buffered, you can pass a [bufio.Writer] if you need that
+// (do not forget to flush it at the end).
+type Recorder struct {
+	cache Cache
+	w     io.Writer
+	buf   []byte
+	err   error // first encountered write error.
+	mu    sync.Mutex
+}
+
+// NewRecorder instantiates a new Recorder which decorates given cache,
+// and writes its operations to w.
+func NewRecorder(cache Cache, w io.Writer) *Recorder {
+	return &Recorder{
+		cache: cache,
+		w:     w,
+		buf:   make([]byte, 0, 64),
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache,
+// and records the operation.
+func (rec *Recorder) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	rec.record(recOpSave, key, len(value), expire)
+
+	return rec.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache, and records the operation.
+func (rec *Recorder) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := rec.cache.Load(ctx, key)
+	rec.record(recOpLoad, key, len(value), 0)
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, and records the operation.
+func (rec *Recorder) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := rec.cache.TTL(ctx, key)
+	rec.record(recOpTTL, key, 0, ttl)
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics, and records the operation.
+func (rec *Recorder) Stats(ctx context.Context) (Stats, error) {
+	rec.record(recOpStats, "", 0, 0)
+
+	return rec.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (rec *Recorder) Unwrap() Cache {
+	return rec.cache
+}
+
+// Err returns the first error encountered while writing the recording, if any.
+func (rec *Recorder) Err() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return rec.err
+}
+
+// record writes an operation line.
+func (rec *Recorder) record(op, key string, size int, ttl time.Duration) {
+	var keyHash uint64
+	if key != "" {
+		keyHash = hashKey(key)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.err != nil {
+		return
+	}
+	rec.buf = rec.buf[:0]
+	rec.buf = strconv.AppendInt(rec.buf, time.Now().UnixNano(), 10)
+	rec.buf = append(rec.buf, '\t')
+	rec.buf = append(rec.buf, op...)
+	rec.buf = append(rec.buf, '\t')
+	rec.buf = strconv.AppendUint(rec.buf, keyHash, 16)
+	rec.buf = append(rec.buf, '\t')
+	rec.buf = strconv.AppendInt(rec.buf, int64(size), 10)
+	rec.buf = append(rec.buf, '\t')
+	rec.buf = strconv.AppendInt(rec.buf, int64(ttl), 10)
+	rec.buf = append(rec.buf, '\n')
+	_, rec.err = rec.w.Write(rec.buf)
+}
+
+// hashKey returns the FNV-1a hash of given key.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+// ReplayResult holds information about a replayed recording.
+type ReplayResult struct {
+	// Ops represents the number of replayed operations.
+	Ops int64
+	// Errors represents the number of operations which returned an error
+	// (other than ErrNotFound).
+	Errors int64
+	// Elapsed represents the total duration of the replay.
+	Elapsed time.Duration
+}
+
+// Replay drives given cache with the workload recorded by a Recorder.
+//
+// The speed parameter scales the original pace of operations: 1 replays the
+// workload at original speed, 2 at double speed, 0.5 at half speed, and so on.
+// A speed <= 0 replays the operations as fast as possible.
+//
+// Keys are the hex representation of recorded key hashes, and values are
+// deterministic byte sequences of recorded sizes.
+// Replay stops at the end of the recording, or when context is done.
+// It returns an error if the recording is malformed, or the context's error.
+func Replay(ctx context.Context, cache Cache, r io.Reader, speed float64) (ReplayResult, error) {
+	var (
+		result  ReplayResult
+		scanner = bufio.NewScanner(r)
+		start   = time.Now()
+		firstTs int64
+		value   []byte
+	)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			result.Elapsed = time.Since(start)
+
+			return result, err
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		rop, err := parseRecordedOp(line)
+		if err != nil {
+			result.Elapsed = time.Since(start)
+
+			return result, err
+		}
+
+		if speed > 0 {
+			if firstTs == 0 {
+				firstTs = rop.ts
+			}
+			offset := time.Duration(float64(rop.ts-firstTs) / speed)
+			if wait := offset - time.Since(start); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					result.Elapsed = time.Since(start)
+
+					return result, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		key := strconv.FormatUint(rop.keyHash, 16)
+		switch rop.op {
+		case recOpSave:
+			if rop.ttl < 0 {
+				err = cache.Save(ctx, key, nil, rop.ttl)
+			} else {
+				value = replayValue(value, rop.size)
+				err = cache.Save(ctx, key, value[:rop.size], rop.ttl)
+			}
+		case recOpLoad:
+			_, err = cache.Load(ctx, key)
+		case recOpTTL:
+			_, err = cache.TTL(ctx, key)
+		case recOpStats:
+			_, err = cache.Stats(ctx)
+		}
+		result.Ops++
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			result.Errors++
+		}
+	}
+	result.Elapsed = time.Since(start)
+
+	return result, scanner.Err()
+}
+
+// recordedOp is a parsed line from a recording.
+type recordedOp struct {
+	ts      int64
+	op      string
+	keyHash uint64
+	size    int
+	ttl     time.Duration
+}
+
+// parseRecordedOp parses a recording line.
+func parseRecordedOp(line []byte) (recordedOp, error) {
+	var (
+		rop    recordedOp
+		fields [5][]byte
+		fIdx   int
+		start  int
+		err    error
+	)
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == '\t' {
+			if fIdx == len(fields) {
+				return rop, ErrInvalidRecording
+			}
+			fields[fIdx] = line[start:i]
+			fIdx++
+			start = i + 1
+		}
+	}
+	if fIdx != len(fields) {
+		return rop, ErrInvalidRecording
+	}
+
+	if rop.ts, err = strconv.ParseInt(bytesToString(fields[0]), 10, 64); err != nil {
+		return rop, ErrInvalidRecording
+	}
+	rop.op = string(fields[1])
+	switch rop.op {
+	case recOpSave, recOpLoad, recOpTTL, recOpStats:
+	default:
+		return rop, ErrInvalidRecording
+	}
+	if rop.keyHash, err = strconv.ParseUint(bytesToString(fields[2]), 16, 64); err != nil {
+		return rop, ErrInvalidRecording
+	}
+	size, err := strconv.ParseInt(bytesToString(fields[3]), 10, 64)
+	if err != nil || size < 0 {
+		return rop, ErrInvalidRecording
+	}
+	rop.size = int(size)
+	ttl, err := strconv.ParseInt(bytesToString(fields[4]), 10, 64)
+	if err != nil {
+		return rop, ErrInvalidRecording
+	}
+	rop.ttl = time.Duration(ttl)
+
+	return rop, nil
+}
+
+// replayValue returns a deterministic byte sequence of at least given size,
+// reusing given buffer if it is large enough.
+func replayValue(buf []byte, size int) []byte {
+	if len(buf) >= size {
+		return buf
+	}
+	buf = make([]byte, size)
+	for i := range buf {
+		buf[i] = 'a' + byte(i%26)
+	}
+
+	return buf
+}
@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Recorder)(nil) // test Recorder is a Cache
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("operations are recorded", testRecorderRecordsOperations)
+	t.Run("recording is replayed", testRecorderRecordingIsReplayed)
+	t.Run("replay at scaled speed", testRecorderReplayAtScaledSpeed)
+	t.Run("replay malformed recording", testRecorderReplayMalformedRecording)
+	t.Run("replay with done context", testRecorderReplayWithDoneContext)
+}
+
+func testRecorderRecordsOperations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		buf     bytes.Buffer
+		subject = xcache.NewRecorder(cache, &buf)
+		ctx     = context.Background()
+		key     = "test-recorder-key"
+		value   = []byte("test value")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return value, nil
+	})
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return time.Minute, nil
+	})
+
+	// act
+	_ = subject.Save(ctx, key, value, time.Minute)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.TTL(ctx, key)
+	_, _ = subject.Stats(ctx)
+	_ = subject.Save(ctx, key, nil, -1)
+
+	// assert
+	assertNil(t, subject.Err())
+	assertEqual(t, 2, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertEqual(t, 1, cache.TTLCallsCount())
+	assertEqual(t, 1, cache.StatsCallsCount())
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if assertEqual(t, 5, len(lines)) {
+		expectedOps := []string{"save", "load", "ttl", "stats", "save"}
+		expectedSizes := []string{"10", "10", "0", "0", "0"}
+		expectedTTLs := []string{"60000000000", "0", "60000000000", "0", "-1"}
+		for i, line := range lines {
+			fields := strings.Split(line, "\t")
+			if !assertEqual(t, 5, len(fields)) {
+				continue
+			}
+			assertEqual(t, expectedOps[i], fields[1])
+			assertTrue(t, !strings.Contains(line, key))
+			assertEqual(t, expectedSizes[i], fields[3])
+			assertEqual(t, expectedTTLs[i], fields[4])
+		}
+	}
+}
+
+func testRecorderRecordingIsReplayed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		buf      bytes.Buffer
+		recorder = xcache.NewRecorder(xcache.NewMemory(1), &buf)
+		subject  = xcache.NewMemory(1)
+		ctx      = context.Background()
+		key      = "test-recorder-replay-key"
+	)
+	_ = recorder.Save(ctx, key, []byte("test value"), time.Minute)
+	_, _ = recorder.Load(ctx, key)
+	_, _ = recorder.Load(ctx, "test-recorder-replay-not-found-key")
+	_, _ = recorder.TTL(ctx, key)
+	_, _ = recorder.Stats(ctx)
+
+	// act
+	result, err := xcache.Replay(ctx, subject, &buf, 0)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, int64(5), result.Ops)
+	assertEqual(t, int64(0), result.Errors)
+	stats, _ := subject.Stats(ctx)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, int64(1), stats.Hits)
+	assertEqual(t, int64(1), stats.Misses)
+}
+
+func testRecorderReplayAtScaledSpeed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = new(xcache.Mock)
+		recording = "1000000000\tsave\tabc\t5\t0\n" +
+			"1200000000\tload\tabc\t5\t0\n"
+		ctx = context.Background()
+	)
+	cache.SetSaveCallback(func(_ context.Context, key string, value []byte, exp time.Duration) error {
+		assertEqual(t, "abc", key)
+		assertEqual(t, []byte("abcde"), value)
+		assertEqual(t, xcache.NoExpire, exp)
+
+		return nil
+	})
+
+	// act
+	result, err := xcache.Replay(ctx, cache, strings.NewReader(recording), 2)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, int64(2), result.Ops)
+	assertTrue(t, result.Elapsed >= 100*time.Millisecond) // 200ms at double speed
+	assertEqual(t, 1, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+}
+
+func testRecorderReplayMalformedRecording(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name      string
+		recording string
+	}{
+		{"missing fields", "1000000000\tsave\tabc\t5\n"},
+		{"too many fields", "1000000000\tsave\tabc\t5\t0\t1\n"},
+		{"invalid timestamp", "x\tsave\tabc\t5\t0\n"},
+		{"invalid op", "1000000000\tdrop\tabc\t5\t0\n"},
+		{"invalid key hash", "1000000000\tsave\txyz\t5\t0\n"},
+		{"invalid size", "1000000000\tsave\tabc\t-5\t0\n"},
+		{"invalid ttl", "1000000000\tsave\tabc\t5\tx\n"},
+	}
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result, err := xcache.Replay(context.Background(), xcache.Nop{}, strings.NewReader(test.recording), 0)
+
+			// assert
+			assertTrue(t, errors.Is(err, xcache.ErrInvalidRecording))
+			assertEqual(t, int64(0), result.Ops)
+		})
+	}
+}
+
+func testRecorderReplayWithDoneContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recording      = "1000000000\tload\tabc\t0\t0\n" + "9000000000\tload\tabc\t0\t0\n"
+		ctx, cancelCtx = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	)
+	defer cancelCtx()
+
+	// act
+	result, err := xcache.Replay(ctx, xcache.Nop{}, strings.NewReader(recording), 1)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertEqual(t, int64(1), result.Ops)
+}
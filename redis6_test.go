@@ -11,7 +11,13 @@ import (
 )
 
 func init() {
-	var _ xcache.Cache = (*xcache.Redis6)(nil) // test Redis6 is a Cache
+	var _ xcache.Cache = (*xcache.Redis6)(nil)            // test Redis6 is a Cache
+	var _ xcache.Deleter = (*xcache.Redis6)(nil)          // test Redis6 is a Deleter
+	var _ xcache.ExistenceChecker = (*xcache.Redis6)(nil) // test Redis6 is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Redis6)(nil)          // test Redis6 is a Batcher
+	var _ xcache.BulkDeleter = (*xcache.Redis6)(nil)      // test Redis6 is a BulkDeleter
+	var _ xcache.Toucher = (*xcache.Redis6)(nil)          // test Redis6 is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Redis6)(nil)        // test Redis6 is a TTLKeeper
 }
 
 func ExampleRedis6() {
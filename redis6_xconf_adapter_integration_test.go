@@ -17,6 +17,7 @@ import (
 
 	"github.com/actforgood/xcache"
 	"github.com/actforgood/xconf"
+	redis6 "github.com/go-redis/redis/v8"
 )
 
 func TestRedis6_withXConf_integration(t *testing.T) {
@@ -63,13 +64,21 @@ func testRedis6WithXConfConfigIsChanged(t *testing.T) {
 			configLoader,
 			xconf.DefaultConfigWithReloadInterval(time.Second),
 		)
-		subject   = xcache.NewRedis6WithConfig(config)
-		keyPrefix = "test-xconf-withconfigchange-key-"
-		value     = []byte("test value")
-		ctx       = context.Background()
+		subject         = xcache.NewRedis6WithConfig(config)
+		keyPrefix       = "test-xconf-withconfigchange-key-"
+		value           = []byte("test value")
+		ctx             = context.Background()
+		hook            = new(redis6CountingHook)
+		reconfiguredCnt uint32
 	)
 	defer config.Close()
 	defer subject.Close()
+	subject.AddHook(hook)
+	subject.OnReconfigure(func(oldConfig, newConfig xcache.RedisConfig) {
+		atomic.AddUint32(&reconfiguredCnt, 1)
+		assertEqual(t, 0, oldConfig.DB)
+		assertEqual(t, 1, newConfig.DB)
+	})
 	// save some keys
 	for i := 0; i < 10; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
@@ -88,6 +97,8 @@ func testRedis6WithXConfConfigIsChanged(t *testing.T) {
 		_, err := subject.Load(ctx, key)
 		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
 	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&reconfiguredCnt))
+	assertEqual(t, uint32(20), atomic.LoadUint32(&hook.calls)) // hook survived reconfiguration.
 }
 
 func testRedis6WithXConfConfigIsNotChanged(t *testing.T) {
@@ -183,3 +194,26 @@ func TestRedis6_withXConf_concurrency(t *testing.T) {
 
 	t.Logf("config changed %d times during test", (readTimeout-3*time.Second)/time.Second)
 }
+
+// redis6CountingHook is a hook which counts processed commands.
+type redis6CountingHook struct {
+	calls uint32
+}
+
+func (hook *redis6CountingHook) BeforeProcess(ctx context.Context, _ redis6.Cmder) (context.Context, error) {
+	atomic.AddUint32(&hook.calls, 1)
+
+	return ctx, nil
+}
+
+func (*redis6CountingHook) AfterProcess(context.Context, redis6.Cmder) error {
+	return nil
+}
+
+func (*redis6CountingHook) BeforeProcessPipeline(ctx context.Context, _ []redis6.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (*redis6CountingHook) AfterProcessPipeline(context.Context, []redis6.Cmder) error {
+	return nil
+}
@@ -8,7 +8,9 @@ package xcache
 import (
 	"context"
 	"errors"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,9 +23,14 @@ import (
 // application shutdown.
 type Redis7 struct {
 	client               redis7.UniversalClient
-	isCluster            bool          // flag indicating if cache is on a Cluster setup.
-	statsInfoKeyPrefixes []string      // stats INFO command keys.
-	mu                   *sync.RWMutex // concurrency semaphore used for xconf adapter.
+	isCluster            bool                   // flag indicating if cache is on a Cluster setup.
+	disableUnlink        bool                   // flag indicating if DEL should be used instead of UNLINK.
+	statsInfoKeyPrefixes []string               // stats INFO command keys.
+	statsCache           *statsCache            // last retrieved stats, if RedisConfig.StatsCacheTTL is set.
+	mu                   *sync.RWMutex          // concurrency semaphore used for xconf adapter.
+	config               RedisConfig            // current configuration.
+	hooks                []redis7.Hook          // client hooks, to be re-applied on client reinitialization.
+	onReconfigure        []redisReconfigureFunc // callbacks executed after reinitialization.
 }
 
 // NewRedis7 instantiates a new Redis7 Cache instance (compatible with Redis ver.7).
@@ -33,8 +40,11 @@ type Redis7 struct {
 // 3. Otherwise, a single-node Client is used.
 func NewRedis7(config RedisConfig) *Redis7 {
 	cache := &Redis7{
-		client:    redis7.NewUniversalClient(getRedis7UniversalOptions(config)),
-		isCluster: config.IsCluster(),
+		client:        redis7.NewUniversalClient(getRedis7UniversalOptions(config)),
+		isCluster:     config.IsCluster(),
+		disableUnlink: config.DisableUnlink,
+		statsCache:    newStatsCache(config.StatsCacheTTL),
+		config:        config,
 	}
 	cache.setStatsKeyPrefixes(config.DB)
 
@@ -43,7 +53,7 @@ func NewRedis7(config RedisConfig) *Redis7 {
 
 // setStatsKeyPrefixes sets key prefixes used to find Stats.
 // If it's not a cluster configuration, adds the keys count prefix,
-// otherwise, this information is not retrieved.
+// otherwise, this information is not retrieved from INFO (see RedisConfig.CountClusterKeys).
 func (cache *Redis7) setStatsKeyPrefixes(db int) {
 	if cache.isCluster {
 		cache.statsInfoKeyPrefixes = make([]string, len(clusterMasterKeyPrefixes))
@@ -58,7 +68,8 @@ func (cache *Redis7) setStatsKeyPrefixes(db int) {
 }
 
 // Save stores the given key-value with expiration period into cache.
-// An expiration period equal to 0 (NoExpire) means no expiration.
+// An expiration period equal to 0 (NoExpire) means no expiration
+// (see also RedisConfig.ForbidNoExpire / RedisConfig.NoExpireTTL).
 // A negative expiration period triggers deletion of key.
 // It returns an error if the key could not be saved.
 func (cache *Redis7) Save(
@@ -67,21 +78,196 @@ func (cache *Redis7) Save(
 	value []byte,
 	expire time.Duration,
 ) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
 	cache.rLock()
 	defer cache.rUnlock()
 
-	if expire < 0 {
-		return cache.client.Del(ctx, key).Err()
+	expire, err := cache.config.noExpirePolicy(expire)
+	if err != nil {
+		return err
+	}
+
+	return cache.client.Set(ctx, cache.config.prefixKey(key), value, expire).Err()
+}
+
+// SaveKeepTTL stores the given value for a key, keeping key's remaining time to live (SET with KEEPTTL).
+// A key which does not exist is saved as with NoExpire (see also RedisConfig.ForbidNoExpire / RedisConfig.NoExpireTTL).
+// It returns an error if the key could not be saved.
+func (cache *Redis7) SaveKeepTTL(ctx context.Context, key string, value []byte) error {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	key = cache.config.prefixKey(key)
+	expire, policyErr := cache.config.noExpirePolicy(NoExpire)
+	if expire == NoExpire && policyErr == nil {
+		return cache.client.Set(ctx, key, value, redis7.KeepTTL).Err()
+	}
+
+	// a missing key is subject to the NoExpire policy, so the value is set only if the key exists.
+	err := cache.client.SetArgs(ctx, key, value, redis7.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if !errors.Is(err, redis7.Nil) {
+		return err
+	}
+	if policyErr != nil {
+		return policyErr
 	}
 
 	return cache.client.Set(ctx, key, value, expire).Err()
 }
 
+// SaveMany stores the given items into cache, in a single round trip (pipelined SET / UNLINK commands).
+// An item's expiration period equal to 0 (NoExpire) means no expiration,
+// a negative one triggers deletion of key.
+// It returns an error if any of the items could not be saved (if an item without expiration is forbidden,
+// see RedisConfig.ForbidNoExpire, nothing is saved).
+func (cache *Redis7) SaveMany(ctx context.Context, items map[string]Item) error {
+	if err := ctx.Err(); err != nil { // pipeline may not check it before sending the commands.
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	pipe := cache.client.Pipeline()
+	for key, item := range items {
+		key = cache.config.prefixKey(key)
+		switch {
+		case item.Expire >= 0:
+			expire, err := cache.config.noExpirePolicy(item.Expire)
+			if err != nil {
+				return err
+			}
+			pipe.Set(ctx, key, item.Value, expire)
+		case cache.disableUnlink:
+			pipe.Del(ctx, key)
+		default:
+			pipe.Unlink(ctx, key)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// LoadMany returns the values of given keys from cache, in a single round trip, with MGET
+// (on a cluster, keys are grouped by hash slot, and a MGET is pipelined for each group).
+// Keys not found are missing from the returned map.
+// It returns an error if something bad happened.
+func (cache *Redis7) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil { // pipeline may not check it before sending the commands.
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	groups := redisGroupKeys(cache.config.prefixKeys(keys), cache.isCluster)
+	pipe := cache.client.Pipeline()
+	cmds := make([]*redis7.SliceCmd, len(groups))
+	for i, group := range groups {
+		cmds[i] = pipe.MGet(ctx, group...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	for i, cmd := range cmds {
+		for j, value := range cmd.Val() {
+			if str, ok := value.(string); ok { // nil for a key not found.
+				values[strings.TrimPrefix(groups[i][j], cache.config.KeyPrefix)] = []byte(str)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// Delete deletes the given key from cache, with UNLINK (or DEL, if unlinking is disabled).
+// It returns an error if the key could not be deleted.
+func (cache *Redis7) Delete(ctx context.Context, key string) error {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	_, err := redis7Delete(ctx, cache.client, []string{cache.config.prefixKey(key)}, false, cache.disableUnlink)
+
+	return err
+}
+
+// DeleteMany deletes the given keys from cache, with UNLINK (or DEL, if UNLINK is disabled).
+// On a cluster, keys are grouped by hash slot, and a command is pipelined for each group
+// (as a multi-key command fails if keys belong to different slots).
+// It returns an error if something bad happened.
+func (cache *Redis7) DeleteMany(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	_, err := redis7Delete(ctx, cache.client, cache.config.prefixKeys(keys), cache.isCluster, cache.disableUnlink)
+
+	return err
+}
+
+// Has returns true if the given key exists in cache, with EXISTS.
+// It returns an error if something bad happened.
+func (cache *Redis7) Has(ctx context.Context, key string) (bool, error) {
+	cache.rLock()
+	exists, err := cache.client.Exists(ctx, cache.config.prefixKey(key)).Result()
+	cache.rUnlock()
+
+	return exists > 0, err
+}
+
+// Touch sets the given expiration period to an existing key, with EXPIRE (or PERSIST, for NoExpire).
+// A negative expiration period triggers deletion of key.
+// NoExpire is subject to RedisConfig.ForbidNoExpire / RedisConfig.NoExpireTTL, like on Save.
+// It returns false if the key does not exist, or an error if something bad happened.
+func (cache *Redis7) Touch(ctx context.Context, key string, expire time.Duration) (bool, error) {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	expire, err := cache.config.noExpirePolicy(expire)
+	if err != nil {
+		return false, err
+	}
+	key = cache.config.prefixKey(key)
+	switch {
+	case expire < 0:
+		deleted, err := redis7Delete(ctx, cache.client, []string{key}, false, cache.disableUnlink)
+
+		return deleted > 0, err
+	case expire == NoExpire:
+		// PERSIST replies 0 also for an existing key without expiration, so existence is checked, too.
+		pipe := cache.client.Pipeline()
+		persistCmd := pipe.Persist(ctx, key)
+		existsCmd := pipe.Exists(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return false, err
+		}
+
+		return persistCmd.Val() || existsCmd.Val() > 0, nil
+	default:
+		return cache.client.Expire(ctx, key, expire).Result()
+	}
+}
+
 // Load returns a key's value from cache, or an error if something bad happened.
 // If the key is not found, ErrNotFound is returned.
 func (cache *Redis7) Load(ctx context.Context, key string) ([]byte, error) {
 	cache.rLock()
-	value, err := cache.client.Get(ctx, key).Bytes()
+	value, err := cache.client.Get(ctx, cache.config.prefixKey(key)).Bytes()
 	cache.rUnlock()
 
 	if errors.Is(err, redis7.Nil) {
@@ -96,7 +282,7 @@ func (cache *Redis7) Load(ctx context.Context, key string) ([]byte, error) {
 // If the key has no expiration, 0 (NoExpire) is returned.
 func (cache *Redis7) TTL(ctx context.Context, key string) (time.Duration, error) {
 	cache.rLock()
-	ttl, err := cache.client.TTL(ctx, key).Result()
+	ttl, err := cache.client.TTL(ctx, cache.config.prefixKey(key)).Result()
 	cache.rUnlock()
 
 	if err != nil || ttl == 0 {
@@ -112,33 +298,58 @@ func (cache *Redis7) TTL(ctx context.Context, key string) (time.Duration, error)
 // Stats returns some statistics about cache memory/keys.
 // It returns an error if something goes wrong (for example,
 // client might not be able to connect to Redis server).
+// If RedisConfig.StatsCacheTTL is set, retrieved stats are reused for that period.
+// On a Cluster setup, the no. of keys is retrieved only if RedisConfig.CountClusterKeys is set.
+// If RedisConfig.CountPrefixKeys is set, only the keys having RedisConfig.KeyPrefix are counted, with SCAN,
+// which iterates the whole keyspace (O(N)), consider setting RedisConfig.StatsCacheTTL, too.
 func (cache *Redis7) Stats(ctx context.Context) (Stats, error) {
 	cache.rLock()
 	defer cache.rUnlock()
 
+	return cache.statsCache.load(ctx, cache.getStats)
+}
+
+// getStats retrieves the stats from Redis server(s).
+func (cache *Redis7) getStats(ctx context.Context) (Stats, error) {
 	if cache.isCluster {
 		if clusterClient, ok := cache.client.(*redis7.ClusterClient); ok {
 			return cache.getClusterStats(ctx, clusterClient)
 		}
 	}
 
-	info, err := cache.client.Info(ctx).Bytes()
+	info, err := cache.client.Info(ctx, redisInfoSectionMemory, redisInfoSectionStats, redisInfoSectionKeyspace).Bytes()
 	if err != nil {
 		return Stats{}, err
 	}
 
-	return parseInfoStats(info, cache.statsInfoKeyPrefixes), nil
+	stats := parseInfoStats(info, cache.statsInfoKeyPrefixes)
+	if cache.config.CountPrefixKeys {
+		// the database may be shared with other applications, count only own keys.
+		stats.Keys, err = redis7CountMatching(ctx, cache.client, redisEscapeGlob(cache.config.KeyPrefix)+"*")
+		if err != nil {
+			return Stats{}, err
+		}
+	}
+
+	return stats, nil
 }
 
 func (cache *Redis7) getClusterStats(ctx context.Context, cc *redis7.ClusterClient) (Stats, error) {
 	var stats Stats
 	err := cc.ForEachMaster(ctx, func(ctxx context.Context, client *redis7.Client) error {
-		info, errInfo := client.Info(ctxx).Bytes()
+		info, errInfo := client.Info(ctxx, redisInfoSectionMemory, redisInfoSectionStats).Bytes()
 		if errInfo != nil {
 			return errInfo
 		}
 
 		masterStats := parseInfoStats(info, cache.statsInfoKeyPrefixes)
+		if cache.config.CountClusterKeys {
+			masterStats.Keys, errInfo = cache.countNodeKeys(ctxx, client)
+			if errInfo != nil {
+				return errInfo
+			}
+		}
+		atomic.AddInt64(&stats.Keys, masterStats.Keys)
 		atomic.AddInt64(&stats.Memory, masterStats.Memory)
 		atomic.AddInt64(&stats.MaxMemory, masterStats.MaxMemory)
 		atomic.AddInt64(&stats.Hits, masterStats.Hits)
@@ -154,7 +365,7 @@ func (cache *Redis7) getClusterStats(ctx context.Context, cc *redis7.ClusterClie
 	// If ReadOnly option is enabled, requests will end up on replicas,
 	// we must take into account the hits and misses from there.
 	err = cc.ForEachSlave(ctx, func(ctxx context.Context, client *redis7.Client) error {
-		info, errInfo := client.Info(ctxx, "stats").Bytes()
+		info, errInfo := client.Info(ctxx, redisInfoSectionStats).Bytes()
 		if errInfo != nil {
 			return errInfo
 		}
@@ -172,6 +383,504 @@ func (cache *Redis7) getClusterStats(ctx context.Context, cc *redis7.ClusterClie
 	return stats, nil
 }
 
+// countNodeKeys returns the no. of keys of given cluster node, with DBSIZE,
+// or, if RedisConfig.CountPrefixKeys is set, the no. of keys having RedisConfig.KeyPrefix, with SCAN.
+func (cache *Redis7) countNodeKeys(ctx context.Context, client *redis7.Client) (int64, error) {
+	if cache.config.CountPrefixKeys {
+		return redis7CountMatching(ctx, client, redisEscapeGlob(cache.config.KeyPrefix)+"*")
+	}
+
+	return client.DBSize(ctx).Result()
+}
+
+// KeyspaceStats returns the keys statistics of each (non-empty) logical database of Redis server,
+// sorted by database index. It can be useful to see how much of the instance
+// the configured database (RedisConfig.DB) occupies.
+// On a Cluster setup, statistics are summed up from all masters.
+// It returns an error if something goes wrong (for example,
+// client might not be able to connect to Redis server).
+func (cache *Redis7) KeyspaceStats(ctx context.Context) ([]RedisDBStats, error) {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	if cache.isCluster {
+		if clusterClient, ok := cache.client.(*redis7.ClusterClient); ok {
+			var (
+				dbsStats []RedisDBStats
+				mu       sync.Mutex
+			)
+			err := clusterClient.ForEachMaster(ctx, func(ctxx context.Context, client *redis7.Client) error {
+				info, errInfo := client.Info(ctxx, redisInfoSectionKeyspace).Bytes()
+				if errInfo != nil {
+					return errInfo
+				}
+				nodeDBsStats := parseInfoKeyspace(info)
+				mu.Lock()
+				dbsStats = mergeRedisDBStats(dbsStats, nodeDBsStats)
+				mu.Unlock()
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return dbsStats, nil
+		}
+	}
+
+	info, err := cache.client.Info(ctx, redisInfoSectionKeyspace).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInfoKeyspace(info), nil
+}
+
+// DeletePrefix deletes all keys starting with given prefix.
+// Keys are iterated with SCAN and deleted in batches with UNLINK, or DEL if
+// RedisConfig.DisableUnlink is set (on each master node, on a Cluster setup).
+// It returns the number of deleted keys, or an error if something bad happened.
+func (cache *Redis7) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	match := redisEscapeGlob(cache.config.KeyPrefix+prefix) + "*"
+	if cache.isCluster {
+		if clusterClient, ok := cache.client.(*redis7.ClusterClient); ok {
+			var deleted int64
+			err := clusterClient.ForEachMaster(ctx, func(ctxx context.Context, client *redis7.Client) error {
+				nodeDeleted, err := redis7DeleteMatching(ctxx, client, match, true, cache.disableUnlink)
+				atomic.AddInt64(&deleted, nodeDeleted)
+
+				return err
+			})
+
+			return int(deleted), err
+		}
+	}
+
+	deleted, err := redis7DeleteMatching(ctx, cache.client, match, false, cache.disableUnlink)
+
+	return int(deleted), err
+}
+
+// MemoryUsageSample estimates the memory consumed by keys matching given pattern (glob-style, as SCAN's MATCH option),
+// without an offline (BGSAVE based) analysis.
+// Keys are iterated with SCAN (on each master node, on a Cluster setup) until given no. of keys is sampled,
+// and the memory of each one of them is retrieved with MEMORY USAGE.
+// If sample size is not positive, 1000 keys are sampled.
+// Pattern is matched against keys without RedisConfig.KeyPrefix.
+// It returns an error if something goes wrong (for example,
+// client might not be able to connect to Redis server).
+//
+// Example:
+//
+//	usage, err := cache.MemoryUsageSample(ctx, "user:*", 500)
+func (cache *Redis7) MemoryUsageSample(ctx context.Context, pattern string, sampleSize int) (RedisMemoryUsage, error) {
+	if sampleSize <= 0 {
+		sampleSize = redisMemoryUsageDefaultSampleSize
+	}
+	remaining := int64(sampleSize)
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	pattern = redisEscapeGlob(cache.config.KeyPrefix) + pattern
+	if cache.isCluster {
+		if clusterClient, ok := cache.client.(*redis7.ClusterClient); ok {
+			var (
+				usage   = RedisMemoryUsage{Complete: true}
+				usageMu sync.Mutex
+			)
+			err := clusterClient.ForEachMaster(ctx, func(ctxx context.Context, client *redis7.Client) error {
+				nodeUsage, err := redis7MemoryUsageMatching(ctxx, client, pattern, &remaining)
+				usageMu.Lock()
+				usage.SampledKeys += nodeUsage.SampledKeys
+				usage.SampledBytes += nodeUsage.SampledBytes
+				usage.Complete = usage.Complete && nodeUsage.Complete
+				usageMu.Unlock()
+
+				return err
+			})
+			if err != nil {
+				return RedisMemoryUsage{}, err
+			}
+
+			return usage, nil
+		}
+	}
+
+	return redis7MemoryUsageMatching(ctx, cache.client, pattern, &remaining)
+}
+
+// redis7MemoryUsageMatching retrieves with MEMORY USAGE the memory of keys matching given pattern,
+// until the remaining sample size (shared between Cluster nodes) is consumed.
+func redis7MemoryUsageMatching(
+	ctx context.Context,
+	client redis7.Cmdable,
+	match string,
+	remaining *int64,
+) (RedisMemoryUsage, error) {
+	var (
+		cursor uint64
+		usage  RedisMemoryUsage
+	)
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return usage, err
+		}
+		if len(keys) > 0 {
+			keysNo := int64(len(keys))
+			available := atomic.AddInt64(remaining, -keysNo) + keysNo
+			if available <= 0 {
+				return usage, nil
+			}
+			if available < keysNo {
+				keys = keys[:available]
+			}
+			if err := redis7MemoryUsage(ctx, client, keys, &usage); err != nil {
+				return usage, err
+			}
+			if available < keysNo {
+				return usage, nil
+			}
+		}
+		if nextCursor == 0 {
+			usage.Complete = true
+
+			return usage, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// redis7MemoryUsage adds to given usage the memory of given keys, retrieved with MEMORY USAGE, in a pipeline.
+func redis7MemoryUsage(ctx context.Context, client redis7.Cmdable, keys []string, usage *RedisMemoryUsage) error {
+	pipe := client.Pipeline()
+	cmds := make([]*redis7.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.MemoryUsage(ctx, key)
+	}
+	_, _ = pipe.Exec(ctx) // commands' errors are checked below.
+	for _, cmd := range cmds {
+		keyBytes, err := cmd.Result()
+		if errors.Is(err, redis7.Nil) { // key was deleted / has expired meanwhile.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		usage.SampledKeys++
+		usage.SampledBytes += keyBytes
+	}
+
+	return nil
+}
+
+// Scan returns a batch of keys starting with given prefix, and the cursor to continue the iteration with.
+// See [Scanner] for more details.
+// Keys are iterated with SCAN, node by node (in the order of their addresses), on a Cluster setup.
+//
+// Note: SCAN guarantees are preserved, a key present during the whole iteration
+// is returned at least once, but it may be returned multiple times.
+func (cache *Redis7) Scan(ctx context.Context, cursor, prefix string, count int) ([]string, string, error) {
+	if count <= 0 {
+		count = scanDefaultCount
+	}
+
+	cache.rLock()
+	defer cache.rUnlock()
+
+	match := redisEscapeGlob(cache.config.KeyPrefix+prefix) + "*"
+	if cache.isCluster {
+		if clusterClient, ok := cache.client.(*redis7.ClusterClient); ok {
+			keys, nextCursor, err := redis7ClusterScan(ctx, clusterClient, cursor, match, count)
+
+			return cache.config.unprefixKeys(keys), nextCursor, err
+		}
+	}
+
+	nodeCursor, err := decodeUintCursor(cursor, cursorKindRedis)
+	if err != nil {
+		return nil, "", err
+	}
+	keys, nextCursor, err := cache.client.Scan(ctx, nodeCursor, match, int64(count)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	keys = cache.config.unprefixKeys(keys)
+	if nextCursor == 0 {
+		return keys, "", nil
+	}
+
+	return keys, encodeUintCursor(cursorKindRedis, nextCursor), nil
+}
+
+// redis7ClusterScan scans the master node stored in given cursor, and returns
+// the cursor to continue the iteration with, on the same node, or on the next one.
+func redis7ClusterScan(
+	ctx context.Context,
+	client *redis7.ClusterClient,
+	cursor, match string,
+	count int,
+) ([]string, string, error) {
+	addr, nodeCursor, err := decodeRedisClusterCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		nodes   = make(map[string]*redis7.Client)
+		nodesMu sync.Mutex
+	)
+	err = client.ForEachMaster(ctx, func(_ context.Context, node *redis7.Client) error {
+		nodesMu.Lock()
+		nodes[node.Options().Addr] = node
+		nodesMu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// resume from the stored node, or from the next one, if it's no longer a master.
+	addrs := sortedKeys(nodes)
+	idx := sort.SearchStrings(addrs, addr)
+	if idx == len(addrs) {
+		return nil, "", nil
+	}
+	if addrs[idx] != addr {
+		nodeCursor = 0
+	}
+
+	keys, nextCursor, err := nodes[addrs[idx]].Scan(ctx, nodeCursor, match, int64(count)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	if nextCursor == 0 {
+		idx++
+		if idx == len(addrs) {
+			return keys, "", nil
+		}
+	}
+
+	return keys, encodeRedisClusterCursor(addrs[idx], nextCursor), nil
+}
+
+// redis7DeleteMatching deletes keys matching given pattern, and returns the number of deleted keys.
+// Flag perSlot specifies whether keys should be deleted grouped by hash slot (in a pipeline),
+// as it's the case of a Cluster node, where a multi-key command fails if keys belong to different slots.
+func redis7DeleteMatching(
+	ctx context.Context,
+	client redis7.Cmdable,
+	match string,
+	perSlot, useDel bool,
+) (int64, error) {
+	var (
+		cursor  uint64
+		deleted int64
+	)
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			batchDeleted, err := redis7Delete(ctx, client, keys, perSlot, useDel)
+			deleted += batchDeleted
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if nextCursor == 0 {
+			return deleted, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// redis7CountMatching returns the number of keys matching given pattern, iterated with SCAN.
+func redis7CountMatching(ctx context.Context, client redis7.Cmdable, match string) (int64, error) {
+	var (
+		cursor uint64
+		count  int64
+	)
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return count, err
+		}
+		count += int64(len(keys))
+		if nextCursor == 0 {
+			return count, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// redis7Delete deletes given keys with UNLINK, or DEL if useDel flag is set,
+// and returns the number of deleted keys.
+// Flag perSlot specifies whether keys should be grouped by hash slot, a command being pipelined for each group.
+func redis7Delete(ctx context.Context, client redis7.Cmdable, keys []string, perSlot, useDel bool) (int64, error) {
+	del := client.Unlink
+	if useDel {
+		del = client.Del
+	}
+	if !perSlot {
+		return del(ctx, keys...).Result()
+	}
+
+	groups := redisGroupKeys(keys, true)
+	if len(groups) == 1 {
+		return del(ctx, keys...).Result()
+	}
+	pipe := client.Pipeline()
+	delPipe := pipe.Unlink
+	if useDel {
+		delPipe = pipe.Del
+	}
+	cmds := make([]*redis7.IntCmd, len(groups))
+	for i, group := range groups {
+		cmds[i] = delPipe(ctx, group...)
+	}
+	_, err := pipe.Exec(ctx)
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.Val()
+	}
+
+	return deleted, err
+}
+
+// Tx executes given function within a Redis transaction: the saves and deletions performed through
+// the TxCache passed to the function are executed atomically (MULTI / EXEC) after the function returns.
+// If the function returns an error, nothing is executed, and the error is returned.
+//
+// If watch keys are provided, the transaction is executed only if they are not modified (by other clients)
+// since the function started, otherwise ErrTxAborted is returned (optimistic concurrency, with WATCH),
+// so the function can read them, and decide the writes accordingly.
+// On a cluster, all the keys involved in a transaction must belong to the same slot (use hash tags).
+//
+// Example:
+//
+//	err := cache.Tx(ctx, func(tx xcache.TxCache) error {
+//		version, err := tx.Load(ctx, versionKey)
+//		// ...
+//		_ = tx.Save(ctx, key, value, ttl)
+//		_ = tx.Save(ctx, versionKey, nextVersion(version), xcache.NoExpire)
+//
+//		return nil
+//	}, versionKey)
+func (cache *Redis7) Tx(ctx context.Context, fn func(tx TxCache) error, watchKeys ...string) error {
+	cache.rLock()
+	defer cache.rUnlock()
+
+	exec := func(reader redis7.Cmdable, pipe redis7.Pipeliner) error {
+		if err := fn(&redis7TxCache{cache: cache, reader: reader, pipe: pipe}); err != nil {
+			return err
+		}
+		if pipe.Len() == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil { // pipeline may not check it before sending the commands.
+			return err
+		}
+		_, err := pipe.Exec(ctx)
+
+		return err
+	}
+
+	if len(watchKeys) == 0 {
+		return exec(cache.client, cache.client.TxPipeline())
+	}
+	err := cache.client.Watch(ctx, func(tx *redis7.Tx) error {
+		return exec(tx, tx.TxPipeline())
+	}, cache.config.prefixKeys(watchKeys)...)
+	if errors.Is(err, redis7.TxFailedErr) {
+		return ErrTxAborted
+	}
+
+	return err
+}
+
+// redis7TxCache is the TxCache of a Redis7 transaction.
+type redis7TxCache struct {
+	cache  *Redis7
+	reader redis7.Cmdable
+	pipe   redis7.Pipeliner
+}
+
+// Save queues the storing of given key-value with expiration period.
+// A negative expiration period queues the deletion of key.
+func (tx *redis7TxCache) Save(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	if expire < 0 {
+		return tx.Delete(ctx, key)
+	}
+	expire, err := tx.cache.config.noExpirePolicy(expire)
+	if err != nil {
+		return err
+	}
+	tx.pipe.Set(ctx, tx.cache.config.prefixKey(key), value, expire)
+
+	return nil
+}
+
+// Delete queues the deletion of given key, with UNLINK (or DEL, if unlinking is disabled).
+func (tx *redis7TxCache) Delete(ctx context.Context, key string) error {
+	key = tx.cache.config.prefixKey(key)
+	if tx.cache.disableUnlink {
+		tx.pipe.Del(ctx, key)
+	} else {
+		tx.pipe.Unlink(ctx, key)
+	}
+
+	return nil
+}
+
+// Load returns a key's value, read right away.
+// If the key is not found, ErrNotFound is returned.
+func (tx *redis7TxCache) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := tx.reader.Get(ctx, tx.cache.config.prefixKey(key)).Bytes()
+	if errors.Is(err, redis7.Nil) {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+// TTL returns a key's expiration, read right away.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (tx *redis7TxCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := tx.reader.TTL(ctx, tx.cache.config.prefixKey(key)).Result()
+	if err != nil || ttl == 0 {
+		return -1, err
+	}
+	if ttl == redisTTLNoExpire {
+		return NoExpire, nil
+	}
+
+	return ttl, nil
+}
+
+// Stats returns the statistics of the cache the transaction belongs to.
+func (tx *redis7TxCache) Stats(ctx context.Context) (Stats, error) {
+	return tx.cache.statsCache.load(ctx, tx.cache.getStats)
+}
+
+// AddHook adds a hook to the underlying Redis client (for example, a tracing or a metrics hook).
+// Unlike a hook added directly to a client, it is re-applied if the client is reinitialized
+// by the xconf adapter (see NewRedis7WithConfig).
+func (cache *Redis7) AddHook(hook redis7.Hook) {
+	cache.lock()
+	cache.hooks = append(cache.hooks, hook)
+	cache.client.AddHook(hook)
+	cache.unlock()
+}
+
 // Close closes the underlying Redis client.
 func (cache *Redis7) Close() (err error) {
 	cache.rLock()
@@ -181,6 +890,18 @@ func (cache *Redis7) Close() (err error) {
 	return
 }
 
+func (cache *Redis7) lock() {
+	if cache.mu != nil {
+		cache.mu.Lock()
+	}
+}
+
+func (cache *Redis7) unlock() {
+	if cache.mu != nil {
+		cache.mu.Unlock()
+	}
+}
+
 func (cache *Redis7) rLock() {
 	if cache.mu != nil {
 		cache.mu.RLock()
@@ -203,6 +924,7 @@ func getRedis7UniversalOptions(cfg RedisConfig) *redis7.UniversalOptions {
 		DialTimeout:  cfg.DialTimeout,
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
+		TLSConfig:    cfg.TLSConfig,
 
 		ReadOnly: cfg.ReadOnly,
 
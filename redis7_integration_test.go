@@ -10,8 +10,10 @@ package xcache_test
 import (
 	"context"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/actforgood/xcache"
 	"github.com/actforgood/xlog"
@@ -51,6 +53,14 @@ func TestRedis7_integration(t *testing.T) {
 		t.Run("key does not exist", testCacheWithNotExistKey(subject))
 		t.Run("delete key", testCacheDeleteKey(subject))
 		t.Run("ttl for not yet expired key", testCacheTTLWithNotYetExpiredKey(subject))
+		t.Run("delete prefix", testCacheDeletePrefix(subject))
+		t.Run("scan", testCacheScan(subject))
+		t.Run("touch", testCacheTouch(subject))
+		t.Run("save keep ttl", testCacheSaveKeepTTL(subject))
+		t.Run("has", testCacheHas(subject))
+		t.Run("save many & load many", testCacheSaveManyLoadMany(subject))
+		t.Run("delete many", testCacheDeleteMany(subject))
+		t.Run("done context", testCacheWithDoneContext(subject))
 		t.Run("stats", testCacheStats(subject, 256, 1024*1024, ">=", !redis7ConfigIntegration.IsCluster()))
 	})
 
@@ -59,6 +69,138 @@ func TestRedis7_integration(t *testing.T) {
 	assertNil(t, err)
 }
 
+func TestRedis7_withDisabledUnlink_integration(t *testing.T) {
+	// Note: test is not parallel as it uses the same keys as TestRedis7_integration.
+
+	// setup
+	config := redis7ConfigIntegration
+	config.DisableUnlink = true
+	subject := xcache.NewRedis7(config)
+
+	t.Run("wait", func(t *testing.T) { // wait for parallel tests to complete
+		t.Run("delete key", testCacheDeleteKey(subject))
+		t.Run("delete prefix", testCacheDeletePrefix(subject))
+		t.Run("scan", testCacheScan(subject))
+		t.Run("done context", testCacheWithDoneContext(subject))
+	})
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
+
+func TestRedis7_withCountClusterKeys_integration(t *testing.T) {
+	// Note: test is not parallel as it uses the same keys as TestRedis7_integration.
+
+	// setup
+	config := redis7ConfigIntegration
+	config.CountClusterKeys = true
+	subject := xcache.NewRedis7(config)
+
+	t.Run("stats", testCacheStats(subject, 256, 1024*1024, ">=", true))
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
+
+func TestRedis7_withStatsCache_integration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := redis7ConfigIntegration
+	config.StatsCacheTTL = time.Minute
+	subject := xcache.NewRedis7(config)
+	ctx := context.Background()
+
+	// act
+	firstStats, firstErr := subject.Stats(ctx)
+	for i := 0; i < 5; i++ { // 5 x miss
+		_, _ = subject.Load(ctx, "test-stats-cache-miss-key")
+	}
+	secondStats, secondErr := subject.Stats(ctx)
+
+	// assert
+	requireNil(t, firstErr)
+	requireNil(t, secondErr)
+	assertEqual(t, firstStats, secondStats)
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
+
+func TestRedis7_KeyspaceStats_integration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewRedis7(redis7ConfigIntegration)
+	ctx := context.Background()
+	key := "test-keyspace-stats-redis7-key"
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), time.Minute))
+
+	// act
+	dbsStats, err := subject.KeyspaceStats(ctx)
+
+	// assert
+	requireNil(t, err)
+	var configuredDBStats *xcache.RedisDBStats
+	for i := range dbsStats {
+		if dbsStats[i].DB == redis7ConfigIntegration.DB {
+			configuredDBStats = &dbsStats[i]
+		}
+	}
+	if assertNotNil(t, configuredDBStats) {
+		assertTrue(t, configuredDBStats.Keys >= 1)
+		assertTrue(t, configuredDBStats.Expires >= 1)
+	}
+
+	// tear down
+	_ = subject.Save(ctx, key, nil, -1)
+	err = subject.Close()
+	assertNil(t, err)
+}
+
+func TestRedis7_MemoryUsageSample_integration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewRedis7(redis7ConfigIntegration)
+	ctx := context.Background()
+	prefix := "test-memory-usage-sample-redis7-key-"
+	for i := 0; i < 10; i++ {
+		requireNil(t, subject.Save(ctx, prefix+strconv.Itoa(i), []byte("test value"), time.Minute))
+	}
+
+	t.Run("complete sample", func(t *testing.T) {
+		// act
+		usage, err := subject.MemoryUsageSample(ctx, prefix+"*", 100)
+
+		// assert
+		requireNil(t, err)
+		assertEqual(t, int64(10), usage.SampledKeys)
+		assertTrue(t, usage.SampledBytes > 0)
+		assertTrue(t, usage.Complete)
+		assertEqual(t, usage.SampledBytes/10, usage.AvgKeyBytes())
+	})
+
+	t.Run("partial sample", func(t *testing.T) {
+		// act
+		usage, err := subject.MemoryUsageSample(ctx, prefix+"*", 4)
+
+		// assert
+		requireNil(t, err)
+		assertEqual(t, int64(4), usage.SampledKeys)
+		assertTrue(t, usage.SampledBytes > 0)
+		assertTrue(t, !usage.Complete)
+	})
+
+	// tear down
+	_, _ = subject.DeletePrefix(ctx, prefix)
+	err := subject.Close()
+	assertNil(t, err)
+}
+
 func BenchmarkRedis7_Save_integration(b *testing.B) {
 	cache := xcache.NewRedis7(redis7ConfigIntegration)
 	benchSaveSequential(cache)(b)
@@ -11,7 +11,13 @@ import (
 )
 
 func init() {
-	var _ xcache.Cache = (*xcache.Redis7)(nil) // test Redis7 is a Cache
+	var _ xcache.Cache = (*xcache.Redis7)(nil)            // test Redis7 is a Cache
+	var _ xcache.Deleter = (*xcache.Redis7)(nil)          // test Redis7 is a Deleter
+	var _ xcache.ExistenceChecker = (*xcache.Redis7)(nil) // test Redis7 is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Redis7)(nil)          // test Redis7 is a Batcher
+	var _ xcache.BulkDeleter = (*xcache.Redis7)(nil)      // test Redis7 is a BulkDeleter
+	var _ xcache.Toucher = (*xcache.Redis7)(nil)          // test Redis7 is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Redis7)(nil)        // test Redis7 is a TTLKeeper
 }
 
 func ExampleRedis7() {
@@ -0,0 +1,365 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	redis7 "github.com/redis/go-redis/v9"
+)
+
+// ErrTrackingOnCluster is returned by NewRedis7Tracked for a Redis7 on a Cluster setup,
+// as invalidations would have to be received from each master.
+var ErrTrackingOnCluster = errors.New("client side caching is not supported on a cluster setup")
+
+// redis7TrackingChannel is the channel invalidation messages are published on, for a RESP2 redirect client.
+const redis7TrackingChannel = "__redis__:invalidate"
+
+// redis7TrackingPingInterval is the idle period after which the tracking connection's health is checked.
+const redis7TrackingPingInterval = 15 * time.Second
+
+// Redis7TrackedConfig holds the settings of a Redis7Tracked cache.
+type Redis7TrackedConfig struct {
+	// MemSize is the size of the local cache, in bytes (see NewMemory). By default (0), it's 32 MB.
+	MemSize int
+	// Prefixes restricts tracking to the keys with these prefixes. By default (empty), all keys are tracked.
+	// Redis sends an invalidation message for each write of a tracked key (by any client), so narrow
+	// them to the (read heavy) keys worth caching locally.
+	// Prefixes are relative to RedisConfig.KeyPrefix, if set (tracking being restricted to it, by default).
+	Prefixes []string
+	// MaxLocalTTL caps the period a value is kept locally. By default (0), a value is kept
+	// for its remaining time to live in Redis (or, until it is invalidated / evicted).
+	MaxLocalTTL time.Duration
+}
+
+// Redis7Tracked is a Redis7 decorator which keeps a local (Memory) copy of the loaded values,
+// invalidated by Redis server itself, through client side caching (CLIENT TRACKING, broadcasting mode),
+// giving Multi like read latency, with no manual invalidation plumbing, nor stale reads after a write
+// (other than the delivery delay of an invalidation message).
+//
+// Invalidation messages are received on a dedicated connection, subscribed to "__redis__:invalidate"
+// (tracking redirect mode, as go-redis does not surface RESP3 push messages; the invalidations are the same).
+// While that connection is down (or the server does not support tracking), local copies are neither used
+// nor stored, and they are flushed when it's re-established.
+// On a Redis7 reconfiguration (see NewRedis7WithConfig), the tracking connection follows the new configuration.
+//
+// It implements io.Closer, and it should be closed at your application shutdown (the Redis7 is not closed).
+type Redis7Tracked struct {
+	cache         *Redis7
+	local         *Memory
+	config        Redis7TrackedConfig
+	epoch         uint64       // incremented on each invalidation.
+	active        int32        // 1 while invalidation messages are received.
+	fill          sync.RWMutex // serializes local fills with invalidations.
+	localHits     int64
+	invalidations int64
+	mu            sync.Mutex // guards tracker and closed.
+	tracker       *redis7Tracker
+	closed        bool
+}
+
+// redis7Tracker holds the connection invalidation messages are received on.
+type redis7Tracker struct {
+	client    *redis7.Client
+	pubsub    *redis7.PubSub
+	keyPrefix string // RedisConfig.KeyPrefix, stripped from invalidated keys.
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewRedis7Tracked instantiates a new Redis7Tracked cache, upon given Redis7, according to given settings.
+// It returns ErrTrackingOnCluster if the Redis7 is on a Cluster setup.
+func NewRedis7Tracked(cache *Redis7, config Redis7TrackedConfig) (*Redis7Tracked, error) {
+	cache.rLock()
+	redisConfig := cache.config
+	cache.rUnlock()
+	if redisConfig.IsCluster() {
+		return nil, ErrTrackingOnCluster
+	}
+	if config.MemSize <= 0 {
+		config.MemSize = 32 * 1024 * 1024
+	}
+
+	tracked := &Redis7Tracked{
+		cache:  cache,
+		local:  NewMemory(config.MemSize),
+		config: config,
+	}
+	tracked.tracker = tracked.startTracker(redisConfig)
+	cache.OnReconfigure(tracked.onReconfigure)
+
+	return tracked, nil
+}
+
+// Save stores the given key-value with expiration period into Redis, and invalidates the local copy.
+// It returns an error if the key could not be saved.
+func (cache *Redis7Tracked) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	err := cache.cache.Save(ctx, key, value, expire)
+	cache.invalidate(key)
+
+	return err
+}
+
+// Load returns a key's value from the local copy, if any, or from Redis (storing it locally).
+// If the key is not found, ErrNotFound is returned.
+func (cache *Redis7Tracked) Load(ctx context.Context, key string) ([]byte, error) {
+	if atomic.LoadInt32(&cache.active) == 1 {
+		if value, err := cache.local.Load(ctx, key); err == nil {
+			atomic.AddInt64(&cache.localHits, 1)
+
+			return value, nil
+		}
+	}
+
+	epoch := atomic.LoadUint64(&cache.epoch)
+	value, ttl, err := cache.loadWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if ttl < 0 { // expired meanwhile, or unknown.
+		return value, nil
+	}
+	if cache.config.MaxLocalTTL > 0 && (ttl == NoExpire || ttl > cache.config.MaxLocalTTL) {
+		ttl = cache.config.MaxLocalTTL
+	}
+
+	cache.fill.RLock()
+	// store locally only if no invalidation was received meanwhile (the value may be stale already).
+	if atomic.LoadInt32(&cache.active) == 1 && atomic.LoadUint64(&cache.epoch) == epoch {
+		_ = cache.local.Save(ctx, key, value, ttl)
+	}
+	cache.fill.RUnlock()
+
+	return value, nil
+}
+
+// TTL returns a key's remaining time to live, from Redis.
+func (cache *Redis7Tracked) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns Redis's statistics.
+func (cache *Redis7Tracked) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Delete deletes the given key from Redis, and invalidates the local copy.
+// It returns an error if the key could not be deleted.
+func (cache *Redis7Tracked) Delete(ctx context.Context, key string) error {
+	err := cache.cache.Delete(ctx, key)
+	cache.invalidate(key)
+
+	return err
+}
+
+// Unwrap returns the decorated Redis7.
+func (cache *Redis7Tracked) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the loads served locally, the invalidations received, and whether tracking
+// is active (1) or not (0), as "tracking.local_hits", "tracking.invalidations" and "tracking.active" metrics.
+func (cache *Redis7Tracked) ContributeStats(add func(name string, value float64)) {
+	add("tracking.local_hits", float64(atomic.LoadInt64(&cache.localHits)))
+	add("tracking.invalidations", float64(atomic.LoadInt64(&cache.invalidations)))
+	add("tracking.active", float64(atomic.LoadInt32(&cache.active)))
+}
+
+// Close closes the tracking connection.
+func (cache *Redis7Tracked) Close() error {
+	cache.mu.Lock()
+	tracker := cache.tracker
+	cache.tracker = nil
+	cache.closed = true
+	cache.mu.Unlock()
+
+	cache.deactivate()
+	if tracker == nil {
+		return nil
+	}
+
+	return tracker.stop()
+}
+
+// loadWithTTL returns a key's value and remaining time to live from Redis, in a single round trip.
+// The TTL is 0 (NoExpire) for a key with no expiration, and negative if it could not be retrieved.
+func (cache *Redis7Tracked) loadWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	cache.cache.rLock()
+	key = cache.cache.config.prefixKey(key)
+	pipe := cache.cache.client.Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.PTTL(ctx, key)
+	_, _ = pipe.Exec(ctx)
+	cache.cache.rUnlock()
+
+	value, err := getCmd.Bytes()
+	if errors.Is(err, redis7.Nil) {
+		return nil, 0, ErrNotFound
+	} else if err != nil {
+		return nil, 0, err
+	}
+	ttl, err := ttlCmd.Result()
+	if err != nil {
+		return value, -1, nil
+	}
+	if ttl == redisTTLNoExpire {
+		return value, NoExpire, nil
+	}
+
+	return value, ttl, nil
+}
+
+// invalidate deletes the local copies of given keys.
+func (cache *Redis7Tracked) invalidate(keys ...string) {
+	cache.fill.Lock()
+	atomic.AddUint64(&cache.epoch, 1)
+	for _, key := range keys {
+		_ = cache.local.Delete(context.Background(), key)
+	}
+	cache.fill.Unlock()
+}
+
+// flush deletes all local copies.
+func (cache *Redis7Tracked) flush() {
+	cache.fill.Lock()
+	atomic.AddUint64(&cache.epoch, 1)
+	cache.local.client.Clear()
+	cache.fill.Unlock()
+}
+
+// deactivate stops using local copies, and flushes them, as invalidations might be missed.
+func (cache *Redis7Tracked) deactivate() {
+	if atomic.SwapInt32(&cache.active, 0) == 1 {
+		cache.flush()
+	}
+}
+
+// onReconfigure restarts the tracking connection, with the new Redis configuration.
+func (cache *Redis7Tracked) onReconfigure(_, newConfig RedisConfig) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.closed {
+		return
+	}
+	cache.deactivate()
+	_ = cache.tracker.stop()
+	cache.tracker = cache.startTracker(newConfig)
+}
+
+// startTracker connects (a dedicated client) to Redis, enabling tracking in broadcasting mode on the connection,
+// with redirection to itself, subscribes it to the invalidation channel, and starts receiving invalidations.
+func (cache *Redis7Tracked) startTracker(config RedisConfig) *redis7Tracker {
+	opts := getRedis7UniversalOptions(config)
+	opts.Protocol = 2 // invalidations are received as Pub/Sub messages only in RESP2.
+	opts.OnConnect = func(ctx context.Context, cn *redis7.Conn) error {
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return err
+		}
+		args := []any{"CLIENT", "TRACKING", "ON", "REDIRECT", id, "BCAST"}
+		for _, prefix := range cache.config.Prefixes {
+			args = append(args, "PREFIX", config.KeyPrefix+prefix)
+		}
+		if len(cache.config.Prefixes) == 0 && config.KeyPrefix != "" {
+			args = append(args, "PREFIX", config.KeyPrefix)
+		}
+
+		cmd := redis7.NewStatusCmd(ctx, args...)
+		_ = cn.Process(ctx, cmd)
+
+		return cmd.Err()
+	}
+
+	var client *redis7.Client
+	if opts.MasterName != "" {
+		client = redis7.NewFailoverClient(opts.Failover())
+	} else {
+		client = redis7.NewClient(opts.Simple())
+	}
+	tracker := &redis7Tracker{
+		client:    client,
+		pubsub:    client.Subscribe(context.Background(), redis7TrackingChannel),
+		keyPrefix: config.KeyPrefix,
+		done:      make(chan struct{}),
+	}
+	tracker.wg.Add(1)
+	go cache.track(tracker)
+
+	return tracker
+}
+
+// track receives invalidation messages until the tracker is stopped.
+func (cache *Redis7Tracked) track(tracker *redis7Tracker) {
+	defer tracker.wg.Done()
+
+	ctx := context.Background()
+	for {
+		msg, err := tracker.pubsub.ReceiveTimeout(ctx, redis7TrackingPingInterval)
+		select {
+		case <-tracker.done:
+			return
+		default:
+		}
+
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && tracker.pubsub.Ping(ctx) == nil {
+				continue // idle, but healthy.
+			}
+			// connection is broken (it is re-established on next receive), or a flush (nil payload) was received.
+			cache.deactivate()
+			select {
+			case <-tracker.done:
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis7.Subscription:
+			if msg.Kind == "subscribe" && msg.Channel == redis7TrackingChannel {
+				cache.flush()
+				atomic.StoreInt32(&cache.active, 1)
+			}
+		case *redis7.Message:
+			keys := msg.PayloadSlice
+			if msg.Payload != "" {
+				keys = append(keys, msg.Payload)
+			}
+			for i, key := range keys {
+				keys[i] = strings.TrimPrefix(key, tracker.keyPrefix)
+			}
+			atomic.AddInt64(&cache.invalidations, int64(len(keys)))
+			cache.invalidate(keys...)
+		}
+	}
+}
+
+// stop closes the tracker's connection, and waits for the receiving goroutine to return.
+func (tracker *redis7Tracker) stop() error {
+	close(tracker.done)
+	err := tracker.pubsub.Close()
+	tracker.wg.Wait()
+	if errClient := tracker.client.Close(); err == nil {
+		err = errClient
+	}
+
+	return err
+}
@@ -0,0 +1,235 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Redis7Tracked)(nil)            // test Redis7Tracked is a Cache
+	var _ xcache.Deleter = (*xcache.Redis7Tracked)(nil)          // test Redis7Tracked is a Deleter
+	var _ xcache.StatsContributor = (*xcache.Redis7Tracked)(nil) // test Redis7Tracked is a StatsContributor
+	var _ io.Closer = (*xcache.Redis7Tracked)(nil)               // test Redis7Tracked is a Closer
+}
+
+func TestRedis7Tracked(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads are served locally until invalidated", testRedis7TrackedLoadsAreServedLocallyUntilInvalidated)
+	t.Run("writes invalidate local copy", testRedis7TrackedWritesInvalidateLocalCopy)
+	t.Run("key prefix is stripped from invalidated keys", testRedis7TrackedKeyPrefixIsStrippedFromInvalidatedKeys)
+	t.Run("tracking not supported", testRedis7TrackedTrackingNotSupported)
+	t.Run("cluster is not supported", testRedis7TrackedClusterIsNotSupported)
+}
+
+func testRedis7TrackedLoadsAreServedLocallyUntilInvalidated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr    = newTrackingMiniRedis(t)
+		redis = xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{mr.Addr()}})
+		ctx   = context.Background()
+		key   = "test-tracked-key"
+	)
+	defer redis.Close()
+	subject, err := xcache.NewRedis7Tracked(redis, xcache.Redis7TrackedConfig{Prefixes: []string{"test-"}})
+	requireNil(t, err)
+	defer subject.Close()
+	requireTrackedMetric(t, subject, "tracking.active", 1)
+	requireNil(t, mr.Set(key, "value 1"))
+
+	// act & assert
+	value, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 1"), value)
+
+	requireNil(t, mr.Set(key, "value 2")) // modified behind, without invalidation.
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 1"), value)
+	requireTrackedMetric(t, subject, "tracking.local_hits", 1)
+
+	mr.Publish("__redis__:invalidate", key)
+	requireTrackedMetric(t, subject, "tracking.invalidations", 1)
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 2"), value)
+
+	assertNil(t, subject.Close())
+	requireTrackedMetric(t, subject, "tracking.active", 0)
+	requireNil(t, mr.Set(key, "value 3"))
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 3"), value)
+}
+
+func testRedis7TrackedKeyPrefixIsStrippedFromInvalidatedKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr    = newTrackingMiniRedis(t)
+		redis = xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{mr.Addr()}, KeyPrefix: "app:"})
+		ctx   = context.Background()
+		key   = "test-tracked-prefixed-key"
+	)
+	defer redis.Close()
+	subject, err := xcache.NewRedis7Tracked(redis, xcache.Redis7TrackedConfig{})
+	requireNil(t, err)
+	defer subject.Close()
+	requireTrackedMetric(t, subject, "tracking.active", 1)
+	requireNil(t, mr.Set("app:"+key, "value 1"))
+	value, err := subject.Load(ctx, key)
+	requireNil(t, err)
+	requireNil(t, mr.Set("app:"+key, "value 2"))
+
+	// act
+	mr.Publish("__redis__:invalidate", "app:"+key)
+
+	// assert
+	assertEqual(t, []byte("value 1"), value)
+	requireTrackedMetric(t, subject, "tracking.invalidations", 1)
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 2"), value)
+}
+
+func testRedis7TrackedWritesInvalidateLocalCopy(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr    = newTrackingMiniRedis(t)
+		redis = xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{mr.Addr()}})
+		ctx   = context.Background()
+		key   = "test-tracked-write-key"
+	)
+	defer redis.Close()
+	subject, err := xcache.NewRedis7Tracked(redis, xcache.Redis7TrackedConfig{})
+	requireNil(t, err)
+	defer subject.Close()
+	requireTrackedMetric(t, subject, "tracking.active", 1)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, []byte("value 1"), time.Minute))
+	value, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 1"), value)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, time.Minute, ttl)
+
+	requireNil(t, subject.Save(ctx, key, []byte("value 2"), time.Minute))
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 2"), value)
+
+	requireNil(t, subject.Delete(ctx, key))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testRedis7TrackedTrackingNotSupported(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr    = miniredis.RunT(t) // CLIENT TRACKING is not supported.
+		redis = xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{mr.Addr()}})
+		ctx   = context.Background()
+		key   = "test-tracked-not-supported-key"
+	)
+	defer redis.Close()
+	subject, err := xcache.NewRedis7Tracked(redis, xcache.Redis7TrackedConfig{})
+	requireNil(t, err)
+	defer subject.Close()
+	requireNil(t, mr.Set(key, "value 1"))
+
+	// act & assert
+	value, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 1"), value)
+
+	requireNil(t, mr.Set(key, "value 2"))
+	value, err = subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 2"), value)
+	requireTrackedMetric(t, subject, "tracking.local_hits", 0)
+	requireTrackedMetric(t, subject, "tracking.active", 0)
+}
+
+func testRedis7TrackedClusterIsNotSupported(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	redis := xcache.NewRedis7(xcache.RedisConfig{Addrs: []string{"127.0.0.1:7000", "127.0.0.1:7001"}})
+	defer redis.Close()
+
+	// act
+	subject, err := xcache.NewRedis7Tracked(redis, xcache.Redis7TrackedConfig{})
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrTrackingOnCluster))
+	assertTrue(t, subject == nil)
+}
+
+// newTrackingMiniRedis starts a miniredis which accepts CLIENT ID / CLIENT TRACKING commands.
+// Invalidation messages are to be published manually.
+func newTrackingMiniRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	mr.Server().SetPreHook(func(peer *server.Peer, cmd string, args ...string) bool {
+		if cmd != "CLIENT" || len(args) == 0 {
+			return false
+		}
+		switch strings.ToUpper(args[0]) {
+		case "ID":
+			peer.WriteInt(1)
+
+			return true
+		case "TRACKING":
+			peer.WriteOK()
+
+			return true
+		}
+
+		return false
+	})
+
+	return mr
+}
+
+// requireTrackedMetric waits (up to 1 second) for a Redis7Tracked's metric to get the expected value.
+func requireTrackedMetric(t *testing.T, subject *xcache.Redis7Tracked, name string, expected float64) {
+	t.Helper()
+
+	var actual float64
+	for i := 0; i < 100; i++ {
+		subject.ContributeStats(func(metric string, value float64) {
+			if metric == name {
+				actual = value
+			}
+		})
+		if actual == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %q metric to be %v, but got %v", name, expected, actual)
+}
@@ -20,8 +20,16 @@ import (
 //
 // An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
 // In case any config value requested by Redis7 is changed, the Redis7 is reinitialized with the new config.
+// See OnReconfigure for being notified about it, and AddHook for installing client hooks which survive it.
+//
+// Values with common representations are coerced to expected types (for example, Redis server(s)
+// can be given as a comma separated string, timeouts as duration strings like "5s", or as numbers
+// expressed in milliseconds, unless another unit is configured under CfgKeyDurationUnit key).
+// Invalid values are replaced with defaults, use NewRedis7WithValidConfig to fail on them instead.
+// An invalid configuration reload is disregarded, the current configuration is kept.
 func NewRedis7WithConfig(config xconf.Config) *Redis7 {
-	cache := NewRedis7(getRedisConfig(config))
+	redisConfig, _ := getRedisConfig(config)
+	cache := NewRedis7(redisConfig)
 	cache.mu = new(sync.RWMutex)
 
 	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
@@ -31,8 +39,19 @@ func NewRedis7WithConfig(config xconf.Config) *Redis7 {
 	return cache
 }
 
+// NewRedis7WithValidConfig is like NewRedis7WithConfig, but it fails on invalid values:
+// an error aggregating a ConfigError for each of them is returned, and cache is nil.
+func NewRedis7WithValidConfig(config xconf.Config) (*Redis7, error) {
+	if err := ValidateRedisXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewRedis7WithConfig(config), nil
+}
+
 // onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
-// In case one of RedisCfgKey* configs is changed, the Redis7 is reinitialized with the new config.
+// In case one of RedisCfgKey* configs is changed, the Redis7 is reinitialized with the new config,
+// if it's valid.
 // This callback is automatically registered on instantiation of a Redis7 object with NewRedis7WithConfig.
 func (cache *Redis7) onConfigChange(config xconf.Config, changedKeys ...string) {
 	configHasChanged := false
@@ -48,15 +67,40 @@ func (cache *Redis7) onConfigChange(config xconf.Config, changedKeys ...string)
 		return
 	}
 
-	redisConfig := getRedisConfig(config)
+	redisConfig, err := getRedisConfig(config)
+	if err != nil { // keep current configuration.
+		return
+	}
 	newClient := redis7.NewUniversalClient(getRedis7UniversalOptions(redisConfig))
 
 	cache.mu.Lock()
+	for _, hook := range cache.hooks {
+		newClient.AddHook(hook)
+	}
 	oldClient := cache.client
+	oldConfig := cache.config
 	cache.client = newClient
+	cache.config = redisConfig
 	cache.isCluster = redisConfig.IsCluster()
+	cache.disableUnlink = redisConfig.DisableUnlink
 	cache.setStatsKeyPrefixes(redisConfig.DB)
+	cache.statsCache = newStatsCache(redisConfig.StatsCacheTTL)
+	onReconfigure := cache.onReconfigure
 	cache.mu.Unlock()
 
 	_ = oldClient.Close()
+
+	for _, callback := range onReconfigure {
+		callback(oldConfig, redisConfig)
+	}
+}
+
+// OnReconfigure registers a callback to be executed after the Redis7 was reinitialized
+// with a new configuration, as a result of a config change (see NewRedis7WithConfig).
+// It can be used to log, or emit metrics about reconfiguration.
+// Note: hooks added with AddHook are automatically re-applied on the new client.
+func (cache *Redis7) OnReconfigure(callback func(oldConfig, newConfig RedisConfig)) {
+	cache.lock()
+	cache.onReconfigure = append(cache.onReconfigure, callback)
+	cache.unlock()
 }
@@ -17,6 +17,7 @@ import (
 
 	"github.com/actforgood/xcache"
 	"github.com/actforgood/xconf"
+	redis7 "github.com/redis/go-redis/v9"
 )
 
 func TestRedis7_withXConf_integration(t *testing.T) {
@@ -63,13 +64,21 @@ func testRedis7WithXConfConfigIsChanged(t *testing.T) {
 			configLoader,
 			xconf.DefaultConfigWithReloadInterval(time.Second),
 		)
-		subject   = xcache.NewRedis7WithConfig(config)
-		keyPrefix = "test-xconf-withconfigchange-key-"
-		value     = []byte("test value")
-		ctx       = context.Background()
+		subject         = xcache.NewRedis7WithConfig(config)
+		keyPrefix       = "test-xconf-withconfigchange-key-"
+		value           = []byte("test value")
+		ctx             = context.Background()
+		hook            = new(redis7CountingHook)
+		reconfiguredCnt uint32
 	)
 	defer config.Close()
 	defer subject.Close()
+	subject.AddHook(hook)
+	subject.OnReconfigure(func(oldConfig, newConfig xcache.RedisConfig) {
+		atomic.AddUint32(&reconfiguredCnt, 1)
+		assertEqual(t, 0, oldConfig.DB)
+		assertEqual(t, 1, newConfig.DB)
+	})
 	// save some keys
 	for i := 0; i < 10; i++ {
 		key := keyPrefix + strconv.FormatInt(int64(i), 10)
@@ -88,6 +97,8 @@ func testRedis7WithXConfConfigIsChanged(t *testing.T) {
 		_, err := subject.Load(ctx, key)
 		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
 	}
+	assertEqual(t, uint32(1), atomic.LoadUint32(&reconfiguredCnt))
+	assertEqual(t, uint32(20), atomic.LoadUint32(&hook.calls)) // hook survived reconfiguration.
 }
 
 func testRedis7WithXConfConfigIsNotChanged(t *testing.T) {
@@ -183,3 +194,24 @@ func TestRedis7_withXConf_concurrency(t *testing.T) {
 
 	t.Logf("config changed %d times during test", (readTimeout-3*time.Second)/time.Second)
 }
+
+// redis7CountingHook is a hook which counts processed commands.
+type redis7CountingHook struct {
+	calls uint32
+}
+
+func (*redis7CountingHook) DialHook(next redis7.DialHook) redis7.DialHook {
+	return next
+}
+
+func (hook *redis7CountingHook) ProcessHook(next redis7.ProcessHook) redis7.ProcessHook {
+	return func(ctx context.Context, cmd redis7.Cmder) error {
+		atomic.AddUint32(&hook.calls, 1)
+
+		return next(ctx, cmd)
+	}
+}
+
+func (*redis7CountingHook) ProcessPipelineHook(next redis7.ProcessPipelineHook) redis7.ProcessPipelineHook {
+	return next
+}
@@ -0,0 +1,71 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+// redisBatchCache is the subset of Redis6 / Redis7 api batch operations are part of.
+type redisBatchCache interface {
+	xcache.Cache
+	xcache.Batcher
+	xcache.BulkDeleter
+	io.Closer
+}
+
+func TestRedis_batch(t *testing.T) {
+	t.Parallel()
+
+	newCaches := map[string]func(config xcache.RedisConfig) redisBatchCache{
+		"redis6": func(config xcache.RedisConfig) redisBatchCache { return xcache.NewRedis6(config) },
+		"redis7": func(config xcache.RedisConfig) redisBatchCache { return xcache.NewRedis7(config) },
+	}
+	for name, newCache := range newCaches {
+		newCache := newCache // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testRedisBatchCostsOneCommand(t, newCache)
+		})
+	}
+}
+
+func testRedisBatchCostsOneCommand(t *testing.T, newCache func(config xcache.RedisConfig) redisBatchCache) {
+	t.Helper()
+
+	// arrange
+	var (
+		mr      = xcachetest.NewMiniRedis(t)
+		subject = newCache(mr.Config())
+		ctx     = context.Background()
+		keys    = []string{"test-redis-batch-key-1", "{test-redis-batch}-key-2", "test-redis-batch-key-3"}
+	)
+	defer subject.Close()
+	requireNil(t, subject.SaveMany(ctx, map[string]xcache.Item{
+		keys[0]: {Value: []byte("value 1"), Expire: time.Minute},
+		keys[1]: {Value: []byte("value 2"), Expire: time.Minute},
+	}))
+
+	// act & assert load many
+	commandsBefore := mr.CommandCount()
+	values, err := subject.LoadMany(ctx, keys)
+	assertNil(t, err)
+	assertEqual(t, 1, mr.CommandCount()-commandsBefore) // a single MGET.
+	assertEqual(t, map[string][]byte{keys[0]: []byte("value 1"), keys[1]: []byte("value 2")}, values)
+
+	// act & assert delete many
+	commandsBefore = mr.CommandCount()
+	err = subject.DeleteMany(ctx, keys...)
+	assertNil(t, err)
+	assertEqual(t, 1, mr.CommandCount()-commandsBefore) // a single UNLINK.
+	assertEqual(t, 0, len(mr.Keys()))
+}
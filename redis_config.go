@@ -7,7 +7,11 @@ package xcache
 
 import (
 	"bytes"
+	"crypto/tls"
+	"errors"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +21,10 @@ import (
 // redisTTLNoExpire is Redis TTL command reply value for a key with no expiration.
 const redisTTLNoExpire = -1
 
+// ErrNoExpireForbidden is returned by Redis6 / Redis7 when saving a key without expiration (NoExpire),
+// if RedisConfig.ForbidNoExpire is set.
+var ErrNoExpireForbidden = errors.New("saving a key without expiration is forbidden")
+
 // RedisConfig contains commonly used information for Redis connection.
 type RedisConfig struct {
 	// Addrs contains either a single address or a seed list of host:port addresses
@@ -43,8 +51,45 @@ type RedisConfig struct {
 	// WriteTimeout is the timeout for write ops.
 	WriteTimeout time.Duration
 
+	// TLSConfig is the TLS configuration to use. When set, TLS is negotiated.
+	TLSConfig *tls.Config
+
+	// DisableUnlink forces the usage of DEL command instead of UNLINK for deletions.
+	// By default, UNLINK (Redis >= 4) is used, which reclaims the memory in a different thread,
+	// so deleting large values does not block Redis's event loop.
+	DisableUnlink bool
+
+	// StatsCacheTTL is the period for which the result of a Stats call is reused by subsequent calls,
+	// in order to reduce the load generated on server(s) by frequent calls (like a StatsWatcher's ones).
+	// Example: 500 * time.Millisecond. By default (0), stats are retrieved on each call.
+	StatsCacheTTL time.Duration
+
+	// ForbidNoExpire makes saves of keys without expiration (NoExpire) fail with ErrNoExpireForbidden,
+	// as keys without expiration written by mistake may end up filling Redis's memory.
+	// It applies also to Touch with NoExpire, and to saves performed within transactions.
+	ForbidNoExpire bool
+	// NoExpireTTL, if positive, is the expiration period keys saved without expiration (NoExpire)
+	// are stored with instead. It takes precedence over ForbidNoExpire.
+	NoExpireTTL time.Duration
+
+	// KeyPrefix, if set, is prepended to each key, so that applications sharing
+	// a Redis instance (database) do not collide, without wrapping keys manually.
+	// Keys returned by Scan are stripped of it, and patterns / prefixes given to MemoryUsageSample /
+	// DeletePrefix are relative to it.
+	// Stats count all the keys of the database, unless CountPrefixKeys is set.
+	// Example: "myapp:".
+	KeyPrefix string
+	// CountPrefixKeys makes Stats count only the keys having KeyPrefix, with SCAN, instead of all the keys
+	// of the database (on a Cluster, of each master, if CountClusterKeys is set).
+	// Note: the whole keyspace is iterated on each Stats call (O(N), N being the no. of keys of the database),
+	// consider setting StatsCacheTTL, too.
+	CountPrefixKeys bool
+
 	// Enables read-only commands on slave nodes. [cluster only]
 	ReadOnly bool
+	// CountClusterKeys enables retrieving the no. of keys in Stats, with DBSIZE on each master node
+	// (or SCAN, if CountPrefixKeys is set), at the cost of these extra commands. [cluster only]
+	CountClusterKeys bool
 
 	// MasterName represents the sentinel master name. [failover only]
 	MasterName string
@@ -60,6 +105,52 @@ type RedisAuth struct {
 	Password string
 }
 
+// noExpirePolicy returns the expiration period a key should be saved / touched with, considering
+// NoExpireTTL / ForbidNoExpire settings, or ErrNoExpireForbidden.
+func (rc RedisConfig) noExpirePolicy(expire time.Duration) (time.Duration, error) {
+	if expire != NoExpire {
+		return expire, nil
+	}
+	if rc.NoExpireTTL > 0 {
+		return rc.NoExpireTTL, nil
+	}
+	if rc.ForbidNoExpire {
+		return expire, ErrNoExpireForbidden
+	}
+
+	return expire, nil
+}
+
+// prefixKey returns given key, prefixed with KeyPrefix.
+func (rc RedisConfig) prefixKey(key string) string {
+	return rc.KeyPrefix + key
+}
+
+// prefixKeys returns given keys, prefixed with KeyPrefix.
+func (rc RedisConfig) prefixKeys(keys []string) []string {
+	if rc.KeyPrefix == "" {
+		return keys
+	}
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = rc.KeyPrefix + key
+	}
+
+	return prefixedKeys
+}
+
+// unprefixKeys strips KeyPrefix from given keys, in place.
+func (rc RedisConfig) unprefixKeys(keys []string) []string {
+	if rc.KeyPrefix == "" {
+		return keys
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, rc.KeyPrefix)
+	}
+
+	return keys
+}
+
 // IsCluster returns true if config is for a cluster configuration.
 func (rc RedisConfig) IsCluster() bool {
 	return len(rc.Addrs) > 1 && rc.MasterName == ""
@@ -75,6 +166,13 @@ const (
 	redisInfoPrefixExpiredKeys    = "expired_keys:"
 )
 
+// INFO command sections holding needed stats information.
+const (
+	redisInfoSectionMemory   = "memory"
+	redisInfoSectionStats    = "stats"
+	redisInfoSectionKeyspace = "keyspace"
+)
+
 var clusterReplicaKeyPrefixes = []string{
 	redisInfoPrefixHits,
 	redisInfoPrefixMisses,
@@ -92,9 +190,9 @@ var clusterMasterKeyPrefixes = []string{
 
 // parseInfoStats parses INFO command response and extracts needed information.
 //
-// Note: On cluster setup, no. of keys can't be retrieved directly (INFO KEYSPACE / DBSIZE don't work on Redis Cluster).
-// Can be calculated with Cluster Slots and Cluster CountKeysInSlot (each slot), but there are 16384 slots,
-// and due to too much overhead reasons, stats.Keys remains 0.
+// Note: On cluster setup, no. of keys can't be retrieved with a single command (INFO KEYSPACE / DBSIZE
+// report only the node's keys), and stats.Keys remains 0, unless RedisConfig.CountClusterKeys is set,
+// in which case DBSIZE is issued on each master node, and the results are summed.
 func parseInfoStats(info []byte, keyPrefixes []string) Stats {
 	var (
 		extractedDigits = make([]byte, 20)
@@ -143,3 +241,183 @@ func parseInfoStats(info []byte, keyPrefixes []string) Stats {
 
 	return stats
 }
+
+// RedisDBStats holds the keyspace statistics of a Redis logical database.
+type RedisDBStats struct {
+	// DB is the database index.
+	DB int
+	// Keys represents the number of keys in the database.
+	Keys int64
+	// Expires represents the number of keys with an expiration set.
+	Expires int64
+	// AvgTTL represents the (estimated) average time to live of keys with an expiration set.
+	AvgTTL time.Duration
+}
+
+// parseInfoKeyspace parses INFO KEYSPACE command response and extracts all databases' statistics,
+// sorted by database index.
+// Example of a database line: db0:keys=59,expires=1,avg_ttl=98929 .
+func parseInfoKeyspace(info []byte) []RedisDBStats {
+	var dbsStats []RedisDBStats
+	for _, line := range bytes.Split(info, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("db")) {
+			continue
+		}
+		dbIdx, fields, found := bytes.Cut(line[2:], []byte(":"))
+		if !found {
+			continue
+		}
+		db, err := strconv.Atoi(bytesToString(dbIdx))
+		if err != nil {
+			continue
+		}
+		dbStats := RedisDBStats{DB: db}
+		for _, field := range bytes.Split(fields, []byte(",")) {
+			name, value, _ := bytes.Cut(field, []byte("="))
+			intValue, _ := strconv.ParseInt(bytesToString(value), 10, 64)
+			switch bytesToString(name) {
+			case "keys":
+				dbStats.Keys = intValue
+			case "expires":
+				dbStats.Expires = intValue
+			case "avg_ttl":
+				dbStats.AvgTTL = time.Duration(intValue) * time.Millisecond
+			}
+		}
+		dbsStats = append(dbsStats, dbStats)
+	}
+	sort.Slice(dbsStats, func(i, j int) bool {
+		return dbsStats[i].DB < dbsStats[j].DB
+	})
+
+	return dbsStats
+}
+
+// mergeRedisDBStats adds given databases' statistics to the existing ones (on a Cluster setup,
+// each master holds a part of the keyspace), keeping them sorted by database index.
+// Average TTLs are weighted by the no. of keys with an expiration set.
+func mergeRedisDBStats(dbsStats, nodeDBsStats []RedisDBStats) []RedisDBStats {
+	for _, nodeDBStats := range nodeDBsStats {
+		idx := sort.Search(len(dbsStats), func(i int) bool {
+			return dbsStats[i].DB >= nodeDBStats.DB
+		})
+		if idx == len(dbsStats) || dbsStats[idx].DB != nodeDBStats.DB {
+			dbsStats = append(dbsStats, RedisDBStats{})
+			copy(dbsStats[idx+1:], dbsStats[idx:])
+			dbsStats[idx] = nodeDBStats
+
+			continue
+		}
+		dbStats := &dbsStats[idx]
+		if expires := dbStats.Expires + nodeDBStats.Expires; expires > 0 {
+			dbStats.AvgTTL = time.Duration(
+				(int64(dbStats.AvgTTL)*dbStats.Expires + int64(nodeDBStats.AvgTTL)*nodeDBStats.Expires) / expires,
+			)
+		}
+		dbStats.Keys += nodeDBStats.Keys
+		dbStats.Expires += nodeDBStats.Expires
+	}
+
+	return dbsStats
+}
+
+// redisMemoryUsageDefaultSampleSize is the no. of keys sampled by MemoryUsageSample
+// if a non-positive sample size is provided.
+const redisMemoryUsageDefaultSampleSize = 1000
+
+// RedisMemoryUsage holds the memory consumed by a sample of keys matching a pattern.
+type RedisMemoryUsage struct {
+	// SampledKeys represents the number of sampled keys.
+	SampledKeys int64
+	// SampledBytes represents the number of bytes consumed by sampled keys, as reported by MEMORY USAGE.
+	SampledBytes int64
+	// Complete is a flag indicating that all the keys matching the pattern were sampled,
+	// and thus, SampledBytes is the memory consumed by the whole pattern.
+	Complete bool
+}
+
+// AvgKeyBytes returns the average no. of bytes consumed by a sampled key.
+// It can be multiplied with the (estimated) no. of keys matching the pattern,
+// in order to estimate the memory consumed by the whole pattern.
+func (mu RedisMemoryUsage) AvgKeyBytes() int64 {
+	if mu.SampledKeys == 0 {
+		return 0
+	}
+
+	return mu.SampledBytes / mu.SampledKeys
+}
+
+// redisScanCount is the COUNT hint used for SCAN commands.
+const redisScanCount = 1000
+
+// redisClusterSlots is the number of hash slots of a Redis Cluster.
+const redisClusterSlots = 16384
+
+// redisKeySlot returns the Cluster hash slot of given key: the CRC16 (XMODEM) of the key,
+// or of its hash tag (the substring between the first "{" and the next "}", if not empty), modulo 16384.
+func redisKeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return int(crc) % redisClusterSlots
+}
+
+// redisGroupKeys groups given keys by their Cluster hash slot, if perSlot flag is set,
+// so that each group can be the subject of a multi-key command; otherwise, a single group is returned.
+// Groups are in the order of their first key.
+func redisGroupKeys(keys []string, perSlot bool) [][]string {
+	if !perSlot {
+		return [][]string{keys}
+	}
+
+	var (
+		groups    [][]string
+		slotGroup = make(map[int]int, len(keys))
+	)
+	for _, key := range keys {
+		slot := redisKeySlot(key)
+		idx, found := slotGroup[slot]
+		if !found {
+			idx = len(groups)
+			slotGroup[slot] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], key)
+	}
+
+	return groups
+}
+
+// redisEscapeGlob escapes the glob-style special characters of given string,
+// so it can be used literally in a Redis pattern (like SCAN's MATCH option).
+func redisEscapeGlob(str string) string {
+	buf := make([]byte, 0, len(str)+4)
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '*', '?', '[', ']', '^', '-', '\\':
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, str[i])
+	}
+
+	return bytesToString(buf)
+}
+
+// redisReconfigureFunc is a callback executed after a Redis cache was reinitialized with a new configuration.
+type redisReconfigureFunc func(oldConfig, newConfig RedisConfig)
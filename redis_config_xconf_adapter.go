@@ -6,6 +6,7 @@
 package xcache
 
 import (
+	"crypto/tls"
 	"time"
 
 	"github.com/actforgood/xconf"
@@ -27,8 +28,28 @@ const (
 	RedisCfgKeyReadTimeout = "xcache.redis.timeout.read"
 	// RedisCfgKeyWriteTimeout is the key under which xconf.Config expects write timeout.
 	RedisCfgKeyWriteTimeout = "xcache.redis.timeout.write"
+	// RedisCfgKeyTLS is the key under which xconf.Config expects the flag to connect over TLS.
+	RedisCfgKeyTLS = "xcache.redis.tls"
+	// RedisCfgKeyDisableUnlink is the key under which xconf.Config expects the flag to use DEL instead of UNLINK.
+	RedisCfgKeyDisableUnlink = "xcache.redis.disableunlink"
+	// RedisCfgKeyStatsCacheTTL is the key under which xconf.Config expects the period for which stats are cached.
+	RedisCfgKeyStatsCacheTTL = "xcache.redis.stats.cachettl"
+	// RedisCfgKeyForbidNoExpire is the key under which xconf.Config expects the flag to forbid
+	// saving keys without expiration.
+	RedisCfgKeyForbidNoExpire = "xcache.redis.noexpire.forbid"
+	// RedisCfgKeyNoExpireTTL is the key under which xconf.Config expects the expiration period
+	// keys saved without expiration are stored with instead.
+	RedisCfgKeyNoExpireTTL = "xcache.redis.noexpire.ttl"
+	// RedisCfgKeyKeyPrefix is the key under which xconf.Config expects the prefix prepended to each key.
+	RedisCfgKeyKeyPrefix = "xcache.redis.keyprefix"
+	// RedisCfgKeyCountPrefixKeys is the key under which xconf.Config expects the flag to count
+	// only the keys having the key prefix, in stats.
+	RedisCfgKeyCountPrefixKeys = "xcache.redis.keyprefix.count"
 	// RedisCfgKeyClusterReadonly is the key under which xconf.Config expects readonly flag.
 	RedisCfgKeyClusterReadonly = "xcache.redis.cluster.readonly"
+	// RedisCfgKeyClusterCountKeys is the key under which xconf.Config expects the flag to count
+	// the keys of a cluster, in stats.
+	RedisCfgKeyClusterCountKeys = "xcache.redis.cluster.countkeys"
 	// RedisCfgKeyFailoverMasterName is the key under which xconf.Config expects master name.
 	RedisCfgKeyFailoverMasterName = "xcache.redis.failover.mastername"
 	// RedisCfgKeyFailoverAuthUsername is the key under which xconf.Config expects sentinel auth username.
@@ -38,24 +59,78 @@ const (
 )
 
 // getRedisConfig returns a RedisConfig object populated with values taken from a xconf.Config.
-func getRedisConfig(config xconf.Config) RedisConfig {
-	return RedisConfig{
-		Addrs: config.Get(RedisCfgKeyAddrs, []string{"127.0.0.1:6379"}).([]string),
-		DB:    config.Get(RedisCfgKeyDB, 0).(int),
+// Values with common representations (comma separated strings for slices, numeric strings,
+// duration strings) are coerced to expected types.
+// Invalid values are replaced with defaults, and an error aggregating ConfigError(s) is returned.
+func getRedisConfig(config xconf.Config) (RedisConfig, error) {
+	r := newXConfReader(config)
+	redisConfig := readRedisConfig(r)
+
+	return redisConfig, r.Err()
+}
+
+// readRedisConfig returns a RedisConfig object populated with values read by given reader.
+func readRedisConfig(r *xconfReader) RedisConfig {
+	defAddrs := []string{"127.0.0.1:6379"}
+	redisConfig := RedisConfig{
+		Addrs: r.StringSlice(RedisCfgKeyAddrs, defAddrs),
+		DB:    r.Int(RedisCfgKeyDB, 0),
 		Auth: RedisAuth{
-			Username: config.Get(RedisCfgKeyAuthUsername, "").(string),
-			Password: config.Get(RedisCfgKeyAuthPassword, "").(string),
+			Username: r.String(RedisCfgKeyAuthUsername, ""),
+			Password: r.String(RedisCfgKeyAuthPassword, ""),
 		},
-		DialTimeout:  config.Get(RedisCfgKeyDialTimeout, 5*time.Second).(time.Duration),
-		ReadTimeout:  config.Get(RedisCfgKeyReadTimeout, 3*time.Second).(time.Duration),
-		WriteTimeout: config.Get(RedisCfgKeyWriteTimeout, 5*time.Second).(time.Duration),
-		ReadOnly:     config.Get(RedisCfgKeyClusterReadonly, false).(bool),
-		MasterName:   config.Get(RedisCfgKeyFailoverMasterName, "").(string),
+		DialTimeout:      r.Duration(RedisCfgKeyDialTimeout, 5*time.Second),
+		ReadTimeout:      r.Duration(RedisCfgKeyReadTimeout, 3*time.Second),
+		WriteTimeout:     r.Duration(RedisCfgKeyWriteTimeout, 5*time.Second),
+		DisableUnlink:    r.Bool(RedisCfgKeyDisableUnlink, false),
+		StatsCacheTTL:    r.Duration(RedisCfgKeyStatsCacheTTL, 0),
+		ForbidNoExpire:   r.Bool(RedisCfgKeyForbidNoExpire, false),
+		NoExpireTTL:      r.Duration(RedisCfgKeyNoExpireTTL, 0),
+		KeyPrefix:        r.String(RedisCfgKeyKeyPrefix, ""),
+		CountPrefixKeys:  r.Bool(RedisCfgKeyCountPrefixKeys, false),
+		ReadOnly:         r.Bool(RedisCfgKeyClusterReadonly, false),
+		CountClusterKeys: r.Bool(RedisCfgKeyClusterCountKeys, false),
+		MasterName:       r.String(RedisCfgKeyFailoverMasterName, ""),
 		SentinelAuth: RedisAuth{
-			Username: config.Get(RedisCfgKeyFailoverAuthUsername, "").(string),
-			Password: config.Get(RedisCfgKeyFailoverAuthPassword, "").(string),
+			Username: r.String(RedisCfgKeyFailoverAuthUsername, ""),
+			Password: r.String(RedisCfgKeyFailoverAuthPassword, ""),
 		},
 	}
+	if r.Bool(RedisCfgKeyTLS, false) {
+		redisConfig.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if len(redisConfig.Addrs) == 0 {
+		r.addErr(RedisCfgKeyAddrs, errConfigValueEmpty)
+		redisConfig.Addrs = defAddrs
+	}
+	if redisConfig.DB < 0 {
+		r.addErr(RedisCfgKeyDB, errConfigValueRange)
+		redisConfig.DB = 0
+	}
+	if redisConfig.StatsCacheTTL < 0 {
+		r.addErr(RedisCfgKeyStatsCacheTTL, errConfigValueRange)
+		redisConfig.StatsCacheTTL = 0
+	}
+	if redisConfig.NoExpireTTL < 0 {
+		r.addErr(RedisCfgKeyNoExpireTTL, errConfigValueRange)
+		redisConfig.NoExpireTTL = 0
+	}
+
+	return redisConfig
+}
+
+// ValidateRedisXConfig checks the Redis configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for each value which cannot be interpreted,
+// or nil if configuration is valid.
+//
+// NewRedis6WithConfig / NewRedis7WithConfig do not fail on invalid values, defaults are used instead,
+// so you may want to call this function at your application's startup, in order to fail fast
+// (or use NewRedis6WithValidConfig / NewRedis7WithValidConfig).
+func ValidateRedisXConfig(config xconf.Config) error {
+	_, err := getRedisConfig(config)
+
+	return err
 }
 
 // isRedisConfigKey checks of give key is one of RedisCfgKey*. config keys.
@@ -67,8 +142,17 @@ func isRedisConfigKey(key string) bool {
 		key == RedisCfgKeyDialTimeout ||
 		key == RedisCfgKeyReadTimeout ||
 		key == RedisCfgKeyWriteTimeout ||
+		key == RedisCfgKeyTLS ||
+		key == RedisCfgKeyDisableUnlink ||
+		key == RedisCfgKeyStatsCacheTTL ||
+		key == RedisCfgKeyForbidNoExpire ||
+		key == RedisCfgKeyNoExpireTTL ||
+		key == RedisCfgKeyKeyPrefix ||
+		key == RedisCfgKeyCountPrefixKeys ||
 		key == RedisCfgKeyClusterReadonly ||
+		key == RedisCfgKeyClusterCountKeys ||
 		key == RedisCfgKeyFailoverMasterName ||
 		key == RedisCfgKeyFailoverAuthUsername ||
-		key == RedisCfgKeyFailoverAuthPassword
+		key == RedisCfgKeyFailoverAuthPassword ||
+		key == CfgKeyDurationUnit
 }
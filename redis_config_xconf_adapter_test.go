@@ -0,0 +1,173 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestValidateRedisXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing values",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name: "expected types",
+			config: xconf.NewMockConfig(
+				xcache.RedisCfgKeyAddrs, []string{"127.0.0.1:6379"},
+				xcache.RedisCfgKeyDB, 1,
+				xcache.RedisCfgKeyAuthPassword, "secret",
+				xcache.RedisCfgKeyDialTimeout, 2*time.Second,
+				xcache.RedisCfgKeyDisableUnlink, true,
+			),
+		},
+		{
+			name: "common representations",
+			config: xconf.NewMockConfig(
+				xcache.RedisCfgKeyAddrs, "127.0.0.1:7000, 127.0.0.1:7001,",
+				xcache.RedisCfgKeyDB, "1",
+				xcache.RedisCfgKeyAuthPassword, 1234,
+				xcache.RedisCfgKeyDialTimeout, "2s",
+				xcache.RedisCfgKeyReadTimeout, int64(time.Second),
+				xcache.RedisCfgKeyWriteTimeout, "1000000000",
+				xcache.RedisCfgKeyDisableUnlink, "true",
+				xcache.RedisCfgKeyStatsCacheTTL, "500ms",
+				xcache.RedisCfgKeyForbidNoExpire, "true",
+				xcache.RedisCfgKeyNoExpireTTL, "24h",
+				xcache.RedisCfgKeyKeyPrefix, "app:",
+				xcache.RedisCfgKeyCountPrefixKeys, "true",
+				xcache.RedisCfgKeyClusterReadonly, 1,
+				xcache.RedisCfgKeyClusterCountKeys, "true",
+			),
+		},
+		{
+			name: "durations as milliseconds",
+			config: xconf.NewMockConfig(
+				xcache.CfgKeyDurationUnit, "ms",
+				xcache.RedisCfgKeyDialTimeout, 2000,
+				xcache.RedisCfgKeyReadTimeout, "1000",
+				xcache.RedisCfgKeyWriteTimeout, "1s",
+				xcache.RedisCfgKeyStatsCacheTTL, 500.0,
+			),
+		},
+		{
+			name: "invalid values",
+			config: xconf.NewMockConfig(
+				xcache.RedisCfgKeyAddrs, []int{6379},
+				xcache.RedisCfgKeyDB, "one",
+				xcache.RedisCfgKeyAuthPassword, []string{"secret"},
+				xcache.RedisCfgKeyDialTimeout, "2 seconds",
+				xcache.RedisCfgKeyReadTimeout, 1.5,
+				xcache.RedisCfgKeyDisableUnlink, "sure",
+				xcache.RedisCfgKeyForbidNoExpire, "yes",
+				xcache.RedisCfgKeyClusterReadonly, 2,
+			),
+			expectedErrKeys: []string{
+				xcache.RedisCfgKeyAddrs,
+				xcache.RedisCfgKeyDB,
+				xcache.RedisCfgKeyAuthPassword,
+				xcache.RedisCfgKeyDialTimeout,
+				xcache.RedisCfgKeyReadTimeout,
+				xcache.RedisCfgKeyDisableUnlink,
+				xcache.RedisCfgKeyForbidNoExpire,
+				xcache.RedisCfgKeyClusterReadonly,
+			},
+		},
+		{
+			name: "out of range values",
+			config: xconf.NewMockConfig(
+				xcache.RedisCfgKeyAddrs, " , ",
+				xcache.RedisCfgKeyDB, -1,
+				xcache.RedisCfgKeyStatsCacheTTL, -time.Second,
+				xcache.RedisCfgKeyNoExpireTTL, "-1h",
+			),
+			expectedErrKeys: []string{
+				xcache.RedisCfgKeyAddrs,
+				xcache.RedisCfgKeyDB,
+				xcache.RedisCfgKeyStatsCacheTTL,
+				xcache.RedisCfgKeyNoExpireTTL,
+			},
+		},
+		{
+			name: "invalid duration unit",
+			config: xconf.NewMockConfig(
+				xcache.CfgKeyDurationUnit, "parsecs",
+				xcache.RedisCfgKeyDialTimeout, 2000,
+			),
+			expectedErrKeys: []string{
+				xcache.CfgKeyDurationUnit,
+			},
+		},
+		{
+			name: "out of range duration",
+			config: xconf.NewMockConfig(
+				xcache.CfgKeyDurationUnit, "h",
+				xcache.RedisCfgKeyDialTimeout, int64(math.MaxInt64/2),
+			),
+			expectedErrKeys: []string{
+				xcache.RedisCfgKeyDialTimeout,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateRedisXConfig(test.config)
+			cache, resultCtorErr := xcache.NewRedis7WithValidConfig(test.config)
+			if cache != nil {
+				_ = cache.Close()
+			}
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, cache != nil)
+		})
+	}
+}
+
+// assertConfigErrorKeys checks that given error aggregates a ConfigError for each of given keys.
+func assertConfigErrorKeys(t *testing.T, expectedKeys []string, err error) {
+	t.Helper()
+
+	if len(expectedKeys) == 0 {
+		assertNil(t, err)
+
+		return
+	}
+
+	errs := []error{err}
+	var mErr interface{ Errors() []error }
+	if errors.As(err, &mErr) {
+		errs = mErr.Errors()
+	}
+	if !assertEqual(t, len(expectedKeys), len(errs)) {
+		return
+	}
+	for i, expectedKey := range expectedKeys {
+		var cfgErr *xcache.ConfigError
+		if assertTrue(t, errors.As(errs[i], &cfgErr)) {
+			assertEqual(t, expectedKey, cfgErr.Key)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"testing"
+
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+func TestRedis_SaveKeepTTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("redis6", testCacheSaveKeepTTL(xcachetest.NewMiniRedis(t).NewRedis6(t)))
+	t.Run("redis7", testCacheSaveKeepTTL(xcachetest.NewMiniRedis(t).NewRedis7(t)))
+}
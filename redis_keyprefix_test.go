@@ -0,0 +1,130 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+// redisKeyPrefixCache is the subset of Redis6 / Redis7 api the key prefix applies to.
+type redisKeyPrefixCache interface {
+	xcache.Cache
+	xcache.Batcher
+	xcache.Deleter
+	xcache.BulkDeleter
+	xcache.ExistenceChecker
+	xcache.Toucher
+	xcache.PrefixDeleter
+	xcache.Scanner
+	io.Closer
+	Tx(ctx context.Context, fn func(tx xcache.TxCache) error, watchKeys ...string) error
+}
+
+func TestRedis_keyPrefix(t *testing.T) {
+	t.Parallel()
+
+	newCaches := map[string]func(config xcache.RedisConfig) redisKeyPrefixCache{
+		"redis6": func(config xcache.RedisConfig) redisKeyPrefixCache { return xcache.NewRedis6(config) },
+		"redis7": func(config xcache.RedisConfig) redisKeyPrefixCache { return xcache.NewRedis7(config) },
+	}
+	for name, newCache := range newCaches {
+		newCache := newCache // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testRedisKeyPrefixIsApplied(t, newCache)
+		})
+	}
+}
+
+func testRedisKeyPrefixIsApplied(t *testing.T, newCache func(config xcache.RedisConfig) redisKeyPrefixCache) {
+	t.Helper()
+
+	// arrange
+	var (
+		mr     = xcachetest.NewMiniRedis(t)
+		config = mr.Config()
+		ctx    = context.Background()
+		value  = []byte("test value")
+	)
+	config.KeyPrefix = "app:"
+	subject := newCache(config)
+	defer subject.Close()
+	requireNil(t, mr.Set("other-app-key", "other value"))
+
+	// act & assert single key ops
+	requireNil(t, subject.Save(ctx, "key-1", value, time.Minute))
+	assertTrue(t, mr.Exists("app:key-1"))
+	loadedValue, err := subject.Load(ctx, "key-1")
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, "key-1")
+	assertNil(t, err)
+	assertTrue(t, ttl > 58*time.Second && ttl <= time.Minute)
+	exists, err := subject.Has(ctx, "key-1")
+	assertNil(t, err)
+	assertTrue(t, exists)
+	touched, err := subject.Touch(ctx, "key-1", xcache.NoExpire)
+	assertNil(t, err)
+	assertTrue(t, touched)
+	_, err = subject.Load(ctx, "other-app-key")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	// act & assert batch ops
+	requireNil(t, subject.SaveMany(ctx, map[string]xcache.Item{
+		"key-2": {Value: value, Expire: time.Minute},
+		"key-3": {Value: value, Expire: time.Minute},
+	}))
+	assertTrue(t, mr.Exists("app:key-2") && mr.Exists("app:key-3"))
+	values, err := subject.LoadMany(ctx, []string{"key-1", "key-2", "key-4"})
+	assertNil(t, err)
+	assertEqual(t, map[string][]byte{"key-1": value, "key-2": value}, values)
+
+	// act & assert stats count all the keys, unless counting only own keys is enabled
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(4), stats.Keys)
+	config.CountPrefixKeys = true
+	countingSubject := newCache(config)
+	defer countingSubject.Close()
+	stats, err = countingSubject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(3), stats.Keys)
+
+	// act & assert scan returns keys without prefix
+	keys, cursor, err := subject.Scan(ctx, "", "key-", 100)
+	assertNil(t, err)
+	assertEqual(t, "", cursor)
+	sort.Strings(keys)
+	assertEqual(t, []string{"key-1", "key-2", "key-3"}, keys)
+
+	// act & assert transaction
+	err = subject.Tx(ctx, func(tx xcache.TxCache) error {
+		txValue, err := tx.Load(ctx, "key-1")
+		if err != nil {
+			return err
+		}
+
+		return tx.Save(ctx, "key-4", txValue, time.Minute)
+	}, "key-1")
+	assertNil(t, err)
+	assertTrue(t, mr.Exists("app:key-4"))
+
+	// act & assert deletions
+	requireNil(t, subject.Delete(ctx, "key-4"))
+	requireNil(t, subject.DeleteMany(ctx, "key-3"))
+	deleted, err := subject.DeletePrefix(ctx, "key-")
+	assertNil(t, err)
+	assertEqual(t, 2, deleted)
+	assertEqual(t, []string{"other-app-key"}, mr.Keys())
+}
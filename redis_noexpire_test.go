@@ -0,0 +1,144 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+// redisNoExpireCache is the subset of Redis6 / Redis7 api the NoExpire policy applies to.
+type redisNoExpireCache interface {
+	xcache.Cache
+	xcache.Batcher
+	xcache.Toucher
+	xcache.TTLKeeper
+	io.Closer
+	Tx(ctx context.Context, fn func(tx xcache.TxCache) error, watchKeys ...string) error
+}
+
+func TestRedis_noExpirePolicy(t *testing.T) {
+	t.Parallel()
+
+	newCaches := map[string]func(config xcache.RedisConfig) redisNoExpireCache{
+		"redis6": func(config xcache.RedisConfig) redisNoExpireCache { return xcache.NewRedis6(config) },
+		"redis7": func(config xcache.RedisConfig) redisNoExpireCache { return xcache.NewRedis7(config) },
+	}
+	for name, newCache := range newCaches {
+		newCache := newCache // capture range variable
+		t.Run(name+" forbid", func(t *testing.T) {
+			t.Parallel()
+			testRedisNoExpireIsForbidden(t, newCache)
+		})
+		t.Run(name+" substitute ttl", func(t *testing.T) {
+			t.Parallel()
+			testRedisNoExpireTTLIsSubstituted(t, newCache)
+		})
+	}
+}
+
+func testRedisNoExpireIsForbidden(t *testing.T, newCache func(config xcache.RedisConfig) redisNoExpireCache) {
+	t.Helper()
+
+	// arrange
+	var (
+		mr     = xcachetest.NewMiniRedis(t)
+		config = mr.Config()
+		ctx    = context.Background()
+		key    = "test-redis-noexpire-forbidden-key"
+		value  = []byte("test value")
+	)
+	config.ForbidNoExpire = true
+	subject := newCache(config)
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, value, xcache.NoExpire)
+	errSaveMany := subject.SaveMany(ctx, map[string]xcache.Item{
+		key:          {Value: value, Expire: time.Minute},
+		key + "-bis": {Value: value, Expire: xcache.NoExpire},
+	})
+	errTx := subject.Tx(ctx, func(tx xcache.TxCache) error {
+		return tx.Save(ctx, key, value, xcache.NoExpire)
+	})
+
+	// assert
+	assertTrue(t, errors.Is(errSave, xcache.ErrNoExpireForbidden))
+	assertTrue(t, errors.Is(errSaveMany, xcache.ErrNoExpireForbidden))
+	assertTrue(t, errors.Is(errTx, xcache.ErrNoExpireForbidden))
+	assertEqual(t, 0, len(mr.Keys()))
+
+	// act & assert keys with expiration are saved, touching with NoExpire is forbidden
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	touched, errTouch := subject.Touch(ctx, key, xcache.NoExpire)
+	assertTrue(t, errors.Is(errTouch, xcache.ErrNoExpireForbidden))
+	assertTrue(t, !touched)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 0 && ttl <= time.Minute)
+
+	// act & assert existing key's value is overwritten keeping its TTL, missing key is not saved
+	assertNil(t, subject.SaveKeepTTL(ctx, key, value))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 0 && ttl <= time.Minute)
+	errKeepTTL := subject.SaveKeepTTL(ctx, key+"-bis", value)
+	assertTrue(t, errors.Is(errKeepTTL, xcache.ErrNoExpireForbidden))
+	assertEqual(t, 1, len(mr.Keys()))
+}
+
+func testRedisNoExpireTTLIsSubstituted(t *testing.T, newCache func(config xcache.RedisConfig) redisNoExpireCache) {
+	t.Helper()
+
+	// arrange
+	var (
+		mr     = xcachetest.NewMiniRedis(t)
+		config = mr.Config()
+		ctx    = context.Background()
+		key    = "test-redis-noexpire-ttl-key"
+		value  = []byte("test value")
+	)
+	config.ForbidNoExpire = true // NoExpireTTL takes precedence.
+	config.NoExpireTTL = time.Hour
+	subject := newCache(config)
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, value, xcache.NoExpire)
+	errSaveMany := subject.SaveMany(ctx, map[string]xcache.Item{
+		key + "-bis": {Value: value, Expire: xcache.NoExpire},
+	})
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errSaveMany)
+	for _, k := range []string{key, key + "-bis"} {
+		ttl, err := subject.TTL(ctx, k)
+		assertNil(t, err)
+		assertTrue(t, ttl > 59*time.Minute && ttl <= time.Hour)
+	}
+
+	// act & assert touch
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	touched, err := subject.Touch(ctx, key, xcache.NoExpire)
+	assertNil(t, err)
+	assertTrue(t, touched)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Minute && ttl <= time.Hour)
+
+	// act & assert missing key is saved with substituted TTL
+	requireNil(t, subject.SaveKeepTTL(ctx, key+"-ter", value))
+	ttl, err = subject.TTL(ctx, key+"-ter")
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Minute && ttl <= time.Hour)
+}
@@ -0,0 +1,23 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import "errors"
+
+// ErrTxAborted is returned by a Redis transaction whose watched keys were modified
+// before its commands were executed. The transaction can be retried.
+var ErrTxAborted = errors.New("transaction aborted, watched keys were modified")
+
+// TxCache is a Cache which groups the writes performed through it into a Redis transaction
+// (see Redis6.Tx, Redis7.Tx): saves and deletions are queued, and executed atomically (MULTI / EXEC)
+// after the transaction function returns, so they return no error themselves.
+// Loads, TTLs, and stats are performed right away (outside of the transaction),
+// so watched keys can be read, and checked, before queueing the writes.
+// As a TxCache is a Cache, helpers built on top of a Cache (like Tags) can be used within a transaction.
+type TxCache interface {
+	Cache
+	Deleter
+}
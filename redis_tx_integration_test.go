@@ -0,0 +1,177 @@
+//go:build integration
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+// txCache is a Redis cache which supports transactions.
+type txCache interface {
+	xcache.Cache
+	Tx(ctx context.Context, fn func(tx xcache.TxCache) error, watchKeys ...string) error
+}
+
+func TestRedis6_Tx_integration(t *testing.T) {
+	t.Parallel()
+
+	// setup
+	subject := xcache.NewRedis6(redis6ConfigIntegration)
+
+	t.Run("wait", func(t *testing.T) { // wait for parallel tests to complete
+		t.Run("writes are committed", testRedisTxWritesAreCommitted(subject, "redis6"))
+		t.Run("function error discards writes", testRedisTxFunctionErrorDiscardsWrites(subject, "redis6"))
+		t.Run("modified watched key aborts tx", testRedisTxModifiedWatchedKeyAbortsTx(subject, "redis6"))
+	})
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
+
+func TestRedis7_Tx_integration(t *testing.T) {
+	t.Parallel()
+
+	// setup
+	subject := xcache.NewRedis7(redis7ConfigIntegration)
+
+	t.Run("wait", func(t *testing.T) { // wait for parallel tests to complete
+		t.Run("writes are committed", testRedisTxWritesAreCommitted(subject, "redis7"))
+		t.Run("function error discards writes", testRedisTxFunctionErrorDiscardsWrites(subject, "redis7"))
+		t.Run("modified watched key aborts tx", testRedisTxModifiedWatchedKeyAbortsTx(subject, "redis7"))
+	})
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
+
+func testRedisTxWritesAreCommitted(subject txCache, keyPrefix string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			ctx        = context.Background()
+			key        = "{" + keyPrefix + "-tx}-test-key"
+			versionKey = "{" + keyPrefix + "-tx}-test-version-key"
+			deletedKey = "{" + keyPrefix + "-tx}-test-deleted-key"
+			value      = []byte("test value")
+		)
+		requireNil(t, subject.Save(ctx, versionKey, []byte("1"), time.Minute))
+		requireNil(t, subject.Save(ctx, deletedKey, []byte("test deleted value"), time.Minute))
+
+		// act
+		err := subject.Tx(ctx, func(tx xcache.TxCache) error {
+			version, err := tx.Load(ctx, versionKey)
+			if err != nil {
+				return err
+			}
+			_ = tx.Save(ctx, key, value, time.Minute)
+			_ = tx.Save(ctx, versionKey, append(version, '1'), time.Minute)
+			_ = tx.Delete(ctx, deletedKey)
+
+			// writes are queued, not visible before the tx is committed.
+			_, err = tx.Load(ctx, key)
+			assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+			return nil
+		}, versionKey)
+
+		// assert
+		assertNil(t, err)
+		resultValue, err := subject.Load(ctx, key)
+		assertNil(t, err)
+		assertEqual(t, value, resultValue)
+		resultVersion, err := subject.Load(ctx, versionKey)
+		assertNil(t, err)
+		assertEqual(t, []byte("11"), resultVersion)
+		_, err = subject.Load(ctx, deletedKey)
+		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+		// act & assert a tx without watched keys
+		err = subject.Tx(ctx, func(tx xcache.TxCache) error {
+			return xcache.NewTags(tx, "{"+keyPrefix+"-tx}-tag-").Invalidate(ctx, "users", "orders")
+		})
+		assertNil(t, err)
+		_, err = subject.Load(ctx, "{"+keyPrefix+"-tx}-tag-orders")
+		assertNil(t, err)
+
+		// tear down
+		_ = subject.Save(ctx, key, nil, -1)
+		_ = subject.Save(ctx, versionKey, nil, -1)
+		_ = subject.Save(ctx, "{"+keyPrefix+"-tx}-tag-users", nil, -1)
+		_ = subject.Save(ctx, "{"+keyPrefix+"-tx}-tag-orders", nil, -1)
+	}
+}
+
+func testRedisTxFunctionErrorDiscardsWrites(subject txCache, keyPrefix string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			ctx         = context.Background()
+			key         = keyPrefix + "-tx-test-discarded-key"
+			expectedErr = errors.New("intentionally triggered tx function error")
+		)
+
+		// act
+		err := subject.Tx(ctx, func(tx xcache.TxCache) error {
+			_ = tx.Save(ctx, key, []byte("test value"), time.Minute)
+
+			return expectedErr
+		})
+
+		// assert
+		assertTrue(t, errors.Is(err, expectedErr))
+		_, err = subject.Load(ctx, key)
+		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	}
+}
+
+func testRedisTxModifiedWatchedKeyAbortsTx(subject txCache, keyPrefix string) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var (
+			ctx        = context.Background()
+			key        = "{" + keyPrefix + "-tx-abort}-test-key"
+			versionKey = "{" + keyPrefix + "-tx-abort}-test-version-key"
+		)
+		requireNil(t, subject.Save(ctx, versionKey, []byte("1"), time.Minute))
+
+		// act
+		err := subject.Tx(ctx, func(tx xcache.TxCache) error {
+			_, _ = tx.Load(ctx, versionKey)
+			// another client modifies the watched key meanwhile.
+			requireNil(t, subject.Save(ctx, versionKey, []byte("2"), time.Minute))
+			_ = tx.Save(ctx, key, []byte("test value"), time.Minute)
+			_ = tx.Save(ctx, versionKey, []byte("3"), time.Minute)
+
+			return nil
+		}, versionKey)
+
+		// assert
+		assertTrue(t, errors.Is(err, xcache.ErrTxAborted))
+		_, err = subject.Load(ctx, key)
+		assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+		resultVersion, err := subject.Load(ctx, versionKey)
+		assertNil(t, err)
+		assertEqual(t, []byte("2"), resultVersion)
+
+		// tear down
+		_ = subject.Save(ctx, versionKey, nil, -1)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RefreshFunc fetches a key's value from the source of truth (a database, a service, etc.),
+// returning it along with the expiration period it should be cached for.
+type RefreshFunc func(ctx context.Context, key string) ([]byte, time.Duration, error)
+
+// RefreshPolicy holds the refresh settings of the keys sharing a prefix (a data class), see RefreshAhead.SetPolicies.
+type RefreshPolicy struct {
+	// Prefix is the prefix of the keys the policy applies to.
+	// If more policies match a key, the one with the longest prefix applies.
+	Prefix string
+	// SoftTTLFraction is the fraction, within (0, 1), of a key's expiration period after which the key is refreshed
+	// (for example, with 0.8, a key saved for 1h is refreshed when loaded after 48m).
+	// 0 means the RefreshAhead's threshold applies.
+	SoftTTLFraction float64
+	// Jitter is the percent, within [0, 100), the refresh window is randomly enlarged / shrunk with, on each load,
+	// so that the instances of an application do not refresh a key at the same moment.
+	Jitter float64
+	// MaxConcurrent is the max. no. of concurrent refreshes of the keys the policy applies to
+	// (refreshes exceeding it are skipped, a later Load retrying them). 0 means no limit, other than pool's workers.
+	MaxConcurrent int
+}
+
+// RefreshAhead is a Cache decorator which keeps hot keys warm: when a loaded key's remaining time to live
+// falls below a threshold, the key's value is refreshed in background, with a RefreshFunc,
+// while the current value is returned, so users do not experience latency spikes when hot keys expire.
+//
+// Keys' expiration moments are stored alongside the values, in an Envelope, so no extra round trip
+// is needed for checking them. Values not having an envelope (saved directly into decorated cache)
+// are returned as they are, and are not refreshed; keys saved with NoExpire are not refreshed either.
+//
+// Refreshes are executed by a WorkerPool (the no. of concurrent refreshes is bounded by the pool's workers),
+// and a key already being refreshed is not refreshed again, until its refresh completes.
+// Refreshes which cannot be scheduled (the pool's queue is full) are skipped, a later Load retrying them.
+//
+// Refresh settings can be customized per key prefix, see SetPolicies (and NewRefreshAheadWithConfig,
+// for having them driven by a xconf.Config).
+//
+// Example:
+//
+//	cache := xcache.NewRefreshAhead(redisCache, time.Minute, pool,
+//		func(ctx context.Context, key string) ([]byte, time.Duration, error) {
+//			value, err := productsRepo.GetJSON(ctx, strings.TrimPrefix(key, "product:"))
+//
+//			return value, time.Hour, err
+//		},
+//	)
+type RefreshAhead struct {
+	cache     Cache
+	threshold time.Duration
+	pool      *WorkerPool
+	refresh   RefreshFunc
+	inFlight  map[string]struct{}
+	policies  []RefreshPolicy // sorted by prefix length, descending.
+	active    map[string]int  // no. of in flight refreshes, per policy prefix.
+	mu        sync.Mutex
+	refreshed int64
+	failed    int64
+}
+
+// NewRefreshAhead instantiates a new RefreshAhead which decorates given cache, refreshing keys,
+// through given pool, with given function, when their remaining time to live falls below given threshold.
+func NewRefreshAhead(cache Cache, threshold time.Duration, pool *WorkerPool, refresh RefreshFunc) *RefreshAhead {
+	return &RefreshAhead{
+		cache:     cache,
+		threshold: threshold,
+		pool:      pool,
+		refresh:   refresh,
+		inFlight:  make(map[string]struct{}),
+		active:    make(map[string]int),
+	}
+}
+
+// SetPolicies sets the refresh policies of the keys having given prefixes, replacing the current ones.
+// Keys not matching any policy are refreshed according to the threshold. It's safe to be called at runtime.
+func (cache *RefreshAhead) SetPolicies(policies ...RefreshPolicy) {
+	sorted := append([]RefreshPolicy(nil), policies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+
+	cache.mu.Lock()
+	cache.policies = sorted
+	cache.mu.Unlock()
+}
+
+// Policies returns the current refresh policies.
+func (cache *RefreshAhead) Policies() []RefreshPolicy {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return append([]RefreshPolicy(nil), cache.policies...)
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+func (cache *RefreshAhead) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.cache.Save(ctx, key, value, expire)
+	}
+
+	var (
+		env       Envelope
+		periodBuf [binary.MaxVarintLen64]byte
+	)
+	if expire > 0 {
+		env.ExpireAt = time.Now().Add(expire).UnixMilli()
+		// the expiration period is kept, too, for the soft TTL of the key's policy.
+		env.Metadata = binary.AppendUvarint(periodBuf[:0], uint64(expire.Milliseconds()))
+	}
+	enveloped := env.Append(make([]byte, 0, env.Size(len(value))), value)
+
+	return cache.cache.Save(ctx, key, enveloped, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// If the key's remaining time to live is below the threshold (or the refresh window of the key's policy),
+// its refresh is scheduled, in background.
+func (cache *RefreshAhead) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil || !IsEnvelope(value) {
+		return value, err
+	}
+
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return value, nil // not a value of ours, return it as it is.
+	}
+	if env.ExpireAt > 0 {
+		remaining := time.Until(time.UnixMilli(env.ExpireAt))
+		if remaining <= 0 {
+			return nil, ErrNotFound
+		}
+		policy, found := cache.policy(key)
+		if remaining < cache.refreshWindow(policy, found, env.Metadata) {
+			cache.scheduleRefresh(ctx, key, policy.Prefix)
+		}
+	}
+
+	return payload, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *RefreshAhead) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *RefreshAhead) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *RefreshAhead) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of refreshed keys, as "refreshahead.refreshed" metric,
+// and the no. of failed refreshes (the RefreshFunc, or the save, failed), as "refreshahead.failed" metric.
+func (cache *RefreshAhead) ContributeStats(add func(name string, value float64)) {
+	add("refreshahead.refreshed", float64(atomic.LoadInt64(&cache.refreshed)))
+	add("refreshahead.failed", float64(atomic.LoadInt64(&cache.failed)))
+}
+
+// policy returns the refresh policy of given key, if any.
+func (cache *RefreshAhead) policy(key string) (RefreshPolicy, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for _, policy := range cache.policies {
+		if strings.HasPrefix(key, policy.Prefix) {
+			return policy, true
+		}
+	}
+
+	return RefreshPolicy{}, false
+}
+
+// refreshWindow returns the remaining time to live below which a key, having given policy and envelope metadata
+// (its expiration period), is refreshed.
+func (cache *RefreshAhead) refreshWindow(policy RefreshPolicy, found bool, metadata []byte) time.Duration {
+	window := cache.threshold
+	if !found {
+		return window
+	}
+	if policy.SoftTTLFraction > 0 && policy.SoftTTLFraction < 1 {
+		if periodMillis, n := binary.Uvarint(metadata); n > 0 && periodMillis > 0 {
+			period := time.Duration(periodMillis) * time.Millisecond
+			window = time.Duration(float64(period) * (1 - policy.SoftTTLFraction))
+		}
+	}
+	if policy.Jitter > 0 && policy.Jitter < 100 {
+		window += time.Duration(float64(window) * policy.Jitter / 100 * (2*rand.Float64() - 1))
+	}
+
+	return window
+}
+
+// scheduleRefresh schedules the refresh of given key, having given policy prefix,
+// if it's not already being refreshed, and policy's concurrency limit is not reached.
+// Note: the refresh is not canceled if the context gets done.
+func (cache *RefreshAhead) scheduleRefresh(ctx context.Context, key, prefix string) {
+	if !cache.acquire(key, prefix) {
+		return
+	}
+	bgCtx := context.WithoutCancel(ctx)
+	if !cache.pool.Submit(func() {
+		defer cache.release(key, prefix)
+		cache.refreshKey(bgCtx, key)
+	}) {
+		cache.release(key, prefix)
+	}
+}
+
+// refreshKey fetches given key's value with the RefreshFunc, and saves it.
+func (cache *RefreshAhead) refreshKey(ctx context.Context, key string) {
+	value, expire, err := cache.refresh(ctx, key)
+	if err == nil {
+		err = cache.Save(ctx, key, value, expire)
+	}
+	if err != nil {
+		atomic.AddInt64(&cache.failed, 1)
+
+		return
+	}
+	atomic.AddInt64(&cache.refreshed, 1)
+}
+
+// acquire marks the given key, having given policy prefix, as being refreshed.
+// It returns false if the key is already being refreshed, or the policy's concurrency limit is reached.
+func (cache *RefreshAhead) acquire(key, prefix string) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, found := cache.inFlight[key]; found {
+		return false
+	}
+	if limit := cache.maxConcurrent(prefix); limit > 0 && cache.active[prefix] >= limit {
+		return false
+	}
+	cache.inFlight[key] = struct{}{}
+	cache.active[prefix]++
+
+	return true
+}
+
+// release marks the given key, having given policy prefix, as not being refreshed anymore.
+func (cache *RefreshAhead) release(key, prefix string) {
+	cache.mu.Lock()
+	delete(cache.inFlight, key)
+	if cache.active[prefix]--; cache.active[prefix] <= 0 {
+		delete(cache.active, prefix)
+	}
+	cache.mu.Unlock()
+}
+
+// maxConcurrent returns the concurrency limit of the policy having given prefix (0 if there is none).
+// Mutex must be locked.
+func (cache *RefreshAhead) maxConcurrent(prefix string) int {
+	for _, policy := range cache.policies {
+		if policy.Prefix == prefix {
+			return policy.MaxConcurrent
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,258 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.RefreshAhead)(nil)            // test RefreshAhead is a Cache
+	var _ xcache.StatsContributor = (*xcache.RefreshAhead)(nil) // test RefreshAhead is a StatsContributor
+}
+
+func TestRefreshAhead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("key near expiration is refreshed", testRefreshAheadKeyNearExpirationIsRefreshed)
+	t.Run("fresh key is not refreshed", testRefreshAheadFreshKeyIsNotRefreshed)
+	t.Run("failed refresh is counted", testRefreshAheadFailedRefreshIsCounted)
+	t.Run("value without envelope is returned", testRefreshAheadValueWithoutEnvelopeIsReturned)
+	t.Run("policy soft ttl", testRefreshAheadPolicySoftTTL)
+	t.Run("policy max concurrent refreshes", testRefreshAheadPolicyMaxConcurrent)
+}
+
+func testRefreshAheadKeyNearExpirationIsRefreshed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = xcache.NewMemory(freecacheMinMem)
+		pool      = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		refreshes int32
+		subject   = xcache.NewRefreshAhead(cache, time.Minute, pool,
+			func(_ context.Context, key string) ([]byte, time.Duration, error) {
+				atomic.AddInt32(&refreshes, 1)
+				time.Sleep(10 * time.Millisecond) // concurrent loads do not trigger another refresh.
+
+				return []byte("test refreshed value"), time.Hour, nil
+			},
+		)
+		ctx   = context.Background()
+		key   = "test-refresh-ahead-key"
+		value = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, key, value, 30*time.Second))
+
+	// act
+	result1, err1 := subject.Load(ctx, key)
+	result2, err2 := subject.Load(ctx, key)
+	requireNil(t, pool.Close()) // wait for refreshes to complete.
+
+	// assert
+	assertNil(t, err1)
+	assertEqual(t, value, result1) // current value is returned.
+	assertNil(t, err2)
+	assertEqual(t, value, result2)
+	assertEqual(t, int32(1), atomic.LoadInt32(&refreshes))
+	result, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("test refreshed value"), result)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Minute)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"refreshahead.refreshed": 1, "refreshahead.failed": 0}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testRefreshAheadFreshKeyIsNotRefreshed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewRefreshAhead(cache, time.Minute, pool,
+			func(context.Context, string) ([]byte, time.Duration, error) {
+				t.Error("refresh should not be called")
+
+				return nil, 0, nil
+			},
+		)
+		ctx      = context.Background()
+		key      = "test-refresh-ahead-fresh-key"
+		noExpKey = "test-refresh-ahead-no-exp-key"
+		value    = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, key, value, time.Hour))
+	requireNil(t, subject.Save(ctx, noExpKey, value, xcache.NoExpire))
+
+	// act
+	result, err := subject.Load(ctx, key)
+	resultNoExp, errNoExp := subject.Load(ctx, noExpKey)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertNil(t, errNoExp)
+	assertEqual(t, value, resultNoExp)
+}
+
+func testRefreshAheadFailedRefreshIsCounted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewRefreshAhead(cache, time.Minute, pool,
+			func(context.Context, string) ([]byte, time.Duration, error) {
+				return nil, 0, errors.New("intentionally triggered refresh error")
+			},
+		)
+		ctx   = context.Background()
+		key   = "test-refresh-ahead-failed-key"
+		value = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, key, value, 30*time.Second))
+
+	// act
+	result, err := subject.Load(ctx, key)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"refreshahead.refreshed": 0, "refreshahead.failed": 1}, metrics)
+	result, err = subject.Load(ctx, key) // current value is kept.
+	assertNil(t, err)
+	assertEqual(t, value, result)
+}
+
+func testRefreshAheadValueWithoutEnvelopeIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewRefreshAhead(cache, time.Hour, pool,
+			func(context.Context, string) ([]byte, time.Duration, error) {
+				t.Error("refresh should not be called")
+
+				return nil, 0, nil
+			},
+		)
+		ctx   = context.Background()
+		key   = "test-refresh-ahead-legacy-key"
+		value = []byte("test legacy value")
+	)
+	requireNil(t, cache.Save(ctx, key, value, time.Minute))
+
+	// act
+	result, err := subject.Load(ctx, key)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+}
+
+func testRefreshAheadPolicySoftTTL(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = xcache.NewMemory(freecacheMinMem)
+		pool      = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		refreshed = make(chan string, 2)
+		subject   = xcache.NewRefreshAhead(cache, time.Second, pool,
+			func(_ context.Context, key string) ([]byte, time.Duration, error) {
+				refreshed <- key
+
+				return []byte("test refreshed value"), time.Hour, nil
+			},
+		)
+		ctx       = context.Background()
+		policyKey = "product:test-refresh-ahead-policy-key"
+		otherKey  = "test-refresh-ahead-no-policy-key"
+		value     = []byte("test value")
+	)
+	subject.SetPolicies(
+		xcache.RefreshPolicy{Prefix: "prod", SoftTTLFraction: 0.99},
+		xcache.RefreshPolicy{Prefix: "product:", SoftTTLFraction: 0.01, Jitter: 1}, // longest prefix applies.
+	)
+	requireNil(t, subject.Save(ctx, policyKey, value, 10*time.Second))
+	requireNil(t, subject.Save(ctx, otherKey, value, 10*time.Second))
+	time.Sleep(300 * time.Millisecond) // policy key's refresh window ([9.8s, 10s]) is reached.
+
+	// act
+	result, err := subject.Load(ctx, policyKey)
+	resultOther, errOther := subject.Load(ctx, otherKey)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertNil(t, errOther)
+	assertEqual(t, value, resultOther)
+	close(refreshed)
+	var refreshedKeys []string
+	for key := range refreshed {
+		refreshedKeys = append(refreshedKeys, key)
+	}
+	assertEqual(t, []string{policyKey}, refreshedKeys)
+	assertEqual(t, "product:", subject.Policies()[0].Prefix)
+}
+
+func testRefreshAheadPolicyMaxConcurrent(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = xcache.NewMemory(freecacheMinMem)
+		pool      = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 2})
+		release   = make(chan struct{})
+		refreshes int32
+		subject   = xcache.NewRefreshAhead(cache, time.Hour, pool,
+			func(context.Context, string) ([]byte, time.Duration, error) {
+				atomic.AddInt32(&refreshes, 1)
+				<-release
+
+				return []byte("test refreshed value"), time.Hour, nil
+			},
+		)
+		ctx   = context.Background()
+		key1  = "user:test-refresh-ahead-concurrent-key-1"
+		key2  = "user:test-refresh-ahead-concurrent-key-2"
+		value = []byte("test value")
+	)
+	subject.SetPolicies(xcache.RefreshPolicy{Prefix: "user:", MaxConcurrent: 1})
+	requireNil(t, subject.Save(ctx, key1, value, time.Minute))
+	requireNil(t, subject.Save(ctx, key2, value, time.Minute))
+
+	// act
+	_, err1 := subject.Load(ctx, key1)
+	_, err2 := subject.Load(ctx, key2) // skipped, as key1 is being refreshed.
+	close(release)
+	requireNil(t, pool.Close())
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, int32(1), atomic.LoadInt32(&refreshes))
+}
@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xconf"
+)
+
+// RefreshAheadCfgKeyPolicies is the key under which xconf.Config expects the list of refresh policies documents,
+// see NewRefreshAheadWithConfig.
+const RefreshAheadCfgKeyPolicies = "xcache.refreshahead.policies"
+
+// Keys of a refresh policy document.
+const (
+	refreshPolicyKeyPrefix        = "prefix"
+	refreshPolicyKeySoftTTL       = "softttl"
+	refreshPolicyKeyJitter        = "jitter"
+	refreshPolicyKeyMaxConcurrent = "maxconcurrent"
+
+	// refreshPolicyCfgKeyPrefix is the prefix under which the current policy document's keys are read.
+	refreshPolicyCfgKeyPrefix = "refreshpolicy."
+)
+
+// refreshPolicyKeys holds the keys a refresh policy document can have.
+var refreshPolicyKeys = map[string]struct{}{
+	refreshPolicyKeyPrefix:        {},
+	refreshPolicyKeySoftTTL:       {},
+	refreshPolicyKeyJitter:        {},
+	refreshPolicyKeyMaxConcurrent: {},
+}
+
+// NewRefreshAheadWithConfig initializes a RefreshAhead (see NewRefreshAhead), having its refresh policies
+// taken from a xconf.Config, so that refresh behavior can be tuned per data class from central configuration.
+//
+// The key under which the policies are expected is "xcache.refreshahead.policies" (see RefreshAheadCfgKeyPolicies).
+// Each policy document has the keys (see RefreshPolicy):
+//   - "prefix": the prefix of the keys the policy applies to;
+//   - "softttl": the fraction of a key's expiration period after which the key is refreshed;
+//   - "jitter": the percent the refresh window is randomly enlarged / shrunk with;
+//   - "maxconcurrent": the max. no. of concurrent refreshes of the policy's keys.
+//
+// Example (YAML):
+//
+//	xcache:
+//	  refreshahead:
+//	    policies:
+//	      - prefix: "product:"
+//	        softttl: 0.8
+//	        jitter: 10
+//	        maxconcurrent: 4
+//	      - prefix: "price:"
+//	        softttl: 0.5
+//
+// Invalid values are replaced with defaults, use NewRefreshAheadWithValidConfig to fail on them instead.
+//
+// An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
+// In case the policies are changed, they are applied right away, without the need of
+// restarting your application. An invalid configuration reload is disregarded.
+func NewRefreshAheadWithConfig(
+	cache Cache,
+	threshold time.Duration,
+	pool *WorkerPool,
+	refresh RefreshFunc,
+	config xconf.Config,
+) *RefreshAhead {
+	refreshAhead := NewRefreshAhead(cache, threshold, pool, refresh)
+	policies, _ := getRefreshPolicies(config)
+	refreshAhead.SetPolicies(policies...)
+
+	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
+		defConfig.RegisterObserver(refreshAhead.onConfigChange)
+	}
+
+	return refreshAhead
+}
+
+// NewRefreshAheadWithValidConfig is like NewRefreshAheadWithConfig, but it fails on invalid policies:
+// an error aggregating a ConfigError for each invalid value is returned, and refresh ahead is nil.
+func NewRefreshAheadWithValidConfig(
+	cache Cache,
+	threshold time.Duration,
+	pool *WorkerPool,
+	refresh RefreshFunc,
+	config xconf.Config,
+) (*RefreshAhead, error) {
+	if err := ValidateRefreshAheadXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewRefreshAheadWithConfig(cache, threshold, pool, refresh, config), nil
+}
+
+// getRefreshPolicies returns the refresh policies taken from a xconf.Config.
+// Invalid values are replaced with defaults, and an error aggregating ConfigError(s) is returned.
+func getRefreshPolicies(config xconf.Config) ([]RefreshPolicy, error) {
+	view := &topologyView{root: config}
+	r := &xconfReader{config: view, keyName: view.keyName}
+	value := getDocument(config, RefreshAheadCfgKeyPolicies)
+	if value == nil {
+		return nil, nil
+	}
+	docs, ok := value.([]any)
+	if !ok {
+		r.addValueErr(RefreshAheadCfgKeyPolicies, value, errConfigValueType)
+
+		return nil, r.Err()
+	}
+
+	policies := make([]RefreshPolicy, 0, len(docs))
+	for idx, item := range docs {
+		path := RefreshAheadCfgKeyPolicies + "." + strconv.Itoa(idx)
+		doc, ok := item.(map[string]any)
+		if !ok {
+			r.addValueErr(path, item, errConfigValueType)
+
+			continue
+		}
+		for _, key := range sortedKeys(doc) {
+			if _, known := refreshPolicyKeys[key]; !known {
+				r.addErr(path+"."+key, errTopologyKeyUnknown)
+			}
+		}
+		view.setDocument(refreshPolicyCfgKeyPrefix, path, doc)
+		policy := RefreshPolicy{
+			Prefix:          r.String(refreshPolicyCfgKeyPrefix+refreshPolicyKeyPrefix, ""),
+			SoftTTLFraction: r.Float64(refreshPolicyCfgKeyPrefix+refreshPolicyKeySoftTTL, 0),
+			Jitter:          r.Float64(refreshPolicyCfgKeyPrefix+refreshPolicyKeyJitter, 0),
+			MaxConcurrent:   r.Int(refreshPolicyCfgKeyPrefix+refreshPolicyKeyMaxConcurrent, 0),
+		}
+		if policy.SoftTTLFraction < 0 || policy.SoftTTLFraction >= 1 {
+			r.addErr(refreshPolicyCfgKeyPrefix+refreshPolicyKeySoftTTL, errConfigValueRange)
+			policy.SoftTTLFraction = 0
+		}
+		if policy.Jitter < 0 || policy.Jitter >= 100 {
+			r.addErr(refreshPolicyCfgKeyPrefix+refreshPolicyKeyJitter, errConfigValueRange)
+			policy.Jitter = 0
+		}
+		if policy.MaxConcurrent < 0 {
+			r.addErr(refreshPolicyCfgKeyPrefix+refreshPolicyKeyMaxConcurrent, errConfigValueRange)
+			policy.MaxConcurrent = 0
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, r.Err()
+}
+
+// ValidateRefreshAheadXConfig checks the refresh policies taken from a xconf.Config.
+// It returns an error aggregating a ConfigError (having the document path of the invalid value as Key,
+// like "xcache.refreshahead.policies.1.softttl") for each value which cannot be interpreted,
+// or nil if configuration is valid.
+func ValidateRefreshAheadXConfig(config xconf.Config) error {
+	_, err := getRefreshPolicies(config)
+
+	return err
+}
+
+// onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
+// In case the refresh policies are changed, they are applied, if they are valid.
+// This callback is automatically registered on instantiation of a RefreshAhead object with
+// NewRefreshAheadWithConfig.
+func (cache *RefreshAhead) onConfigChange(config xconf.Config, changedKeys ...string) {
+	configHasChanged := false
+	for _, changedKey := range changedKeys {
+		if strings.HasPrefix(changedKey, RefreshAheadCfgKeyPolicies) ||
+			strings.HasPrefix(RefreshAheadCfgKeyPolicies, changedKey+".") { // a parent document changed.
+			configHasChanged = true
+
+			break
+		}
+	}
+	if !configHasChanged {
+		return
+	}
+
+	policies, err := getRefreshPolicies(config)
+	if err != nil { // keep current policies.
+		return
+	}
+	cache.SetPolicies(policies...)
+}
@@ -0,0 +1,152 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestRefreshAhead_withXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig  uint32
+		initialConfig = map[string]any{
+			"xcache": map[string]any{
+				"refreshahead": map[string]any{
+					"policies": []any{
+						map[string]any{"prefix": "price:", "softttl": 0.5},
+						map[string]any{"prefix": "product:", "softttl": "0.8", "jitter": 10, "maxconcurrent": 4},
+					},
+				},
+			},
+		}
+		configReloaded = map[string]any{
+			xcache.RefreshAheadCfgKeyPolicies: []any{
+				map[string]any{"prefix": "price:", "softttl": 0.9},
+			},
+		}
+		configReloadedInvalid = map[string]any{
+			xcache.RefreshAheadCfgKeyPolicies: []any{
+				map[string]any{"prefix": "price:", "softttl": 1.5},
+			},
+		}
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			switch atomic.LoadUint32(&reloadConfig) {
+			case 1:
+				return configReloaded, nil
+			case 2:
+				return configReloadedInvalid, nil
+			}
+
+			return initialConfig, nil
+		})
+		pool    = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject = xcache.NewRefreshAheadWithConfig(xcache.Nop{}, time.Minute, pool,
+			func(context.Context, string) ([]byte, time.Duration, error) {
+				return nil, 0, nil
+			},
+			config,
+		)
+	)
+	defer pool.Close()
+
+	// act & assert initial config
+	assertEqual(
+		t,
+		[]xcache.RefreshPolicy{
+			{Prefix: "product:", SoftTTLFraction: 0.8, Jitter: 10, MaxConcurrent: 4},
+			{Prefix: "price:", SoftTTLFraction: 0.5},
+		},
+		subject.Policies(),
+	)
+
+	// act & assert reloaded config
+	atomic.StoreUint32(&reloadConfig, 1)
+	waitReload()
+	expectedPolicies := []xcache.RefreshPolicy{{Prefix: "price:", SoftTTLFraction: 0.9}}
+	assertEqual(t, expectedPolicies, subject.Policies())
+
+	// act & assert invalid reloaded config is disregarded
+	atomic.StoreUint32(&reloadConfig, 2)
+	waitReload()
+	assertEqual(t, expectedPolicies, subject.Policies())
+}
+
+func TestValidateRefreshAheadXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing values",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name: "valid values",
+			config: xconf.NewMockConfig(
+				xcache.RefreshAheadCfgKeyPolicies, []any{
+					map[string]any{"prefix": "product:", "softttl": 0.8, "jitter": "5", "maxconcurrent": 2},
+				},
+			),
+		},
+		{
+			name: "invalid policies type",
+			config: xconf.NewMockConfig(
+				xcache.RefreshAheadCfgKeyPolicies, "product:",
+			),
+			expectedErrKeys: []string{xcache.RefreshAheadCfgKeyPolicies},
+		},
+		{
+			name: "invalid values",
+			config: xconf.NewMockConfig(
+				xcache.RefreshAheadCfgKeyPolicies, []any{
+					"product:",
+					map[string]any{"prefix": "price:", "softttl": 1, "jitter": -1, "maxconcurrent": -1, "ttl": "1h"},
+				},
+			),
+			expectedErrKeys: []string{
+				xcache.RefreshAheadCfgKeyPolicies + ".0",
+				xcache.RefreshAheadCfgKeyPolicies + ".1.ttl",
+				xcache.RefreshAheadCfgKeyPolicies + ".1.softttl",
+				xcache.RefreshAheadCfgKeyPolicies + ".1.jitter",
+				xcache.RefreshAheadCfgKeyPolicies + ".1.maxconcurrent",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateRefreshAheadXConfig(test.config)
+			refreshAhead, resultCtorErr := xcache.NewRefreshAheadWithValidConfig(
+				xcache.NewMemory(0),
+				time.Second,
+				nil,
+				func(context.Context, string) ([]byte, time.Duration, error) { return nil, 0, nil },
+				test.config,
+			)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, refreshAhead != nil)
+		})
+	}
+}
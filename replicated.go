@@ -0,0 +1,277 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoReplicas is returned by NewReplicated if no cache is given.
+var ErrNoReplicas = errors.New("no replicas")
+
+// ReplicaReadStrategy tells which replica of a Replicated cache is read first.
+type ReplicaReadStrategy int
+
+// Replicas' read strategies.
+const (
+	// ReadPreferred reads the preferred replica first (see ReplicatedConfig.Preferred),
+	// and falls back to the others, in order.
+	ReadPreferred ReplicaReadStrategy = iota
+	// ReadRandom reads a random replica first (spreading the load among replicas),
+	// and falls back to the others, in order.
+	ReadRandom
+)
+
+// ReplicatedConfig holds the settings of a Replicated cache.
+type ReplicatedConfig struct {
+	// Read is the replicas' read strategy. By default, it's ReadPreferred.
+	Read ReplicaReadStrategy
+	// Preferred is the index of the replica read first with ReadPreferred strategy
+	// (like the one of the local datacenter). By default, it's the first one.
+	Preferred int
+	// FallbackOnMiss tells whether a key not found in a replica is looked up in the other replicas
+	// (a write may have failed in a replica, for example). By default, only on errors are other replicas read.
+	FallbackOnMiss bool
+}
+
+// Replicated is a composite Cache holding the same data in multiple caches (replicas) of the same tier,
+// like the caches of two datacenters (app level replication). Unlike Multi, which has L1 / L2 layers semantics,
+// writes go to all replicas, in parallel, and reads go to a single replica (preferred, or random one),
+// falling back to the others if it fails (or the key is not found, if configured so), see ReplicatedConfig.
+//
+// Example:
+//
+//	cache, err := xcache.NewReplicated(xcache.ReplicatedConfig{Preferred: 0}, localDCRedis, remoteDCRedis)
+type Replicated struct {
+	replicas  []Cache
+	config    ReplicatedConfig
+	fallbacks int64
+}
+
+// NewReplicated instantiates a new Replicated cache holding the data in given caches (replicas),
+// according to given settings. It returns ErrNoReplicas if no cache is given.
+func NewReplicated(config ReplicatedConfig, replicas ...Cache) (*Replicated, error) {
+	if len(replicas) == 0 {
+		return nil, ErrNoReplicas
+	}
+	if config.Preferred < 0 || config.Preferred >= len(replicas) {
+		config.Preferred = 0
+	}
+
+	return &Replicated{
+		replicas: replicas,
+		config:   config,
+	}, nil
+}
+
+// Save stores the given key-value with expiration period into all replicas, in parallel.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved (in any of the
+// replicas - note, that the key can end up being saved in other replica(s)).
+func (cache *Replicated) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.write(func(replica Cache) error {
+		return replica.Save(ctx, key, value, expire)
+	})
+}
+
+// Load returns a key's value from a replica (chosen according to the read strategy),
+// falling back to the other replicas if it fails (or the key is not found, if configured so).
+// Note: if a replica returns an error, but the next replica returns the value,
+// the value and nil error will be returned (method aims to be successful).
+// If no replica has the key, and some of them failed, their errors are returned, otherwise ErrNotFound.
+func (cache *Replicated) Load(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := cache.read(func(replica Cache) error {
+		var err error
+		value, err = replica.Load(ctx, key)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// TTL returns a key's remaining time to live from a replica (chosen according to the read strategy),
+// falling back to the other replicas if it fails (or the key is not found, if configured so).
+func (cache *Replicated) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var ttl time.Duration
+	err := cache.read(func(replica Cache) error {
+		var err error
+		ttl, err = replica.TTL(ctx, key)
+		if err == nil && ttl < 0 {
+			return ErrNotFound
+		}
+
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	}
+
+	return ttl, err
+}
+
+// Stats returns the statistics of all replicas, summed up (note that keys and memory are counted
+// for each replica), or an error if something bad happens within any of the replicas.
+func (cache *Replicated) Stats(ctx context.Context) (Stats, error) {
+	var (
+		mErr   multiErrors
+		rStats Stats
+	)
+	for _, replica := range cache.replicas {
+		if stats, err := replica.Stats(ctx); err != nil {
+			mErr.add(err)
+		} else {
+			rStats.add(stats)
+		}
+	}
+
+	if err := mErr.errOrNil(); err != nil {
+		return Stats{}, err
+	}
+
+	return rStats, nil
+}
+
+// Delete deletes the given key from all replicas, in parallel.
+func (cache *Replicated) Delete(ctx context.Context, key string) error {
+	return cache.write(func(replica Cache) error {
+		return deleteKey(ctx, replica, key)
+	})
+}
+
+// DeleteMany deletes the given keys from all replicas, in parallel.
+func (cache *Replicated) DeleteMany(ctx context.Context, keys ...string) error {
+	return cache.write(func(replica Cache) error {
+		return deleteKeys(ctx, replica, keys...)
+	})
+}
+
+// Has checks if the given key exists in a replica (chosen according to the read strategy),
+// falling back to the other replicas if it fails (or the key is not found, if configured so).
+func (cache *Replicated) Has(ctx context.Context, key string) (bool, error) {
+	err := cache.read(func(replica Cache) error {
+		found, err := hasKey(ctx, replica, key)
+		if err == nil && !found {
+			return ErrNotFound
+		}
+
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// SaveMany stores the given items into all replicas, in parallel.
+func (cache *Replicated) SaveMany(ctx context.Context, items map[string]Item) error {
+	return cache.write(func(replica Cache) error {
+		return saveMany(ctx, replica, items)
+	})
+}
+
+// LoadMany returns the values of given keys from a replica (chosen according to the read strategy),
+// falling back to the other replicas if it fails (keys not found are missing from the returned map).
+func (cache *Replicated) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	var values map[string][]byte
+	err := cache.read(func(replica Cache) error {
+		var err error
+		values, err = loadMany(ctx, replica, keys)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// ContributeStats reports the no. of reads served by a replica other than the one read first,
+// as "replicated.fallbacks" metric.
+func (cache *Replicated) ContributeStats(add func(name string, value float64)) {
+	add("replicated.fallbacks", float64(atomic.LoadInt64(&cache.fallbacks)))
+}
+
+// write executes given write operation on all replicas, in parallel.
+func (cache *Replicated) write(op func(replica Cache) error) error {
+	if len(cache.replicas) == 1 {
+		return op(cache.replicas[0])
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(cache.replicas))
+	)
+	wg.Add(len(cache.replicas))
+	for idx, replica := range cache.replicas {
+		go func(idx int, replica Cache) {
+			defer wg.Done()
+			errs[idx] = op(replica)
+		}(idx, replica)
+	}
+	wg.Wait()
+
+	var mErr multiErrors
+	for _, err := range errs {
+		if err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return mErr.errOrNil()
+}
+
+// read executes given read operation on replicas, in the order given by the read strategy,
+// until one succeeds (or returns ErrNotFound, if FallbackOnMiss is not set).
+// The operation keeps the result of the last read replica, which is returned to the caller in case of success.
+// Otherwise, replicas' errors are returned (like Multi.Load does), or ErrNotFound, if no replica failed.
+func (cache *Replicated) read(op func(replica Cache) error) error {
+	var mErr multiErrors
+	start := cache.config.Preferred
+	if cache.config.Read == ReadRandom && len(cache.replicas) > 1 {
+		start = rand.Intn(len(cache.replicas))
+	}
+	for i := 0; i < len(cache.replicas); i++ {
+		err := op(cache.replicas[(start+i)%len(cache.replicas)])
+		if err == nil {
+			if i > 0 {
+				atomic.AddInt64(&cache.fallbacks, 1)
+			}
+
+			return nil
+		}
+		if errors.Is(err, ErrNotFound) {
+			if !cache.config.FallbackOnMiss {
+				return err
+			}
+
+			continue
+		}
+		mErr.add(err)
+	}
+
+	if err := mErr.errOrNil(); err != nil {
+		return err
+	}
+
+	return ErrNotFound
+}
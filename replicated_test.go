@@ -0,0 +1,244 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Replicated)(nil)            // test Replicated is a Cache
+	var _ xcache.Deleter = (*xcache.Replicated)(nil)          // test Replicated is a Deleter
+	var _ xcache.BulkDeleter = (*xcache.Replicated)(nil)      // test Replicated is a BulkDeleter
+	var _ xcache.ExistenceChecker = (*xcache.Replicated)(nil) // test Replicated is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Replicated)(nil)          // test Replicated is a Batcher
+	var _ xcache.StatsContributor = (*xcache.Replicated)(nil) // test Replicated is a StatsContributor
+}
+
+func TestReplicated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes go to all replicas", testReplicatedWritesGoToAllReplicas)
+	t.Run("reads go to preferred replica", testReplicatedReadsGoToPreferredReplica)
+	t.Run("reads fall back on error", testReplicatedReadsFallBackOnError)
+	t.Run("reads fall back on miss", testReplicatedReadsFallBackOnMiss)
+	t.Run("errors are kept on miss", testReplicatedErrorsAreKeptOnMiss)
+	t.Run("random reads are spread", testReplicatedRandomReadsAreSpread)
+	t.Run("no replicas", testReplicatedNoReplicas)
+}
+
+func testReplicatedWritesGoToAllReplicas(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = xcache.NewMemory(freecacheMinMem)
+		replica2   = new(xcache.Mock)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{}, replica1, replica2)
+		ctx        = context.Background()
+		key        = "test-replicated-key"
+		value      = []byte("test value")
+		errSave    = errors.New("connection refused")
+	)
+	replica2.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return errSave
+	})
+
+	// act
+	err := subject.Save(ctx, key, value, time.Minute)
+
+	// assert
+	assertTrue(t, errors.Is(err, errSave))
+	loadedValue, err := replica1.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	assertEqual(t, 1, replica2.SaveCallsCount())
+	requireNil(t, subject.SaveMany(ctx, map[string]xcache.Item{"k1": {Value: value}, "k2": {Value: value}}))
+	requireNil(t, subject.DeleteMany(ctx, "k1", "k2"))
+	requireNil(t, subject.Delete(ctx, key))
+	assertEqual(t, 1, replica2.SaveManyCallsCount())
+	assertEqual(t, 1, replica2.DeleteManyCallsCount())
+	assertEqual(t, 1, replica2.DeleteCallsCount())
+}
+
+func testReplicatedReadsGoToPreferredReplica(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = new(xcache.Mock)
+		replica2   = new(xcache.Mock)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{Preferred: 1}, replica1, replica2)
+		ctx        = context.Background()
+		key        = "test-replicated-preferred-key"
+	)
+
+	// act
+	_, err := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+	found, errHas := subject.Has(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+	assertNil(t, errHas)
+	assertTrue(t, !found)
+	assertEqual(t, 0, replica1.LoadCallsCount())
+	assertEqual(t, 0, replica1.TTLCallsCount())
+	assertEqual(t, 1, replica2.LoadCallsCount())
+	assertEqual(t, 1, replica2.TTLCallsCount())
+	assertEqual(t, 0, replica1.HasCallsCount())
+	assertEqual(t, 1, replica2.HasCallsCount())
+}
+
+func testReplicatedReadsFallBackOnError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = new(xcache.Mock)
+		replica2   = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{}, replica1, replica2)
+		ctx        = context.Background()
+		key        = "test-replicated-fallback-key"
+		value      = []byte("test value")
+		errLoad    = errors.New("connection refused")
+	)
+	replica1.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errLoad
+	})
+	requireNil(t, replica2.Save(ctx, key, value, time.Minute))
+
+	// act
+	loadedValue, err := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"replicated.fallbacks": 1}, metrics)
+
+	// act - all replicas fail
+	onlyFailing, _ := xcache.NewReplicated(xcache.ReplicatedConfig{}, replica1, replica1)
+	_, err = onlyFailing.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, errLoad))
+}
+
+func testReplicatedReadsFallBackOnMiss(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = xcache.NewMemory(freecacheMinMem)
+		replica2   = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{FallbackOnMiss: true}, replica1, replica2)
+		ctx        = context.Background()
+		key        = "test-replicated-miss-key"
+		value      = []byte("test value")
+	)
+	requireNil(t, replica2.Save(ctx, key, value, time.Minute))
+
+	// act
+	loadedValue, err := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+	values, errMany := subject.LoadMany(ctx, []string{key})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	assertNil(t, errTTL)
+	assertTrue(t, ttl > 0)
+	assertNil(t, errMany)
+	assertEqual(t, 0, len(values)) // LoadMany falls back only on errors.
+	noFallback, _ := xcache.NewReplicated(xcache.ReplicatedConfig{}, replica1, replica2)
+	_, err = noFallback.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testReplicatedErrorsAreKeptOnMiss(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = new(xcache.Mock)
+		replica2   = xcache.NewMemory(freecacheMinMem)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{FallbackOnMiss: true}, replica1, replica2)
+		ctx        = context.Background()
+		key        = "test-replicated-errors-key"
+		errReplica = errors.New("intentionally triggered replica error")
+	)
+	replica1.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errReplica
+	})
+	replica1.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return 0, errReplica
+	})
+
+	// act
+	value, err := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, errReplica))
+	assertTrue(t, !errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, value)
+	assertTrue(t, errors.Is(errTTL, errReplica))
+
+	// act - no replica fails
+	healthy, _ := xcache.NewReplicated(xcache.ReplicatedConfig{FallbackOnMiss: true}, replica2, replica2)
+	_, err = healthy.Load(ctx, key)
+	ttl, errTTL := healthy.TTL(ctx, key)
+	found, errHas := healthy.Has(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+	assertNil(t, errHas)
+	assertTrue(t, !found)
+}
+
+func testReplicatedRandomReadsAreSpread(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		replica1   = new(xcache.Mock)
+		replica2   = new(xcache.Mock)
+		subject, _ = xcache.NewReplicated(xcache.ReplicatedConfig{Read: xcache.ReadRandom}, replica1, replica2)
+		ctx        = context.Background()
+	)
+
+	// act
+	for i := 0; i < 200; i++ {
+		_, _ = subject.Load(ctx, "test-replicated-random-key")
+	}
+
+	// assert
+	assertEqual(t, 200, replica1.LoadCallsCount()+replica2.LoadCallsCount())
+	assertTrue(t, replica1.LoadCallsCount() > 50)
+	assertTrue(t, replica2.LoadCallsCount() > 50)
+}
+
+func testReplicatedNoReplicas(t *testing.T) {
+	t.Parallel()
+
+	// act
+	subject, err := xcache.NewReplicated(xcache.ReplicatedConfig{})
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNoReplicas))
+	assertTrue(t, subject == nil)
+}
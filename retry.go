@@ -0,0 +1,210 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// Retry defaults.
+const (
+	retryDefaultMaxAttempts    = 3
+	retryDefaultInitialBackoff = 10 * time.Millisecond
+	retryDefaultMaxBackoff     = time.Second
+	retryDefaultMultiplier     = 2
+	retryDefaultJitter         = 0.5
+)
+
+// RetryConfig holds the settings of a Retry.
+type RetryConfig struct {
+	// MaxAttempts is the max. no. of times an operation is tried (including the first one). By default (0), it's 3.
+	MaxAttempts int
+	// MaxElapsed is the max. period an operation (all its attempts and the waits between them) can take;
+	// a retry which would start beyond it is not made. By default (0), there is no limit other than MaxAttempts.
+	MaxElapsed time.Duration
+	// InitialBackoff is the period waited before the first retry. By default (0), it's 10ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the period waited before a retry. By default (0), it's 1s.
+	MaxBackoff time.Duration
+	// Multiplier (> 1) multiplies the period waited before each retry. By default (0), it's 2.
+	Multiplier float64
+	// Jitter is the fraction, within (0, 1], the period waited before a retry is randomly shrunk with,
+	// so that clients do not retry at the same moment. By default (0), it's 0.5; a negative value disables it.
+	Jitter float64
+	// IsRetryable tells whether an operation's error is transient, and the operation should be retried.
+	// By default (nil), timeouts and unavailability errors are retried (see ClassifyError),
+	// except ErrCircuitOpen.
+	IsRetryable func(err error) bool
+}
+
+// Retry is a Cache decorator which retries the failed operations (Save, Load, TTL) on transient errors,
+// with exponential backoff and jitter, within a max. no. of attempts and a max. elapsed period.
+// Waiting for a retry stops if the operation's context gets done, case when the last error is returned.
+// Stats is not retried.
+//
+// When combined with a CircuitBreaker, decorate the CircuitBreaker with the Retry, so retries are accounted
+// by the breaker, and are not made while the breaker is open:
+//
+//	cache := xcache.NewRetry(xcache.NewCircuitBreaker(redisCache, cbConfig), xcache.RetryConfig{})
+type Retry struct {
+	cache     Cache
+	config    RetryConfig
+	retries   int64
+	exhausted int64
+}
+
+// NewRetry instantiates a new Retry which decorates given cache, according to given settings.
+func NewRetry(cache Cache, config RetryConfig) *Retry {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = retryDefaultMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = retryDefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = retryDefaultMaxBackoff
+	}
+	if config.Multiplier <= 1 {
+		config.Multiplier = retryDefaultMultiplier
+	}
+	if config.Jitter == 0 {
+		config.Jitter = retryDefaultJitter
+	} else if config.Jitter < 0 {
+		config.Jitter = 0
+	} else if config.Jitter > 1 {
+		config.Jitter = 1
+	}
+	if config.IsRetryable == nil {
+		config.IsRetryable = isRetryable
+	}
+
+	return &Retry{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache, retrying on transient errors.
+func (cache *Retry) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.do(ctx, func() error {
+		return cache.cache.Save(ctx, key, value, expire)
+	})
+}
+
+// Load returns a key's value from decorated cache, retrying on transient errors.
+func (cache *Retry) Load(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := cache.do(ctx, func() error {
+		var err error
+		value, err = cache.cache.Load(ctx, key)
+
+		return err
+	})
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, retrying on transient errors.
+func (cache *Retry) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var ttl time.Duration
+	err := cache.do(ctx, func() error {
+		var err error
+		ttl, err = cache.cache.TTL(ctx, key)
+
+		return err
+	})
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *Retry) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Retry) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of retries, as "retry.retries" metric,
+// and the no. of operations which still failed with a retryable error after their last attempt,
+// as "retry.exhausted" metric.
+func (cache *Retry) ContributeStats(add func(name string, value float64)) {
+	add("retry.retries", float64(atomic.LoadInt64(&cache.retries)))
+	add("retry.exhausted", float64(atomic.LoadInt64(&cache.exhausted)))
+}
+
+// do executes given operation, retrying it according to the settings.
+func (cache *Retry) do(ctx context.Context, op func() error) error {
+	var (
+		start = time.Now()
+		timer *time.Timer
+	)
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !cache.config.IsRetryable(err) {
+			return err
+		}
+		if attempt >= cache.config.MaxAttempts {
+			atomic.AddInt64(&cache.exhausted, 1)
+
+			return err
+		}
+		wait := cache.backoff(attempt)
+		if cache.config.MaxElapsed > 0 && time.Since(start)+wait >= cache.config.MaxElapsed {
+			atomic.AddInt64(&cache.exhausted, 1)
+
+			return err
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(wait)
+			defer timer.Stop()
+		} else {
+			timer.Reset(wait)
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return err
+		}
+		atomic.AddInt64(&cache.retries, 1)
+	}
+}
+
+// backoff returns the period to wait before the retry following given attempt.
+func (cache *Retry) backoff(attempt int) time.Duration {
+	backoff := float64(cache.config.InitialBackoff) * math.Pow(cache.config.Multiplier, float64(attempt-1))
+	if backoff > float64(cache.config.MaxBackoff) { // compared as float, it can overflow a Duration.
+		backoff = float64(cache.config.MaxBackoff)
+	}
+	if cache.config.Jitter > 0 {
+		backoff -= backoff * cache.config.Jitter * rand.Float64()
+	}
+
+	return time.Duration(backoff)
+}
+
+// isRetryable is the default RetryConfig.IsRetryable.
+func isRetryable(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	class := ClassifyError(err)
+
+	return class == ErrorClassTimeout || class == ErrorClassUnavailable
+}
@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Retry)(nil)            // test Retry is a Cache
+	var _ xcache.StatsContributor = (*xcache.Retry)(nil) // test Retry is a StatsContributor
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("transient error is retried", testRetryTransientErrorIsRetried)
+	t.Run("attempts are exhausted", testRetryAttemptsAreExhausted)
+	t.Run("non retryable error is not retried", testRetryNonRetryableErrorIsNotRetried)
+	t.Run("max elapsed is honored", testRetryMaxElapsedIsHonored)
+	t.Run("context cancellation is honored", testRetryContextCancellationIsHonored)
+	t.Run("custom classifier", testRetryCustomClassifier)
+}
+
+func testRetryTransientErrorIsRetried(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRetry(cache, xcache.RetryConfig{InitialBackoff: time.Millisecond})
+		ctx     = context.Background()
+		key     = "test-retry-key"
+		value   = []byte("test value")
+		loads   int32
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		if atomic.AddInt32(&loads, 1) < 3 {
+			return nil, io.EOF
+		}
+
+		return value, nil
+	})
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		if cache.TTLCallsCount() < 2 {
+			return -1, context.DeadlineExceeded
+		}
+
+		return time.Minute, nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertEqual(t, 3, cache.LoadCallsCount())
+	assertNil(t, errTTL)
+	assertEqual(t, time.Minute, ttl)
+	assertEqual(t, 2, cache.TTLCallsCount())
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"retry.retries": 3, "retry.exhausted": 0}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testRetryAttemptsAreExhausted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRetry(cache, xcache.RetryConfig{
+			MaxAttempts:    4,
+			InitialBackoff: time.Millisecond,
+			Jitter:         -1,
+		})
+		ctx = context.Background()
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return xcache.ErrOverloaded
+	})
+
+	// act
+	start := time.Now()
+	err := subject.Save(ctx, "test-retry-exhausted-key", []byte("test value"), time.Minute)
+	elapsed := time.Since(start)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrOverloaded))
+	assertEqual(t, 4, cache.SaveCallsCount())
+	assertTrue(t, elapsed >= 7*time.Millisecond) // 1ms + 2ms + 4ms backoffs.
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"retry.retries": 3, "retry.exhausted": 1}, metrics)
+}
+
+func testRetryNonRetryableErrorIsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRetry(cache, xcache.RetryConfig{InitialBackoff: time.Millisecond})
+		ctx     = context.Background()
+		errs    = []error{
+			xcache.ErrNotFound,
+			xcache.ErrCircuitOpen,
+			errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"),
+		}
+	)
+
+	for _, expectedErr := range errs {
+		expectedErr := expectedErr // capture range variable
+		cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+			return nil, expectedErr
+		})
+
+		// act
+		_, err := subject.Load(ctx, "test-retry-non-retryable-key")
+
+		// assert
+		assertTrue(t, errors.Is(err, expectedErr))
+	}
+	assertEqual(t, len(errs), cache.LoadCallsCount())
+}
+
+func testRetryMaxElapsedIsHonored(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewRetry(cache, xcache.RetryConfig{
+			MaxAttempts:    10,
+			MaxElapsed:     50 * time.Millisecond,
+			InitialBackoff: 20 * time.Millisecond,
+			Jitter:         -1,
+		})
+		ctx = context.Background()
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, io.ErrUnexpectedEOF
+	})
+
+	// act
+	_, err := subject.Load(ctx, "test-retry-elapsed-key")
+
+	// assert
+	assertTrue(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assertEqual(t, 2, cache.LoadCallsCount()) // the 2nd retry would start after 20ms + 40ms.
+}
+
+func testRetryContextCancellationIsHonored(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewRetry(cache, xcache.RetryConfig{InitialBackoff: time.Minute})
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	)
+	defer cancel()
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, io.EOF
+	})
+
+	// act
+	start := time.Now()
+	_, err := subject.Load(ctx, "test-retry-ctx-key")
+	elapsed := time.Since(start)
+
+	// assert
+	assertTrue(t, errors.Is(err, io.EOF))
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertTrue(t, elapsed < time.Second)
+}
+
+func testRetryCustomClassifier(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = new(xcache.Mock)
+		errLoading = errors.New("LOADING Redis is loading the dataset in memory")
+		subject    = xcache.NewRetry(cache, xcache.RetryConfig{
+			InitialBackoff: time.Millisecond,
+			IsRetryable: func(err error) bool {
+				return errors.Is(err, errLoading)
+			},
+		})
+		ctx = context.Background()
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errLoading
+	})
+
+	// act
+	_, err := subject.Load(ctx, "test-retry-classifier-key")
+
+	// assert
+	assertTrue(t, errors.Is(err, errLoading))
+	assertEqual(t, 3, cache.LoadCallsCount())
+}
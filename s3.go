@@ -0,0 +1,223 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// S3ExpiresAtMetadata is the (user defined) object metadata key the expiration moment is stored under,
+// in unix milliseconds. An object without it does not expire.
+const S3ExpiresAtMetadata = "xcache-expires-at"
+
+// S3Object is an object, as stored into an S3 bucket.
+type S3Object struct {
+	// Body is the object's content (the value).
+	Body []byte
+	// Metadata is the object's user defined metadata (x-amz-meta-*).
+	Metadata map[string]string
+}
+
+// S3Client is the subset of S3 (compatible object storage) operations an S3 cache is built upon.
+//
+// An aws-sdk-go-v2 based implementation is along these lines:
+//
+//	type s3Client struct{ s3 *s3.Client }
+//
+//	func (c s3Client) GetObject(ctx context.Context, bucket, key string) (xcache.S3Object, error) {
+//		out, err := c.s3.GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+//		var noSuchKey *types.NoSuchKey
+//		if errors.As(err, &noSuchKey) {
+//			return xcache.S3Object{}, xcache.ErrNotFound
+//		} else if err != nil {
+//			return xcache.S3Object{}, err
+//		}
+//		defer out.Body.Close()
+//		body, err := io.ReadAll(out.Body)
+//
+//		return xcache.S3Object{Body: body, Metadata: out.Metadata}, err
+//	}
+//
+//	func (c s3Client) HeadObject(ctx context.Context, bucket, key string) (map[string]string, error) {
+//		out, err := c.s3.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &key})
+//		var notFound *types.NotFound
+//		if errors.As(err, &notFound) {
+//			return nil, xcache.ErrNotFound
+//		} else if err != nil {
+//			return nil, err
+//		}
+//
+//		return out.Metadata, nil
+//	}
+//
+//	func (c s3Client) PutObject(ctx context.Context, bucket, key string, obj xcache.S3Object) error {
+//		_, err := c.s3.PutObject(ctx, &s3.PutObjectInput{
+//			Bucket:   &bucket,
+//			Key:      &key,
+//			Body:     bytes.NewReader(obj.Body),
+//			Metadata: obj.Metadata,
+//		})
+//
+//		return err
+//	}
+//
+// DeleteObject calls s3.Client's DeleteObject.
+type S3Client interface {
+	// GetObject returns the object with given key, or ErrNotFound, if it does not exist.
+	GetObject(ctx context.Context, bucket, key string) (S3Object, error)
+	// HeadObject returns the metadata of the object with given key, or ErrNotFound, if it does not exist.
+	HeadObject(ctx context.Context, bucket, key string) (map[string]string, error)
+	// PutObject stores (overwrites) the object with given key.
+	PutObject(ctx context.Context, bucket, key string, obj S3Object) error
+	// DeleteObject deletes the object with given key (not existing is not an error).
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3Config holds the settings of an S3 cache.
+type S3Config struct {
+	// Bucket is the bucket the objects are stored into.
+	Bucket string
+	// Prefix is prepended to each key, isolating the cache's objects from other bucket data.
+	// By default (""), it's "xcache/".
+	Prefix string
+}
+
+// S3 is a Cache backed by an S3 (compatible) bucket, for very large, infrequently changing values
+// (rendered documents, ML feature blobs), meant to be the last layer of a Multi cache.
+// A key's expiration moment is stored in the object's metadata (see S3ExpiresAtMetadata); expired objects
+// are never returned, and they are deleted lazily, when they are loaded.
+// Expired objects which are never loaded again are not deleted, configure a lifecycle rule
+// on the bucket's prefix (with an expiration greater than your longest TTL) to reclaim them.
+//
+// Example:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	blobCache := xcache.NewS3(s3Client{s3.NewFromConfig(cfg)}, xcache.S3Config{Bucket: "app-cache"})
+//	cache := xcache.NewMulti(memCache, redisCache, blobCache)
+type S3 struct {
+	client S3Client
+	bucket string
+	prefix string
+	hits   int64
+	misses int64
+}
+
+// NewS3 instantiates a new S3 cache upon given client, according to given settings.
+func NewS3(client S3Client, config S3Config) *S3 {
+	if config.Prefix == "" {
+		config.Prefix = "xcache/"
+	}
+
+	return &S3{
+		client: client,
+		bucket: config.Bucket,
+		prefix: config.Prefix,
+	}
+}
+
+// Save stores (overwrites) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *S3) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+
+	obj := S3Object{Body: value, Metadata: map[string]string{}}
+	if expire > 0 {
+		expiresAt := time.Now().Add(expire).UnixMilli()
+		obj.Metadata[S3ExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+	}
+	if obj.Body == nil {
+		obj.Body = []byte{}
+	}
+
+	return cache.client.PutObject(ctx, cache.bucket, cache.prefix+key, obj)
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+// An expired object is deleted.
+func (cache *S3) Load(ctx context.Context, key string) ([]byte, error) {
+	obj, err := cache.client.GetObject(ctx, cache.bucket, cache.prefix+key)
+	if err == nil && isBlobExpired(obj.Metadata, S3ExpiresAtMetadata) {
+		_ = cache.client.DeleteObject(ctx, cache.bucket, cache.prefix+key) // lazy deletion, best effort.
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return obj.Body, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the read fails.
+// If the key is not found (or it's expired), a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *S3) TTL(ctx context.Context, key string) (time.Duration, error) {
+	metadata, err := cache.client.HeadObject(ctx, cache.bucket, cache.prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	return blobTTL(metadata, S3ExpiresAtMetadata), nil
+}
+
+// Stats returns the hits and misses since the cache was instantiated.
+// The no. of keys and memory are not reported, as listing a bucket is not a cheap operation
+// (use the bucket's storage metrics instead).
+func (cache *S3) Stats(_ context.Context) (Stats, error) {
+	return Stats{
+		Hits:   atomic.LoadInt64(&cache.hits),
+		Misses: atomic.LoadInt64(&cache.misses),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *S3) Delete(ctx context.Context, key string) error {
+	return cache.client.DeleteObject(ctx, cache.bucket, cache.prefix+key)
+}
+
+// blobTTL returns the remaining time to live of an object with given metadata, where the expiration
+// moment (unix milliseconds) is stored under given metadata key.
+// If the object has no (valid) expiration, 0 (NoExpire) is returned; if it's expired, a negative TTL is returned.
+func blobTTL(metadata map[string]string, expiresAtKey string) time.Duration {
+	expiresAt, err := strconv.ParseInt(metadata[expiresAtKey], 10, 64)
+	if err != nil {
+		return NoExpire
+	}
+	ttl := time.Until(time.UnixMilli(expiresAt))
+	if ttl <= 0 {
+		return -1
+	}
+
+	return ttl
+}
+
+// isBlobExpired returns whether an object with given metadata is expired (see blobTTL).
+func isBlobExpired(metadata map[string]string, expiresAtKey string) bool {
+	return blobTTL(metadata, expiresAtKey) < 0
+}
@@ -0,0 +1,209 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.S3)(nil)   // test S3 is a Cache
+	var _ xcache.Deleter = (*xcache.S3)(nil) // test S3 is a Deleter
+	var _ xcache.S3Client = (*s3Bucket)(nil) // test s3Bucket is a S3Client
+}
+
+func TestS3(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testS3SaveLoadTTLDelete)
+	t.Run("expired objects are deleted lazily", testS3ExpiredObjectsAreDeletedLazily)
+	t.Run("client errors are returned", testS3ClientErrorsAreReturned)
+}
+
+func testS3SaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newS3Bucket()
+		subject = xcache.NewS3(bucket, xcache.S3Config{Bucket: "test-bucket", Prefix: "blobs/"})
+		ctx     = context.Background()
+		key     = "test-s3-key"
+		value   = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	obj := bucket.Object("test-bucket/blobs/" + key)
+	assertEqual(t, value, obj.Body)
+	assertTrue(t, obj.Metadata[xcache.S3ExpiresAtMetadata] != "")
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	requireNil(t, subject.Delete(ctx, key))
+	assertEqual(t, 0, bucket.Len())
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1}, stats)
+}
+
+func testS3ExpiredObjectsAreDeletedLazily(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newS3Bucket()
+		subject = xcache.NewS3(bucket, xcache.S3Config{Bucket: "test-bucket"})
+		ctx     = context.Background()
+		key     = "test-s3-expired-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), time.Minute))
+	bucket.Expire("test-bucket/xcache/" + key)
+
+	// act
+	ttl, errTTL := subject.TTL(ctx, key)
+	lenBeforeLoad := bucket.Len()
+	_, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+	assertEqual(t, 1, lenBeforeLoad)
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertEqual(t, 0, bucket.Len())
+}
+
+func testS3ClientErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		bucket  = newS3Bucket()
+		subject = xcache.NewS3(bucket, xcache.S3Config{Bucket: "test-bucket"})
+		ctx     = context.Background()
+		key     = "test-s3-error-key"
+		errS3   = errors.New("slow down")
+	)
+	bucket.err = errS3
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+	_, errTTL := subject.TTL(ctx, key)
+	errDelete := subject.Delete(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errSave, errS3))
+	assertTrue(t, errors.Is(errLoad, errS3))
+	assertTrue(t, errors.Is(errTTL, errS3))
+	assertTrue(t, errors.Is(errDelete, errS3))
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, xcache.Stats{}, stats)
+}
+
+// s3Bucket is an in memory xcache.S3Client, objects being stored by "bucket/key".
+type s3Bucket struct {
+	objects map[string]xcache.S3Object
+	err     error
+	mu      sync.Mutex
+}
+
+func newS3Bucket() *s3Bucket {
+	return &s3Bucket{objects: make(map[string]xcache.S3Object)}
+}
+
+func (b *s3Bucket) GetObject(_ context.Context, bucket, key string) (xcache.S3Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return xcache.S3Object{}, b.err
+	}
+	obj, found := b.objects[bucket+"/"+key]
+	if !found {
+		return xcache.S3Object{}, xcache.ErrNotFound
+	}
+
+	return obj, nil
+}
+
+func (b *s3Bucket) HeadObject(ctx context.Context, bucket, key string) (map[string]string, error) {
+	obj, err := b.GetObject(ctx, bucket, key)
+
+	return obj.Metadata, err
+}
+
+func (b *s3Bucket) PutObject(_ context.Context, bucket, key string, obj xcache.S3Object) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	b.objects[bucket+"/"+key] = obj
+
+	return nil
+}
+
+func (b *s3Bucket) DeleteObject(_ context.Context, bucket, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	delete(b.objects, bucket+"/"+key)
+
+	return nil
+}
+
+func (b *s3Bucket) Object(path string) xcache.S3Object {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.objects[path]
+}
+
+// Expire makes the object with given path expired.
+func (b *s3Bucket) Expire(path string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expiresAt := time.Now().Add(-time.Second).UnixMilli()
+	b.objects[path].Metadata[xcache.S3ExpiresAtMetadata] = strconv.FormatInt(expiresAt, 10)
+}
+
+func (b *s3Bucket) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.objects)
+}
@@ -0,0 +1,109 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor is returned by a Scan operation if given cursor is malformed
+// or does not belong to the scanned cache type.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// scanDefaultCount is the count hint used by Scan if a non-positive one is provided.
+const scanDefaultCount = 100
+
+// cursor kinds, each cache type has its own cursor format.
+const (
+	cursorKindMemory       = "m"
+	cursorKindRedis        = "r"
+	cursorKindRedisCluster = "c"
+)
+
+const cursorPartsSep = "|"
+
+// encodeCursor returns an opaque cursor made of given kind and parts.
+func encodeCursor(kind string, parts ...string) string {
+	raw := kind + cursorPartsSep + strings.Join(parts, cursorPartsSep)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor returns the parts of given opaque cursor, which is expected to be of given kind
+// and to have given no. of parts.
+func decodeCursor(cursor, kind string, partsNo int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), cursorPartsSep, partsNo+1)
+	if len(parts) != partsNo+1 || parts[0] != kind {
+		return nil, ErrInvalidCursor
+	}
+
+	return parts[1:], nil
+}
+
+// decodeUintCursor returns the number stored in given opaque cursor of given kind.
+// An empty cursor results in 0.
+func decodeUintCursor(cursor, kind string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	parts, err := decodeCursor(cursor, kind, 1)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	return value, nil
+}
+
+// encodeUintCursor returns an opaque cursor storing given number.
+func encodeUintCursor(kind string, value uint64) string {
+	return encodeCursor(kind, strconv.FormatUint(value, 10))
+}
+
+// decodeRedisClusterCursor returns the address of the node to be scanned and the node's SCAN cursor
+// stored in given opaque cursor. An empty cursor results in empty address and 0.
+func decodeRedisClusterCursor(cursor string) (string, uint64, error) {
+	if cursor == "" {
+		return "", 0, nil
+	}
+	parts, err := decodeCursor(cursor, cursorKindRedisCluster, 2)
+	if err != nil {
+		return "", 0, err
+	}
+	nodeCursor, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || parts[1] == "" {
+		return "", 0, ErrInvalidCursor
+	}
+
+	return parts[1], nodeCursor, nil
+}
+
+// encodeRedisClusterCursor returns an opaque cursor storing given node address and node's SCAN cursor.
+func encodeRedisClusterCursor(addr string, nodeCursor uint64) string {
+	return encodeCursor(cursorKindRedisCluster, strconv.FormatUint(nodeCursor, 10), addr)
+}
+
+// sortedKeys returns given map's keys, sorted.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,270 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrNoShards is returned by NewSharded if no cache is given.
+var ErrNoShards = errors.New("no shards")
+
+// ShardedVirtualNodes is the no. of virtual nodes (points on the hash ring) of each shard of a Sharded cache.
+const ShardedVirtualNodes = 160
+
+// Sharded is a composite Cache which distributes keys among independent caches (shards), like
+// multiple Redis instances which do not form a cluster (client side sharding).
+// Each key is routed to a single shard, through consistent hashing (with virtual nodes), so adding a shard
+// remaps only about 1/N of the keys, and removing one remaps only its keys.
+//
+// Shards are identified by their position, so all instances of an application should provide
+// the shards in the same order. Shards should be added at the end.
+//
+// Example:
+//
+//	cache, err := xcache.NewSharded(redisCache1, redisCache2, redisCache3)
+type Sharded struct {
+	shards []Cache
+	ring   []shardPoint // sorted by hash.
+}
+
+// shardPoint is a point (virtual node) of a shard on the hash ring.
+type shardPoint struct {
+	hash  uint64
+	shard int
+}
+
+// NewSharded instantiates a new Sharded cache, distributing keys among given caches.
+// It returns ErrNoShards if no cache is given.
+func NewSharded(caches ...Cache) (*Sharded, error) {
+	if len(caches) == 0 {
+		return nil, ErrNoShards
+	}
+
+	ring := make([]shardPoint, 0, len(caches)*ShardedVirtualNodes)
+	for shard := range caches {
+		for vnode := 0; vnode < ShardedVirtualNodes; vnode++ {
+			ring = append(ring, shardPoint{
+				hash:  shardHash(strconv.Itoa(shard) + "#" + strconv.Itoa(vnode)),
+				shard: shard,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+
+	return &Sharded{
+		shards: caches,
+		ring:   ring,
+	}, nil
+}
+
+// Save stores the given key-value with expiration period into key's shard.
+// A negative expiration period triggers deletion of key.
+func (cache *Sharded) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.Shard(key).Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from key's shard.
+func (cache *Sharded) Load(ctx context.Context, key string) ([]byte, error) {
+	return cache.Shard(key).Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from key's shard.
+func (cache *Sharded) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.Shard(key).TTL(ctx, key)
+}
+
+// Stats returns the statistics of all shards, summed up, or an error if something bad happens
+// within any of the shards.
+func (cache *Sharded) Stats(ctx context.Context) (Stats, error) {
+	var (
+		mErr   multiErrors
+		sStats Stats
+	)
+	for _, shard := range cache.shards {
+		if stats, err := shard.Stats(ctx); err != nil {
+			mErr.add(err)
+		} else {
+			sStats.add(stats)
+		}
+	}
+
+	if err := mErr.errOrNil(); err != nil {
+		return Stats{}, err
+	}
+
+	return sStats, nil
+}
+
+// Delete deletes the given key from key's shard.
+func (cache *Sharded) Delete(ctx context.Context, key string) error {
+	return deleteKey(ctx, cache.Shard(key), key)
+}
+
+// DeleteMany deletes the given keys, each one from its shard.
+func (cache *Sharded) DeleteMany(ctx context.Context, keys ...string) error {
+	var mErr multiErrors
+	for shard, shardKeys := range cache.groupKeys(keys) {
+		if err := deleteKeys(ctx, cache.shards[shard], shardKeys...); err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return mErr.errOrNil()
+}
+
+// Has checks if the given key exists in key's shard.
+func (cache *Sharded) Has(ctx context.Context, key string) (bool, error) {
+	return hasKey(ctx, cache.Shard(key), key)
+}
+
+// SaveMany stores the given items, each one into its shard.
+// It returns an error if the items could not be saved (in any of the shards - note,
+// that the items of other shards can end up being saved).
+func (cache *Sharded) SaveMany(ctx context.Context, items map[string]Item) error {
+	shardsItems := make(map[int]map[string]Item, len(cache.shards))
+	for key, item := range items {
+		shard := cache.shardIndex(key)
+		if shardsItems[shard] == nil {
+			shardsItems[shard] = make(map[string]Item)
+		}
+		shardsItems[shard][key] = item
+	}
+
+	var mErr multiErrors
+	for shard, shardItems := range shardsItems {
+		if err := saveMany(ctx, cache.shards[shard], shardItems); err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return mErr.errOrNil()
+}
+
+// LoadMany returns the values of given keys, each one from its shard
+// (keys not found are missing from the returned map).
+// If any shard gives an error, the values found in the other shards and that error are returned.
+func (cache *Sharded) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	var (
+		mErr   multiErrors
+		values = make(map[string][]byte, len(keys))
+	)
+	for shard, shardKeys := range cache.groupKeys(keys) {
+		shardValues, err := loadMany(ctx, cache.shards[shard], shardKeys)
+		if err != nil {
+			mErr.add(err)
+		}
+		for key, value := range shardValues {
+			values[key] = value
+		}
+	}
+
+	return values, mErr.errOrNil()
+}
+
+// DeletePrefix deletes all keys starting with given prefix, from all shards.
+// It returns the total no. of deleted keys, or an error if deleting failed in any of the shards.
+// A shard that does not implement PrefixDeleter results in an [errors.ErrUnsupported] error.
+func (cache *Sharded) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	var (
+		mErr    multiErrors
+		deleted int
+	)
+	for _, shard := range cache.shards {
+		pd, ok := shard.(PrefixDeleter)
+		if !ok {
+			mErr.add(errors.ErrUnsupported)
+
+			continue
+		}
+		n, err := pd.DeletePrefix(ctx, prefix)
+		deleted += n
+		if err != nil {
+			mErr.add(err)
+		}
+	}
+
+	return deleted, mErr.errOrNil()
+}
+
+// Touch sets the given expiration period to a key, in key's shard.
+// A shard that does not implement Toucher results in an [errors.ErrUnsupported] error.
+func (cache *Sharded) Touch(ctx context.Context, key string, expire time.Duration) (bool, error) {
+	toucher, ok := cache.Shard(key).(Toucher)
+	if !ok {
+		return false, errors.ErrUnsupported
+	}
+
+	return toucher.Touch(ctx, key, expire)
+}
+
+// SaveKeepTTL stores the given value for a key into key's shard, keeping key's remaining time to live,
+// see SaveKeepTTL function.
+func (cache *Sharded) SaveKeepTTL(ctx context.Context, key string, value []byte) error {
+	return SaveKeepTTL(ctx, cache.Shard(key), key, value)
+}
+
+// Shard returns the shard given key is routed to.
+func (cache *Sharded) Shard(key string) Cache {
+	return cache.shards[cache.shardIndex(key)]
+}
+
+// shardIndex returns the index of the shard given key is routed to:
+// the shard of the first point on the ring having a hash greater or equal to key's hash.
+func (cache *Sharded) shardIndex(key string) int {
+	if len(cache.shards) == 1 {
+		return 0
+	}
+
+	hash := shardHash(key)
+	idx := sort.Search(len(cache.ring), func(i int) bool {
+		return cache.ring[i].hash >= hash
+	})
+	if idx == len(cache.ring) {
+		idx = 0 // wrap around the ring.
+	}
+
+	return cache.ring[idx].shard
+}
+
+// groupKeys returns given keys grouped by their shard.
+func (cache *Sharded) groupKeys(keys []string) map[int][]string {
+	shardsKeys := make(map[int][]string, len(cache.shards))
+	for _, key := range keys {
+		shard := cache.shardIndex(key)
+		shardsKeys[shard] = append(shardsKeys[shard], key)
+	}
+
+	return shardsKeys
+}
+
+// shardHash returns the position of given key on the hash ring: its FNV-1a 64 hash,
+// with the bits mixed (FNV alone distributes similar keys poorly on the ring).
+func shardHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	hash := h.Sum64()
+	// SplitMix64 finalizer.
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+
+	return hash
+}
@@ -0,0 +1,214 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Sharded)(nil)            // test Sharded is a Cache
+	var _ xcache.Deleter = (*xcache.Sharded)(nil)          // test Sharded is a Deleter
+	var _ xcache.BulkDeleter = (*xcache.Sharded)(nil)      // test Sharded is a BulkDeleter
+	var _ xcache.ExistenceChecker = (*xcache.Sharded)(nil) // test Sharded is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.Sharded)(nil)          // test Sharded is a Batcher
+	var _ xcache.PrefixDeleter = (*xcache.Sharded)(nil)    // test Sharded is a PrefixDeleter
+	var _ xcache.Toucher = (*xcache.Sharded)(nil)          // test Sharded is a Toucher
+	var _ xcache.TTLKeeper = (*xcache.Sharded)(nil)        // test Sharded is a TTLKeeper
+}
+
+func TestSharded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys are routed to a single shard", testShardedKeysAreRoutedToASingleShard)
+	t.Run("keys are evenly distributed", testShardedKeysAreEvenlyDistributed)
+	t.Run("adding a shard remaps few keys", testShardedAddingAShardRemapsFewKeys)
+	t.Run("batch operations", testShardedBatchOperations)
+	t.Run("stats are aggregated", testShardedStatsAreAggregated)
+	t.Run("no shards", testShardedNoShards)
+}
+
+func testShardedKeysAreRoutedToASingleShard(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		shards     = []xcache.Cache{xcache.NewMemory(freecacheMinMem), xcache.NewMemory(freecacheMinMem)}
+		subject, _ = xcache.NewSharded(shards...)
+		ctx        = context.Background()
+		value      = []byte("test value")
+	)
+
+	for i := 0; i < 20; i++ {
+		key := "test-sharded-key-" + strconv.Itoa(i)
+
+		// act
+		err := subject.Save(ctx, key, value, time.Minute)
+
+		// assert
+		requireNil(t, err)
+		loadedValue, err := subject.Load(ctx, key)
+		assertNil(t, err)
+		assertEqual(t, value, loadedValue)
+		ttl, err := subject.TTL(ctx, key)
+		assertNil(t, err)
+		assertTrue(t, ttl > 0)
+		found := 0
+		for _, shard := range shards {
+			if _, err := shard.Load(ctx, key); err == nil {
+				found++
+			}
+		}
+		assertEqual(t, 1, found)
+		_, err = subject.Shard(key).Load(ctx, key)
+		assertNil(t, err)
+	}
+}
+
+func testShardedKeysAreEvenlyDistributed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		shards     = []xcache.Cache{new(xcache.Mock), new(xcache.Mock), new(xcache.Mock)}
+		subject, _ = xcache.NewSharded(shards...)
+		counts     = make([]int, len(shards))
+		keysCnt    = 30000
+	)
+
+	// act
+	for i := 0; i < keysCnt; i++ {
+		shard := subject.Shard("user:" + strconv.Itoa(i))
+		for idx := range shards {
+			if shard == shards[idx] {
+				counts[idx]++
+			}
+		}
+	}
+
+	// assert
+	assertEqual(t, keysCnt, counts[0]+counts[1]+counts[2])
+	for _, count := range counts {
+		assertTrue(t, count > keysCnt/4 && count < keysCnt*5/12) // within 25%-41%.
+	}
+}
+
+func testShardedAddingAShardRemapsFewKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		shards     = []xcache.Cache{new(xcache.Mock), new(xcache.Mock), new(xcache.Mock), new(xcache.Mock)}
+		subject, _ = xcache.NewSharded(shards[:3]...)
+		grown, _   = xcache.NewSharded(shards...)
+		keysCnt    = 10000
+		moved      int
+	)
+
+	// act
+	for i := 0; i < keysCnt; i++ {
+		key := "product:" + strconv.Itoa(i)
+		before, after := subject.Shard(key), grown.Shard(key)
+		if before != after {
+			moved++
+			assertTrue(t, after == shards[3]) // keys move only to the new shard.
+		}
+	}
+
+	// assert
+	assertTrue(t, moved > keysCnt/6 && moved < keysCnt/3) // ideally 1/4.
+}
+
+func testShardedBatchOperations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		shards     = []xcache.Cache{xcache.NewMemory(freecacheMinMem), xcache.NewMemory(freecacheMinMem)}
+		subject, _ = xcache.NewSharded(shards...)
+		ctx        = context.Background()
+		items      = make(map[string]xcache.Item)
+		keys       []string
+	)
+	for i := 0; i < 10; i++ {
+		key := "test-sharded-batch-key-" + strconv.Itoa(i)
+		keys = append(keys, key)
+		items[key] = xcache.Item{Value: []byte("value " + strconv.Itoa(i)), Expire: time.Minute}
+	}
+
+	// act & assert
+	requireNil(t, subject.SaveMany(ctx, items))
+	values, err := subject.LoadMany(ctx, append(keys, "test-sharded-batch-missing-key"))
+	assertNil(t, err)
+	assertEqual(t, len(items), len(values))
+	for key, item := range items {
+		assertEqual(t, item.Value, values[key])
+	}
+	touched, err := subject.Touch(ctx, keys[0], time.Hour)
+	assertNil(t, err)
+	assertTrue(t, touched)
+	requireNil(t, subject.DeleteMany(ctx, keys[:5]...))
+	requireNil(t, subject.Delete(ctx, keys[5]))
+	found, err := subject.Has(ctx, keys[5])
+	assertNil(t, err)
+	assertTrue(t, !found)
+	values, err = subject.LoadMany(ctx, keys)
+	assertNil(t, err)
+	assertEqual(t, 4, len(values))
+	deleted, err := subject.DeletePrefix(ctx, "test-sharded-batch-key-")
+	assertNil(t, err)
+	assertEqual(t, 4, deleted)
+	_, err = subject.DeletePrefix(ctx, "")
+	assertNil(t, err)
+	unsupported, _ := xcache.NewSharded(xcache.NewRateLimited(new(xcache.Mock), xcache.RateLimitConfig{}))
+	_, err = unsupported.DeletePrefix(ctx, "test-sharded-batch-key-")
+	assertTrue(t, errors.Is(err, errors.ErrUnsupported)) // RateLimited is not a PrefixDeleter.
+}
+
+func testShardedStatsAreAggregated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		shard1     = new(xcache.Mock)
+		shard2     = xcache.NewRateLimited(new(xcache.Mock), xcache.RateLimitConfig{})
+		subject, _ = xcache.NewSharded(shard1, shard2)
+		ctx        = context.Background()
+	)
+	shard1.SetStatsCallback(func(context.Context) (xcache.Stats, error) {
+		return xcache.Stats{Keys: 3, Hits: 10}, nil
+	})
+
+	// act
+	stats, err := xcache.CollectDetailedStats(ctx, subject)
+
+	// assert
+	requireNil(t, err)
+	assertEqual(t, int64(3), stats.Keys)
+	assertEqual(t, int64(10), stats.Hits)
+	assertEqual(
+		t,
+		map[string]float64{"shards.1.ratelimit.limited": 0, "shards.1.ratelimit.rejected": 0},
+		stats.Metrics,
+	)
+}
+
+func testShardedNoShards(t *testing.T) {
+	t.Parallel()
+
+	// act
+	subject, err := xcache.NewSharded()
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNoShards))
+	assertTrue(t, subject == nil)
+}
@@ -0,0 +1,293 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SQLDialect is the SQL dialect of the database a SQL cache works against.
+type SQLDialect int
+
+// SQL dialects.
+const (
+	// SQLPostgres is the Postgres dialect.
+	SQLPostgres SQLDialect = iota
+	// SQLMySQL is the MySQL (MariaDB) dialect.
+	SQLMySQL
+)
+
+// SQLConfig holds the settings of a SQL cache.
+type SQLConfig struct {
+	// Dialect is the SQL dialect of the database. By default, it's SQLPostgres.
+	Dialect SQLDialect
+	// Table is the table keys are stored into. By default (""), it's "xcache".
+	// Note: it's used as it is in statements, it must be a trusted identifier.
+	Table string
+	// CleanInterval is the interval at which expired keys are deleted from the table, in background.
+	// By default (0), the background cleaning is disabled (expired keys are not returned anyway,
+	// and they can be deleted with Clean, from a cron job, for example).
+	CleanInterval time.Duration
+	// CleanBatch is the max. no. of expired keys deleted in a statement. By default (0), it's 1000.
+	CleanBatch int
+}
+
+// SQL is a Cache backed by a SQL database table (Postgres / MySQL), through database/sql, so teams which
+// cannot deploy Redis can still use this package's contract, Multi and StatsWatcher unchanged.
+// Keys are saved with upsert semantics, having their expiration moment stored in a column; expired keys
+// are not returned, and are deleted periodically, if configured so, see SQLConfig.
+// The table can be created with CreateTable.
+// It implements io.Closer and should be closed at your application shutdown (the database is not closed).
+//
+// Example:
+//
+//	db, err := sql.Open("pgx", dsn)
+//	if err != nil {
+//		return err
+//	}
+//	cache := xcache.NewSQL(db, xcache.SQLConfig{CleanInterval: time.Minute})
+//	defer cache.Close()
+//	if err := cache.CreateTable(ctx); err != nil {
+//		return err
+//	}
+type SQL struct {
+	db      *sql.DB
+	config  SQLConfig
+	queries sqlQueries
+	hits    int64
+	misses  int64
+	expired int64
+	closed  chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// sqlQueries holds the statements of a SQL cache.
+type sqlQueries struct {
+	create []string
+	upsert string
+	load   string
+	ttl    string
+	delete string
+	count  string
+	clean  string
+}
+
+// NewSQL instantiates a new SQL cache upon given database, according to given settings.
+func NewSQL(db *sql.DB, config SQLConfig) *SQL {
+	if config.Table == "" {
+		config.Table = "xcache"
+	}
+	if config.CleanBatch <= 0 {
+		config.CleanBatch = 1000
+	}
+	cache := &SQL{
+		db:      db,
+		config:  config,
+		queries: newSQLQueries(config.Dialect, config.Table, config.CleanBatch),
+		closed:  make(chan struct{}),
+	}
+
+	if config.CleanInterval > 0 {
+		cache.wg.Add(1)
+		go cache.cleanAsync()
+	}
+
+	return cache
+}
+
+// CreateTable creates the table (and its index on the expiration column), if it does not exist.
+func (cache *SQL) CreateTable(ctx context.Context) error {
+	for _, query := range cache.queries.create {
+		if _, err := cache.db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save stores (inserts, or updates) the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (cache *SQL) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		_, err := cache.db.ExecContext(ctx, cache.queries.delete, key)
+
+		return err
+	}
+
+	var expireAt sql.NullInt64
+	if expire > 0 {
+		expireAt = sql.NullInt64{Int64: time.Now().Add(expire).UnixMilli(), Valid: true}
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := cache.db.ExecContext(ctx, cache.queries.upsert, key, value, expireAt)
+
+	return err
+}
+
+// Load returns a key's value, or an error if something bad happened.
+// If the key is not found (or it's expired), ErrNotFound is returned.
+func (cache *SQL) Load(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := cache.db.QueryRowContext(ctx, cache.queries.load, key, time.Now().UnixMilli()).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.hits, 1)
+
+	return value, nil
+}
+
+// TTL returns a key's remaining time to live. Error is nil, unless the query fails.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *SQL) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var expireAt sql.NullInt64
+	err := cache.db.QueryRowContext(ctx, cache.queries.ttl, key, time.Now().UnixMilli()).Scan(&expireAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if !expireAt.Valid {
+		return NoExpire, nil
+	}
+
+	return time.Until(time.UnixMilli(expireAt.Int64)), nil
+}
+
+// Stats returns statistics about SQL cache: the no. of (not expired) keys, the hits and misses,
+// and the no. of expired keys deleted by cleaning, since the cache was instantiated.
+// Memory is not reported. It returns an error if the keys could not be counted.
+func (cache *SQL) Stats(ctx context.Context) (Stats, error) {
+	var keys int64
+	if err := cache.db.QueryRowContext(ctx, cache.queries.count, time.Now().UnixMilli()).Scan(&keys); err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Keys:    keys,
+		Hits:    atomic.LoadInt64(&cache.hits),
+		Misses:  atomic.LoadInt64(&cache.misses),
+		Expired: atomic.LoadInt64(&cache.expired),
+	}, nil
+}
+
+// Delete deletes the given key.
+func (cache *SQL) Delete(ctx context.Context, key string) error {
+	_, err := cache.db.ExecContext(ctx, cache.queries.delete, key)
+
+	return err
+}
+
+// Clean deletes the keys expired until now, in batches (see SQLConfig.CleanBatch).
+// It returns the no. of deleted keys, or an error if a statement fails.
+func (cache *SQL) Clean(ctx context.Context) (int, error) {
+	var (
+		now     = time.Now().UnixMilli()
+		deleted int
+	)
+	for {
+		result, err := cache.db.ExecContext(ctx, cache.queries.clean, now)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += int(n)
+		atomic.AddInt64(&cache.expired, n)
+		if n < int64(cache.config.CleanBatch) {
+			return deleted, nil
+		}
+	}
+}
+
+// Close stops the background cleaning.
+// It implements io.Closer interface, and the returned error can be disregarded (is nil all the time).
+func (cache *SQL) Close() error {
+	cache.once.Do(func() {
+		close(cache.closed)
+		cache.wg.Wait()
+	})
+
+	return nil
+}
+
+// cleanAsync deletes the expired keys, interval based, until the cache is closed.
+func (cache *SQL) cleanAsync() {
+	defer cache.wg.Done()
+
+	ticker := time.NewTicker(cache.config.CleanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cache.closed:
+			return
+		case <-ticker.C:
+			_, _ = cache.Clean(context.Background())
+		}
+	}
+}
+
+// newSQLQueries returns the statements of given dialect, working with given table.
+func newSQLQueries(dialect SQLDialect, table string, cleanBatch int) sqlQueries {
+	limit := strconv.Itoa(cleanBatch)
+	if dialect == SQLMySQL {
+		return sqlQueries{
+			create: []string{
+				"CREATE TABLE IF NOT EXISTS " + table + " (k VARBINARY(767) NOT NULL PRIMARY KEY, " +
+					"v LONGBLOB NOT NULL, expire_at BIGINT NULL, INDEX " + strings.ReplaceAll(table, ".", "_") +
+					"_expire_at_idx (expire_at))",
+			},
+			upsert: "INSERT INTO " + table + " (k, v, expire_at) VALUES (?, ?, ?) " +
+				"ON DUPLICATE KEY UPDATE v = VALUES(v), expire_at = VALUES(expire_at)",
+			load:   "SELECT v FROM " + table + " WHERE k = ? AND (expire_at IS NULL OR expire_at > ?)",
+			ttl:    "SELECT expire_at FROM " + table + " WHERE k = ? AND (expire_at IS NULL OR expire_at > ?)",
+			delete: "DELETE FROM " + table + " WHERE k = ?",
+			count:  "SELECT COUNT(*) FROM " + table + " WHERE expire_at IS NULL OR expire_at > ?",
+			clean:  "DELETE FROM " + table + " WHERE expire_at <= ? LIMIT " + limit,
+		}
+	}
+
+	return sqlQueries{
+		create: []string{
+			"CREATE TABLE IF NOT EXISTS " + table + " (k TEXT NOT NULL PRIMARY KEY, v BYTEA NOT NULL, expire_at BIGINT)",
+			"CREATE INDEX IF NOT EXISTS " + strings.ReplaceAll(table, ".", "_") + "_expire_at_idx ON " + table +
+				" (expire_at)",
+		},
+		upsert: "INSERT INTO " + table + " (k, v, expire_at) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (k) DO UPDATE SET v = EXCLUDED.v, expire_at = EXCLUDED.expire_at",
+		load:   "SELECT v FROM " + table + " WHERE k = $1 AND (expire_at IS NULL OR expire_at > $2)",
+		ttl:    "SELECT expire_at FROM " + table + " WHERE k = $1 AND (expire_at IS NULL OR expire_at > $2)",
+		delete: "DELETE FROM " + table + " WHERE k = $1",
+		count:  "SELECT COUNT(*) FROM " + table + " WHERE expire_at IS NULL OR expire_at > $1",
+		clean: "DELETE FROM " + table + " WHERE k IN (SELECT k FROM " + table +
+			" WHERE expire_at <= $1 LIMIT " + limit + ")",
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.SQL)(nil)   // test SQL is a Cache
+	var _ xcache.Deleter = (*xcache.SQL)(nil) // test SQL is a Deleter
+	var _ io.Closer = (*xcache.SQL)(nil)      // test SQL is a Closer
+}
+
+func TestSQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("postgres statements", testSQLPostgresStatements)
+	t.Run("mysql statements", testSQLMySQLStatements)
+	t.Run("not found key", testSQLNotFoundKey)
+	t.Run("clean deletes in batches", testSQLCleanDeletesInBatches)
+}
+
+func testSQLPostgresStatements(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		value     = []byte("test value")
+		connector = &fakeSQLDriver{
+			handler: func(query string, _ []driver.Value) ([][]driver.Value, int64, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT v "):
+					return [][]driver.Value{{value}}, 0, nil
+				case strings.HasPrefix(query, "SELECT expire_at "):
+					return [][]driver.Value{{time.Now().Add(time.Minute).UnixMilli()}}, 0, nil
+				case strings.HasPrefix(query, "SELECT COUNT(*) "):
+					return [][]driver.Value{{int64(7)}}, 0, nil
+				}
+
+				return nil, 1, nil
+			},
+		}
+		subject = xcache.NewSQL(sql.OpenDB(connector), xcache.SQLConfig{Table: "app.cache"})
+		ctx     = context.Background()
+		key     = "test-sql-key"
+	)
+	defer subject.Close()
+
+	// act
+	errCreate := subject.CreateTable(ctx)
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	loadedValue, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+	errDelete := subject.Save(ctx, key, nil, -1)
+	stats, errStats := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, errCreate)
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, loadedValue)
+	assertNil(t, errTTL)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute)
+	assertNil(t, errDelete)
+	assertNil(t, errStats)
+	assertEqual(t, xcache.Stats{Keys: 7, Hits: 1}, stats)
+	queries := connector.Queries()
+	if assertEqual(t, 7, len(queries)) {
+		assertEqual(
+			t,
+			"CREATE TABLE IF NOT EXISTS app.cache (k TEXT NOT NULL PRIMARY KEY, v BYTEA NOT NULL, expire_at BIGINT)",
+			queries[0].query,
+		)
+		assertEqual(t, "CREATE INDEX IF NOT EXISTS app_cache_expire_at_idx ON app.cache (expire_at)", queries[1].query)
+		assertEqual(
+			t,
+			"INSERT INTO app.cache (k, v, expire_at) VALUES ($1, $2, $3) "+
+				"ON CONFLICT (k) DO UPDATE SET v = EXCLUDED.v, expire_at = EXCLUDED.expire_at",
+			queries[2].query,
+		)
+		assertEqual(t, key, queries[2].args[0])
+		assertEqual(t, value, queries[2].args[1])
+		assertEqual(t, "DELETE FROM app.cache WHERE k = $1", queries[5].query)
+	}
+}
+
+func testSQLMySQLStatements(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		connector = &fakeSQLDriver{
+			handler: func(string, []driver.Value) ([][]driver.Value, int64, error) {
+				return [][]driver.Value{{nil}}, 1, nil
+			},
+		}
+		subject = xcache.NewSQL(sql.OpenDB(connector), xcache.SQLConfig{Dialect: xcache.SQLMySQL})
+		ctx     = context.Background()
+		key     = "test-sql-mysql-key"
+	)
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), xcache.NoExpire)
+	ttl, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errTTL)
+	assertEqual(t, xcache.NoExpire, ttl)
+	queries := connector.Queries()
+	if assertEqual(t, 2, len(queries)) {
+		assertEqual(
+			t,
+			"INSERT INTO xcache (k, v, expire_at) VALUES (?, ?, ?) "+
+				"ON DUPLICATE KEY UPDATE v = VALUES(v), expire_at = VALUES(expire_at)",
+			queries[0].query,
+		)
+		assertEqual(t, nil, queries[0].args[2]) // no expiration.
+		assertEqual(
+			t,
+			"SELECT expire_at FROM xcache WHERE k = ? AND (expire_at IS NULL OR expire_at > ?)",
+			queries[1].query,
+		)
+	}
+}
+
+func testSQLNotFoundKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		connector = &fakeSQLDriver{
+			handler: func(string, []driver.Value) ([][]driver.Value, int64, error) {
+				return nil, 0, nil
+			},
+		}
+		subject = xcache.NewSQL(sql.OpenDB(connector), xcache.SQLConfig{})
+		ctx     = context.Background()
+		key     = "test-sql-missing-key"
+	)
+	defer subject.Close()
+
+	// act
+	_, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertNil(t, errTTL)
+	assertTrue(t, ttl < 0)
+}
+
+func testSQLCleanDeletesInBatches(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		affected  = []int64{2, 2, 1}
+		connector = &fakeSQLDriver{
+			handler: func(string, []driver.Value) ([][]driver.Value, int64, error) {
+				n := affected[0]
+				affected = affected[1:]
+
+				return nil, n, nil
+			},
+		}
+		subject = xcache.NewSQL(sql.OpenDB(connector), xcache.SQLConfig{CleanBatch: 2})
+		ctx     = context.Background()
+	)
+	defer subject.Close()
+
+	// act
+	deleted, err := subject.Clean(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 5, deleted)
+	queries := connector.Queries()
+	if assertEqual(t, 3, len(queries)) {
+		assertEqual(
+			t,
+			"DELETE FROM xcache WHERE k IN (SELECT k FROM xcache WHERE expire_at <= $1 LIMIT 2)",
+			queries[0].query,
+		)
+	}
+}
+
+// fakeSQLQuery is a statement executed through a fakeSQLDriver.
+type fakeSQLQuery struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeSQLDriver is a database/sql driver.Connector which records the executed statements,
+// and returns the rows (single column) / no. of affected rows given by its handler.
+type fakeSQLDriver struct {
+	handler func(query string, args []driver.Value) ([][]driver.Value, int64, error)
+	queries []fakeSQLQuery
+	mu      sync.Mutex
+}
+
+func (d *fakeSQLDriver) Connect(context.Context) (driver.Conn, error) { return fakeSQLConn{d}, nil }
+func (d *fakeSQLDriver) Driver() driver.Driver                        { return nil }
+
+func (d *fakeSQLDriver) Queries() []fakeSQLQuery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]fakeSQLQuery(nil), d.queries...)
+}
+
+func (d *fakeSQLDriver) handle(query string, args []driver.NamedValue) ([][]driver.Value, int64, error) {
+	values := make([]driver.Value, len(args))
+	for idx, arg := range args {
+		values[idx] = arg.Value
+	}
+	d.mu.Lock()
+	d.queries = append(d.queries, fakeSQLQuery{query: query, args: values})
+	d.mu.Unlock()
+
+	return d.handler(query, values)
+}
+
+type fakeSQLConn struct{ d *fakeSQLDriver }
+
+func (fakeSQLConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeSQLConn) Close() error                        { return nil }
+func (fakeSQLConn) Begin() (driver.Tx, error)           { return nil, errors.ErrUnsupported }
+
+func (c fakeSQLConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, affected, err := c.d.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(affected), nil
+}
+
+func (c fakeSQLConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, _, err := c.d.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeSQLRows{rows: rows}, nil
+}
+
+type fakeSQLRows struct{ rows [][]driver.Value }
+
+func (*fakeSQLRows) Columns() []string { return []string{"c"} }
+func (*fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadResult is the result of loading a key from a StaleWhileRevalidate cache.
+type LoadResult struct {
+	// Value is the key's value.
+	Value []byte
+	// Stale is true if the value's soft TTL passed (the value should be revalidated),
+	// but its hard TTL did not.
+	Stale bool
+}
+
+// StaleWhileRevalidateConfig holds the settings of a StaleWhileRevalidate.
+type StaleWhileRevalidateConfig struct {
+	// StalePeriod is the period, after a value's soft TTL, the value is still served, flagged as stale,
+	// for values saved with Save (the expiration period being the soft TTL).
+	// By default (0), it's equal to the soft TTL (hard TTL = 2 x soft TTL).
+	StalePeriod time.Duration
+	// Pool executes the revalidations of stale loaded values. If Pool or Revalidate is nil,
+	// values are not revalidated in background, it's caller's job to do so, based on LoadResult.Stale.
+	Pool *WorkerPool
+	// Revalidate fetches a stale key's value from the source of truth, returning it along with its soft TTL.
+	Revalidate RefreshFunc
+}
+
+// StaleWhileRevalidate is a Cache decorator implementing two level expiration of values:
+// after the soft TTL, a value is still returned, but flagged as stale (and, optionally, revalidated in background),
+// and after the hard TTL, it's a real miss (ErrNotFound).
+// This way, users do not wait for the recomputation of an expired value, while the served data is bounded
+// in staleness.
+//
+// Keys' soft and hard expiration moments are stored alongside the values, in an Envelope;
+// the decorated cache stores the values for their hard TTL.
+// Values not having an envelope (saved directly into decorated cache) are returned as they are, as fresh ones.
+//
+// A stale key being revalidated is not revalidated again, until its revalidation completes.
+// Revalidations which cannot be scheduled (the pool's queue is full) are skipped, a later load retrying them.
+//
+// Example:
+//
+//	cache := xcache.NewStaleWhileRevalidate(redisCache, xcache.StaleWhileRevalidateConfig{
+//		StalePeriod: 10 * time.Minute,
+//		Pool:        pool,
+//		Revalidate: func(ctx context.Context, key string) ([]byte, time.Duration, error) {
+//			value, err := productsRepo.GetJSON(ctx, strings.TrimPrefix(key, "product:"))
+//
+//			return value, time.Minute, err
+//		},
+//	})
+//	result, err := cache.LoadStale(ctx, "product:"+id)
+//	if err == nil && result.Stale {
+//		w.Header().Set("Warning", `110 - "Response is Stale"`)
+//	}
+type StaleWhileRevalidate struct {
+	cache       Cache
+	config      StaleWhileRevalidateConfig
+	inFlight    map[string]struct{}
+	mu          sync.Mutex
+	served      int64
+	revalidated int64
+	failed      int64
+}
+
+// NewStaleWhileRevalidate instantiates a new StaleWhileRevalidate which decorates given cache,
+// according to given settings.
+func NewStaleWhileRevalidate(cache Cache, config StaleWhileRevalidateConfig) *StaleWhileRevalidate {
+	return &StaleWhileRevalidate{
+		cache:    cache,
+		config:   config,
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// Save stores the given key-value into decorated cache, with given expiration period as soft TTL,
+// and the soft TTL plus the stale period as hard TTL.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+func (cache *StaleWhileRevalidate) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	stalePeriod := cache.config.StalePeriod
+	if stalePeriod <= 0 {
+		stalePeriod = expire
+	}
+
+	return cache.SaveWithHardTTL(ctx, key, value, expire, expire+stalePeriod)
+}
+
+// SaveWithHardTTL stores the given key-value into decorated cache, with given soft and hard TTLs.
+// A soft TTL equal to 0 (NoExpire) means no expiration.
+// A negative soft TTL triggers deletion of key.
+// A hard TTL lower than the soft TTL is replaced with the soft TTL (the value is never served stale).
+func (cache *StaleWhileRevalidate) SaveWithHardTTL(
+	ctx context.Context,
+	key string,
+	value []byte,
+	softTTL time.Duration,
+	hardTTL time.Duration,
+) error {
+	if softTTL < 0 {
+		return cache.cache.Save(ctx, key, value, softTTL)
+	}
+
+	var (
+		env    Envelope
+		hardAt [binary.MaxVarintLen64]byte
+	)
+	if softTTL == NoExpire {
+		hardTTL = NoExpire
+	} else {
+		hardTTL = max(hardTTL, softTTL)
+		now := time.Now()
+		env.ExpireAt = now.Add(softTTL).UnixMilli()
+		env.Metadata = binary.AppendUvarint(hardAt[:0], uint64(now.Add(hardTTL).UnixMilli()))
+	}
+	enveloped := env.Append(make([]byte, 0, env.Size(len(value))), value)
+
+	return cache.cache.Save(ctx, key, enveloped, hardTTL)
+}
+
+// Load returns a key's value from decorated cache, even if it's stale (see LoadStale for telling so).
+func (cache *StaleWhileRevalidate) Load(ctx context.Context, key string) ([]byte, error) {
+	result, err := cache.LoadStale(ctx, key)
+
+	return result.Value, err
+}
+
+// LoadStale returns a key's value from decorated cache, flagged as stale if its soft TTL passed.
+// A stale value's revalidation is scheduled, in background, if a Pool and a Revalidate function were configured.
+// If the value's hard TTL passed, ErrNotFound is returned.
+func (cache *StaleWhileRevalidate) LoadStale(ctx context.Context, key string) (LoadResult, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil || !IsEnvelope(value) {
+		return LoadResult{Value: value}, err
+	}
+
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return LoadResult{Value: value}, nil // not a value of ours, return it as it is.
+	}
+	if env.ExpireAt == 0 {
+		return LoadResult{Value: payload}, nil
+	}
+	now := time.Now().UnixMilli()
+	if now < env.ExpireAt {
+		return LoadResult{Value: payload}, nil
+	}
+	if hardAt, n := binary.Uvarint(env.Metadata); n <= 0 || now >= int64(hardAt) {
+		return LoadResult{}, ErrNotFound
+	}
+	atomic.AddInt64(&cache.served, 1)
+	cache.scheduleRevalidation(ctx, key)
+
+	return LoadResult{Value: payload, Stale: true}, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache (the remaining hard TTL).
+func (cache *StaleWhileRevalidate) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *StaleWhileRevalidate) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *StaleWhileRevalidate) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of served stale values, as "stale.served" metric,
+// the no. of revalidated keys, as "stale.revalidated" metric, and the no. of failed revalidations
+// (the Revalidate function, or the save, failed), as "stale.failed" metric.
+func (cache *StaleWhileRevalidate) ContributeStats(add func(name string, value float64)) {
+	add("stale.served", float64(atomic.LoadInt64(&cache.served)))
+	add("stale.revalidated", float64(atomic.LoadInt64(&cache.revalidated)))
+	add("stale.failed", float64(atomic.LoadInt64(&cache.failed)))
+}
+
+// scheduleRevalidation schedules the revalidation of given key, if it's configured,
+// and the key is not already being revalidated.
+// Note: the revalidation is not canceled if the context gets done.
+func (cache *StaleWhileRevalidate) scheduleRevalidation(ctx context.Context, key string) {
+	if cache.config.Pool == nil || cache.config.Revalidate == nil || !cache.acquire(key) {
+		return
+	}
+	bgCtx := context.WithoutCancel(ctx)
+	if !cache.config.Pool.Submit(func() {
+		defer cache.release(key)
+		cache.revalidate(bgCtx, key)
+	}) {
+		cache.release(key)
+	}
+}
+
+// revalidate fetches given key's value with the Revalidate function, and saves it.
+func (cache *StaleWhileRevalidate) revalidate(ctx context.Context, key string) {
+	value, expire, err := cache.config.Revalidate(ctx, key)
+	if err == nil {
+		err = cache.Save(ctx, key, value, expire)
+	}
+	if err != nil {
+		atomic.AddInt64(&cache.failed, 1)
+
+		return
+	}
+	atomic.AddInt64(&cache.revalidated, 1)
+}
+
+// acquire marks the given key as being revalidated.
+// It returns false if the key is already being revalidated.
+func (cache *StaleWhileRevalidate) acquire(key string) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if _, found := cache.inFlight[key]; found {
+		return false
+	}
+	cache.inFlight[key] = struct{}{}
+
+	return true
+}
+
+// release marks the given key as not being revalidated anymore.
+func (cache *StaleWhileRevalidate) release(key string) {
+	cache.mu.Lock()
+	delete(cache.inFlight, key)
+	cache.mu.Unlock()
+}
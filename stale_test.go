@@ -0,0 +1,164 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.StaleWhileRevalidate)(nil)            // test StaleWhileRevalidate is a Cache
+	var _ xcache.StatsContributor = (*xcache.StaleWhileRevalidate)(nil) // test StaleWhileRevalidate is a StatsContributor
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fresh value is not stale", testStaleWhileRevalidateFreshValueIsNotStale)
+	t.Run("stale value is served and revalidated", testStaleWhileRevalidateStaleValueIsServedAndRevalidated)
+	t.Run("hard expired value is not found", testStaleWhileRevalidateHardExpiredValueIsNotFound)
+	t.Run("value without envelope is returned", testStaleWhileRevalidateValueWithoutEnvelopeIsReturned)
+}
+
+func testStaleWhileRevalidateFreshValueIsNotStale(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewStaleWhileRevalidate(cache, xcache.StaleWhileRevalidateConfig{StalePeriod: time.Hour})
+		ctx     = context.Background()
+		key     = "test-swr-fresh-key"
+		hardKey = "test-swr-hard-ttl-key"
+		value   = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	requireNil(t, subject.SaveWithHardTTL(ctx, hardKey, value, time.Minute, 10*time.Minute))
+
+	// act
+	result, err := subject.LoadStale(ctx, key)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, xcache.LoadResult{Value: value}, result)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 60*time.Minute && ttl <= 61*time.Minute) // decorated cache stores the value for its hard TTL.
+	ttl, err = subject.TTL(ctx, hardKey)
+	assertNil(t, err)
+	assertTrue(t, ttl > 9*time.Minute && ttl <= 10*time.Minute)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testStaleWhileRevalidateStaleValueIsServedAndRevalidated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache         = xcache.NewMemory(freecacheMinMem)
+		pool          = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		revalidations int32
+		subject       = xcache.NewStaleWhileRevalidate(cache, xcache.StaleWhileRevalidateConfig{
+			Pool: pool,
+			Revalidate: func(context.Context, string) ([]byte, time.Duration, error) {
+				atomic.AddInt32(&revalidations, 1)
+				time.Sleep(10 * time.Millisecond) // concurrent loads do not trigger another revalidation.
+
+				return []byte("test revalidated value"), time.Hour, nil
+			},
+		})
+		ctx   = context.Background()
+		key   = "test-swr-stale-key"
+		value = []byte("test value")
+	)
+	requireNil(t, cache.Save(ctx, key, newStaleEnvelope(value, time.Hour), time.Hour))
+
+	// act
+	result1, err1 := subject.LoadStale(ctx, key)
+	result2, err2 := subject.Load(ctx, key)
+	requireNil(t, pool.Close()) // wait for revalidations to complete.
+
+	// assert
+	assertNil(t, err1)
+	assertEqual(t, xcache.LoadResult{Value: value, Stale: true}, result1)
+	assertNil(t, err2)
+	assertEqual(t, value, result2)
+	assertEqual(t, int32(1), atomic.LoadInt32(&revalidations))
+	result, err := subject.LoadStale(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.LoadResult{Value: []byte("test revalidated value")}, result)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"stale.served": 2, "stale.revalidated": 1, "stale.failed": 0}, metrics)
+}
+
+func testStaleWhileRevalidateHardExpiredValueIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewStaleWhileRevalidate(cache, xcache.StaleWhileRevalidateConfig{})
+		ctx     = context.Background()
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return newStaleEnvelope([]byte("test value"), -time.Second), nil
+	})
+
+	// act
+	result, err := subject.LoadStale(ctx, "test-swr-expired-key")
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertEqual(t, xcache.LoadResult{}, result)
+}
+
+func testStaleWhileRevalidateValueWithoutEnvelopeIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewStaleWhileRevalidate(cache, xcache.StaleWhileRevalidateConfig{})
+		ctx     = context.Background()
+		key     = "test-swr-raw-key"
+		noExp   = "test-swr-no-exp-key"
+		value   = []byte("test raw value")
+	)
+	requireNil(t, cache.Save(ctx, key, value, time.Minute))
+	requireNil(t, subject.Save(ctx, noExp, value, xcache.NoExpire))
+
+	// act
+	result, err := subject.LoadStale(ctx, key)
+	resultNoExp, errNoExp := subject.LoadStale(ctx, noExp)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, xcache.LoadResult{Value: value}, result)
+	assertNil(t, errNoExp)
+	assertEqual(t, xcache.LoadResult{Value: value}, resultNoExp)
+	ttl, err := subject.TTL(ctx, noExp)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+}
+
+// newStaleEnvelope returns given value enveloped as a StaleWhileRevalidate one,
+// whose soft TTL passed, and whose hard TTL passes in given period.
+func newStaleEnvelope(value []byte, hardIn time.Duration) []byte {
+	env := xcache.Envelope{
+		ExpireAt: time.Now().Add(-time.Minute).UnixMilli(),
+		Metadata: binary.AppendUvarint(nil, uint64(time.Now().Add(hardIn).UnixMilli())),
+	}
+
+	return env.Append(nil, value)
+}
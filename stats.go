@@ -22,8 +22,9 @@ import (
 type Stats struct {
 	// Memory represents the in use memory.
 	// Notes:
-	// - for Memory Cache it's equal to the memory size used to initialize the cache,
-	// as Freecache allocates that amount of memory from the start. Thus, Memory is always equal to MaxMemory.
+	// - for Memory Cache it's an approximation of the memory occupied by stored entries
+	// (no. of entries multiplied by the average size of a sample of entries). Note that Freecache allocates
+	// MaxMemory from the start, and deleted / expired entries still occupy space until they are overwritten.
 	// To figure out that the memory is effectively full, a raise in Evicted number of keys should be considered.
 	// - for Redis Cache it's the used memory.
 	Memory int64
@@ -53,6 +54,27 @@ type Stats struct {
 	Expired int64
 	// Evicted represents the number of evicted keys reported by cache.
 	Evicted int64
+	// BytesRead represents the number of bytes read from cache (values' payload).
+	// Notes:
+	// - it's reported only by a cache decorated with Metered, otherwise it's 0.
+	BytesRead int64
+	// BytesWritten represents the number of bytes sent to cache (keys' and values' payload).
+	// Notes:
+	// - it's reported only by a cache decorated with Metered, otherwise it's 0.
+	BytesWritten int64
+}
+
+// add adds given stats to current ones.
+func (s *Stats) add(stats Stats) {
+	s.Memory += stats.Memory
+	s.MaxMemory += stats.MaxMemory
+	s.Hits += stats.Hits
+	s.Misses += stats.Misses
+	s.Keys += stats.Keys
+	s.Expired += stats.Expired
+	s.Evicted += stats.Evicted
+	s.BytesRead += stats.BytesRead
+	s.BytesWritten += stats.BytesWritten
 }
 
 // String implements fmt.Stringer.
@@ -61,6 +83,10 @@ type Stats struct {
 // Example:
 //
 //	mem=1.25M maxMem=7.77G memPerc=0.02% hits=101701 misses=0 hitRate=100.00% keys=1 expired=14473 evicted=0
+//
+// Bytes read / written are appended only if they are reported (see Metered):
+//
+//	... evicted=0 bytesRead=1.50G bytesWritten=256.12M
 func (s Stats) String() string {
 	buf := make([]byte, 0, 128)
 	buf = append(buf, "mem="...)
@@ -94,6 +120,12 @@ func (s Stats) String() string {
 	buf = append(buf, strconv.FormatInt(s.Expired, 10)...)
 	buf = append(buf, " evicted="...)
 	buf = append(buf, strconv.FormatInt(s.Evicted, 10)...)
+	if s.BytesRead > 0 || s.BytesWritten > 0 {
+		buf = append(buf, " bytesRead="...)
+		buf = append(buf, bytesHumanFriendly(s.BytesRead)...)
+		buf = append(buf, " bytesWritten="...)
+		buf = append(buf, bytesHumanFriendly(s.BytesWritten)...)
+	}
 
 	return bytesToString(buf)
 }
@@ -130,6 +162,83 @@ func bytesHumanFriendly(bytes int64) string {
 	return bytesToString(buf)
 }
 
+// StatsContributor is implemented by caches (usually decorators) having metrics of their own,
+// like a no. of rejected operations, or a compression ratio, which are merged into DetailedStats.
+type StatsContributor interface {
+	// ContributeStats reports cache's own metrics, calling given function for each of them.
+	ContributeStats(add func(name string, value float64))
+}
+
+// DetailedStats holds the statistics of a cache, and the metrics of the caches of its pipeline.
+type DetailedStats struct {
+	Stats
+	// Metrics holds the metrics contributed by the caches of the pipeline (see StatsContributor), by name.
+	// Names of the metrics contributed by a Multi cache's layer are prefixed with the layer's index
+	// (like "layers.1.loadshed.shed"). Same metrics contributed by multiple caches of a layer are summed.
+	Metrics map[string]float64
+}
+
+// CollectDetailedStats returns the statistics of given cache, along with the metrics contributed by the caches
+// of its pipeline: the decorators chain (see Unwrapper), the layers of Multi caches, the shards of Sharded caches
+// (metrics prefixed with "shards.<index>."), and the replicas of Replicated caches (metrics prefixed with
+// "replicas.<index>."), so the entire pipeline can be observed from a single place.
+// If the statistics cannot be retrieved, the error is returned along with the metrics.
+//
+// Example:
+//
+//	stats, err := xcache.CollectDetailedStats(ctx, cache)
+//	shed := stats.Metrics["layers.1.loadshed.shed"]
+func CollectDetailedStats(ctx context.Context, cache Cache) (DetailedStats, error) {
+	stats, err := cache.Stats(ctx)
+	detailedStats := DetailedStats{
+		Stats:   stats,
+		Metrics: make(map[string]float64),
+	}
+	contributeStats(cache, "", detailedStats.Metrics)
+
+	return detailedStats, err
+}
+
+// contributeStats collects into metrics, with names prefixed with given prefix,
+// the metrics of given cache's decorators chain, and of its layers / shards / replicas,
+// if it's a Multi / Sharded / Replicated cache.
+func contributeStats(cache Cache, prefix string, metrics map[string]float64) {
+	add := func(name string, value float64) {
+		metrics[prefix+name] += value
+	}
+	for cache != nil {
+		if contributor, ok := cache.(StatsContributor); ok {
+			contributor.ContributeStats(add)
+		}
+		if multi, ok := cache.(Multi); ok {
+			for idx, layer := range multi.caches {
+				contributeStats(layer, prefix+"layers."+strconv.Itoa(idx)+".", metrics)
+			}
+
+			return
+		}
+		if sharded, ok := cache.(*Sharded); ok {
+			for idx, shard := range sharded.shards {
+				contributeStats(shard, prefix+"shards."+strconv.Itoa(idx)+".", metrics)
+			}
+
+			return
+		}
+		if replicated, ok := cache.(*Replicated); ok {
+			for idx, replica := range replicated.replicas {
+				contributeStats(replica, prefix+"replicas."+strconv.Itoa(idx)+".", metrics)
+			}
+
+			return
+		}
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			return
+		}
+		cache = unwrapper.Unwrap()
+	}
+}
+
 // StatsWatcher can be used to execute a given callback
 // upon stats, interval based.
 // It implements io.Closer and should be closed at your application shutdown.
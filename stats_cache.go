@@ -0,0 +1,55 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// statsCache keeps the last retrieved Stats for a short period of time,
+// so that frequent Stats calls (like a StatsWatcher's ones) do not query the server each time.
+type statsCache struct {
+	ttl       time.Duration // period for which retrieved Stats are reused.
+	mu        sync.Mutex    // concurrency semaphore, serializes retrievals.
+	stats     Stats         // last retrieved Stats.
+	expiresAt time.Time     // moment after which Stats must be retrieved again.
+}
+
+// newStatsCache instantiates a new statsCache.
+// A nil statsCache is returned if given ttl is not positive, meaning Stats are not cached.
+func newStatsCache(ttl time.Duration) *statsCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &statsCache{ttl: ttl}
+}
+
+// load returns the cached Stats, if they did not expire, otherwise it retrieves them
+// with given function, and caches them.
+// Concurrent callers wait for a single retrieval. Errors are not cached.
+func (sc *statsCache) load(ctx context.Context, fetch func(context.Context) (Stats, error)) (Stats, error) {
+	if sc == nil {
+		return fetch(ctx)
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if time.Now().Before(sc.expiresAt) {
+		return sc.stats, nil
+	}
+	stats, err := fetch(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	sc.stats = stats
+	sc.expiresAt = time.Now().Add(sc.ttl)
+
+	return stats, nil
+}
@@ -6,6 +6,7 @@
 package xcache_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -69,6 +70,19 @@ func TestStats_String(t *testing.T) {
 			},
 			expectedResult: "mem=1T maxMem=0B memUsage=100.00% hits=0 misses=0 hitRate=100.00% keys=1001 expired=1000002 evicted=50000",
 		},
+		{
+			name: "bytes read/written",
+			subject: xcache.Stats{
+				Memory:       1024,
+				MaxMemory:    2048,
+				Hits:         1,
+				Misses:       1,
+				Keys:         1,
+				BytesRead:    1536 * 1024 * 1024,
+				BytesWritten: 100,
+			},
+			expectedResult: "mem=1K maxMem=2K memUsage=50.00% hits=1 misses=1 hitRate=50.00% keys=1 expired=0 evicted=0 bytesRead=1.50G bytesWritten=100B",
+		},
 	}
 
 	for _, testData := range tests {
@@ -235,6 +249,41 @@ func testStatsWatcherFinalizerIsCalled(t *testing.T) {
 	assertEqual(t, uint32(0), atomic.LoadUint32(&callsCnt))
 }
 
+func TestCollectDetailedStats(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		memCache    = xcache.NewMemory(8 * 1024 * 1024)
+		remoteCache = xcache.NewMemory(8 * 1024 * 1024)
+		compressed  = xcache.NewCompressed(remoteCache, xcache.NewGzipCompressor(0))
+		deadline    = xcache.NewDeadlineAware(xcache.NewLoadShed(compressed, 1, 0), time.Hour)
+		pool        = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1})
+		subject     = xcache.NewMulti(memCache, deadline).WithBackfillPool(pool)
+		ctx         = context.Background()
+		value       = bytes.Repeat([]byte("test value "), 100)
+	)
+	defer pool.Close()
+	requireNil(t, subject.Save(ctx, "test-detailed-stats-key", value, time.Minute))
+	ctxWithDeadline, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	_, _ = deadline.Load(ctxWithDeadline, "test-detailed-stats-key") // skipped, budget is too low.
+
+	// act
+	result, err := xcache.CollectDetailedStats(ctx, subject)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, int64(2), result.Keys)
+	assertEqual(t, float64(1), result.Metrics["layers.1.deadline.skipped"])
+	assertEqual(t, float64(0), result.Metrics["layers.1.loadshed.shed"])
+	assertEqual(t, float64(len(value)), result.Metrics["layers.1.compressed.raw_bytes"])
+	assertTrue(t, result.Metrics["layers.1.compressed.ratio"] > 10)
+	_, found := result.Metrics["multi.backfill.dropped"]
+	assertTrue(t, found)
+	assertEqual(t, 9, len(result.Metrics))
+}
+
 func BenchmarkStats_String(b *testing.B) {
 	stats := xcache.Stats{
 		Memory:    512 * 1024,
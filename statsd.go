@@ -0,0 +1,152 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatsDConfig holds the settings of a StatsDSink.
+type StatsDConfig struct {
+	// Addr is the address of the StatsD / DogStatsD server, like "127.0.0.1:8125".
+	Addr string
+	// Network is the network of the server, "udp" (default), or "unixgram", for example.
+	Network string
+	// Prefix is prepended to metrics' names. By default (""), it's "xcache.".
+	Prefix string
+	// Tags are DogStatsD tags (like "env:prod", "cache:redis") to be attached to metrics.
+	// Leave it empty for plain StatsD servers, which do not support tags.
+	Tags []string
+}
+
+// StatsDSink pushes caches' stats to a StatsD / DogStatsD server. Its Watch method is
+// a StatsWatcher callback.
+//
+// Gauges are sent for keys, memory and max memory ("keys", "memory", "max_memory"), and counters' deltas
+// since previous push are sent for hits, misses, expired / evicted keys and bytes read / written
+// ("hits", "misses", "expired", "evicted", "bytes_read", "bytes_written"). The first push is used as
+// baseline for counters (only gauges are sent). If a counter decreased (cache got restarted),
+// its current value is sent as delta. A stats' error is counted as "errors".
+//
+// Example:
+//
+//	sink, err := xcache.NewStatsDSink(xcache.StatsDConfig{Addr: "127.0.0.1:8125", Tags: []string{"cache:redis"}})
+//	if err != nil {
+//		return err
+//	}
+//	defer sink.Close()
+//	watcher := xcache.NewStatsWatcher(redisCache, 10*time.Second)
+//	defer watcher.Close()
+//	watcher.Watch(ctx, sink.Watch)
+type StatsDSink struct {
+	conn    net.Conn
+	prefix  string
+	tags    string // "|#tag1,tag2" suffix, or "".
+	mu      sync.Mutex
+	last    Stats
+	hasLast bool
+}
+
+// NewStatsDSink instantiates a new StatsDSink, according to given settings.
+// It returns an error if server's address cannot be resolved.
+// It implements io.Closer and should be closed at your application shutdown.
+func NewStatsDSink(config StatsDConfig) (*StatsDSink, error) {
+	if config.Network == "" {
+		config.Network = "udp"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "xcache."
+	}
+	conn, err := net.Dial(config.Network, config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	var tags string
+	if len(config.Tags) > 0 {
+		tags = "|#" + strings.Join(config.Tags, ",")
+	}
+
+	return &StatsDSink{
+		conn:   conn,
+		prefix: config.Prefix,
+		tags:   tags,
+	}, nil
+}
+
+// Watch pushes given stats, or counts given error. It can be used as a StatsWatcher callback.
+// Sending errors are disregarded, use Push if you need them.
+func (sink *StatsDSink) Watch(_ context.Context, stats Stats, err error) {
+	if err != nil {
+		_ = sink.send(sink.appendMetric(nil, "errors", 1, "c"))
+
+		return
+	}
+	_ = sink.Push(stats)
+}
+
+// Push sends given stats' gauges, and counters' deltas since previous push, in a single packet.
+func (sink *StatsDSink) Push(stats Stats) error {
+	buf := make([]byte, 0, 512)
+	buf = sink.appendMetric(buf, "keys", stats.Keys, "g")
+	buf = sink.appendMetric(buf, "memory", stats.Memory, "g")
+	buf = sink.appendMetric(buf, "max_memory", stats.MaxMemory, "g")
+
+	sink.mu.Lock()
+	if sink.hasLast {
+		buf = sink.appendMetric(buf, "hits", counterDelta(sink.last.Hits, stats.Hits), "c")
+		buf = sink.appendMetric(buf, "misses", counterDelta(sink.last.Misses, stats.Misses), "c")
+		buf = sink.appendMetric(buf, "expired", counterDelta(sink.last.Expired, stats.Expired), "c")
+		buf = sink.appendMetric(buf, "evicted", counterDelta(sink.last.Evicted, stats.Evicted), "c")
+		buf = sink.appendMetric(buf, "bytes_read", counterDelta(sink.last.BytesRead, stats.BytesRead), "c")
+		buf = sink.appendMetric(buf, "bytes_written", counterDelta(sink.last.BytesWritten, stats.BytesWritten), "c")
+	}
+	sink.last, sink.hasLast = stats, true
+	sink.mu.Unlock()
+
+	return sink.send(buf)
+}
+
+// Close closes the connection to the server.
+func (sink *StatsDSink) Close() error {
+	return sink.conn.Close()
+}
+
+// appendMetric appends to given buffer a metric line, like "xcache.hits:10|c|#env:prod".
+func (sink *StatsDSink) appendMetric(buf []byte, name string, value int64, typ string) []byte {
+	if len(buf) > 0 {
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, sink.prefix...)
+	buf = append(buf, name...)
+	buf = append(buf, ':')
+	buf = strconv.AppendInt(buf, value, 10)
+	buf = append(buf, '|')
+	buf = append(buf, typ...)
+	buf = append(buf, sink.tags...)
+
+	return buf
+}
+
+// send writes given packet to the server.
+func (sink *StatsDSink) send(packet []byte) error {
+	_, err := sink.conn.Write(packet)
+
+	return err
+}
+
+// counterDelta returns the difference between current and previous value of a counter,
+// or the current value, if the counter was reset.
+func counterDelta(previous, current int64) int64 {
+	if current < previous {
+		return current
+	}
+
+	return current - previous
+}
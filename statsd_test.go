@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ io.Closer = (*xcache.StatsDSink)(nil) // test StatsDSink is a Closer
+}
+
+func TestStatsDSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deltas are pushed", testStatsDSinkDeltasArePushed)
+	t.Run("errors are counted", testStatsDSinkErrorsAreCounted)
+}
+
+func testStatsDSinkDeltasArePushed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	requireNil(t, err)
+	defer server.Close()
+	subject, err := xcache.NewStatsDSink(xcache.StatsDConfig{
+		Addr:   server.LocalAddr().String(),
+		Prefix: "app.cache.",
+		Tags:   []string{"env:test", "cache:local"},
+	})
+	requireNil(t, err)
+	defer subject.Close()
+	ctx := context.Background()
+
+	// act
+	subject.Watch(ctx, xcache.Stats{Keys: 2, Memory: 100, MaxMemory: 1000, Hits: 10, Misses: 3}, nil)
+	packet1 := readPacket(t, server)
+	subject.Watch(ctx, xcache.Stats{Keys: 5, Memory: 250, MaxMemory: 1000, Hits: 15, Misses: 1, Evicted: 1}, nil)
+	packet2 := readPacket(t, server)
+
+	// assert
+	assertEqual(
+		t,
+		"app.cache.keys:2|g|#env:test,cache:local\n"+
+			"app.cache.memory:100|g|#env:test,cache:local\n"+
+			"app.cache.max_memory:1000|g|#env:test,cache:local",
+		packet1,
+	)
+	assertEqual(
+		t,
+		"app.cache.keys:5|g|#env:test,cache:local\n"+
+			"app.cache.memory:250|g|#env:test,cache:local\n"+
+			"app.cache.max_memory:1000|g|#env:test,cache:local\n"+
+			"app.cache.hits:5|c|#env:test,cache:local\n"+
+			"app.cache.misses:1|c|#env:test,cache:local\n"+ // counter was reset.
+			"app.cache.expired:0|c|#env:test,cache:local\n"+
+			"app.cache.evicted:1|c|#env:test,cache:local\n"+
+			"app.cache.bytes_read:0|c|#env:test,cache:local\n"+
+			"app.cache.bytes_written:0|c|#env:test,cache:local",
+		packet2,
+	)
+}
+
+func testStatsDSinkErrorsAreCounted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	requireNil(t, err)
+	defer server.Close()
+	subject, err := xcache.NewStatsDSink(xcache.StatsDConfig{Addr: server.LocalAddr().String()})
+	requireNil(t, err)
+	defer subject.Close()
+
+	// act
+	subject.Watch(context.Background(), xcache.Stats{}, errors.New("connection refused"))
+
+	// assert
+	assertEqual(t, "xcache.errors:1|c", readPacket(t, server))
+}
+
+// readPacket reads a packet from given connection.
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+
+	buf := make([]byte, 2048)
+	requireNil(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	requireNil(t, err)
+
+	return string(buf[:n])
+}
@@ -0,0 +1,74 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tags manages versions of tags (for example database tables names) stored into a cache,
+// enabling the invalidation of all entries depending on a tag at once:
+// entries' keys embed the current versions of the tags they depend on,
+// and invalidating a tag changes its version, so those keys are not reached anymore
+// (they will expire according to their TTL).
+type Tags struct {
+	cache     Cache
+	keyPrefix string
+}
+
+// NewTags instantiates a new Tags, which stores tags' versions into given cache,
+// under keys starting with given prefix.
+func NewTags(cache Cache, keyPrefix string) *Tags {
+	return &Tags{
+		cache:     cache,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Invalidate changes the versions of given tags, invalidating all entries depending on them.
+// It returns an error if any of the versions could not be saved.
+func (tags *Tags) Invalidate(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		if err := tags.cache.Save(ctx, tags.keyPrefix+name, newTagVersion(), NoExpire); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Versions returns the current versions of given tags, joined in a string.
+// A tag without a version gets one.
+// It returns an error if any of the versions could not be loaded / saved.
+func (tags *Tags) Versions(ctx context.Context, names ...string) (string, error) {
+	var sb strings.Builder
+	for _, name := range names {
+		key := tags.keyPrefix + name
+		version, err := tags.cache.Load(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			version = newTagVersion()
+			err = tags.cache.Save(ctx, key, version, NoExpire)
+		}
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.Write(version)
+		sb.WriteByte(';')
+	}
+
+	return sb.String(), nil
+}
+
+// newTagVersion returns a new tag version.
+func newTagVersion() []byte {
+	return strconv.AppendInt(nil, time.Now().UnixNano(), 36)
+}
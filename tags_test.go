@@ -0,0 +1,74 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("versions are stable until invalidation", testTagsVersionsAreStableUntilInvalidation)
+	t.Run("cache error is returned", testTagsCacheErrIsReturned)
+}
+
+func testTagsVersionsAreStableUntilInvalidation(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewTags(xcache.NewMemory(1), "test-tags-")
+		ctx     = context.Background()
+	)
+
+	// act & assert
+	versions1, err := subject.Versions(ctx, "users", "orders")
+	requireNil(t, err)
+	versions2, err := subject.Versions(ctx, "users", "orders")
+	requireNil(t, err)
+	assertEqual(t, versions1, versions2)
+
+	time.Sleep(time.Microsecond) // make sure the new version differs
+	err = subject.Invalidate(ctx, "orders")
+	requireNil(t, err)
+	versions3, err := subject.Versions(ctx, "users", "orders")
+	requireNil(t, err)
+	assertTrue(t, versions1 != versions3)
+	usersVersion1, _ := subject.Versions(ctx, "users")
+	assertEqual(t, versions1[:len(usersVersion1)], versions3[:len(usersVersion1)])
+}
+
+func testTagsCacheErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.NewTags(cache, "test-tags-")
+		ctx         = context.Background()
+		expectedErr = errors.New("intentionally triggered cache error")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, expectedErr
+	})
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return expectedErr
+	})
+
+	// act
+	_, errVersions := subject.Versions(ctx, "users")
+	errInvalidate := subject.Invalidate(ctx, "users")
+
+	// assert
+	assertTrue(t, errors.Is(errVersions, expectedErr))
+	assertTrue(t, errors.Is(errInvalidate, expectedErr))
+}
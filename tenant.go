@@ -0,0 +1,242 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQuotaExceeded is returned by a TenantCache Save operation if the tenant's quota would be exceeded.
+var ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+// ManagerConfig holds the settings of a Manager.
+type ManagerConfig struct {
+	// KeyPrefix returns the prefix of given tenant's keys.
+	// Prefixes should not overlap (a tenant's prefix should not start with another tenant's prefix).
+	// By default, it's "tenant:<tenant>:".
+	KeyPrefix func(tenant string) string
+	// Quota returns the max. no. of bytes (keys' and values' sizes) given tenant can store.
+	// A non-positive quota means no limit. By default, tenants have no quota.
+	Quota func(tenant string) int64
+}
+
+// Manager creates (on demand) and manages per tenant cache views over a shared cache,
+// each tenant having its keys namespaced with a prefix, its own quota and statistics.
+//
+// Example:
+//
+//	manager := xcache.NewManager(redisCache, xcache.ManagerConfig{})
+//	cache := manager.Tenant(tenantID) // use it as any other Cache.
+//	// ...
+//	_, err := manager.Offboard(ctx, tenantID) // delete all tenant's keys.
+type Manager struct {
+	cache   Cache
+	config  ManagerConfig
+	tenants map[string]*TenantCache
+	mu      sync.Mutex
+}
+
+// NewManager instantiates a new Manager, which creates tenants' cache views over given cache.
+func NewManager(cache Cache, config ManagerConfig) *Manager {
+	if config.KeyPrefix == nil {
+		config.KeyPrefix = func(tenant string) string {
+			return "tenant:" + tenant + ":"
+		}
+	}
+
+	return &Manager{
+		cache:   cache,
+		config:  config,
+		tenants: make(map[string]*TenantCache),
+	}
+}
+
+// Tenant returns the cache view of given tenant, creating it on first call.
+func (m *Manager) Tenant(tenant string) *TenantCache {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenantCache, found := m.tenants[tenant]
+	if !found {
+		tenantCache = &TenantCache{
+			cache:  m.cache,
+			prefix: m.config.KeyPrefix(tenant),
+			keys:   make(map[string]int64),
+		}
+		if m.config.Quota != nil {
+			tenantCache.quota = m.config.Quota(tenant)
+		}
+		m.tenants[tenant] = tenantCache
+	}
+
+	return tenantCache
+}
+
+// Tenants returns the tenants having a cache view, sorted.
+func (m *Manager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return sortedKeys(m.tenants)
+}
+
+// Offboard deletes all the keys of given tenant, and drops its cache view
+// (a new one is created if Tenant is called again).
+// Keys are deleted by tenant's prefix, if the shared cache is a PrefixDeleter, otherwise
+// the keys saved through tenant's cache view are deleted.
+// It returns the no. of deleted keys, or an error if something bad happened.
+func (m *Manager) Offboard(ctx context.Context, tenant string) (int, error) {
+	m.mu.Lock()
+	tenantCache, found := m.tenants[tenant]
+	delete(m.tenants, tenant)
+	m.mu.Unlock()
+
+	if prefixDeleter, ok := m.cache.(PrefixDeleter); ok {
+		return prefixDeleter.DeletePrefix(ctx, m.config.KeyPrefix(tenant))
+	}
+	if !found {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, key := range tenantCache.trackedKeys() {
+		if err := deleteKey(ctx, m.cache, tenantCache.prefix+key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// TenantCache is a tenant's cache view, created by a Manager.
+// Keys are prefixed with tenant's prefix, and the size of saved keys is tracked
+// (in memory, for current instance only), in order to enforce tenant's quota.
+// Expired keys are untracked when they are found to be missing (on Load / TTL).
+type TenantCache struct {
+	cache  Cache
+	prefix string
+	quota  int64
+	keys   map[string]int64 // tracked keys, with their sizes.
+	usage  int64            // sum of tracked keys' sizes.
+	hits   int64
+	misses int64
+	mu     sync.Mutex
+}
+
+// Save stores the given key-value with expiration period into shared cache, under tenant's prefix.
+// If tenant's quota would be exceeded, ErrQuotaExceeded is returned.
+func (cache *TenantCache) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		err := cache.cache.Save(ctx, cache.prefix+key, value, expire)
+		if err == nil {
+			cache.untrack(key)
+		}
+
+		return err
+	}
+
+	size := int64(len(key) + len(value))
+	cache.mu.Lock()
+	prevSize, tracked := cache.keys[key]
+	if cache.quota > 0 && size > prevSize && cache.usage+size-prevSize > cache.quota {
+		cache.mu.Unlock()
+
+		return ErrQuotaExceeded
+	}
+	cache.keys[key] = size
+	cache.usage += size - prevSize
+	cache.mu.Unlock()
+
+	err := cache.cache.Save(ctx, cache.prefix+key, value, expire)
+	if err != nil { // restore previous tracking, if the key was not saved meanwhile.
+		cache.mu.Lock()
+		if cache.keys[key] == size {
+			if tracked {
+				cache.keys[key] = prevSize
+			} else {
+				delete(cache.keys, key)
+			}
+			cache.usage -= size - prevSize
+		}
+		cache.mu.Unlock()
+	}
+
+	return err
+}
+
+// Load returns a key's value from shared cache, from under tenant's prefix.
+func (cache *TenantCache) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, cache.prefix+key)
+	switch {
+	case err == nil:
+		atomic.AddInt64(&cache.hits, 1)
+	case errors.Is(err, ErrNotFound):
+		atomic.AddInt64(&cache.misses, 1)
+		cache.untrack(key)
+	}
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from shared cache, from under tenant's prefix.
+func (cache *TenantCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := cache.cache.TTL(ctx, cache.prefix+key)
+	if err == nil && ttl < 0 {
+		cache.untrack(key)
+	}
+
+	return ttl, err
+}
+
+// Stats returns tenant's statistics:
+// Memory is the size of tracked keys, MaxMemory is the quota, Keys is the no. of tracked keys,
+// Hits and Misses are the ones of this cache view's Load operations.
+func (cache *TenantCache) Stats(context.Context) (Stats, error) {
+	cache.mu.Lock()
+	stats := Stats{
+		Memory:    cache.usage,
+		MaxMemory: cache.quota,
+		Keys:      int64(len(cache.keys)),
+	}
+	cache.mu.Unlock()
+	stats.Hits = atomic.LoadInt64(&cache.hits)
+	stats.Misses = atomic.LoadInt64(&cache.misses)
+
+	return stats, nil
+}
+
+// Unwrap returns the shared cache (the one of all tenants).
+func (cache *TenantCache) Unwrap() Cache {
+	return cache.cache
+}
+
+// untrack stops tracking given key.
+func (cache *TenantCache) untrack(key string) {
+	cache.mu.Lock()
+	if size, tracked := cache.keys[key]; tracked {
+		delete(cache.keys, key)
+		cache.usage -= size
+	}
+	cache.mu.Unlock()
+}
+
+// trackedKeys returns the tracked keys, sorted.
+func (cache *TenantCache) trackedKeys() []string {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return sortedKeys(cache.keys)
+}
@@ -0,0 +1,170 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.TenantCache)(nil) // test TenantCache is a Cache
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tenants' keys are namespaced", testManagerTenantsKeysAreNamespaced)
+	t.Run("quota is enforced", testManagerQuotaIsEnforced)
+	t.Run("tenant's stats are returned", testManagerTenantsStatsAreReturned)
+	t.Run("offboard - prefix deleter", testManagerOffboardPrefixDeleter)
+	t.Run("offboard - tracked keys", testManagerOffboardTrackedKeys)
+}
+
+func testManagerTenantsKeysAreNamespaced(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewManager(cache, xcache.ManagerConfig{})
+		ctx     = context.Background()
+		key     = "test-tenant-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Tenant("acme").Save(ctx, key, value, time.Minute)
+	acmeValue, acmeErr := subject.Tenant("acme").Load(ctx, key)
+	_, otherErr := subject.Tenant("other").Load(ctx, key)
+	sharedValue, sharedErr := cache.Load(ctx, "tenant:acme:"+key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, acmeErr)
+	assertEqual(t, value, acmeValue)
+	assertTrue(t, errors.Is(otherErr, xcache.ErrNotFound))
+	assertNil(t, sharedErr)
+	assertEqual(t, value, sharedValue)
+	assertEqual(t, []string{"acme", "other"}, subject.Tenants())
+}
+
+func testManagerQuotaIsEnforced(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewManager(cache, xcache.ManagerConfig{
+			Quota: func(tenant string) int64 {
+				if tenant == "acme" {
+					return 20
+				}
+
+				return 0
+			},
+		})
+		ctx = context.Background()
+	)
+
+	// act & assert
+	acme := subject.Tenant("acme")
+	assertNil(t, acme.Save(ctx, "key1", []byte("0123456789"), time.Minute)) // 14 bytes
+	err := acme.Save(ctx, "key2", []byte("0123456789"), time.Minute)
+	assertTrue(t, errors.Is(err, xcache.ErrQuotaExceeded))
+	assertNil(t, acme.Save(ctx, "key1", []byte("01"), time.Minute)) // 6 bytes, overwritten
+	assertNil(t, acme.Save(ctx, "key2", []byte("0123456789"), time.Minute))
+	assertNil(t, acme.Save(ctx, "key2", nil, -1)) // deletions free the quota
+	assertNil(t, acme.Save(ctx, "key3", []byte("0123456789"), time.Minute))
+	assertNil(t, subject.Tenant("other").Save(ctx, "key", make([]byte, 1024), time.Minute))
+	assertEqual(t, 6, cache.SaveCallsCount())
+}
+
+func testManagerTenantsStatsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewManager(xcache.NewMemory(1), xcache.ManagerConfig{
+			Quota: func(string) int64 { return 1024 },
+		})
+		ctx    = context.Background()
+		tenant = subject.Tenant("acme")
+	)
+	_ = tenant.Save(ctx, "key1", []byte("value1"), time.Minute)
+	_ = tenant.Save(ctx, "key2", []byte("value2"), time.Minute)
+	_, _ = tenant.Load(ctx, "key1")
+	_, _ = tenant.Load(ctx, "key3")
+
+	// act
+	stats, err := tenant.Stats(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xcache.Stats{Memory: 20, MaxMemory: 1024, Hits: 1, Misses: 1, Keys: 2},
+		stats,
+	)
+}
+
+func testManagerOffboardPrefixDeleter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewManager(cache, xcache.ManagerConfig{
+			KeyPrefix: func(tenant string) string {
+				return "test-offboard-" + tenant + ":"
+			},
+		})
+		ctx = context.Background()
+	)
+	_ = subject.Tenant("acme").Save(ctx, "key1", []byte("value"), time.Minute)
+	_ = subject.Tenant("acme").Save(ctx, "key2", []byte("value"), time.Minute)
+	_ = subject.Tenant("other").Save(ctx, "key1", []byte("value"), time.Minute)
+
+	// act
+	deleted, err := subject.Offboard(ctx, "acme")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, deleted)
+	assertEqual(t, []string{"other"}, subject.Tenants())
+	_, err = subject.Tenant("acme").Load(ctx, "key1")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	_, err = subject.Tenant("other").Load(ctx, "key1")
+	assertNil(t, err)
+}
+
+func testManagerOffboardTrackedKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMetered(xcache.NewMemory(1)) // not a PrefixDeleter
+		subject = xcache.NewManager(cache, xcache.ManagerConfig{})
+		ctx     = context.Background()
+	)
+	_ = subject.Tenant("acme").Save(ctx, "key1", []byte("value"), time.Minute)
+	_ = subject.Tenant("acme").Save(ctx, "key2", []byte("value"), time.Minute)
+
+	// act
+	deleted, err := subject.Offboard(ctx, "acme")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, deleted)
+	_, err = cache.Load(ctx, "tenant:acme:key1")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	_, err = cache.Load(ctx, "tenant:acme:key2")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
@@ -0,0 +1,377 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+// TieredConfig holds the settings of a Tiered cache.
+type TieredConfig struct {
+	// MemSize is the size of the memory tier, in bytes (see NewMemory). By default (0), it's 64 MB.
+	MemSize int
+	// SpillRatio is the ratio of MemSize the entries held only in memory may occupy, before the oldest
+	// of them are spilled to disk (until they occupy half of it). By default (0), it's 0.5.
+	// Freecache evicts entries per segment (1/256 of memory), so a ratio close to 1 lets entries be evicted
+	// before they are spilled.
+	SpillRatio float64
+}
+
+// Tiered is a cache made of a memory tier (Freecache), holding the hot entries, and a disk tier (Disk),
+// holding the entries overflowing the memory, presented as one Cache, with combined Stats.
+//
+// Writes go to memory only; as the memory fills up, the oldest entries are spilled to disk, in background,
+// before Freecache evicts them, so that an evicted entry is still served, from disk (and promoted back into memory).
+// Entries too large for the memory tier (larger than 1/1024 of it) are written directly to disk.
+// Unlike Multi(NewMemory(...), disk), short lived / frequently overwritten entries do not cost disk writes.
+//
+// Spilling is best effort: Freecache offers no eviction hook, the memory occupied by not spilled entries
+// is tracked instead (see TieredConfig.SpillRatio), and an entry of a (relatively) fuller segment can be evicted
+// before being spilled.
+// It implements io.Closer and should be closed at your application shutdown (the disk is not closed).
+//
+// Example:
+//
+//	disk, err := xcache.NewDisk(pebbleStore{db}, xcache.DiskConfig{})
+//	if err != nil {
+//		return err
+//	}
+//	defer disk.Close()
+//	cache := xcache.NewTiered(disk, xcache.TieredConfig{MemSize: 512 * 1024 * 1024})
+//	defer cache.Close()
+type Tiered struct {
+	mem          *Memory
+	disk         *Disk
+	locks        *KeyMutex
+	spillAt      int64 // memory-only bytes above which entries are spilled.
+	mu           sync.Mutex
+	pending      map[string]int64 // memory-only keys, and their entry sizes.
+	queue        []string         // memory-only keys, in the order they were saved.
+	pendingBytes int64
+	memHits      int64
+	diskHits     int64
+	misses       int64
+	spilled      int64
+	overflowed   int64
+	spill        chan struct{}
+	closed       chan struct{}
+	wg           sync.WaitGroup
+	once         sync.Once
+}
+
+// NewTiered instantiates a new Tiered cache upon given disk, according to given settings,
+// and starts the background spilling.
+func NewTiered(disk *Disk, config TieredConfig) *Tiered {
+	if config.MemSize <= 0 {
+		config.MemSize = 64 * 1024 * 1024
+	}
+	if config.SpillRatio <= 0 || config.SpillRatio > 1 {
+		config.SpillRatio = 0.5
+	}
+	mem := NewMemory(config.MemSize)
+
+	cache := &Tiered{
+		mem:     mem,
+		disk:    disk,
+		locks:   NewKeyMutex(256),
+		spillAt: int64(float64(mem.memSize) * config.SpillRatio),
+		pending: make(map[string]int64),
+		spill:   make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	cache.wg.Add(1)
+	go cache.spillAsync()
+
+	return cache
+}
+
+// Save stores the given key-value with expiration period into memory
+// (or disk, if it's too large for the memory tier), removing its disk copy, if any.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved, or the context's error, if it is done.
+func (cache *Tiered) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.Delete(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cache.locks.Lock(key)
+	defer cache.locks.Unlock(key)
+
+	err := cache.mem.client.Set([]byte(key), value, memoryExpireSeconds(expire))
+	if errors.Is(err, freecache.ErrLargeEntry) {
+		_ = cache.mem.client.Del([]byte(key))
+		cache.unmarkPending(key)
+		atomic.AddInt64(&cache.overflowed, 1)
+
+		return cache.disk.Save(ctx, key, value, expire)
+	} else if err != nil {
+		return err
+	}
+	cache.markPending(key, memoryEntryHeaderSize+int64(len(key)+len(value)))
+
+	return cache.disk.Save(ctx, key, nil, -1) // stale copy.
+}
+
+// Load returns a key's value from memory, or disk (promoting it into memory).
+// If the key is not found, ErrNotFound is returned.
+// If the context is done, its error is returned.
+func (cache *Tiered) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, err := cache.mem.client.Get([]byte(key)); err == nil {
+		atomic.AddInt64(&cache.memHits, 1)
+
+		return value, nil
+	}
+
+	cache.locks.Lock(key)
+	defer cache.locks.Unlock(key)
+
+	// the key could have been saved meanwhile.
+	if value, err := cache.mem.client.Get([]byte(key)); err == nil {
+		atomic.AddInt64(&cache.memHits, 1)
+
+		return value, nil
+	}
+	data, err := cache.disk.load(diskDataKey(key))
+	if errors.Is(err, ErrNotFound) {
+		atomic.AddInt64(&cache.misses, 1)
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&cache.diskHits, 1)
+
+	value := data[diskHeaderLen:]
+	if expireAt := int64(binary.BigEndian.Uint64(data)); expireAt == 0 {
+		_ = cache.mem.client.Set([]byte(key), value, 0)
+	} else if expire := time.Duration(expireAt - time.Now().UnixNano()); expire > 0 {
+		_ = cache.mem.client.Set([]byte(key), value, memoryExpireSeconds(expire))
+	}
+
+	return value, nil
+}
+
+// TTL returns a key's remaining time to live, from memory, or disk.
+// Error is nil, unless the disk fails, or the context is done.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (cache *Tiered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := cache.mem.TTL(ctx, key)
+	if err != nil || ttl >= 0 {
+		return ttl, err
+	}
+
+	return cache.disk.TTL(ctx, key)
+}
+
+// Stats returns the combined statistics of the tiers:
+// - Memory, MaxMemory and Evicted are the memory tier's (an evicted entry may be served from disk);
+// - Keys are the disk tier's keys, plus the ones held only in memory;
+// - Expired are the expired keys of both tiers;
+// - Hits and Misses are the Tiered's, a hit being served by either tier.
+// Returned error is nil, unless the context is done.
+func (cache *Tiered) Stats(ctx context.Context) (Stats, error) {
+	memStats, err := cache.mem.Stats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	diskStats, err := cache.disk.Stats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	cache.mu.Lock()
+	memOnlyKeys := int64(len(cache.pending))
+	cache.mu.Unlock()
+
+	return Stats{
+		Memory:    memStats.Memory,
+		MaxMemory: memStats.MaxMemory,
+		Hits:      atomic.LoadInt64(&cache.memHits) + atomic.LoadInt64(&cache.diskHits),
+		Misses:    atomic.LoadInt64(&cache.misses),
+		Keys:      diskStats.Keys + memOnlyKeys,
+		Expired:   memStats.Expired + diskStats.Expired,
+		Evicted:   memStats.Evicted,
+	}, nil
+}
+
+// Delete deletes the given key from both tiers.
+// It returns an error if the key could not be deleted from disk, or the context's error, if it is done.
+func (cache *Tiered) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cache.locks.Lock(key)
+	defer cache.locks.Unlock(key)
+
+	_ = cache.mem.client.Del([]byte(key))
+	cache.unmarkPending(key)
+
+	return cache.disk.Save(ctx, key, nil, -1)
+}
+
+// ContributeStats reports the hits served by each tier, the entries spilled to disk, and the ones
+// written directly to disk, as "tiered.memory_hits", "tiered.disk_hits", "tiered.spilled"
+// and "tiered.overflowed" metrics.
+func (cache *Tiered) ContributeStats(add func(name string, value float64)) {
+	add("tiered.memory_hits", float64(atomic.LoadInt64(&cache.memHits)))
+	add("tiered.disk_hits", float64(atomic.LoadInt64(&cache.diskHits)))
+	add("tiered.spilled", float64(atomic.LoadInt64(&cache.spilled)))
+	add("tiered.overflowed", float64(atomic.LoadInt64(&cache.overflowed)))
+}
+
+// Spill spills to disk all the entries held only in memory (for example, before shutdown,
+// for them to survive a restart). It returns the disk's error, if any.
+func (cache *Tiered) Spill(ctx context.Context) error {
+	return cache.spillTo(ctx, 0)
+}
+
+// Close stops the background spilling.
+// It implements io.Closer interface, and the returned error can be disregarded (is nil all the time).
+func (cache *Tiered) Close() error {
+	cache.once.Do(func() {
+		close(cache.closed)
+		cache.wg.Wait()
+	})
+
+	return nil
+}
+
+// spillTo spills to disk the oldest entries held only in memory, until they occupy at most target bytes.
+func (cache *Tiered) spillTo(ctx context.Context, target int64) error {
+	for {
+		cache.mu.Lock()
+		if cache.pendingBytes <= target || len(cache.queue) == 0 {
+			cache.mu.Unlock()
+
+			return nil
+		}
+		key := cache.queue[0]
+		cache.queue[0] = ""
+		cache.queue = cache.queue[1:]
+		_, isPending := cache.pending[key]
+		cache.mu.Unlock()
+
+		if isPending {
+			if err := cache.spillKey(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// spillKey writes given memory-only key to disk, with its remaining time to live.
+func (cache *Tiered) spillKey(ctx context.Context, key string) error {
+	cache.locks.Lock(key)
+	defer cache.locks.Unlock(key)
+
+	cache.mu.Lock()
+	_, isPending := cache.pending[key]
+	cache.mu.Unlock()
+	if !isPending { // deleted, or spilled, meanwhile.
+		return nil
+	}
+
+	value, expireAt, err := cache.mem.client.GetWithExpiration([]byte(key))
+	if err == nil {
+		expire := NoExpire
+		if expireAt > 0 {
+			expire = time.Until(time.Unix(int64(expireAt), 0))
+		}
+		if expireAt == 0 || expire > 0 {
+			if err := cache.disk.Save(ctx, key, value, expire); err != nil {
+				return err
+			}
+			atomic.AddInt64(&cache.spilled, 1)
+		}
+	}
+	cache.unmarkPending(key) // spilled, or expired / evicted.
+
+	return nil
+}
+
+// markPending records given key as held only in memory, signaling the spilling, if the threshold is exceeded.
+func (cache *Tiered) markPending(key string, size int64) {
+	cache.mu.Lock()
+	oldSize, isPending := cache.pending[key]
+	if !isPending {
+		cache.queue = append(cache.queue, key)
+	}
+	cache.pending[key] = size
+	cache.pendingBytes += size - oldSize
+	shouldSpill := cache.pendingBytes > cache.spillAt
+	if len(cache.queue) > 2*len(cache.pending)+1024 { // too many deleted keys are queued.
+		cache.compactQueue()
+	}
+	cache.mu.Unlock()
+
+	if shouldSpill {
+		select {
+		case cache.spill <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// unmarkPending records given key as not being held only in memory.
+func (cache *Tiered) unmarkPending(key string) {
+	cache.mu.Lock()
+	if size, isPending := cache.pending[key]; isPending {
+		delete(cache.pending, key)
+		cache.pendingBytes -= size
+	}
+	cache.mu.Unlock()
+}
+
+// compactQueue removes the not pending keys from queue. Caller must hold mu.
+func (cache *Tiered) compactQueue() {
+	seen := make(map[string]struct{}, len(cache.pending))
+	queue := make([]string, 0, len(cache.pending))
+	for _, key := range cache.queue {
+		if _, isPending := cache.pending[key]; !isPending {
+			continue
+		}
+		if _, isSeen := seen[key]; !isSeen {
+			seen[key] = struct{}{}
+			queue = append(queue, key)
+		}
+	}
+	cache.queue = queue
+}
+
+// spillAsync spills entries to disk each time the threshold is exceeded, until the cache is closed.
+func (cache *Tiered) spillAsync() {
+	defer cache.wg.Done()
+
+	for {
+		select {
+		case <-cache.closed:
+			return
+		case <-cache.spill:
+			_ = cache.spillTo(context.Background(), cache.spillAt/2)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Tiered)(nil)            // test Tiered is a Cache
+	var _ xcache.Deleter = (*xcache.Tiered)(nil)          // test Tiered is a Deleter
+	var _ xcache.StatsContributor = (*xcache.Tiered)(nil) // test Tiered is a StatsContributor
+}
+
+func TestTiered(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, delete", testTieredSaveLoadTTLDelete)
+	t.Run("spilled entries are served from disk", testTieredSpilledEntriesAreServedFromDisk)
+	t.Run("large entries overflow to disk", testTieredLargeEntriesOverflowToDisk)
+	t.Run("save removes stale disk copy", testTieredSaveRemovesStaleDiskCopy)
+	t.Run("spilling starts in background", testTieredSpillingStartsInBackground)
+}
+
+func newTieredDisk(t *testing.T) (*xcache.Disk, *sortedStore) {
+	t.Helper()
+
+	store := newSortedStore()
+	disk, err := xcache.NewDisk(store, xcache.DiskConfig{SweepInterval: -1})
+	requireNil(t, err)
+	t.Cleanup(func() { _ = disk.Close() })
+
+	return disk, store
+}
+
+func testTieredSaveLoadTTLDelete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		disk, store = newTieredDisk(t)
+		subject     = xcache.NewTiered(disk, xcache.TieredConfig{})
+		ctx         = context.Background()
+		key         = "test-tiered-key"
+		value       = []byte("test value")
+	)
+	defer subject.Close()
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 58*time.Second && ttl <= time.Minute)
+	assertEqual(t, 0, store.Len()) // held only in memory.
+
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, int64(1), stats.Hits)
+	assertTrue(t, stats.MaxMemory > 0)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+
+	stats, err = subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(0), stats.Keys)
+	assertEqual(t, int64(1), stats.Misses)
+}
+
+func testTieredSpilledEntriesAreServedFromDisk(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		disk, _ = newTieredDisk(t)
+		subject = xcache.NewTiered(disk, xcache.TieredConfig{MemSize: 512 * 1024, SpillRatio: 1})
+		ctx     = context.Background()
+		value   = bytes.Repeat([]byte("v"), 256)
+	)
+	defer subject.Close()
+	for i := 0; i < 100; i++ {
+		requireNil(t, subject.Save(ctx, "test-tiered-spilled-key-"+strconv.Itoa(i), value, xcache.NoExpire))
+	}
+
+	// act
+	requireNil(t, subject.Spill(ctx))
+	for i := 0; i < 10000; i++ { // evict the spilled entries from memory.
+		_ = subject.Save(ctx, "test-tiered-hot-key-"+strconv.Itoa(i), value, time.Minute)
+	}
+
+	// assert
+	for i := 0; i < 100; i++ {
+		loadedValue, err := subject.Load(ctx, "test-tiered-spilled-key-"+strconv.Itoa(i))
+		assertNil(t, err)
+		assertEqual(t, value, loadedValue)
+	}
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertTrue(t, metrics["tiered.disk_hits"] > 0)
+	assertTrue(t, metrics["tiered.spilled"] >= 100)
+	ttl, err := subject.TTL(ctx, "test-tiered-spilled-key-0")
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+}
+
+func testTieredLargeEntriesOverflowToDisk(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		disk, store = newTieredDisk(t)
+		subject     = xcache.NewTiered(disk, xcache.TieredConfig{MemSize: 512 * 1024})
+		ctx         = context.Background()
+		key         = "test-tiered-large-key"
+		value       = bytes.Repeat([]byte("v"), 1024) // > 1/1024 of memory.
+	)
+	defer subject.Close()
+
+	// act
+	err := subject.Save(ctx, key, value, time.Minute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 2, store.Len()) // value + TTL index entry.
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 58*time.Second && ttl <= time.Minute)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, float64(1), metrics["tiered.overflowed"])
+}
+
+func testTieredSaveRemovesStaleDiskCopy(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		disk, _ = newTieredDisk(t)
+		subject = xcache.NewTiered(disk, xcache.TieredConfig{})
+		ctx     = context.Background()
+		key     = "test-tiered-stale-key"
+	)
+	defer subject.Close()
+	requireNil(t, subject.Save(ctx, key, []byte("value 1"), xcache.NoExpire))
+	requireNil(t, subject.Spill(ctx))
+	_, err := disk.Load(ctx, key)
+	requireNil(t, err)
+
+	// act
+	err = subject.Save(ctx, key, []byte("value 2"), xcache.NoExpire)
+
+	// assert
+	assertNil(t, err)
+	_, err = disk.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, []byte("value 2"), loadedValue)
+}
+
+func testTieredSpillingStartsInBackground(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		disk, _ = newTieredDisk(t)
+		subject = xcache.NewTiered(disk, xcache.TieredConfig{MemSize: 4 * 1024 * 1024, SpillRatio: 0.1})
+		ctx     = context.Background()
+		value   = bytes.Repeat([]byte("v"), 256)
+	)
+	defer subject.Close()
+
+	// act
+	for i := 0; i < 2000; i++ { // ~560KB, above the 10% spilling threshold.
+		requireNil(t, subject.Save(ctx, "test-tiered-bg-key-"+strconv.Itoa(i), value, time.Minute))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// assert
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertTrue(t, metrics["tiered.spilled"] > 0)
+	stats, err := disk.Stats(ctx)
+	assertNil(t, err)
+	assertTrue(t, stats.Keys > 0)
+	stats, err = subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(2000), stats.Keys)
+}
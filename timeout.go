@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TimeLimited is a Cache decorator which bounds in time each operation whose context has no deadline,
+// with a per operation timeout, so a forgotten deadline upstream does not let a hung (usually remote) cache
+// stall requests indefinitely. Contexts already having a deadline are passed as they are.
+// A timed out operation returns the decorated cache's error (which is a context.DeadlineExceeded one,
+// or a network timeout, usually).
+//
+// Example:
+//
+//	cache := xcache.WithTimeout(redisCache, 100*time.Millisecond, 50*time.Millisecond, 50*time.Millisecond)
+type TimeLimited struct {
+	cache       Cache
+	saveTimeout time.Duration
+	loadTimeout time.Duration
+	ttlTimeout  time.Duration
+	bounded     int64
+}
+
+// WithTimeout returns a TimeLimited cache which decorates given cache, deriving a context with given timeout
+// for Save / Load / TTL operations whose context has no deadline. A non-positive timeout leaves the
+// operation's context as it is.
+func WithTimeout(cache Cache, saveTimeout, loadTimeout, ttlTimeout time.Duration) *TimeLimited {
+	return &TimeLimited{
+		cache:       cache,
+		saveTimeout: saveTimeout,
+		loadTimeout: loadTimeout,
+		ttlTimeout:  ttlTimeout,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache,
+// within the save timeout, if the context has no deadline.
+func (cache *TimeLimited) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	ctx, cancel := cache.bound(ctx, cache.saveTimeout)
+	defer cancel()
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache, within the load timeout, if the context has no deadline.
+func (cache *TimeLimited) Load(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := cache.bound(ctx, cache.loadTimeout)
+	defer cancel()
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache, within the TTL timeout,
+// if the context has no deadline.
+func (cache *TimeLimited) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ctx, cancel := cache.bound(ctx, cache.ttlTimeout)
+	defer cancel()
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *TimeLimited) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *TimeLimited) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of operations which were given a deadline, as "timeout.bounded" metric.
+func (cache *TimeLimited) ContributeStats(add func(name string, value float64)) {
+	add("timeout.bounded", float64(atomic.LoadInt64(&cache.bounded)))
+}
+
+// bound returns a context derived from given one, with given timeout, if it has no deadline.
+func (cache *TimeLimited) bound(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	atomic.AddInt64(&cache.bounded, 1)
+
+	return context.WithTimeout(ctx, timeout)
+}
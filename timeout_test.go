@@ -0,0 +1,128 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.TimeLimited)(nil)            // test TimeLimited is a Cache
+	var _ xcache.StatsContributor = (*xcache.TimeLimited)(nil) // test TimeLimited is a StatsContributor
+}
+
+func TestTimeLimited(t *testing.T) {
+	t.Parallel()
+
+	t.Run("context without deadline is bounded", testTimeLimitedContextWithoutDeadlineIsBounded)
+	t.Run("context with deadline is kept", testTimeLimitedContextWithDeadlineIsKept)
+	t.Run("hung operation times out", testTimeLimitedHungOperationTimesOut)
+}
+
+func testTimeLimitedContextWithoutDeadlineIsBounded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = new(xcache.Mock)
+		subject   = xcache.WithTimeout(cache, time.Second, 2*time.Second, 0)
+		ctx       = context.Background()
+		key       = "test-timeout-key"
+		deadlines = make(map[string]time.Duration)
+	)
+	cache.SetSaveCallback(func(ctx context.Context, _ string, _ []byte, _ time.Duration) error {
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines["save"] = time.Until(deadline)
+		}
+
+		return nil
+	})
+	cache.SetLoadCallback(func(ctx context.Context, _ string) ([]byte, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines["load"] = time.Until(deadline)
+		}
+
+		return nil, xcache.ErrNotFound
+	})
+	cache.SetTTLCallback(func(ctx context.Context, _ string) (time.Duration, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines["ttl"] = time.Until(deadline)
+		}
+
+		return -1, nil
+	})
+
+	// act
+	_ = subject.Save(ctx, key, []byte("test value"), time.Minute)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.TTL(ctx, key)
+
+	// assert
+	if assertEqual(t, 2, len(deadlines)) {
+		assertTrue(t, deadlines["save"] > 900*time.Millisecond && deadlines["save"] <= time.Second)
+		assertTrue(t, deadlines["load"] > 1900*time.Millisecond && deadlines["load"] <= 2*time.Second)
+	}
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"timeout.bounded": 2}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testTimeLimitedContextWithDeadlineIsKept(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = new(xcache.Mock)
+		subject     = xcache.WithTimeout(cache, time.Second, time.Second, time.Second)
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		key         = "test-timeout-deadline-key"
+	)
+	defer cancel()
+	cache.SetLoadCallback(func(ctx context.Context, _ string) ([]byte, error) {
+		deadline, _ := ctx.Deadline()
+		assertTrue(t, time.Until(deadline) > 59*time.Second)
+
+		return nil, xcache.ErrNotFound
+	})
+
+	// act
+	_, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertEqual(t, 1, cache.LoadCallsCount())
+}
+
+func testTimeLimitedHungOperationTimesOut(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.WithTimeout(cache, 0, 20*time.Millisecond, 0)
+		ctx     = context.Background()
+	)
+	cache.SetLoadCallback(func(ctx context.Context, _ string) ([]byte, error) {
+		<-ctx.Done() // simulate a hung connection.
+
+		return nil, ctx.Err()
+	})
+
+	// act
+	start := time.Now()
+	_, err := subject.Load(ctx, "test-timeout-hung-key")
+	elapsed := time.Since(start)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertTrue(t, elapsed < time.Second)
+}
@@ -0,0 +1,496 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xerr"
+)
+
+// TopologyCfgKey is the key under which xconf.Config expects the topology document, see NewTopology.
+const TopologyCfgKey = "xcache.topology"
+
+// Keys of a topology document.
+const (
+	topologyKeyMulti             = "multi"
+	topologyKeyMemory            = "memory"
+	topologyKeyRedis6            = "redis6"
+	topologyKeyRedis7            = "redis7"
+	topologyKeyNop               = "nop"
+	topologyKeyCompress          = "compress"
+	topologyKeyCompressThreshold = "compressthreshold"
+	topologyKeyTTI               = "tti"
+	topologyKeyTTLFactor         = "ttlfactor"
+	topologyKeyDeadline          = "deadline"
+	topologyKeyMemorySize        = "size"
+
+	// topologyKeyPrefix is the prefix under which the current document's keys are read.
+	topologyKeyPrefix = "topology."
+)
+
+var (
+	errTopologyKeyUnknown = errors.New("unknown key")
+	errTopologyBackend    = errors.New("exactly one of memory, redis6, redis7, nop keys expected")
+)
+
+// topologyBackends holds the keys of a layer's backend.
+var topologyBackends = [...]string{topologyKeyMemory, topologyKeyRedis6, topologyKeyRedis7, topologyKeyNop}
+
+// topologyLayerKeys holds the keys a layer document can have.
+var topologyLayerKeys = map[string]struct{}{
+	topologyKeyMemory:            {},
+	topologyKeyRedis6:            {},
+	topologyKeyRedis7:            {},
+	topologyKeyNop:               {},
+	topologyKeyCompress:          {},
+	topologyKeyCompressThreshold: {},
+	topologyKeyTTI:               {},
+	topologyKeyTTLFactor:         {},
+	topologyKeyDeadline:          {},
+}
+
+// compressionNames maps the compression algorithms names used in a topology document to their identifiers.
+var compressionNames = map[string]uint8{
+	"gzip":   CompressionGzip,
+	"snappy": CompressionSnappy,
+	"zstd":   CompressionZstd,
+}
+
+// Topology is a cache pipeline (layers and their decorators) materialized from a topology document,
+// see NewTopology.
+type Topology struct {
+	// Cache is the materialized pipeline: a Multi cache, or a single layer.
+	Cache
+	closers []io.Closer
+}
+
+// NewTopology materializes the cache pipeline described by the topology document taken from a xconf.Config,
+// so that cache stacks can be standardized across services from configuration alone.
+//
+// The key under which the document is expected is "xcache.topology" (see TopologyCfgKey).
+// The document describes a single layer, or a Multi cache's layers, under "multi" key, ordered from
+// the fastest to the slowest. A layer has exactly one backend key, holding its settings:
+//   - "memory": a Memory cache, with its "size" in bytes (a number, or a string like "64MB"), 10M by default;
+//   - "redis6" / "redis7": a Redis6 / Redis7 cache, with the settings named like RedisCfgKey* keys,
+//     without "xcache.redis." prefix (like "addrs", "db", "auth": {"username", "password"}, "timeout": {"dial"});
+//   - "nop": a Nop cache.
+//
+// and, optionally, decorators' settings, applied in this order:
+//   - "compress": the compression algorithm name ("gzip", "snappy", "zstd") the layer is Compressed with.
+//     Gzip, snappy and zstd are available by default (with default levels), given Compressor(s) replace them.
+//     All compressors are used as decompressors, too, so the algorithm can be switched;
+//   - "compressthreshold": the size in bytes below which values are not compressed;
+//   - "tti": the idle period the layer has TimeToIdle semantics with;
+//   - "ttlfactor": a factor the expiration periods of the saved keys are multiplied with
+//     (a local layer usually keeps keys for a fraction of the remote layer's period);
+//   - "deadline": the budget below which the layer is skipped (it is DeadlineAware).
+//
+// Example (YAML):
+//
+//	xcache:
+//	  durationunit: ms
+//	  topology:
+//	    multi:
+//	      - memory:
+//	          size: 64MB
+//	        ttlfactor: 0.1
+//	      - redis7:
+//	          addrs: [redis-1:6379, redis-2:6379]
+//	          auth:
+//	            password: secret
+//	        compress: zstd
+//	        compressthreshold: 1KB
+//	        deadline: 5
+//
+// Durations can be given as duration strings (like "10m"), or as numbers expressed in milliseconds
+// (or in the unit configured under CfgKeyDurationUnit key).
+// Unlike the caches initialized with NewMemoryWithConfig / NewRedis7WithConfig,
+// the pipeline is not reconfigured when the document changes.
+//
+// If the document is invalid, an error aggregating a ConfigError (having the document path of
+// the invalid value as Key, like "xcache.topology.multi.1.redis7.db") for each invalid value is returned,
+// and topology is nil.
+func NewTopology(config xconf.Config, compressors ...Compressor) (*Topology, error) {
+	parser := newTopologyParser(config, compressors)
+	layers := parser.parse()
+	if err := parser.r.Err(); err != nil {
+		return nil, err
+	}
+
+	topology := new(Topology)
+	caches := make([]Cache, 0, len(layers))
+	for _, layer := range layers {
+		caches = append(caches, topology.build(layer))
+	}
+	if layers[0].path == TopologyCfgKey { // single layer.
+		topology.Cache = caches[0]
+	} else {
+		topology.Cache = NewMulti(caches...)
+	}
+
+	return topology, nil
+}
+
+// Close closes the Redis clients of the topology's layers.
+func (topology *Topology) Close() error {
+	var mErr *xerr.MultiError
+	for _, closer := range topology.closers {
+		mErr = mErr.Add(closer.Close())
+	}
+
+	return mErr.ErrOrNil()
+}
+
+// build materializes given layer.
+func (topology *Topology) build(layer topologyLayer) Cache {
+	var cache Cache
+	switch layer.backend {
+	case topologyKeyMemory:
+		cache = NewMemory(layer.memSize)
+	case topologyKeyRedis6:
+		redis := NewRedis6(layer.redisConfig)
+		topology.closers = append(topology.closers, redis)
+		cache = redis
+	case topologyKeyRedis7:
+		redis := NewRedis7(layer.redisConfig)
+		topology.closers = append(topology.closers, redis)
+		cache = redis
+	default:
+		cache = Nop{}
+	}
+
+	if layer.compressor != nil {
+		cache = NewCompressed(cache, layer.compressor).
+			WithThreshold(layer.compressThreshold).
+			WithDecompressors(layer.decompressors...)
+	}
+	if layer.tti > 0 {
+		cache = NewTimeToIdle(cache, layer.tti)
+	}
+	if layer.ttlFactor > 0 && layer.ttlFactor != 1 {
+		cache = &ttlScaled{cache: cache, factor: layer.ttlFactor}
+	}
+	if layer.deadline > 0 {
+		cache = NewDeadlineAware(cache, layer.deadline)
+	}
+
+	return cache
+}
+
+// topologyLayer is the specification of a topology's layer.
+type topologyLayer struct {
+	path              string // the document path of the layer.
+	backend           string
+	memSize           int
+	redisConfig       RedisConfig
+	compressor        Compressor
+	decompressors     []Compressor
+	compressThreshold int
+	tti               time.Duration
+	ttlFactor         float64
+	deadline          time.Duration
+}
+
+// topologyParser parses a topology document into layers' specifications.
+type topologyParser struct {
+	view        *topologyView
+	r           *xconfReader
+	compressors map[uint8]Compressor
+}
+
+// newTopologyParser instantiates a new topologyParser for the topology document taken from given config.
+func newTopologyParser(config xconf.Config, compressors []Compressor) *topologyParser {
+	view := &topologyView{root: config}
+	parser := &topologyParser{
+		view: view,
+		r: &xconfReader{
+			config:  view,
+			keyName: view.keyName,
+		},
+		compressors: map[uint8]Compressor{
+			CompressionGzip:   NewGzipCompressor(0),
+			CompressionSnappy: NewSnappyCompressor(),
+			CompressionZstd:   NewZstdCompressor(0),
+		},
+	}
+	for _, compressor := range compressors {
+		parser.compressors[compressor.Algorithm()] = compressor
+	}
+
+	return parser
+}
+
+// parse returns the specifications of the document's layers.
+// Errors are collected by parser's reader.
+func (parser *topologyParser) parse() []topologyLayer {
+	doc, ok := parser.document(TopologyCfgKey, getDocument(parser.view.root, TopologyCfgKey))
+	if !ok {
+		return nil
+	}
+	multi, found := doc[topologyKeyMulti]
+	if !found {
+		return []topologyLayer{parser.parseLayer(TopologyCfgKey, doc)}
+	}
+
+	path := TopologyCfgKey + "." + topologyKeyMulti
+	for _, key := range sortedKeys(doc) {
+		if key != topologyKeyMulti {
+			parser.r.addErr(TopologyCfgKey+"."+key, errTopologyKeyUnknown)
+		}
+	}
+	layersDocs, ok := multi.([]any)
+	if !ok {
+		parser.r.addValueErr(path, multi, errConfigValueType)
+
+		return nil
+	}
+	if len(layersDocs) == 0 {
+		parser.r.addErr(path, errConfigValueEmpty)
+
+		return nil
+	}
+	layers := make([]topologyLayer, 0, len(layersDocs))
+	for idx, layerDoc := range layersDocs {
+		layerPath := path + "." + strconv.Itoa(idx)
+		if doc, ok := parser.document(layerPath, layerDoc); ok {
+			layers = append(layers, parser.parseLayer(layerPath, doc))
+		}
+	}
+
+	return layers
+}
+
+// parseLayer returns the specification of the layer described by given document.
+func (parser *topologyParser) parseLayer(path string, doc map[string]any) topologyLayer {
+	layer := topologyLayer{path: path}
+	for _, key := range sortedKeys(doc) {
+		if _, known := topologyLayerKeys[key]; !known {
+			parser.r.addErr(path+"."+key, errTopologyKeyUnknown)
+		}
+	}
+	for _, backend := range topologyBackends {
+		if _, found := doc[backend]; found {
+			if layer.backend != "" {
+				layer.backend = ""
+
+				break
+			}
+			layer.backend = backend
+		}
+	}
+	if layer.backend == "" {
+		parser.r.addErr(path, errTopologyBackend)
+
+		return layer
+	}
+
+	parser.parseBackend(&layer, doc[layer.backend])
+
+	parser.view.setDocument(topologyKeyPrefix, path, doc)
+	if name := parser.r.String(topologyKeyPrefix+topologyKeyCompress, ""); name != "" {
+		layer.compressor = parser.compressors[compressionNames[strings.ToLower(name)]]
+		if layer.compressor == nil {
+			parser.r.addErr(topologyKeyPrefix+topologyKeyCompress, ErrUnsupportedCompression)
+		}
+		for _, compressor := range parser.compressors {
+			layer.decompressors = append(layer.decompressors, compressor)
+		}
+	}
+	layer.compressThreshold = parser.r.ByteSize(topologyKeyPrefix+topologyKeyCompressThreshold, 0)
+	layer.tti = parser.r.Duration(topologyKeyPrefix+topologyKeyTTI, 0)
+	layer.ttlFactor = parser.r.Float64(topologyKeyPrefix+topologyKeyTTLFactor, 0)
+	layer.deadline = parser.r.Duration(topologyKeyPrefix+topologyKeyDeadline, 0)
+	if layer.compressThreshold < 0 {
+		parser.r.addErr(topologyKeyPrefix+topologyKeyCompressThreshold, errConfigValueRange)
+	}
+	if layer.tti < 0 {
+		parser.r.addErr(topologyKeyPrefix+topologyKeyTTI, errConfigValueRange)
+	}
+	if layer.ttlFactor < 0 {
+		parser.r.addErr(topologyKeyPrefix+topologyKeyTTLFactor, errConfigValueRange)
+	}
+	if layer.deadline < 0 {
+		parser.r.addErr(topologyKeyPrefix+topologyKeyDeadline, errConfigValueRange)
+	}
+
+	return layer
+}
+
+// parseBackend reads the settings of given layer's backend from given document.
+func (parser *topologyParser) parseBackend(layer *topologyLayer, backendDoc any) {
+	path := layer.path + "." + layer.backend
+	if layer.backend == topologyKeyNop {
+		return
+	}
+	doc := map[string]any{}
+	if backendDoc != nil {
+		var ok bool
+		if doc, ok = parser.document(path, backendDoc); !ok {
+			return
+		}
+	}
+
+	switch layer.backend {
+	case topologyKeyMemory:
+		parser.view.setDocument(topologyKeyPrefix, path, doc)
+		for _, key := range sortedKeys(doc) {
+			if key != topologyKeyMemorySize {
+				parser.r.addErr(topologyKeyPrefix+key, errTopologyKeyUnknown)
+			}
+		}
+		layer.memSize = parser.r.ByteSize(topologyKeyPrefix+topologyKeyMemorySize, memoryCfgDefValueMemorySize)
+		if layer.memSize < 0 {
+			parser.r.addErr(topologyKeyPrefix+topologyKeyMemorySize, errConfigValueRange)
+		}
+	default:
+		parser.view.setDocument(redisCfgKeyPrefix, path, doc)
+		layer.redisConfig = readRedisConfig(parser.r)
+	}
+}
+
+// document returns given value as a (sub)document, or reports a ConfigError for given path.
+func (parser *topologyParser) document(path string, value any) (map[string]any, bool) {
+	switch doc := value.(type) {
+	case map[string]any:
+		return doc, true
+	case nil:
+		parser.r.addErr(path, errConfigValueEmpty)
+	default:
+		parser.r.addValueErr(path, value, errConfigValueType)
+	}
+
+	return nil, false
+}
+
+// redisCfgKeyPrefix is the common prefix of RedisCfgKey* keys.
+const redisCfgKeyPrefix = "xcache.redis."
+
+// topologyView is a xconf.Config view over a (sub)document of the topology,
+// exposing its values under prefix + their path within the document (like "xcache.redis." + "auth.username"),
+// so the readers of other xconf adapters can be reused.
+// Keys not having the prefix (like CfgKeyDurationUnit) are taken from the root config.
+type topologyView struct {
+	root   xconf.Config
+	prefix string
+	path   string
+	doc    map[string]any
+}
+
+// setDocument sets the document exposed under given prefix, having given path within the topology.
+func (view *topologyView) setDocument(prefix, path string, doc map[string]any) {
+	view.prefix, view.path, view.doc = prefix, path, doc
+}
+
+// Get returns the value for given key.
+func (view *topologyView) Get(key string, def ...any) any {
+	var value any
+	if view.prefix != "" && strings.HasPrefix(key, view.prefix) {
+		value = lookupDocument(view.doc, key[len(view.prefix):])
+	} else {
+		value = view.root.Get(key)
+	}
+	if value == nil && len(def) > 0 {
+		return def[0]
+	}
+
+	return value
+}
+
+// keyName returns the name under which given key is reported in errors, its topology path.
+func (view *topologyView) keyName(key string) string {
+	if view.prefix != "" && strings.HasPrefix(key, view.prefix) {
+		return view.path + "." + key[len(view.prefix):]
+	}
+
+	return key
+}
+
+// getDocument returns the value of given key from given config.
+// If the key is not found, it's looked up within the documents of its parent keys, too
+// (like "topology" within the document under "xcache" key), as loaders usually keep the nesting of documents.
+func getDocument(config xconf.Config, key string) any {
+	if value := config.Get(key); value != nil {
+		return value
+	}
+	for idx := 0; idx < len(key); idx++ {
+		if key[idx] != '.' {
+			continue
+		}
+		if doc, ok := config.Get(key[:idx]).(map[string]any); ok {
+			if value := lookupDocument(doc, key[idx+1:]); value != nil {
+				return value
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupDocument returns the value found at given (dot separated) path within given document.
+// Flat keys (like "auth.username") are looked up first, then nested documents.
+func lookupDocument(doc map[string]any, path string) any {
+	if value, found := doc[path]; found {
+		return value
+	}
+	head, tail, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+	subDoc, ok := doc[head].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return lookupDocument(subDoc, tail)
+}
+
+// ttlScaled is a Cache decorator which multiplies the expiration periods of saved keys with a factor.
+type ttlScaled struct {
+	cache  Cache
+	factor float64
+}
+
+// Save stores the given key-value into decorated cache, with the expiration period multiplied with the factor
+// (at least a millisecond). NoExpire is kept as it is.
+func (cache *ttlScaled) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire > 0 {
+		expire = max(time.Duration(float64(expire)*cache.factor), time.Millisecond)
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+func (cache *ttlScaled) Load(ctx context.Context, key string) ([]byte, error) {
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *ttlScaled) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *ttlScaled) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *ttlScaled) Unwrap() Cache {
+	return cache.cache
+}
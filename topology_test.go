@@ -0,0 +1,186 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xconf"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestNewTopology(t *testing.T) {
+	t.Parallel()
+
+	t.Run("multi layers are materialized", testNewTopologyMultiLayersAreMaterialized)
+	t.Run("single layer is materialized", testNewTopologySingleLayerIsMaterialized)
+	t.Run("invalid document", testNewTopologyInvalidDocument)
+}
+
+func testNewTopologyMultiLayersAreMaterialized(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		doc = `
+xcache:
+  topology:
+    multi:
+      - memory:
+          size: 8MB
+        ttlfactor: 0.1
+      - memory:
+          size: 8388608
+        compress: zstd # available by default.
+        compressthreshold: 1KB
+        tti: 1h
+`
+		config, _ = xconf.NewDefaultConfig(xconf.YAMLReaderLoader(strings.NewReader(doc)))
+		ctx       = context.Background()
+		key       = "test-topology-key"
+		value     = bytes.Repeat([]byte("test topology value "), 100)
+	)
+
+	// act
+	subject, err := xcache.NewTopology(config)
+
+	// assert
+	requireNil(t, err)
+	_, isMulti := subject.Cache.(xcache.Multi)
+	assertTrue(t, isMulti)
+	requireNil(t, subject.Save(ctx, key, value, 10*time.Minute))
+	result, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	ttl, err := subject.TTL(ctx, key) // memory layer's TTL.
+	assertNil(t, err)
+	assertTrue(t, ttl > 0 && ttl <= time.Minute)
+	stats, err := subject.Cache.(xcache.Multi).StatsSnapshot(ctx, time.Second)
+	requireNil(t, err)
+	assertEqual(t, 2, len(stats.Layers))
+	assertEqual(t, int64(8*1024*1024), stats.Layers[0].MaxMemory)
+	assertTrue(t, stats.Layers[1].Memory < int64(len(value))) // value is stored compressed.
+	assertNil(t, subject.Close())
+}
+
+func testNewTopologySingleLayerIsMaterialized(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	config := xconf.NewMockConfig(
+		xcache.TopologyCfgKey, map[string]any{
+			"redis7": map[string]any{
+				"addrs": "127.0.0.1:6379",
+				"auth": map[string]any{
+					"password": "secret",
+				},
+				"timeout.dial": "1s",
+			},
+			"deadline": 5,
+		},
+		xcache.CfgKeyDurationUnit, "ms",
+	)
+
+	// act
+	subject, err := xcache.NewTopology(config)
+
+	// assert
+	requireNil(t, err)
+	deadlineAware, ok := subject.Cache.(*xcache.DeadlineAware)
+	if !assertTrue(t, ok) {
+		return
+	}
+	_, ok = deadlineAware.Unwrap().(*xcache.Redis7)
+	assertTrue(t, ok)
+	assertNil(t, subject.Close())
+}
+
+func testNewTopologyInvalidDocument(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		doc             any
+		expectedErrKeys []string
+	}{
+		{
+			name:            "missing document",
+			expectedErrKeys: []string{"xcache.topology"},
+		},
+		{
+			name:            "multi is not a list",
+			doc:             map[string]any{"multi": "memory"},
+			expectedErrKeys: []string{"xcache.topology.multi"},
+		},
+		{
+			name:            "empty multi",
+			doc:             map[string]any{"multi": []any{}},
+			expectedErrKeys: []string{"xcache.topology.multi"},
+		},
+		{
+			name: "invalid backends",
+			doc: map[string]any{"multi": []any{
+				map[string]any{"compress": "gzip"},
+				map[string]any{"memory": nil, "nop": nil},
+				"redis7",
+			}},
+			expectedErrKeys: []string{
+				"xcache.topology.multi.0",
+				"xcache.topology.multi.1",
+				"xcache.topology.multi.2",
+			},
+		},
+		{
+			name: "invalid settings",
+			doc: map[string]any{"multi": []any{
+				map[string]any{
+					"memory":    map[string]any{"size": "64XB", "ttl": "1m"},
+					"ttlfactor": -0.1,
+					"unknown":   true,
+				},
+				map[string]any{
+					"redis7":   map[string]any{"db": "x"},
+					"compress": "lz4",
+					"tti":      "1 hour",
+				},
+			}},
+			expectedErrKeys: []string{
+				"xcache.topology.multi.0.unknown",
+				"xcache.topology.multi.0.memory.ttl",
+				"xcache.topology.multi.0.memory.size",
+				"xcache.topology.multi.0.ttlfactor",
+				"xcache.topology.multi.1.redis7.db",
+				"xcache.topology.multi.1.compress",
+				"xcache.topology.multi.1.tti",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			config := xconf.NewMockConfig()
+			if test.doc != nil {
+				config = xconf.NewMockConfig(xcache.TopologyCfgKey, test.doc)
+			}
+
+			// act
+			subject, err := xcache.NewTopology(config)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, err)
+			assertTrue(t, subject == nil)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attributes of the spans created by a Traced cache.
+const (
+	// SpanAttrBackend is the attribute holding the decorated cache's backend (like "memory", "redis7", "multi").
+	SpanAttrBackend = "cache.backend"
+	// SpanAttrKeyHash is the attribute holding the hex encoded FNV-1a 64 hash of the key
+	// (keys are not recorded as they are, they may hold sensitive data).
+	SpanAttrKeyHash = "cache.key_hash"
+	// SpanAttrHit is the attribute holding whether a loaded key was found.
+	SpanAttrHit = "cache.hit"
+	// SpanAttrBytes is the attribute holding the size of the saved / loaded value.
+	SpanAttrBytes = "cache.bytes"
+	// SpanAttrExpire is the attribute holding the expiration period of a saved key, in seconds.
+	SpanAttrExpire = "cache.expire_seconds"
+)
+
+// SpanAttribute is an attribute (key-value) of a span.
+type SpanAttribute struct {
+	Key   string
+	Value any // string / bool / int64 / float64.
+}
+
+// Span is a tracing span, the subset of a tracing library's span (like OpenTelemetry's trace.Span)
+// a Traced cache uses.
+type Span interface {
+	// SetAttributes sets given attributes on the span.
+	SetAttributes(attrs ...SpanAttribute)
+	// RecordError records given error on the span, and marks the span as failed.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Tracer starts tracing spans. It's the contract a Traced cache needs from a tracing library.
+// An OpenTelemetry based implementation is provided by xcacheotel subpackage.
+type Tracer interface {
+	// Start starts a span with given name, returning it, and a context holding it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Traced is a Cache decorator which creates a span for each operation (named "xcache.save", "xcache.load",
+// "xcache.ttl", "xcache.stats"), through a Tracer, having as attributes the backend, the key's hash,
+// whether the key was found (hit / miss), and the value's size (see SpanAttr* constants),
+// and records operations' errors (ErrNotFound is not an error), so cache latency shows up in distributed traces
+// next to database and HTTP spans.
+//
+// Example:
+//
+//	cache := xcache.NewTraced(redisCache, xcacheotel.NewTracer(otelTracerProvider), "")
+type Traced struct {
+	cache   Cache
+	tracer  Tracer
+	backend string
+}
+
+// NewTraced instantiates a new Traced which decorates given cache, creating spans through given tracer.
+// An empty backend is replaced with the name of the innermost decorated cache's type (like "redis7").
+func NewTraced(cache Cache, tracer Tracer, backend string) *Traced {
+	if backend == "" {
+		backend = backendName(cache)
+	}
+
+	return &Traced{
+		cache:   cache,
+		tracer:  tracer,
+		backend: backend,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache, within a span.
+func (cache *Traced) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	ctx, span := cache.start(ctx, "xcache.save", key)
+	defer span.End()
+
+	span.SetAttributes(
+		SpanAttribute{Key: SpanAttrBytes, Value: int64(len(value))},
+		SpanAttribute{Key: SpanAttrExpire, Value: expire.Seconds()},
+	)
+	err := cache.cache.Save(ctx, key, value, expire)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// Load returns a key's value from decorated cache, within a span.
+func (cache *Traced) Load(ctx context.Context, key string) ([]byte, error) {
+	ctx, span := cache.start(ctx, "xcache.load", key)
+	defer span.End()
+
+	value, err := cache.cache.Load(ctx, key)
+	switch {
+	case err == nil:
+		span.SetAttributes(
+			SpanAttribute{Key: SpanAttrHit, Value: true},
+			SpanAttribute{Key: SpanAttrBytes, Value: int64(len(value))},
+		)
+	case errors.Is(err, ErrNotFound):
+		span.SetAttributes(SpanAttribute{Key: SpanAttrHit, Value: false})
+	default:
+		span.RecordError(err)
+	}
+
+	return value, err
+}
+
+// TTL returns a key's remaining time to live from decorated cache, within a span.
+func (cache *Traced) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ctx, span := cache.start(ctx, "xcache.ttl", key)
+	defer span.End()
+
+	ttl, err := cache.cache.TTL(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return ttl, err
+}
+
+// Stats returns decorated cache's statistics, within a span.
+func (cache *Traced) Stats(ctx context.Context) (Stats, error) {
+	ctx, span := cache.start(ctx, "xcache.stats", "")
+	defer span.End()
+
+	stats, err := cache.cache.Stats(ctx)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return stats, err
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Traced) Unwrap() Cache {
+	return cache.cache
+}
+
+// start starts a span with given name, having the backend and given key's hash as attributes.
+func (cache *Traced) start(ctx context.Context, name, key string) (context.Context, Span) {
+	ctx, span := cache.tracer.Start(ctx, name)
+	attrs := [2]SpanAttribute{{Key: SpanAttrBackend, Value: cache.backend}}
+	if key == "" {
+		span.SetAttributes(attrs[:1]...)
+	} else {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		attrs[1] = SpanAttribute{Key: SpanAttrKeyHash, Value: strconv.FormatUint(h.Sum64(), 16)}
+		span.SetAttributes(attrs[:]...)
+	}
+
+	return ctx, span
+}
+
+// backendName returns the lower cased name of the type of the innermost cache decorated by given cache
+// (like "redis7", "memory", "multi").
+func backendName(cache Cache) string {
+	for {
+		unwrapper, ok := cache.(Unwrapper)
+		if !ok {
+			break
+		}
+		cache = unwrapper.Unwrap()
+	}
+	name := fmt.Sprintf("%T", cache)
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return strings.ToLower(name)
+}
@@ -0,0 +1,176 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Traced)(nil) // test Traced is a Cache
+}
+
+func TestTraced(t *testing.T) {
+	t.Parallel()
+
+	t.Run("operations are traced", testTracedOperationsAreTraced)
+	t.Run("errors are recorded", testTracedErrorsAreRecorded)
+	t.Run("backend is detected", testTracedBackendIsDetected)
+}
+
+func testTracedOperationsAreTraced(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		tracer  = new(spanRecorder)
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewTraced(cache, tracer, "local")
+		ctx     = context.Background()
+		key     = "test-traced-key"
+		keyHash = "e43b3569592972fd" // FNV-1a 64 of key.
+		value   = []byte("test value")
+	)
+
+	// act
+	_ = subject.Save(ctx, key, value, time.Minute)
+	_, _ = subject.Load(ctx, key)
+	_, _ = subject.Load(ctx, "test-traced-missing-key")
+	_, _ = subject.TTL(ctx, key)
+	_, _ = subject.Stats(ctx)
+
+	// assert
+	spans := tracer.Spans()
+	if assertEqual(t, 5, len(spans)) {
+		assertEqual(t, "xcache.save", spans[0].name)
+		assertEqual(
+			t,
+			map[string]any{
+				xcache.SpanAttrBackend: "local",
+				xcache.SpanAttrKeyHash: keyHash,
+				xcache.SpanAttrBytes:   int64(len(value)),
+				xcache.SpanAttrExpire:  float64(60),
+			},
+			spans[0].attrs,
+		)
+		assertEqual(t, "xcache.load", spans[1].name)
+		assertEqual(t, true, spans[1].attrs[xcache.SpanAttrHit])
+		assertEqual(t, int64(len(value)), spans[1].attrs[xcache.SpanAttrBytes])
+		assertEqual(t, "xcache.load", spans[2].name)
+		assertEqual(t, false, spans[2].attrs[xcache.SpanAttrHit])
+		assertNil(t, spans[2].err) // a miss is not an error.
+		assertEqual(t, "xcache.ttl", spans[3].name)
+		assertEqual(t, keyHash, spans[3].attrs[xcache.SpanAttrKeyHash])
+		assertEqual(t, "xcache.stats", spans[4].name)
+		assertEqual(t, map[string]any{xcache.SpanAttrBackend: "local"}, spans[4].attrs)
+		for _, span := range spans {
+			assertTrue(t, span.ended)
+		}
+	}
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testTracedErrorsAreRecorded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		tracer  = new(spanRecorder)
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTraced(cache, tracer, "")
+		ctx     = context.Background()
+		key     = "test-traced-err-key"
+		errLoad = errors.New("connection reset by peer")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errLoad
+	})
+
+	// act
+	_, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, errLoad))
+	spans := tracer.Spans()
+	if assertEqual(t, 1, len(spans)) {
+		assertTrue(t, errors.Is(spans[0].err, errLoad))
+		assertEqual(t, "mock", spans[0].attrs[xcache.SpanAttrBackend])
+		assertTrue(t, spans[0].ended)
+	}
+}
+
+func testTracedBackendIsDetected(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		tracer = new(spanRecorder)
+		memory = xcache.NewMemory(freecacheMinMem)
+		multi  = xcache.NewMulti(memory, xcache.Nop{})
+		ctx    = context.Background()
+	)
+
+	// act
+	_, _ = xcache.NewTraced(xcache.NewMetered(memory), tracer, "").Stats(ctx)
+	_, _ = xcache.NewTraced(multi, tracer, "").Stats(ctx)
+
+	// assert
+	spans := tracer.Spans()
+	if assertEqual(t, 2, len(spans)) {
+		assertEqual(t, "memory", spans[0].attrs[xcache.SpanAttrBackend])
+		assertEqual(t, "multi", spans[1].attrs[xcache.SpanAttrBackend])
+	}
+}
+
+// spanRecorder is a xcache.Tracer which records the spans.
+type spanRecorder struct {
+	spans []*recordedSpan
+	mu    sync.Mutex
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string) (context.Context, xcache.Span) {
+	span := &recordedSpan{name: name, attrs: make(map[string]any)}
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+
+	return ctx, span
+}
+
+func (r *spanRecorder) Spans() []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*recordedSpan(nil), r.spans...)
+}
+
+// recordedSpan is a xcache.Span which records its data.
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...xcache.SpanAttribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
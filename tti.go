@@ -0,0 +1,124 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"time"
+)
+
+// TimeToIdle is a Cache decorator which adds time-to-idle semantics to keys:
+// a key expires if it's not accessed (loaded) for the idle period, regardless of its remaining time to live.
+// Each successful Load extends the key's expiration period with the idle period, without exceeding
+// the expiration period the key was saved with, which acts as a hard cap.
+//
+// The hard cap is stored alongside the value, in an Envelope. Values not having an envelope
+// (saved directly into decorated cache) are returned as they are, and their expiration period is not touched.
+// The expiration period is extended with Touch, if decorated cache is a Toucher, otherwise it's not extended
+// (the value is not saved again, as a concurrent Save could be overwritten by the loaded value),
+// so keys expire after the idle period since they were saved, only the hard cap applying.
+// Failing to extend a key's expiration period does not fail the Load operation.
+//
+// Keys saved with NoExpire have no hard cap: their expiration period slides with each Load,
+// so they live as long as they are accessed (sliding expiration).
+//
+// Example:
+//
+//	sessions := xcache.NewTimeToIdle(redisCache, 30*time.Minute)
+//	// session expires after 30 minutes of inactivity, or after 12 hours anyway.
+//	err := sessions.Save(ctx, sessionID, session, 12*time.Hour)
+type TimeToIdle struct {
+	cache Cache
+	idle  time.Duration
+}
+
+// NewTimeToIdle instantiates a new TimeToIdle which decorates given cache, with given idle period.
+// A non-positive idle period means keys do not expire due to inactivity (only the hard cap applies).
+func NewTimeToIdle(cache Cache, idle time.Duration) *TimeToIdle {
+	return &TimeToIdle{
+		cache: cache,
+		idle:  idle,
+	}
+}
+
+// Save stores the given key-value into decorated cache.
+// The expiration period acts as a hard cap, the key expiring earlier if it's not accessed for the idle period.
+// An expiration period equal to 0 (NoExpire) means no hard cap.
+// A negative expiration period triggers deletion of key.
+func (cache *TimeToIdle) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	if expire < 0 {
+		return cache.cache.Save(ctx, key, value, expire)
+	}
+
+	var env Envelope
+	if expire > 0 {
+		env.ExpireAt = time.Now().Add(expire).UnixMilli()
+	}
+	enveloped := env.Append(make([]byte, 0, env.Size(len(value))), value)
+
+	return cache.cache.Save(ctx, key, enveloped, cache.idleTTL(expire))
+}
+
+// Load returns a key's value from decorated cache, and extends its expiration period with the idle period
+// (without exceeding its hard cap).
+func (cache *TimeToIdle) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil || !IsEnvelope(value) {
+		return value, err
+	}
+
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return value, nil // not a value of ours, return it as it is.
+	}
+
+	var remaining time.Duration
+	if env.ExpireAt > 0 {
+		remaining = time.Until(time.UnixMilli(env.ExpireAt))
+		if remaining <= 0 {
+			return nil, ErrNotFound
+		}
+	}
+	if toucher, ok := cache.cache.(Toucher); ok {
+		if expire := cache.idleTTL(remaining); expire > 0 {
+			_, _ = toucher.Touch(ctx, key, expire)
+		}
+	}
+
+	return payload, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache
+// (until it expires due to inactivity, or due to its hard cap, whichever comes first).
+func (cache *TimeToIdle) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *TimeToIdle) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *TimeToIdle) Unwrap() Cache {
+	return cache.cache
+}
+
+// idleTTL returns the expiration period a key should be saved / touched with,
+// considering the idle period and the remaining time until key's hard cap (0 meaning no hard cap).
+func (cache *TimeToIdle) idleTTL(remaining time.Duration) time.Duration {
+	if cache.idle > 0 && (remaining == 0 || cache.idle < remaining) {
+		return cache.idle
+	}
+
+	return remaining
+}
@@ -0,0 +1,205 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.TimeToIdle)(nil) // test TimeToIdle is a Cache
+}
+
+func TestTimeToIdle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("idle period is applied and extended on load", testTimeToIdleIdlePeriodIsExtendedOnLoad)
+	t.Run("hard cap is not exceeded", testTimeToIdleHardCapIsNotExceeded)
+	t.Run("expired hard cap - not found", testTimeToIdleExpiredHardCapReturnsNotFound)
+	t.Run("value is not saved again - not a toucher", testTimeToIdleValueIsNotSavedAgainForNonToucher)
+	t.Run("value without envelope is returned", testTimeToIdleValueWithoutEnvelopeIsReturned)
+	t.Run("deletion is passed through", testTimeToIdleDeletionIsPassedThrough)
+}
+
+func testTimeToIdleIdlePeriodIsExtendedOnLoad(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(cache, time.Minute)
+		ctx     = context.Background()
+		key     = "test-tti-key"
+		value   = []byte("test value")
+		saved   []byte
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, v []byte, exp time.Duration) error {
+		assertEqual(t, time.Minute, exp)
+		saved = append([]byte(nil), v...)
+
+		return nil
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return saved, nil
+	})
+	cache.SetTouchCallback(func(_ context.Context, k string, exp time.Duration) (bool, error) {
+		assertEqual(t, key, k)
+		assertEqual(t, time.Minute, exp)
+
+		return true, nil
+	})
+
+	// act
+	errSave := subject.Save(ctx, key, value, xcache.NoExpire)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertEqual(t, 1, cache.TouchCallsCount())
+}
+
+func testTimeToIdleHardCapIsNotExceeded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(cache, time.Hour)
+		ctx     = context.Background()
+		key     = "test-tti-hard-cap-key"
+		value   = []byte("test value")
+		saved   []byte
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, v []byte, exp time.Duration) error {
+		assertEqual(t, time.Minute, exp) // hard cap is less than idle period.
+		saved = append([]byte(nil), v...)
+
+		return nil
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return saved, nil
+	})
+	cache.SetTouchCallback(func(_ context.Context, _ string, exp time.Duration) (bool, error) {
+		assertTrue(t, exp > 0)
+		assertTrue(t, exp <= time.Minute)
+
+		return true, nil
+	})
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertEqual(t, 1, cache.TouchCallsCount())
+}
+
+func testTimeToIdleExpiredHardCapReturnsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(cache, time.Hour)
+		ctx     = context.Background()
+		key     = "test-tti-expired-key"
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		env := xcache.Envelope{ExpireAt: time.Now().Add(-time.Second).UnixMilli()}
+
+		return env.Append(nil, []byte("test value")), nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, result)
+	assertEqual(t, 0, cache.TouchCallsCount())
+}
+
+func testTimeToIdleValueIsNotSavedAgainForNonToucher(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(struct{ xcache.Cache }{cache}, time.Minute) // not a Toucher
+		ctx     = context.Background()
+		value   = []byte("test value")
+		env     = xcache.Envelope{ExpireAt: time.Now().Add(time.Hour).UnixMilli()}
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return env.Append(nil, value), nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, "test-tti-non-toucher-key")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertEqual(t, 0, cache.SaveCallsCount())
+	assertEqual(t, 0, cache.TouchCallsCount())
+}
+
+func testTimeToIdleValueWithoutEnvelopeIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(cache, time.Minute)
+		ctx     = context.Background()
+		value   = []byte("test value")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return value, nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, "test-tti-no-envelope-key")
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertEqual(t, 0, cache.TouchCallsCount())
+}
+
+func testTimeToIdleDeletionIsPassedThrough(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTimeToIdle(cache, time.Minute)
+		ctx     = context.Background()
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, v []byte, exp time.Duration) error {
+		assertNil(t, v)
+		assertTrue(t, exp < 0)
+
+		return nil
+	})
+
+	// act
+	err := subject.Save(ctx, "test-tti-delete-key", nil, -1)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}
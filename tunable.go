@@ -0,0 +1,105 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TunableConfig holds the settings of a Tunable cache.
+type TunableConfig struct {
+	// Disabled is a kill switch. If set, the decorated cache is bypassed:
+	// saves are ignored, and loads return ErrNotFound.
+	// Deletions still reach the decorated cache, so no stale data is served after re-enabling it.
+	Disabled bool
+	// DefaultTTL is the expiration period used for keys saved with NoExpire.
+	// A value of 0 keeps NoExpire as it is.
+	DefaultTTL time.Duration
+}
+
+// Tunable is a Cache decorator whose settings can be changed at runtime,
+// see NewTunableWithConfig for having them driven by a xconf.Config.
+type Tunable struct {
+	cache  Cache
+	config TunableConfig
+	mu     sync.RWMutex
+}
+
+// NewTunable instantiates a new Tunable which decorates given cache, with given settings.
+func NewTunable(cache Cache, config TunableConfig) *Tunable {
+	return &Tunable{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// If the cache is disabled, only deletions (negative expiration periods) are performed.
+// If a DefaultTTL is configured, it is used instead of NoExpire.
+func (cache *Tunable) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	config := cache.Config()
+	if config.Disabled && expire >= 0 {
+		return nil
+	}
+	if expire == NoExpire && config.DefaultTTL > 0 {
+		expire = config.DefaultTTL
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+// If the cache is disabled, ErrNotFound is returned.
+func (cache *Tunable) Load(ctx context.Context, key string) ([]byte, error) {
+	if cache.Config().Disabled {
+		return nil, ErrNotFound
+	}
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+// If the cache is disabled, a negative TTL is returned.
+func (cache *Tunable) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if cache.Config().Disabled {
+		return -1, nil
+	}
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics (even if the cache is disabled).
+func (cache *Tunable) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *Tunable) Unwrap() Cache {
+	return cache.cache
+}
+
+// Config returns current settings.
+func (cache *Tunable) Config() TunableConfig {
+	cache.mu.RLock()
+	config := cache.config
+	cache.mu.RUnlock()
+
+	return config
+}
+
+// SetConfig changes the settings.
+func (cache *Tunable) SetConfig(config TunableConfig) {
+	cache.mu.Lock()
+	cache.config = config
+	cache.mu.Unlock()
+}
@@ -0,0 +1,142 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.Tunable)(nil) // test Tunable is a Cache
+}
+
+func TestTunable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enabled cache is used", testTunableEnabledCacheIsUsed)
+	t.Run("disabled cache is bypassed", testTunableDisabledCacheIsBypassed)
+	t.Run("default ttl is applied", testTunableDefaultTTLIsApplied)
+	t.Run("settings are changed", testTunableSettingsAreChanged)
+}
+
+func testTunableEnabledCacheIsUsed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1)
+		subject = xcache.NewTunable(cache, xcache.TunableConfig{})
+		ctx     = context.Background()
+		key     = "test-tunable-enabled-key"
+		value   = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	resultValue, errLoad := subject.Load(ctx, key)
+	resultTTL, errTTL := subject.TTL(ctx, key)
+	resultStats, errStats := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, resultValue)
+	assertNil(t, errTTL)
+	assertTrue(t, resultTTL > 0)
+	assertNil(t, errStats)
+	assertEqual(t, int64(1), resultStats.Keys)
+}
+
+func testTunableDisabledCacheIsBypassed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTunable(cache, xcache.TunableConfig{Disabled: true})
+		ctx     = context.Background()
+		key     = "test-tunable-disabled-key"
+	)
+	cache.SetSaveCallback(func(_ context.Context, k string, _ []byte, exp time.Duration) error {
+		assertEqual(t, key, k)
+		assertTrue(t, exp < 0)
+
+		return nil
+	})
+
+	// act
+	errSave := subject.Save(ctx, key, []byte("test value"), time.Minute)
+	resultValue, errLoad := subject.Load(ctx, key)
+	resultTTL, errTTL := subject.TTL(ctx, key)
+	_, errStats := subject.Stats(ctx)
+	errDelete := subject.Save(ctx, key, nil, -1)
+
+	// assert
+	assertNil(t, errSave)
+	assertTrue(t, errors.Is(errLoad, xcache.ErrNotFound))
+	assertNil(t, resultValue)
+	assertNil(t, errTTL)
+	assertTrue(t, resultTTL < 0)
+	assertNil(t, errStats)
+	assertNil(t, errDelete)
+	assertEqual(t, 1, cache.SaveCallsCount()) // only the deletion
+	assertEqual(t, 0, cache.LoadCallsCount())
+	assertEqual(t, 0, cache.TTLCallsCount())
+	assertEqual(t, 1, cache.StatsCallsCount())
+}
+
+func testTunableDefaultTTLIsApplied(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache      = new(xcache.Mock)
+		defaultTTL = 10 * time.Minute
+		subject    = xcache.NewTunable(cache, xcache.TunableConfig{DefaultTTL: defaultTTL})
+		ctx        = context.Background()
+		expected   = []time.Duration{defaultTTL, time.Minute, -1}
+		callNo     int
+	)
+	cache.SetSaveCallback(func(_ context.Context, _ string, _ []byte, exp time.Duration) error {
+		assertEqual(t, expected[callNo], exp)
+		callNo++
+
+		return nil
+	})
+
+	// act
+	_ = subject.Save(ctx, "test-tunable-default-ttl-key", []byte("test value"), xcache.NoExpire)
+	_ = subject.Save(ctx, "test-tunable-default-ttl-key", []byte("test value"), time.Minute)
+	_ = subject.Save(ctx, "test-tunable-default-ttl-key", nil, -1)
+
+	// assert
+	assertEqual(t, 3, cache.SaveCallsCount())
+}
+
+func testTunableSettingsAreChanged(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache     = new(xcache.Mock)
+		subject   = xcache.NewTunable(cache, xcache.TunableConfig{})
+		ctx       = context.Background()
+		newConfig = xcache.TunableConfig{Disabled: true, DefaultTTL: time.Hour}
+	)
+
+	// act
+	subject.SetConfig(newConfig)
+	_, _ = subject.Load(ctx, "test-tunable-settings-key")
+
+	// assert
+	assertEqual(t, newConfig, subject.Config())
+	assertEqual(t, 0, cache.LoadCallsCount())
+}
@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"github.com/actforgood/xconf"
+)
+
+const (
+	// TunableCfgKeyDisabled is the key under which xconf.Config expects the kill switch flag.
+	TunableCfgKeyDisabled = "xcache.tunable.disabled"
+	// TunableCfgKeyDefaultTTL is the key under which xconf.Config expects the default TTL.
+	TunableCfgKeyDefaultTTL = "xcache.tunable.defaultttl"
+)
+
+// NewTunableWithConfig initializes a Tunable which decorates given cache,
+// with settings taken from a xconf.Config.
+//
+// Keys under which settings are expected are defined in TunableCfgKey* constants
+// (note, you can have different config keys defined in your project, you'll have to create an alias
+// for them to expected values by this package).
+// Values with common representations are coerced to expected types (for example, the default TTL
+// can be given as a duration string like "10m"). Invalid values are replaced with defaults,
+// use NewTunableWithValidConfig to fail on them instead.
+//
+// An observer is registered to xconf.DefaultConfig (which knows to reload configuration).
+// In case any of the settings is changed, it is applied right away, without the need of
+// restarting your application. An invalid configuration reload is disregarded.
+func NewTunableWithConfig(cache Cache, config xconf.Config) *Tunable {
+	tunableConfig, _ := getTunableConfig(config)
+	tunable := NewTunable(cache, tunableConfig)
+
+	if defConfig, ok := config.(*xconf.DefaultConfig); ok {
+		defConfig.RegisterObserver(tunable.onConfigChange)
+	}
+
+	return tunable
+}
+
+// NewTunableWithValidConfig is like NewTunableWithConfig, but it fails on invalid values:
+// an error aggregating a ConfigError for each of them is returned, and tunable is nil.
+func NewTunableWithValidConfig(cache Cache, config xconf.Config) (*Tunable, error) {
+	if err := ValidateTunableXConfig(config); err != nil {
+		return nil, err
+	}
+
+	return NewTunableWithConfig(cache, config), nil
+}
+
+// getTunableConfig returns a TunableConfig object populated with values taken from a xconf.Config.
+// Invalid values are replaced with defaults, and an error aggregating ConfigError(s) is returned.
+func getTunableConfig(config xconf.Config) (TunableConfig, error) {
+	r := newXConfReader(config)
+	tunableConfig := TunableConfig{
+		Disabled:   r.Bool(TunableCfgKeyDisabled, false),
+		DefaultTTL: r.Duration(TunableCfgKeyDefaultTTL, NoExpire),
+	}
+	if tunableConfig.DefaultTTL < 0 {
+		r.addErr(TunableCfgKeyDefaultTTL, errConfigValueRange)
+		tunableConfig.DefaultTTL = NoExpire
+	}
+
+	return tunableConfig, r.Err()
+}
+
+// ValidateTunableXConfig checks the Tunable configuration taken from a xconf.Config.
+// It returns an error aggregating a ConfigError for each value which cannot be interpreted,
+// or nil if configuration is valid.
+func ValidateTunableXConfig(config xconf.Config) error {
+	_, err := getTunableConfig(config)
+
+	return err
+}
+
+// onConfigChange is a callback to be registered to xconf.DefaultConfig which knows to reload configuration.
+// In case one of TunableCfgKey* configs is changed, the new settings are applied, if they are valid.
+// This callback is automatically registered on instantiation of a Tunable object with NewTunableWithConfig.
+func (cache *Tunable) onConfigChange(config xconf.Config, changedKeys ...string) {
+	configHasChanged := false
+	for _, changedKey := range changedKeys {
+		if changedKey == TunableCfgKeyDisabled ||
+			changedKey == TunableCfgKeyDefaultTTL ||
+			changedKey == CfgKeyDurationUnit {
+			configHasChanged = true
+
+			break
+		}
+	}
+	if !configHasChanged {
+		return
+	}
+
+	tunableConfig, err := getTunableConfig(config)
+	if err != nil { // keep current settings.
+		return
+	}
+	cache.SetConfig(tunableConfig)
+}
@@ -0,0 +1,159 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+func TestTunable_withXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		reloadConfig  uint32
+		initialConfig = map[string]any{
+			xcache.TunableCfgKeyDefaultTTL: "10m",
+		}
+		configReloaded = map[string]any{
+			xcache.TunableCfgKeyDisabled:   "true",
+			xcache.TunableCfgKeyDefaultTTL: "1h",
+		}
+		configReloadedInvalid = map[string]any{
+			xcache.TunableCfgKeyDisabled:   "maybe",
+			xcache.TunableCfgKeyDefaultTTL: "1h",
+		}
+		config, waitReload = newReloadingConfig(t, func() (map[string]any, error) {
+			switch atomic.LoadUint32(&reloadConfig) {
+			case 1:
+				return configReloaded, nil
+			case 2:
+				return configReloadedInvalid, nil
+			}
+
+			return initialConfig, nil
+		})
+		subject = xcache.NewTunableWithConfig(xcache.Nop{}, config)
+	)
+
+	// act & assert initial config
+	assertEqual(t, xcache.TunableConfig{DefaultTTL: 10 * time.Minute}, subject.Config())
+
+	// act & assert reloaded config
+	atomic.StoreUint32(&reloadConfig, 1)
+	waitReload()
+	expectedConfig := xcache.TunableConfig{Disabled: true, DefaultTTL: time.Hour}
+	assertEqual(t, expectedConfig, subject.Config())
+
+	// act & assert invalid reloaded config is disregarded
+	atomic.StoreUint32(&reloadConfig, 2)
+	waitReload()
+	assertEqual(t, expectedConfig, subject.Config())
+}
+
+func TestTunable_withXConfDurationUnit(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name        string
+		config      xconf.Config
+		expectedTTL time.Duration
+	}{
+		{
+			name:        "milliseconds by default",
+			config:      xconf.NewMockConfig(xcache.TunableCfgKeyDefaultTTL, 60000),
+			expectedTTL: time.Minute,
+		},
+		{
+			name: "configured unit",
+			config: xconf.NewMockConfig(
+				xcache.CfgKeyDurationUnit, "s",
+				xcache.TunableCfgKeyDefaultTTL, "60",
+			),
+			expectedTTL: time.Minute,
+		},
+		{
+			name: "nanoseconds",
+			config: xconf.NewMockConfig(
+				xcache.CfgKeyDurationUnit, "ns",
+				xcache.TunableCfgKeyDefaultTTL, int64(time.Minute),
+			),
+			expectedTTL: time.Minute,
+		},
+		{
+			name:        "time.Duration is taken as it is",
+			config:      xconf.NewMockConfig(xcache.TunableCfgKeyDefaultTTL, time.Minute),
+			expectedTTL: time.Minute,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			subject := xcache.NewTunableWithConfig(xcache.Nop{}, test.config)
+
+			// assert
+			assertEqual(t, test.expectedTTL, subject.Config().DefaultTTL)
+		})
+	}
+}
+
+func TestValidateTunableXConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		config          xconf.Config
+		expectedErrKeys []string
+	}{
+		{
+			name:   "missing values",
+			config: xconf.NewMockConfig(),
+		},
+		{
+			name: "valid values",
+			config: xconf.NewMockConfig(
+				xcache.TunableCfgKeyDisabled, false,
+				xcache.TunableCfgKeyDefaultTTL, "5m",
+			),
+		},
+		{
+			name: "invalid values",
+			config: xconf.NewMockConfig(
+				xcache.TunableCfgKeyDisabled, "maybe",
+				xcache.TunableCfgKeyDefaultTTL, "-5m",
+			),
+			expectedErrKeys: []string{
+				xcache.TunableCfgKeyDisabled,
+				xcache.TunableCfgKeyDefaultTTL,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			resultErr := xcache.ValidateTunableXConfig(test.config)
+			tunable, resultCtorErr := xcache.NewTunableWithValidConfig(xcache.NewMemory(0), test.config)
+
+			// assert
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultErr)
+			assertConfigErrorKeys(t, test.expectedErrKeys, resultCtorErr)
+			assertEqual(t, resultCtorErr == nil, tunable != nil)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TypedConfig holds the settings of a Typed cache facade.
+type TypedConfig[ID any, T any] struct {
+	// KeyPrefix is prepended to the entity's id in order to build its cache key.
+	// Example: "user:" results in keys like "user:123".
+	KeyPrefix string
+	// KeyFunc builds the cache key of an entity's id. If set, KeyPrefix is disregarded.
+	KeyFunc func(id ID) string
+	// TTL is the expiration period of saved entities.
+	// A value of 0 (NoExpire) means no expiration.
+	TTL time.Duration
+	// TTLFunc returns the expiration period of given entity. If set, TTL is disregarded.
+	TTLFunc func(entity T) time.Duration
+	// Codec converts entities to / from bytes. By default, JSONCodec is used.
+	Codec Codec[T]
+}
+
+// Typed is a facade over a Cache for entities of type T, identified by ids of type ID.
+// It takes care of building the keys, encoding / decoding the entities, and
+// applying the expiration policy, so that the boilerplate is not duplicated across call sites.
+//
+// Example:
+//
+//	users := xcache.NewTyped(cache, xcache.TypedConfig[int64, User]{
+//		KeyPrefix: "user:",
+//		TTL:       10 * time.Minute,
+//	})
+//	user, err := users.Get(ctx, 123)
+type Typed[ID any, T any] struct {
+	cache  Cache
+	config TypedConfig[ID, T]
+}
+
+// NewTyped instantiates a new Typed cache facade over given cache, with given settings.
+func NewTyped[ID any, T any](cache Cache, config TypedConfig[ID, T]) *Typed[ID, T] {
+	if config.Codec == nil {
+		config.Codec = JSONCodec[T]{}
+	}
+
+	return &Typed[ID, T]{
+		cache:  cache,
+		config: config,
+	}
+}
+
+// Get returns the entity with given id.
+// If the entity is not found, ErrNotFound is returned.
+// It returns an error if the entity could not be loaded or decoded.
+func (typed *Typed[ID, T]) Get(ctx context.Context, id ID) (T, error) {
+	data, err := typed.cache.Load(ctx, typed.Key(id))
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return typed.config.Codec.Decode(data)
+}
+
+// Set stores the entity with given id, according to the configured expiration policy.
+// It returns an error if the entity could not be encoded or saved.
+func (typed *Typed[ID, T]) Set(ctx context.Context, id ID, entity T) error {
+	data, err := typed.config.Codec.Encode(entity)
+	if err != nil {
+		return err
+	}
+
+	return typed.cache.Save(ctx, typed.Key(id), data, typed.ttl(entity))
+}
+
+// Invalidate deletes the entity with given id.
+func (typed *Typed[ID, T]) Invalidate(ctx context.Context, id ID) error {
+	return deleteKey(ctx, typed.cache, typed.Key(id))
+}
+
+// Key returns the cache key of the entity with given id.
+func (typed *Typed[ID, T]) Key(id ID) string {
+	if typed.config.KeyFunc != nil {
+		return typed.config.KeyFunc(id)
+	}
+
+	return typed.config.KeyPrefix + fmt.Sprint(id)
+}
+
+// ttl returns the expiration period of given entity.
+func (typed *Typed[ID, T]) ttl(entity T) time.Duration {
+	if typed.config.TTLFunc != nil {
+		return typed.config.TTLFunc(entity)
+	}
+
+	return typed.config.TTL
+}
+
+// TypedCache is a generic wrapper over a Cache, which saves / loads values of type T, instead of bytes,
+// converting them with a Codec.
+// Unlike Typed, it's key based (no key building, nor expiration policy).
+//
+// Example:
+//
+//	users := xcache.NewTypedCache[User](cache, nil) // JSONCodec is used.
+//	err := users.Save(ctx, "user:123", user, 10*time.Minute)
+//	user, err := users.Load(ctx, "user:123")
+type TypedCache[T any] struct {
+	cache Cache
+	codec Codec[T]
+}
+
+// NewTypedCache instantiates a new TypedCache over given cache, which converts values with given codec.
+// If codec is nil, JSONCodec is used.
+func NewTypedCache[T any](cache Cache, codec Codec[T]) *TypedCache[T] {
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+
+	return &TypedCache[T]{
+		cache: cache,
+		codec: codec,
+	}
+}
+
+// Save stores the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the value could not be encoded or saved.
+func (typed *TypedCache[T]) Save(ctx context.Context, key string, value T, expire time.Duration) error {
+	if expire < 0 {
+		return deleteKey(ctx, typed.cache, key)
+	}
+	data, err := typed.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	return typed.cache.Save(ctx, key, data, expire)
+}
+
+// Load returns a key's value.
+// If the key is not found, ErrNotFound is returned.
+// It returns an error if the value could not be loaded or decoded.
+func (typed *TypedCache[T]) Load(ctx context.Context, key string) (T, error) {
+	data, err := typed.cache.Load(ctx, key)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return typed.codec.Decode(data)
+}
+
+// Delete deletes the given key.
+func (typed *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	return deleteKey(ctx, typed.cache, key)
+}
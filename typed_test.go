@@ -0,0 +1,248 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+type testUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func init() {
+	var _ xcache.Codec[testUser] = xcache.JSONCodec[testUser]{} // test JSONCodec is a Codec
+}
+
+func TestTyped(t *testing.T) {
+	t.Parallel()
+
+	t.Run("entity is set, got and invalidated", testTypedEntityIsSetGotAndInvalidated)
+	t.Run("key and ttl functions are used", testTypedKeyAndTTLFunctionsAreUsed)
+	t.Run("decode error is returned", testTypedDecodeErrIsReturned)
+}
+
+func testTypedEntityIsSetGotAndInvalidated(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewTyped(xcache.NewMemory(1), xcache.TypedConfig[int64, testUser]{
+			KeyPrefix: "test-typed-user:",
+			TTL:       time.Minute,
+		})
+		ctx  = context.Background()
+		user = testUser{ID: 123, Name: "John Doe"}
+	)
+
+	// act & assert
+	_, err := subject.Get(ctx, user.ID)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	err = subject.Set(ctx, user.ID, user)
+	requireNil(t, err)
+
+	result, err := subject.Get(ctx, user.ID)
+	assertNil(t, err)
+	assertEqual(t, user, result)
+	assertEqual(t, "test-typed-user:123", subject.Key(user.ID))
+
+	err = subject.Invalidate(ctx, user.ID)
+	assertNil(t, err)
+	_, err = subject.Get(ctx, user.ID)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testTypedKeyAndTTLFunctionsAreUsed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTyped(cache, xcache.TypedConfig[int64, testUser]{
+			KeyPrefix: "disregarded:",
+			KeyFunc: func(id int64) string {
+				return "test-typed-user:{" + strconv.FormatInt(id, 10) + "}"
+			},
+			TTL: time.Hour,
+			TTLFunc: func(user testUser) time.Duration {
+				return time.Duration(user.ID) * time.Second
+			},
+		})
+		ctx  = context.Background()
+		user = testUser{ID: 30, Name: "John Doe"}
+	)
+	cache.SetSaveCallback(func(_ context.Context, key string, value []byte, exp time.Duration) error {
+		assertEqual(t, "test-typed-user:{30}", key)
+		assertEqual(t, `{"id":30,"name":"John Doe"}`, string(value))
+		assertEqual(t, 30*time.Second, exp)
+
+		return nil
+	})
+
+	// act
+	err := subject.Set(ctx, user.ID, user)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}
+
+func testTypedDecodeErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTyped(cache, xcache.TypedConfig[string, testUser]{KeyPrefix: "test-typed-user:"})
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return []byte("not a json"), nil
+	})
+
+	// act
+	result, err := subject.Get(context.Background(), "abc")
+
+	// assert
+	assertNotNil(t, err)
+	assertEqual(t, testUser{}, result)
+	assertEqual(t, 1, cache.LoadCallsCount())
+}
+
+func TestTypedCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value is saved, loaded and deleted", testTypedCacheValueIsSavedLoadedAndDeleted)
+	t.Run("custom codec is used", testTypedCacheCustomCodecIsUsed)
+	t.Run("decode error is returned", testTypedCacheDecodeErrIsReturned)
+}
+
+func testTypedCacheValueIsSavedLoadedAndDeleted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewTypedCache[testUser](xcache.NewMemory(1), nil)
+		ctx     = context.Background()
+		key     = "test-typed-cache-user:123"
+		user    = testUser{ID: 123, Name: "John Doe"}
+	)
+
+	// act & assert
+	_, err := subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	err = subject.Save(ctx, key, user, time.Minute)
+	requireNil(t, err)
+
+	result, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, user, result)
+
+	err = subject.Delete(ctx, key)
+	assertNil(t, err)
+	_, err = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testTypedCacheCustomCodecIsUsed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTypedCache[int64](cache, testDecimalCodec{})
+		ctx     = context.Background()
+		key     = "test-typed-cache-counter"
+	)
+	cache.SetSaveCallback(func(_ context.Context, k string, v []byte, exp time.Duration) error {
+		assertEqual(t, key, k)
+		assertEqual(t, "123", string(v))
+		assertEqual(t, time.Minute, exp)
+
+		return nil
+	})
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return []byte("456"), nil
+	})
+
+	// act
+	errSave := subject.Save(ctx, key, 123, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, int64(456), result)
+	assertEqual(t, 1, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+}
+
+func testTypedCacheDecodeErrIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewTypedCache[testUser](cache, nil)
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return []byte("not a json"), nil
+	})
+
+	// act
+	result, err := subject.Load(context.Background(), "test-typed-cache-user:abc")
+
+	// assert
+	assertNotNil(t, err)
+	assertEqual(t, testUser{}, result)
+}
+
+// testDecimalCodec is a Codec which stores int64 values as decimal strings.
+type testDecimalCodec struct{}
+
+func (testDecimalCodec) Encode(value int64) ([]byte, error) {
+	return strconv.AppendInt(nil, value, 10), nil
+}
+
+func (testDecimalCodec) Decode(data []byte) (int64, error) {
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+func ExampleTyped() {
+	type User struct {
+		ID   int64
+		Name string
+	}
+
+	cache := xcache.NewMemory(10 * 1024 * 1024) // 10 Mb
+	users := xcache.NewTyped(cache, xcache.TypedConfig[int64, User]{
+		KeyPrefix: "example-user:",
+		TTL:       10 * time.Minute,
+	})
+	ctx := context.Background()
+
+	if err := users.Set(ctx, 1, User{ID: 1, Name: "John Doe"}); err != nil {
+		fmt.Println("could not save user: " + err.Error())
+	}
+
+	if user, err := users.Get(ctx, 1); err != nil {
+		fmt.Println("could not get user: " + err.Error())
+	} else {
+		fmt.Println(user.Name)
+	}
+
+	// Output:
+	// John Doe
+}
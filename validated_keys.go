@@ -0,0 +1,237 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidKey is returned by a ValidatedKeys cache when a key breaks the configured rules.
+var ErrInvalidKey = errors.New("invalid key")
+
+// KeyRules holds the rules keys of a ValidatedKeys cache are normalized and validated with.
+type KeyRules struct {
+	// Trim tells whether leading and trailing whitespace is removed from keys.
+	Trim bool
+	// Lowercase tells whether keys are lower cased.
+	Lowercase bool
+	// MaxLen is the max. length of a key, in bytes. By default (0), keys' length is not limited.
+	MaxLen int
+	// AllowEmpty tells whether the empty key is allowed.
+	AllowEmpty bool
+	// AllowWhitespace tells whether keys can contain whitespace (spaces, tabs, newlines)
+	// and control characters. By default (false), they cannot.
+	AllowWhitespace bool
+	// IsAllowed tells whether a character is allowed in keys.
+	// By default (nil), any (valid UTF-8) character is allowed.
+	//
+	// Example, allowing only ASCII letters, digits and some separators:
+	//
+	//	IsAllowed: func(r rune) bool {
+	//		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(":_-.", r))
+	//	},
+	IsAllowed func(r rune) bool
+}
+
+// ValidatedKeys is a Cache decorator which normalizes keys (trims, lower cases them), and validates them
+// against configurable rules (max. length, allowed characters, no whitespace), see KeyRules,
+// before delegating operations to the decorated cache, so that keys with stray whitespace, for example,
+// do not end up in silent cache misses. An operation with an invalid key fails with ErrInvalidKey.
+//
+// Example:
+//
+//	cache := xcache.NewValidatedKeys(redisCache, xcache.KeyRules{Trim: true, MaxLen: 250})
+type ValidatedKeys struct {
+	cache   Cache
+	rules   KeyRules
+	invalid int64
+}
+
+// NewValidatedKeys instantiates a new ValidatedKeys which decorates given cache, applying given rules.
+func NewValidatedKeys(cache Cache, rules KeyRules) *ValidatedKeys {
+	return &ValidatedKeys{
+		cache: cache,
+		rules: rules,
+	}
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// A negative expiration period triggers deletion of key.
+func (cache *ValidatedKeys) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	key, err := cache.Key(key)
+	if err != nil {
+		return err
+	}
+
+	return cache.cache.Save(ctx, key, value, expire)
+}
+
+// Load returns a key's value from decorated cache.
+func (cache *ValidatedKeys) Load(ctx context.Context, key string) ([]byte, error) {
+	key, err := cache.Key(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.cache.Load(ctx, key)
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *ValidatedKeys) TTL(ctx context.Context, key string) (time.Duration, error) {
+	key, err := cache.Key(key)
+	if err != nil {
+		return -1, err
+	}
+
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *ValidatedKeys) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Delete deletes the given key from decorated cache.
+func (cache *ValidatedKeys) Delete(ctx context.Context, key string) error {
+	key, err := cache.Key(key)
+	if err != nil {
+		return err
+	}
+
+	return deleteKey(ctx, cache.cache, key)
+}
+
+// DeleteMany deletes the given keys from decorated cache.
+// If any of the keys is invalid, none is deleted.
+func (cache *ValidatedKeys) DeleteMany(ctx context.Context, keys ...string) error {
+	validKeys, err := cache.keys(keys)
+	if err != nil {
+		return err
+	}
+
+	return deleteKeys(ctx, cache.cache, validKeys...)
+}
+
+// Has checks if the given key exists in decorated cache.
+func (cache *ValidatedKeys) Has(ctx context.Context, key string) (bool, error) {
+	key, err := cache.Key(key)
+	if err != nil {
+		return false, err
+	}
+
+	return hasKey(ctx, cache.cache, key)
+}
+
+// SaveMany stores the given items into decorated cache.
+// If any of the keys is invalid, none is saved.
+func (cache *ValidatedKeys) SaveMany(ctx context.Context, items map[string]Item) error {
+	validItems := make(map[string]Item, len(items))
+	for key, item := range items {
+		validKey, err := cache.Key(key)
+		if err != nil {
+			return err
+		}
+		validItems[validKey] = item
+	}
+
+	return saveMany(ctx, cache.cache, validItems)
+}
+
+// LoadMany returns the values of given keys from decorated cache (keys not found are missing from the returned map).
+// If any of the keys is invalid, none is loaded.
+func (cache *ValidatedKeys) LoadMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	validKeys, err := cache.keys(keys)
+	if err != nil {
+		return nil, err
+	}
+	validValues, err := loadMany(ctx, cache.cache, validKeys)
+	values := make(map[string][]byte, len(validValues))
+	for idx, key := range keys {
+		if value, found := validValues[validKeys[idx]]; found {
+			values[key] = value
+		}
+	}
+
+	return values, err
+}
+
+// Unwrap returns the decorated cache.
+func (cache *ValidatedKeys) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of operations rejected because of an invalid key,
+// as "validatedkeys.invalid" metric.
+func (cache *ValidatedKeys) ContributeStats(add func(name string, value float64)) {
+	add("validatedkeys.invalid", float64(atomic.LoadInt64(&cache.invalid)))
+}
+
+// Key returns the normalized form of given key, or ErrInvalidKey (wrapped, with the broken rule),
+// if it breaks the rules.
+func (cache *ValidatedKeys) Key(key string) (string, error) {
+	if cache.rules.Trim {
+		key = strings.TrimSpace(key)
+	}
+	if cache.rules.Lowercase {
+		key = strings.ToLower(key)
+	}
+	if err := cache.validate(key); err != nil {
+		atomic.AddInt64(&cache.invalid, 1)
+
+		return key, err
+	}
+
+	return key, nil
+}
+
+// validate checks given key against the rules.
+func (cache *ValidatedKeys) validate(key string) error {
+	if key == "" && !cache.rules.AllowEmpty {
+		return fmt.Errorf("%w: empty", ErrInvalidKey)
+	}
+	if cache.rules.MaxLen > 0 && len(key) > cache.rules.MaxLen {
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidKey, cache.rules.MaxLen)
+	}
+	for idx, r := range key {
+		if r == utf8.RuneError {
+			return fmt.Errorf("%w: invalid UTF-8 at byte %d", ErrInvalidKey, idx)
+		}
+		if !cache.rules.AllowWhitespace && (unicode.IsSpace(r) || unicode.IsControl(r)) {
+			return fmt.Errorf("%w: whitespace / control character %q at byte %d", ErrInvalidKey, r, idx)
+		}
+		if cache.rules.IsAllowed != nil && !cache.rules.IsAllowed(r) {
+			return fmt.Errorf("%w: character %q not allowed at byte %d", ErrInvalidKey, r, idx)
+		}
+	}
+
+	return nil
+}
+
+// keys returns the normalized form of given keys, or an error, if any of them is invalid.
+func (cache *ValidatedKeys) keys(keys []string) ([]string, error) {
+	validKeys := make([]string, len(keys))
+	for idx, key := range keys {
+		validKey, err := cache.Key(key)
+		if err != nil {
+			return nil, err
+		}
+		validKeys[idx] = validKey
+	}
+
+	return validKeys, nil
+}
@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.ValidatedKeys)(nil)            // test ValidatedKeys is a Cache
+	var _ xcache.Deleter = (*xcache.ValidatedKeys)(nil)          // test ValidatedKeys is a Deleter
+	var _ xcache.BulkDeleter = (*xcache.ValidatedKeys)(nil)      // test ValidatedKeys is a BulkDeleter
+	var _ xcache.ExistenceChecker = (*xcache.ValidatedKeys)(nil) // test ValidatedKeys is an ExistenceChecker
+	var _ xcache.Batcher = (*xcache.ValidatedKeys)(nil)          // test ValidatedKeys is a Batcher
+	var _ xcache.StatsContributor = (*xcache.ValidatedKeys)(nil) // test ValidatedKeys is a StatsContributor
+}
+
+func TestValidatedKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("key is normalized", testValidatedKeysKeyIsNormalized)
+	t.Run("invalid key is rejected", testValidatedKeysInvalidKeyIsRejected)
+	t.Run("batch operations", testValidatedKeysBatchOperations)
+}
+
+func testValidatedKeysKeyIsNormalized(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewValidatedKeys(cache, xcache.KeyRules{Trim: true, Lowercase: true})
+		ctx     = context.Background()
+		value   = []byte("test value")
+	)
+
+	// act
+	err := subject.Save(ctx, " Test-Validated-Key\n", value, time.Minute)
+
+	// assert
+	requireNil(t, err)
+	loadedValue, err := cache.Load(ctx, "test-validated-key")
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	loadedValue, err = subject.Load(ctx, "TEST-VALIDATED-KEY")
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, "test-validated-key ")
+	assertNil(t, err)
+	assertTrue(t, ttl > 0)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testValidatedKeysInvalidKeyIsRejected(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewValidatedKeys(cache, xcache.KeyRules{
+			MaxLen: 16,
+			IsAllowed: func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(":-", r)
+			},
+		})
+		ctx = context.Background()
+	)
+	tests := [...]struct {
+		name string
+		key  string
+	}{
+		{"empty", ""},
+		{"too long", "test-validated-key-too-long"},
+		{"whitespace", "user:1 "},
+		{"newline", "user:\n1"},
+		{"not allowed character", "user/1"},
+		{"invalid UTF-8", "user:\xff"},
+	}
+
+	for _, test := range tests {
+		// act
+		err := subject.Save(ctx, test.key, []byte("test value"), time.Minute)
+		_, errLoad := subject.Load(ctx, test.key)
+		ttl, errTTL := subject.TTL(ctx, test.key)
+
+		// assert
+		assertTrue(t, errors.Is(err, xcache.ErrInvalidKey))
+		assertTrue(t, errors.Is(errLoad, xcache.ErrInvalidKey))
+		assertTrue(t, errors.Is(errTTL, xcache.ErrInvalidKey))
+		assertEqual(t, time.Duration(-1), ttl)
+	}
+	_, err := subject.Load(ctx, "user:1")
+	assertTrue(t, !errors.Is(err, xcache.ErrInvalidKey))
+	assertEqual(t, 0, cache.SaveCallsCount())
+	assertEqual(t, 1, cache.LoadCallsCount())
+	assertEqual(t, 0, cache.TTLCallsCount())
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"validatedkeys.invalid": float64(3 * len(tests))}, metrics)
+}
+
+func testValidatedKeysBatchOperations(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewValidatedKeys(cache, xcache.KeyRules{Trim: true})
+		ctx     = context.Background()
+		items   = map[string]xcache.Item{
+			"test-validated-batch-key-1 ": {Value: []byte("value 1"), Expire: time.Minute},
+			"test-validated-batch-key-2":  {Value: []byte("value 2"), Expire: time.Minute},
+		}
+	)
+
+	// act & assert
+	requireNil(t, subject.SaveMany(ctx, items))
+	values, err := subject.LoadMany(ctx, []string{" test-validated-batch-key-1", "test-validated-batch-key-2"})
+	assertNil(t, err)
+	assertEqual(
+		t,
+		map[string][]byte{" test-validated-batch-key-1": []byte("value 1"), "test-validated-batch-key-2": []byte("value 2")},
+		values,
+	)
+	found, err := subject.Has(ctx, "test-validated-batch-key-1")
+	assertNil(t, err)
+	assertTrue(t, found)
+	_, err = subject.LoadMany(ctx, []string{"test-validated-batch-key-1", "invalid key"})
+	assertTrue(t, errors.Is(err, xcache.ErrInvalidKey))
+	assertTrue(t, errors.Is(subject.DeleteMany(ctx, "test-validated-batch-key-1", ""), xcache.ErrInvalidKey))
+	requireNil(t, subject.DeleteMany(ctx, "test-validated-batch-key-1", "test-validated-batch-key-2\t"))
+	requireNil(t, subject.Delete(ctx, "test-validated-batch-key-1"))
+	found, err = subject.Has(ctx, "test-validated-batch-key-2")
+	assertNil(t, err)
+	assertTrue(t, !found)
+}
@@ -0,0 +1,23 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+// Valkey is Valkey (the BSD licensed fork of Redis 7.2) based implementation for Cache.
+// Valkey is wire compatible with Redis 7.2 (commands, RESP2 / RESP3, sentinel and cluster protocols),
+// and its INFO output keeps Redis's field names (used_memory, maxmemory, keyspace_hits, db0:keys=...),
+// so it is served by Redis7 (go-redis ver.9 client) - everything documented for Redis7 applies
+// (hooks, SetRedis7Logger, As[*xcache.Redis7], transactions).
+// It implements io.Closer, and thus it should be closed at your application shutdown.
+type Valkey = Redis7
+
+// NewValkey instantiates a new Valkey Cache instance.
+//
+// 1. If the MasterName option is specified, a sentinel-backed FailoverClient is used behind.
+// 2. If the number of Addrs is two or more, a ClusterClient is used behind.
+// 3. Otherwise, a single-node Client is used.
+func NewValkey(config RedisConfig) *Valkey {
+	return NewRedis7(config)
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/xcache"
+)
+
+var valkeyConfigIntegration = xcache.RedisConfig{
+	Addrs: []string{"127.0.0.1:6381"},
+}
+
+func init() {
+	valkeyAddrs := os.Getenv("XCACHE_VALKEY_ADDRS")
+	if valkeyAddrs != "" {
+		valkeyConfigIntegration.Addrs = strings.Split(valkeyAddrs, ",")
+	}
+}
+
+func TestValkey_integration(t *testing.T) {
+	t.Parallel()
+
+	// setup
+	subject := xcache.NewValkey(valkeyConfigIntegration)
+
+	t.Run("wait", func(t *testing.T) { // wait for parallel tests to complete
+		t.Run("key that does not expire", testCacheWithNoExpireKey(subject))
+		t.Run("key expires", testCacheWithExpireKey(subject))
+		t.Run("key does not exist", testCacheWithNotExistKey(subject))
+		t.Run("delete key", testCacheDeleteKey(subject))
+		t.Run("ttl for not yet expired key", testCacheTTLWithNotYetExpiredKey(subject))
+		t.Run("delete prefix", testCacheDeletePrefix(subject))
+		t.Run("scan", testCacheScan(subject))
+		t.Run("touch", testCacheTouch(subject))
+		t.Run("save keep ttl", testCacheSaveKeepTTL(subject))
+		t.Run("has", testCacheHas(subject))
+		t.Run("save many & load many", testCacheSaveManyLoadMany(subject))
+		t.Run("delete many", testCacheDeleteMany(subject))
+		t.Run("done context", testCacheWithDoneContext(subject))
+		t.Run("stats", testCacheStats(subject, 256, 1024*1024, ">=", !valkeyConfigIntegration.IsCluster()))
+	})
+
+	// tear down
+	err := subject.Close()
+	assertNil(t, err)
+}
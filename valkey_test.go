@@ -0,0 +1,143 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+)
+
+// valkeyInfo is an (abridged) INFO reply of a Valkey 8 server.
+const valkeyInfo = "# Server\r\n" +
+	"redis_version:7.2.4\r\n" +
+	"server_name:valkey\r\n" +
+	"valkey_version:8.0.1\r\n" +
+	"redis_mode:standalone\r\n\r\n" +
+	"# Memory\r\n" +
+	"used_memory:1015272\r\n" +
+	"used_memory_human:991.48K\r\n" +
+	"total_system_memory:16624541696\r\n" +
+	"total_system_memory_human:15.48G\r\n" +
+	"maxmemory:0\r\n" +
+	"maxmemory_human:0B\r\n\r\n" +
+	"# Stats\r\n" +
+	"expired_keys:12\r\n" +
+	"expired_stale_perc:0.00\r\n" +
+	"evicted_keys:3\r\n" +
+	"keyspace_hits:100\r\n" +
+	"keyspace_misses:25\r\n\r\n" +
+	"# Keyspace\r\n" +
+	"db0:keys=42,expires=40,avg_ttl=59812,subexpiry=0\r\n"
+
+func TestValkey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl", testValkeySaveLoadTTL)
+	t.Run("stats are parsed from valkey info", testValkeyStatsAreParsedFromValkeyInfo)
+	t.Run("with xconf", testValkeyWithXConf)
+}
+
+func testValkeySaveLoadTTL(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr      = miniredis.RunT(t)
+		subject = xcache.NewValkey(xcache.RedisConfig{Addrs: []string{mr.Addr()}})
+		ctx     = context.Background()
+		key     = "test-valkey-key"
+		value   = []byte("test value")
+	)
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	loadedValue, errLoad := subject.Load(ctx, key)
+	ttl, errTTL := subject.TTL(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, loadedValue)
+	assertNil(t, errTTL)
+	assertEqual(t, time.Minute, ttl)
+	_, isRedis7 := xcache.As[*xcache.Redis7](subject)
+	assertTrue(t, isRedis7)
+}
+
+func testValkeyStatsAreParsedFromValkeyInfo(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr      = miniredis.RunT(t)
+		subject = xcache.NewValkey(xcache.RedisConfig{Addrs: []string{mr.Addr()}})
+		ctx     = context.Background()
+	)
+	defer subject.Close()
+	mr.Server().SetPreHook(func(peer *server.Peer, cmd string, _ ...string) bool {
+		if cmd == "INFO" {
+			peer.WriteBulk(valkeyInfo)
+
+			return true
+		}
+
+		return false
+	})
+
+	// act
+	stats, err := subject.Stats(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(
+		t,
+		xcache.Stats{
+			Memory:    1015272,
+			MaxMemory: 16624541696,
+			Hits:      100,
+			Misses:    25,
+			Keys:      42,
+			Expired:   12,
+			Evicted:   3,
+		},
+		stats,
+	)
+}
+
+func testValkeyWithXConf(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		mr        = miniredis.RunT(t)
+		config, _ = xconf.NewDefaultConfig(xconf.PlainLoader(map[string]any{
+			xcache.RedisCfgKeyAddrs: mr.Addr(),
+		}))
+		subject = xcache.NewValkeyWithConfig(config)
+		ctx     = context.Background()
+		key     = "test-valkey-xconf-key"
+		value   = []byte("test value")
+	)
+	defer config.Close()
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, value, xcache.NoExpire)
+	loadedValue, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, loadedValue)
+}
@@ -0,0 +1,21 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import "github.com/actforgood/xconf"
+
+// NewValkeyWithConfig initializes a Valkey Cache with configuration taken from a xconf.Config.
+// Keys under which configuration is expected are the same as for Redis, defined in RedisCfgKey* constants.
+// See NewRedis7WithConfig for details about configuration reload.
+func NewValkeyWithConfig(config xconf.Config) *Valkey {
+	return NewRedis7WithConfig(config)
+}
+
+// NewValkeyWithValidConfig is like NewValkeyWithConfig, but it fails on invalid values:
+// an error aggregating a ConfigError for each of them is returned, and cache is nil.
+func NewValkeyWithValidConfig(config xconf.Config) (*Valkey, error) {
+	return NewRedis7WithValidConfig(config)
+}
@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// workerPoolDefaultQueueSize is the default max. no. of tasks waiting for a worker.
+const workerPoolDefaultQueueSize = 1024
+
+// WorkerPoolConfig holds the settings of a WorkerPool.
+type WorkerPoolConfig struct {
+	// Workers is the no. of goroutines executing tasks. By default (0), it's GOMAXPROCS.
+	Workers int
+	// QueueSize is the max. no. of tasks waiting for a worker. By default (0), it's 1024.
+	// Tasks submitted when the queue is full are dropped.
+	QueueSize int
+}
+
+// WorkerPoolStats holds the statistics of a WorkerPool.
+type WorkerPoolStats struct {
+	// Workers is the no. of goroutines executing tasks.
+	Workers int
+	// QueueSize is the max. no. of tasks waiting for a worker.
+	QueueSize int
+	// QueueDepth is the current no. of tasks waiting for a worker.
+	QueueDepth int
+	// Active is the current no. of tasks being executed.
+	Active int64
+	// Completed is the no. of executed tasks.
+	Completed int64
+	// Dropped is the no. of tasks dropped because the queue was full (or the pool was closed).
+	Dropped int64
+}
+
+// WorkerPool executes background tasks (like Multi's asynchronous backfill) with a fixed no. of goroutines,
+// so the package does not spawn unbounded goroutines under load.
+// A pool can be shared by multiple caches / features.
+// It implements io.Closer and should be closed at your application shutdown.
+type WorkerPool struct {
+	tasks     chan func()
+	workers   int
+	active    int64
+	completed int64
+	dropped   int64
+	closed    bool
+	mu        sync.RWMutex
+	wg        sync.WaitGroup
+}
+
+// NewWorkerPool instantiates a new WorkerPool with given settings, and starts its workers.
+func NewWorkerPool(config WorkerPoolConfig) *WorkerPool {
+	if config.Workers <= 0 {
+		config.Workers = runtime.GOMAXPROCS(0)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = workerPoolDefaultQueueSize
+	}
+
+	pool := &WorkerPool{
+		tasks:   make(chan func(), config.QueueSize),
+		workers: config.Workers,
+	}
+	pool.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// Submit queues given task for execution, without blocking.
+// It returns false if the task was dropped, because the queue is full, or the pool is closed.
+func (pool *WorkerPool) Submit(task func()) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if !pool.closed {
+		select {
+		case pool.tasks <- task:
+			return true
+		default:
+		}
+	}
+	atomic.AddInt64(&pool.dropped, 1)
+
+	return false
+}
+
+// Stats returns pool's statistics.
+func (pool *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:    pool.workers,
+		QueueSize:  cap(pool.tasks),
+		QueueDepth: len(pool.tasks),
+		Active:     atomic.LoadInt64(&pool.active),
+		Completed:  atomic.LoadInt64(&pool.completed),
+		Dropped:    atomic.LoadInt64(&pool.dropped),
+	}
+}
+
+// Close stops accepting tasks, and waits for the queued ones to be executed.
+// It implements io.Closer interface, and the returned error can be disregarded (is nil all the time).
+func (pool *WorkerPool) Close() error {
+	pool.mu.Lock()
+	if !pool.closed {
+		pool.closed = true
+		close(pool.tasks)
+	}
+	pool.mu.Unlock()
+	pool.wg.Wait()
+
+	return nil
+}
+
+// work executes queued tasks, until the pool is closed.
+func (pool *WorkerPool) work() {
+	defer pool.wg.Done()
+
+	for task := range pool.tasks {
+		atomic.AddInt64(&pool.active, 1)
+		task()
+		atomic.AddInt64(&pool.active, -1)
+		atomic.AddInt64(&pool.completed, 1)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/actforgood/xcache"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tasks are executed", testWorkerPoolTasksAreExecuted)
+	t.Run("tasks are dropped when queue is full", testWorkerPoolTasksAreDroppedWhenQueueIsFull)
+	t.Run("tasks are dropped after close", testWorkerPoolTasksAreDroppedAfterClose)
+}
+
+func testWorkerPoolTasksAreExecuted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject  = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 4, QueueSize: 100})
+		executed int32
+		tasksNo  = 100
+	)
+
+	// act
+	for i := 0; i < tasksNo; i++ {
+		assertTrue(t, subject.Submit(func() {
+			atomic.AddInt32(&executed, 1)
+		}))
+	}
+	err := subject.Close()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, int32(tasksNo), atomic.LoadInt32(&executed))
+	assertEqual(
+		t,
+		xcache.WorkerPoolStats{Workers: 4, QueueSize: 100, Completed: int64(tasksNo)},
+		subject.Stats(),
+	)
+}
+
+func testWorkerPoolTasksAreDroppedWhenQueueIsFull(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcache.NewWorkerPool(xcache.WorkerPoolConfig{Workers: 1, QueueSize: 1})
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	workerOccupied := subject.Submit(func() {
+		close(started)
+		<-release
+	})
+	if !assertTrue(t, workerOccupied) {
+		return
+	}
+	<-started
+	assertTrue(t, subject.Submit(func() {})) // occupies the queue.
+
+	// act
+	result := subject.Submit(func() {})
+
+	// assert
+	assertTrue(t, !result)
+	stats := subject.Stats()
+	assertEqual(t, 1, stats.QueueDepth)
+	assertEqual(t, int64(1), stats.Active)
+	assertEqual(t, int64(1), stats.Dropped)
+	close(release)
+	assertNil(t, subject.Close())
+	assertEqual(t, int64(2), subject.Stats().Completed)
+}
+
+func testWorkerPoolTasksAreDroppedAfterClose(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := xcache.NewWorkerPool(xcache.WorkerPoolConfig{})
+	assertNil(t, subject.Close())
+
+	// act
+	result := subject.Submit(func() {
+		t.Error("task should not be executed")
+	})
+
+	// assert
+	assertTrue(t, !result)
+	assertEqual(t, int64(1), subject.Stats().Dropped)
+	assertNil(t, subject.Close()) // closing again has no effect.
+}
@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachedynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/actforgood/xcache"
+)
+
+// item's attributes names.
+const (
+	attrKey       = "pk"
+	attrValue     = "v"
+	attrExpiresAt = "expires_at"
+)
+
+// ifAbsentCondition conditions a write on the item not existing, or being expired.
+const ifAbsentCondition = "attribute_not_exists(" + attrKey + ") OR " + attrExpiresAt + " <= :now"
+
+// Client is a xcache.DynamoDBClient backed by an aws-sdk-go-v2 DynamoDB client.
+type Client struct {
+	db *dynamodb.Client
+}
+
+// NewClient instantiates a new Client upon given DynamoDB client.
+func NewClient(db *dynamodb.Client) *Client {
+	return &Client{db: db}
+}
+
+// GetItem returns the item with given key from given table, or xcache.ErrNotFound, if it does not exist.
+func (client *Client) GetItem(ctx context.Context, table, key string) (xcache.DynamoDBItem, float64, error) {
+	out, err := client.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:              aws.String(table),
+		Key:                    keyAttr(key),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return xcache.DynamoDBItem{}, 0, err
+	}
+	consumed := capacityUnits(out.ConsumedCapacity)
+	if len(out.Item) == 0 {
+		return xcache.DynamoDBItem{}, consumed, xcache.ErrNotFound
+	}
+
+	item := xcache.DynamoDBItem{Key: key}
+	value, ok := out.Item[attrValue].(*types.AttributeValueMemberB)
+	if !ok {
+		return xcache.DynamoDBItem{}, consumed, fmt.Errorf("xcachedynamo: attribute %q is not binary", attrValue)
+	}
+	item.Value = value.Value
+	if expiresAt, ok := out.Item[attrExpiresAt].(*types.AttributeValueMemberN); ok {
+		if item.ExpiresAt, err = strconv.ParseInt(expiresAt.Value, 10, 64); err != nil {
+			return xcache.DynamoDBItem{}, consumed, fmt.Errorf("xcachedynamo: attribute %q: %w", attrExpiresAt, err)
+		}
+	}
+
+	return item, consumed, nil
+}
+
+// PutItem writes (creates, or replaces) an item.
+// If the write's condition is not met, xcache.ErrConditionFailed is returned.
+func (client *Client) PutItem(ctx context.Context, put xcache.DynamoDBPut) (float64, error) {
+	attrs := keyAttr(put.Item.Key)
+	attrs[attrValue] = &types.AttributeValueMemberB{Value: put.Item.Value}
+	if put.Item.ExpiresAt != 0 {
+		attrs[attrExpiresAt] = numberAttr(put.Item.ExpiresAt)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:              aws.String(put.Table),
+		Item:                   attrs,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	if put.IfAbsentAt != 0 {
+		input.ConditionExpression = aws.String(ifAbsentCondition)
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":now": numberAttr(put.IfAbsentAt),
+		}
+	}
+
+	out, err := client.db.PutItem(ctx, input)
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return 0, xcache.ErrConditionFailed
+	} else if err != nil {
+		return 0, err
+	}
+
+	return capacityUnits(out.ConsumedCapacity), nil
+}
+
+// DeleteItem deletes the item with given key from given table.
+func (client *Client) DeleteItem(ctx context.Context, table, key string) (float64, error) {
+	out, err := client.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:              aws.String(table),
+		Key:                    keyAttr(key),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return capacityUnits(out.ConsumedCapacity), nil
+}
+
+// ItemCount returns the (approximate, updated by DynamoDB about every 6 hours) no. of items of given table.
+func (client *Client) ItemCount(ctx context.Context, table string) (int64, error) {
+	out, err := client.db.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if out.Table == nil {
+		return 0, nil
+	}
+
+	return aws.ToInt64(out.Table.ItemCount), nil
+}
+
+// keyAttr returns the attributes map with given partition key.
+func keyAttr(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		attrKey: &types.AttributeValueMemberS{Value: key},
+	}
+}
+
+// numberAttr returns given integer as a number attribute.
+func numberAttr(n int64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+// capacityUnits returns the total consumed capacity units, 0 if they were not returned.
+func capacityUnits(consumed *types.ConsumedCapacity) float64 {
+	if consumed == nil {
+		return 0
+	}
+
+	return aws.ToFloat64(consumed.CapacityUnits)
+}
@@ -0,0 +1,341 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachedynamo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachedynamo"
+)
+
+func init() {
+	var _ xcache.DynamoDBClient = (*xcachedynamo.Client)(nil) // test Client is a xcache.DynamoDBClient
+}
+
+const testTable = "xcache-test"
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get / put / delete / item count", testClientGetPutDeleteItemCount)
+	t.Run("conditional put", testClientConditionalPut)
+	t.Run("error is returned", testClientErrorIsReturned)
+	t.Run("dynamodb cache", testClientDynamoDBCache)
+}
+
+func testClientGetPutDeleteItemCount(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fake    = newFakeDynamoDB(t)
+		subject = xcachedynamo.NewClient(fake.client())
+		ctx     = context.Background()
+	)
+
+	// act & assert put
+	consumed, err := subject.PutItem(ctx, xcache.DynamoDBPut{
+		Table: testTable,
+		Item:  xcache.DynamoDBItem{Key: "a", Value: []byte("value a"), ExpiresAt: 1700000000},
+	})
+	assertNil(t, err)
+	assertEqual(t, 1.0, consumed)
+	assertEqual(t, map[string]fakeAttr{
+		"pk":         {"S": "a"},
+		"v":          {"B": "dmFsdWUgYQ=="},
+		"expires_at": {"N": "1700000000"},
+	}, fake.lastRequest().Item)
+	assertEqual(t, "", fake.lastRequest().ConditionExpression)
+	_, err = subject.PutItem(ctx, xcache.DynamoDBPut{
+		Table: testTable,
+		Item:  xcache.DynamoDBItem{Key: "b", Value: []byte("value b")},
+	})
+	assertNil(t, err)
+	assertEqual(t, map[string]fakeAttr{
+		"pk": {"S": "b"},
+		"v":  {"B": "dmFsdWUgYg=="},
+	}, fake.lastRequest().Item)
+
+	// act & assert get
+	item, consumed, err := subject.GetItem(ctx, testTable, "a")
+	assertNil(t, err)
+	assertEqual(t, 0.5, consumed)
+	assertEqual(t, xcache.DynamoDBItem{Key: "a", Value: []byte("value a"), ExpiresAt: 1700000000}, item)
+	assertEqual(t, "TOTAL", fake.lastRequest().ReturnConsumedCapacity)
+	item, _, err = subject.GetItem(ctx, testTable, "b")
+	assertNil(t, err)
+	assertEqual(t, xcache.DynamoDBItem{Key: "b", Value: []byte("value b")}, item)
+
+	// act & assert item count
+	count, err := subject.ItemCount(ctx, testTable)
+	assertNil(t, err)
+	assertEqual(t, int64(2), count)
+
+	// act & assert delete
+	consumed, err = subject.DeleteItem(ctx, testTable, "a")
+	assertNil(t, err)
+	assertEqual(t, 1.0, consumed)
+	_, consumed, err = subject.GetItem(ctx, testTable, "a")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertEqual(t, 0.5, consumed)
+	count, err = subject.ItemCount(ctx, testTable)
+	assertNil(t, err)
+	assertEqual(t, int64(1), count)
+}
+
+func testClientConditionalPut(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fake    = newFakeDynamoDB(t)
+		subject = xcachedynamo.NewClient(fake.client())
+		ctx     = context.Background()
+		now     = time.Now().Unix()
+		put     = xcache.DynamoDBPut{
+			Table:      testTable,
+			Item:       xcache.DynamoDBItem{Key: "a", Value: []byte("value a"), ExpiresAt: now + 60},
+			IfAbsentAt: now,
+		}
+	)
+
+	// act & assert absent key is written
+	_, err := subject.PutItem(ctx, put)
+	assertNil(t, err)
+	assertEqual(t, "attribute_not_exists(pk) OR expires_at <= :now", fake.lastRequest().ConditionExpression)
+	assertEqual(
+		t,
+		map[string]fakeAttr{":now": {"N": strconv.FormatInt(now, 10)}},
+		fake.lastRequest().ExpressionAttributeValues,
+	)
+
+	// act & assert existing key is not written
+	put.Item.Value = []byte("new value a")
+	_, err = subject.PutItem(ctx, put)
+	assertTrue(t, errors.Is(err, xcache.ErrConditionFailed))
+	item, _, err := subject.GetItem(ctx, testTable, "a")
+	assertNil(t, err)
+	assertEqual(t, []byte("value a"), item.Value)
+
+	// act & assert expired key is written
+	put.IfAbsentAt = now + 60
+	_, err = subject.PutItem(ctx, put)
+	assertNil(t, err)
+	item, _, err = subject.GetItem(ctx, testTable, "a")
+	assertNil(t, err)
+	assertEqual(t, []byte("new value a"), item.Value)
+}
+
+func testClientErrorIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fake    = newFakeDynamoDB(t)
+		subject = xcachedynamo.NewClient(fake.client())
+		ctx     = context.Background()
+		table   = "unknown-table"
+		errNF   *types.ResourceNotFoundException
+	)
+
+	// act
+	_, _, errGet := subject.GetItem(ctx, table, "a")
+	_, errPut := subject.PutItem(ctx, xcache.DynamoDBPut{Table: table, Item: xcache.DynamoDBItem{Key: "a"}})
+	_, errDel := subject.DeleteItem(ctx, table, "a")
+	_, errCount := subject.ItemCount(ctx, table)
+
+	// assert
+	assertTrue(t, errors.As(errGet, &errNF))
+	assertTrue(t, errors.As(errPut, &errNF))
+	assertTrue(t, errors.As(errDel, &errNF))
+	assertTrue(t, errors.As(errCount, &errNF))
+}
+
+func testClientDynamoDBCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fake  = newFakeDynamoDB(t)
+		cache = xcache.NewDynamoDB(
+			xcachedynamo.NewClient(fake.client()),
+			xcache.DynamoDBConfig{Table: testTable},
+		)
+		ctx = context.Background()
+	)
+
+	// act & assert
+	requireNil(t, cache.Save(ctx, "a", []byte("value a"), time.Minute))
+	value, err := cache.Load(ctx, "a")
+	assertNil(t, err)
+	assertEqual(t, []byte("value a"), value)
+	ttl, err := cache.TTL(ctx, "a")
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Second && ttl <= time.Minute+time.Second)
+
+	saved, err := cache.SaveIfAbsent(ctx, "a", []byte("new value a"), xcache.NoExpire)
+	assertNil(t, err)
+	assertTrue(t, !saved)
+	saved, err = cache.SaveIfAbsent(ctx, "b", []byte("value b"), xcache.NoExpire)
+	assertNil(t, err)
+	assertTrue(t, saved)
+	ttl, err = cache.TTL(ctx, "b")
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	requireNil(t, cache.Delete(ctx, "a"))
+	_, err = cache.Load(ctx, "a")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	stats, err := cache.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, int64(1), stats.Hits)
+	assertEqual(t, int64(1), stats.Misses)
+	metrics := make(map[string]float64)
+	cache.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"dynamodb.consumed_rcu": 2.0, "dynamodb.consumed_wcu": 3.0}, metrics)
+}
+
+// fakeAttr is an attribute value, as serialized by DynamoDB JSON protocol, like {"S": "a"}.
+type fakeAttr map[string]string
+
+// fakeRequest is a (subset of a) DynamoDB request.
+type fakeRequest struct {
+	TableName                 string
+	Key                       map[string]fakeAttr
+	Item                      map[string]fakeAttr
+	ConditionExpression       string
+	ExpressionAttributeValues map[string]fakeAttr
+	ReturnConsumedCapacity    string
+}
+
+// fakeDynamoDB is an in memory DynamoDB server, handling a single table, GetItem / PutItem / DeleteItem /
+// DescribeTable operations, and PutItem's "attribute_not_exists(pk) OR expires_at <= :now" condition.
+type fakeDynamoDB struct {
+	server *httptest.Server
+	items  map[string]map[string]fakeAttr
+	last   fakeRequest
+	mu     sync.Mutex
+}
+
+func newFakeDynamoDB(t *testing.T) *fakeDynamoDB {
+	t.Helper()
+
+	fake := &fakeDynamoDB{items: make(map[string]map[string]fakeAttr)}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+// client returns a DynamoDB client which sends its requests to the fake server.
+func (fake *fakeDynamoDB) client() *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		BaseEndpoint: aws.String(fake.server.URL),
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+// lastRequest returns the last handled request.
+func (fake *fakeDynamoDB) lastRequest() fakeRequest {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.last
+}
+
+func (fake *fakeDynamoDB) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var req fakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeError(w, "SerializationException", err.Error())
+
+		return
+	}
+	if req.TableName != testTable {
+		writeFakeError(w, "ResourceNotFoundException", "Requested resource not found")
+
+		return
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.last = req
+
+	consumed := map[string]any{"TableName": req.TableName, "CapacityUnits": 1.0}
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "GetItem":
+		consumed["CapacityUnits"] = 0.5
+		resp := map[string]any{"ConsumedCapacity": consumed}
+		if item, found := fake.items[req.Key["pk"]["S"]]; found {
+			resp["Item"] = item
+		}
+		writeFakeResponse(w, http.StatusOK, resp)
+	case "PutItem":
+		key := req.Item["pk"]["S"]
+		if req.ConditionExpression != "" && !fake.isAbsent(key, req.ExpressionAttributeValues[":now"]["N"]) {
+			writeFakeError(w, "ConditionalCheckFailedException", "The conditional request failed")
+
+			return
+		}
+		fake.items[key] = req.Item
+		writeFakeResponse(w, http.StatusOK, map[string]any{"ConsumedCapacity": consumed})
+	case "DeleteItem":
+		delete(fake.items, req.Key["pk"]["S"])
+		writeFakeResponse(w, http.StatusOK, map[string]any{"ConsumedCapacity": consumed})
+	case "DescribeTable":
+		writeFakeResponse(w, http.StatusOK, map[string]any{
+			"Table": map[string]any{"TableName": req.TableName, "ItemCount": len(fake.items)},
+		})
+	default:
+		writeFakeError(w, "UnknownOperationException", "")
+	}
+}
+
+// isAbsent returns true if the item with given key does not exist, or it's expired at given moment.
+func (fake *fakeDynamoDB) isAbsent(key, now string) bool {
+	item, found := fake.items[key]
+	if !found {
+		return true
+	}
+	expiresAt, hasExpiration := item["expires_at"]
+	if !hasExpiration {
+		return false
+	}
+	expiresAtN, _ := strconv.ParseInt(expiresAt["N"], 10, 64)
+	nowN, _ := strconv.ParseInt(now, 10, 64)
+
+	return expiresAtN <= nowN
+}
+
+func writeFakeResponse(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeFakeError(w http.ResponseWriter, errType, message string) {
+	writeFakeResponse(w, http.StatusBadRequest, map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + errType,
+		"message": message,
+	})
+}
@@ -0,0 +1,18 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachedynamo provides a xcache.DynamoDBClient backed by an aws-sdk-go-v2 DynamoDB client
+// (through [github.com/aws/aws-sdk-go-v2/service/dynamodb]), the client a xcache.DynamoDB cache is built upon.
+// Items are stored as {pk: S, v: B, expires_at: N}; enable the table's TTL on expires_at attribute.
+//
+// Example:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	client := xcachedynamo.NewClient(dynamodb.NewFromConfig(cfg))
+//	cache := xcache.NewDynamoDB(client, xcache.DynamoDBConfig{Table: "my-cache"})
+package xcachedynamo
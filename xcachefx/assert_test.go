@@ -0,0 +1,44 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachefx_test
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Note: this file contains some assertion utilities.
+
+// assertEqual checks if 2 values are equal.
+// Returns successful assertion status.
+func assertEqual(t *testing.T, expected any, actual any) bool {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf(
+			"\n\t"+`expected "%+v" (%T),`+
+				"\n\t"+`but got  "%+v" (%T)`+"\n",
+			expected, expected,
+			actual, actual,
+		)
+
+		return false
+	}
+
+	return true
+}
+
+// assertTrue checks if value passed is true.
+// Returns successful assertion status.
+func assertTrue(t *testing.T, actual bool) bool {
+	t.Helper()
+	if !actual {
+		t.Error("should be true")
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,18 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachefx provides [go.uber.org/fx] constructors for xcache caches,
+// which register caches' Close / StatsWatcher's Close to the application lifecycle.
+//
+// Example:
+//
+//	app := fx.New(
+//		fx.Provide(newXConfConfig), // your application's xconf.Config provider
+//		xcachefx.Redis7WithConfigModule,
+//		fx.Invoke(xcachefx.WatchStats(time.Minute, func(ctx context.Context, stats xcache.Stats, err error) {
+//			// log / send stats to a metrics system
+//		})),
+//	)
+package xcachefx
@@ -0,0 +1,106 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachefx
+
+import (
+	"context"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+	"go.uber.org/fx"
+)
+
+// Modules which provide a cache both as its concrete type, and as xcache.Cache.
+var (
+	// Redis6Module provides a *xcache.Redis6 / xcache.Cache, based on a xcache.RedisConfig.
+	Redis6Module = fx.Module("xcache-redis6", fx.Provide(NewRedis6, asCache[*xcache.Redis6]))
+	// Redis6WithConfigModule provides a *xcache.Redis6 / xcache.Cache, based on a xconf.Config.
+	Redis6WithConfigModule = fx.Module("xcache-redis6", fx.Provide(NewRedis6WithConfig, asCache[*xcache.Redis6]))
+	// Redis7Module provides a *xcache.Redis7 / xcache.Cache, based on a xcache.RedisConfig.
+	Redis7Module = fx.Module("xcache-redis7", fx.Provide(NewRedis7, asCache[*xcache.Redis7]))
+	// Redis7WithConfigModule provides a *xcache.Redis7 / xcache.Cache, based on a xconf.Config.
+	Redis7WithConfigModule = fx.Module("xcache-redis7", fx.Provide(NewRedis7WithConfig, asCache[*xcache.Redis7]))
+	// MemoryWithConfigModule provides a *xcache.Memory / xcache.Cache, based on a xconf.Config.
+	MemoryWithConfigModule = fx.Module(
+		"xcache-memory",
+		fx.Provide(xcache.NewMemoryWithValidConfig, asCache[*xcache.Memory]),
+	)
+)
+
+// NewRedis6 instantiates a new Redis6 cache, which is closed on application stop.
+func NewRedis6(lc fx.Lifecycle, config xcache.RedisConfig) *xcache.Redis6 {
+	cache := xcache.NewRedis6(config)
+	lc.Append(fx.StopHook(cache.Close))
+
+	return cache
+}
+
+// NewRedis6WithConfig instantiates a new Redis6 cache based on a xconf.Config,
+// which is closed on application stop.
+// An invalid configuration makes the application fail to start (see xcache.NewRedis6WithValidConfig).
+func NewRedis6WithConfig(lc fx.Lifecycle, config xconf.Config) (*xcache.Redis6, error) {
+	cache, err := xcache.NewRedis6WithValidConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.StopHook(cache.Close))
+
+	return cache, nil
+}
+
+// NewRedis7 instantiates a new Redis7 cache, which is closed on application stop.
+func NewRedis7(lc fx.Lifecycle, config xcache.RedisConfig) *xcache.Redis7 {
+	cache := xcache.NewRedis7(config)
+	lc.Append(fx.StopHook(cache.Close))
+
+	return cache
+}
+
+// NewRedis7WithConfig instantiates a new Redis7 cache based on a xconf.Config,
+// which is closed on application stop.
+// An invalid configuration makes the application fail to start (see xcache.NewRedis7WithValidConfig).
+func NewRedis7WithConfig(lc fx.Lifecycle, config xconf.Config) (*xcache.Redis7, error) {
+	cache, err := xcache.NewRedis7WithValidConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.StopHook(cache.Close))
+
+	return cache, nil
+}
+
+// WatchStats returns a function to be passed to fx.Invoke, which starts watching
+// the provided xcache.Cache's stats on application start, and stops on application stop.
+// See xcache.StatsWatcher for more details.
+func WatchStats(
+	interval time.Duration,
+	fn func(context.Context, xcache.Stats, error),
+) func(fx.Lifecycle, xcache.Cache) {
+	return func(lc fx.Lifecycle, cache xcache.Cache) {
+		var (
+			watcher        = xcache.NewStatsWatcher(cache, interval)
+			ctx, cancelCtx = context.WithCancel(context.Background())
+		)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				watcher.Watch(ctx, fn)
+
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancelCtx()
+
+				return watcher.Close()
+			},
+		})
+	}
+}
+
+// asCache returns given cache as a xcache.Cache.
+func asCache[T xcache.Cache](cache T) xcache.Cache {
+	return cache
+}
@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachefx_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachefx"
+	"github.com/actforgood/xconf"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestMemoryWithConfigModule(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config = xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, 1024*1024)
+		cache  xcache.Cache
+		memory *xcache.Memory
+		app    = fxtest.New(
+			t,
+			fx.Supply(fx.Annotate(config, fx.As(new(xconf.Config)))),
+			xcachefx.MemoryWithConfigModule,
+			fx.Populate(&cache, &memory),
+		)
+	)
+
+	// act
+	app.RequireStart()
+	defer app.RequireStop()
+
+	// assert
+	assertTrue(t, cache != nil)
+	assertTrue(t, cache == xcache.Cache(memory))
+	stats, _ := memory.Stats(context.Background())
+	assertEqual(t, int64(1024*1024), stats.MaxMemory)
+}
+
+func TestMemoryWithConfigModule_invalidConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config = xconf.NewMockConfig(xcache.MemoryCfgKeyMemorySize, "1 Mb")
+		memory *xcache.Memory
+	)
+
+	// act
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(fx.Annotate(config, fx.As(new(xconf.Config)))),
+		xcachefx.MemoryWithConfigModule,
+		fx.Populate(&memory),
+	)
+
+	// assert
+	assertTrue(t, app.Err() != nil)
+	assertTrue(t, memory == nil)
+}
+
+func TestRedis7Module(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config = xcache.RedisConfig{Addrs: []string{"127.0.0.1:6379"}}
+		cache  xcache.Cache
+		redis  *xcache.Redis7
+		app    = fxtest.New(
+			t,
+			fx.Supply(config),
+			xcachefx.Redis7Module,
+			fx.Populate(&cache, &redis),
+		)
+	)
+
+	// act
+	app.RequireStart()
+	app.RequireStop()
+
+	// assert
+	assertTrue(t, cache == xcache.Cache(redis))
+	assertTrue(t, redis.Close() != nil) // already closed on application stop.
+}
+
+func TestRedis6Module(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config = xcache.RedisConfig{Addrs: []string{"127.0.0.1:6379"}}
+		cache  xcache.Cache
+		redis  *xcache.Redis6
+		app    = fxtest.New(
+			t,
+			fx.Supply(config),
+			xcachefx.Redis6Module,
+			fx.Populate(&cache, &redis),
+		)
+	)
+
+	// act
+	app.RequireStart()
+	app.RequireStop()
+
+	// assert
+	assertTrue(t, cache == xcache.Cache(redis))
+	assertTrue(t, redis.Close() != nil) // already closed on application stop.
+}
+
+func TestWatchStats(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = new(xcache.Mock)
+		callsCnt uint32
+		app      = fxtest.New(
+			t,
+			fx.Supply(fx.Annotate(cache, fx.As(new(xcache.Cache)))),
+			fx.Invoke(xcachefx.WatchStats(100*time.Millisecond, func(context.Context, xcache.Stats, error) {
+				atomic.AddUint32(&callsCnt, 1)
+			})),
+		)
+	)
+
+	// act
+	app.RequireStart()
+	time.Sleep(350 * time.Millisecond)
+	app.RequireStop()
+	callsCntAtStop := atomic.LoadUint32(&callsCnt)
+	time.Sleep(250 * time.Millisecond)
+
+	// assert
+	assertTrue(t, callsCntAtStop >= 3)
+	assertEqual(t, callsCntAtStop, atomic.LoadUint32(&callsCnt)) // no more calls after stop.
+}
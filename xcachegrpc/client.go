@@ -0,0 +1,156 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachegrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/actforgood/xcache"
+)
+
+// authorizationHeader is the metadata key the bearer token is sent in.
+const authorizationHeader = "authorization"
+
+// ClientConfig holds the settings of a Client.
+type ClientConfig struct {
+	// TLSConfig is the TLS configuration to use. By default (nil), the connection is not secured.
+	TLSConfig *tls.Config
+	// Token returns the bearer token sent with each call (in the "authorization" header).
+	// By default (nil), no token is sent.
+	// Note: without TLSConfig the token travels in clear, which is acceptable only on a trusted network
+	// (like a sidecar, on localhost).
+	Token func(ctx context.Context) (string, error)
+	// DialOptions are additional options for the underlying gRPC client connection
+	// (like interceptors, keepalive, or a custom dialer).
+	DialOptions []grpc.DialOption
+}
+
+// Client is a Cache implementation talking to a cache exposed over gRPC (see NewServer / Register).
+// A not found key is reported as ErrNotFound; other server errors are returned as gRPC status errors.
+// It implements io.Closer, and thus it should be closed at your application shutdown.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient instantiates a new Client, for given target (like "dns:///cache.internal:7070"),
+// according to given settings. The connection is established lazily, at first call.
+// It returns an error if the target, or settings are invalid.
+func NewClient(target string, config ClientConfig) (*Client, error) {
+	creds := insecure.NewCredentials()
+	if config.TLSConfig != nil {
+		creds = credentials.NewTLS(config.TLSConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if config.Token != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{
+			token:  config.Token,
+			secure: config.TLSConfig != nil,
+		}))
+	}
+	opts = append(opts, config.DialOptions...)
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+// Save stores the given key-value with expiration period into remote cache.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// It returns an error if the key could not be saved.
+func (client *Client) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	req := newMessage(saveRequestDesc).
+		setString("key", key).
+		setBytes("value", value).
+		setInt64("expire_nanos", int64(expire))
+
+	return client.invoke(ctx, "Save", req.dynamicMessage, new(emptypb.Empty))
+}
+
+// Load returns a key's value from remote cache, or an error if something bad happened.
+// If the key is not found, ErrNotFound is returned.
+func (client *Client) Load(ctx context.Context, key string) ([]byte, error) {
+	resp := newMessage(loadResponseDesc)
+	err := client.invoke(ctx, "Load", newMessage(keyRequestDesc).setString("key", key).dynamicMessage, resp.dynamicMessage)
+	if status.Code(err) == codes.NotFound {
+		return nil, xcache.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return resp.getBytes("value"), nil
+}
+
+// TTL returns a key's remaining time to live from remote cache, or an error if something bad happened.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (client *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	resp := newMessage(ttlResponseDesc)
+	err := client.invoke(ctx, "TTL", newMessage(keyRequestDesc).setString("key", key).dynamicMessage, resp.dynamicMessage)
+	if err != nil {
+		return -1, err
+	}
+
+	return time.Duration(resp.getInt64("ttl_nanos")), nil
+}
+
+// Stats returns remote cache's statistics.
+func (client *Client) Stats(ctx context.Context) (xcache.Stats, error) {
+	resp := newMessage(statsResponseDesc)
+	if err := client.invoke(ctx, "Stats", new(emptypb.Empty), resp.dynamicMessage); err != nil {
+		return xcache.Stats{}, err
+	}
+
+	return messageToStats(resp), nil
+}
+
+// Close closes the underlying gRPC connection.
+func (client *Client) Close() error {
+	return client.conn.Close()
+}
+
+// invoke calls given method of the cache service.
+func (client *Client) invoke(ctx context.Context, method string, req, resp any) error {
+	return client.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp)
+}
+
+// bearerToken is a credentials.PerRPCCredentials sending a bearer token.
+type bearerToken struct {
+	token  func(ctx context.Context) (string, error)
+	secure bool
+}
+
+// GetRequestMetadata returns the "authorization" header.
+func (creds bearerToken) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := creds.token(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return map[string]string{authorizationHeader: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity returns whether the token is sent only over a secured connection.
+func (creds bearerToken) RequireTransportSecurity() bool {
+	return creds.secure
+}
@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachegrpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachegrpc"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcachegrpc.Client)(nil) // test Client is a Cache
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save, load, ttl, stats", testClientSaveLoadTTLStats)
+	t.Run("not found key", testClientNotFoundKey)
+	t.Run("authorization", testClientAuthorization)
+	t.Run("server errors are returned", testClientServerErrorsAreReturned)
+}
+
+func testClientSaveLoadTTLStats(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(1024 * 1024)
+		subject = newClient(t, cache, xcachegrpc.ServerConfig{}, xcachegrpc.ClientConfig{})
+		ctx     = context.Background()
+		key     = "test-grpc-key"
+		value   = []byte("test value")
+	)
+
+	// act & assert
+	requireNil(t, subject.Save(ctx, key, value, time.Minute))
+	loadedValue, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, loadedValue)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 58*time.Second && ttl <= time.Minute)
+
+	requireNil(t, subject.Save(ctx, key, value, xcache.NoExpire))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	expectedStats, _ := cache.Stats(ctx)
+	assertEqual(t, expectedStats, stats)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, int64(1), stats.Hits)
+
+	requireNil(t, subject.Save(ctx, key, nil, -1))
+	ttl, err = subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl < 0)
+}
+
+func testClientNotFoundKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = newClient(t, xcache.NewMemory(1024*1024), xcachegrpc.ServerConfig{}, xcachegrpc.ClientConfig{})
+		ctx     = context.Background()
+	)
+
+	// act
+	value, err := subject.Load(ctx, "test-grpc-not-found-key")
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, value)
+}
+
+func testClientAuthorization(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache        = xcache.NewMemory(1024 * 1024)
+		ctx          = context.Background()
+		key          = "test-grpc-auth-key"
+		calledMethod string
+		serverConfig = xcachegrpc.ServerConfig{
+			Authorize: func(ctx context.Context, fullMethod string) error {
+				calledMethod = fullMethod
+				switch xcachegrpc.BearerToken(ctx) {
+				case "secret":
+					return nil
+				case "":
+					return status.Error(codes.Unauthenticated, "missing token")
+				default:
+					return errors.New("invalid token")
+				}
+			},
+		}
+		tokenConfig = func(token string) xcachegrpc.ClientConfig {
+			return xcachegrpc.ClientConfig{
+				Token: func(context.Context) (string, error) { return token, nil },
+			}
+		}
+		authorized   = newClient(t, cache, serverConfig, tokenConfig("secret"))
+		unauthorized = newClient(t, cache, serverConfig, tokenConfig("guess"))
+		anonymous    = newClient(t, cache, serverConfig, xcachegrpc.ClientConfig{})
+	)
+
+	// act
+	errAuthorized := authorized.Save(ctx, key, []byte("test value"), time.Minute)
+	_, errUnauthorized := unauthorized.Load(ctx, key)
+	_, errAnonymous := anonymous.Stats(ctx)
+
+	// assert
+	assertNil(t, errAuthorized)
+	assertEqual(t, codes.PermissionDenied, status.Code(errUnauthorized))
+	assertEqual(t, codes.Unauthenticated, status.Code(errAnonymous))
+	assertEqual(t, "/xcache.v1.Cache/Stats", calledMethod)
+}
+
+func testClientServerErrorsAreReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = newClient(t, cache, xcachegrpc.ServerConfig{}, xcachegrpc.ClientConfig{})
+		ctx     = context.Background()
+		errDB   = errors.New("connection refused")
+	)
+	cache.SetSaveCallback(func(context.Context, string, []byte, time.Duration) error {
+		return errDB
+	})
+	cache.SetTTLCallback(func(context.Context, string) (time.Duration, error) {
+		return 0, context.DeadlineExceeded
+	})
+
+	// act
+	errSave := subject.Save(ctx, "test-grpc-error-key", []byte("test value"), time.Minute)
+	ttl, errTTL := subject.TTL(ctx, "test-grpc-error-key")
+
+	// assert
+	assertEqual(t, codes.Internal, status.Code(errSave))
+	assertEqual(t, codes.DeadlineExceeded, status.Code(errTTL))
+	assertTrue(t, ttl < 0)
+}
+
+// newClient returns a Client of given cache, served over an in memory connection.
+func newClient(
+	t *testing.T,
+	cache xcache.Cache,
+	serverConfig xcachegrpc.ServerConfig,
+	clientConfig xcachegrpc.ClientConfig,
+) *xcachegrpc.Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := xcachegrpc.NewServer(cache, serverConfig)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	clientConfig.DialOptions = append(clientConfig.DialOptions, grpc.WithContextDialer(
+		func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		},
+	))
+	client, err := xcachegrpc.NewClient("passthrough:///bufnet", clientConfig)
+	requireNil(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
@@ -0,0 +1,34 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachegrpc exposes a xcache.Cache over gRPC (the "xcache.v1.Cache" service, see cache.proto),
+// and provides Client, a xcache.Cache implementation talking to such a server, so that a (local, Memory)
+// cache can be shared by processes on other hosts, or a cache can be fronted by a sidecar.
+//
+// Transport security is configured through a tls.Config, and authentication through a bearer token,
+// sent by Client (ClientConfig.Token) and checked by the server (ServerConfig.Authorize, see BearerToken).
+//
+// Example:
+//
+//	// server
+//	server := xcachegrpc.NewServer(xcache.NewMemory(256*1024*1024), xcachegrpc.ServerConfig{
+//		TLSConfig: serverTLSConfig,
+//		Authorize: func(ctx context.Context, _ string) error {
+//			if xcachegrpc.BearerToken(ctx) != token {
+//				return status.Error(codes.Unauthenticated, "invalid token")
+//			}
+//
+//			return nil
+//		},
+//	})
+//	lis, err := net.Listen("tcp", ":7070")
+//	go func() { _ = server.Serve(lis) }()
+//
+//	// client
+//	cache, err := xcachegrpc.NewClient("cache.internal:7070", xcachegrpc.ClientConfig{
+//		TLSConfig: clientTLSConfig,
+//		Token:     func(context.Context) (string, error) { return token, nil },
+//	})
+package xcachegrpc
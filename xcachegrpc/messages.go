@@ -0,0 +1,169 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachegrpc
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/actforgood/xcache"
+)
+
+// Note: messages are built at runtime (dynamicpb), from the descriptors below, mirroring cache.proto,
+// so no generated code (and protoc toolchain) is needed; on the wire, they are regular protobuf messages.
+
+// Descriptors of the messages of cache.proto.
+var (
+	saveRequestDesc   protoreflect.MessageDescriptor
+	keyRequestDesc    protoreflect.MessageDescriptor
+	loadResponseDesc  protoreflect.MessageDescriptor
+	ttlResponseDesc   protoreflect.MessageDescriptor
+	statsResponseDesc protoreflect.MessageDescriptor
+	emptyDesc         = (&emptypb.Empty{}).ProtoReflect().Descriptor()
+)
+
+// statsFields are the fields of StatsResponse message.
+var statsFields = []string{
+	"memory", "max_memory", "hits", "misses", "keys", "expired", "evicted", "bytes_read", "bytes_written",
+}
+
+func init() {
+	statsResponse := &descriptorpb.DescriptorProto{Name: proto.String("StatsResponse")}
+	for idx, name := range statsFields {
+		statsResponse.Field = append(statsResponse.Field, field(name, idx+1, descriptorpb.FieldDescriptorProto_TYPE_INT64))
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("xcachegrpc/cache.proto"),
+		Package:    proto.String("xcache.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/empty.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("SaveRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+					field("expire_nanos", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				},
+			},
+			{
+				Name:  proto.String("KeyRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)},
+			},
+			{
+				Name:  proto.String("LoadResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("value", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES)},
+			},
+			{
+				Name: proto.String("TTLResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("ttl_nanos", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				},
+			},
+			statsResponse,
+		},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic("xcachegrpc: invalid descriptor: " + err.Error())
+	}
+
+	messages := file.Messages()
+	saveRequestDesc = messages.ByName("SaveRequest")
+	keyRequestDesc = messages.ByName("KeyRequest")
+	loadResponseDesc = messages.ByName("LoadResponse")
+	ttlResponseDesc = messages.ByName("TTLResponse")
+	statsResponseDesc = messages.ByName("StatsResponse")
+}
+
+// field returns the descriptor of a (proto3, singular) field.
+func field(name string, number int, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(int32(number)),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+// dynamicMessage is the type of the (request / response) messages, as exchanged with grpc.
+type dynamicMessage = *dynamicpb.Message
+
+// message is a dynamic protobuf message, with fields accessed by name.
+type message struct {
+	dynamicMessage
+}
+
+// newMessage returns a new, empty, message of given type.
+func newMessage(desc protoreflect.MessageDescriptor) message {
+	return message{dynamicpb.NewMessage(desc)}
+}
+
+func (m message) fieldByName(name string) protoreflect.FieldDescriptor {
+	return m.Descriptor().Fields().ByName(protoreflect.Name(name))
+}
+
+func (m message) getString(name string) string {
+	return m.Get(m.fieldByName(name)).String()
+}
+
+func (m message) getBytes(name string) []byte {
+	return m.Get(m.fieldByName(name)).Bytes()
+}
+
+func (m message) getInt64(name string) int64 {
+	return m.Get(m.fieldByName(name)).Int()
+}
+
+func (m message) setString(name, value string) message {
+	m.Set(m.fieldByName(name), protoreflect.ValueOfString(value))
+
+	return m
+}
+
+func (m message) setBytes(name string, value []byte) message {
+	m.Set(m.fieldByName(name), protoreflect.ValueOfBytes(value))
+
+	return m
+}
+
+func (m message) setInt64(name string, value int64) message {
+	m.Set(m.fieldByName(name), protoreflect.ValueOfInt64(value))
+
+	return m
+}
+
+// statsToMessage converts given stats to a StatsResponse message.
+func statsToMessage(stats xcache.Stats) message {
+	msg := newMessage(statsResponseDesc)
+	for idx, value := range statsValues(&stats) {
+		msg.setInt64(statsFields[idx], *value)
+	}
+
+	return msg
+}
+
+// messageToStats converts given StatsResponse message to stats.
+func messageToStats(msg message) xcache.Stats {
+	var stats xcache.Stats
+	for idx, value := range statsValues(&stats) {
+		*value = msg.getInt64(statsFields[idx])
+	}
+
+	return stats
+}
+
+// statsValues returns pointers to stats' values, in the order of statsFields.
+func statsValues(stats *xcache.Stats) []*int64 {
+	return []*int64{
+		&stats.Memory, &stats.MaxMemory, &stats.Hits, &stats.Misses, &stats.Keys,
+		&stats.Expired, &stats.Evicted, &stats.BytesRead, &stats.BytesWritten,
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachegrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/actforgood/xcache"
+)
+
+// ServiceName is the full name of the gRPC service.
+const ServiceName = "xcache.v1.Cache"
+
+// ServerConfig holds the settings of the server exposing a cache.
+type ServerConfig struct {
+	// TLSConfig is the TLS configuration to serve with (NewServer only).
+	// By default (nil), the connections are not secured.
+	TLSConfig *tls.Config
+	// Authorize is called before each call, with the call's full method name (like "/xcache.v1.Cache/Load").
+	// A non nil error rejects the call; if it's not a gRPC status error, the call fails with PERMISSION_DENIED.
+	// By default (nil), all calls are allowed.
+	Authorize func(ctx context.Context, fullMethod string) error
+}
+
+// NewServer returns a new gRPC server, exposing given cache, according to given settings.
+// Additional server options (like interceptors, keepalive) can be passed.
+func NewServer(cache xcache.Cache, config ServerConfig, opts ...grpc.ServerOption) *grpc.Server {
+	if config.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.TLSConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	Register(server, cache, config)
+
+	return server
+}
+
+// Register registers the service exposing given cache on given gRPC server
+// (ServerConfig.TLSConfig is ignored, the server's credentials apply).
+func Register(registrar grpc.ServiceRegistrar, cache xcache.Cache, config ServerConfig) {
+	srv := &server{cache: cache, authorize: config.Authorize}
+	registrar.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Save", Handler: srv.handler("Save", newSaveRequest, srv.save)},
+			{MethodName: "Load", Handler: srv.handler("Load", newKeyRequest, srv.load)},
+			{MethodName: "TTL", Handler: srv.handler("TTL", newKeyRequest, srv.ttl)},
+			{MethodName: "Stats", Handler: srv.handler("Stats", newEmpty, srv.stats)},
+		},
+		Metadata: "xcachegrpc/cache.proto",
+	}, srv)
+}
+
+// BearerToken returns the bearer token of an incoming call (from the "authorization" header),
+// or empty string, if there is none.
+func BearerToken(ctx context.Context) string {
+	const scheme = "bearer "
+	for _, value := range metadata.ValueFromIncomingContext(ctx, authorizationHeader) {
+		if len(value) > len(scheme) && strings.EqualFold(value[:len(scheme)], scheme) {
+			return value[len(scheme):]
+		}
+	}
+
+	return ""
+}
+
+// server serves the calls of the cache service.
+type server struct {
+	cache     xcache.Cache
+	authorize func(ctx context.Context, fullMethod string) error
+}
+
+func newSaveRequest() any { return newMessage(saveRequestDesc).dynamicMessage }
+func newKeyRequest() any  { return newMessage(keyRequestDesc).dynamicMessage }
+func newEmpty() any       { return new(emptypb.Empty) }
+
+// handler returns the gRPC handler of a method, decoding the request, authorizing the call,
+// and invoking given implementation (through server's interceptor, if any).
+func (srv *server) handler(
+	method string,
+	newRequest func() any,
+	impl func(ctx context.Context, req any) (any, error),
+) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	fullMethod := "/" + ServiceName + "/" + method
+	call := func(ctx context.Context, req any) (any, error) {
+		if srv.authorize != nil {
+			if err := srv.authorize(ctx, fullMethod); err != nil {
+				if _, ok := status.FromError(err); !ok {
+					err = status.Error(codes.PermissionDenied, err.Error())
+				}
+
+				return nil, err
+			}
+		}
+		resp, err := impl(ctx, req)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+
+		return resp, nil
+	}
+
+	return func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := newRequest()
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(ctx, req)
+		}
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+
+		return interceptor(ctx, req, info, call)
+	}
+}
+
+func (srv *server) save(ctx context.Context, req any) (any, error) {
+	msg := message{req.(dynamicMessage)}
+	err := srv.cache.Save(
+		ctx,
+		msg.getString("key"),
+		msg.getBytes("value"),
+		time.Duration(msg.getInt64("expire_nanos")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(emptypb.Empty), nil
+}
+
+func (srv *server) load(ctx context.Context, req any) (any, error) {
+	value, err := srv.cache.Load(ctx, message{req.(dynamicMessage)}.getString("key"))
+	if err != nil {
+		return nil, err
+	}
+
+	return newMessage(loadResponseDesc).setBytes("value", value).dynamicMessage, nil
+}
+
+func (srv *server) ttl(ctx context.Context, req any) (any, error) {
+	ttl, err := srv.cache.TTL(ctx, message{req.(dynamicMessage)}.getString("key"))
+	if err != nil {
+		return nil, err
+	}
+
+	return newMessage(ttlResponseDesc).setInt64("ttl_nanos", int64(ttl)).dynamicMessage, nil
+}
+
+func (srv *server) stats(ctx context.Context, _ any) (any, error) {
+	stats, err := srv.cache.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return statsToMessage(stats).dynamicMessage, nil
+}
+
+// toStatusError converts a cache error to a gRPC status error.
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, xcache.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachemongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/actforgood/xcache"
+)
+
+// document's fields names.
+const (
+	fieldKey       = "_id"
+	fieldValue     = "value"
+	fieldExpiresAt = "expires_at"
+)
+
+// Collection is a xcache.MongoCollection backed by a mongo-go-driver collection.
+// The collection's client is not disconnected by the Collection, its owner should disconnect it.
+type Collection struct {
+	coll *mongo.Collection
+}
+
+// NewCollection instantiates a new Collection upon given mongo-go-driver collection.
+func NewCollection(coll *mongo.Collection) *Collection {
+	return &Collection{coll: coll}
+}
+
+// FindOne returns the document with given key, or xcache.ErrNotFound, if it does not exist.
+func (c *Collection) FindOne(ctx context.Context, key string) (xcache.MongoDocument, error) {
+	var doc struct {
+		Value     []byte    `bson:"value"`
+		ExpiresAt time.Time `bson:"expires_at,omitempty"`
+	}
+	err := c.coll.FindOne(ctx, bson.D{{Key: fieldKey, Value: key}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return xcache.MongoDocument{}, xcache.ErrNotFound
+	} else if err != nil {
+		return xcache.MongoDocument{}, err
+	}
+
+	return xcache.MongoDocument{Key: key, Value: doc.Value, ExpiresAt: doc.ExpiresAt}, nil
+}
+
+// ReplaceOne replaces (or inserts, if it does not exist - upsert) the document.
+func (c *Collection) ReplaceOne(ctx context.Context, doc xcache.MongoDocument) error {
+	replacement := bson.D{{Key: fieldValue, Value: doc.Value}}
+	if !doc.ExpiresAt.IsZero() {
+		replacement = append(replacement, bson.E{Key: fieldExpiresAt, Value: doc.ExpiresAt})
+	}
+	_, err := c.coll.ReplaceOne(
+		ctx,
+		bson.D{{Key: fieldKey, Value: doc.Key}},
+		replacement,
+		options.Replace().SetUpsert(true),
+	)
+
+	return err
+}
+
+// DeleteOne deletes the document with given key.
+func (c *Collection) DeleteOne(ctx context.Context, key string) error {
+	_, err := c.coll.DeleteOne(ctx, bson.D{{Key: fieldKey, Value: key}})
+
+	return err
+}
+
+// CreateTTLIndex creates, if it does not exist, the TTL index on "expires_at" field
+// (with expireAfterSeconds 0).
+func (c *Collection) CreateTTLIndex(ctx context.Context) error {
+	_, err := c.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: fieldExpiresAt, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+// CollStats returns the collection's statistics (collStats command).
+func (c *Collection) CollStats(ctx context.Context) (xcache.MongoCollStats, error) {
+	var stats struct {
+		Count int64 `bson:"count"`
+		Size  int64 `bson:"size"`
+	}
+	err := c.coll.Database().RunCommand(ctx, bson.D{{Key: "collStats", Value: c.coll.Name()}}).Decode(&stats)
+	if err != nil {
+		return xcache.MongoCollStats{}, err
+	}
+
+	return xcache.MongoCollStats{Count: stats.Count, Size: stats.Size}, nil
+}
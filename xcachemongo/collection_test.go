@@ -0,0 +1,270 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachemongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachemongo"
+)
+
+func init() {
+	var _ xcache.MongoCollection = (*xcachemongo.Collection)(nil) // test Collection is a xcache.MongoCollection
+}
+
+// Note: tests run against mtest's mock deployment, which replies with the queued responses
+// and records the sent commands; they are not parallel, as mtest.T does not support it.
+
+func TestCollection(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find one", testCollectionFindOne)
+	mt.Run("find one - not found", testCollectionFindOneNotFound)
+	mt.Run("replace one", testCollectionReplaceOne)
+	mt.Run("delete one", testCollectionDeleteOne)
+	mt.Run("create ttl index", testCollectionCreateTTLIndex)
+	mt.Run("coll stats", testCollectionCollStats)
+	mt.Run("error is returned", testCollectionErrorIsReturned)
+	mt.Run("mongo cache", testCollectionMongoCache)
+}
+
+func testCollectionFindOne(mt *mtest.T) {
+	// arrange
+	var (
+		subject   = xcachemongo.NewCollection(mt.Coll)
+		expiresAt = time.Date(2030, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	)
+	mt.AddMockResponses(
+		findResponse(mt, bson.D{
+			{Key: "_id", Value: "a"},
+			{Key: "value", Value: primitive.Binary{Data: []byte("value a")}},
+			{Key: "expires_at", Value: primitive.NewDateTimeFromTime(expiresAt)},
+		}),
+		findResponse(mt, bson.D{
+			{Key: "_id", Value: "b"},
+			{Key: "value", Value: primitive.Binary{Data: []byte("value b")}},
+		}),
+	)
+
+	// act
+	docA, errA := subject.FindOne(context.Background(), "a")
+	docB, errB := subject.FindOne(context.Background(), "b")
+
+	// assert
+	assertNil(mt.T, errA)
+	assertEqual(mt.T, "a", docA.Key)
+	assertEqual(mt.T, []byte("value a"), docA.Value)
+	assertTrue(mt.T, expiresAt.Equal(docA.ExpiresAt))
+	assertNil(mt.T, errB)
+	assertEqual(mt.T, xcache.MongoDocument{Key: "b", Value: []byte("value b")}, docB)
+	started := mt.GetStartedEvent()
+	assertEqual(mt.T, "find", started.CommandName)
+	assertEqual(mt.T, bson.Raw(mustMarshal(mt, bson.D{{Key: "_id", Value: "a"}})), started.Command.Lookup("filter").Document())
+}
+
+func testCollectionFindOneNotFound(mt *mtest.T) {
+	// arrange
+	subject := xcachemongo.NewCollection(mt.Coll)
+	mt.AddMockResponses(findResponse(mt))
+
+	// act
+	_, err := subject.FindOne(context.Background(), "a")
+
+	// assert
+	assertTrue(mt.T, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testCollectionReplaceOne(mt *mtest.T) {
+	// arrange
+	var (
+		subject   = xcachemongo.NewCollection(mt.Coll)
+		expiresAt = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+	mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+	// act
+	errA := subject.ReplaceOne(
+		context.Background(),
+		xcache.MongoDocument{Key: "a", Value: []byte("value a"), ExpiresAt: expiresAt},
+	)
+	errB := subject.ReplaceOne(context.Background(), xcache.MongoDocument{Key: "b", Value: []byte("value b")})
+
+	// assert
+	assertNil(mt.T, errA)
+	assertNil(mt.T, errB)
+	update := updateStatement(mt)
+	assertEqual(mt.T, "a", update.Lookup("q", "_id").StringValue())
+	assertEqual(mt.T, []byte("value a"), binaryData(update.Lookup("u", "value")))
+	assertTrue(mt.T, expiresAt.Equal(update.Lookup("u", "expires_at").Time()))
+	assertTrue(mt.T, update.Lookup("upsert").Boolean())
+	update = updateStatement(mt)
+	assertEqual(mt.T, "b", update.Lookup("q", "_id").StringValue())
+	assertEqual(mt.T, []byte("value b"), binaryData(update.Lookup("u", "value")))
+	_, hasExpiresAt := update.Lookup("u").Document().LookupErr("expires_at")
+	assertTrue(mt.T, hasExpiresAt != nil)
+}
+
+func testCollectionDeleteOne(mt *mtest.T) {
+	// arrange
+	subject := xcachemongo.NewCollection(mt.Coll)
+	mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+	// act
+	err := subject.DeleteOne(context.Background(), "a")
+
+	// assert
+	assertNil(mt.T, err)
+	started := mt.GetStartedEvent()
+	assertEqual(mt.T, "delete", started.CommandName)
+	deletes, _ := started.Command.Lookup("deletes").Array().Values()
+	assertEqual(mt.T, "a", deletes[0].Document().Lookup("q", "_id").StringValue())
+	assertEqual(mt.T, int32(1), deletes[0].Document().Lookup("limit").Int32())
+}
+
+func testCollectionCreateTTLIndex(mt *mtest.T) {
+	// arrange
+	subject := xcachemongo.NewCollection(mt.Coll)
+	mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+	// act
+	err := subject.CreateTTLIndex(context.Background())
+
+	// assert
+	assertNil(mt.T, err)
+	started := mt.GetStartedEvent()
+	assertEqual(mt.T, "createIndexes", started.CommandName)
+	indexes, _ := started.Command.Lookup("indexes").Array().Values()
+	index := indexes[0].Document()
+	assertEqual(mt.T, bson.Raw(mustMarshal(mt, bson.D{{Key: "expires_at", Value: 1}})), index.Lookup("key").Document())
+	assertEqual(mt.T, int32(0), index.Lookup("expireAfterSeconds").Int32())
+}
+
+func testCollectionCollStats(mt *mtest.T) {
+	// arrange
+	subject := xcachemongo.NewCollection(mt.Coll)
+	mt.AddMockResponses(mtest.CreateSuccessResponse(
+		bson.E{Key: "count", Value: int32(3)},
+		bson.E{Key: "size", Value: float64(1024)},
+	))
+
+	// act
+	stats, err := subject.CollStats(context.Background())
+
+	// assert
+	assertNil(mt.T, err)
+	assertEqual(mt.T, xcache.MongoCollStats{Count: 3, Size: 1024}, stats)
+	started := mt.GetStartedEvent()
+	assertEqual(mt.T, "collStats", started.CommandName)
+	assertEqual(mt.T, mt.Coll.Name(), started.Command.Lookup("collStats").StringValue())
+}
+
+func testCollectionErrorIsReturned(mt *mtest.T) {
+	// arrange
+	var (
+		subject = xcachemongo.NewCollection(mt.Coll)
+		ctx     = context.Background()
+		cmdErr  = mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "not authorized",
+		})
+	)
+	mt.AddMockResponses(cmdErr, cmdErr, cmdErr, cmdErr, cmdErr)
+
+	// act
+	_, errFind := subject.FindOne(ctx, "a")
+	errReplace := subject.ReplaceOne(ctx, xcache.MongoDocument{Key: "a", Value: []byte("value a")})
+	errDelete := subject.DeleteOne(ctx, "a")
+	errIndex := subject.CreateTTLIndex(ctx)
+	_, errStats := subject.CollStats(ctx)
+
+	// assert
+	for _, err := range []error{errFind, errReplace, errDelete, errIndex, errStats} {
+		assertNotNil(mt.T, err)
+		assertTrue(mt.T, !errors.Is(err, xcache.ErrNotFound))
+	}
+}
+
+func testCollectionMongoCache(mt *mtest.T) {
+	// arrange
+	var (
+		cache     = xcache.NewMongo(xcachemongo.NewCollection(mt.Coll))
+		ctx       = context.Background()
+		expiresAt = time.Now().Add(time.Minute)
+	)
+	mt.AddMockResponses(
+		mtest.CreateSuccessResponse(), // create ttl index
+		mtest.CreateSuccessResponse(), // save
+		findResponse(mt, bson.D{
+			{Key: "_id", Value: "a"},
+			{Key: "value", Value: primitive.Binary{Data: []byte("value a")}},
+			{Key: "expires_at", Value: primitive.NewDateTimeFromTime(expiresAt)},
+		}),
+		findResponse(mt, bson.D{ // expired, not deleted yet
+			{Key: "_id", Value: "b"},
+			{Key: "value", Value: primitive.Binary{Data: []byte("value b")}},
+			{Key: "expires_at", Value: primitive.NewDateTimeFromTime(time.Now().Add(-time.Second))},
+		}),
+		mtest.CreateSuccessResponse( // stats
+			bson.E{Key: "count", Value: int64(2)},
+			bson.E{Key: "size", Value: int32(128)},
+		),
+	)
+
+	// act & assert
+	requireNil(mt.T, cache.CreateTTLIndex(ctx))
+	requireNil(mt.T, cache.Save(ctx, "a", []byte("value a"), time.Minute))
+	value, err := cache.Load(ctx, "a")
+	assertNil(mt.T, err)
+	assertEqual(mt.T, []byte("value a"), value)
+	_, err = cache.Load(ctx, "b")
+	assertTrue(mt.T, errors.Is(err, xcache.ErrNotFound))
+	stats, err := cache.Stats(ctx)
+	assertNil(mt.T, err)
+	assertEqual(mt.T, xcache.Stats{Keys: 2, Memory: 128, Hits: 1, Misses: 1}, stats)
+}
+
+// findResponse returns a find command's response, with given documents in its first batch.
+func findResponse(mt *mtest.T, docs ...bson.D) bson.D {
+	mt.Helper()
+
+	return mtest.CreateCursorResponse(0, mt.Coll.Database().Name()+"."+mt.Coll.Name(), mtest.FirstBatch, docs...)
+}
+
+// updateStatement returns the (single) update statement of the next started update command.
+func updateStatement(mt *mtest.T) bson.Raw {
+	mt.Helper()
+
+	started := mt.GetStartedEvent()
+	assertEqual(mt.T, "update", started.CommandName)
+	updates, _ := started.Command.Lookup("updates").Array().Values()
+
+	return updates[0].Document()
+}
+
+// binaryData returns the data of given binary value.
+func binaryData(value bson.RawValue) []byte {
+	_, data := value.Binary()
+
+	return data
+}
+
+func mustMarshal(mt *mtest.T, doc bson.D) []byte {
+	mt.Helper()
+
+	data, err := bson.Marshal(doc)
+	requireNil(mt.T, err)
+
+	return data
+}
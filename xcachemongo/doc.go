@@ -0,0 +1,21 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachemongo provides a xcache.MongoCollection backed by a mongo-go-driver collection
+// (through [go.mongodb.org/mongo-driver/mongo]), the collection a xcache.Mongo cache is built upon.
+// Documents are stored as {_id: key, value: binary, expires_at: date}.
+//
+// Example:
+//
+//	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Disconnect(ctx)
+//	cache := xcache.NewMongo(xcachemongo.NewCollection(client.Database("app").Collection("cache")))
+//	if err := cache.CreateTTLIndex(ctx); err != nil {
+//		return err
+//	}
+package xcachemongo
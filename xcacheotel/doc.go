@@ -0,0 +1,12 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcacheotel provides a xcache.Tracer backed by OpenTelemetry (through [go.opentelemetry.io/otel]),
+// so a xcache.Traced cache's spans show up in distributed traces next to database and HTTP spans.
+//
+// Example:
+//
+//	cache := xcache.NewTraced(redisCache, xcacheotel.NewTracer(nil), "") // global tracer provider.
+package xcacheotel
@@ -0,0 +1,82 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcacheotel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/actforgood/xcache"
+)
+
+// ScopeName is the instrumentation scope name of the tracer spans are started with.
+const ScopeName = "github.com/actforgood/xcache"
+
+// Tracer is a xcache.Tracer which starts OpenTelemetry spans, of client kind.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer instantiates a new Tracer which starts spans with a tracer obtained from given provider.
+// A nil provider is replaced with the global one (see otel.GetTracerProvider).
+func NewTracer(provider trace.TracerProvider) Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return Tracer{
+		tracer: provider.Tracer(ScopeName),
+	}
+}
+
+// Start starts a span with given name, returning it, and a context holding it.
+func (t Tracer) Start(ctx context.Context, name string) (context.Context, xcache.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+
+	return ctx, span{span: s}
+}
+
+// span adapts an OpenTelemetry span to xcache.Span.
+type span struct {
+	span trace.Span
+}
+
+// SetAttributes sets given attributes on the span.
+// Values of other types than string / bool / int64 / float64 are set as their string representation.
+func (s span) SetAttributes(attrs ...xcache.SpanAttribute) {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs[i] = attribute.String(attr.Key, value)
+		case bool:
+			kvs[i] = attribute.Bool(attr.Key, value)
+		case int64:
+			kvs[i] = attribute.Int64(attr.Key, value)
+		case float64:
+			kvs[i] = attribute.Float64(attr.Key, value)
+		default:
+			kvs[i] = attribute.String(attr.Key, fmt.Sprint(value))
+		}
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+// RecordError records given error on the span, and sets the span's status to error.
+func (s span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End completes the span.
+func (s span) End() {
+	s.span.End()
+}
@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcacheotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcacheotel"
+)
+
+func init() {
+	var _ xcache.Tracer = xcacheotel.Tracer{} // test Tracer is a xcache.Tracer
+}
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("operations are traced", testTracerOperationsAreTraced)
+	t.Run("errors are recorded", testTracerErrorsAreRecorded)
+	t.Run("attribute values", testTracerAttributeValues)
+}
+
+func testTracerOperationsAreTraced(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		subject  = xcache.NewTraced(xcache.NewMemory(512*1024), xcacheotel.NewTracer(provider), "")
+		ctx      = context.Background()
+		key      = "test-otel-key"
+		value    = []byte("test value")
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	_, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	spans := recorder.Ended()
+	if assertEqual(t, 2, len(spans)) {
+		assertEqual(t, "xcache.save", spans[0].Name())
+		assertEqual(t, "xcache.load", spans[1].Name())
+		for _, span := range spans {
+			assertEqual(t, trace.SpanKindClient, span.SpanKind())
+			assertEqual(t, xcacheotel.ScopeName, span.InstrumentationScope().Name)
+			assertEqual(t, codes.Unset, span.Status().Code)
+		}
+		attrs := attributesOf(spans[1])
+		assertEqual(t, attribute.StringValue("memory"), attrs[xcache.SpanAttrBackend])
+		assertEqual(t, attribute.BoolValue(true), attrs[xcache.SpanAttrHit])
+		assertEqual(t, attribute.Int64Value(int64(len(value))), attrs[xcache.SpanAttrBytes])
+		assertEqual(t, attribute.Float64Value(60), attributesOf(spans[0])[xcache.SpanAttrExpire])
+	}
+}
+
+func testTracerErrorsAreRecorded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		cache    = new(xcache.Mock)
+		subject  = xcache.NewTraced(cache, xcacheotel.NewTracer(provider), "")
+		ctx      = context.Background()
+		errLoad  = errors.New("connection reset by peer")
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, errLoad
+	})
+
+	// act
+	_, err := subject.Load(ctx, "test-otel-err-key")
+
+	// assert
+	assertTrue(t, errors.Is(err, errLoad))
+	spans := recorder.Ended()
+	if assertEqual(t, 1, len(spans)) {
+		assertEqual(t, sdktrace.Status{Code: codes.Error, Description: errLoad.Error()}, spans[0].Status())
+		events := spans[0].Events()
+		if assertEqual(t, 1, len(events)) {
+			assertEqual(t, "exception", events[0].Name)
+		}
+	}
+}
+
+func testTracerAttributeValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		subject  = xcacheotel.NewTracer(provider)
+	)
+
+	// act
+	_, span := subject.Start(context.Background(), "test-span")
+	span.SetAttributes(
+		xcache.SpanAttribute{Key: "string", Value: "value"},
+		xcache.SpanAttribute{Key: "bool", Value: true},
+		xcache.SpanAttribute{Key: "int64", Value: int64(10)},
+		xcache.SpanAttribute{Key: "float64", Value: 1.5},
+		xcache.SpanAttribute{Key: "other", Value: 10 * time.Second},
+	)
+	span.End()
+
+	// assert
+	spans := recorder.Ended()
+	if assertEqual(t, 1, len(spans)) {
+		assertEqual(t, map[attribute.Key]attribute.Value{
+			"string":  attribute.StringValue("value"),
+			"bool":    attribute.BoolValue(true),
+			"int64":   attribute.Int64Value(10),
+			"float64": attribute.Float64Value(1.5),
+			"other":   attribute.StringValue("10s"),
+		}, attributesOf(spans[0]))
+	}
+}
+
+// attributesOf returns given span's attributes, by their key.
+func attributesOf(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes()))
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
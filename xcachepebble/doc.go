@@ -0,0 +1,21 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachepebble provides a xcache.KVStore backed by Pebble (through [github.com/cockroachdb/pebble]),
+// the store a xcache.Disk cache is built upon, for working sets too large for RAM, kept on local NVMe.
+//
+// Example:
+//
+//	db, err := pebble.Open("/mnt/nvme/cache", &pebble.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//	cache, err := xcache.NewDisk(xcachepebble.NewStore(db, xcachepebble.StoreConfig{}), xcache.DiskConfig{})
+//	if err != nil {
+//		return err
+//	}
+//	defer cache.Close()
+package xcachepebble
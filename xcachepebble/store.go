@@ -0,0 +1,87 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachepebble
+
+import (
+	"errors"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/actforgood/xcache"
+)
+
+// StoreConfig holds the settings of a Store.
+type StoreConfig struct {
+	// Sync specifies whether writes are synced to disk before being acknowledged.
+	// By default (false), they are not, as a cache can afford losing its last writes on a crash.
+	Sync bool
+}
+
+// Store is a xcache.KVStore backed by a Pebble database.
+// The database is not closed by the Store, its owner should close it, after closing the Disk cache using it.
+type Store struct {
+	db        *pebble.DB
+	writeOpts *pebble.WriteOptions
+}
+
+// NewStore instantiates a new Store upon given Pebble database, according to given settings.
+func NewStore(db *pebble.DB, config StoreConfig) *Store {
+	writeOpts := pebble.NoSync
+	if config.Sync {
+		writeOpts = pebble.Sync
+	}
+
+	return &Store{
+		db:        db,
+		writeOpts: writeOpts,
+	}
+}
+
+// Get returns the value of given key, or xcache.ErrNotFound, if the key does not exist.
+func (store *Store) Get(key []byte) ([]byte, error) {
+	value, closer, err := store.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, xcache.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	return append([]byte(nil), value...), nil // value is valid only till closer is closed.
+}
+
+// Write applies given writes atomically, in a batch.
+func (store *Store) Write(writes []xcache.KVWrite) error {
+	batch := store.db.NewBatch()
+	defer batch.Close()
+
+	for _, write := range writes {
+		var err error
+		if write.Delete {
+			err = batch.Delete(write.Key, nil)
+		} else {
+			err = batch.Set(write.Key, write.Value, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Commit(store.writeOpts)
+}
+
+// Iterate calls given function for the keys within [lower, upper), in ascending order,
+// until it returns false.
+func (store *Store) Iterate(lower, upper []byte, fn func(key, value []byte) bool) error {
+	iter, err := store.db.NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: upper})
+	if err != nil {
+		return err
+	}
+	for valid := iter.First(); valid && fn(iter.Key(), iter.Value()); valid = iter.Next() {
+	}
+
+	return iter.Close()
+}
@@ -0,0 +1,167 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachepebble_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachepebble"
+)
+
+func init() {
+	var _ xcache.KVStore = (*xcachepebble.Store)(nil) // test Store is a xcache.KVStore
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get / write / iterate", testStoreGetWriteIterate)
+	t.Run("disk cache", testStoreDiskCache)
+	t.Run("disk cache keys survive reopening", testStoreDiskCacheKeysSurviveReopening)
+}
+
+func testStoreGetWriteIterate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		db      = openPebble(t, vfs.NewMem())
+		subject = xcachepebble.NewStore(db, xcachepebble.StoreConfig{Sync: true})
+	)
+	requireNil(t, subject.Write([]xcache.KVWrite{
+		{Key: []byte("a"), Value: []byte("value a")},
+		{Key: []byte("b"), Value: []byte("value b")},
+		{Key: []byte("c"), Value: []byte("value c")},
+		{Key: []byte("d"), Value: []byte("value d")},
+	}))
+
+	// act & assert get
+	value, err := subject.Get([]byte("b"))
+	assertNil(t, err)
+	assertEqual(t, []byte("value b"), value)
+	_, err = subject.Get([]byte("z"))
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	// act & assert write with deletion
+	err = subject.Write([]xcache.KVWrite{
+		{Key: []byte("a"), Delete: true},
+		{Key: []byte("b"), Value: []byte("new value b")},
+	})
+	assertNil(t, err)
+	_, err = subject.Get([]byte("a"))
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	// act & assert iterate within bounds, stopping early
+	var keys, values []string
+	err = subject.Iterate([]byte("a"), []byte("d"), func(key, value []byte) bool {
+		keys = append(keys, string(key))
+		values = append(values, string(value))
+
+		return true
+	})
+	assertNil(t, err)
+	assertEqual(t, []string{"b", "c"}, keys)
+	assertEqual(t, []string{"new value b", "value c"}, values)
+	keys = nil
+	err = subject.Iterate(nil, nil, func(key, _ []byte) bool {
+		keys = append(keys, string(key))
+
+		return len(keys) < 2
+	})
+	assertNil(t, err)
+	assertEqual(t, []string{"b", "c"}, keys)
+}
+
+func testStoreDiskCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		db      = openPebble(t, vfs.NewMem())
+		ctx     = context.Background()
+		key     = "test-pebble-key"
+		value   = []byte("test value")
+		subject = newDisk(t, db)
+	)
+
+	// act
+	errSave := subject.Save(ctx, key, value, 50*time.Millisecond)
+	result, errLoad := subject.Load(ctx, key)
+	time.Sleep(60 * time.Millisecond)
+	swept, errSweep := subject.Sweep(ctx)
+	_, errLoadExpired := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+	assertNil(t, errSweep)
+	assertEqual(t, 1, swept)
+	assertTrue(t, errors.Is(errLoadExpired, xcache.ErrNotFound))
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(0), stats.Keys)
+}
+
+func testStoreDiskCacheKeysSurviveReopening(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		fs    = vfs.NewMem()
+		ctx   = context.Background()
+		key   = "test-pebble-persistent-key"
+		value = []byte("test value")
+	)
+	db, err := pebble.Open("", &pebble.Options{FS: fs})
+	requireNil(t, err)
+	cache, err := xcache.NewDisk(xcachepebble.NewStore(db, xcachepebble.StoreConfig{}), xcache.DiskConfig{SweepInterval: -1})
+	requireNil(t, err)
+	requireNil(t, cache.Save(ctx, key, value, xcache.NoExpire))
+	requireNil(t, cache.Close())
+	requireNil(t, db.Close())
+	subject := newDisk(t, openPebble(t, fs))
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	stats, err := subject.Stats(ctx)
+	assertNil(t, err)
+	assertEqual(t, int64(1), stats.Keys)
+}
+
+// openPebble opens a Pebble database on given file system, closed at the end of the test.
+func openPebble(t *testing.T, fs vfs.FS) *pebble.DB {
+	t.Helper()
+
+	db, err := pebble.Open("", &pebble.Options{FS: fs})
+	requireNil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// newDisk instantiates a Disk cache, without background sweeping, upon given database,
+// closed at the end of the test.
+func newDisk(t *testing.T, db *pebble.DB) *xcache.Disk {
+	t.Helper()
+
+	cache, err := xcache.NewDisk(xcachepebble.NewStore(db, xcachepebble.StoreConfig{}), xcache.DiskConfig{SweepInterval: -1})
+	requireNil(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	return cache
+}
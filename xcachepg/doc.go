@@ -0,0 +1,24 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachepg provides an invalidation bus backed by Postgres LISTEN / NOTIFY (through [github.com/jackc/pgx/v5]),
+// so applications caching Postgres data can invalidate their (local, Memory) caches on writes,
+// without adding Redis pub/sub or Kafka to their stack.
+//
+// Writers publish invalidations with Notify (within the writing transaction, invalidations being delivered on commit),
+// and each application instance runs a Listener, which feeds the received invalidations to a xcache.Invalidator.
+//
+// Example:
+//
+//	// writer
+//	invalidation := xcache.Invalidation{Kind: xcache.InvalidationKey, Target: key}
+//	err := xcachepg.Notify(ctx, tx, xcachepg.DefaultChannel, invalidation)
+//
+//	// each application instance
+//	invalidations := make(chan xcache.Invalidation)
+//	listener := xcachepg.NewListener(pgConnConfig, xcachepg.ListenerConfig{})
+//	go func() { _ = listener.Listen(ctx, invalidations) }()
+//	go func() { _ = xcache.ApplyInvalidations(ctx, memCache, invalidations) }()
+package xcachepg
@@ -0,0 +1,145 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachepg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultChannel is the default Postgres channel invalidations are published on.
+const DefaultChannel = "xcache_invalidations"
+
+// listenerDefaultReconnectDelay is the default period a Listener waits before reconnecting.
+const listenerDefaultReconnectDelay = time.Second
+
+// Execer executes a SQL statement. It is implemented by *pgx.Conn, pgx.Tx, *pgxpool.Pool.
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Notify publishes given invalidations on given Postgres channel.
+// If db is a transaction, invalidations are delivered to listeners only if (and when) the transaction is committed.
+// Note: a notification's payload must be shorter than 8000 bytes (the key / tag / prefix length is bounded).
+func Notify(ctx context.Context, db Execer, channel string, invalidations ...xcache.Invalidation) error {
+	for _, invalidation := range invalidations {
+		payload, err := invalidation.MarshalText()
+		if err != nil {
+			return err
+		}
+		if _, err := db.Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(payload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListenerConfig holds the settings of a Listener.
+type ListenerConfig struct {
+	// Channel is the Postgres channel to listen on. By default, it's DefaultChannel.
+	Channel string
+	// ReconnectDelay is the period waited before reconnecting, after a connection failure. By default (0), it's 1s.
+	ReconnectDelay time.Duration
+	// OnError is called with connection errors, and with notifications which are not valid invalidations.
+	OnError func(err error)
+	// OnReconnect is called after the listener started listening again, following a connection failure.
+	// Notifications sent while the listener was disconnected are lost, so it can be used
+	// to clear the local caches, for example.
+	OnReconnect func()
+}
+
+// Listener receives the invalidations published (see Notify) on a Postgres channel.
+// It uses a dedicated connection, which is re-established on failures.
+type Listener struct {
+	connConfig *pgx.ConnConfig
+	config     ListenerConfig
+}
+
+// NewListener instantiates a new Listener, which connects to Postgres with given connection settings
+// (see [pgx.ParseConfig]), and listens according to given settings.
+func NewListener(connConfig *pgx.ConnConfig, config ListenerConfig) *Listener {
+	if config.Channel == "" {
+		config.Channel = DefaultChannel
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = listenerDefaultReconnectDelay
+	}
+
+	return &Listener{
+		connConfig: connConfig,
+		config:     config,
+	}
+}
+
+// Listen sends the received invalidations on given channel (which is not closed by Listen).
+// It blocks until the context is done, returning its error.
+func (l *Listener) Listen(ctx context.Context, invalidations chan<- xcache.Invalidation) error {
+	for failed := false; ; failed = true {
+		err := l.listen(ctx, invalidations, failed)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		l.reportErr(err)
+
+		timer := time.NewTimer(l.config.ReconnectDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// listen connects to Postgres, and sends the received invalidations on given channel,
+// until the connection fails / the context is done.
+func (l *Listener) listen(ctx context.Context, invalidations chan<- xcache.Invalidation, reconnected bool) error {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.config.Channel}.Sanitize()); err != nil {
+		return err
+	}
+	if reconnected && l.config.OnReconnect != nil {
+		l.config.OnReconnect()
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var invalidation xcache.Invalidation
+		if err := invalidation.UnmarshalText([]byte(notification.Payload)); err != nil {
+			l.reportErr(fmt.Errorf("%w: %q", err, notification.Payload))
+
+			continue
+		}
+		select {
+		case invalidations <- invalidation:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reportErr passes given error to OnError callback, if set.
+func (l *Listener) reportErr(err error) {
+	if l.config.OnError != nil {
+		l.config.OnError(err)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+// backendItem is a key's value and expiration time, stored by a Backend.
+type backendItem struct {
+	value    []byte
+	expireAt time.Time // zero if key does not expire.
+}
+
+// Backend is an in-memory xcache.Cache, meant to be shared by the instances of a Fleet (like a Redis is).
+// Its clock is manual: keys expire only when the clock is moved forward with Advance, so expiration
+// scenarios are deterministic.
+// It counts the operations performed upon it, and can simulate a slow backend, see SetLatency.
+// It implements also xcache.Deleter.
+type Backend struct {
+	items   map[string]backendItem
+	now     time.Time
+	latency time.Duration
+	hits    int64
+	misses  int64
+	saves   int64
+	expired int64
+	mu      sync.Mutex
+}
+
+// NewBackend instantiates a new, empty, Backend.
+func NewBackend() *Backend {
+	return &Backend{
+		items: make(map[string]backendItem),
+		now:   time.Unix(0, 0),
+	}
+}
+
+// Save stores the given key-value with expiration period.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+// Returned error is nil, unless the context is done.
+func (b *Backend) Save(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	if err := b.wait(ctx); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.saves++
+	if expire < 0 {
+		delete(b.items, key)
+
+		return nil
+	}
+	item := backendItem{value: append([]byte(nil), value...)}
+	if expire > 0 {
+		item.expireAt = b.now.Add(expire)
+	}
+	b.items[key] = item
+
+	return nil
+}
+
+// Load returns a key's value.
+// If the key is not found (or it expired), ErrNotFound is returned.
+func (b *Backend) Load(ctx context.Context, key string) ([]byte, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	item, found := b.items[key]
+	if !found {
+		b.misses++
+
+		return nil, xcache.ErrNotFound
+	}
+	b.hits++
+
+	return append([]byte(nil), item.value...), nil
+}
+
+// TTL returns a key's remaining time to live, according to Backend's clock.
+// If the key is not found, a negative TTL is returned.
+// If the key has no expiration, 0 (NoExpire) is returned.
+func (b *Backend) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := b.wait(ctx); err != nil {
+		return -1, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	item, found := b.items[key]
+	if !found {
+		return -1, nil
+	}
+	if item.expireAt.IsZero() {
+		return xcache.NoExpire, nil
+	}
+
+	return item.expireAt.Sub(b.now), nil
+}
+
+// Stats returns Backend's hits, misses, keys and expired keys.
+func (b *Backend) Stats(ctx context.Context) (xcache.Stats, error) {
+	if err := ctx.Err(); err != nil {
+		return xcache.Stats{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return xcache.Stats{
+		Hits:    b.hits,
+		Misses:  b.misses,
+		Keys:    int64(len(b.items)),
+		Expired: b.expired,
+	}, nil
+}
+
+// Delete deletes the given key.
+// Returned error is nil, unless the context is done.
+func (b *Backend) Delete(ctx context.Context, key string) error {
+	return b.Save(ctx, key, nil, -1)
+}
+
+// Advance moves Backend's clock forward with given period, expiring the keys whose time to live passed.
+func (b *Backend) Advance(period time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.now = b.now.Add(period)
+	for key, item := range b.items {
+		if !item.expireAt.IsZero() && !item.expireAt.After(b.now) {
+			delete(b.items, key)
+			b.expired++
+		}
+	}
+}
+
+// SetLatency sets the period each operation (except Stats) takes, simulating a slow backend.
+// It widens the window in which concurrent requests overlap.
+func (b *Backend) SetLatency(latency time.Duration) {
+	b.mu.Lock()
+	b.latency = latency
+	b.mu.Unlock()
+}
+
+// LoadCount returns the no. of Load operations performed (hits and misses).
+func (b *Backend) LoadCount() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.hits + b.misses
+}
+
+// SaveCount returns the no. of Save (and Delete) operations performed.
+func (b *Backend) SaveCount() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.saves
+}
+
+// wait simulates the latency of an operation.
+// It returns the context's error, if it is done.
+func (b *Backend) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	latency := b.latency
+	b.mu.Unlock()
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcachetest.Backend)(nil)   // test Backend is a Cache
+	var _ xcache.Deleter = (*xcachetest.Backend)(nil) // test Backend is a Deleter
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keys expire when clock advances", testBackendKeysExpireWhenClockAdvances)
+	t.Run("delete key", testBackendDeleteKey)
+	t.Run("latency", testBackendLatency)
+	t.Run("done context", testBackendWithDoneContext)
+}
+
+func testBackendKeysExpireWhenClockAdvances(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject     = xcachetest.NewBackend()
+		ctx         = context.Background()
+		expKey      = "test-backend-exp-key"
+		noExpKey    = "test-backend-no-exp-key"
+		value       = []byte("test value")
+		expectedTTL = time.Minute
+	)
+	requireNil(t, subject.Save(ctx, expKey, value, expectedTTL))
+	requireNil(t, subject.Save(ctx, noExpKey, value, xcache.NoExpire))
+
+	// act & assert before expiration
+	subject.Advance(expectedTTL - time.Second)
+	resultTTL, resultErr := subject.TTL(ctx, expKey)
+	assertNil(t, resultErr)
+	assertEqual(t, time.Second, resultTTL)
+	resultValue, resultErr := subject.Load(ctx, expKey)
+	assertNil(t, resultErr)
+	assertEqual(t, value, resultValue)
+
+	// act & assert after expiration
+	subject.Advance(time.Second)
+	_, resultErr = subject.Load(ctx, expKey)
+	assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+	resultTTL, resultErr = subject.TTL(ctx, expKey)
+	assertNil(t, resultErr)
+	assertTrue(t, resultTTL < 0)
+	resultTTL, resultErr = subject.TTL(ctx, noExpKey)
+	assertNil(t, resultErr)
+	assertEqual(t, xcache.NoExpire, resultTTL)
+
+	// act & assert stats
+	resultStats, resultErr := subject.Stats(ctx)
+	assertNil(t, resultErr)
+	assertEqual(t, xcache.Stats{Hits: 1, Misses: 1, Keys: 1, Expired: 1}, resultStats)
+	assertEqual(t, int64(2), subject.LoadCount())
+	assertEqual(t, int64(2), subject.SaveCount())
+}
+
+func testBackendDeleteKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcachetest.NewBackend()
+		ctx     = context.Background()
+		key     = "test-backend-delete-key"
+	)
+	requireNil(t, subject.Save(ctx, key, []byte("test value"), xcache.NoExpire))
+
+	// act
+	resultErr := subject.Delete(ctx, key)
+
+	// assert
+	assertNil(t, resultErr)
+	_, resultErr = subject.Load(ctx, key)
+	assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+}
+
+func testBackendLatency(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject = xcachetest.NewBackend()
+		latency = 20 * time.Millisecond
+		start   = time.Now()
+	)
+	subject.SetLatency(latency)
+
+	// act
+	_, resultErr := subject.Load(context.Background(), "test-backend-latency-key")
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, xcache.ErrNotFound))
+	assertTrue(t, time.Since(start) >= latency)
+}
+
+func testBackendWithDoneContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject        = xcachetest.NewBackend()
+		key            = "test-backend-done-context-key"
+		ctx, cancelCtx = context.WithCancel(context.Background())
+	)
+	subject.SetLatency(time.Minute)
+	cancelCtx()
+
+	// act & assert
+	assertTrue(t, errors.Is(subject.Save(ctx, key, []byte("test value"), xcache.NoExpire), context.Canceled))
+	_, resultErr := subject.Load(ctx, key)
+	assertTrue(t, errors.Is(resultErr, context.Canceled))
+	_, resultErr = subject.TTL(ctx, key)
+	assertTrue(t, errors.Is(resultErr, context.Canceled))
+	_, resultErr = subject.Stats(ctx)
+	assertTrue(t, errors.Is(resultErr, context.Canceled))
+	assertTrue(t, errors.Is(subject.Delete(ctx, key), context.Canceled))
+	assertEqual(t, int64(0), subject.SaveCount())
+}
@@ -0,0 +1,84 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+
+	"github.com/actforgood/xcache"
+)
+
+// RedisContainer defaults.
+const (
+	// RedisContainerDefaultVersion is the Redis docker image tag StartRedisContainer uses by default
+	// (the one xcache's integration tests run against).
+	RedisContainerDefaultVersion = "7.2.4-alpine3.19"
+	redisContainerStartTimeout   = 60 * time.Second
+)
+
+// StartRedisContainer starts a Redis server docker container, having given version (the "redis" docker image tag,
+// like "6.2.14-alpine3.19", "7"; RedisContainerDefaultVersion if empty), waits for it to be ready,
+// and returns the configuration for a xcache.Redis6 / xcache.Redis7 to connect to it.
+// The container is removed at the end of the test.
+// The test is skipped if docker is not available, and fails if the container cannot be started.
+//
+// It allows projects depending on xcache to run their cache-dependent tests against a real Redis server,
+// without maintaining a docker environment of their own. For unit tests, see also NewMiniRedis.
+// The container is managed with testcontainers-go's redis module.
+func StartRedisContainer(t testing.TB, version string) xcache.RedisConfig {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisContainerStartTimeout)
+	defer cancel()
+
+	skipIfDockerIsNotAvailable(ctx, t)
+	if version == "" {
+		version = RedisContainerDefaultVersion
+	}
+
+	container, err := tcredis.RunContainer(ctx, testcontainers.WithImage("redis:"+version))
+	if err != nil {
+		t.Fatalf("could not start redis container: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), redisContainerStartTimeout)
+		defer cancel()
+		_ = container.Terminate(ctx)
+	})
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("could not get redis container's host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "6379/tcp")
+	if err != nil {
+		t.Fatalf("could not get redis container's port: %v", err)
+	}
+
+	return xcache.RedisConfig{
+		Addrs: []string{net.JoinHostPort(host, port.Port())},
+	}
+}
+
+// skipIfDockerIsNotAvailable skips the test if the docker provider cannot be reached.
+func skipIfDockerIsNotAvailable(ctx context.Context, t testing.TB) {
+	t.Helper()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		t.Skipf("docker is not available: %v", err)
+	}
+	defer provider.Close()
+	if err := provider.Health(ctx); err != nil {
+		t.Skipf("docker is not available: %v", err)
+	}
+}
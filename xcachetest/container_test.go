@@ -0,0 +1,42 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+func TestStartRedisContainer(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("starting a docker container is slow")
+	}
+
+	// arrange
+	var (
+		config  = xcachetest.StartRedisContainer(t, "")
+		subject = xcache.NewRedis7(config)
+		ctx     = context.Background()
+		key     = "test-redis-container-key"
+		value   = []byte("test value")
+	)
+	defer subject.Close()
+
+	// act
+	errSave := subject.Save(ctx, key, value, time.Minute)
+	result, errLoad := subject.Load(ctx, key)
+
+	// assert
+	assertNil(t, errSave)
+	assertNil(t, errLoad)
+	assertEqual(t, value, result)
+}
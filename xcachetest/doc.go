@@ -0,0 +1,17 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachetest provides utilities for testing caching features meant for a fleet of application instances
+// (stampede protection, invalidation), deterministically, in CI:
+// a Fleet of simulated instances, each one having its own xcache.Memory layer over a shared Backend,
+// which is an in-memory cache with a manual clock.
+//
+// A typical scenario warms up the fleet, expires a key (backend.Advance / fleet.ExpireLocal),
+// then drives concurrent requests (fleet.Run), and asserts on the no. of requests which reached the Backend
+// (or the origin).
+//
+// Redis6 / Redis7 code paths can be unit tested against a MiniRedis, an embedded Redis server, see NewMiniRedis,
+// or against a real Redis server docker container, see StartRedisContainer.
+package xcachetest
@@ -0,0 +1,139 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/actforgood/xcache"
+)
+
+// Fleet defaults.
+const (
+	fleetDefaultInstances  = 10
+	fleetDefaultMemorySize = 512 * 1024
+)
+
+// FleetConfig holds the settings of a Fleet.
+type FleetConfig struct {
+	// Instances is the no. of simulated application instances. By default (0), it's 10.
+	Instances int
+	// MemorySize is the size of each instance's Memory layer. By default (0), it's 512KB.
+	MemorySize int
+	// Decorate, if set, is called with each instance's cache (a Multi of instance's Memory layer and the Backend),
+	// and the returned cache is used instead, so the feature under test (stampede protection, for example)
+	// can be plugged in.
+	Decorate func(instance int, cache xcache.Cache) xcache.Cache
+}
+
+// Fleet simulates a fleet of application instances, each one having its own Memory layer
+// over a shared Backend, so features meant for such a setup (stampede protection, invalidation)
+// can be validated in tests.
+//
+// Example:
+//
+//	backend := xcachetest.NewBackend()
+//	fleet := xcachetest.NewFleet(backend, xcachetest.FleetConfig{Instances: 50})
+//	// ... warm up the fleet, then expire the key everywhere:
+//	backend.Advance(time.Minute)
+//	fleet.ExpireLocal(key)
+//	err := fleet.Run(ctx, 1, func(ctx context.Context, instance int, cache xcache.Cache) error {
+//		_, err := loadOrCompute(ctx, cache, key)
+//
+//		return err
+//	})
+//	// assert on backend.LoadCount() / the no. of computations.
+type Fleet struct {
+	backend   *Backend
+	memories  []*xcache.Memory
+	instances []xcache.Cache
+}
+
+// NewFleet instantiates a new Fleet over given Backend, with given settings.
+func NewFleet(backend *Backend, config FleetConfig) *Fleet {
+	if config.Instances <= 0 {
+		config.Instances = fleetDefaultInstances
+	}
+	if config.MemorySize <= 0 {
+		config.MemorySize = fleetDefaultMemorySize
+	}
+
+	fleet := &Fleet{
+		backend:   backend,
+		memories:  make([]*xcache.Memory, config.Instances),
+		instances: make([]xcache.Cache, config.Instances),
+	}
+	for i := 0; i < config.Instances; i++ {
+		fleet.memories[i] = xcache.NewMemory(config.MemorySize)
+		var cache xcache.Cache = xcache.NewMulti(fleet.memories[i], backend)
+		if config.Decorate != nil {
+			cache = config.Decorate(i, cache)
+		}
+		fleet.instances[i] = cache
+	}
+
+	return fleet
+}
+
+// Len returns the no. of instances.
+func (fleet *Fleet) Len() int {
+	return len(fleet.instances)
+}
+
+// Instance returns the cache of the instance at given index.
+func (fleet *Fleet) Instance(idx int) xcache.Cache {
+	return fleet.instances[idx]
+}
+
+// Memory returns the Memory layer of the instance at given index.
+func (fleet *Fleet) Memory(idx int) *xcache.Memory {
+	return fleet.memories[idx]
+}
+
+// Backend returns the Backend shared by the instances.
+func (fleet *Fleet) Backend() *Backend {
+	return fleet.backend
+}
+
+// ExpireLocal deletes the given keys from all instances' Memory layers,
+// simulating their simultaneous expiration.
+func (fleet *Fleet) ExpireLocal(keys ...string) {
+	ctx := context.Background()
+	for _, memory := range fleet.memories {
+		_ = memory.DeleteMany(ctx, keys...)
+	}
+}
+
+// Run executes given function concurrently, requests times for each instance, from a goroutine per request.
+// All goroutines are started before any of them is released, so the requests overlap as much as possible
+// (like the requests hitting a fleet right after a key expired).
+// It waits for all requests to complete, and returns their errors, joined.
+func (fleet *Fleet) Run(
+	ctx context.Context,
+	requests int,
+	fn func(ctx context.Context, instance int, cache xcache.Cache) error,
+) error {
+	var (
+		start = make(chan struct{})
+		wg    sync.WaitGroup
+		errs  = make([]error, len(fleet.instances)*requests)
+	)
+	wg.Add(len(errs))
+	for i := range errs {
+		go func(idx int) {
+			defer wg.Done()
+			<-start
+			instance := idx % len(fleet.instances)
+			errs[idx] = fn(ctx, instance, fleet.instances[instance])
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+func TestFleet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("local layers serve warm keys", testFleetLocalLayersServeWarmKeys)
+	t.Run("expired key is computed once with protection", testFleetExpiredKeyIsComputedOnceWithProtection)
+	t.Run("errors are joined", testFleetErrorsAreJoined)
+}
+
+func testFleetLocalLayersServeWarmKeys(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		backend = xcachetest.NewBackend()
+		subject = xcachetest.NewFleet(backend, xcachetest.FleetConfig{Instances: 5})
+		ctx     = context.Background()
+		key     = "test-fleet-warm-key"
+		value   = []byte("test value")
+		load    = func(ctx context.Context, _ int, cache xcache.Cache) error {
+			_, err := cache.Load(ctx, key)
+
+			return err
+		}
+	)
+	requireNil(t, backend.Save(ctx, key, value, time.Minute))
+
+	// act & assert warm up
+	resultErr := subject.Run(ctx, 1, load)
+	assertNil(t, resultErr)
+	assertEqual(t, 5, subject.Len())
+	assertEqual(t, int64(5), backend.LoadCount())
+
+	// act & assert warm requests
+	resultErr = subject.Run(ctx, 10, load)
+	assertNil(t, resultErr)
+	assertEqual(t, int64(5), backend.LoadCount())
+
+	// act & assert local expiration
+	subject.ExpireLocal(key)
+	resultErr = subject.Run(ctx, 1, load)
+	assertNil(t, resultErr)
+	assertEqual(t, int64(10), backend.LoadCount())
+	resultValue, resultErr := subject.Memory(0).Load(ctx, key)
+	assertNil(t, resultErr)
+	assertEqual(t, value, resultValue)
+}
+
+func testFleetExpiredKeyIsComputedOnceWithProtection(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		backend = xcachetest.NewBackend()
+		mu      sync.Mutex // a naive, fleet wide, stampede protection.
+		subject = xcachetest.NewFleet(backend, xcachetest.FleetConfig{
+			Instances: 10,
+			Decorate: func(_ int, cache xcache.Cache) xcache.Cache {
+				return lockedLoadCache{Cache: cache, mu: &mu}
+			},
+		})
+		ctx          = context.Background()
+		key          = "test-fleet-stampede-key"
+		computations int32
+	)
+	backend.SetLatency(time.Millisecond)
+	requireNil(t, backend.Save(ctx, key, []byte("test value"), time.Minute))
+	backend.Advance(time.Minute)
+
+	// act
+	resultErr := subject.Run(ctx, 5, func(ctx context.Context, _ int, cache xcache.Cache) error {
+		_, err := cache.Load(ctx, key)
+		if errors.Is(err, xcache.ErrNotFound) {
+			atomic.AddInt32(&computations, 1)
+			err = cache.Save(ctx, key, []byte("test computed value"), time.Minute)
+		}
+
+		return err
+	})
+
+	// assert
+	assertNil(t, resultErr)
+	assertEqual(t, int32(1), atomic.LoadInt32(&computations))
+}
+
+func testFleetErrorsAreJoined(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		subject     = xcachetest.NewFleet(xcachetest.NewBackend(), xcachetest.FleetConfig{Instances: 3})
+		expectedErr = errors.New("intentionally triggered instance error")
+		calls       int32
+	)
+
+	// act
+	resultErr := subject.Run(context.Background(), 2, func(_ context.Context, instance int, _ xcache.Cache) error {
+		atomic.AddInt32(&calls, 1)
+		if instance == 1 {
+			return expectedErr
+		}
+
+		return nil
+	})
+
+	// assert
+	assertTrue(t, errors.Is(resultErr, expectedErr))
+	assertEqual(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+// lockedLoadCache serializes loads, so a missing key is computed and saved by the first request only.
+type lockedLoadCache struct {
+	xcache.Cache
+	mu *sync.Mutex
+}
+
+func (cache lockedLoadCache) Load(ctx context.Context, key string) ([]byte, error) {
+	cache.mu.Lock()
+	value, err := cache.Cache.Load(ctx, key)
+	if err == nil {
+		cache.mu.Unlock()
+	}
+
+	return value, err // on miss, the lock is released by Save.
+}
+
+func (cache lockedLoadCache) Save(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	defer cache.mu.Unlock()
+
+	return cache.Cache.Save(ctx, key, value, expire)
+}
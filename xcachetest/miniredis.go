@@ -0,0 +1,207 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+
+	"github.com/actforgood/xcache"
+)
+
+// MiniRedisTotalSystemMemory is the total system memory (in bytes) a MiniRedis reports through INFO,
+// and thus, the max. memory reported by xcache.Redis6 / xcache.Redis7 Stats.
+const MiniRedisTotalSystemMemory = 1024 * 1024 * 1024
+
+// MiniRedis is an embedded Redis server (miniredis), preconfigured with xcache's expectations,
+// so that xcache.Redis6 / xcache.Redis7 code paths can be unit tested without a real Redis server
+// (Docker) and without the integration build tag:
+//   - keys' TTLs decrease with the wall clock (miniredis TTLs decrease only with FastForward),
+//     so TTL assertions behave like against a real Redis server;
+//   - INFO command's memory, stats and keyspace sections are stubbed with the fields xcache parses
+//     (used memory, total system memory, keyspace hits / misses, expired / evicted keys, keys count),
+//     so Stats and KeyspaceStats return meaningful values.
+//
+// Note: only the logical database 0 is accounted in INFO.
+type MiniRedis struct {
+	*miniredis.Miniredis
+	lastSync time.Time
+	hits     int64
+	misses   int64
+	expired  int64
+	mu       sync.Mutex
+}
+
+// NewMiniRedis starts a new MiniRedis, on a random port, which is closed at the end of the test.
+func NewMiniRedis(t testing.TB) *MiniRedis {
+	t.Helper()
+
+	mr := &MiniRedis{
+		Miniredis: miniredis.NewMiniRedis(),
+		lastSync:  time.Now(),
+	}
+	if err := mr.Start(); err != nil {
+		t.Fatalf("could not start miniredis: %v", err)
+	}
+	mr.Server().SetPreHook(mr.preHook)
+	t.Cleanup(mr.Close)
+
+	return mr
+}
+
+// Config returns the configuration for a xcache.Redis6 / xcache.Redis7 to connect to the MiniRedis.
+func (mr *MiniRedis) Config() xcache.RedisConfig {
+	return xcache.RedisConfig{
+		Addrs: []string{mr.Addr()},
+	}
+}
+
+// NewRedis6 returns a xcache.Redis6 connected to the MiniRedis, which is closed at the end of the test.
+func (mr *MiniRedis) NewRedis6(t testing.TB) *xcache.Redis6 {
+	t.Helper()
+
+	cache := xcache.NewRedis6(mr.Config())
+	t.Cleanup(func() { _ = cache.Close() })
+
+	return cache
+}
+
+// NewRedis7 returns a xcache.Redis7 connected to the MiniRedis, which is closed at the end of the test.
+func (mr *MiniRedis) NewRedis7(t testing.TB) *xcache.Redis7 {
+	t.Helper()
+
+	cache := xcache.NewRedis7(mr.Config())
+	t.Cleanup(func() { _ = cache.Close() })
+
+	return cache
+}
+
+// FastForward moves the MiniRedis's clock forward with given duration, expiring keys accordingly,
+// without waiting.
+func (mr *MiniRedis) FastForward(duration time.Duration) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.fastForward(duration)
+}
+
+// fastForward expires keys which TTLs are shorter than given duration, and decreases the TTLs of the others.
+// Caller should hold the lock.
+func (mr *MiniRedis) fastForward(duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	db := mr.DB(0)
+	for _, key := range db.Keys() {
+		if ttl := db.TTL(key); ttl > 0 && ttl <= duration {
+			mr.expired++
+		}
+	}
+	mr.Miniredis.FastForward(duration)
+}
+
+// preHook is executed before each command: it syncs keys' TTLs with the wall clock,
+// counts keyspace hits / misses, and responds to INFO command.
+func (mr *MiniRedis) preHook(peer *server.Peer, cmd string, args ...string) bool {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := time.Now()
+	mr.fastForward(now.Sub(mr.lastSync))
+	mr.lastSync = now
+
+	switch cmd {
+	case "GET", "GETEX", "GETDEL":
+		if len(args) > 0 {
+			mr.countLookups(args[:1])
+		}
+	case "MGET":
+		mr.countLookups(args)
+	case "INFO":
+		peer.WriteBulk(mr.info(args))
+
+		return true
+	}
+
+	return false
+}
+
+// countLookups counts the hits / misses of given keys' lookup.
+// Caller should hold the lock.
+func (mr *MiniRedis) countLookups(keys []string) {
+	db := mr.DB(0)
+	for _, key := range keys {
+		if db.Exists(key) {
+			mr.hits++
+		} else {
+			mr.misses++
+		}
+	}
+}
+
+// info returns the response of INFO command for given sections (all, if none is given),
+// containing the fields parsed by xcache.
+// Caller should hold the lock.
+func (mr *MiniRedis) info(sections []string) string {
+	if len(sections) == 0 {
+		sections = []string{"memory", "stats", "keyspace"}
+	}
+
+	var (
+		db   = mr.DB(0)
+		sb   strings.Builder
+		itoa = func(value int64) string { return strconv.FormatInt(value, 10) }
+	)
+	for _, section := range sections {
+		switch strings.ToLower(section) {
+		case "memory":
+			var usedMemory int64
+			for _, key := range db.Keys() {
+				usedMemory += int64(len(key))
+				if value, err := db.Get(key); err == nil {
+					usedMemory += int64(len(value))
+				}
+			}
+			sb.WriteString("# Memory\r\n")
+			sb.WriteString("used_memory:" + itoa(usedMemory) + "\r\n")
+			sb.WriteString("total_system_memory:" + itoa(MiniRedisTotalSystemMemory) + "\r\n")
+			sb.WriteString("maxmemory:0\r\n\r\n")
+		case "stats":
+			sb.WriteString("# Stats\r\n")
+			sb.WriteString("expired_keys:" + itoa(mr.expired) + "\r\n")
+			sb.WriteString("evicted_keys:0\r\n")
+			sb.WriteString("keyspace_hits:" + itoa(mr.hits) + "\r\n")
+			sb.WriteString("keyspace_misses:" + itoa(mr.misses) + "\r\n\r\n")
+		case "keyspace":
+			sb.WriteString("# Keyspace\r\n")
+			keys := db.Keys()
+			if len(keys) == 0 {
+				break
+			}
+			var expires, ttlSum int64
+			for _, key := range keys {
+				if ttl := db.TTL(key); ttl > 0 {
+					expires++
+					ttlSum += ttl.Milliseconds()
+				}
+			}
+			var avgTTL int64
+			if expires > 0 {
+				avgTTL = ttlSum / expires
+			}
+			sb.WriteString("db0:keys=" + itoa(int64(len(keys))) + ",expires=" + itoa(expires) +
+				",avg_ttl=" + itoa(avgTTL) + "\r\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachetest"
+)
+
+func TestMiniRedis(t *testing.T) {
+	t.Parallel()
+
+	t.Run("redis6", func(t *testing.T) {
+		t.Parallel()
+
+		mr := xcachetest.NewMiniRedis(t)
+		testMiniRedis(t, mr, mr.NewRedis6(t))
+	})
+	t.Run("redis7", func(t *testing.T) {
+		t.Parallel()
+
+		mr := xcachetest.NewMiniRedis(t)
+		testMiniRedis(t, mr, mr.NewRedis7(t))
+	})
+}
+
+func testMiniRedis(t *testing.T, mr *xcachetest.MiniRedis, subject xcache.Cache) {
+	t.Helper()
+
+	// arrange
+	var (
+		ctx      = context.Background()
+		expKey   = "test-miniredis-exp-key"
+		noExpKey = "test-miniredis-no-exp-key"
+		value    = []byte("test value")
+	)
+	requireNil(t, subject.Save(ctx, expKey, value, time.Minute))
+	requireNil(t, subject.Save(ctx, noExpKey, value, xcache.NoExpire))
+
+	// act & assert ttl decreases with the wall clock
+	time.Sleep(20 * time.Millisecond)
+	ttl, err := subject.TTL(ctx, expKey)
+	assertNil(t, err)
+	assertTrue(t, ttl > 0 && ttl < time.Minute)
+
+	// act & assert load
+	result, err := subject.Load(ctx, expKey)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	_, err = subject.Load(ctx, "test-miniredis-missing-key")
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+
+	// act & assert keys expire with fast forward
+	mr.FastForward(time.Minute)
+	_, err = subject.Load(ctx, expKey)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	ttl, err = subject.TTL(ctx, noExpKey)
+	assertNil(t, err)
+	assertEqual(t, xcache.NoExpire, ttl)
+
+	// act & assert stats
+	stats, err := subject.Stats(ctx)
+	requireNil(t, err)
+	assertEqual(t, int64(1), stats.Keys)
+	assertEqual(t, int64(1), stats.Hits)
+	assertEqual(t, int64(2), stats.Misses)
+	assertEqual(t, int64(1), stats.Expired)
+	assertEqual(t, int64(len(noExpKey)+len(value)), stats.Memory)
+	assertEqual(t, int64(xcachetest.MiniRedisTotalSystemMemory), stats.MaxMemory)
+}
@@ -0,0 +1,18 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+// Package xcachewire provides [github.com/google/wire] provider sets for xcache caches,
+// whose cleanup functions close the caches / StatsWatcher.
+//
+// Example:
+//
+//	func initCache(config xconf.Config, interval xcachewire.StatsWatchInterval, fn xcachewire.StatsWatchFunc) (
+//		*xcache.StatsWatcher, func(), error,
+//	) {
+//		wire.Build(xcachewire.Redis7WithConfigSet, xcachewire.StatsWatcherSet)
+//
+//		return nil, nil, nil
+//	}
+package xcachewire
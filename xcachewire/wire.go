@@ -0,0 +1,95 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachewire
+
+import (
+	"context"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xconf"
+	"github.com/google/wire"
+)
+
+// Provider sets which provide a cache both as its concrete type, and as xcache.Cache.
+var (
+	// Redis6Set provides a *xcache.Redis6 / xcache.Cache, based on a xcache.RedisConfig.
+	Redis6Set = wire.NewSet(ProvideRedis6, wire.Bind(new(xcache.Cache), new(*xcache.Redis6)))
+	// Redis6WithConfigSet provides a *xcache.Redis6 / xcache.Cache, based on a xconf.Config.
+	Redis6WithConfigSet = wire.NewSet(ProvideRedis6WithConfig, wire.Bind(new(xcache.Cache), new(*xcache.Redis6)))
+	// Redis7Set provides a *xcache.Redis7 / xcache.Cache, based on a xcache.RedisConfig.
+	Redis7Set = wire.NewSet(ProvideRedis7, wire.Bind(new(xcache.Cache), new(*xcache.Redis7)))
+	// Redis7WithConfigSet provides a *xcache.Redis7 / xcache.Cache, based on a xconf.Config.
+	Redis7WithConfigSet = wire.NewSet(ProvideRedis7WithConfig, wire.Bind(new(xcache.Cache), new(*xcache.Redis7)))
+	// MemoryWithConfigSet provides a *xcache.Memory / xcache.Cache, based on a xconf.Config.
+	MemoryWithConfigSet = wire.NewSet(xcache.NewMemoryWithValidConfig, wire.Bind(new(xcache.Cache), new(*xcache.Memory)))
+	// StatsWatcherSet provides a started *xcache.StatsWatcher, based on a xcache.Cache,
+	// a StatsWatchInterval and a StatsWatchFunc.
+	StatsWatcherSet = wire.NewSet(ProvideStatsWatcher)
+)
+
+// StatsWatchInterval is the interval at which a StatsWatcher executes its callback.
+type StatsWatchInterval time.Duration
+
+// StatsWatchFunc is the callback executed by a StatsWatcher.
+type StatsWatchFunc func(context.Context, xcache.Stats, error)
+
+// ProvideRedis6 provides a new Redis6 cache, and the cleanup function which closes it.
+func ProvideRedis6(config xcache.RedisConfig) (*xcache.Redis6, func()) {
+	cache := xcache.NewRedis6(config)
+
+	return cache, func() { _ = cache.Close() }
+}
+
+// ProvideRedis6WithConfig provides a new Redis6 cache based on a xconf.Config,
+// and the cleanup function which closes it.
+// An invalid configuration results in an error (see xcache.NewRedis6WithValidConfig).
+func ProvideRedis6WithConfig(config xconf.Config) (*xcache.Redis6, func(), error) {
+	cache, err := xcache.NewRedis6WithValidConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cache, func() { _ = cache.Close() }, nil
+}
+
+// ProvideRedis7 provides a new Redis7 cache, and the cleanup function which closes it.
+func ProvideRedis7(config xcache.RedisConfig) (*xcache.Redis7, func()) {
+	cache := xcache.NewRedis7(config)
+
+	return cache, func() { _ = cache.Close() }
+}
+
+// ProvideRedis7WithConfig provides a new Redis7 cache based on a xconf.Config,
+// and the cleanup function which closes it.
+// An invalid configuration results in an error (see xcache.NewRedis7WithValidConfig).
+func ProvideRedis7WithConfig(config xconf.Config) (*xcache.Redis7, func(), error) {
+	cache, err := xcache.NewRedis7WithValidConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cache, func() { _ = cache.Close() }, nil
+}
+
+// ProvideStatsWatcher provides a StatsWatcher which is already watching given cache's stats,
+// and the cleanup function which stops it.
+func ProvideStatsWatcher(
+	cache xcache.Cache,
+	interval StatsWatchInterval,
+	fn StatsWatchFunc,
+) (*xcache.StatsWatcher, func()) {
+	var (
+		watcher        = xcache.NewStatsWatcher(cache, time.Duration(interval))
+		ctx, cancelCtx = context.WithCancel(context.Background())
+	)
+	watcher.Watch(ctx, fn)
+
+	return watcher, func() {
+		cancelCtx()
+		_ = watcher.Close()
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcachewire_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+	"github.com/actforgood/xcache/xcachewire"
+	"github.com/actforgood/xconf"
+)
+
+func TestProvideRedis(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		config            = xcache.RedisConfig{Addrs: []string{"127.0.0.1:6379"}}
+		xconfig           = xconf.NewMockConfig(xcache.RedisCfgKeyAddrs, []string{"127.0.0.1:6379"})
+		redis6, c1        = xcachewire.ProvideRedis6(config)
+		redis7, c2        = xcachewire.ProvideRedis7(config)
+		redis6X, c3, err6 = xcachewire.ProvideRedis6WithConfig(xconfig)
+		redis7X, c4, err7 = xcachewire.ProvideRedis7WithConfig(xconfig)
+	)
+	requireNil(t, err6)
+	requireNil(t, err7)
+
+	// act
+	c1()
+	c2()
+	c3()
+	c4()
+
+	// assert - already closed by cleanup functions.
+	assertTrue(t, redis6.Close() != nil)
+	assertTrue(t, redis7.Close() != nil)
+	assertTrue(t, redis6X.Close() != nil)
+	assertTrue(t, redis7X.Close() != nil)
+}
+
+func TestProvideRedis_invalidConfig(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	xconfig := xconf.NewMockConfig(xcache.RedisCfgKeyAddrs, []int{6379})
+
+	// act
+	redis6, c6, err6 := xcachewire.ProvideRedis6WithConfig(xconfig)
+	redis7, c7, err7 := xcachewire.ProvideRedis7WithConfig(xconfig)
+
+	// assert
+	assertTrue(t, err6 != nil)
+	assertTrue(t, err7 != nil)
+	assertTrue(t, redis6 == nil && c6 == nil)
+	assertTrue(t, redis7 == nil && c7 == nil)
+}
+
+func TestProvideStatsWatcher(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = new(xcache.Mock)
+		callsCnt uint32
+		fn       = func(context.Context, xcache.Stats, error) {
+			atomic.AddUint32(&callsCnt, 1)
+		}
+	)
+
+	// act
+	watcher, cleanup := xcachewire.ProvideStatsWatcher(
+		cache,
+		xcachewire.StatsWatchInterval(100*time.Millisecond),
+		fn,
+	)
+	time.Sleep(350 * time.Millisecond)
+	cleanup()
+	callsCntAtCleanup := atomic.LoadUint32(&callsCnt)
+	time.Sleep(250 * time.Millisecond)
+
+	// assert
+	assertTrue(t, watcher != nil)
+	assertTrue(t, callsCntAtCleanup >= 3)
+	assertEqual(t, callsCntAtCleanup, atomic.LoadUint32(&callsCnt)) // no more calls after cleanup.
+}
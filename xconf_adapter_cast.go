@@ -0,0 +1,406 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/actforgood/xconf"
+	"github.com/actforgood/xerr"
+)
+
+// ConfigError describes an invalid configuration value taken from a xconf.Config.
+type ConfigError struct {
+	// Key is the config key holding the invalid value.
+	Key string
+	// Err is the reason the value is invalid.
+	Err error
+}
+
+// Error returns the string representation of the error.
+func (cfgErr *ConfigError) Error() string {
+	return "xcache: invalid value for config key " + strconv.Quote(cfgErr.Key) + ": " + cfgErr.Err.Error()
+}
+
+// Unwrap returns the reason the value is invalid.
+func (cfgErr *ConfigError) Unwrap() error {
+	return cfgErr.Err
+}
+
+// CfgKeyDurationUnit is the key under which xconf.Config expects the unit of durations given as numbers,
+// for all xcache duration keys. Value should be a unit like "ms" / "s" (or a duration string like "1ms").
+// By default, numbers are considered milliseconds, as sources like YAML / env usually provide them
+// (a plain 500 meaning 500ms, not 500ns). Set it to "ns" for numbers being time.Duration values.
+const CfgKeyDurationUnit = "xcache.durationunit"
+
+var (
+	errConfigValueType   = errors.New("unsupported type")
+	errConfigValueFormat = errors.New("invalid format")
+	errConfigValueRange  = errors.New("value out of range")
+	errConfigValueEmpty  = errors.New("empty value")
+)
+
+// xconfReader reads values from a xconf.Config, coercing common representations to expected types.
+// Invalid values are replaced with defaults, and corresponding ConfigError(s) are collected.
+type xconfReader struct {
+	config       xconf.Config
+	mErr         *xerr.MultiError
+	keyName      func(key string) string // optional, the name under which a key is reported in errors.
+	durationUnit time.Duration           // the unit of durations given as numbers, read on first use.
+}
+
+// newXConfReader instantiates a new xconfReader for given config.
+func newXConfReader(config xconf.Config) *xconfReader {
+	return &xconfReader{config: config}
+}
+
+// Err returns the collected ConfigError(s), if any.
+func (r *xconfReader) Err() error {
+	return r.mErr.ErrOrNil()
+}
+
+// addErr collects a ConfigError for given key.
+func (r *xconfReader) addErr(key string, err error) {
+	if r.keyName != nil {
+		key = r.keyName(key)
+	}
+	r.mErr = r.mErr.Add(&ConfigError{Key: key, Err: err})
+}
+
+// addValueErr collects a ConfigError for given key, mentioning the type of the invalid value.
+func (r *xconfReader) addValueErr(key string, value any, err error) {
+	r.addErr(key, fmt.Errorf("%w (%T)", err, value))
+}
+
+// String returns the string value of given key.
+// Numbers and booleans are accepted, too.
+func (r *xconfReader) String(key, def string) string {
+	switch value := r.config.Get(key).(type) {
+	case nil:
+		return def
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return fmt.Sprint(value)
+	default:
+		r.addValueErr(key, value, errConfigValueType)
+
+		return def
+	}
+}
+
+// StringSlice returns the string slice value of given key.
+// A comma separated string is accepted, too.
+func (r *xconfReader) StringSlice(key string, def []string) []string {
+	switch value := r.config.Get(key).(type) {
+	case nil:
+		return def
+	case []string:
+		return value
+	case string:
+		return splitCommaSeparated(value)
+	case []any:
+		result := make([]string, len(value))
+		for i, elem := range value {
+			str, ok := elem.(string)
+			if !ok {
+				r.addValueErr(key, elem, errConfigValueType)
+
+				return def
+			}
+			result[i] = str
+		}
+
+		return result
+	default:
+		r.addValueErr(key, value, errConfigValueType)
+
+		return def
+	}
+}
+
+// Int returns the int value of given key.
+// Any integer type, integral floats, and numeric strings are accepted, too.
+func (r *xconfReader) Int(key string, def int) int {
+	value := r.config.Get(key)
+	if value == nil {
+		return def
+	}
+	result, err := toInt64(value)
+	if err == nil && (result < math.MinInt || result > math.MaxInt) {
+		err = errConfigValueRange
+	}
+	if err != nil {
+		r.addValueErr(key, value, err)
+
+		return def
+	}
+
+	return int(result)
+}
+
+// Bool returns the bool value of given key.
+// Strings accepted by [strconv.ParseBool], and 0/1 integers are accepted, too.
+func (r *xconfReader) Bool(key string, def bool) bool {
+	switch value := r.config.Get(key).(type) {
+	case nil:
+		return def
+	case bool:
+		return value
+	case string:
+		result, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			r.addValueErr(key, value, errConfigValueFormat)
+
+			return def
+		}
+
+		return result
+	default:
+		number, err := toInt64(value)
+		if err != nil || (number != 0 && number != 1) {
+			r.addValueErr(key, value, errConfigValueType)
+
+			return def
+		}
+
+		return number == 1
+	}
+}
+
+// Float64 returns the float64 value of given key.
+// Any integer type, and numeric strings are accepted, too.
+func (r *xconfReader) Float64(key string, def float64) float64 {
+	switch value := r.config.Get(key).(type) {
+	case nil:
+		return def
+	case float64:
+		return value
+	case float32:
+		return float64(value)
+	case string:
+		result, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			r.addValueErr(key, value, errConfigValueFormat)
+
+			return def
+		}
+
+		return result
+	default:
+		result, err := toInt64(value)
+		if err != nil {
+			r.addValueErr(key, value, err)
+
+			return def
+		}
+
+		return float64(result)
+	}
+}
+
+// ByteSize returns the size in bytes value of given key.
+// Strings having a unit like "512KB", "64MB", "1GB" (multiples of 1024) are accepted, too.
+func (r *xconfReader) ByteSize(key string, def int) int {
+	value := r.config.Get(key)
+	if value == nil {
+		return def
+	}
+	result, err := toByteSize(value)
+	if err == nil && (result < math.MinInt || result > math.MaxInt) {
+		err = errConfigValueRange
+	}
+	if err != nil {
+		r.addValueErr(key, value, err)
+
+		return def
+	}
+
+	return int(result)
+}
+
+// Duration returns the time.Duration value of given key.
+// Strings accepted by [time.ParseDuration] are accepted, too.
+// Numbers (and numeric strings) are considered to be expressed in the unit configured under
+// CfgKeyDurationUnit key, milliseconds by default. A time.Duration value is taken as it is.
+func (r *xconfReader) Duration(key string, def time.Duration) time.Duration {
+	value := r.config.Get(key)
+	switch value := value.(type) {
+	case nil:
+		return def
+	case time.Duration:
+		return value
+	case string:
+		if result, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			return result
+		}
+	}
+	result, err := toInt64(value)
+	unit := r.DurationUnit()
+	if err == nil && (result > math.MaxInt64/int64(unit) || result < math.MinInt64/int64(unit)) {
+		err = errConfigValueRange
+	}
+	if err != nil {
+		r.addValueErr(key, value, err)
+
+		return def
+	}
+
+	return time.Duration(result) * unit
+}
+
+// DurationUnit returns the unit of durations given as numbers, configured under CfgKeyDurationUnit key.
+// Units accepted by [time.ParseDuration] (like "ms"), and duration strings (like "1ms") are accepted.
+// By default, it's a millisecond.
+func (r *xconfReader) DurationUnit() time.Duration {
+	if r.durationUnit != 0 {
+		return r.durationUnit
+	}
+
+	r.durationUnit = time.Millisecond
+	switch value := r.config.Get(CfgKeyDurationUnit).(type) {
+	case nil:
+	case time.Duration:
+		if value > 0 {
+			r.durationUnit = value
+		} else {
+			r.addValueErr(CfgKeyDurationUnit, value, errConfigValueRange)
+		}
+	case string:
+		value = strings.TrimSpace(value)
+		unit, err := time.ParseDuration(value)
+		if err != nil {
+			unit, err = time.ParseDuration("1" + value)
+		}
+		if err == nil && unit > 0 {
+			r.durationUnit = unit
+		} else {
+			r.addValueErr(CfgKeyDurationUnit, value, errConfigValueFormat)
+		}
+	default:
+		r.addValueErr(CfgKeyDurationUnit, value, errConfigValueType)
+	}
+
+	return r.durationUnit
+}
+
+// toInt64 converts given value to int64.
+func toInt64(value any) (int64, error) {
+	switch value := value.(type) {
+	case int:
+		return int64(value), nil
+	case int8:
+		return int64(value), nil
+	case int16:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case uint:
+		return uintToInt64(uint64(value))
+	case uint8:
+		return int64(value), nil
+	case uint16:
+		return int64(value), nil
+	case uint32:
+		return int64(value), nil
+	case uint64:
+		return uintToInt64(value)
+	case float32:
+		return floatToInt64(float64(value))
+	case float64:
+		return floatToInt64(value)
+	case string:
+		result, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, errConfigValueFormat
+		}
+
+		return result, nil
+	default:
+		return 0, errConfigValueType
+	}
+}
+
+// byteSizeUnits holds the units accepted by toByteSize, longest first.
+var byteSizeUnits = [...]struct {
+	suffix string
+	size   int64
+}{
+	{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// toByteSize converts given value to a size in bytes.
+// Besides toInt64 accepted values, strings having a unit (like "64MB") are accepted.
+func toByteSize(value any) (int64, error) {
+	str, ok := value.(string)
+	if !ok {
+		return toInt64(value)
+	}
+	str = strings.ToUpper(strings.TrimSpace(str))
+	unit := int64(1)
+	for _, byteSizeUnit := range byteSizeUnits {
+		if number, found := strings.CutSuffix(str, byteSizeUnit.suffix); found {
+			str, unit = strings.TrimSpace(number), byteSizeUnit.size
+
+			break
+		}
+	}
+	result, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, errConfigValueFormat
+	}
+	if result > math.MaxInt64/unit || result < math.MinInt64/unit {
+		return 0, errConfigValueRange
+	}
+
+	return result * unit, nil
+}
+
+// uintToInt64 converts given uint64 to int64, if it's in range.
+func uintToInt64(value uint64) (int64, error) {
+	if value > math.MaxInt64 {
+		return 0, errConfigValueRange
+	}
+
+	return int64(value), nil
+}
+
+// floatToInt64 converts given float to int64, if it's an integral number in range.
+func floatToInt64(value float64) (int64, error) {
+	if value != math.Trunc(value) {
+		return 0, errConfigValueFormat
+	}
+	if value < math.MinInt64 || value >= math.MaxInt64 {
+		return 0, errConfigValueRange
+	}
+
+	return int64(value), nil
+}
+
+// splitCommaSeparated splits given string by comma,
+// trims spaces around elements, and disregards empty ones.
+func splitCommaSeparated(str string) []string {
+	parts := strings.Split(str, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,212 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// XFetchDefaultBeta is the default XFetch's beta, the one recommended by the algorithm's authors.
+const XFetchDefaultBeta = 1.0
+
+// XFetch is a Cache decorator implementing the "optimal probabilistic cache stampede prevention"
+// algorithm (XFetch, Vattani et al.): each value is stored along with the time it took to be computed (delta),
+// and, on Fetch, a value is recomputed before its expiration with a probability which grows as the expiration
+// approaches, and the longer the recomputation takes.
+// This way, usually only one of the instances of an application recomputes a hot key before it expires,
+// without any coordination between them, complementing the in-process deduplication of recomputations
+// (concurrent Fetch calls for the same key share the recomputation, like on LoadOrCompute).
+//
+// Keys' expiration moments and recompute costs are stored alongside the values, in an Envelope.
+// Values saved with Save have no recompute cost, they are not recomputed early;
+// values not having an envelope (saved directly into decorated cache) are returned as they are.
+//
+// Example:
+//
+//	cache := xcache.NewXFetch(redisCache, xcache.XFetchDefaultBeta)
+//	value, err := cache.Fetch(ctx, "product:"+id, time.Hour, func(ctx context.Context) ([]byte, error) {
+//		return productsRepo.GetJSON(ctx, id)
+//	})
+type XFetch struct {
+	cache Cache
+	beta  float64
+	early int64
+}
+
+// NewXFetch instantiates a new XFetch which decorates given cache.
+// Beta (> 0) scales the probability of early recomputation: values greater than 1 favor earlier recomputations,
+// values lower than 1 favor later ones. A non-positive beta is replaced with XFetchDefaultBeta.
+func NewXFetch(cache Cache, beta float64) *XFetch {
+	if beta <= 0 {
+		beta = XFetchDefaultBeta
+	}
+
+	return &XFetch{
+		cache: cache,
+		beta:  beta,
+	}
+}
+
+// Fetch returns a key's value from decorated cache, or, if it's missing, or it was chosen to be recomputed
+// early, computes it with given function and saves it into cache, with given expiration period,
+// and the time the computation took.
+// Concurrent in-process calls for the same key share the computation (singleflight), which runs
+// without the cancellation of the triggering caller's context (see LoadOrCompute).
+//
+// Cache errors are not returned (the value is computed, as if the key was not found),
+// nor is a failure to save the computed value. Errors returned by the compute function are returned,
+// unless the value was recomputed early, case when the current value is returned.
+func (cache *XFetch) Fetch(
+	ctx context.Context,
+	key string,
+	expire time.Duration,
+	compute ComputeFunc,
+) ([]byte, error) {
+	var (
+		current []byte
+		valid   bool
+	)
+	if value, err := cache.cache.Load(ctx, key); err == nil {
+		payload, expireAt, delta, expired := cache.open(value)
+		if !expired {
+			if !cache.recomputeEarly(expireAt, delta) {
+				return payload, nil
+			}
+			current, valid = payload, true
+		}
+	}
+
+	resultCh := computeGroup.DoChan(cacheIdentity(cache)+"\x00"+key, func() (any, error) {
+		if valid {
+			atomic.AddInt64(&cache.early, 1)
+		}
+		sharedCtx := context.WithoutCancel(ctx)
+		start := time.Now()
+		value, err := compute(sharedCtx)
+		if err != nil {
+			return nil, err
+		}
+		_ = cache.save(sharedCtx, key, value, expire, time.Since(start))
+
+		return value, nil
+	})
+
+	value, err := awaitComputation(ctx, resultCh)
+	if err != nil && valid {
+		return current, nil
+	}
+
+	return value, err
+}
+
+// Save stores the given key-value with expiration period into decorated cache.
+// An expiration period equal to 0 (NoExpire) means no expiration.
+// A negative expiration period triggers deletion of key.
+func (cache *XFetch) Save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+) error {
+	return cache.save(ctx, key, value, expire, 0)
+}
+
+// Load returns a key's value from decorated cache (it's never recomputed early, see Fetch).
+func (cache *XFetch) Load(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.cache.Load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	payload, _, _, expired := cache.open(value)
+	if expired {
+		return nil, ErrNotFound
+	}
+
+	return payload, nil
+}
+
+// TTL returns a key's remaining time to live from decorated cache.
+func (cache *XFetch) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return cache.cache.TTL(ctx, key)
+}
+
+// Stats returns decorated cache's statistics.
+func (cache *XFetch) Stats(ctx context.Context) (Stats, error) {
+	return cache.cache.Stats(ctx)
+}
+
+// Unwrap returns the decorated cache.
+func (cache *XFetch) Unwrap() Cache {
+	return cache.cache
+}
+
+// ContributeStats reports the no. of values recomputed before their expiration, as "xfetch.early" metric.
+func (cache *XFetch) ContributeStats(add func(name string, value float64)) {
+	add("xfetch.early", float64(atomic.LoadInt64(&cache.early)))
+}
+
+// save stores the given key-value into decorated cache, enveloped with its expiration moment
+// and recompute cost (delta).
+func (cache *XFetch) save(
+	ctx context.Context,
+	key string,
+	value []byte,
+	expire time.Duration,
+	delta time.Duration,
+) error {
+	if expire < 0 {
+		return cache.cache.Save(ctx, key, value, expire)
+	}
+
+	var (
+		env      Envelope
+		deltaBuf [binary.MaxVarintLen64]byte
+	)
+	if expire > 0 {
+		env.ExpireAt = time.Now().Add(expire).UnixMilli()
+	}
+	if deltaMicro := delta.Microseconds(); deltaMicro > 0 {
+		env.Metadata = binary.AppendUvarint(deltaBuf[:0], uint64(deltaMicro))
+	}
+	enveloped := env.Append(make([]byte, 0, env.Size(len(value))), value)
+
+	return cache.cache.Save(ctx, key, enveloped, expire)
+}
+
+// open returns the payload, expiration moment (unix time, in milliseconds) and recompute cost of given value,
+// and whether it is expired. A value which is not of ours is returned as it is, as a not expiring one.
+func (cache *XFetch) open(value []byte) (payload []byte, expireAt int64, delta time.Duration, expired bool) {
+	if !IsEnvelope(value) {
+		return value, 0, 0, false
+	}
+	var env Envelope
+	payload, err := env.Unmarshal(value)
+	if err != nil {
+		return value, 0, 0, false
+	}
+	if deltaMicro, n := binary.Uvarint(env.Metadata); n > 0 && deltaMicro <= math.MaxInt64/1000 {
+		delta = time.Duration(deltaMicro) * time.Microsecond
+	}
+	expired = env.ExpireAt > 0 && time.Now().UnixMilli() >= env.ExpireAt
+
+	return payload, env.ExpireAt, delta, expired
+}
+
+// recomputeEarly decides if a value, expiring at given moment, and taking given delta to be recomputed,
+// should be recomputed now: now - delta * beta * ln(rand(0, 1]) >= expireAt.
+func (cache *XFetch) recomputeEarly(expireAt int64, delta time.Duration) bool {
+	if expireAt == 0 || delta == 0 {
+		return false
+	}
+	gap := -float64(delta) * cache.beta * math.Log(1-rand.Float64()) // compared as float, it can overflow a Duration.
+
+	return float64(time.Until(time.UnixMilli(expireAt))) <= gap
+}
@@ -0,0 +1,267 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/xcache/blob/main/LICENSE.
+
+package xcache_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/xcache"
+)
+
+func init() {
+	var _ xcache.Cache = (*xcache.XFetch)(nil)            // test XFetch is a Cache
+	var _ xcache.StatsContributor = (*xcache.XFetch)(nil) // test XFetch is a StatsContributor
+}
+
+func TestXFetch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing key is computed", testXFetchMissingKeyIsComputed)
+	t.Run("key is recomputed early", testXFetchKeyIsRecomputedEarly)
+	t.Run("current value is returned if early recomputation fails", testXFetchEarlyRecomputationFails)
+	t.Run("compute error is returned for missing key", testXFetchComputeErrorIsReturned)
+	t.Run("leader's canceled context - waiter still succeeds", testXFetchLeadersCanceledContext)
+	t.Run("expired value is not found", testXFetchExpiredValueIsNotFound)
+	t.Run("value without envelope is returned", testXFetchValueWithoutEnvelopeIsReturned)
+}
+
+func testXFetchMissingKeyIsComputed(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = xcache.NewMemory(freecacheMinMem)
+		subject  = xcache.NewXFetch(cache, xcache.XFetchDefaultBeta)
+		ctx      = context.Background()
+		key      = "test-xfetch-key"
+		value    = []byte("test value")
+		computes int32
+		compute  = func(context.Context) ([]byte, error) {
+			atomic.AddInt32(&computes, 1)
+
+			return value, nil
+		}
+	)
+
+	// act
+	result1, err1 := subject.Fetch(ctx, key, time.Hour, compute)
+	result2, err2 := subject.Fetch(ctx, key, time.Hour, compute)
+
+	// assert
+	assertNil(t, err1)
+	assertEqual(t, value, result1)
+	assertNil(t, err2)
+	assertEqual(t, value, result2)
+	assertEqual(t, int32(1), atomic.LoadInt32(&computes))
+	result, err := subject.Load(ctx, key)
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	ttl, err := subject.TTL(ctx, key)
+	assertNil(t, err)
+	assertTrue(t, ttl > 59*time.Minute)
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"xfetch.early": 0}, metrics)
+	assertTrue(t, subject.Unwrap() == cache)
+}
+
+func testXFetchKeyIsRecomputedEarly(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache = xcache.NewMemory(freecacheMinMem)
+		// a huge beta makes the early recomputation (almost) certain.
+		subject  = xcache.NewXFetch(cache, 1e12)
+		ctx      = context.Background()
+		key      = "test-xfetch-early-key"
+		computes int32
+		compute  = func(context.Context) ([]byte, error) {
+			if atomic.AddInt32(&computes, 1) == 1 {
+				time.Sleep(time.Millisecond) // the recompute cost.
+
+				return []byte("test value"), nil
+			}
+
+			return []byte("test recomputed value"), nil
+		}
+	)
+	_, err := subject.Fetch(ctx, key, time.Hour, compute)
+	requireNil(t, err)
+
+	// act
+	result, err := subject.Fetch(ctx, key, time.Hour, compute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, []byte("test recomputed value"), result)
+	assertEqual(t, int32(2), atomic.LoadInt32(&computes))
+	metrics := make(map[string]float64)
+	subject.ContributeStats(func(name string, value float64) { metrics[name] = value })
+	assertEqual(t, map[string]float64{"xfetch.early": 1}, metrics)
+}
+
+func testXFetchEarlyRecomputationFails(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache    = xcache.NewMemory(freecacheMinMem)
+		subject  = xcache.NewXFetch(cache, 1e12)
+		ctx      = context.Background()
+		key      = "test-xfetch-early-err-key"
+		value    = []byte("test value")
+		computes int32
+		compute  = func(context.Context) ([]byte, error) {
+			if atomic.AddInt32(&computes, 1) == 1 {
+				time.Sleep(time.Millisecond)
+
+				return value, nil
+			}
+
+			return nil, errors.New("intentionally triggered compute error")
+		}
+	)
+	_, err := subject.Fetch(ctx, key, time.Hour, compute)
+	requireNil(t, err)
+
+	// act
+	result, err := subject.Fetch(ctx, key, time.Hour, compute)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+	assertEqual(t, int32(2), atomic.LoadInt32(&computes))
+}
+
+func testXFetchComputeErrorIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache       = xcache.NewMemory(freecacheMinMem)
+		subject     = xcache.NewXFetch(cache, xcache.XFetchDefaultBeta)
+		ctx         = context.Background()
+		key         = "test-xfetch-err-key"
+		expectedErr = errors.New("intentionally triggered compute error")
+	)
+
+	// act
+	result, err := subject.Fetch(ctx, key, time.Hour, func(context.Context) ([]byte, error) {
+		return nil, expectedErr
+	})
+
+	// assert
+	assertTrue(t, errors.Is(err, expectedErr))
+	assertNil(t, result)
+	_, err = cache.Load(ctx, key)
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+}
+
+func testXFetchExpiredValueIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = new(xcache.Mock)
+		subject = xcache.NewXFetch(cache, xcache.XFetchDefaultBeta)
+		ctx     = context.Background()
+		key     = "test-xfetch-expired-key"
+		env     = xcache.Envelope{ExpireAt: time.Now().Add(-time.Second).UnixMilli()}
+	)
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return env.Append(nil, []byte("test value")), nil
+	})
+
+	// act
+	result, err := subject.Load(ctx, key)
+
+	// assert
+	assertTrue(t, errors.Is(err, xcache.ErrNotFound))
+	assertNil(t, result)
+}
+
+func testXFetchValueWithoutEnvelopeIsReturned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache   = xcache.NewMemory(freecacheMinMem)
+		subject = xcache.NewXFetch(cache, xcache.XFetchDefaultBeta)
+		ctx     = context.Background()
+		key     = "test-xfetch-no-envelope-key"
+		value   = []byte("test value")
+	)
+	requireNil(t, cache.Save(ctx, key, value, time.Hour))
+
+	// act
+	result, err := subject.Fetch(ctx, key, time.Hour, func(context.Context) ([]byte, error) {
+		t.Error("value should not be computed")
+
+		return nil, nil
+	})
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, value, result)
+}
+
+func testXFetchLeadersCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		cache                 = new(xcache.Mock) // every key is missing.
+		subject               = xcache.NewXFetch(cache, 1)
+		key                   = "test-xfetch-leader-key"
+		value                 = []byte("test value")
+		release               = make(chan struct{})
+		started               = make(chan struct{})
+		leaderCtx, cancelLead = context.WithCancel(context.Background())
+		leaderErr             = make(chan error, 1)
+	)
+	defer cancelLead()
+	cache.SetLoadCallback(func(context.Context, string) ([]byte, error) {
+		return nil, xcache.ErrNotFound
+	})
+	go func() {
+		_, err := subject.Fetch(leaderCtx, key, time.Minute, func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-release
+
+			return value, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-started
+	waiterResult := make(chan []byte, 1)
+	go func() {
+		result, err := subject.Fetch(context.Background(), key, time.Minute, func(context.Context) ([]byte, error) {
+			return nil, errors.New("compute should not be called")
+		})
+		assertNil(t, err)
+		waiterResult <- result
+	}()
+	for cache.LoadCallsCount() < 2 { // leader's load, waiter's load.
+		runtime.Gosched()
+	}
+
+	// act
+	cancelLead()
+	errLeader := <-leaderErr
+	close(release)
+	result := <-waiterResult
+
+	// assert
+	assertTrue(t, errors.Is(errLeader, context.Canceled))
+	assertEqual(t, value, result)
+	assertEqual(t, 1, cache.SaveCallsCount())
+}